@@ -1,127 +1,716 @@
 package main
 
 import (
+	"context"
+	"crypto/ed25519"
 	"database/sql"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
-	"sync/atomic"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/kai-xlr/neo_chirpy/internal/alerts"
+	"github.com/kai-xlr/neo_chirpy/internal/backup"
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
+	"github.com/kai-xlr/neo_chirpy/internal/chaos"
+	"github.com/kai-xlr/neo_chirpy/internal/config"
 	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/domains"
+	"github.com/kai-xlr/neo_chirpy/internal/entitlements"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/httpclient"
+	"github.com/kai-xlr/neo_chirpy/internal/jobs"
+	"github.com/kai-xlr/neo_chirpy/internal/journal"
+	"github.com/kai-xlr/neo_chirpy/internal/linkpreview"
+	"github.com/kai-xlr/neo_chirpy/internal/listen"
+	"github.com/kai-xlr/neo_chirpy/internal/loadshed"
+	"github.com/kai-xlr/neo_chirpy/internal/logtail"
+	"github.com/kai-xlr/neo_chirpy/internal/mailer"
+	"github.com/kai-xlr/neo_chirpy/internal/metrics"
+	"github.com/kai-xlr/neo_chirpy/internal/migration"
+	"github.com/kai-xlr/neo_chirpy/internal/moderation"
+	"github.com/kai-xlr/neo_chirpy/internal/oidc"
+	"github.com/kai-xlr/neo_chirpy/internal/profanity"
+	"github.com/kai-xlr/neo_chirpy/internal/querylog"
+	"github.com/kai-xlr/neo_chirpy/internal/ratelimit"
+	"github.com/kai-xlr/neo_chirpy/internal/retention"
+	"github.com/kai-xlr/neo_chirpy/internal/rollout"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/internal/timeouts"
+	"github.com/kai-xlr/neo_chirpy/internal/version"
 	"github.com/kai-xlr/neo_chirpy/pkg/admin"
+	"github.com/kai-xlr/neo_chirpy/pkg/bootstrap"
 	"github.com/kai-xlr/neo_chirpy/pkg/chirp"
+	"github.com/kai-xlr/neo_chirpy/pkg/firehose"
 	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/instance"
 	"github.com/kai-xlr/neo_chirpy/pkg/middleware"
+	"github.com/kai-xlr/neo_chirpy/pkg/oauth"
+	"github.com/kai-xlr/neo_chirpy/pkg/scim"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
 	"github.com/kai-xlr/neo_chirpy/pkg/user"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
 	"github.com/kai-xlr/neo_chirpy/pkg/webhook"
 	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
 )
 
 const (
 	port         = 8080
 	filepathRoot = "."
+	logTailSize  = 1000
 )
 
 type apiConfig struct {
-	fileserverHits atomic.Int32
+	fileserverHits *cache.Counter
 	db             *database.Queries
 	platform       string
 	jwtSecret      string
 	polkaKey       string
+	cache          cache.Store
+	mailer         mailer.Mailer
 
 	// Handler configs
 	adminConfig      admin.Config
+	bootstrapConfig  bootstrap.Config
 	chirpConfig      chirp.Config
+	firehoseConfig   firehose.Config
+	instanceConfig   instance.Config
 	userConfig       user.Config
 	middlewareConfig middleware.Config
+	oauthConfig      oauth.Config
+	scimConfig       scim.Config
 	webhookConfig    webhook.Config
 }
 
 func main() {
-	// Load environment and initialize database
-	dbQueries, platform, jwtSecret, polkaKey := initDatabase()
+	// Load configuration from .env, CONFIG_FILE and the environment
+	godotenv.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Error loading configuration: %s", err)
+	}
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(cfg, os.Args[1:]))
+	}
+	if cfg.RegistrationMode != types.RegistrationOpen && cfg.RegistrationMode != types.RegistrationClosed {
+		log.Fatalf("Unknown REGISTRATION_MODE %q, expected open or closed", cfg.RegistrationMode)
+	}
+	counting, err := validation.ParseCounting(cfg.ChirpLengthCounting)
+	if err != nil {
+		log.Fatalf("Unknown CHIRP_LENGTH_COUNTING %q, expected runes or graphemes", cfg.ChirpLengthCounting)
+	}
+
+	// Keep recent log lines for admins to stream outside production
+	var logTail *logtail.Tail
+	if cfg.Platform == "dev" || cfg.Platform == "staging" {
+		logTail = logtail.New(os.Stderr, logTailSize)
+		log.SetOutput(logTail)
+		log.SetFlags(logtail.Flags)
+	}
+
+	db := initDatabase(cfg.DBURL, cfg.SlowQueryThreshold)
+	dbQueries := database.New(db)
+	platform, jwtSecret, polkaKey := cfg.Platform, cfg.JWTSecret, cfg.PolkaKey
 
 	// Initialize API configuration
 	apiCfg := &apiConfig{
-		fileserverHits: atomic.Int32{},
-		db:             dbQueries,
-		platform:       platform,
-		jwtSecret:      jwtSecret,
-		polkaKey:       polkaKey,
+		db:        dbQueries,
+		platform:  platform,
+		jwtSecret: jwtSecret,
+		polkaKey:  polkaKey,
+	}
+
+	// Cancelled on SIGINT/SIGTERM to begin a graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Internal event bus shared by all handler packages
+	eventBus := events.NewBus()
+
+	// Shared state backend: Redis when configured, in-memory otherwise
+	cacheStore, redisClient := initCache(cfg)
+	apiCfg.cache = cacheStore
+	apiCfg.fileserverHits = cache.NewCounter(cacheStore, "metrics:fileserver_hits")
+	if redisClient != nil {
+		events.NewRedisBridge(eventBus, redisClient, "chirpy:events").Start(ctx)
+	}
+
+	// Background jobs, outbound HTTP and email
+	jobRunner := jobs.NewRunner(time.Second, time.Minute)
+	jobRunner.Start(ctx)
+	outboundClient := httpclient.New(httpclient.DefaultConfig())
+	apiCfg.mailer = initMailer(cfg, jobRunner, outboundClient)
+
+	// Banned words are cached in memory; a failed load keeps the defaults
+	// until the reload job succeeds
+	bannedWords := profanity.New(dbQueries)
+	if err := bannedWords.Reload(ctx); err != nil {
+		log.Printf("Couldn't load banned words: %s", err)
 	}
 
 	// Initialize handler configs
 	apiCfg.adminConfig = admin.Config{
 		FileserverHits: apiCfg.fileserverHits,
 		DB:             dbQueries,
+		SQL:            db,
 		Platform:       platform,
+		JWTSecret:      jwtSecret,
+		Templates:      mailer.NewRenderer(),
+		RuntimeConfig:  cfg,
+		Store:          cacheStore,
+		Jobs:           jobRunner,
+		Mailer:         apiCfg.mailer,
+		Events:         eventBus,
+		Profanity:      bannedWords,
+		Logs:           logTail,
+		Done:           ctx.Done(),
+		PublicURL:      cfg.PublicURL,
+		InstanceName:   cfg.InstanceName,
+		CustomDomains:  cfg.CustomDomains,
 	}
 	apiCfg.chirpConfig = chirp.Config{
-		DB:        dbQueries,
-		JWTSecret: jwtSecret,
+		DB:             dbQueries,
+		JWTSecret:      jwtSecret,
+		RequireAltText: cfg.RequireAltText,
+		AllowEdits:     cfg.AllowChirpEdits,
+		Events:         eventBus,
+		Reactions:      validation.NewReactions(cfg.AllowedReactions),
+		Counting:       counting,
+
+		ArchiveAfterMonths: cfg.ArchiveAfterMonths,
+		SortableIDs:        cfg.SortableChirpIDs,
+		Views:              &chirp.ViewBuffer{},
+		Profanity:          bannedWords,
+		Store:              cacheStore,
+		Languages:          chirp.StopwordDetector{},
+		Jobs:               jobRunner,
 	}
+	if cfg.ChirpRateLimit > 0 {
+		apiCfg.chirpConfig.PostLimiter = ratelimit.New(cacheStore, cfg.ChirpRateLimit, time.Minute)
+	}
+	if cfg.ProbationPeriod > 0 {
+		apiCfg.chirpConfig.Entitlements = entitlements.Policy{Probation: cfg.ProbationPeriod}
+		if cfg.ProbationChirpRateLimit > 0 {
+			apiCfg.chirpConfig.ProbationLimiter = ratelimit.New(cacheStore, cfg.ProbationChirpRateLimit, time.Minute)
+		}
+	}
+	if len(cfg.LinkPreviewHosts) > 0 {
+		// Allowed names could still resolve to internal hosts, so previews
+		// are fetched with a client that refuses them, once per link
+		previewClient := httpclient.DefaultConfig()
+		previewClient.Timeout = cfg.LinkPreviewTimeout
+		previewClient.MaxRetries = 0
+		previewClient.PublicOnly = true
+		apiCfg.chirpConfig.Previews = linkpreview.New(cfg.LinkPreviewHosts, previewClient)
+	}
+	if cfg.ModerationClassifierURL != "" {
+		apiCfg.chirpConfig.Classifier = &moderation.Classifier{
+			URL:    cfg.ModerationClassifierURL,
+			Token:  cfg.ModerationClassifierToken,
+			Client: outboundClient,
+		}
+		apiCfg.chirpConfig.Mailer = apiCfg.mailer
+		apiCfg.chirpConfig.Templates = apiCfg.adminConfig.Templates
+	}
+	// Origins of imported account bundles come from users, so their keys
+	// are fetched with a client that can't reach internal hosts
+	publicOnly := httpclient.DefaultConfig()
+	publicOnly.PublicOnly = true
+	migrationClient := httpclient.New(publicOnly)
 	apiCfg.userConfig = user.Config{
+		DB:                   dbQueries,
+		JWTSecret:            jwtSecret,
+		Events:               eventBus,
+		ReservedHandles:      validation.NewReservedHandles(cfg.ReservedHandles),
+		RegistrationMode:     cfg.RegistrationMode,
+		Store:                cacheStore,
+		PublicURL:            cfg.PublicURL,
+		Counting:             counting,
+		Profanity:            bannedWords,
+		IndexImported:        apiCfg.chirpConfig.IndexImported,
+		Entitlements:         apiCfg.chirpConfig.Entitlements,
+		CustomDomains:        cfg.CustomDomains,
+		DeletionGracePeriod:  cfg.AccountDeletionGracePeriod,
+		FingerprintSecret:    cfg.FingerprintSecret,
+		FingerprintRetention: cfg.FingerprintRetention,
+		MigrationKeys: func(ctx context.Context, origin string) (ed25519.PublicKey, error) {
+			return migration.FetchKey(ctx, migrationClient, origin)
+		},
+	}
+	if cfg.MigrationSigningKey != "" {
+		key, err := migration.ParseKey(cfg.MigrationSigningKey)
+		if err != nil {
+			log.Fatalf("Invalid MIGRATION_SIGNING_KEY: %v", err)
+		}
+		apiCfg.userConfig.MigrationKey = key
+	}
+	if cfg.OIDCIssuer != "" {
+		if cfg.OIDCClientID == "" || cfg.OIDCClientSecret == "" {
+			log.Fatal("OIDC_CLIENT_ID and OIDC_CLIENT_SECRET must be set when OIDC_ISSUER is")
+		}
+		apiCfg.userConfig.SSO = &oidc.Provider{
+			Issuer:       cfg.OIDCIssuer,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			Client:       outboundClient,
+		}
+		apiCfg.userConfig.SSORedirectURL = cfg.OIDCRedirectURL
+		if apiCfg.userConfig.SSORedirectURL == "" && cfg.PublicURL != "" {
+			apiCfg.userConfig.SSORedirectURL = strings.TrimRight(cfg.PublicURL, "/") + "/api/sso/callback"
+		}
+		apiCfg.userConfig.SSOJITProvisioning = cfg.SSOJITProvisioning
+	}
+	apiCfg.firehoseConfig = firehose.Config{
+		DB:     dbQueries,
+		Events: eventBus,
+		Store:  cacheStore,
+		Done:   ctx.Done(),
+	}
+	apiCfg.scimConfig = scim.Config{
+		DB:     dbQueries,
+		Events: eventBus,
+	}
+	apiCfg.oauthConfig = oauth.Config{
 		DB:        dbQueries,
 		JWTSecret: jwtSecret,
 	}
 	apiCfg.middlewareConfig = middleware.Config{
 		FileserverHits: apiCfg.fileserverHits,
+		Version:        version.Get().Version,
+		Tenants: &tenant.Resolver{
+			DB:         dbQueries,
+			Enabled:    cfg.MultiTenant,
+			BaseDomain: cfg.TenantBaseDomain,
+
+			CustomDomains: cfg.CustomDomains,
+			PublicHost:    publicHost(cfg.PublicURL),
+		},
+		JWTSecret:  jwtSecret,
+		ActiveUser: dbQueries.IsUserActive,
+	}
+	if platform == "dev" {
+		apiCfg.middlewareConfig.FaultInjector = chaos.New()
+		apiCfg.adminConfig.Chaos = apiCfg.middlewareConfig.FaultInjector
+	}
+	if len(cfg.Rollout) > 0 {
+		flags, err := rollout.Parse(cfg.Rollout)
+		if err != nil {
+			log.Fatalf("Invalid ROLLOUT: %s", err)
+		}
+		apiCfg.middlewareConfig.Rollout = flags
+	}
+	if cfg.RequestTimeout > 0 || len(cfg.RouteTimeouts) > 0 {
+		budgets, err := timeouts.Parse(cfg.RouteTimeouts, cfg.RequestTimeout)
+		if err != nil {
+			log.Fatalf("Invalid ROUTE_TIMEOUTS: %s", err)
+		}
+		apiCfg.middlewareConfig.Timeouts = budgets
+	}
+	if cfg.LoadShedBacklog > 0 || cfg.LoadShedDBLatency > 0 {
+		specs := cfg.LoadShedPriorities
+		if len(specs) == 0 {
+			specs = loadshed.DefaultPriorities
+		}
+		priorities, err := loadshed.ParsePriorities(specs)
+		if err != nil {
+			log.Fatalf("Invalid LOAD_SHED_PRIORITIES: %s", err)
+		}
+		apiCfg.middlewareConfig.LoadShedder = &loadshed.Shedder{
+			Priorities: priorities,
+			Backlog:    jobRunner.Backlog,
+			MaxBacklog: cfg.LoadShedBacklog,
+			Latency:    db.Latency,
+			MaxLatency: cfg.LoadShedDBLatency,
+		}
+	}
+	if cfg.RateLimit > 0 {
+		apiCfg.middlewareConfig.RateLimiter = ratelimit.New(cacheStore, cfg.RateLimit, cfg.RateLimitWindow)
 	}
 
-	// Initialize webhook config
-	apiCfg.webhookConfig = webhook.Config{
-		DB:       dbQueries,
-		PolkaKey: polkaKey,
+	jobRunner.Every("purge-deactivated-users", time.Hour, apiCfg.userConfig.PurgeDeactivatedUsers)
+	jobRunner.Every("purge-deleted-accounts", time.Hour, apiCfg.userConfig.PurgeDeletedAccounts)
+	jobRunner.Every("purge-expired-refresh-tokens", time.Hour, apiCfg.userConfig.PurgeExpiredRefreshTokens)
+	jobRunner.Every("purge-stale-fingerprints", time.Hour, apiCfg.userConfig.PurgeStaleFingerprints)
+	jobRunner.Every("purge-expired-oauth-tokens", time.Hour, apiCfg.oauthConfig.PurgeExpiredTokens)
+	jobRunner.Every("generate-recaps", time.Hour, apiCfg.userConfig.GenerateRecaps)
+	jobRunner.Every("purge-deleted-chirps", time.Hour, apiCfg.chirpConfig.PurgeDeletedChirps)
+	jobRunner.Every("reconcile-chirp-counters", 24*time.Hour, apiCfg.chirpConfig.ReconcileCounters)
+	jobRunner.Every("flush-chirp-views", 10*time.Second, apiCfg.chirpConfig.FlushViews)
+	jobRunner.Every("publish-scheduled-chirps", time.Minute, apiCfg.chirpConfig.PublishScheduledChirps)
+	jobRunner.Every("reload-banned-words", time.Minute, bannedWords.Reload)
+	if cfg.CustomDomains {
+		verifier := &domains.Verifier{DB: dbQueries, LookupTXT: net.DefaultResolver.LookupTXT}
+		jobRunner.Every("verify-custom-domains", 5*time.Minute, verifier.Run)
 	}
-	apiCfg.chirpConfig = chirp.Config{
-		DB:        dbQueries,
-		JWTSecret: jwtSecret,
+	if cfg.ArchiveAfterMonths > 0 {
+		jobRunner.Every("archive-old-chirps", time.Hour, apiCfg.chirpConfig.ArchiveOldChirps)
 	}
-	apiCfg.userConfig = user.Config{
+	if cfg.RetentionRevokedTokens > 0 || cfg.RetentionAuditLog > 0 {
+		retentionEngine := retention.New(dbQueries, retention.MaxAges{
+			RevokedTokens: cfg.RetentionRevokedTokens,
+			AuditLog:      cfg.RetentionAuditLog,
+		}, cfg.RetentionDryRun)
+		retentionEngine.Store, retentionEngine.Interval = cacheStore, 24*time.Hour
+		jobRunner.Every("apply-retention-policies", retentionEngine.Interval, retentionEngine.Run)
+	}
+	if cfg.BackupDir != "" {
+		apiCfg.adminConfig.Backups = newBackupManager(cfg, cacheStore)
+		jobRunner.Every("backup-database", cfg.BackupInterval, apiCfg.adminConfig.Backups.Run)
+	}
+	if cfg.AlertWebhookURL != "" || len(cfg.AlertEmail) > 0 {
+		apiCfg.middlewareConfig.ServerErrors = cache.NewCounter(cacheStore, "metrics:server_errors")
+		apiCfg.middlewareConfig.Metrics = metrics.NewRegistry()
+		monitor := initAlerts(cfg, cacheStore, apiCfg.middlewareConfig.ServerErrors, apiCfg.middlewareConfig.Metrics, outboundClient, apiCfg.mailer)
+		jobRunner.Every("check-alerts", cfg.AlertCheckInterval, monitor.Check)
+	}
+
+	apiCfg.instanceConfig = instance.Config{
+		Name:             cfg.InstanceName,
+		Description:      cfg.InstanceDescription,
+		Version:          version.Get().Version,
+		RegistrationMode: apiCfg.userConfig.RegistrationMode,
+		Features: types.InstanceFeatures{
+			ChirpEdits:     apiCfg.chirpConfig.AllowEdits,
+			RequireAltText: apiCfg.chirpConfig.RequireAltText,
+			Media:          true,
+			MutedWords:     true,
+			UndoSend:       true,
+			Usernames:      true,
+			VerifiedBadges: true,
+			MultiTenant:    cfg.MultiTenant,
+			Reactions:      true,
+			Sensitive:      true,
+			Likes:          true,
+			SSO:            apiCfg.userConfig.SSO != nil,
+			CustomDomains:  cfg.CustomDomains,
+		},
+		Reactions: apiCfg.chirpConfig.Reactions,
+		Counting:  apiCfg.chirpConfig.Counting,
+	}
+	if apiCfg.userConfig.MigrationKey != nil && cfg.PublicURL != "" {
+		apiCfg.instanceConfig.MigrationKey = migration.PublicKey(apiCfg.userConfig.MigrationKey)
+	}
+
+	apiCfg.bootstrapConfig = bootstrap.Config{
 		DB:        dbQueries,
 		JWTSecret: jwtSecret,
+		Users:     &apiCfg.userConfig,
+		Chirps:    &apiCfg.chirpConfig,
 	}
-	apiCfg.middlewareConfig = middleware.Config{
-		FileserverHits: apiCfg.fileserverHits,
+
+	// Initialize webhook config. Moderation webhook URLs are chosen by
+	// tenant admins, so they get their own client that can't reach
+	// internal hosts either.
+	apiCfg.webhookConfig = webhook.Config{
+		DB:       dbQueries,
+		PolkaKey: polkaKey,
+		Events:   eventBus,
+		Client:   httpclient.New(publicOnly),
+		Jobs:     jobRunner,
+	}
+	// Moderation webhooks are delivered for as long as the server runs
+	apiCfg.webhookConfig.SubscribeModeration()
+	if cfg.WebhookJournal != "" {
+		upgrades, err := journal.Open(cfg.WebhookJournal)
+		if err != nil {
+			log.Fatalf("Invalid WEBHOOK_JOURNAL: %s", err)
+		}
+		defer upgrades.Close()
+		apiCfg.webhookConfig.Journal = upgrades
 	}
 
 	// Setup HTTP router
 	mux := setupRouter(apiCfg)
 
-	// Start server
-	startServer(mux)
+	// Start server, then let background work finish once it has drained
+	var handler http.Handler = apiCfg.middlewareConfig.DataLoaders(mux)
+	handler = apiCfg.middlewareConfig.Scopes(handler)
+	handler = apiCfg.middlewareConfig.Variant(handler)
+	handler = apiCfg.middlewareConfig.Tenant(handler)
+	handler = apiCfg.middlewareConfig.RateLimit(handler)
+	handler = apiCfg.middlewareConfig.Authenticate(handler)
+	handler = apiCfg.middlewareConfig.Chaos(handler)
+	handler = apiCfg.middlewareConfig.Shed(handler)
+	handler = apiCfg.middlewareConfig.Timeout(handler)
+	handler = apiCfg.middlewareConfig.VersionHeader(handler)
+	if apiCfg.middlewareConfig.ServerErrors != nil {
+		handler = apiCfg.middlewareConfig.CountServerErrors(handler)
+	}
+	if apiCfg.middlewareConfig.Metrics != nil {
+		handler = apiCfg.middlewareConfig.RecordMetrics(handler)
+	}
+	if cfg.LogRequests {
+		handler = apiCfg.middlewareConfig.RequestLog(handler)
+	}
+	startServer(ctx, cfg, handler)
+	jobRunner.Wait()
+	// Views counted since the last flush would otherwise be lost
+	if err := apiCfg.chirpConfig.FlushViews(context.Background()); err != nil {
+		log.Printf("Couldn't flush chirp views: %s", err)
+	}
+	eventBus.Wait()
+	log.Println("Shutdown complete")
 }
 
-func initDatabase() (*database.Queries, string, string, string) {
-	godotenv.Load()
-	dbURL := os.Getenv("DB_URL")
-	if dbURL == "" {
-		log.Fatal("DB_URL must be set")
+// runCommand runs a maintenance subcommand instead of the server and returns
+// the process exit code
+func runCommand(cfg *config.Config, args []string) int {
+	switch args[0] {
+	case "restore":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, "usage: chirpy restore <backup>")
+			return 2
+		}
+		if cfg.BackupDir == "" {
+			fmt.Fprintln(os.Stderr, "BACKUP_DIR must be set to restore a backup")
+			return 1
+		}
+		if err := newBackupManager(cfg, nil).Restore(context.Background(), args[1]); err != nil {
+			fmt.Fprintf(os.Stderr, "Restore failed: %s\n", err)
+			return 1
+		}
+		fmt.Printf("Restored %s\n", args[1])
+		return 0
+	case "replay-journal":
+		if len(args) < 2 || len(args) > 3 {
+			fmt.Fprintln(os.Stderr, "usage: chirpy replay-journal <journal> [since]")
+			return 2
+		}
+		var since time.Time
+		if len(args) == 3 {
+			var err error
+			if since, err = time.Parse(time.RFC3339, args[2]); err != nil {
+				fmt.Fprintln(os.Stderr, "since must be an RFC 3339 time")
+				return 2
+			}
+		}
+		return replayJournal(cfg, args[1], since)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q, expected restore or replay-journal\n", args[0])
+		return 2
 	}
+}
 
-	platform := os.Getenv("PLATFORM")
-	if platform == "" {
-		log.Fatal("PLATFORM must be set")
+// replayJournal reapplies the webhook journal entries recorded at or after
+// since to the database
+func replayJournal(cfg *config.Config, path string, since time.Time) int {
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Couldn't open journal: %s\n", err)
+		return 1
+	}
+	defer file.Close()
+	entries, err := journal.Read(file, since)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Couldn't read journal: %s\n", err)
+		return 1
 	}
 
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		log.Fatal("JWT_SECRET must be set")
+	webhookConfig := webhook.Config{DB: database.New(initDatabase(cfg.DBURL, cfg.SlowQueryThreshold))}
+	result, err := webhookConfig.Replay(context.Background(), entries)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Replay failed after %d entries: %s\n", result.Applied+result.Skipped, err)
+		return 1
 	}
+	fmt.Printf("Replayed %d entries, skipped %d for users that no longer exist\n", result.Applied, result.Skipped)
+	return 0
+}
 
-	polkaKey := os.Getenv("POLKA_KEY")
-	if polkaKey == "" {
-		log.Fatal("POLKA_KEY must be set")
+// newBackupManager configures backups of the database into BACKUP_DIR. The
+// cache store keeps replicas sharing a directory from backing up twice.
+func newBackupManager(cfg *config.Config, store cache.Store) *backup.Manager {
+	return &backup.Manager{
+		DBURL:    cfg.DBURL,
+		Dir:      cfg.BackupDir,
+		Keep:     cfg.BackupKeep,
+		Store:    store,
+		Interval: cfg.BackupInterval,
 	}
+}
 
-	db, err := sql.Open("postgres", dbURL)
+// publicHost returns the host name of PUBLIC_URL, or "" when it is unset
+func publicHost(publicURL string) string {
+	parsed, err := url.Parse(publicURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
+
+// initDatabase opens the database behind a query logger that reports
+// queries slower than slowQuery and counts queries per request
+func initDatabase(dbURL string, slowQuery time.Duration) *querylog.DB {
+	db, err := sql.Open("postgres", utcSession(dbURL))
 	if err != nil {
 		log.Fatalf("Error opening database: %s", err)
 	}
 
-	return database.New(db), platform, jwtSecret, polkaKey
+	return querylog.New(db, slowQuery)
+}
+
+// utcSession pins the session time zone to UTC unless DB_URL sets one.
+// Timestamp columns have no zone, so NOW() defaults would otherwise be
+// stored in the database server's local time.
+func utcSession(dbURL string) string {
+	if strings.Contains(strings.ToLower(dbURL), "timezone=") {
+		return dbURL
+	}
+	parsed, err := url.Parse(dbURL)
+	if err != nil || parsed.Scheme == "" {
+		// key=value connection string
+		return dbURL + " timezone=UTC"
+	}
+	query := parsed.Query()
+	query.Set("timezone", "UTC")
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+// initCache connects to Redis when REDIS_URL is set so that replicas share
+// state, and falls back to an in-memory store otherwise. In cluster mode the
+// in-memory fallback is refused, since each replica would keep its own state.
+func initCache(cfg *config.Config) (cache.Store, *redis.Client) {
+	redisURL := cfg.RedisURL
+	if redisURL == "" {
+		if cfg.ClusterMode {
+			log.Fatal("REDIS_URL must be set when CLUSTER_MODE is enabled")
+		}
+		return cache.NewMemory(), nil
+	}
+
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		log.Fatalf("Error parsing REDIS_URL: %s", err)
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		log.Fatalf("Error connecting to Redis: %s", err)
+	}
+
+	log.Printf("Using Redis at %s for shared state", opts.Addr)
+	return cache.NewRedis(client, "chirpy:"), client
+}
+
+// initMailer selects the email backend from MAILER (log, smtp or ses).
+// Real backends send through the job runner so failures are retried.
+func initMailer(cfg *config.Config, runner *jobs.Runner, client *httpclient.Client) mailer.Mailer {
+	from := cfg.MailFrom
+
+	var backend mailer.Mailer
+	switch cfg.Mailer {
+	case "", "log":
+		return &mailer.LogMailer{Out: os.Stdout}
+	case "smtp":
+		backend = &mailer.SMTPMailer{
+			Addr:     cfg.SMTPAddr,
+			From:     from,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+		}
+	case "ses":
+		backend = &mailer.SESMailer{
+			Client:          client,
+			Region:          cfg.AWSRegion,
+			From:            from,
+			AccessKeyID:     cfg.AWSAccessKeyID,
+			SecretAccessKey: cfg.AWSSecretAccessKey,
+			SessionToken:    cfg.AWSSessionToken,
+		}
+	default:
+		log.Fatalf("Unknown MAILER %q, expected log, smtp or ses", cfg.Mailer)
+	}
+
+	if from == "" {
+		log.Fatal("MAIL_FROM must be set when MAILER is smtp or ses")
+	}
+	return &mailer.QueuedMailer{Mailer: backend, Runner: runner, MaxAttempts: 5}
+}
+
+// minErrorRateRequests keeps a handful of failures during a quiet interval
+// from raising the error rate alert
+const minErrorRateRequests = 20
+
+// initAlerts builds the monitor that posts to ALERT_WEBHOOK_URL and emails
+// ALERT_EMAIL when 5xx responses, failed outbound requests, the error rate,
+// p95 latency or a run of failing Polka webhooks cross their thresholds. A
+// threshold of 0 turns its rule off. Alerts are posted with their own client
+// so a failing webhook doesn't count towards the outbound failures it
+// reports.
+func initAlerts(cfg *config.Config, store cache.Store, serverErrors *cache.Counter, registry *metrics.Registry, outbound *httpclient.Client, mail mailer.Mailer) *alerts.Monitor {
+	monitor := &alerts.Monitor{
+		WebhookURL: cfg.AlertWebhookURL,
+		Client:     httpclient.New(httpclient.DefaultConfig()),
+		Mailer:     mail,
+		Templates:  mailer.NewRenderer(),
+		EmailTo:    cfg.AlertEmail,
+		Store:      store,
+		Interval:   cfg.AlertCheckInterval,
+	}
+	if cfg.AlertServerErrors > 0 {
+		monitor.Rules = append(monitor.Rules, alerts.Rule{
+			Name:      "5xx responses",
+			Threshold: int64(cfg.AlertServerErrors),
+			Count:     serverErrors.Value,
+		})
+	}
+	if cfg.AlertOutboundFailures > 0 {
+		monitor.Rules = append(monitor.Rules, alerts.Rule{
+			Name:      "Failed outbound requests",
+			Threshold: int64(cfg.AlertOutboundFailures),
+			Count: func(context.Context) (int64, error) {
+				var failed int64
+				for _, stats := range outbound.Stats() {
+					failed += stats.Errors + stats.ServerErrors
+				}
+				return failed, nil
+			},
+		})
+	}
+	if cfg.AlertErrorRate > 0 {
+		window := registry.Window()
+		monitor.Rules = append(monitor.Rules, alerts.Rule{
+			Name:      "5xx error rate",
+			Threshold: int64(cfg.AlertErrorRate),
+			Unit:      "%",
+			Level: func(context.Context) (float64, error) {
+				served := window.Next()
+				if served.Requests < minErrorRateRequests {
+					return 0, nil
+				}
+				return served.ErrorRate(), nil
+			},
+		})
+	}
+	if cfg.AlertP95Latency > 0 {
+		window := registry.Window()
+		monitor.Rules = append(monitor.Rules, alerts.Rule{
+			Name:      "p95 latency",
+			Threshold: cfg.AlertP95Latency.Milliseconds(),
+			Unit:      "ms",
+			Level: func(context.Context) (float64, error) {
+				return float64(window.Next().Quantile(0.95).Milliseconds()), nil
+			},
+		})
+	}
+	if cfg.AlertWebhookFailures > 0 {
+		monitor.Rules = append(monitor.Rules, alerts.Rule{
+			Name:      "Polka webhook failures in a row",
+			Threshold: int64(cfg.AlertWebhookFailures),
+			Level: func(context.Context) (float64, error) {
+				return float64(registry.WebhookFailureStreak()), nil
+			},
+		})
+	}
+	return monitor
 }
 
 func setupRouter(apiCfg *apiConfig) *http.ServeMux {
@@ -134,29 +723,124 @@ func setupRouter(apiCfg *apiConfig) *http.ServeMux {
 
 	// API endpoints
 	mux.HandleFunc("/api/healthz", handlers.HandlerReadiness)
-	mux.HandleFunc("/api/chirps", apiCfg.chirpConfig.HandlerCreate)
+	mux.HandleFunc("/api/instance", apiCfg.instanceConfig.HandlerInstance)
+	mux.HandleFunc("/api/version", handlers.HandlerVersion)
+	mux.HandleFunc("/api/chirps", apiCfg.chirpConfig.HandlerChirps)
+	mux.HandleFunc("/api/feed", apiCfg.chirpConfig.HandlerFeed)
+	mux.HandleFunc("/api/chirps/delete", apiCfg.chirpConfig.HandlerBatchDelete)
+	mux.HandleFunc("/api/chirps/poll", apiCfg.chirpConfig.HandlerPoll)
+	mux.HandleFunc("/api/chirps/search", apiCfg.chirpConfig.HandlerSearch)
+	mux.HandleFunc("/api/chirps/validate", apiCfg.chirpConfig.HandlerValidate)
 	mux.HandleFunc("/api/chirps/", apiCfg.chirpConfig.HandlerByID)
+	mux.HandleFunc("/api/drafts", apiCfg.chirpConfig.HandlerDrafts)
+	mux.HandleFunc("/api/drafts/", apiCfg.chirpConfig.HandlerDrafts)
+	mux.HandleFunc("/api/scheduled-chirps", apiCfg.chirpConfig.HandlerScheduledChirps)
+	mux.HandleFunc("/api/scheduled-chirps/", apiCfg.chirpConfig.HandlerScheduledChirps)
+	mux.HandleFunc("/api/hashtags/trending", apiCfg.chirpConfig.HandlerTrendingHashtags)
+	mux.HandleFunc("/api/appeals", apiCfg.chirpConfig.HandlerAppeals)
+	mux.HandleFunc("/api/firehose", apiCfg.firehoseConfig.HandlerFirehose)
+	mux.HandleFunc("/scim/v2/Users", apiCfg.scimConfig.HandlerUsers)
+	mux.HandleFunc("/scim/v2/Users/", apiCfg.scimConfig.HandlerUsers)
+	mux.HandleFunc("/api/bootstrap", apiCfg.bootstrapConfig.HandlerBootstrap)
 	mux.HandleFunc("/api/users", apiCfg.userConfig.HandlerUsers)
+	mux.HandleFunc("/api/users/me", apiCfg.userConfig.HandlerDeleteAccount)
+	mux.HandleFunc("/api/users/me/domain", apiCfg.userConfig.HandlerCustomDomain)
+	mux.HandleFunc("/api/users/me/migration", apiCfg.userConfig.HandlerMigration)
+	mux.HandleFunc("/api/users/me/muted-words", apiCfg.userConfig.HandlerMutedWords)
+	mux.HandleFunc("/api/users/me/muted-words/", apiCfg.userConfig.HandlerMutedWords)
+	mux.HandleFunc("/api/users/me/preferences", apiCfg.userConfig.HandlerPreferences)
+	mux.HandleFunc("/api/users/me/deactivate", apiCfg.userConfig.HandlerDeactivate)
+	mux.HandleFunc("/api/users/me/coauthor-invites", apiCfg.userConfig.HandlerCoauthorInvites)
+	mux.HandleFunc("/api/users/me/recap", apiCfg.userConfig.HandlerRecap)
+	mux.HandleFunc("/api/users/me/chirps/export", apiCfg.chirpConfig.HandlerExport)
+	mux.HandleFunc("/api/users/me/linked-accounts", apiCfg.userConfig.HandlerLinkedAccounts)
+	mux.HandleFunc("/api/users/me/linked-accounts/", apiCfg.userConfig.HandlerLinkedAccounts)
+	mux.HandleFunc("/api/users/by-username/", apiCfg.userConfig.HandlerUserByUsername)
+	mux.HandleFunc("/api/users/", apiCfg.chirpConfig.HandlerUsers)
 	mux.HandleFunc("/api/login", apiCfg.userConfig.HandlerLogin)
 	mux.HandleFunc("/api/refresh", apiCfg.userConfig.HandlerRefresh)
 	mux.HandleFunc("/api/revoke", apiCfg.userConfig.HandlerRevoke)
+	mux.HandleFunc("/api/password-reset", apiCfg.userConfig.HandlerPasswordReset)
+	mux.HandleFunc("/api/sso/login", apiCfg.userConfig.HandlerSSOLogin)
+	mux.HandleFunc("/api/sso/callback", apiCfg.userConfig.HandlerSSOCallback)
+	mux.HandleFunc("/api/sso/token", apiCfg.userConfig.HandlerSSOToken)
+	mux.HandleFunc("/api/oauth/authorize", apiCfg.oauthConfig.HandlerAuthorize)
+	mux.HandleFunc("/api/oauth/token", apiCfg.oauthConfig.HandlerToken)
+	mux.HandleFunc("/api/oauth/clients", apiCfg.oauthConfig.HandlerClients)
+	mux.HandleFunc("/api/oauth/clients/", apiCfg.oauthConfig.HandlerClientByID)
+	mux.HandleFunc("/api/oauth/grants", apiCfg.oauthConfig.HandlerGrants)
+	mux.HandleFunc("/api/oauth/grants/", apiCfg.oauthConfig.HandlerGrantByID)
 	mux.HandleFunc("/api/polka/webhooks", apiCfg.webhookConfig.HandlerPolkaWebhooks)
 
 	// Admin endpoints
 	mux.HandleFunc("/admin/metrics", apiCfg.adminConfig.HandlerMetrics)
 	mux.HandleFunc("/admin/reset", apiCfg.adminConfig.HandlerReset)
+	mux.HandleFunc("/admin/templates/preview/", apiCfg.adminConfig.HandlerTemplatePreview)
+	mux.HandleFunc("/admin/users/", apiCfg.adminConfig.HandlerUsers)
+	mux.HandleFunc("/admin/chirps/", apiCfg.adminConfig.HandlerChirps)
+	mux.HandleFunc("/admin/reports", apiCfg.adminConfig.HandlerReports)
+	mux.HandleFunc("/admin/reports/", apiCfg.adminConfig.HandlerReports)
+	mux.HandleFunc("/admin/verdicts", apiCfg.adminConfig.HandlerVerdicts)
+	mux.HandleFunc("/admin/verdicts/", apiCfg.adminConfig.HandlerVerdicts)
+	mux.HandleFunc("/admin/appeals", apiCfg.adminConfig.HandlerAppeals)
+	mux.HandleFunc("/admin/appeals/", apiCfg.adminConfig.HandlerAppeals)
+	mux.HandleFunc("/admin/ban-evasion", apiCfg.adminConfig.HandlerBanEvasion)
+	mux.HandleFunc("/admin/config", apiCfg.adminConfig.HandlerConfig)
+	mux.HandleFunc("/admin/banned-words", apiCfg.adminConfig.HandlerBannedWords)
+	mux.HandleFunc("/admin/banned-words/", apiCfg.adminConfig.HandlerBannedWords)
+	mux.HandleFunc("/admin/db/analyze", apiCfg.adminConfig.HandlerAnalyze)
+	mux.HandleFunc("/admin/tenants", apiCfg.adminConfig.HandlerTenants)
+	mux.HandleFunc("/admin/domains", apiCfg.adminConfig.HandlerDomains)
+	mux.HandleFunc("/admin/domains/", apiCfg.adminConfig.HandlerDomains)
+	mux.HandleFunc("/admin/clients", apiCfg.adminConfig.HandlerClients)
+	mux.HandleFunc("/admin/backups", apiCfg.adminConfig.HandlerBackups)
+	mux.HandleFunc("/admin/chaos", apiCfg.adminConfig.HandlerChaos)
+	mux.HandleFunc("/admin/logs/stream", apiCfg.adminConfig.HandlerLogStream)
+	mux.HandleFunc("/admin/api-keys", apiCfg.adminConfig.HandlerAPIKeys)
+	mux.HandleFunc("/admin/api-keys/", apiCfg.adminConfig.HandlerAPIKeys)
+	mux.HandleFunc("/admin/webhooks", apiCfg.adminConfig.HandlerWebhooks)
+	mux.HandleFunc("/admin/webhooks/", apiCfg.adminConfig.HandlerWebhooks)
 
 	return mux
 }
 
-func startServer(handler http.Handler) {
+// startServer serves until ctx is cancelled, then stops accepting new
+// connections and waits up to SHUTDOWN_TIMEOUT for in-flight requests. The
+// socket is inherited from systemd when socket-activated, or bound with
+// SO_REUSEPORT when REUSE_PORT=true, so a replacement process can take over
+// without connections being refused.
+func startServer(ctx context.Context, cfg *config.Config, handler http.Handler) {
+	listener, err := listen.Listen(ctx, fmt.Sprintf(":%d", port), listen.Options{
+		ReusePort: cfg.ReusePort,
+	})
+	if err != nil {
+		log.Fatalf("Error creating listener: %s", err)
+	}
+
 	server := &http.Server{
-		Addr:    fmt.Sprintf(":%d", port),
 		Handler: handler,
 	}
 
-	log.Printf("Serving on port %d", port)
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatal(err)
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+	log.Printf("Serving on %s", listener.Addr())
+
+	select {
+	case err := <-serveErr:
+		if err != nil && err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
+		return
+	case <-ctx.Done():
+	}
+
+	timeout := cfg.ShutdownTimeout
+	log.Printf("Shutting down, waiting up to %s for in-flight requests", timeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error during shutdown: %s", err)
 	}
 }
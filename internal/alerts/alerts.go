@@ -0,0 +1,225 @@
+// Package alerts watches operational counters and levels and notifies
+// operators in a Slack or Discord channel, by email or both when they cross
+// a threshold.
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
+	"github.com/kai-xlr/neo_chirpy/internal/httpclient"
+	"github.com/kai-xlr/neo_chirpy/internal/mailer"
+)
+
+// Rule raises an alert when a counter grows by Threshold or more between two
+// checks. Count returns the counter's running total. A rule with Level
+// instead compares the value Level returns on each check with Threshold,
+// in Unit.
+type Rule struct {
+	Name      string
+	Threshold int64
+	Count     func(ctx context.Context) (int64, error)
+	Level     func(ctx context.Context) (float64, error)
+	Unit      string
+}
+
+// Monitor evaluates rules on every Check and notifies operators when a rule
+// starts or stops firing
+type Monitor struct {
+	Rules []Rule
+
+	// WebhookURL receives alerts through Client; empty posts nowhere
+	WebhookURL string
+	Client     *httpclient.Client
+
+	// Mailer emails alerts rendered from Templates to EmailTo; nil or no
+	// recipients sends no email
+	Mailer    mailer.Mailer
+	Templates *mailer.Renderer
+	EmailTo   []string
+
+	// Store deduplicates messages when several replicas run the same
+	// checks against shared counters; nil sends every message
+	Store    cache.Store
+	Interval time.Duration
+
+	mu     sync.Mutex
+	last   map[string]int64
+	firing map[string]bool
+}
+
+// Check evaluates every rule once. The first check only records the
+// counters' starting values.
+func (m *Monitor) Check(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	first := m.last == nil
+	if first {
+		m.last = make(map[string]int64)
+		m.firing = make(map[string]bool)
+	}
+
+	var errs []string
+	for _, rule := range m.Rules {
+		var firing bool
+		var current, threshold string
+		if rule.Level != nil {
+			level, err := rule.Level(ctx)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", rule.Name, err))
+				continue
+			}
+			firing = level >= float64(rule.Threshold)
+			current = formatLevel(level, rule.Unit)
+			threshold = formatLevel(float64(rule.Threshold), rule.Unit)
+		} else {
+			total, err := rule.Count(ctx)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", rule.Name, err))
+				continue
+			}
+			previous, seen := m.last[rule.Name]
+			m.last[rule.Name] = total
+			if first || !seen {
+				continue
+			}
+
+			// Counters reset on restart or by an admin; treat that as a fresh start
+			delta := total - previous
+			if delta < 0 {
+				delta = total
+			}
+			firing = delta >= rule.Threshold
+			current = fmt.Sprintf("%d in the last %s", delta, m.Interval)
+			threshold = strconv.FormatInt(rule.Threshold, 10)
+		}
+
+		var alert Alert
+		switch {
+		case firing && !m.firing[rule.Name]:
+			alert = Alert{Rule: rule.Name, Firing: true, Value: current, Threshold: threshold}
+		case !firing && m.firing[rule.Name]:
+			alert = Alert{Rule: rule.Name, Value: current, Threshold: threshold}
+		default:
+			continue
+		}
+
+		if err := m.notify(ctx, alert); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", rule.Name, err))
+			continue
+		}
+		m.firing[rule.Name] = !m.firing[rule.Name]
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("alert checks failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Alert is a rule starting or stopping firing, with the value that made it
+// change and the rule's threshold, formatted for people
+type Alert struct {
+	Rule      string
+	Firing    bool
+	Value     string
+	Threshold string
+}
+
+// Text describes the alert in one line for a chat channel
+func (a Alert) Text() string {
+	if a.Firing {
+		return fmt.Sprintf(":rotating_light: %s: %s (threshold %s)", a.Rule, a.Value, a.Threshold)
+	}
+	return fmt.Sprintf(":white_check_mark: %s back to normal: %s", a.Rule, a.Value)
+}
+
+// notify posts the alert to the webhook and emails it unless another
+// replica already sent the same alert during this interval
+func (m *Monitor) notify(ctx context.Context, alert Alert) error {
+	if m.Store != nil && m.Interval > 0 {
+		key := "alerts:" + alert.Rule + ":" + strconv.FormatBool(alert.Firing)
+		first, err := cache.OncePer(ctx, m.Store, key, m.Interval)
+		if err != nil || !first {
+			return err
+		}
+	}
+
+	var errs []error
+	if m.WebhookURL != "" {
+		errs = append(errs, post(ctx, m.Client, m.WebhookURL, alert.Text()))
+	}
+	if m.Mailer != nil && len(m.EmailTo) > 0 {
+		errs = append(errs, m.email(ctx, alert))
+	}
+	return errors.Join(errs...)
+}
+
+// email sends the alert to every operator address
+func (m *Monitor) email(ctx context.Context, alert Alert) error {
+	msg, err := m.Templates.Render("operator-alert", "", m.EmailTo, alert)
+	if err != nil {
+		return err
+	}
+	return m.Mailer.Send(ctx, msg)
+}
+
+// formatLevel writes a level to one decimal place with its unit, dropping
+// needless decimals
+func formatLevel(value float64, unit string) string {
+	return strconv.FormatFloat(math.Round(value*10)/10, 'f', -1, 64) + unit
+}
+
+// post sends a message to a Slack or Discord incoming webhook. Discord is
+// recognised by its host; any other URL gets Slack's payload format, which
+// Mattermost and Rocket.Chat also accept.
+func post(ctx context.Context, client *httpclient.Client, webhookURL, text string) error {
+	body, err := messageBody(webhookURL, text)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// messageBody encodes text in the payload format of the webhook's service
+func messageBody(webhookURL, text string) ([]byte, error) {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil {
+		return nil, err
+	}
+
+	field := "text"
+	if host := parsed.Hostname(); host == "discord.com" || host == "discordapp.com" {
+		field = "content"
+	}
+	return json.Marshal(map[string]string{field: text})
+}
@@ -0,0 +1,189 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
+	"github.com/kai-xlr/neo_chirpy/internal/httpclient"
+	"github.com/kai-xlr/neo_chirpy/internal/mailer"
+)
+
+// webhookRecorder collects the messages posted to a test webhook
+type webhookRecorder struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (rec *webhookRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]string
+	json.NewDecoder(r.Body).Decode(&payload)
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.messages = append(rec.messages, payload["text"])
+}
+
+func (rec *webhookRecorder) sent() []string {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]string(nil), rec.messages...)
+}
+
+func TestMonitorFiresAndResolves(t *testing.T) {
+	rec := &webhookRecorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	var total int64
+	monitor := &Monitor{
+		Rules: []Rule{{
+			Name:      "5xx responses",
+			Threshold: 10,
+			Count:     func(context.Context) (int64, error) { return total, nil },
+		}},
+		WebhookURL: server.URL,
+		Client:     httpclient.New(httpclient.DefaultConfig()),
+		Interval:   time.Minute,
+	}
+
+	ctx := context.Background()
+	// Each step adds to the counter, then runs a check
+	for _, step := range []int64{100, 3, 12, 20, 2, 1} {
+		total += step
+		if err := monitor.Check(ctx); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+	}
+
+	messages := rec.sent()
+	if len(messages) != 2 {
+		t.Fatalf("sent %d messages, want 2: %q", len(messages), messages)
+	}
+	if !strings.Contains(messages[0], "5xx responses: 12 in the last 1m0s") {
+		t.Errorf("alert = %q", messages[0])
+	}
+	if !strings.Contains(messages[1], "back to normal: 2") {
+		t.Errorf("resolution = %q", messages[1])
+	}
+}
+
+func TestMonitorDeduplicatesAcrossReplicas(t *testing.T) {
+	rec := &webhookRecorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	store := cache.NewMemory()
+	var total int64
+	newMonitor := func() *Monitor {
+		return &Monitor{
+			Rules: []Rule{{
+				Name:      "5xx responses",
+				Threshold: 1,
+				Count:     func(context.Context) (int64, error) { return total, nil },
+			}},
+			WebhookURL: server.URL,
+			Client:     httpclient.New(httpclient.DefaultConfig()),
+			Store:      store,
+			Interval:   time.Hour,
+		}
+	}
+	replicas := []*Monitor{newMonitor(), newMonitor()}
+
+	ctx := context.Background()
+	for _, replica := range replicas {
+		replica.Check(ctx)
+	}
+	total += 5
+	for _, replica := range replicas {
+		if err := replica.Check(ctx); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+	}
+
+	if messages := rec.sent(); len(messages) != 1 {
+		t.Errorf("sent %d messages, want 1: %q", len(messages), messages)
+	}
+}
+
+// mailRecorder collects the emails a monitor sends
+type mailRecorder struct {
+	messages []mailer.Message
+}
+
+func (rec *mailRecorder) Send(_ context.Context, msg mailer.Message) error {
+	rec.messages = append(rec.messages, msg)
+	return nil
+}
+
+func TestMonitorLevelRuleEmails(t *testing.T) {
+	mail := &mailRecorder{}
+	var p95 float64
+	monitor := &Monitor{
+		Rules: []Rule{{
+			Name:      "p95 latency",
+			Threshold: 500,
+			Level:     func(context.Context) (float64, error) { return p95, nil },
+			Unit:      "ms",
+		}},
+		Mailer:    mail,
+		Templates: mailer.NewRenderer(),
+		EmailTo:   []string{"ops@example.com"},
+		Interval:  time.Minute,
+	}
+
+	ctx := context.Background()
+	// Levels are compared as they are, from the first check on
+	for _, level := range []float64{1000, 2500, 250} {
+		p95 = level
+		if err := monitor.Check(ctx); err != nil {
+			t.Fatalf("Check() error = %v", err)
+		}
+	}
+
+	if len(mail.messages) != 2 {
+		t.Fatalf("sent %d emails, want 2", len(mail.messages))
+	}
+	firing, resolved := mail.messages[0], mail.messages[1]
+	if firing.Subject != "Chirpy alert: p95 latency" || !strings.Contains(firing.TextBody, "Value: 1000ms") || !strings.Contains(firing.TextBody, "Threshold: 500ms") {
+		t.Errorf("alert email = %q\n%s", firing.Subject, firing.TextBody)
+	}
+	if resolved.Subject != "Chirpy alert resolved: p95 latency" || !strings.Contains(resolved.TextBody, "Value: 250ms") {
+		t.Errorf("resolution email = %q\n%s", resolved.Subject, resolved.TextBody)
+	}
+}
+
+func TestFormatLevel(t *testing.T) {
+	if got := formatLevel(100.0/7, "%"); got != "14.3%" {
+		t.Errorf("formatLevel() = %q, want 14.3%%", got)
+	}
+	if got := formatLevel(4, ""); got != "4" {
+		t.Errorf("formatLevel() = %q, want 4", got)
+	}
+}
+
+func TestMessageBody(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "https://hooks.slack.com/services/T000/B000/XXXX", want: `{"text":"hi"}`},
+		{url: "https://discord.com/api/webhooks/1/abc", want: `{"content":"hi"}`},
+		{url: "https://discordapp.com/api/webhooks/1/abc", want: `{"content":"hi"}`},
+	}
+
+	for _, tt := range tests {
+		body, err := messageBody(tt.url, "hi")
+		if err != nil {
+			t.Fatalf("messageBody(%q) error = %v", tt.url, err)
+		}
+		if string(body) != tt.want {
+			t.Errorf("messageBody(%q) = %s, want %s", tt.url, body, tt.want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package auth
+
+import "context"
+
+type principalContextKey struct{}
+
+// WithPrincipal returns a context carrying the principal a request acts as
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal a request acts as, or nil for
+// anonymous requests and those with an invalid token
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return principal
+}
+
+// ClientIDFromContext returns the third-party client the request was made
+// by, or an empty string for first-party and anonymous requests
+func ClientIDFromContext(ctx context.Context) string {
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		return principal.ClientID
+	}
+	return ""
+}
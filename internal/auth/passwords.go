@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"errors"
 	"net/http"
@@ -24,6 +26,26 @@ var (
 	ErrUnauthorized       = errors.New("unauthorized")
 )
 
+// Scopes a third-party client can be granted. Read allows safe (GET) API
+// requests and write allows the others; neither reaches account settings,
+// OAuth client management or the admin API.
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+// tokenClaims are the claims of every access token. ClientID and Scope are
+// only set on tokens issued to third-party clients.
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	ClientID string `json:"client_id,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+
+	// AuthTime is when the user signed in, only set on tokens issued at
+	// sign-in
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+}
+
 // HashPassword creates a secure hash from a plain text password
 // Uses Argon2id, which is the recommended password hashing algorithm
 func HashPassword(password string) (string, error) {
@@ -42,15 +64,16 @@ func HashPassword(password string) (string, error) {
 
 // VerifyPassword checks if a plain text password matches a stored hash
 // Returns an error if the passwords don't match or the hash is invalid
+// Accounts without a password report ErrPasswordNotSet whatever was given
 func VerifyPassword(plainPassword, hashedPassword string) error {
-	if plainPassword == "" {
-		return ErrPasswordEmpty
-	}
-
 	if hashedPassword == "" || hashedPassword == "unset" {
 		return ErrPasswordNotSet
 	}
 
+	if plainPassword == "" {
+		return ErrPasswordEmpty
+	}
+
 	// Compare password with hash
 	match, err := argon2id.ComparePasswordAndHash(plainPassword, hashedPassword)
 	if err != nil {
@@ -66,14 +89,54 @@ func VerifyPassword(plainPassword, hashedPassword string) error {
 
 // MakeJWT creates a JWT token for a user with the specified secret and expiration time
 func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (string, error) {
+	return MakeTenantJWT(userID, "", tokenSecret, expiresIn)
+}
+
+// MakeTenantJWT creates a JWT token like MakeJWT that is only accepted by the
+// given tenant, recorded in the audience claim. An empty tenant means the
+// default community.
+func MakeTenantJWT(userID uuid.UUID, tenant, tokenSecret string, expiresIn time.Duration) (string, error) {
+	return MakeScopedJWT(userID, tenant, "", nil, tokenSecret, expiresIn)
+}
+
+// MakeSignInJWT creates an access token like MakeTenantJWT for a user who
+// has just signed in, recording the time in the auth_time claim so sensitive
+// actions can ask for a recent sign-in
+func MakeSignInJWT(userID uuid.UUID, tenant, tokenSecret string, expiresIn time.Duration) (string, error) {
+	return signClaims(userID, tenant, tokenSecret, expiresIn, func(claims *tokenClaims) {
+		claims.AuthTime = claims.IssuedAt
+	})
+}
+
+// MakeScopedJWT creates an access token issued to a third-party client,
+// limited to the given scopes. An empty clientID creates a first-party token
+// with full access, like MakeTenantJWT.
+func MakeScopedJWT(userID uuid.UUID, tenant, clientID string, scopes []string, tokenSecret string, expiresIn time.Duration) (string, error) {
+	return signClaims(userID, tenant, tokenSecret, expiresIn, func(claims *tokenClaims) {
+		if clientID != "" {
+			claims.ClientID = clientID
+			claims.Scope = strings.Join(scopes, " ")
+		}
+	})
+}
+
+// signClaims signs an access token for userID after extra sets the claims
+// specific to its kind
+func signClaims(userID uuid.UUID, tenant, tokenSecret string, expiresIn time.Duration, extra func(*tokenClaims)) (string, error) {
 	now := time.Now().UTC()
 
-	claims := jwt.RegisteredClaims{
-		Issuer:    "chirpy",
-		IssuedAt:  jwt.NewNumericDate(now),
-		ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
-		Subject:   userID.String(),
+	claims := tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    "chirpy",
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
+			Subject:   userID.String(),
+		},
+	}
+	if tenant != "" {
+		claims.Audience = jwt.ClaimStrings{tenant}
 	}
+	extra(&claims)
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	signedToken, err := token.SignedString([]byte(tokenSecret))
@@ -86,7 +149,23 @@ func MakeJWT(userID uuid.UUID, tokenSecret string, expiresIn time.Duration) (str
 
 // ValidateJWT checks if a JWT token is valid and returns the user ID
 func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
+	claims, err := validateClaims(tokenString, tokenSecret)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	// Parse user ID from subject
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.Nil, ErrInvalidToken
+	}
+
+	return userID, nil
+}
+
+// validateClaims verifies a JWT token's signature and expiry
+func validateClaims(tokenString, tokenSecret string) (*tokenClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// Validate the signing method
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, ErrInvalidToken
@@ -99,34 +178,28 @@ func ValidateJWT(tokenString, tokenSecret string) (uuid.UUID, error) {
 		errStr := err.Error()
 		if errStr == "token is expired" ||
 			errStr == "token has invalid claims: token is expired" {
-			return uuid.Nil, ErrExpiredToken
+			return nil, ErrExpiredToken
 		}
 		// Handle various signature invalid error formats
 		if errStr == "signature is invalid" ||
 			errStr == "token has invalid claims: signature is invalid" ||
 			errStr == "token signature is invalid: signature is invalid" {
-			return uuid.Nil, ErrInvalidToken
+			return nil, ErrInvalidToken
 		}
-		return uuid.Nil, err
+		return nil, err
 	}
 
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	claims, ok := token.Claims.(*tokenClaims)
 	if !ok || !token.Valid {
-		return uuid.Nil, ErrInvalidToken
+		return nil, ErrInvalidToken
 	}
 
 	// Check if token is expired (double-check)
 	if claims.ExpiresAt != nil && claims.ExpiresAt.Before(time.Now()) {
-		return uuid.Nil, ErrExpiredToken
+		return nil, ErrExpiredToken
 	}
 
-	// Parse user ID from subject
-	userID, err := uuid.Parse(claims.Subject)
-	if err != nil {
-		return uuid.Nil, ErrInvalidToken
-	}
-
-	return userID, nil
+	return claims, nil
 }
 
 // CreateAccessToken generates a JWT token for a user
@@ -179,6 +252,22 @@ func GetAPIKey(headers http.Header) (string, error) {
 	return key, nil
 }
 
+// HashAPIKey returns the digest an API key is stored and looked up by. Keys
+// are random, so a fast unsalted hash is enough.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// HashFingerprint returns the digest the IP address or device ID of a
+// sign-in is stored by. There are few enough IPv4 addresses to hash every
+// one, so the digest is keyed with secret to keep it from being reversed.
+func HashFingerprint(value, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 // MakeRefreshToken generates a cryptographically secure random refresh token
 func MakeRefreshToken() (string, error) {
 	// Generate 32 bytes (256 bits) of random data
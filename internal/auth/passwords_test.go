@@ -186,3 +186,16 @@ func TestCreateAccessToken_Integration(t *testing.T) {
 		t.Errorf("CreateAccessToken() validation failed, got %v, want %v", validatedUserID, userID)
 	}
 }
+
+func TestHashFingerprint(t *testing.T) {
+	hash := HashFingerprint("203.0.113.7", "fingerprint-secret")
+	if hash != HashFingerprint("203.0.113.7", "fingerprint-secret") {
+		t.Error("same address hashed differently")
+	}
+	if hash == HashFingerprint("203.0.113.8", "fingerprint-secret") {
+		t.Error("different addresses hashed the same")
+	}
+	if hash == HashFingerprint("203.0.113.7", "other-secret") {
+		t.Error("hash doesn't depend on the secret")
+	}
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TokenKind is the kind of access token a principal authenticated with
+type TokenKind string
+
+const (
+	// TokenSession is a token from the user's own login
+	TokenSession TokenKind = "session"
+	// TokenClient is a token issued to a third-party OAuth client
+	TokenClient TokenKind = "client"
+)
+
+// Principal is who a request acts as, read from its access token once and
+// shared by everything that needs the caller's identity
+type Principal struct {
+	UserID uuid.UUID
+
+	// Tenant is the community the token was issued for, empty for the
+	// default community
+	Tenant string
+
+	Kind TokenKind
+
+	// ClientID and Scopes are only set for TokenClient principals
+	ClientID string
+	Scopes   []string
+
+	// AuthTime is when the user signed in to get the token, zero for tokens
+	// from a refresh or an account switch
+	AuthTime time.Time
+
+	roleOnce sync.Once
+	role     string
+	roleErr  error
+}
+
+// ParsePrincipal validates an access token and returns its principal
+func ParsePrincipal(tokenString, tokenSecret string) (*Principal, error) {
+	claims, err := validateClaims(tokenString, tokenSecret)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	principal := &Principal{UserID: userID, Kind: TokenSession}
+	if len(claims.Audience) > 0 {
+		principal.Tenant = claims.Audience[0]
+	}
+	if claims.AuthTime != nil {
+		principal.AuthTime = claims.AuthTime.Time
+	}
+	if claims.ClientID != "" {
+		principal.Kind = TokenClient
+		principal.ClientID = claims.ClientID
+		principal.Scopes = strings.Fields(claims.Scope)
+	}
+	return principal, nil
+}
+
+// Authenticate returns the principal of a request: the one the middleware
+// stored in its context, or else the one its bearer token names
+func Authenticate(r *http.Request, tokenSecret string) (*Principal, error) {
+	if principal := PrincipalFromContext(r.Context()); principal != nil {
+		return principal, nil
+	}
+	token, err := GetBearerToken(r.Header)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePrincipal(token, tokenSecret)
+}
+
+// HasScope reports whether the principal may make requests needing scope.
+// Only client tokens are limited by scope.
+func (p *Principal) HasScope(scope string) bool {
+	return p.Kind != TokenClient || slices.Contains(p.Scopes, scope)
+}
+
+// SignedInWithin reports whether the token was issued when the user signed
+// in, no longer than d ago
+func (p *Principal) SignedInWithin(d time.Duration) bool {
+	return !p.AuthTime.IsZero() && time.Since(p.AuthTime) <= d
+}
+
+// Role returns the user's role, looking it up with lookup the first time it
+// is asked for during the request
+func (p *Principal) Role(ctx context.Context, lookup func(context.Context, uuid.UUID) (string, error)) (string, error) {
+	p.roleOnce.Do(func() {
+		p.role, p.roleErr = lookup(ctx, p.UserID)
+	})
+	return p.role, p.roleErr
+}
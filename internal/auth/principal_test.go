@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParsePrincipal(t *testing.T) {
+	userID := uuid.New()
+	tokenSecret := "test-secret-key"
+
+	session, err := MakeJWT(userID, tokenSecret, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT() error = %v", err)
+	}
+	client, err := MakeScopedJWT(userID, "birds", "client-1", []string{ScopeRead}, tokenSecret, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeScopedJWT() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantKind   TokenKind
+		wantTenant string
+		wantWrite  bool
+	}{
+		{name: "session", token: session, wantKind: TokenSession, wantWrite: true},
+		{name: "client", token: client, wantKind: TokenClient, wantTenant: "birds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := ParsePrincipal(tt.token, tokenSecret)
+			if err != nil {
+				t.Fatalf("ParsePrincipal() error = %v", err)
+			}
+			if principal.UserID != userID {
+				t.Errorf("UserID = %v, want %v", principal.UserID, userID)
+			}
+			if principal.Kind != tt.wantKind {
+				t.Errorf("Kind = %q, want %q", principal.Kind, tt.wantKind)
+			}
+			if principal.Tenant != tt.wantTenant {
+				t.Errorf("Tenant = %q, want %q", principal.Tenant, tt.wantTenant)
+			}
+			if !principal.HasScope(ScopeRead) {
+				t.Error("HasScope(read) = false, want true")
+			}
+			if got := principal.HasScope(ScopeWrite); got != tt.wantWrite {
+				t.Errorf("HasScope(write) = %v, want %v", got, tt.wantWrite)
+			}
+		})
+	}
+
+	if _, err := ParsePrincipal(session, "wrong-secret"); err == nil {
+		t.Error("ParsePrincipal() with wrong secret should fail")
+	}
+}
+
+func TestAuthenticatePrefersContext(t *testing.T) {
+	tokenSecret := "test-secret-key"
+	stored := &Principal{UserID: uuid.New(), Kind: TokenSession}
+
+	// The principal stored by the middleware wins over the header
+	req := httptest.NewRequest("GET", "/", nil)
+	req = req.WithContext(WithPrincipal(req.Context(), stored))
+	if principal, err := Authenticate(req, tokenSecret); err != nil || principal != stored {
+		t.Errorf("Authenticate() = %v, %v, want the stored principal", principal, err)
+	}
+
+	// Without one, the bearer token is parsed
+	userID := uuid.New()
+	token, err := MakeJWT(userID, tokenSecret, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT() error = %v", err)
+	}
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if principal, err := Authenticate(req, tokenSecret); err != nil || principal.UserID != userID {
+		t.Errorf("Authenticate() = %v, %v, want user %v", principal, err, userID)
+	}
+
+	req = httptest.NewRequest("GET", "/", nil)
+	if _, err := Authenticate(req, tokenSecret); err == nil {
+		t.Error("Authenticate() without a token should fail")
+	}
+}
+
+func TestPrincipalRoleIsLookedUpOnce(t *testing.T) {
+	principal := &Principal{UserID: uuid.New()}
+	calls := 0
+	lookup := func(_ context.Context, userID uuid.UUID) (string, error) {
+		calls++
+		if userID != principal.UserID {
+			t.Errorf("lookup(%v), want %v", userID, principal.UserID)
+		}
+		return "admin", nil
+	}
+
+	for range 3 {
+		if role, err := principal.Role(context.Background(), lookup); err != nil || role != "admin" {
+			t.Errorf("Role() = %q, %v, want admin", role, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("lookup called %d times, want 1", calls)
+	}
+}
+
+func TestSignedInWithin(t *testing.T) {
+	tokenSecret := "test-secret-key"
+	userID := uuid.New()
+
+	signIn, err := MakeSignInJWT(userID, "", tokenSecret, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeSignInJWT() error = %v", err)
+	}
+	principal, err := ParsePrincipal(signIn, tokenSecret)
+	if err != nil {
+		t.Fatalf("ParsePrincipal() error = %v", err)
+	}
+	if !principal.SignedInWithin(time.Minute) {
+		t.Error("SignedInWithin() = false for a sign-in token, want true")
+	}
+
+	// Tokens from a refresh don't prove a recent sign-in
+	refreshed, err := MakeJWT(userID, tokenSecret, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT() error = %v", err)
+	}
+	principal, err = ParsePrincipal(refreshed, tokenSecret)
+	if err != nil {
+		t.Fatalf("ParsePrincipal() error = %v", err)
+	}
+	if principal.SignedInWithin(time.Hour) {
+		t.Error("SignedInWithin() = true for a refreshed token, want false")
+	}
+
+	old := &Principal{UserID: userID, AuthTime: time.Now().Add(-time.Hour)}
+	if old.SignedInWithin(5 * time.Minute) {
+		t.Error("SignedInWithin() = true for an hour-old sign-in, want false")
+	}
+}
@@ -0,0 +1,168 @@
+// Package backup takes compressed pg_dump backups of the database into a
+// local directory, rotates old ones and restores them with pg_restore.
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
+)
+
+const (
+	filePrefix = "chirpy-"
+	fileSuffix = ".dump"
+
+	// nameLayout sorts chronologically as a string
+	nameLayout = "20060102T150405Z"
+)
+
+// ErrNotFound is returned when restoring a backup that doesn't exist
+var ErrNotFound = errors.New("backup not found")
+
+// Backup describes a stored dump
+type Backup struct {
+	Name      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// Manager writes backups of DBURL to Dir and keeps the newest Keep of them
+type Manager struct {
+	DBURL string
+	Dir   string
+	Keep  int
+
+	// PgDump and PgRestore name the client binaries; empty means look
+	// them up on PATH
+	PgDump    string
+	PgRestore string
+
+	// Store makes sure only one replica backs up per Interval when several
+	// share a backup directory; nil backs up on every run
+	Store    cache.Store
+	Interval time.Duration
+}
+
+// Run takes a backup, then deletes the oldest ones beyond Keep. The dump is
+// written under a temporary name and renamed once complete, so a failed run
+// never leaves a partial backup behind.
+func (m *Manager) Run(ctx context.Context) error {
+	if m.Store != nil && m.Interval > 0 {
+		first, err := cache.OncePer(ctx, m.Store, "backup", m.Interval)
+		if err != nil || !first {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(m.Dir, 0o700); err != nil {
+		return err
+	}
+
+	name := filePrefix + time.Now().UTC().Format(nameLayout) + fileSuffix
+	tmp := filepath.Join(m.Dir, "."+name+".partial")
+	cmd := exec.CommandContext(ctx, binary(m.PgDump, "pg_dump"),
+		"--format=custom", "--compress=6", "--no-owner", "--file="+tmp, m.DBURL)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("pg_dump failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	if err := os.Rename(tmp, filepath.Join(m.Dir, name)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return m.prune()
+}
+
+// List returns the stored backups, newest first
+func (m *Manager) List() ([]Backup, error) {
+	entries, err := os.ReadDir(m.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Backup{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []Backup{}
+	for _, entry := range entries {
+		createdAt, ok := parseName(entry.Name())
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, Backup{Name: entry.Name(), Size: info.Size(), CreatedAt: createdAt})
+	}
+	slices.SortFunc(backups, func(a, b Backup) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return backups, nil
+}
+
+// Restore replaces the contents of the database with the named backup.
+// Objects in the dump are dropped and recreated; tables added since the
+// backup are left alone.
+func (m *Manager) Restore(ctx context.Context, name string) error {
+	if _, ok := parseName(name); !ok || filepath.Base(name) != name {
+		return ErrNotFound
+	}
+	path := filepath.Join(m.Dir, name)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+
+	cmd := exec.CommandContext(ctx, binary(m.PgRestore, "pg_restore"),
+		"--clean", "--if-exists", "--no-owner", "--single-transaction", "--dbname="+m.DBURL, path)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("pg_restore failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// prune deletes the oldest backups beyond Keep
+func (m *Manager) prune() error {
+	if m.Keep <= 0 {
+		return nil
+	}
+	backups, err := m.List()
+	if err != nil {
+		return err
+	}
+	for _, old := range backups[min(m.Keep, len(backups)):] {
+		if err := os.Remove(filepath.Join(m.Dir, old.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseName returns the creation time encoded in a backup's file name
+func parseName(name string) (time.Time, bool) {
+	stamp, ok := strings.CutPrefix(name, filePrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	if stamp, ok = strings.CutSuffix(stamp, fileSuffix); !ok {
+		return time.Time{}, false
+	}
+	createdAt, err := time.Parse(nameLayout, stamp)
+	return createdAt, err == nil
+}
+
+func binary(configured, fallback string) string {
+	if configured != "" {
+		return configured
+	}
+	return fallback
+}
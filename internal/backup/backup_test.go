@@ -0,0 +1,99 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeTool writes a shell script standing in for pg_dump or pg_restore
+func fakeTool(t *testing.T, script string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunWritesAndRotatesBackups(t *testing.T) {
+	dir := t.TempDir()
+	m := &Manager{
+		DBURL:  "postgres://localhost/chirpy",
+		Dir:    dir,
+		Keep:   2,
+		PgDump: fakeTool(t, `for arg; do case $arg in --file=*) echo dump > "${arg#--file=}";; esac; done`),
+	}
+
+	// Older backups from previous nights
+	for _, stamp := range []string{"20260101T030000Z", "20260102T030000Z"} {
+		if err := os.WriteFile(filepath.Join(dir, filePrefix+stamp+fileSuffix), []byte("old"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	backups, err := m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("List() = %+v, want 2 backups", backups)
+	}
+	if time.Since(backups[0].CreatedAt) > time.Minute || backups[0].Size == 0 {
+		t.Errorf("newest backup = %+v, want the one just taken", backups[0])
+	}
+	if backups[1].Name != filePrefix+"20260102T030000Z"+fileSuffix {
+		t.Errorf("kept %q, want the newer of the old backups", backups[1].Name)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("unrelated file was removed: %v", err)
+	}
+}
+
+func TestRunFailureLeavesNoBackup(t *testing.T) {
+	m := &Manager{
+		Dir:    t.TempDir(),
+		PgDump: fakeTool(t, `for arg; do case $arg in --file=*) echo partial > "${arg#--file=}";; esac; done; echo "connection refused" >&2; exit 1`),
+	}
+
+	if err := m.Run(context.Background()); err == nil {
+		t.Fatal("Run() error = nil, want pg_dump failure")
+	}
+	entries, err := os.ReadDir(m.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("backup directory has %d entries, want none", len(entries))
+	}
+}
+
+func TestRestoreRejectsUnknownBackups(t *testing.T) {
+	m := &Manager{Dir: t.TempDir(), PgRestore: fakeTool(t, "exit 0")}
+	if err := os.WriteFile(filepath.Join(m.Dir, filePrefix+"20260101T030000Z"+fileSuffix), []byte("dump"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{
+		"chirpy-20260102T030000Z.dump",
+		"../chirpy-20260101T030000Z.dump",
+		"/etc/passwd",
+	} {
+		if err := m.Restore(context.Background(), name); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Restore(%q) error = %v, want ErrNotFound", name, err)
+		}
+	}
+	if err := m.Restore(context.Background(), "chirpy-20260101T030000Z.dump"); err != nil {
+		t.Errorf("Restore() error = %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// ErrNotFound is returned when a key is missing or has expired
+var ErrNotFound = errors.New("cache: key not found")
+
+// Store is a shared key/value store with per-key expiry. The in-memory
+// implementation is fine for a single server; the Redis implementation lets
+// several replicas share state.
+type Store interface {
+	// Get returns the value stored for key or ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value for key; a zero ttl keeps the key until deleted
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key if present
+	Delete(ctx context.Context, key string) error
+	// Incr atomically increments the counter at key and returns the new
+	// value. The ttl is applied when the counter is created.
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+}
+
+// OncePer reports whether the caller is the first to claim key during the
+// current interval. Replicas sharing a Redis store use it so only one of
+// them runs a periodic task each interval; with the memory store every
+// process claims its own.
+func OncePer(ctx context.Context, store Store, key string, interval time.Duration) (bool, error) {
+	window := time.Now().Truncate(interval).Unix()
+	count, err := store.Incr(ctx, key+":"+strconv.FormatInt(window, 10), 2*interval)
+	if err != nil {
+		return false, err
+	}
+	return count == 1, nil
+}
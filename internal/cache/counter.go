@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// Counter is a named counter kept in a Store. Backed by the Redis store it
+// is shared by every replica; backed by the memory store it is per-process.
+type Counter struct {
+	store Store
+	key   string
+}
+
+// NewCounter creates a counter stored under key
+func NewCounter(store Store, key string) *Counter {
+	return &Counter{store: store, key: key}
+}
+
+// Inc increments the counter and returns the new value
+func (c *Counter) Inc(ctx context.Context) (int64, error) {
+	return c.store.Incr(ctx, c.key, 0)
+}
+
+// Value returns the current count, which is zero if never incremented
+func (c *Counter) Value(ctx context.Context) (int64, error) {
+	raw, err := c.store.Get(ctx, c.key)
+	if errors.Is(err, ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(raw), 10, 64)
+}
+
+// Reset sets the counter back to zero
+func (c *Counter) Reset(ctx context.Context) error {
+	return c.store.Delete(ctx, c.key)
+}
@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCounter(t *testing.T) {
+	ctx := context.Background()
+	counter := NewCounter(NewMemory(), "hits")
+
+	value, err := counter.Value(ctx)
+	if err != nil || value != 0 {
+		t.Fatalf("Value() = %d, %v, want 0, nil", value, err)
+	}
+
+	counter.Inc(ctx)
+	counter.Inc(ctx)
+
+	value, err = counter.Value(ctx)
+	if err != nil || value != 2 {
+		t.Errorf("Value() = %d, %v, want 2, nil", value, err)
+	}
+
+	if err := counter.Reset(ctx); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	value, err = counter.Value(ctx)
+	if err != nil || value != 0 {
+		t.Errorf("Value() after Reset = %d, %v, want 0, nil", value, err)
+	}
+}
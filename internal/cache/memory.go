@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// expired reports whether the entry has a deadline that has passed
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// sweepInterval is how many writes the memory store accepts between sweeps
+// for expired keys that are never read again, such as rate limit windows
+const sweepInterval = 1024
+
+// Memory is an in-process Store. Expired keys are removed lazily on access
+// and swept every sweepInterval writes.
+type Memory struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+	writes  int
+}
+
+// NewMemory creates an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{
+		entries: make(map[string]memoryEntry),
+		now:     time.Now,
+	}
+}
+
+// Get returns the value stored for key or ErrNotFound
+func (m *Memory) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, found := m.entries[key]
+	if !found || entry.expired(m.now()) {
+		delete(m.entries, key)
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), entry.value...), nil
+}
+
+// Set stores value for key with an optional ttl
+func (m *Memory) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = memoryEntry{
+		value:     append([]byte(nil), value...),
+		expiresAt: m.deadline(ttl),
+	}
+	m.wrote()
+	return nil
+}
+
+// Delete removes key if present
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+	return nil
+}
+
+// Incr increments the counter at key, creating it with ttl if needed
+func (m *Memory) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, found := m.entries[key]
+	if !found || entry.expired(m.now()) {
+		entry = memoryEntry{value: []byte("0"), expiresAt: m.deadline(ttl)}
+	}
+
+	count, err := strconv.ParseInt(string(entry.value), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	count++
+	entry.value = []byte(strconv.FormatInt(count, 10))
+	m.entries[key] = entry
+	m.wrote()
+	return count, nil
+}
+
+// wrote counts a write and sweeps expired keys every sweepInterval writes.
+// The caller must hold m.mu.
+func (m *Memory) wrote() {
+	m.writes++
+	if m.writes < sweepInterval {
+		return
+	}
+	m.writes = 0
+
+	now := m.now()
+	for key, entry := range m.entries {
+		if entry.expired(now) {
+			delete(m.entries, key)
+		}
+	}
+}
+
+// deadline converts a ttl to an absolute expiry, or zero for no expiry
+func (m *Memory) deadline(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return m.now().Add(ttl)
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMemoryGetSet(t *testing.T) {
+	store := NewMemory()
+	ctx := context.Background()
+
+	if _, err := store.Get(ctx, "missing"); err != ErrNotFound {
+		t.Fatalf("Get() error = %v, want %v", err, ErrNotFound)
+	}
+
+	if err := store.Set(ctx, "key", []byte("value"), 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	value, err := store.Get(ctx, "key")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if string(value) != "value" {
+		t.Errorf("Get() = %q, want %q", value, "value")
+	}
+
+	if err := store.Delete(ctx, "key"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get(ctx, "key"); err != ErrNotFound {
+		t.Errorf("Get() after Delete error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestMemoryExpiry(t *testing.T) {
+	store := NewMemory()
+	ctx := context.Background()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	store.Set(ctx, "key", []byte("value"), time.Minute)
+
+	now = now.Add(59 * time.Second)
+	if _, err := store.Get(ctx, "key"); err != nil {
+		t.Fatalf("Get() before expiry error = %v", err)
+	}
+
+	now = now.Add(time.Second)
+	if _, err := store.Get(ctx, "key"); err != ErrNotFound {
+		t.Errorf("Get() after expiry error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestMemoryIncr(t *testing.T) {
+	store := NewMemory()
+	ctx := context.Background()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	for want := int64(1); want <= 3; want++ {
+		got, err := store.Incr(ctx, "counter", time.Minute)
+		if err != nil {
+			t.Fatalf("Incr() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("Incr() = %d, want %d", got, want)
+		}
+	}
+
+	// The window resets once the counter expires
+	now = now.Add(time.Minute)
+	got, err := store.Incr(ctx, "counter", time.Minute)
+	if err != nil {
+		t.Fatalf("Incr() error = %v", err)
+	}
+	if got != 1 {
+		t.Errorf("Incr() after expiry = %d, want 1", got)
+	}
+}
+
+func TestMemorySweepsExpiredKeys(t *testing.T) {
+	store := NewMemory()
+	ctx := context.Background()
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	store.Set(ctx, "kept", []byte("value"), 0)
+	for i := range sweepInterval / 2 {
+		store.Incr(ctx, "window:"+strconv.Itoa(i), time.Minute)
+	}
+
+	now = now.Add(time.Minute)
+	for i := range sweepInterval / 2 {
+		store.Incr(ctx, "fresh:"+strconv.Itoa(i), time.Minute)
+	}
+
+	if got, want := len(store.entries), sweepInterval/2+1; got != want {
+		t.Errorf("entries after sweep = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis is a Store backed by a Redis server, shared by every replica
+type Redis struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedis wraps a Redis client. Keys are namespaced with prefix so several
+// applications can share one Redis database.
+func NewRedis(client *redis.Client, prefix string) *Redis {
+	return &Redis{client: client, prefix: prefix}
+}
+
+// Get returns the value stored for key or ErrNotFound
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+// Set stores value for key with an optional ttl
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, r.prefix+key, value, ttl).Err()
+}
+
+// Delete removes key if present
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.prefix+key).Err()
+}
+
+// Incr increments the counter at key, creating it with ttl if needed
+func (r *Redis) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	count, err := r.client.Incr(ctx, r.prefix+key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if count == 1 && ttl > 0 {
+		if err := r.client.Expire(ctx, r.prefix+key, ttl).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,86 @@
+// Package chaos decides which requests get an injected fault, so client
+// retry logic and circuit breakers can be exercised against a dev server.
+package chaos
+
+import (
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"sync"
+)
+
+// Faults a rule can inject after its latency
+const (
+	// FaultNone only adds latency
+	FaultNone = ""
+	// FaultError responds with 500 Internal Server Error
+	FaultError = "error"
+	// FaultDrop closes the connection without a response
+	FaultDrop = "drop"
+)
+
+// Rule applies to Percent of the requests whose path starts with Path
+type Rule struct {
+	Path      string  `json:"path"`
+	Percent   float64 `json:"percent"`
+	LatencyMS int     `json:"latency_ms"`
+	Fault     string  `json:"fault"`
+}
+
+// Injector holds the active rules. It is safe for concurrent use.
+type Injector struct {
+	mu    sync.RWMutex
+	rules []Rule
+	roll  func() float64
+}
+
+// New creates an injector with no rules
+func New() *Injector {
+	return &Injector{roll: rand.Float64}
+}
+
+// SetRules replaces the active rules
+func (i *Injector) SetRules(rules []Rule) error {
+	for _, rule := range rules {
+		if rule.Percent < 0 || rule.Percent > 100 {
+			return errors.New("percent must be between 0 and 100")
+		}
+		if rule.LatencyMS < 0 {
+			return errors.New("latency_ms can't be negative")
+		}
+		if rule.Fault != FaultNone && rule.Fault != FaultError && rule.Fault != FaultDrop {
+			return errors.New("fault must be empty, error or drop")
+		}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.rules = append([]Rule{}, rules...)
+	return nil
+}
+
+// Rules returns a copy of the active rules
+func (i *Injector) Rules() []Rule {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return append([]Rule{}, i.rules...)
+}
+
+// Match returns the rule to apply to a request for path, if any. The rule
+// with the longest matching Path decides, and applies to its Percent of
+// requests.
+func (i *Injector) Match(path string) (Rule, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	best := -1
+	for idx, rule := range i.rules {
+		if strings.HasPrefix(path, rule.Path) && (best < 0 || len(rule.Path) > len(i.rules[best].Path)) {
+			best = idx
+		}
+	}
+	if best < 0 || i.roll()*100 >= i.rules[best].Percent {
+		return Rule{}, false
+	}
+	return i.rules[best], true
+}
@@ -0,0 +1,45 @@
+package chaos
+
+import "testing"
+
+func TestInjectorMatch(t *testing.T) {
+	injector := New()
+	err := injector.SetRules([]Rule{
+		{Path: "/api/", Percent: 100, LatencyMS: 200},
+		{Path: "/api/chirps", Percent: 30, Fault: FaultError},
+	})
+	if err != nil {
+		t.Fatalf("SetRules() error = %v", err)
+	}
+
+	tests := []struct {
+		path      string
+		roll      float64
+		wantFault string
+		wantMatch bool
+	}{
+		{"/api/users", 0.99, FaultNone, true},
+		{"/api/chirps/123", 0.29, FaultError, true},
+		{"/api/chirps/123", 0.30, "", false},
+		{"/admin/metrics", 0, "", false},
+	}
+	for _, tt := range tests {
+		injector.roll = func() float64 { return tt.roll }
+		rule, ok := injector.Match(tt.path)
+		if ok != tt.wantMatch || rule.Fault != tt.wantFault {
+			t.Errorf("Match(%q) with roll %v = %+v, %v, want fault %q, %v", tt.path, tt.roll, rule, ok, tt.wantFault, tt.wantMatch)
+		}
+	}
+}
+
+func TestInjectorSetRulesValidates(t *testing.T) {
+	for _, rule := range []Rule{
+		{Percent: 101},
+		{Percent: 10, LatencyMS: -1},
+		{Percent: 10, Fault: "timeout"},
+	} {
+		if err := New().SetRules([]Rule{rule}); err == nil {
+			t.Errorf("SetRules(%+v) error = nil, want error", rule)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+// Package config loads the server's runtime configuration. Settings come from
+// built-in defaults, then an optional JSON file named by CONFIG_FILE, then an
+// optional Vault secret, then environment variables, with later sources
+// taking precedence. Secrets may also be read from files named by *_FILE
+// variables, as Docker secrets are mounted.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds every setting the server reads at startup. Each field is
+// named by its env tag, which is also its key in the config file. Fields
+// tagged secret are masked by Settings: "true" hides the whole value and
+// "url" hides only the password of a connection URL. Only secrets are read
+// from Vault and *_FILE variables.
+type Config struct {
+	DBURL     string `env:"DB_URL" required:"true" secret:"url"`
+	Platform  string `env:"PLATFORM" required:"true"`
+	JWTSecret string `env:"JWT_SECRET" required:"true" secret:"true"`
+	PolkaKey  string `env:"POLKA_KEY" required:"true" secret:"true"`
+
+	InstanceName        string   `env:"INSTANCE_NAME" default:"Chirpy"`
+	InstanceDescription string   `env:"INSTANCE_DESCRIPTION"`
+	PublicURL           string   `env:"PUBLIC_URL"`
+	RegistrationMode    string   `env:"REGISTRATION_MODE" default:"open"`
+	ReservedHandles     []string `env:"RESERVED_HANDLES"`
+	RequireAltText      bool     `env:"REQUIRE_ALT_TEXT"`
+	AllowChirpEdits     bool     `env:"ALLOW_CHIRP_EDITS"`
+	ArchiveAfterMonths  int      `env:"ARCHIVE_AFTER_MONTHS"`
+	SortableChirpIDs    bool     `env:"SORTABLE_CHIRP_IDS"`
+	AllowedReactions    []string `env:"ALLOWED_REACTIONS"`
+	ChirpLengthCounting string   `env:"CHIRP_LENGTH_COUNTING" default:"runes"`
+
+	LinkPreviewHosts   []string      `env:"LINK_PREVIEW_HOSTS"`
+	LinkPreviewTimeout time.Duration `env:"LINK_PREVIEW_TIMEOUT" default:"5s"`
+
+	ModerationClassifierURL   string `env:"MODERATION_CLASSIFIER_URL"`
+	ModerationClassifierToken string `env:"MODERATION_CLASSIFIER_TOKEN" secret:"true"`
+
+	OIDCIssuer         string `env:"OIDC_ISSUER"`
+	OIDCClientID       string `env:"OIDC_CLIENT_ID"`
+	OIDCClientSecret   string `env:"OIDC_CLIENT_SECRET" secret:"true"`
+	OIDCRedirectURL    string `env:"OIDC_REDIRECT_URL"`
+	SSOJITProvisioning bool   `env:"SSO_JIT_PROVISIONING"`
+
+	MigrationSigningKey string `env:"MIGRATION_SIGNING_KEY" secret:"true"`
+
+	FingerprintSecret    string        `env:"FINGERPRINT_SECRET" secret:"true"`
+	FingerprintRetention time.Duration `env:"FINGERPRINT_RETENTION" default:"2160h"`
+
+	MultiTenant      bool   `env:"MULTI_TENANT"`
+	TenantBaseDomain string `env:"TENANT_BASE_DOMAIN"`
+	CustomDomains    bool   `env:"CUSTOM_DOMAINS"`
+
+	RedisURL    string `env:"REDIS_URL" secret:"url"`
+	ClusterMode bool   `env:"CLUSTER_MODE"`
+
+	Mailer             string `env:"MAILER" default:"log"`
+	MailFrom           string `env:"MAIL_FROM"`
+	SMTPAddr           string `env:"SMTP_ADDR"`
+	SMTPUsername       string `env:"SMTP_USERNAME"`
+	SMTPPassword       string `env:"SMTP_PASSWORD" secret:"true"`
+	AWSRegion          string `env:"AWS_REGION"`
+	AWSAccessKeyID     string `env:"AWS_ACCESS_KEY_ID" secret:"true"`
+	AWSSecretAccessKey string `env:"AWS_SECRET_ACCESS_KEY" secret:"true"`
+	AWSSessionToken    string `env:"AWS_SESSION_TOKEN" secret:"true"`
+
+	ReusePort       bool          `env:"REUSE_PORT"`
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" default:"30s"`
+
+	RequestTimeout time.Duration `env:"REQUEST_TIMEOUT" default:"0s"`
+	RouteTimeouts  []string      `env:"ROUTE_TIMEOUTS"`
+
+	LoadShedBacklog    int           `env:"LOAD_SHED_BACKLOG" default:"0"`
+	LoadShedDBLatency  time.Duration `env:"LOAD_SHED_DB_LATENCY" default:"0s"`
+	LoadShedPriorities []string      `env:"LOAD_SHED_PRIORITIES"`
+
+	Rollout []string `env:"ROLLOUT"`
+
+	RateLimit       int           `env:"RATE_LIMIT" default:"300"`
+	RateLimitWindow time.Duration `env:"RATE_LIMIT_WINDOW" default:"1m"`
+	ChirpRateLimit  int           `env:"CHIRP_RATE_LIMIT" default:"10"`
+
+	ProbationPeriod         time.Duration `env:"PROBATION_PERIOD" default:"0s"`
+	ProbationChirpRateLimit int           `env:"PROBATION_CHIRP_RATE_LIMIT" default:"2"`
+
+	BackupDir      string        `env:"BACKUP_DIR"`
+	BackupInterval time.Duration `env:"BACKUP_INTERVAL" default:"24h"`
+	BackupKeep     int           `env:"BACKUP_KEEP" default:"7"`
+
+	WebhookJournal string `env:"WEBHOOK_JOURNAL"`
+
+	RetentionDryRun        bool          `env:"RETENTION_DRY_RUN" default:"true"`
+	RetentionRevokedTokens time.Duration `env:"RETENTION_REVOKED_TOKENS" default:"0s"`
+	RetentionAuditLog      time.Duration `env:"RETENTION_AUDIT_LOG" default:"0s"`
+
+	AccountDeletionGracePeriod time.Duration `env:"ACCOUNT_DELETION_GRACE_PERIOD" default:"720h"`
+
+	AlertWebhookURL       string        `env:"ALERT_WEBHOOK_URL" secret:"true"`
+	AlertEmail            []string      `env:"ALERT_EMAIL"`
+	AlertCheckInterval    time.Duration `env:"ALERT_CHECK_INTERVAL" default:"1m"`
+	AlertServerErrors     int           `env:"ALERT_SERVER_ERRORS" default:"50"`
+	AlertOutboundFailures int           `env:"ALERT_OUTBOUND_FAILURES" default:"10"`
+	AlertErrorRate        int           `env:"ALERT_ERROR_RATE" default:"5"`
+	AlertP95Latency       time.Duration `env:"ALERT_P95_LATENCY" default:"2s"`
+	AlertWebhookFailures  int           `env:"ALERT_WEBHOOK_FAILURES" default:"5"`
+
+	LogRequests        bool          `env:"LOG_REQUESTS" default:"true"`
+	SlowQueryThreshold time.Duration `env:"SLOW_QUERY_THRESHOLD" default:"200ms"`
+
+	// sources records where each setting's value came from
+	sources map[string]string
+}
+
+// Sources a setting's value can come from
+const (
+	SourceDefault    = "default"
+	SourceFile       = "file"
+	SourceVault      = "vault"
+	SourceEnv        = "env"
+	SourceSecretFile = "secret_file"
+)
+
+// masked replaces secret values in Settings
+const masked = "********"
+
+// Setting is one effective configuration value, safe to display
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+// Load reads the configuration from defaults, the CONFIG_FILE JSON file (if
+// set), the Vault secret at VAULT_SECRET_PATH (if VAULT_ADDR is set) and the
+// environment
+func Load() (*Config, error) {
+	var fileValues map[string]string
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		var err error
+		if fileValues, err = readFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var vaultValues map[string]string
+	if addr := os.Getenv("VAULT_ADDR"); addr != "" {
+		token, err := vaultToken(os.LookupEnv)
+		if err != nil {
+			return nil, err
+		}
+		path := os.Getenv("VAULT_SECRET_PATH")
+		if path == "" {
+			return nil, errors.New("VAULT_SECRET_PATH must be set when VAULT_ADDR is")
+		}
+		if vaultValues, err = readVault(http.DefaultClient, addr, token, path); err != nil {
+			return nil, err
+		}
+	}
+	return load(fileValues, vaultValues, os.LookupEnv)
+}
+
+// load merges defaults, file values, Vault values and environment lookups
+// into a Config
+func load(fileValues, vaultValues map[string]string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg := &Config{sources: make(map[string]string)}
+	value := reflect.ValueOf(cfg).Elem()
+
+	var missing []string
+	for _, field := range reflect.VisibleFields(value.Type()) {
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+
+		raw, source := field.Tag.Get("default"), SourceDefault
+		if fileValue, found := fileValues[key]; found {
+			raw, source = fileValue, SourceFile
+		}
+		secret := field.Tag.Get("secret") != ""
+		if vaultValue, found := vaultValues[key]; found && secret {
+			raw, source = vaultValue, SourceVault
+		}
+		envValue, found := lookupEnv(key)
+		if found && envValue != "" {
+			raw, source = envValue, SourceEnv
+		}
+		if path, found := lookupEnv(key + "_FILE"); found && path != "" && secret {
+			if envValue != "" {
+				return nil, fmt.Errorf("%s and %s_FILE are both set", key, key)
+			}
+			fileValue, err := readSecretFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s_FILE: %w", key, err)
+			}
+			raw, source = fileValue, SourceSecretFile
+		}
+
+		if raw == "" && field.Tag.Get("required") == "true" {
+			missing = append(missing, key)
+			continue
+		}
+		if err := setField(value.FieldByIndex(field.Index), raw); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		if raw != "" {
+			cfg.sources[key] = source
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%s must be set", strings.Join(missing, ", "))
+	}
+	return cfg, nil
+}
+
+// readFile parses a JSON object of settings keyed by environment name.
+// Values may be strings, numbers, booleans or, for lists, arrays of strings.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	values, err := stringValues(raw)
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// readSecretFile reads a secret mounted as a file, dropping the trailing
+// newline editors and `echo` leave behind
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// stringValues converts decoded JSON settings to their environment form
+func stringValues(raw map[string]any) (map[string]string, error) {
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		switch v := value.(type) {
+		case string:
+			values[key] = v
+		case bool, float64:
+			values[key] = fmt.Sprint(v)
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+		default:
+			return nil, fmt.Errorf("unsupported value for %s", key)
+		}
+	}
+	return values, nil
+}
+
+// setField parses raw into the field according to its type
+func setField(field reflect.Value, raw string) error {
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(raw)
+	case bool:
+		if raw == "" {
+			return nil
+		}
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case int:
+		if raw == "" {
+			return nil
+		}
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(parsed))
+	case time.Duration:
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(parsed))
+	case []string:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return errors.New("unsupported setting type " + field.Type().String())
+	}
+	return nil
+}
+
+// Settings returns every setting sorted by key, with secrets masked
+func (c *Config) Settings() []Setting {
+	value := reflect.ValueOf(c).Elem()
+
+	var settings []Setting
+	for _, field := range reflect.VisibleFields(value.Type()) {
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+
+		display := formatValue(value.FieldByIndex(field.Index))
+		switch field.Tag.Get("secret") {
+		case "true":
+			if display != "" {
+				display = masked
+			}
+		case "url":
+			display = redactURL(display)
+		}
+
+		source := c.sources[key]
+		if source == "" {
+			source = SourceDefault
+		}
+		settings = append(settings, Setting{Key: key, Value: display, Source: source})
+	}
+
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+	return settings
+}
+
+// formatValue renders a field the way it would be written in the environment
+func formatValue(field reflect.Value) string {
+	switch v := field.Interface().(type) {
+	case []string:
+		return strings.Join(v, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// redactURL hides the password in a connection URL, masking the whole value
+// if it can't be parsed
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return masked
+	}
+	return parsed.Redacted()
+}
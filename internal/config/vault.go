@@ -0,0 +1,75 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// vaultTimeout bounds the single Vault read made at startup
+const vaultTimeout = 10 * time.Second
+
+// vaultToken returns the token from VAULT_TOKEN, or from the file named by
+// VAULT_TOKEN_FILE
+func vaultToken(lookupEnv func(string) (string, bool)) (string, error) {
+	if token, found := lookupEnv("VAULT_TOKEN"); found && token != "" {
+		return token, nil
+	}
+	if path, found := lookupEnv("VAULT_TOKEN_FILE"); found && path != "" {
+		token, err := readSecretFile(path)
+		if err != nil {
+			return "", fmt.Errorf("invalid VAULT_TOKEN_FILE: %w", err)
+		}
+		return token, nil
+	}
+	return "", errors.New("VAULT_TOKEN or VAULT_TOKEN_FILE must be set when VAULT_ADDR is")
+}
+
+// readVault reads the settings stored in a key/value secret, keyed by
+// environment name. Both versions of the KV engine are supported; for
+// version 2 the path includes "data/", e.g. "secret/data/chirpy".
+func readVault(client *http.Client, addr, token, path string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), vaultTimeout)
+	defer cancel()
+
+	url := strings.TrimRight(addr, "/") + "/v1/" + strings.TrimLeft(path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("reading vault secret: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("reading vault secret: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("reading vault secret %s: status %d", path, resp.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("parsing vault secret %s: %w", path, err)
+	}
+
+	// KV version 2 nests the secret under data.data next to its metadata
+	raw := body.Data
+	if nested, ok := raw["data"].(map[string]any); ok {
+		if _, versioned := raw["metadata"]; versioned {
+			raw = nested
+		}
+	}
+
+	values, err := stringValues(raw)
+	if err != nil {
+		return nil, fmt.Errorf("vault secret %s: %w", path, err)
+	}
+	return values, nil
+}
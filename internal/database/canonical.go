@@ -0,0 +1,35 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// CanonicalQuery is a hot-path query together with representative arguments,
+// so its plan can be inspected with EXPLAIN
+type CanonicalQuery struct {
+	Name string
+	SQL  string
+	Args []interface{}
+}
+
+// CanonicalQueries returns the listing and lookup queries whose plans should
+// stay index-backed as tables grow. The SQL is the generated query text, so
+// the list cannot drift from what the handlers run.
+func CanonicalQueries() []CanonicalQuery {
+	sampleID := uuid.Nil
+	defaultTenantID := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	return []CanonicalQuery{
+		{Name: "GetChirpsAsc", SQL: getChirpsAsc, Args: []interface{}{defaultTenantID, sql.NullString{}}},
+		{Name: "GetChirpsDesc", SQL: getChirpsDesc, Args: []interface{}{defaultTenantID, sql.NullString{}}},
+		{Name: "GetChirpsByAuthorAsc", SQL: getChirpsByAuthorAsc, Args: []interface{}{defaultTenantID, sampleID, sql.NullString{}}},
+		{Name: "GetChirpsByAuthorDesc", SQL: getChirpsByAuthorDesc, Args: []interface{}{defaultTenantID, sampleID, sql.NullString{}}},
+		{Name: "GetMediaForChirps", SQL: getMediaForChirps, Args: []interface{}{pq.Array([]uuid.UUID{sampleID})}},
+		{Name: "GetChirpAuthors", SQL: getChirpAuthors, Args: []interface{}{pq.Array([]uuid.UUID{sampleID})}},
+		{Name: "GetUserFromRefreshToken", SQL: getUserFromRefreshToken, Args: []interface{}{"", defaultTenantID}},
+		{Name: "DeleteExpiredRefreshTokens", SQL: deleteExpiredRefreshTokens, Args: []interface{}{time.Now().UTC()}},
+	}
+}
@@ -0,0 +1,27 @@
+package database
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+var placeholder = regexp.MustCompile(`\$(\d+)`)
+
+// TestCanonicalQueriesArgs checks every canonical query is given one
+// argument per placeholder, so EXPLAIN can run it
+func TestCanonicalQueriesArgs(t *testing.T) {
+	for _, query := range CanonicalQueries() {
+		placeholders := 0
+		for _, match := range placeholder.FindAllStringSubmatch(query.SQL, -1) {
+			n, err := strconv.Atoi(match[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+			placeholders = max(placeholders, n)
+		}
+		if placeholders != len(query.Args) {
+			t.Errorf("%s has %d placeholders but %d args", query.Name, placeholders, len(query.Args))
+		}
+	}
+}
@@ -7,29 +7,140 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
-VALUES (
-    gen_random_uuid(),
-    NOW(),
-    NOW(),
-    $1,
-    $2
+WITH created AS (
+    INSERT INTO chirps (id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, language, content_warning)
+    VALUES (
+        $1,
+        NOW(),
+        NOW(),
+        $2,
+        $3,
+        NOW() + ($4::int * INTERVAL '1 second'),
+        $5,
+        $6,
+        $7,
+        $8,
+        $9,
+        $10,
+        $11
+    )
+    RETURNING id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count
+), counted AS (
+    UPDATE chirps SET reply_count = chirps.reply_count + 1
+    WHERE chirps.id = (SELECT created.parent_chirp_id FROM created)
+), counted_archive AS (
+    UPDATE chirps_archive SET reply_count = chirps_archive.reply_count + 1
+    WHERE chirps_archive.id = (SELECT created.parent_chirp_id FROM created)
 )
-RETURNING id, created_at, updated_at, body, user_id
+SELECT id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count FROM created
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.UUID
+	ID             uuid.UUID
+	Body           string
+	UserID         uuid.UUID
+	DelaySeconds   int32
+	TenantID       uuid.UUID
+	Sensitive      bool
+	Source         string
+	OauthClientID  uuid.NullUUID
+	ParentChirpID  uuid.NullUUID
+	Language       string
+	ContentWarning string
 }
 
+// Counts a reply on its parent, live or archived, in the same statement
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp,
+		arg.ID,
+		arg.Body,
+		arg.UserID,
+		arg.DelaySeconds,
+		arg.TenantID,
+		arg.Sensitive,
+		arg.Source,
+		arg.OauthClientID,
+		arg.ParentChirpID,
+		arg.Language,
+		arg.ContentWarning,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.PublishedAt,
+		&i.TenantID,
+		&i.Sensitive,
+		&i.Source,
+		&i.OauthClientID,
+		&i.ParentChirpID,
+		&i.Locked,
+		&i.RepostOfChirpID,
+		&i.DeletedAt,
+		&i.Language,
+		&i.ContentWarning,
+		&i.LikeCount,
+		&i.ReplyCount,
+		&i.RepostCount,
+	)
+	return i, err
+}
+
+const createRepost = `-- name: CreateRepost :one
+WITH created AS (
+    INSERT INTO chirps (id, created_at, updated_at, body, user_id, published_at, tenant_id, source, oauth_client_id, repost_of_chirp_id)
+    VALUES (
+        $1,
+        NOW(),
+        NOW(),
+        '',
+        $2,
+        NOW(),
+        $3,
+        $4,
+        $5,
+        $6::uuid
+    )
+    ON CONFLICT (repost_of_chirp_id, user_id) WHERE repost_of_chirp_id IS NOT NULL AND deleted_at IS NULL DO NOTHING
+    RETURNING id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count
+), counted AS (
+    UPDATE chirps SET repost_count = chirps.repost_count + 1
+    WHERE chirps.id = (SELECT created.repost_of_chirp_id FROM created)
+)
+SELECT id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count FROM created
+`
+
+type CreateRepostParams struct {
+	ID              uuid.UUID
+	UserID          uuid.UUID
+	TenantID        uuid.UUID
+	Source          string
+	OauthClientID   uuid.NullUUID
+	RepostOfChirpID uuid.UUID
+}
+
+// Returns no row if the user already reposted the chirp. Counts the repost
+// on the original in the same statement.
+func (q *Queries) CreateRepost(ctx context.Context, arg CreateRepostParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, createRepost,
+		arg.ID,
+		arg.UserID,
+		arg.TenantID,
+		arg.Source,
+		arg.OauthClientID,
+		arg.RepostOfChirpID,
+	)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -37,23 +148,125 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.PublishedAt,
+		&i.TenantID,
+		&i.Sensitive,
+		&i.Source,
+		&i.OauthClientID,
+		&i.ParentChirpID,
+		&i.Locked,
+		&i.RepostOfChirpID,
+		&i.DeletedAt,
+		&i.Language,
+		&i.ContentWarning,
+		&i.LikeCount,
+		&i.ReplyCount,
+		&i.RepostCount,
 	)
 	return i, err
 }
 
 const deleteChirp = `-- name: DeleteChirp :exec
-DELETE FROM chirps
-WHERE id = $1
+WITH removed AS (
+    DELETE FROM chirps
+    WHERE id = $1
+    RETURNING parent_chirp_id, repost_of_chirp_id
+), counted_archive AS (
+    UPDATE chirps_archive
+    SET reply_count = GREATEST(chirps_archive.reply_count - ((chirps_archive.id = removed.parent_chirp_id) IS TRUE)::int, 0),
+        repost_count = GREATEST(chirps_archive.repost_count - ((chirps_archive.id = removed.repost_of_chirp_id) IS TRUE)::int, 0)
+    FROM removed
+    WHERE chirps_archive.id IN (removed.parent_chirp_id, removed.repost_of_chirp_id)
+)
+UPDATE chirps
+SET reply_count = GREATEST(chirps.reply_count - ((chirps.id = removed.parent_chirp_id) IS TRUE)::int, 0),
+    repost_count = GREATEST(chirps.repost_count - ((chirps.id = removed.repost_of_chirp_id) IS TRUE)::int, 0)
+FROM removed
+WHERE chirps.id IN (removed.parent_chirp_id, removed.repost_of_chirp_id)
 `
 
+// Removes a chirp outright; used to roll back a failed create. A reply or
+// repost stops being counted on the chirp it answers or shares.
 func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
 	_, err := q.db.ExecContext(ctx, deleteChirp, id)
 	return err
 }
 
+const getChirpAncestors = `-- name: GetChirpAncestors :many
+WITH RECURSIVE ancestors AS (
+    SELECT chirps.id, chirps.parent_chirp_id, 1 AS depth
+    FROM chirps
+    WHERE chirps.id = $1::uuid AND chirps.tenant_id = $2
+    UNION ALL
+    SELECT parents.id, parents.parent_chirp_id, ancestors.depth + 1
+    FROM chirps AS parents
+    JOIN ancestors ON parents.id = ancestors.parent_chirp_id
+    WHERE parents.tenant_id = $2
+)
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.published_at, chirps.tenant_id, chirps.sensitive, chirps.source, chirps.oauth_client_id, chirps.parent_chirp_id, chirps.locked, chirps.repost_of_chirp_id, chirps.deleted_at, chirps.language, chirps.content_warning, chirps.like_count, chirps.reply_count, chirps.repost_count FROM ancestors
+JOIN chirps ON chirps.id = ancestors.id
+WHERE chirps.published_at <= NOW()
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY ancestors.depth DESC
+`
+
+type GetChirpAncestorsParams struct {
+	ParentChirpID uuid.UUID
+	TenantID      uuid.UUID
+}
+
+// The chirps a reply answers, walking parent_chirp_id up from its parent,
+// root first. Hidden chirps are walked through but left out.
+func (q *Queries) GetChirpAncestors(ctx context.Context, arg GetChirpAncestorsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpAncestors, arg.ParentChirpID, arg.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.PublishedAt,
+			&i.TenantID,
+			&i.Sensitive,
+			&i.Source,
+			&i.OauthClientID,
+			&i.ParentChirpID,
+			&i.Locked,
+			&i.RepostOfChirpID,
+			&i.DeletedAt,
+			&i.Language,
+			&i.ContentWarning,
+			&i.LikeCount,
+			&i.ReplyCount,
+			&i.RepostCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getChirpByID = `-- name: GetChirpByID :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-WHERE id = $1
+SELECT id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count FROM chirps
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetChirpByID(ctx context.Context, id uuid.UUID) (Chirp, error) {
@@ -65,17 +278,103 @@ func (q *Queries) GetChirpByID(ctx context.Context, id uuid.UUID) (Chirp, error)
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.PublishedAt,
+		&i.TenantID,
+		&i.Sensitive,
+		&i.Source,
+		&i.OauthClientID,
+		&i.ParentChirpID,
+		&i.Locked,
+		&i.RepostOfChirpID,
+		&i.DeletedAt,
+		&i.Language,
+		&i.ContentWarning,
+		&i.LikeCount,
+		&i.ReplyCount,
+		&i.RepostCount,
 	)
 	return i, err
 }
 
+const getChirpReplies = `-- name: GetChirpReplies :many
+SELECT id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count FROM chirps
+WHERE chirps.tenant_id = $1 AND chirps.parent_chirp_id = $2::uuid
+  AND published_at <= NOW()
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY created_at ASC, id ASC
+`
+
+type GetChirpRepliesParams struct {
+	TenantID      uuid.UUID
+	ParentChirpID uuid.UUID
+}
+
+func (q *Queries) GetChirpReplies(ctx context.Context, arg GetChirpRepliesParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpReplies, arg.TenantID, arg.ParentChirpID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.PublishedAt,
+			&i.TenantID,
+			&i.Sensitive,
+			&i.Source,
+			&i.OauthClientID,
+			&i.ParentChirpID,
+			&i.Locked,
+			&i.RepostOfChirpID,
+			&i.DeletedAt,
+			&i.Language,
+			&i.ContentWarning,
+			&i.LikeCount,
+			&i.ReplyCount,
+			&i.RepostCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getChirpsAsc = `-- name: GetChirpsAsc :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-ORDER BY created_at ASC
+SELECT id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count FROM chirps
+WHERE chirps.tenant_id = $1 AND published_at <= NOW()
+  AND ($2::text IS NULL OR chirps.language = $2::text)
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY created_at ASC, id ASC
 `
 
-func (q *Queries) GetChirpsAsc(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpsAsc)
+type GetChirpsAscParams struct {
+	TenantID uuid.UUID
+	Language sql.NullString
+}
+
+func (q *Queries) GetChirpsAsc(ctx context.Context, arg GetChirpsAscParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsAsc, arg.TenantID, arg.Language)
 	if err != nil {
 		return nil, err
 	}
@@ -89,6 +388,20 @@ func (q *Queries) GetChirpsAsc(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.PublishedAt,
+			&i.TenantID,
+			&i.Sensitive,
+			&i.Source,
+			&i.OauthClientID,
+			&i.ParentChirpID,
+			&i.Locked,
+			&i.RepostOfChirpID,
+			&i.DeletedAt,
+			&i.Language,
+			&i.ContentWarning,
+			&i.LikeCount,
+			&i.ReplyCount,
+			&i.RepostCount,
 		); err != nil {
 			return nil, err
 		}
@@ -104,13 +417,25 @@ func (q *Queries) GetChirpsAsc(ctx context.Context) ([]Chirp, error) {
 }
 
 const getChirpsByAuthorAsc = `-- name: GetChirpsByAuthorAsc :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-WHERE user_id = $1
-ORDER BY created_at ASC
+SELECT id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count FROM chirps
+WHERE chirps.tenant_id = $1 AND chirps.user_id = $2 AND published_at <= NOW()
+  AND ($3::text IS NULL OR chirps.language = $3::text)
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY created_at ASC, id ASC
 `
 
-func (q *Queries) GetChirpsByAuthorAsc(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpsByAuthorAsc, userID)
+type GetChirpsByAuthorAscParams struct {
+	TenantID uuid.UUID
+	UserID   uuid.UUID
+	Language sql.NullString
+}
+
+func (q *Queries) GetChirpsByAuthorAsc(ctx context.Context, arg GetChirpsByAuthorAscParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByAuthorAsc, arg.TenantID, arg.UserID, arg.Language)
 	if err != nil {
 		return nil, err
 	}
@@ -124,6 +449,20 @@ func (q *Queries) GetChirpsByAuthorAsc(ctx context.Context, userID uuid.UUID) ([
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.PublishedAt,
+			&i.TenantID,
+			&i.Sensitive,
+			&i.Source,
+			&i.OauthClientID,
+			&i.ParentChirpID,
+			&i.Locked,
+			&i.RepostOfChirpID,
+			&i.DeletedAt,
+			&i.Language,
+			&i.ContentWarning,
+			&i.LikeCount,
+			&i.ReplyCount,
+			&i.RepostCount,
 		); err != nil {
 			return nil, err
 		}
@@ -139,13 +478,25 @@ func (q *Queries) GetChirpsByAuthorAsc(ctx context.Context, userID uuid.UUID) ([
 }
 
 const getChirpsByAuthorDesc = `-- name: GetChirpsByAuthorDesc :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-WHERE user_id = $1
-ORDER BY created_at DESC
+SELECT id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count FROM chirps
+WHERE chirps.tenant_id = $1 AND chirps.user_id = $2 AND published_at <= NOW()
+  AND ($3::text IS NULL OR chirps.language = $3::text)
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY created_at DESC, id DESC
 `
 
-func (q *Queries) GetChirpsByAuthorDesc(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpsByAuthorDesc, userID)
+type GetChirpsByAuthorDescParams struct {
+	TenantID uuid.UUID
+	UserID   uuid.UUID
+	Language sql.NullString
+}
+
+func (q *Queries) GetChirpsByAuthorDesc(ctx context.Context, arg GetChirpsByAuthorDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByAuthorDesc, arg.TenantID, arg.UserID, arg.Language)
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +510,79 @@ func (q *Queries) GetChirpsByAuthorDesc(ctx context.Context, userID uuid.UUID) (
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.PublishedAt,
+			&i.TenantID,
+			&i.Sensitive,
+			&i.Source,
+			&i.OauthClientID,
+			&i.ParentChirpID,
+			&i.Locked,
+			&i.RepostOfChirpID,
+			&i.DeletedAt,
+			&i.Language,
+			&i.ContentWarning,
+			&i.LikeCount,
+			&i.ReplyCount,
+			&i.RepostCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsByIDs = `-- name: GetChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count FROM chirps
+WHERE chirps.tenant_id = $1 AND chirps.id = ANY($2::uuid[])
+  AND published_at <= NOW()
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+`
+
+type GetChirpsByIDsParams struct {
+	TenantID uuid.UUID
+	Ids      []uuid.UUID
+}
+
+func (q *Queries) GetChirpsByIDs(ctx context.Context, arg GetChirpsByIDsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByIDs, arg.TenantID, pq.Array(arg.Ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.PublishedAt,
+			&i.TenantID,
+			&i.Sensitive,
+			&i.Source,
+			&i.OauthClientID,
+			&i.ParentChirpID,
+			&i.Locked,
+			&i.RepostOfChirpID,
+			&i.DeletedAt,
+			&i.Language,
+			&i.ContentWarning,
+			&i.LikeCount,
+			&i.ReplyCount,
+			&i.RepostCount,
 		); err != nil {
 			return nil, err
 		}
@@ -174,12 +598,24 @@ func (q *Queries) GetChirpsByAuthorDesc(ctx context.Context, userID uuid.UUID) (
 }
 
 const getChirpsDesc = `-- name: GetChirpsDesc :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-ORDER BY created_at DESC
+SELECT id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count FROM chirps
+WHERE chirps.tenant_id = $1 AND published_at <= NOW()
+  AND ($2::text IS NULL OR chirps.language = $2::text)
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY created_at DESC, id DESC
 `
 
-func (q *Queries) GetChirpsDesc(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpsDesc)
+type GetChirpsDescParams struct {
+	TenantID uuid.UUID
+	Language sql.NullString
+}
+
+func (q *Queries) GetChirpsDesc(ctx context.Context, arg GetChirpsDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsDesc, arg.TenantID, arg.Language)
 	if err != nil {
 		return nil, err
 	}
@@ -193,6 +629,20 @@ func (q *Queries) GetChirpsDesc(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.PublishedAt,
+			&i.TenantID,
+			&i.Sensitive,
+			&i.Source,
+			&i.OauthClientID,
+			&i.ParentChirpID,
+			&i.Locked,
+			&i.RepostOfChirpID,
+			&i.DeletedAt,
+			&i.Language,
+			&i.ContentWarning,
+			&i.LikeCount,
+			&i.ReplyCount,
+			&i.RepostCount,
 		); err != nil {
 			return nil, err
 		}
@@ -206,3 +656,746 @@ func (q *Queries) GetChirpsDesc(ctx context.Context) ([]Chirp, error) {
 	}
 	return items, nil
 }
+
+const getChirpsForExport = `-- name: GetChirpsForExport :many
+SELECT id, created_at, updated_at, published_at, body, sensitive, content_warning, language, source, parent_chirp_id, repost_of_chirp_id,
+       FALSE AS archived
+FROM chirps
+WHERE chirps.user_id = $1 AND chirps.deleted_at IS NULL
+  AND (created_at, id) > ($2::timestamp, $3::uuid)
+UNION ALL
+SELECT id, created_at, updated_at, published_at, body, sensitive, content_warning, language, source, parent_chirp_id, repost_of_chirp_id,
+       TRUE AS archived
+FROM chirps_archive
+WHERE chirps_archive.user_id = $1
+  AND (created_at, id) > ($2::timestamp, $3::uuid)
+ORDER BY created_at ASC, id ASC
+LIMIT $4
+`
+
+type GetChirpsForExportParams struct {
+	UserID         uuid.UUID
+	AfterCreatedAt time.Time
+	AfterID        uuid.UUID
+	PageSize       int32
+}
+
+type GetChirpsForExportRow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	PublishedAt     time.Time
+	Body            string
+	Sensitive       bool
+	ContentWarning  string
+	Language        string
+	Source          string
+	ParentChirpID   uuid.NullUUID
+	RepostOfChirpID uuid.NullUUID
+	Archived        bool
+}
+
+// One page of a user's live and archived chirps, oldest first, created
+// after the given chirp. Pass uuid.Nil with the zero time for the first page.
+func (q *Queries) GetChirpsForExport(ctx context.Context, arg GetChirpsForExportParams) ([]GetChirpsForExportRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsForExport,
+		arg.UserID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpsForExportRow
+	for rows.Next() {
+		var i GetChirpsForExportRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PublishedAt,
+			&i.Body,
+			&i.Sensitive,
+			&i.ContentWarning,
+			&i.Language,
+			&i.Source,
+			&i.ParentChirpID,
+			&i.RepostOfChirpID,
+			&i.Archived,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getClientUsage = `-- name: GetClientUsage :many
+SELECT chirps.source,
+       oauth_clients.client_id AS oauth_client_id,
+       COUNT(*)::bigint AS chirps,
+       COUNT(DISTINCT chirps.user_id)::bigint AS authors,
+       MAX(chirps.created_at)::timestamp AS last_chirp_at
+FROM chirps
+LEFT JOIN oauth_clients ON oauth_clients.id = chirps.oauth_client_id
+WHERE chirps.tenant_id = $1 AND chirps.deleted_at IS NULL
+GROUP BY chirps.source, oauth_clients.client_id
+ORDER BY chirps DESC, chirps.source
+`
+
+type GetClientUsageRow struct {
+	Source        string
+	OauthClientID sql.NullString
+	Chirps        int64
+	Authors       int64
+	LastChirpAt   time.Time
+}
+
+// Live chirps of the tenant grouped by the app that posted them, busiest first
+func (q *Queries) GetClientUsage(ctx context.Context, tenantID uuid.UUID) ([]GetClientUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, getClientUsage, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetClientUsageRow
+	for rows.Next() {
+		var i GetClientUsageRow
+		if err := rows.Scan(
+			&i.Source,
+			&i.OauthClientID,
+			&i.Chirps,
+			&i.Authors,
+			&i.LastChirpAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getConversationReplies = `-- name: GetConversationReplies :many
+WITH RECURSIVE replies AS (
+    SELECT chirps.id, 1 AS depth,
+           ARRAY[to_char(chirps.created_at, 'YYYYMMDDHH24MISSUS') || chirps.id::text] AS path
+    FROM chirps
+    WHERE chirps.parent_chirp_id = $1::uuid AND chirps.tenant_id = $2
+    UNION ALL
+    SELECT children.id, replies.depth + 1,
+           replies.path || (to_char(children.created_at, 'YYYYMMDDHH24MISSUS') || children.id::text)
+    FROM chirps AS children
+    JOIN replies ON children.parent_chirp_id = replies.id
+    WHERE children.tenant_id = $2
+)
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.published_at, chirps.tenant_id, chirps.sensitive, chirps.source, chirps.oauth_client_id, chirps.parent_chirp_id, chirps.locked, chirps.repost_of_chirp_id, chirps.deleted_at, chirps.language, chirps.content_warning, chirps.like_count, chirps.reply_count, chirps.repost_count, replies.depth::int AS depth
+FROM replies
+JOIN chirps ON chirps.id = replies.id
+WHERE chirps.published_at <= NOW()
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+  AND ($3::uuid IS NULL
+    OR replies.path > (SELECT cursor.path FROM replies AS cursor WHERE cursor.id = $3::uuid))
+ORDER BY replies.path
+LIMIT $4
+`
+
+type GetConversationRepliesParams struct {
+	ChirpID  uuid.UUID
+	TenantID uuid.UUID
+	AfterID  uuid.NullUUID
+	PageSize int32
+}
+
+type GetConversationRepliesRow struct {
+	Chirp Chirp
+	Depth int32
+}
+
+// One page of the replies below a chirp at any depth, depth first with
+// siblings oldest first; depth is 1 for direct replies. Pass a NULL after_id
+// for the first page. Hidden replies are walked through but left out.
+func (q *Queries) GetConversationReplies(ctx context.Context, arg GetConversationRepliesParams) ([]GetConversationRepliesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getConversationReplies,
+		arg.ChirpID,
+		arg.TenantID,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetConversationRepliesRow
+	for rows.Next() {
+		var i GetConversationRepliesRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.PublishedAt,
+			&i.Chirp.TenantID,
+			&i.Chirp.Sensitive,
+			&i.Chirp.Source,
+			&i.Chirp.OauthClientID,
+			&i.Chirp.ParentChirpID,
+			&i.Chirp.Locked,
+			&i.Chirp.RepostOfChirpID,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.Language,
+			&i.Chirp.ContentWarning,
+			&i.Chirp.LikeCount,
+			&i.Chirp.ReplyCount,
+			&i.Chirp.RepostCount,
+			&i.Depth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLatestChirps = `-- name: GetLatestChirps :many
+SELECT id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count FROM chirps
+WHERE chirps.tenant_id = $1 AND published_at <= NOW()
+  AND (chirps.user_id = $2::uuid OR EXISTS (
+    SELECT 1 FROM follows
+    WHERE follows.follower_id = $2::uuid AND follows.followee_id = chirps.user_id
+  ))
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT $3
+`
+
+type GetLatestChirpsParams struct {
+	TenantID uuid.UUID
+	ViewerID uuid.UUID
+	PageSize int32
+}
+
+// The viewer's chronological feed: the newest chirps of the users they
+// follow and their own, at most page_size of them
+func (q *Queries) GetLatestChirps(ctx context.Context, arg GetLatestChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getLatestChirps, arg.TenantID, arg.ViewerID, arg.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.PublishedAt,
+			&i.TenantID,
+			&i.Sensitive,
+			&i.Source,
+			&i.OauthClientID,
+			&i.ParentChirpID,
+			&i.Locked,
+			&i.RepostOfChirpID,
+			&i.DeletedAt,
+			&i.Language,
+			&i.ContentWarning,
+			&i.LikeCount,
+			&i.ReplyCount,
+			&i.RepostCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserTimeline = `-- name: GetUserTimeline :many
+SELECT id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count FROM chirps
+WHERE chirps.tenant_id = $1 AND chirps.user_id = $2
+  AND (created_at, id) < ($3::timestamp, $4::uuid)
+  AND published_at <= NOW()
+  AND chirps.deleted_at IS NULL
+ORDER BY created_at DESC, id DESC
+LIMIT $5
+`
+
+type GetUserTimelineParams struct {
+	TenantID        uuid.UUID
+	UserID          uuid.UUID
+	BeforeCreatedAt time.Time
+	BeforeID        uuid.UUID
+	PageSize        int32
+}
+
+// One page of an author's chirps, newest first, created before the given
+// chirp. Pass uuid.Max with a time in the future for the first page.
+func (q *Queries) GetUserTimeline(ctx context.Context, arg GetUserTimelineParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getUserTimeline,
+		arg.TenantID,
+		arg.UserID,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.PublishedAt,
+			&i.TenantID,
+			&i.Sensitive,
+			&i.Source,
+			&i.OauthClientID,
+			&i.ParentChirpID,
+			&i.Locked,
+			&i.RepostOfChirpID,
+			&i.DeletedAt,
+			&i.Language,
+			&i.ContentWarning,
+			&i.LikeCount,
+			&i.ReplyCount,
+			&i.RepostCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isChirpReplyTo = `-- name: IsChirpReplyTo :one
+WITH RECURSIVE ancestors AS (
+    SELECT chirps.parent_chirp_id
+    FROM chirps
+    WHERE chirps.id = $1::uuid
+    UNION ALL
+    SELECT parents.parent_chirp_id
+    FROM chirps AS parents
+    JOIN ancestors ON parents.id = ancestors.parent_chirp_id
+)
+SELECT EXISTS (
+    SELECT 1 FROM ancestors WHERE ancestors.parent_chirp_id = $2::uuid
+)::boolean AS reply
+`
+
+type IsChirpReplyToParams struct {
+	ChirpID    uuid.UUID
+	AncestorID uuid.UUID
+}
+
+// Reports whether a chirp is a reply to the ancestor chirp at any depth
+func (q *Queries) IsChirpReplyTo(ctx context.Context, arg IsChirpReplyToParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isChirpReplyTo, arg.ChirpID, arg.AncestorID)
+	var reply bool
+	err := row.Scan(&reply)
+	return reply, err
+}
+
+const purgeDeletedChirps = `-- name: PurgeDeletedChirps :execrows
+DELETE FROM chirps
+WHERE deleted_at < $1::timestamp
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.legal_hold
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM moderation_verdicts
+    WHERE moderation_verdicts.chirp_id = chirps.id AND moderation_verdicts.reviewed_at IS NULL
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM appeals
+    WHERE appeals.chirp_id = chirps.id AND appeals.decided_at IS NULL
+  )
+`
+
+// Chirps of users under legal hold are kept until the hold is released,
+// chirps with a verdict until a moderator has reviewed it, and chirps with
+// an appeal until it is decided
+func (q *Queries) PurgeDeletedChirps(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedChirps, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const reconcileChirpCounters = `-- name: ReconcileChirpCounters :execrows
+WITH counts AS (
+    SELECT chirps.id,
+           (SELECT COUNT(*) FROM chirp_likes AS likes JOIN users ON users.id = likes.user_id
+            WHERE likes.chirp_id = chirps.id AND users.deactivated_at IS NULL) AS like_count,
+           (SELECT COUNT(*) FROM chirps AS replies JOIN users ON users.id = replies.user_id
+            WHERE replies.parent_chirp_id = chirps.id AND replies.deleted_at IS NULL AND users.deactivated_at IS NULL)
+         + (SELECT COUNT(*) FROM chirps_archive AS replies JOIN users ON users.id = replies.user_id
+            WHERE replies.parent_chirp_id = chirps.id AND users.deactivated_at IS NULL) AS reply_count,
+           (SELECT COUNT(*) FROM chirps AS reposts JOIN users ON users.id = reposts.user_id
+            WHERE reposts.repost_of_chirp_id = chirps.id AND reposts.deleted_at IS NULL AND users.deactivated_at IS NULL)
+         + (SELECT COUNT(*) FROM chirps_archive AS reposts JOIN users ON users.id = reposts.user_id
+            WHERE reposts.repost_of_chirp_id = chirps.id AND users.deactivated_at IS NULL) AS repost_count
+    FROM chirps
+)
+UPDATE chirps
+SET like_count = counts.like_count, reply_count = counts.reply_count, repost_count = counts.repost_count
+FROM counts
+WHERE chirps.id = counts.id
+  AND (chirps.like_count, chirps.reply_count, chirps.repost_count)
+      <> (counts.like_count, counts.reply_count, counts.repost_count)
+`
+
+// Recounts the likes, replies and reposts of every chirp, fixing the
+// counters that drifted from their rows. Only active accounts count, and
+// replies and reposts are counted live or archived unless deleted.
+func (q *Queries) ReconcileChirpCounters(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reconcileChirpCounters)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreChirp = `-- name: RestoreChirp :one
+WITH restored AS (
+    UPDATE chirps
+    SET deleted_at = NULL
+    WHERE chirps.id = $1 AND chirps.user_id = $2 AND chirps.tenant_id = $3
+      AND chirps.deleted_at > $4::timestamp
+      AND NOT EXISTS (
+        SELECT 1 FROM reports
+        WHERE reports.chirp_id = chirps.id AND reports.resolution = 'removed'
+      )
+      AND NOT EXISTS (
+        SELECT 1 FROM moderation_verdicts
+        WHERE moderation_verdicts.chirp_id = chirps.id AND moderation_verdicts.verdict = 'remove'
+          AND moderation_verdicts.outcome IS DISTINCT FROM 'overturned'
+      )
+    RETURNING id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count
+), counted AS (
+    UPDATE chirps
+    SET reply_count = chirps.reply_count + ((chirps.id = restored.parent_chirp_id) IS TRUE)::int,
+        repost_count = chirps.repost_count + ((chirps.id = restored.repost_of_chirp_id) IS TRUE)::int
+    FROM restored
+    WHERE chirps.id IN (restored.parent_chirp_id, restored.repost_of_chirp_id)
+), counted_archive AS (
+    UPDATE chirps_archive
+    SET reply_count = chirps_archive.reply_count + ((chirps_archive.id = restored.parent_chirp_id) IS TRUE)::int,
+        repost_count = chirps_archive.repost_count + ((chirps_archive.id = restored.repost_of_chirp_id) IS TRUE)::int
+    FROM restored
+    WHERE chirps_archive.id IN (restored.parent_chirp_id, restored.repost_of_chirp_id)
+)
+SELECT id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count FROM restored
+`
+
+type RestoreChirpParams struct {
+	ID       uuid.UUID
+	UserID   uuid.UUID
+	TenantID uuid.UUID
+	Cutoff   time.Time
+}
+
+// Returns no row unless the author deleted the chirp after the cutoff.
+// Chirps removed by a moderator, or hidden by the classifier unless a
+// moderator overturned its verdict, can't be restored. A restored reply or
+// repost is counted again on the chirp it answers or shares.
+func (q *Queries) RestoreChirp(ctx context.Context, arg RestoreChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, restoreChirp,
+		arg.ID,
+		arg.UserID,
+		arg.TenantID,
+		arg.Cutoff,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.PublishedAt,
+		&i.TenantID,
+		&i.Sensitive,
+		&i.Source,
+		&i.OauthClientID,
+		&i.ParentChirpID,
+		&i.Locked,
+		&i.RepostOfChirpID,
+		&i.DeletedAt,
+		&i.Language,
+		&i.ContentWarning,
+		&i.LikeCount,
+		&i.ReplyCount,
+		&i.RepostCount,
+	)
+	return i, err
+}
+
+const searchChirps = `-- name: SearchChirps :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.published_at, chirps.tenant_id, chirps.sensitive, chirps.source, chirps.oauth_client_id, chirps.parent_chirp_id, chirps.locked, chirps.repost_of_chirp_id, chirps.deleted_at, chirps.language, chirps.content_warning, chirps.like_count, chirps.reply_count, chirps.repost_count FROM chirps
+WHERE chirps.tenant_id = $1 AND published_at <= NOW()
+  AND to_tsvector('english', body) @@ websearch_to_tsquery('english', $2::text)
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY ts_rank(to_tsvector('english', body), websearch_to_tsquery('english', $2::text)) DESC, created_at DESC, id DESC
+LIMIT $4::int OFFSET $3::int
+`
+
+type SearchChirpsParams struct {
+	TenantID   uuid.UUID
+	Query      string
+	PageOffset int32
+	PageSize   int32
+}
+
+// Best matches first. The to_tsvector expression matches idx_chirps_search.
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirps,
+		arg.TenantID,
+		arg.Query,
+		arg.PageOffset,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.PublishedAt,
+			&i.TenantID,
+			&i.Sensitive,
+			&i.Source,
+			&i.OauthClientID,
+			&i.ParentChirpID,
+			&i.Locked,
+			&i.RepostOfChirpID,
+			&i.DeletedAt,
+			&i.Language,
+			&i.ContentWarning,
+			&i.LikeCount,
+			&i.ReplyCount,
+			&i.RepostCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setChirpLocked = `-- name: SetChirpLocked :one
+WITH updated AS (
+    UPDATE chirps
+    SET locked = $1
+    WHERE chirps.id = $2 AND chirps.tenant_id = $3 AND chirps.deleted_at IS NULL
+    RETURNING id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count
+), audit AS (
+    INSERT INTO admin_audit_log (id, created_at, actor_id, action, target_user_id, details)
+    SELECT gen_random_uuid(), NOW(), $4, $5, updated.user_id, updated.id::text
+    FROM updated
+)
+SELECT id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count FROM updated
+`
+
+type SetChirpLockedParams struct {
+	Locked   bool
+	ID       uuid.UUID
+	TenantID uuid.UUID
+	ActorID  uuid.UUID
+	Action   string
+}
+
+type SetChirpLockedRow struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Body            string
+	UserID          uuid.UUID
+	PublishedAt     time.Time
+	TenantID        uuid.UUID
+	Sensitive       bool
+	Source          string
+	OauthClientID   uuid.NullUUID
+	ParentChirpID   uuid.NullUUID
+	Locked          bool
+	RepostOfChirpID uuid.NullUUID
+	DeletedAt       sql.NullTime
+	Language        string
+	ContentWarning  string
+	LikeCount       int64
+	ReplyCount      int64
+	RepostCount     int64
+}
+
+// Records the change in the audit log against the chirp's author, with the
+// chirp ID as details
+func (q *Queries) SetChirpLocked(ctx context.Context, arg SetChirpLockedParams) (SetChirpLockedRow, error) {
+	row := q.db.QueryRowContext(ctx, setChirpLocked,
+		arg.Locked,
+		arg.ID,
+		arg.TenantID,
+		arg.ActorID,
+		arg.Action,
+	)
+	var i SetChirpLockedRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.PublishedAt,
+		&i.TenantID,
+		&i.Sensitive,
+		&i.Source,
+		&i.OauthClientID,
+		&i.ParentChirpID,
+		&i.Locked,
+		&i.RepostOfChirpID,
+		&i.DeletedAt,
+		&i.Language,
+		&i.ContentWarning,
+		&i.LikeCount,
+		&i.ReplyCount,
+		&i.RepostCount,
+	)
+	return i, err
+}
+
+const setChirpSensitive = `-- name: SetChirpSensitive :one
+UPDATE chirps
+SET sensitive = $2, content_warning = $3
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, deleted_at, language, content_warning, like_count, reply_count, repost_count
+`
+
+type SetChirpSensitiveParams struct {
+	ID             uuid.UUID
+	Sensitive      bool
+	ContentWarning string
+}
+
+func (q *Queries) SetChirpSensitive(ctx context.Context, arg SetChirpSensitiveParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, setChirpSensitive,
+		arg.ID,
+		arg.Sensitive,
+		arg.ContentWarning,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.PublishedAt,
+		&i.TenantID,
+		&i.Sensitive,
+		&i.Source,
+		&i.OauthClientID,
+		&i.ParentChirpID,
+		&i.Locked,
+		&i.RepostOfChirpID,
+		&i.DeletedAt,
+		&i.Language,
+		&i.ContentWarning,
+		&i.LikeCount,
+		&i.ReplyCount,
+		&i.RepostCount,
+	)
+	return i, err
+}
+
+const softDeleteChirp = `-- name: SoftDeleteChirp :exec
+WITH deleted AS (
+    UPDATE chirps
+    SET deleted_at = NOW()
+    WHERE id = $1 AND deleted_at IS NULL
+    RETURNING parent_chirp_id, repost_of_chirp_id
+), counted_archive AS (
+    UPDATE chirps_archive
+    SET reply_count = GREATEST(chirps_archive.reply_count - ((chirps_archive.id = deleted.parent_chirp_id) IS TRUE)::int, 0),
+        repost_count = GREATEST(chirps_archive.repost_count - ((chirps_archive.id = deleted.repost_of_chirp_id) IS TRUE)::int, 0)
+    FROM deleted
+    WHERE chirps_archive.id IN (deleted.parent_chirp_id, deleted.repost_of_chirp_id)
+)
+UPDATE chirps
+SET reply_count = GREATEST(chirps.reply_count - ((chirps.id = deleted.parent_chirp_id) IS TRUE)::int, 0),
+    repost_count = GREATEST(chirps.repost_count - ((chirps.id = deleted.repost_of_chirp_id) IS TRUE)::int, 0)
+FROM deleted
+WHERE chirps.id IN (deleted.parent_chirp_id, deleted.repost_of_chirp_id)
+`
+
+// A reply or repost stops being counted on the chirp it answers or shares
+func (q *Queries) SoftDeleteChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, softDeleteChirp, id)
+	return err
+}
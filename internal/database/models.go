@@ -11,12 +11,325 @@ import (
 	"github.com/google/uuid"
 )
 
-type Chirp struct {
+type AccountDeletion struct {
+	UserID      uuid.UUID
+	RequestedAt time.Time
+	DeleteAfter time.Time
+}
+
+type AccountFingerprint struct {
+	UserID      uuid.UUID
+	Kind        string
+	Hash        string
+	FirstSeenAt time.Time
+	LastSeenAt  time.Time
+}
+
+type AccountMigration struct {
+	Origin        string
+	SourceAccount string
+	UserID        uuid.UUID
+	ImportedAt    time.Time
+}
+
+type AdminAuditLog struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	ActorID      uuid.UUID
+	Action       string
+	TargetUserID uuid.NullUUID
+	Details      string
+}
+
+type ApiKey struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	KeyHash         string
+	Name            string
+	Tier            string
+	TenantID        uuid.UUID
+	Requests        int64
+	ChirpsDelivered int64
+	LastUsedAt      sql.NullTime
+	RevokedAt       sql.NullTime
+}
+
+type Appeal struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
+	TenantID  uuid.UUID
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	Reason    string
+	DecidedAt sql.NullTime
+	DecidedBy uuid.NullUUID
+	Decision  sql.NullString
+}
+
+type BannedWord struct {
+	Word      string
+	CreatedAt time.Time
+}
+
+type Chirp struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Body            string
+	UserID          uuid.UUID
+	PublishedAt     time.Time
+	TenantID        uuid.UUID
+	Sensitive       bool
+	Source          string
+	OauthClientID   uuid.NullUUID
+	ParentChirpID   uuid.NullUUID
+	Locked          bool
+	RepostOfChirpID uuid.NullUUID
+	DeletedAt       sql.NullTime
+	Language        string
+	ContentWarning  string
+	LikeCount       int64
+	ReplyCount      int64
+	RepostCount     int64
+}
+
+type ChirpCoauthor struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	Status    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type ChirpCoauthorsArchive struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	Status    string
+	CreatedAt time.Time
 	UpdatedAt time.Time
+}
+
+type ChirpHashtag struct {
+	ChirpID   uuid.UUID
+	Tag       string
+	CreatedAt time.Time
+}
+
+type ChirpLike struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpLikesArchive struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpLink struct {
+	ChirpID uuid.UUID
+	Url     string
+}
+
+type ChirpMediaArchive struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	ChirpID   uuid.UUID
+	Position  int32
+	Url       string
+	AltText   string
+}
+
+type ChirpMedium struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	ChirpID   uuid.UUID
+	Position  int32
+	Url       string
+	AltText   string
+}
+
+type ChirpMention struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpReaction struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	Emoji     string
+	CreatedAt time.Time
+}
+
+type ChirpReactionsArchive struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	Emoji     string
+	CreatedAt time.Time
+}
+
+type ChirpRevision struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	ChirpID   uuid.UUID
+	Revision  int32
+	Body      string
+}
+
+type ChirpRevisionsArchive struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	ChirpID   uuid.UUID
+	Revision  int32
 	Body      string
+}
+
+type ChirpView struct {
+	ChirpID   uuid.UUID
+	ViewCount int64
+}
+
+type ChirpViewsArchive struct {
+	ChirpID   uuid.UUID
+	ViewCount int64
+}
+
+type ChirpsArchive struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Body            string
+	UserID          uuid.UUID
+	PublishedAt     time.Time
+	ArchivedAt      time.Time
+	TenantID        uuid.UUID
+	Sensitive       bool
+	Source          string
+	OauthClientID   uuid.NullUUID
+	ParentChirpID   uuid.NullUUID
+	Locked          bool
+	RepostOfChirpID uuid.NullUUID
+	Language        string
+	ContentWarning  string
+	LikeCount       int64
+	ReplyCount      int64
+	RepostCount     int64
+}
+
+type CustomDomain struct {
+	Domain            string
+	CreatedAt         time.Time
+	TenantID          uuid.UUID
+	UserID            uuid.NullUUID
+	VerificationToken string
+	VerifiedAt        sql.NullTime
+	CheckedAt         sql.NullTime
+}
+
+type Draft struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	UserID        uuid.UUID
+	Body          string
+	Sensitive     bool
+	ParentChirpID uuid.NullUUID
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type Identity struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
 	UserID    uuid.UUID
+	TenantID  uuid.UUID
+	Issuer    string
+	Subject   string
+}
+
+type LinkPreview struct {
+	Url         string
+	FetchedAt   time.Time
+	Title       string
+	Description string
+	ImageUrl    string
+}
+
+type LinkedAccount struct {
+	UserID       uuid.UUID
+	LinkedUserID uuid.UUID
+	CreatedAt    time.Time
+}
+
+type ModerationVerdict struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	TenantID   uuid.UUID
+	ChirpID    uuid.UUID
+	Verdict    string
+	Label      string
+	Score      float64
+	ReviewedAt sql.NullTime
+	ReviewedBy uuid.NullUUID
+	Outcome    sql.NullString
+}
+
+type ModerationWebhook struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	TenantID  uuid.UUID
+	Url       string
+	Secret    string
+	Events    []string
+}
+
+type OauthClient struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	ClientID     string
+	SecretHash   string
+	Name         string
+	RedirectUris []string
+	UserID       uuid.UUID
+	TenantID     uuid.UUID
+}
+
+type OauthCode struct {
+	Code          string
+	CreatedAt     time.Time
+	ClientID      uuid.UUID
+	UserID        uuid.UUID
+	RedirectUri   string
+	Scopes        []string
+	CodeChallenge string
+	ExpiresAt     time.Time
+	UsedAt        sql.NullTime
+}
+
+type OauthToken struct {
+	Token     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ClientID  uuid.UUID
+	UserID    uuid.UUID
+	Scopes    []string
+	ExpiresAt time.Time
+	RevokedAt sql.NullTime
+}
+
+type PasswordResetToken struct {
+	TokenHash string
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	UsedAt    sql.NullTime
 }
 
 type RefreshToken struct {
@@ -28,6 +341,38 @@ type RefreshToken struct {
 	RevokedAt sql.NullTime
 }
 
+type Report struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	TenantID   uuid.UUID
+	ChirpID    uuid.UUID
+	ReporterID uuid.UUID
+	Reason     string
+	Details    string
+	ResolvedAt sql.NullTime
+	ResolvedBy uuid.NullUUID
+	Resolution sql.NullString
+}
+
+type ScheduledChirp struct {
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type ScimUser struct {
+	UserID     uuid.UUID
+	ExternalID sql.NullString
+	Suspended  bool
+}
+
+type Tenant struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	Slug        string
+	Name        string
+	Description string
+}
+
 type User struct {
 	ID             uuid.UUID
 	CreatedAt      time.Time
@@ -35,4 +380,40 @@ type User struct {
 	Email          string
 	HashedPassword string
 	IsChirpyRed    bool
+	Role           string
+	DeactivatedAt  sql.NullTime
+	Username       sql.NullString
+	Verified       bool
+	TenantID       uuid.UUID
+	LegalHold      bool
+	DisplayName    string
+	Bio            string
+	Location       string
+	Website        string
+	AvatarUrl      string
+}
+
+type UserMutedWord struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UserID    uuid.UUID
+	Phrase    string
+}
+
+type UserPreference struct {
+	UserID           uuid.UUID
+	UpdatedAt        time.Time
+	SensitiveContent string
+}
+
+type UserRecap struct {
+	UserID             uuid.UUID
+	Period             string
+	PeriodStart        time.Time
+	PeriodEnd          time.Time
+	CreatedAt          time.Time
+	TotalChirps        int32
+	MostLikedChirpID   uuid.NullUUID
+	MostLikedReactions int32
+	TopHashtags        []string
 }
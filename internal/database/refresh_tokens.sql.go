@@ -44,15 +44,34 @@ func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshToken
 	return i, err
 }
 
+const deleteExpiredRefreshTokens = `-- name: DeleteExpiredRefreshTokens :execrows
+DELETE FROM refresh_tokens
+WHERE expires_at < $1::timestamp
+`
+
+func (q *Queries) DeleteExpiredRefreshTokens(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredRefreshTokens, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getUserFromRefreshToken = `-- name: GetUserFromRefreshToken :one
 SELECT users.id, users.created_at, users.updated_at, users.email, users.hashed_password 
 FROM refresh_tokens
 JOIN users ON refresh_tokens.user_id = users.id
 WHERE refresh_tokens.token = $1 
+  AND users.tenant_id = $2
   AND refresh_tokens.expires_at > NOW() 
   AND refresh_tokens.revoked_at IS NULL
 `
 
+type GetUserFromRefreshTokenParams struct {
+	Token    string
+	TenantID uuid.UUID
+}
+
 type GetUserFromRefreshTokenRow struct {
 	ID             uuid.UUID
 	CreatedAt      time.Time
@@ -61,8 +80,8 @@ type GetUserFromRefreshTokenRow struct {
 	HashedPassword string
 }
 
-func (q *Queries) GetUserFromRefreshToken(ctx context.Context, token string) (GetUserFromRefreshTokenRow, error) {
-	row := q.db.QueryRowContext(ctx, getUserFromRefreshToken, token)
+func (q *Queries) GetUserFromRefreshToken(ctx context.Context, arg GetUserFromRefreshTokenParams) (GetUserFromRefreshTokenRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserFromRefreshToken, arg.Token, arg.TenantID)
 	var i GetUserFromRefreshTokenRow
 	err := row.Scan(
 		&i.ID,
@@ -94,3 +113,20 @@ func (q *Queries) RevokeRefreshToken(ctx context.Context, token string) (Refresh
 	)
 	return i, err
 }
+
+const revokeUserRefreshTokens = `-- name: RevokeUserRefreshTokens :exec
+WITH oauth AS (
+    UPDATE oauth_tokens
+    SET revoked_at = NOW(), updated_at = NOW()
+    WHERE oauth_tokens.user_id = $1 AND oauth_tokens.revoked_at IS NULL
+)
+UPDATE refresh_tokens
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE refresh_tokens.user_id = $1 AND refresh_tokens.revoked_at IS NULL
+`
+
+// Revokes the user's own refresh tokens and those issued to OAuth clients
+func (q *Queries) RevokeUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, revokeUserRefreshTokens, userID)
+	return err
+}
@@ -10,6 +10,11 @@ import (
 )
 
 const reset = `-- name: Reset :exec
+WITH audit AS (
+    DELETE FROM admin_audit_log
+), extra_tenants AS (
+    DELETE FROM tenants WHERE slug <> 'default'
+)
 DELETE FROM users
 `
 
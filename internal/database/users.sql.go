@@ -7,8 +7,11 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createUser = `-- name: CreateUser :one
@@ -19,7 +22,7 @@ VALUES (
     NOW(),
     $1
 )
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url
 `
 
 func (q *Queries) CreateUser(ctx context.Context, email string) (User, error) {
@@ -32,29 +35,49 @@ func (q *Queries) CreateUser(ctx context.Context, email string) (User, error) {
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.Username,
+		&i.Verified,
+		&i.TenantID,
+		&i.LegalHold,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.Website,
+		&i.AvatarUrl,
 	)
 	return i, err
 }
 
 const createUserWithPassword = `-- name: CreateUserWithPassword :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, username, tenant_id)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3,
+    $4
 )
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url
 `
 
 type CreateUserWithPasswordParams struct {
 	Email          string
 	HashedPassword string
+	Username       sql.NullString
+	TenantID       uuid.UUID
 }
 
 func (q *Queries) CreateUserWithPassword(ctx context.Context, arg CreateUserWithPasswordParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUserWithPassword, arg.Email, arg.HashedPassword)
+	row := q.db.QueryRowContext(ctx, createUserWithPassword,
+		arg.Email,
+		arg.HashedPassword,
+		arg.Username,
+		arg.TenantID,
+	)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -63,16 +86,108 @@ func (q *Queries) CreateUserWithPassword(ctx context.Context, arg CreateUserWith
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.Username,
+		&i.Verified,
+		&i.TenantID,
+		&i.LegalHold,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.Website,
+		&i.AvatarUrl,
 	)
 	return i, err
 }
 
+const deactivateUser = `-- name: DeactivateUser :exec
+UPDATE users
+SET deactivated_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deactivated_at IS NULL
+`
+
+func (q *Queries) DeactivateUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deactivateUser, id)
+	return err
+}
+
+const deleteDeactivatedUsers = `-- name: DeleteDeactivatedUsers :execrows
+DELETE FROM users
+WHERE deactivated_at IS NOT NULL AND deactivated_at < $1::timestamp
+  AND NOT legal_hold
+  AND id NOT IN (SELECT user_id FROM account_deletions)
+`
+
+// Accounts under legal hold are kept until the hold is released, and
+// accounts their owners asked to delete wait for their own deadline
+func (q *Queries) DeleteDeactivatedUsers(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteDeactivatedUsers, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getChirpAuthors = `-- name: GetChirpAuthors :many
+SELECT id, username, verified, display_name, bio, location, website, avatar_url FROM users
+WHERE id = ANY($1::uuid[])
+`
+
+type GetChirpAuthorsRow struct {
+	ID          uuid.UUID
+	Username    sql.NullString
+	Verified    bool
+	DisplayName string
+	Bio         string
+	Location    string
+	Website     string
+	AvatarUrl   string
+}
+
+func (q *Queries) GetChirpAuthors(ctx context.Context, userIds []uuid.UUID) ([]GetChirpAuthorsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpAuthors, pq.Array(userIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpAuthorsRow
+	for rows.Next() {
+		var i GetChirpAuthorsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Verified,
+			&i.DisplayName,
+			&i.Bio,
+			&i.Location,
+			&i.Website,
+			&i.AvatarUrl,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users WHERE email = $1
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url FROM users WHERE tenant_id = $1 AND email = $2
 `
 
-func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+type GetUserByEmailParams struct {
+	TenantID uuid.UUID
+	Email    string
+}
+
+func (q *Queries) GetUserByEmail(ctx context.Context, arg GetUserByEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, arg.TenantID, arg.Email)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -81,25 +196,387 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.Username,
+		&i.Verified,
+		&i.TenantID,
+		&i.LegalHold,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.Website,
+		&i.AvatarUrl,
 	)
 	return i, err
 }
 
-const updateUser = `-- name: UpdateUser :one
-UPDATE users 
-SET email = $2, hashed_password = $3, updated_at = NOW()
+const getUserByID = `-- name: GetUserByID :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url FROM users WHERE tenant_id = $1 AND id = $2
+`
+
+type GetUserByIDParams struct {
+	TenantID uuid.UUID
+	ID       uuid.UUID
+}
+
+func (q *Queries) GetUserByID(ctx context.Context, arg GetUserByIDParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, arg.TenantID, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.Username,
+		&i.Verified,
+		&i.TenantID,
+		&i.LegalHold,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.Website,
+		&i.AvatarUrl,
+	)
+	return i, err
+}
+
+const getUserByUsername = `-- name: GetUserByUsername :one
+SELECT id, created_at, username, verified, display_name, bio, location, website, avatar_url FROM users
+WHERE tenant_id = $1 AND username = $2 AND deactivated_at IS NULL
+`
+
+type GetUserByUsernameParams struct {
+	TenantID uuid.UUID
+	Username sql.NullString
+}
+
+type GetUserByUsernameRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	Username    sql.NullString
+	Verified    bool
+	DisplayName string
+	Bio         string
+	Location    string
+	Website     string
+	AvatarUrl   string
+}
+
+// Public profile of an active user, looked up by handle
+func (q *Queries) GetUserByUsername(ctx context.Context, arg GetUserByUsernameParams) (GetUserByUsernameRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserByUsername, arg.TenantID, arg.Username)
+	var i GetUserByUsernameRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.Username,
+		&i.Verified,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.Website,
+		&i.AvatarUrl,
+	)
+	return i, err
+}
+
+const getUserRole = `-- name: GetUserRole :one
+SELECT role FROM users WHERE id = $1
+`
+
+func (q *Queries) GetUserRole(ctx context.Context, id uuid.UUID) (string, error) {
+	row := q.db.QueryRowContext(ctx, getUserRole, id)
+	var role string
+	err := row.Scan(&role)
+	return role, err
+}
+
+const isActiveUserInTenant = `-- name: IsActiveUserInTenant :one
+SELECT EXISTS (
+    SELECT 1 FROM users
+    WHERE id = $1 AND tenant_id = $2 AND deactivated_at IS NULL
+)::boolean AS found
+`
+
+type IsActiveUserInTenantParams struct {
+	ID       uuid.UUID
+	TenantID uuid.UUID
+}
+
+func (q *Queries) IsActiveUserInTenant(ctx context.Context, arg IsActiveUserInTenantParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isActiveUserInTenant, arg.ID, arg.TenantID)
+	var found bool
+	err := row.Scan(&found)
+	return found, err
+}
+
+const isUserActive = `-- name: IsUserActive :one
+SELECT (deactivated_at IS NULL)::boolean AS active FROM users WHERE id = $1
+`
+
+func (q *Queries) IsUserActive(ctx context.Context, id uuid.UUID) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isUserActive, id)
+	var active bool
+	err := row.Scan(&active)
+	return active, err
+}
+
+const isUserOnLegalHold = `-- name: IsUserOnLegalHold :one
+SELECT legal_hold FROM users WHERE id = $1
+`
+
+func (q *Queries) IsUserOnLegalHold(ctx context.Context, id uuid.UUID) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isUserOnLegalHold, id)
+	var legal_hold bool
+	err := row.Scan(&legal_hold)
+	return legal_hold, err
+}
+
+const reactivateUser = `-- name: ReactivateUser :one
+UPDATE users
+SET deactivated_at = NULL, updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url
 `
 
-type UpdateUserParams struct {
+func (q *Queries) ReactivateUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, reactivateUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.Username,
+		&i.Verified,
+		&i.TenantID,
+		&i.LegalHold,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.Website,
+		&i.AvatarUrl,
+	)
+	return i, err
+}
+
+const replayUserUpgrade = `-- name: ReplayUserUpgrade :execrows
+UPDATE users
+SET is_chirpy_red = TRUE, updated_at = GREATEST(updated_at, $1::timestamp)
+WHERE id = $2
+`
+
+type ReplayUserUpgradeParams struct {
+	UpgradedAt time.Time
+	ID         uuid.UUID
+}
+
+// Reapplies a journaled upgrade; updated_at never moves backwards
+func (q *Queries) ReplayUserUpgrade(ctx context.Context, arg ReplayUserUpgradeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, replayUserUpgrade, arg.UpgradedAt, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const setUserLegalHold = `-- name: SetUserLegalHold :one
+WITH updated AS (
+    UPDATE users
+    SET legal_hold = $1, updated_at = NOW()
+    WHERE users.id = $2 AND users.tenant_id = $3
+    RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url
+), audit AS (
+    INSERT INTO admin_audit_log (id, created_at, actor_id, action, target_user_id)
+    SELECT gen_random_uuid(), NOW(), $4, $5, updated.id
+    FROM updated
+)
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url
+FROM updated
+`
+
+type SetUserLegalHoldParams struct {
+	LegalHold bool
+	ID        uuid.UUID
+	TenantID  uuid.UUID
+	ActorID   uuid.UUID
+	Action    string
+}
+
+type SetUserLegalHoldRow struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Email          string
+	HashedPassword string
+	IsChirpyRed    bool
+	Role           string
+	DeactivatedAt  sql.NullTime
+	Username       sql.NullString
+	Verified       bool
+	TenantID       uuid.UUID
+	LegalHold      bool
+	DisplayName    string
+	Bio            string
+	Location       string
+	Website        string
+	AvatarUrl      string
+}
+
+func (q *Queries) SetUserLegalHold(ctx context.Context, arg SetUserLegalHoldParams) (SetUserLegalHoldRow, error) {
+	row := q.db.QueryRowContext(ctx, setUserLegalHold,
+		arg.LegalHold,
+		arg.ID,
+		arg.TenantID,
+		arg.ActorID,
+		arg.Action,
+	)
+	var i SetUserLegalHoldRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.Username,
+		&i.Verified,
+		&i.TenantID,
+		&i.LegalHold,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.Website,
+		&i.AvatarUrl,
+	)
+	return i, err
+}
+
+const setUserVerified = `-- name: SetUserVerified :one
+WITH updated AS (
+    UPDATE users
+    SET verified = $1, updated_at = NOW()
+    WHERE users.id = $2 AND users.tenant_id = $3
+    RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url
+), audit AS (
+    INSERT INTO admin_audit_log (id, created_at, actor_id, action, target_user_id)
+    SELECT gen_random_uuid(), NOW(), $4, $5, updated.id
+    FROM updated
+)
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url
+FROM updated
+`
+
+type SetUserVerifiedParams struct {
+	Verified bool
+	ID       uuid.UUID
+	TenantID uuid.UUID
+	ActorID  uuid.UUID
+	Action   string
+}
+
+type SetUserVerifiedRow struct {
 	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
 	Email          string
 	HashedPassword string
+	IsChirpyRed    bool
+	Role           string
+	DeactivatedAt  sql.NullTime
+	Username       sql.NullString
+	Verified       bool
+	TenantID       uuid.UUID
+	LegalHold      bool
+	DisplayName    string
+	Bio            string
+	Location       string
+	Website        string
+	AvatarUrl      string
 }
 
+func (q *Queries) SetUserVerified(ctx context.Context, arg SetUserVerifiedParams) (SetUserVerifiedRow, error) {
+	row := q.db.QueryRowContext(ctx, setUserVerified,
+		arg.Verified,
+		arg.ID,
+		arg.TenantID,
+		arg.ActorID,
+		arg.Action,
+	)
+	var i SetUserVerifiedRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.Username,
+		&i.Verified,
+		&i.TenantID,
+		&i.LegalHold,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.Website,
+		&i.AvatarUrl,
+	)
+	return i, err
+}
+
+const updateUser = `-- name: UpdateUser :one
+UPDATE users 
+SET email = COALESCE($1, email),
+    hashed_password = COALESCE($2, hashed_password),
+    username = COALESCE($3, username),
+    display_name = COALESCE($4, display_name),
+    bio = COALESCE($5, bio),
+    location = COALESCE($6, location),
+    website = COALESCE($7, website),
+    avatar_url = COALESCE($8, avatar_url),
+    updated_at = NOW()
+WHERE id = $9
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url
+`
+
+type UpdateUserParams struct {
+	Email          sql.NullString
+	HashedPassword sql.NullString
+	Username       sql.NullString
+	DisplayName    sql.NullString
+	Bio            sql.NullString
+	Location       sql.NullString
+	Website        sql.NullString
+	AvatarUrl      sql.NullString
+	ID             uuid.UUID
+}
+
+// NULL arguments keep the stored value
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUser, arg.ID, arg.Email, arg.HashedPassword)
+	row := q.db.QueryRowContext(ctx, updateUser,
+		arg.Email,
+		arg.HashedPassword,
+		arg.Username,
+		arg.DisplayName,
+		arg.Bio,
+		arg.Location,
+		arg.Website,
+		arg.AvatarUrl,
+		arg.ID,
+	)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -108,6 +585,17 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.Username,
+		&i.Verified,
+		&i.TenantID,
+		&i.LegalHold,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.Website,
+		&i.AvatarUrl,
 	)
 	return i, err
 }
@@ -116,7 +604,7 @@ const upgradeUserToChirpyRed = `-- name: UpgradeUserToChirpyRed :one
 UPDATE users 
 SET is_chirpy_red = TRUE, updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url
 `
 
 func (q *Queries) UpgradeUserToChirpyRed(ctx context.Context, id uuid.UUID) (User, error) {
@@ -129,6 +617,17 @@ func (q *Queries) UpgradeUserToChirpyRed(ctx context.Context, id uuid.UUID) (Use
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.Role,
+		&i.DeactivatedAt,
+		&i.Username,
+		&i.Verified,
+		&i.TenantID,
+		&i.LegalHold,
+		&i.DisplayName,
+		&i.Bio,
+		&i.Location,
+		&i.Website,
+		&i.AvatarUrl,
 	)
 	return i, err
 }
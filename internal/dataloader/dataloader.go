@@ -0,0 +1,119 @@
+// Package dataloader batches and caches lookups by ID for the lifetime of a
+// request, so building responses that embed related records (authors, media,
+// counts) costs one query per kind of record rather than one per item.
+package dataloader
+
+import (
+	"context"
+	"sync"
+)
+
+// BatchFunc loads the values for keys in a single call. Keys without a
+// value are simply left out of the result.
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader deduplicates keys, fetches the ones it hasn't seen with one call to
+// its BatchFunc and remembers the results, including misses
+type Loader[K comparable, V any] struct {
+	batch BatchFunc[K, V]
+
+	mu    sync.Mutex
+	cache map[K]result[V]
+}
+
+type result[V any] struct {
+	value V
+	found bool
+}
+
+// New creates a Loader backed by batch
+func New[K comparable, V any](batch BatchFunc[K, V]) *Loader[K, V] {
+	return &Loader[K, V]{batch: batch, cache: make(map[K]result[V])}
+}
+
+// LoadMany returns the values found for keys
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) (map[K]V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var missing []K
+	pending := make(map[K]struct{})
+	for _, key := range keys {
+		if _, cached := l.cache[key]; cached {
+			continue
+		}
+		if _, queued := pending[key]; !queued {
+			pending[key] = struct{}{}
+			missing = append(missing, key)
+		}
+	}
+
+	if len(missing) > 0 {
+		loaded, err := l.batch(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range missing {
+			value, found := loaded[key]
+			l.cache[key] = result[V]{value: value, found: found}
+		}
+	}
+
+	values := make(map[K]V, len(keys))
+	for _, key := range keys {
+		if res := l.cache[key]; res.found {
+			values[key] = res.value
+		}
+	}
+	return values, nil
+}
+
+// Load returns the value for a single key and whether it was found
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, bool, error) {
+	values, err := l.LoadMany(ctx, []K{key})
+	if err != nil {
+		var zero V
+		return zero, false, err
+	}
+	value, found := values[key]
+	return value, found, nil
+}
+
+// Clear forgets a cached key, e.g. after the record it refers to changes
+func (l *Loader[K, V]) Clear(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.cache, key)
+}
+
+type scopeKey struct{}
+
+// scope holds the loaders created during one request
+type scope struct {
+	mu      sync.Mutex
+	loaders map[string]any
+}
+
+// WithScope returns a context whose loaders are shared until it is discarded
+func WithScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, scopeKey{}, &scope{loaders: make(map[string]any)})
+}
+
+// For returns the request's loader registered under name, creating it with
+// batch on first use. Outside a scope a fresh, uncached loader is returned so
+// callers behave the same, only without sharing between calls.
+func For[K comparable, V any](ctx context.Context, name string, batch BatchFunc[K, V]) *Loader[K, V] {
+	s, ok := ctx.Value(scopeKey{}).(*scope)
+	if !ok {
+		return New(batch)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if loader, found := s.loaders[name].(*Loader[K, V]); found {
+		return loader
+	}
+	loader := New(batch)
+	s.loaders[name] = loader
+	return loader
+}
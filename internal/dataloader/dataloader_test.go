@@ -0,0 +1,93 @@
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// countingBatch returns each key doubled, except 0 which is missing, and
+// records the keys of every call
+func countingBatch(calls *[][]int) BatchFunc[int, int] {
+	return func(_ context.Context, keys []int) (map[int]int, error) {
+		*calls = append(*calls, slices.Clone(keys))
+		values := make(map[int]int, len(keys))
+		for _, key := range keys {
+			if key != 0 {
+				values[key] = key * 2
+			}
+		}
+		return values, nil
+	}
+}
+
+func TestLoadManyBatchesAndCaches(t *testing.T) {
+	var calls [][]int
+	loader := New(countingBatch(&calls))
+	ctx := context.Background()
+
+	got, err := loader.LoadMany(ctx, []int{1, 2, 1, 0})
+	if err != nil {
+		t.Fatalf("LoadMany() error = %v", err)
+	}
+	if want := map[int]int{1: 2, 2: 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadMany() = %v, want %v", got, want)
+	}
+
+	// Cached hits and misses are not fetched again
+	if _, err := loader.LoadMany(ctx, []int{2, 0, 3}); err != nil {
+		t.Fatalf("LoadMany() error = %v", err)
+	}
+	if want := [][]int{{1, 2, 0}, {3}}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("batch calls = %v, want %v", calls, want)
+	}
+
+	loader.Clear(2)
+	if value, found, err := loader.Load(ctx, 2); err != nil || !found || value != 4 {
+		t.Errorf("Load(2) = %d, %v, %v, want 4, true, nil", value, found, err)
+	}
+	if len(calls) != 3 {
+		t.Errorf("batch calls after Clear = %d, want 3", len(calls))
+	}
+}
+
+func TestLoadManyErrorIsNotCached(t *testing.T) {
+	fail := true
+	loader := New(func(_ context.Context, keys []int) (map[int]int, error) {
+		if fail {
+			return nil, errors.New("database unavailable")
+		}
+		return map[int]int{1: 1}, nil
+	})
+
+	if _, err := loader.LoadMany(context.Background(), []int{1}); err == nil {
+		t.Fatal("LoadMany() error = nil, want error")
+	}
+	fail = false
+	if _, found, err := loader.Load(context.Background(), 1); err != nil || !found {
+		t.Errorf("Load() after failure = %v, %v, want found", found, err)
+	}
+}
+
+func TestForSharesLoadersWithinScope(t *testing.T) {
+	var calls [][]int
+	batch := countingBatch(&calls)
+
+	ctx := WithScope(context.Background())
+	For(ctx, "doubles", batch).LoadMany(ctx, []int{1})
+	For(ctx, "doubles", batch).LoadMany(ctx, []int{1})
+	if len(calls) != 1 {
+		t.Errorf("batch calls within scope = %d, want 1", len(calls))
+	}
+
+	// Without a scope nothing is shared
+	calls = nil
+	ctx = context.Background()
+	For(ctx, "doubles", batch).LoadMany(ctx, []int{1})
+	For(ctx, "doubles", batch).LoadMany(ctx, []int{1})
+	if len(calls) != 2 {
+		t.Errorf("batch calls without scope = %d, want 2", len(calls))
+	}
+}
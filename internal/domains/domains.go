@@ -0,0 +1,138 @@
+// Package domains lets communities and single profiles be served on domains
+// of their own. Whoever adds a domain proves they control it by publishing
+// the token it was issued in a TXT record; a background job looks for the
+// record and only then is the domain routed.
+package domains
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+)
+
+// ErrInvalidDomain is returned for anything that isn't a fully qualified
+// host name
+var ErrInvalidDomain = errors.New("Domain must be a host name such as chirps.example.com")
+
+const (
+	// recordLabel is prepended to a domain to name its verification record
+	recordLabel = "_chirpy-verification."
+
+	// valuePrefix starts the text of a verification record
+	valuePrefix = "chirpy-verification="
+
+	// maxDomainLength is the longest name DNS allows
+	maxDomainLength = 253
+
+	// defaultBatchSize is how many domains a Verifier checks per run when
+	// BatchSize is unset
+	defaultBatchSize = 100
+)
+
+// Normalize lowercases a domain and strips a trailing dot, rejecting URLs,
+// ports, IP addresses and single-label names
+func Normalize(raw string) (string, error) {
+	domain := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(raw)), ".")
+	if domain == "" || len(domain) > maxDomainLength || net.ParseIP(domain) != nil {
+		return "", ErrInvalidDomain
+	}
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return "", ErrInvalidDomain
+	}
+	for _, label := range labels {
+		if !validLabel(label) {
+			return "", ErrInvalidDomain
+		}
+	}
+	return domain, nil
+}
+
+// validLabel reports whether label is 1-63 letters, digits or hyphens, not
+// starting or ending with a hyphen
+func validLabel(label string) bool {
+	if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for _, c := range label {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewToken returns a random verification token
+func NewToken() string {
+	return rand.Text()
+}
+
+// RecordName is the name of the TXT record that verifies domain
+func RecordName(domain string) string {
+	return recordLabel + domain
+}
+
+// RecordValue is the text the verification record must hold
+func RecordValue(token string) string {
+	return valuePrefix + token
+}
+
+// Verified reports whether any of records is the verification record for
+// token
+func Verified(records []string, token string) bool {
+	want := RecordValue(token)
+	for _, record := range records {
+		if strings.TrimSpace(record) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// Verifier checks unverified domains for their TXT record
+type Verifier struct {
+	DB *database.Queries
+	// LookupTXT resolves TXT records; net.DefaultResolver.LookupTXT in
+	// production
+	LookupTXT func(ctx context.Context, name string) ([]string, error)
+	// BatchSize caps how many domains are checked per run
+	BatchSize int32
+}
+
+// Run checks the least recently checked unverified domains, marking those
+// whose record is in place as verified. A domain whose lookup fails is
+// checked again on a later run.
+func (v *Verifier) Run(ctx context.Context) error {
+	batchSize := v.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	pending, err := v.DB.ListUnverifiedCustomDomains(ctx, batchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, domain := range pending {
+		records, err := v.LookupTXT(ctx, RecordName(domain.Domain))
+		var dnsErr *net.DNSError
+		if err != nil && !(errors.As(err, &dnsErr) && dnsErr.IsNotFound) {
+			log.Printf("Couldn't look up verification record of %s: %s", domain.Domain, err)
+		}
+		verified := err == nil && Verified(records, domain.VerificationToken)
+		if err := v.DB.MarkCustomDomainChecked(ctx, database.MarkCustomDomainCheckedParams{
+			Verified: verified,
+			Domain:   domain.Domain,
+		}); err != nil {
+			return err
+		}
+		if verified {
+			log.Printf("Verified custom domain %s", domain.Domain)
+		}
+	}
+	return nil
+}
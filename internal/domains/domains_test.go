@@ -0,0 +1,46 @@
+package domains
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "Chirps.Example.com", want: "chirps.example.com"},
+		{raw: " example.org. ", want: "example.org"},
+		{raw: "xn--bcher-kva.example", want: "xn--bcher-kva.example"},
+		{raw: "localhost", wantErr: true},
+		{raw: "127.0.0.1", wantErr: true},
+		{raw: "example.com:8080", wantErr: true},
+		{raw: "https://example.com", wantErr: true},
+		{raw: "-bad.example.com", wantErr: true},
+		{raw: "a..example.com", wantErr: true},
+		{raw: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := Normalize(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Normalize(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerified(t *testing.T) {
+	token := NewToken()
+	if Verified([]string{"v=spf1 -all", RecordValue("other")}, token) {
+		t.Error("Verified() = true without the token's record")
+	}
+	if !Verified([]string{"v=spf1 -all", RecordValue(token)}, token) {
+		t.Error("Verified() = false with the token's record")
+	}
+	if Verified(nil, token) {
+		t.Error("Verified() = true without records")
+	}
+}
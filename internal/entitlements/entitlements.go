@@ -0,0 +1,48 @@
+// Package entitlements decides what an account may do from its standing.
+// Instances with open registration can put new accounts on probation: until
+// they are old enough they post at a lower rate, can't post links and don't
+// count towards trending, which takes most of the value out of throwaway
+// spam accounts.
+package entitlements
+
+import "time"
+
+// Policy holds an instance's restrictions on new accounts
+type Policy struct {
+	// Probation is how long after registration accounts stay restricted;
+	// zero disables probation
+	Probation time.Duration
+}
+
+// Entitlements are what one account may currently do
+type Entitlements struct {
+	// Probation is set while the account is restricted, which also selects
+	// the lower posting rate limit
+	Probation bool
+	// PostLinks allows links in chirp bodies
+	PostLinks bool
+	// Trending counts the account's chirps towards trending hashtags
+	Trending bool
+}
+
+// Full are the entitlements of an account in good standing
+var Full = Entitlements{PostLinks: true, Trending: true}
+
+// Enabled reports whether new accounts are put on probation at all
+func (p Policy) Enabled() bool {
+	return p.Probation > 0
+}
+
+// For returns the entitlements at now of an account registered at createdAt
+func (p Policy) For(createdAt, now time.Time) Entitlements {
+	if !p.Enabled() || now.Sub(createdAt) >= p.Probation {
+		return Full
+	}
+	return Entitlements{Probation: true}
+}
+
+// EstablishedBefore is the registration time an account must not be later
+// than to be out of probation at now
+func (p Policy) EstablishedBefore(now time.Time) time.Time {
+	return now.Add(-p.Probation)
+}
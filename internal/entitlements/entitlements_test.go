@@ -0,0 +1,41 @@
+package entitlements
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPolicyFor(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		probation time.Duration
+		age       time.Duration
+		want      Entitlements
+	}{
+		{name: "disabled", age: time.Minute, want: Full},
+		{name: "new account", probation: 24 * time.Hour, age: time.Hour, want: Entitlements{Probation: true}},
+		{name: "just out of probation", probation: 24 * time.Hour, age: 24 * time.Hour, want: Full},
+		{name: "established account", probation: 24 * time.Hour, age: 30 * 24 * time.Hour, want: Full},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := Policy{Probation: tt.probation}
+			if got := policy.For(now.Add(-tt.age), now); got != tt.want {
+				t.Errorf("For() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstablishedBefore(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	policy := Policy{Probation: 6 * time.Hour}
+	cutoff := policy.EstablishedBefore(now)
+	if policy.For(cutoff, now).Probation {
+		t.Error("account registered at the cutoff is still on probation")
+	}
+	if !policy.For(cutoff.Add(time.Second), now).Probation {
+		t.Error("account registered after the cutoff is out of probation")
+	}
+}
@@ -0,0 +1,143 @@
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Type identifies the kind of domain event
+type Type string
+
+// Domain events published by the HTTP handlers
+const (
+	ChirpCreated         Type = "chirp.created"
+	ChirpDeleted         Type = "chirp.deleted"
+	ChirpRestored        Type = "chirp.restored"
+	ChirpCoauthorInvited Type = "chirp.coauthor_invited"
+	ChirpReacted         Type = "chirp.reacted"
+	ChirpLiked           Type = "chirp.liked"
+	ChirpReported        Type = "chirp.reported"
+	ChirpRemoved         Type = "chirp.removed"
+	UserCreated          Type = "user.created"
+	UserUpgraded         Type = "user.upgraded"
+	UserSuspended        Type = "user.suspended"
+)
+
+// Event describes something that happened in the application.
+// IDs that don't apply to an event type are left as uuid.Nil. TenantID is
+// only set on events delivered per community, such as to moderation
+// webhooks.
+type Event struct {
+	Type       Type      `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	TenantID   uuid.UUID `json:"tenant_id"`
+	UserID     uuid.UUID `json:"user_id"`
+	ChirpID    uuid.UUID `json:"chirp_id"`
+
+	// Remote is true when the event was relayed from another replica
+	Remote bool `json:"-"`
+}
+
+// Handler receives published events
+type Handler func(ctx context.Context, event Event)
+
+type subscription struct {
+	id      int
+	handler Handler
+	types   map[Type]struct{}
+}
+
+// Bus is an in-process publish/subscribe event bus. Handlers run in their
+// own goroutine so a slow subscriber never delays the request that
+// published the event.
+type Bus struct {
+	mu            sync.RWMutex
+	nextID        int
+	subscriptions []subscription
+	wg            sync.WaitGroup
+}
+
+// NewBus creates an empty event bus
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers a handler for the given event types, or for every
+// event when no types are given. It returns a function that removes the
+// subscription.
+func (b *Bus) Subscribe(handler Handler, eventTypes ...Type) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub := subscription{id: b.nextID, handler: handler}
+	b.nextID++
+	if len(eventTypes) > 0 {
+		sub.types = make(map[Type]struct{}, len(eventTypes))
+		for _, eventType := range eventTypes {
+			sub.types[eventType] = struct{}{}
+		}
+	}
+	b.subscriptions = append(b.subscriptions, sub)
+
+	return func() {
+		b.unsubscribe(sub.id)
+	}
+}
+
+// unsubscribe removes the subscription with the given ID
+func (b *Bus) unsubscribe(id int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, sub := range b.subscriptions {
+		if sub.id == id {
+			b.subscriptions = append(b.subscriptions[:i], b.subscriptions[i+1:]...)
+			return
+		}
+	}
+}
+
+// Publish delivers the event to every matching subscriber. A nil bus
+// discards events, which keeps handler configs usable without one.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subscriptions {
+		if sub.types != nil {
+			if _, found := sub.types[event.Type]; !found {
+				continue
+			}
+		}
+		b.wg.Add(1)
+		go b.deliver(sub.handler, event)
+	}
+}
+
+// deliver runs a single handler, recovering from panics so one broken
+// subscriber can't take down the server
+func (b *Bus) deliver(handler Handler, event Event) {
+	defer b.wg.Done()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Event handler for %s panicked: %v", event.Type, r)
+		}
+	}()
+	handler(context.Background(), event)
+}
+
+// Wait blocks until every in-flight handler has returned
+func (b *Bus) Wait() {
+	b.wg.Wait()
+}
@@ -0,0 +1,97 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBusPublishFiltersByType(t *testing.T) {
+	bus := NewBus()
+
+	var mu sync.Mutex
+	var received []Type
+	bus.Subscribe(func(ctx context.Context, event Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, event.Type)
+	}, ChirpCreated)
+
+	bus.Publish(Event{Type: UserCreated, UserID: uuid.New()})
+	bus.Publish(Event{Type: ChirpCreated, ChirpID: uuid.New()})
+	bus.Wait()
+
+	if len(received) != 1 || received[0] != ChirpCreated {
+		t.Errorf("received = %v, want [%s]", received, ChirpCreated)
+	}
+}
+
+func TestBusSubscribeAll(t *testing.T) {
+	bus := NewBus()
+
+	var mu sync.Mutex
+	count := 0
+	bus.Subscribe(func(ctx context.Context, event Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		count++
+	})
+
+	bus.Publish(Event{Type: UserCreated})
+	bus.Publish(Event{Type: ChirpDeleted})
+	bus.Wait()
+
+	if count != 2 {
+		t.Errorf("count = %d, want 2", count)
+	}
+}
+
+func TestBusUnsubscribe(t *testing.T) {
+	bus := NewBus()
+
+	called := false
+	unsubscribe := bus.Subscribe(func(ctx context.Context, event Event) {
+		called = true
+	})
+	unsubscribe()
+
+	bus.Publish(Event{Type: UserUpgraded})
+	bus.Wait()
+
+	if called {
+		t.Error("handler was called after unsubscribing")
+	}
+}
+
+func TestBusRecoversFromPanics(t *testing.T) {
+	bus := NewBus()
+
+	bus.Subscribe(func(ctx context.Context, event Event) {
+		panic("broken subscriber")
+	})
+
+	bus.Publish(Event{Type: ChirpCreated})
+	bus.Wait()
+}
+
+func TestNilBusPublish(t *testing.T) {
+	var bus *Bus
+	bus.Publish(Event{Type: ChirpCreated})
+}
+
+func TestBusSetsOccurredAt(t *testing.T) {
+	bus := NewBus()
+
+	var got Event
+	bus.Subscribe(func(ctx context.Context, event Event) {
+		got = event
+	})
+	bus.Publish(Event{Type: ChirpCreated})
+	bus.Wait()
+
+	if got.OccurredAt.IsZero() {
+		t.Error("OccurredAt should be set when publishing")
+	}
+}
@@ -0,0 +1,92 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// envelope wraps relayed events with the ID of the replica that sent them
+type envelope struct {
+	Origin string `json:"origin"`
+	Event  Event  `json:"event"`
+}
+
+// RedisBridge relays events between the local bus and every other replica
+// subscribed to the same Redis channel, so subscribers see events no matter
+// which server handled the request.
+type RedisBridge struct {
+	bus     *Bus
+	client  *redis.Client
+	channel string
+	origin  string
+}
+
+// NewRedisBridge creates a bridge between bus and the given Redis channel
+func NewRedisBridge(bus *Bus, client *redis.Client, channel string) *RedisBridge {
+	return &RedisBridge{
+		bus:     bus,
+		client:  client,
+		channel: channel,
+		origin:  uuid.NewString(),
+	}
+}
+
+// Start forwards local events to Redis and remote events to the local bus
+// until ctx is cancelled
+func (rb *RedisBridge) Start(ctx context.Context) {
+	unsubscribe := rb.bus.Subscribe(rb.forward)
+
+	pubsub := rb.client.Subscribe(ctx, rb.channel)
+	go func() {
+		defer unsubscribe()
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				rb.receive(msg.Payload)
+			}
+		}
+	}()
+}
+
+// forward publishes a locally raised event to the Redis channel
+func (rb *RedisBridge) forward(ctx context.Context, event Event) {
+	if event.Remote {
+		return
+	}
+
+	payload, err := json.Marshal(envelope{Origin: rb.origin, Event: event})
+	if err != nil {
+		log.Printf("Couldn't encode %s event for Redis: %s", event.Type, err)
+		return
+	}
+	if err := rb.client.Publish(ctx, rb.channel, payload).Err(); err != nil {
+		log.Printf("Couldn't relay %s event to Redis: %s", event.Type, err)
+	}
+}
+
+// receive republishes an event from another replica on the local bus
+func (rb *RedisBridge) receive(payload string) {
+	var env envelope
+	if err := json.Unmarshal([]byte(payload), &env); err != nil {
+		log.Printf("Couldn't decode event from Redis: %s", err)
+		return
+	}
+	if env.Origin == rb.origin {
+		return
+	}
+
+	env.Event.Remote = true
+	rb.bus.Publish(env.Event)
+}
@@ -0,0 +1,35 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRedisBridgeReceive(t *testing.T) {
+	bus := NewBus()
+	bridge := NewRedisBridge(bus, nil, "events")
+
+	var received []Event
+	bus.Subscribe(func(ctx context.Context, event Event) {
+		received = append(received, event)
+	})
+
+	chirpID := uuid.New()
+	remote, _ := json.Marshal(envelope{Origin: "other-replica", Event: Event{Type: ChirpCreated, ChirpID: chirpID}})
+	own, _ := json.Marshal(envelope{Origin: bridge.origin, Event: Event{Type: ChirpDeleted}})
+
+	bridge.receive(string(remote))
+	bus.Wait()
+	bridge.receive(string(own))
+	bus.Wait()
+
+	if len(received) != 1 {
+		t.Fatalf("received %d events, want 1", len(received))
+	}
+	if received[0].ChirpID != chirpID || !received[0].Remote {
+		t.Errorf("received = %+v, want remote %s event for chirp %s", received[0], ChirpCreated, chirpID)
+	}
+}
@@ -0,0 +1,241 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Config controls timeouts, retries and connection limits for outbound calls
+type Config struct {
+	// Timeout bounds a single attempt, including reading the response headers
+	Timeout time.Duration
+	// MaxRetries is the number of extra attempts after the first one fails
+	MaxRetries int
+	// BaseBackoff is the delay before the first retry; it doubles each time
+	BaseBackoff time.Duration
+	// MaxBackoff caps the delay between retries
+	MaxBackoff time.Duration
+	// MaxConnsPerHost limits concurrent connections to a single host
+	MaxConnsPerHost int
+	// UserAgent is sent with every request that doesn't set its own
+	UserAgent string
+	// PublicOnly refuses to connect to loopback, private, link-local and
+	// other non-public addresses, for URLs that come from users. The check
+	// runs on the address being dialed, after DNS resolution, so a public
+	// name can't be pointed at an internal host. Proxies aren't used.
+	PublicOnly bool
+	// CheckRedirect, when set, decides whether each redirect is followed,
+	// as http.Client.CheckRedirect does
+	CheckRedirect func(req *http.Request, via []*http.Request) error
+}
+
+// ErrNonPublicAddress is returned by clients with PublicOnly set for
+// requests to hosts that resolve to a non-public address
+var ErrNonPublicAddress = errors.New("refusing to connect to a non-public address")
+
+// DefaultConfig returns conservative settings suitable for integrations
+func DefaultConfig() Config {
+	return Config{
+		Timeout:         10 * time.Second,
+		MaxRetries:      2,
+		BaseBackoff:     200 * time.Millisecond,
+		MaxBackoff:      5 * time.Second,
+		MaxConnsPerHost: 10,
+		UserAgent:       "Chirpy",
+	}
+}
+
+// Client is an HTTP client shared by every outbound integration. It retries
+// transient failures (network errors, 429 and 5xx responses) with
+// exponential backoff and records per-host metrics.
+type Client struct {
+	http    *http.Client
+	cfg     Config
+	metrics *Metrics
+}
+
+// New creates a client with its own connection pool
+func New(cfg Config) *Client {
+	dialer := &net.Dialer{
+		Timeout:   5 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	proxy := http.ProxyFromEnvironment
+	if cfg.PublicOnly {
+		dialer.Control = refuseNonPublic
+		proxy = nil
+	}
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		MaxIdleConnsPerHost:   cfg.MaxConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: cfg.Timeout,
+	}
+
+	return &Client{
+		http: &http.Client{
+			Transport:     transport,
+			Timeout:       cfg.Timeout,
+			CheckRedirect: cfg.CheckRedirect,
+		},
+		cfg:     cfg,
+		metrics: newMetrics(),
+	}
+}
+
+// Do sends the request, retrying transient failures. Requests with a body are
+// only retried when the body can be replayed (http.NewRequest sets GetBody for
+// in-memory bodies).
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" && c.cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", c.cfg.UserAgent)
+	}
+
+	host := req.URL.Host
+	replayable := req.Body == nil || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				req.Body = body
+			}
+			c.metrics.record(host, func(s *HostStats) { s.Retries++ })
+		}
+
+		start := time.Now()
+		resp, err := c.http.Do(req)
+		elapsed := time.Since(start)
+		c.metrics.record(host, func(s *HostStats) {
+			s.Requests++
+			s.TotalLatency += elapsed
+			if err != nil {
+				s.Errors++
+			} else if resp.StatusCode >= 500 {
+				s.ServerErrors++
+			}
+		})
+
+		if !shouldRetry(resp, err) || attempt >= c.cfg.MaxRetries || !replayable {
+			return resp, err
+		}
+
+		wait := c.backoff(attempt, resp)
+		if resp != nil {
+			// Drain so the connection can be reused for the next attempt
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Get is a convenience wrapper for GET requests
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// Stats returns a snapshot of the per-host metrics
+func (c *Client) Stats() map[string]HostStats {
+	return c.metrics.snapshot()
+}
+
+// shouldRetry reports whether the attempt failed in a way worth retrying
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		// Cancellation by the caller and refused addresses are final
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, ErrNonPublicAddress)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// nonPublicPrefixes are shared, reserved and translation ranges that
+// netip.Addr's own checks don't cover
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// refuseNonPublic is a net.Dialer Control function failing dials to
+// non-public addresses
+func refuseNonPublic(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, ip)
+	}
+	return nil
+}
+
+// isPublic reports whether ip is a globally routable unicast address
+func isPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// backoff computes the delay before the next attempt, honouring Retry-After
+func (c *Client) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, c.cfg.MaxBackoff)
+		}
+	}
+
+	delay := c.cfg.BaseBackoff << attempt
+	if delay <= 0 || delay > c.cfg.MaxBackoff {
+		delay = c.cfg.MaxBackoff
+	}
+	// Full jitter keeps many clients from retrying in lockstep
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("waiting to retry: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
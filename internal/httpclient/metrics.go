@@ -0,0 +1,50 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+)
+
+// HostStats counts outbound requests to a single host
+type HostStats struct {
+	Requests     int64         `json:"requests"`
+	Retries      int64         `json:"retries"`
+	Errors       int64         `json:"errors"`
+	ServerErrors int64         `json:"server_errors"`
+	TotalLatency time.Duration `json:"total_latency_ns"`
+}
+
+// Metrics aggregates HostStats for every host the client has called
+type Metrics struct {
+	mu    sync.Mutex
+	hosts map[string]*HostStats
+}
+
+func newMetrics() *Metrics {
+	return &Metrics{hosts: make(map[string]*HostStats)}
+}
+
+// record applies update to the stats for host
+func (m *Metrics) record(host string, update func(*HostStats)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, found := m.hosts[host]
+	if !found {
+		stats = &HostStats{}
+		m.hosts[host] = stats
+	}
+	update(stats)
+}
+
+// snapshot copies the current stats so callers can read them without locking
+func (m *Metrics) snapshot() map[string]HostStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make(map[string]HostStats, len(m.hosts))
+	for host, stats := range m.hosts {
+		result[host] = *stats
+	}
+	return result
+}
@@ -0,0 +1,152 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var errPanicked = errors.New("job panicked")
+
+// Func is a unit of background work. Returning an error marks the attempt
+// as failed so it can be retried.
+type Func func(ctx context.Context) error
+
+// Runner executes one-off jobs with retries and periodic jobs on an
+// interval, all in-process. Jobs stop when the context passed to Start is
+// cancelled.
+type Runner struct {
+	ctx         context.Context
+	wg          sync.WaitGroup
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+
+	// backlog counts enqueued jobs that haven't finished, retries included
+	backlog atomic.Int64
+
+	mu       sync.Mutex
+	started  bool
+	periodic []periodicJob
+}
+
+type periodicJob struct {
+	name     string
+	interval time.Duration
+	fn       Func
+}
+
+// NewRunner creates a runner that waits baseBackoff before the first retry,
+// doubling up to maxBackoff
+func NewRunner(baseBackoff, maxBackoff time.Duration) *Runner {
+	return &Runner{
+		ctx:         context.Background(),
+		baseBackoff: baseBackoff,
+		maxBackoff:  maxBackoff,
+	}
+}
+
+// Start launches the registered periodic jobs. Jobs enqueued or registered
+// afterwards start immediately.
+func (r *Runner) Start(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ctx = ctx
+	r.started = true
+	for _, job := range r.periodic {
+		r.launch(job)
+	}
+}
+
+// Every registers fn to run once per interval
+func (r *Runner) Every(name string, interval time.Duration, fn Func) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job := periodicJob{name: name, interval: interval, fn: fn}
+	r.periodic = append(r.periodic, job)
+	if r.started {
+		r.launch(job)
+	}
+}
+
+// launch runs a periodic job until the runner's context is done
+func (r *Runner) launch(job periodicJob) {
+	ctx := r.ctx
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(job.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := r.safeRun(ctx, job.name, job.fn); err != nil {
+					log.Printf("Job %s failed: %s", job.name, err)
+				}
+			}
+		}
+	}()
+}
+
+// Enqueue runs fn in the background, retrying failures with exponential
+// backoff up to maxAttempts in total
+func (r *Runner) Enqueue(name string, maxAttempts int, fn Func) {
+	r.mu.Lock()
+	ctx := r.ctx
+	r.mu.Unlock()
+
+	r.wg.Add(1)
+	r.backlog.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer r.backlog.Add(-1)
+		backoff := r.baseBackoff
+		for attempt := 1; ; attempt++ {
+			err := r.safeRun(ctx, name, fn)
+			if err == nil {
+				return
+			}
+			if attempt >= maxAttempts {
+				log.Printf("Job %s failed after %d attempts: %s", name, attempt, err)
+				return
+			}
+
+			log.Printf("Job %s attempt %d failed, retrying in %s: %s", name, attempt, backoff, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, r.maxBackoff)
+		}
+	}()
+}
+
+// Backlog returns the number of enqueued jobs still running or waiting to
+// retry. Periodic jobs aren't counted.
+func (r *Runner) Backlog() int {
+	return int(r.backlog.Load())
+}
+
+// safeRun executes fn, turning panics into errors
+func (r *Runner) safeRun(ctx context.Context, name string, fn Func) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			log.Printf("Job %s panicked: %v", name, rec)
+			err = errPanicked
+		}
+	}()
+	return fn(ctx)
+}
+
+// Wait blocks until all running jobs have returned. Periodic jobs only
+// return once the context passed to Start is cancelled.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
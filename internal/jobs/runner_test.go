@@ -0,0 +1,97 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEnqueueRetriesUntilSuccess(t *testing.T) {
+	runner := NewRunner(time.Millisecond, 5*time.Millisecond)
+
+	var attempts atomic.Int32
+	runner.Enqueue("flaky", 5, func(ctx context.Context) error {
+		if attempts.Add(1) < 3 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+	runner.Wait()
+
+	if attempts.Load() != 3 {
+		t.Errorf("attempts = %d, want 3", attempts.Load())
+	}
+}
+
+func TestEnqueueStopsAtMaxAttempts(t *testing.T) {
+	runner := NewRunner(time.Millisecond, 5*time.Millisecond)
+
+	var attempts atomic.Int32
+	runner.Enqueue("broken", 2, func(ctx context.Context) error {
+		attempts.Add(1)
+		return errors.New("permanent failure")
+	})
+	runner.Wait()
+
+	if attempts.Load() != 2 {
+		t.Errorf("attempts = %d, want 2", attempts.Load())
+	}
+}
+
+func TestBacklogCountsUnfinishedJobs(t *testing.T) {
+	runner := NewRunner(time.Millisecond, 5*time.Millisecond)
+
+	release := make(chan struct{})
+	for range 3 {
+		runner.Enqueue("blocked", 1, func(ctx context.Context) error {
+			<-release
+			return nil
+		})
+	}
+	if got := runner.Backlog(); got != 3 {
+		t.Errorf("Backlog() = %d, want 3", got)
+	}
+
+	close(release)
+	runner.Wait()
+	if got := runner.Backlog(); got != 0 {
+		t.Errorf("Backlog() after Wait = %d, want 0", got)
+	}
+}
+
+func TestEnqueueRecoversFromPanics(t *testing.T) {
+	runner := NewRunner(time.Millisecond, 5*time.Millisecond)
+
+	var attempts atomic.Int32
+	runner.Enqueue("panicky", 2, func(ctx context.Context) error {
+		attempts.Add(1)
+		panic("boom")
+	})
+	runner.Wait()
+
+	if attempts.Load() != 2 {
+		t.Errorf("attempts = %d, want 2", attempts.Load())
+	}
+}
+
+func TestEveryRunsUntilCancelled(t *testing.T) {
+	runner := NewRunner(time.Millisecond, 5*time.Millisecond)
+
+	var runs atomic.Int32
+	runner.Every("tick", time.Millisecond, func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runner.Start(ctx)
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	runner.Wait()
+
+	if runs.Load() == 0 {
+		t.Error("periodic job never ran")
+	}
+}
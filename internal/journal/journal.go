@@ -0,0 +1,92 @@
+// Package journal appends the database changes made on behalf of external
+// webhooks to a file kept outside the database, one JSON entry per line.
+// The file survives a database restore, so the changes it records can be
+// audited and replayed against the restored data.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Mutations an entry can record
+const (
+	// MutationUserUpgraded sets users.is_chirpy_red for UserID
+	MutationUserUpgraded = "user.upgraded"
+)
+
+// Entry records one change: what was changed, when, and the webhook that
+// caused it
+type Entry struct {
+	Time     time.Time       `json:"time"`
+	Source   string          `json:"source"`
+	Event    string          `json:"event"`
+	Mutation string          `json:"mutation"`
+	UserID   uuid.UUID       `json:"user_id"`
+	Payload  json.RawMessage `json:"payload,omitempty"`
+}
+
+// Journal appends entries to a file. It is safe for concurrent use.
+type Journal struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Open opens the journal at path for appending, creating it if needed
+func Open(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening journal: %w", err)
+	}
+	return &Journal{file: file}, nil
+}
+
+// Record appends an entry and syncs it to disk before returning, so a
+// change the caller acknowledges is never missing from the journal
+func (j *Journal) Record(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
+
+// Read returns the entries recorded at or after since, in the order they
+// were written. A truncated last line, left by a crash mid-write, is
+// skipped.
+func Read(r io.Reader, since time.Time) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			if !scanner.Scan() {
+				break
+			}
+			return nil, fmt.Errorf("journal line %d: %w", line, err)
+		}
+		if !entry.Time.Before(since) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,68 @@
+package journal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestRecordAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.ndjson")
+	j, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	userIDs := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for i, userID := range userIDs {
+		err := j.Record(Entry{
+			Time:     start.Add(time.Duration(i) * time.Hour),
+			Source:   "polka",
+			Event:    "user.upgraded",
+			Mutation: MutationUserUpgraded,
+			UserID:   userID,
+			Payload:  json.RawMessage(`{"event":"user.upgraded"}`),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	entries, err := Read(file, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].UserID != userIDs[1] || entries[1].UserID != userIDs[2] {
+		t.Errorf("Read() = %+v, want the last two entries in order", entries)
+	}
+}
+
+func TestReadSkipsTruncatedLastLine(t *testing.T) {
+	journal := `{"time":"2026-10-16T12:00:00Z","mutation":"user.upgraded","user_id":"` + uuid.NewString() + `"}` + "\n" + `{"time":"2026-10-16T13:`
+	entries, err := Read(strings.NewReader(journal), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Read() returned %d entries, want 1", len(entries))
+	}
+
+	corrupt := `{"time":` + "\n" + `{"time":"2026-10-16T12:00:00Z"}` + "\n"
+	if _, err := Read(strings.NewReader(corrupt), time.Time{}); err == nil {
+		t.Error("Read() accepted a corrupt line before the end")
+	}
+}
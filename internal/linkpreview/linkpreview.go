@@ -0,0 +1,202 @@
+// Package linkpreview fetches the OpenGraph metadata shown as a preview of
+// links in chirps. Only links to hosts on an allowlist are fetched, each
+// fetch has a deadline and only the start of each page is read, so a
+// chirp can't make the server request arbitrary addresses. Fetches go
+// through an httpclient.Client, which with PublicOnly set also keeps an
+// allowed name that resolves to an internal address from being fetched.
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kai-xlr/neo_chirpy/internal/httpclient"
+)
+
+// Common preview errors
+var (
+	ErrHostNotAllowed = errors.New("link host is not allowed")
+	ErrNoMetadata     = errors.New("page has no preview metadata")
+)
+
+const (
+	// maxPageBytes is how much of a page is read looking for metadata
+	maxPageBytes = 512 << 10
+
+	// maxRedirects caps the redirects followed, each to an allowed host
+	maxRedirects = 5
+
+	// Previews are cut to these lengths in characters
+	maxTitleLength       = 200
+	maxDescriptionLength = 300
+)
+
+// Preview is the metadata shown for a link
+type Preview struct {
+	URL         string
+	Title       string
+	Description string
+	Image       string
+}
+
+// Fetcher fetches previews of links to allowed hosts
+type Fetcher struct {
+	allowed []string
+	client  *httpclient.Client
+}
+
+// New returns a fetcher for links to the allowed hosts and their
+// subdomains. Its client is built from cfg, whose Timeout bounds each fetch;
+// cfg should have PublicOnly set so links can't reach internal hosts.
+func New(allowedHosts []string, cfg httpclient.Config) *Fetcher {
+	f := &Fetcher{}
+	for _, host := range allowedHosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			f.allowed = append(f.allowed, host)
+		}
+	}
+	cfg.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return errors.New("too many redirects")
+		}
+		if !f.Allowed(req.URL.String()) {
+			return ErrHostNotAllowed
+		}
+		return nil
+	}
+	f.client = httpclient.New(cfg)
+	return f
+}
+
+// Allowed reports whether rawURL is an http or https link to an allowed
+// host. A nil fetcher allows nothing.
+func (f *Fetcher) Allowed(rawURL string) bool {
+	if f == nil {
+		return false
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.User != nil {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, allowed := range f.allowed {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fetch downloads the page at rawURL and reads its OpenGraph title,
+// description and image, falling back to the page title and description
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (Preview, error) {
+	if !f.Allowed(rawURL) {
+		return Preview{}, ErrHostNotAllowed
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return Preview{}, err
+	}
+	req.Header.Set("User-Agent", "Chirpy link preview")
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return Preview{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Preview{}, fmt.Errorf("fetching %s: status %d", rawURL, resp.StatusCode)
+	}
+	if !strings.Contains(resp.Header.Get("Content-Type"), "text/html") {
+		return Preview{}, ErrNoMetadata
+	}
+	page, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return Preview{}, err
+	}
+
+	preview := Parse(string(page), resp.Request.URL)
+	preview.URL = rawURL
+	if preview.Title == "" && preview.Description == "" && preview.Image == "" {
+		return Preview{}, ErrNoMetadata
+	}
+	return preview, nil
+}
+
+var (
+	metaPattern  = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	attrPattern  = regexp.MustCompile(`(?is)([a-z:_-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+)
+
+// Parse reads preview metadata from an HTML page. A relative image URL is
+// resolved against base, and images that aren't http or https are dropped.
+func Parse(page string, base *url.URL) Preview {
+	meta := make(map[string]string)
+	for _, tag := range metaPattern.FindAllString(page, -1) {
+		attrs := make(map[string]string)
+		for _, match := range attrPattern.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(match[1])] = match[2] + match[3] + match[4]
+		}
+		key := attrs["property"]
+		if key == "" {
+			key = attrs["name"]
+		}
+		key = strings.ToLower(key)
+		if _, seen := meta[key]; key != "" && !seen {
+			meta[key] = clean(attrs["content"])
+		}
+	}
+
+	preview := Preview{
+		Title:       firstOf(meta["og:title"], meta["twitter:title"]),
+		Description: firstOf(meta["og:description"], meta["twitter:description"], meta["description"]),
+	}
+	if preview.Title == "" {
+		if match := titlePattern.FindStringSubmatch(page); match != nil {
+			preview.Title = clean(match[1])
+		}
+	}
+	preview.Title = truncate(preview.Title, maxTitleLength)
+	preview.Description = truncate(preview.Description, maxDescriptionLength)
+
+	if image := firstOf(meta["og:image"], meta["twitter:image"]); image != "" {
+		if resolved, err := base.Parse(image); err == nil && (resolved.Scheme == "http" || resolved.Scheme == "https") {
+			preview.Image = resolved.String()
+		}
+	}
+	return preview
+}
+
+// clean unescapes HTML entities and collapses whitespace
+func clean(text string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+}
+
+func firstOf(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// truncate cuts text to at most limit characters, ending with an ellipsis
+// when it was cut
+func truncate(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:limit-1])) + "…"
+}
@@ -0,0 +1,79 @@
+// Package listen creates the server's listening socket in a way that allows
+// restarts without refusing connections: either by inheriting a socket from
+// systemd (socket activation) or by binding with SO_REUSEPORT so a new
+// process can start accepting before the old one stops.
+package listen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd
+const listenFDsStart = 3
+
+// ErrReusePortUnsupported is returned when SO_REUSEPORT is requested on a
+// platform that lacks it
+var ErrReusePortUnsupported = errors.New("SO_REUSEPORT is not supported on this platform")
+
+// Options controls how the listener is created
+type Options struct {
+	// ReusePort binds with SO_REUSEPORT when no socket is inherited
+	ReusePort bool
+}
+
+// Listen returns the socket passed by systemd when present, and otherwise
+// binds addr, with SO_REUSEPORT if requested
+func Listen(ctx context.Context, addr string, opts Options) (net.Listener, error) {
+	count, err := listenFDs(os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS"), os.Getpid())
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		// Only the first socket is used; clear the variables so child
+		// processes don't try to inherit it as well
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+
+		file := os.NewFile(uintptr(listenFDsStart), "systemd-socket")
+		defer file.Close()
+		listener, err := net.FileListener(file)
+		if err != nil {
+			return nil, fmt.Errorf("using inherited socket: %w", err)
+		}
+		return listener, nil
+	}
+
+	config := net.ListenConfig{}
+	if opts.ReusePort {
+		config.Control = setReusePort
+	}
+	return config.Listen(ctx, "tcp", addr)
+}
+
+// listenFDs returns how many sockets systemd passed to this process, following
+// the sd_listen_fds protocol: LISTEN_PID must match our pid
+func listenFDs(pidEnv, fdsEnv string, pid int) (int, error) {
+	if pidEnv == "" || fdsEnv == "" {
+		return 0, nil
+	}
+
+	listenPID, err := strconv.Atoi(pidEnv)
+	if err != nil {
+		return 0, fmt.Errorf("invalid LISTEN_PID %q: %w", pidEnv, err)
+	}
+	if listenPID != pid {
+		return 0, nil
+	}
+
+	count, err := strconv.Atoi(fdsEnv)
+	if err != nil || count < 0 {
+		return 0, fmt.Errorf("invalid LISTEN_FDS %q", fdsEnv)
+	}
+	return count, nil
+}
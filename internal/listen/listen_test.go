@@ -0,0 +1,56 @@
+package listen
+
+import (
+	"context"
+	"runtime"
+	"testing"
+)
+
+func TestListenFDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		pid     string
+		fds     string
+		want    int
+		wantErr bool
+	}{
+		{name: "not socket activated", pid: "", fds: "", want: 0},
+		{name: "activated for this process", pid: "42", fds: "1", want: 1},
+		{name: "activated for another process", pid: "7", fds: "1", want: 0},
+		{name: "invalid pid", pid: "abc", fds: "1", wantErr: true},
+		{name: "invalid count", pid: "42", fds: "-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := listenFDs(tt.pid, tt.fds, 42)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("listenFDs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("listenFDs() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListenReusePort(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("SO_REUSEPORT is only enabled on linux")
+	}
+	t.Setenv("LISTEN_PID", "")
+	t.Setenv("LISTEN_FDS", "")
+
+	first, err := Listen(context.Background(), "127.0.0.1:0", Options{ReusePort: true})
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer first.Close()
+
+	// A second process (here, a second socket) can bind the same port
+	second, err := Listen(context.Background(), first.Addr().String(), Options{ReusePort: true})
+	if err != nil {
+		t.Fatalf("second Listen() on %s error = %v", first.Addr(), err)
+	}
+	second.Close()
+}
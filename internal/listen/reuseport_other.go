@@ -0,0 +1,10 @@
+//go:build !linux
+
+package listen
+
+import "syscall"
+
+// setReusePort reports that SO_REUSEPORT is unavailable
+func setReusePort(network, address string, conn syscall.RawConn) error {
+	return ErrReusePortUnsupported
+}
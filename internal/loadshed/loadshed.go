@@ -0,0 +1,215 @@
+// Package loadshed turns away less important requests while the server is
+// struggling, so core actions keep working. Each route has a priority,
+// written as "[METHOD ]/path/prefix=priority" where a "*" segment matches
+// any one path segment. A route covers the paths below it unless it ends in
+// "$", which matches the path alone, and the most specific matching route
+// applies.
+package loadshed
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Priority is how important a route is to keep serving under load
+type Priority int
+
+const (
+	// Low routes, such as search and trending, are shed first
+	Low Priority = iota
+	// Normal routes are shed once the server is far past its limits
+	Normal
+	// Critical routes, such as login and posting, are never shed
+	Critical
+)
+
+// DefaultPriorities protect signing in, posting and reading the feed, and
+// shed search, trending and the analytics endpoints first. Routes not
+// listed are normal, including likes, reposts and batch deletes.
+var DefaultPriorities = []string{
+	"POST /api/login=critical",
+	"POST /api/refresh=critical",
+	"POST /api/chirps$=critical",
+	"GET /api/feed=critical",
+	"/api/healthz=critical",
+	"/admin=critical",
+	"/admin/db/analyze=low",
+	"/admin/logs/stream=low",
+	"/api/chirps/search=low",
+	"/api/chirps/validate=low",
+	"/api/hashtags/trending=low",
+	"GET /api/chirps/*/stats=low",
+	"/api/users/me/recap=low",
+	"/api/users/me/chirps/export=low",
+}
+
+// route is the priority of requests whose path starts with segments
+type route struct {
+	method    string
+	segments  []string
+	wildcards int
+	exact     bool
+	priority  Priority
+}
+
+// Priorities maps routes to their priority
+type Priorities struct {
+	// routes is sorted most specific first: more segments, then fewer
+	// wildcards, then exact before prefix, then method-specific before
+	// method-less
+	routes []route
+}
+
+// ParsePriorities reads priorities such as "GET /api/feed=critical" or
+// "/api/chirps/search=low"
+func ParsePriorities(specs []string) (*Priorities, error) {
+	p := &Priorities{routes: make([]route, 0, len(specs))}
+	seen := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		path, raw, found := strings.Cut(spec, "=")
+		path = strings.TrimSpace(path)
+		if !found || path == "" {
+			return nil, fmt.Errorf("route priority %q must be written as [METHOD ]/path=priority", spec)
+		}
+
+		var r route
+		if method, prefix, hasMethod := strings.Cut(path, " "); hasMethod {
+			r.method, path = strings.ToUpper(method), strings.TrimSpace(prefix)
+		}
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("route priority %q: path must start with /", spec)
+		}
+		path, r.exact = strings.CutSuffix(path, "$")
+		r.segments = splitPath(path)
+		for _, segment := range r.segments {
+			if segment == "*" {
+				r.wildcards++
+			}
+		}
+
+		switch strings.ToLower(strings.TrimSpace(raw)) {
+		case "low":
+			r.priority = Low
+		case "normal":
+			r.priority = Normal
+		case "critical":
+			r.priority = Critical
+		default:
+			return nil, fmt.Errorf("route priority %q: priority must be low, normal or critical", spec)
+		}
+
+		key := r.method + " /" + strings.Join(r.segments, "/")
+		if r.exact {
+			key += "$"
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("route priority for %s is listed twice", strings.TrimSpace(key))
+		}
+		seen[key] = true
+		p.routes = append(p.routes, r)
+	}
+
+	sort.SliceStable(p.routes, func(i, j int) bool {
+		a, b := p.routes[i], p.routes[j]
+		if len(a.segments) != len(b.segments) {
+			return len(a.segments) > len(b.segments)
+		}
+		if a.wildcards != b.wildcards {
+			return a.wildcards < b.wildcards
+		}
+		if a.exact != b.exact {
+			return a.exact
+		}
+		return a.method != "" && b.method == ""
+	})
+	return p, nil
+}
+
+// For returns the priority of a request, Normal if no route matches
+func (p *Priorities) For(method, path string) Priority {
+	if p == nil {
+		return Normal
+	}
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	segments := splitPath(path)
+	for _, r := range p.routes {
+		if (r.method == "" || r.method == method) && r.matches(segments) {
+			return r.priority
+		}
+	}
+	return Normal
+}
+
+// matches reports whether the route's segments start the path's, so a
+// route covers everything below it, or for exact routes are the path's
+func (r route) matches(path []string) bool {
+	if len(path) < len(r.segments) || (r.exact && len(path) != len(r.segments)) {
+		return false
+	}
+	for i, segment := range r.segments {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// splitPath splits a path into its segments, ignoring the slashes at either
+// end
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+// Shedder decides whether to turn a request away from the job queue
+// backlog and recent database latency. A zero limit ignores that signal.
+type Shedder struct {
+	Priorities *Priorities
+
+	// Backlog returns the number of background jobs waiting or running
+	Backlog    func() int
+	MaxBacklog int
+
+	// Latency returns the recent average database query time
+	Latency    func() time.Duration
+	MaxLatency time.Duration
+}
+
+// Pressure is how far past its limits the server is: below 1 it is
+// healthy, and 2 means a signal is at twice its limit
+func (s *Shedder) Pressure() float64 {
+	var pressure float64
+	if s.MaxBacklog > 0 && s.Backlog != nil {
+		pressure = max(pressure, float64(s.Backlog())/float64(s.MaxBacklog))
+	}
+	if s.MaxLatency > 0 && s.Latency != nil {
+		pressure = max(pressure, float64(s.Latency())/float64(s.MaxLatency))
+	}
+	return pressure
+}
+
+// Shed reports whether to turn a request away. Low priority requests are
+// shed once a signal crosses its limit and normal ones once it reaches
+// twice the limit; critical requests are always served.
+func (s *Shedder) Shed(method, path string) bool {
+	if s == nil {
+		return false
+	}
+	priority := s.Priorities.For(method, path)
+	if priority == Critical {
+		return false
+	}
+	pressure := s.Pressure()
+	if priority == Low {
+		return pressure >= 1
+	}
+	return pressure >= 2
+}
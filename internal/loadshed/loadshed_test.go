@@ -0,0 +1,98 @@
+package loadshed
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPrioritiesFor(t *testing.T) {
+	priorities, err := ParsePriorities(DefaultPriorities)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		want   Priority
+	}{
+		{"POST", "/api/login", Critical},
+		{"POST", "/api/chirps", Critical},
+		{"POST", "/api/chirps/", Critical},
+		{"GET", "/api/chirps", Normal},
+		{"POST", "/api/chirps/delete", Normal},
+		{"POST", "/api/chirps/validate", Low},
+		{"POST", "/api/chirps/0190d1a0-0000-7000-8000-000000000000/like", Normal},
+		{"POST", "/api/chirps/0190d1a0-0000-7000-8000-000000000000/reactions", Normal},
+		{"POST", "/api/chirps/0190d1a0-0000-7000-8000-000000000000/repost", Normal},
+		{"GET", "/api/feed", Critical},
+		{"HEAD", "/api/feed", Critical},
+		{"GET", "/api/chirps/search", Low},
+		{"GET", "/api/hashtags/trending", Low},
+		{"GET", "/api/chirps/0190d1a0-0000-7000-8000-000000000000/stats", Low},
+		{"GET", "/api/chirps/0190d1a0-0000-7000-8000-000000000000", Normal},
+		{"GET", "/admin/reports", Critical},
+		{"POST", "/admin/db/analyze", Low},
+		{"GET", "/admin/logs/stream", Low},
+		{"GET", "/api/users/me/recap", Low},
+	}
+	for _, tt := range tests {
+		if got := priorities.For(tt.method, tt.path); got != tt.want {
+			t.Errorf("For(%s, %s) = %d, want %d", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParsePrioritiesRejectsBadSpecs(t *testing.T) {
+	for _, spec := range []string{"/api/chirps", "api/chirps=low", "/api/chirps=urgent", "=low"} {
+		if _, err := ParsePriorities([]string{spec}); err == nil {
+			t.Errorf("ParsePriorities(%q) succeeded, want error", spec)
+		}
+	}
+	if _, err := ParsePriorities([]string{"/api/feed=low", "/api/feed/=critical"}); err == nil {
+		t.Error("ParsePriorities accepted a route listed twice")
+	}
+	if _, err := ParsePriorities([]string{"/api/feed$=low", "/api/feed=critical"}); err != nil {
+		t.Errorf("ParsePriorities rejected an exact and a prefix route for one path: %v", err)
+	}
+}
+
+func TestShed(t *testing.T) {
+	priorities, err := ParsePriorities([]string{"/api/search=low", "POST /api/chirps=critical"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		backlog    int
+		latency    time.Duration
+		wantLow    bool
+		wantNormal bool
+	}{
+		{name: "healthy", backlog: 10, latency: 20 * time.Millisecond},
+		{name: "backlog over limit", backlog: 150, latency: 20 * time.Millisecond, wantLow: true},
+		{name: "latency over limit", backlog: 10, latency: 150 * time.Millisecond, wantLow: true},
+		{name: "twice the limit", backlog: 200, latency: 20 * time.Millisecond, wantLow: true, wantNormal: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shedder := &Shedder{
+				Priorities: priorities,
+				Backlog:    func() int { return tt.backlog },
+				MaxBacklog: 100,
+				Latency:    func() time.Duration { return tt.latency },
+				MaxLatency: 100 * time.Millisecond,
+			}
+			if got := shedder.Shed("GET", "/api/search"); got != tt.wantLow {
+				t.Errorf("Shed(low) = %v, want %v", got, tt.wantLow)
+			}
+			if got := shedder.Shed("GET", "/api/users"); got != tt.wantNormal {
+				t.Errorf("Shed(normal) = %v, want %v", got, tt.wantNormal)
+			}
+			if shedder.Shed("POST", "/api/chirps") {
+				t.Error("Shed(critical) = true, want false")
+			}
+		})
+	}
+}
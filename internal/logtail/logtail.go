@@ -0,0 +1,167 @@
+// Package logtail keeps the most recent log lines in memory and hands new
+// ones to subscribers, so operators can follow a server's logs without
+// shelling into the host.
+package logtail
+
+import (
+	"io"
+	"log"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Flags are the log flags lines must be written with: Tail reads the time
+// and the calling file from them
+const Flags = log.LstdFlags | log.Llongfile
+
+// Levels, from least to most severe
+const (
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+var severity = map[string]int{LevelInfo: 0, LevelWarn: 1, LevelError: 2}
+
+// ValidLevel reports whether level names a known level
+func ValidLevel(level string) bool {
+	_, ok := severity[level]
+	return ok
+}
+
+// Entry is one parsed log line. Module is the package directory of the
+// code that logged it, such as "chirp" or "jobs".
+type Entry struct {
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Module  string    `json:"module"`
+	Message string    `json:"message"`
+}
+
+// Filter selects entries at or above Level and, when Modules is non-empty,
+// from one of Modules
+type Filter struct {
+	Level   string
+	Modules []string
+}
+
+// Match reports whether the filter selects e
+func (f Filter) Match(e Entry) bool {
+	if severity[e.Level] < severity[f.Level] {
+		return false
+	}
+	return len(f.Modules) == 0 || slices.Contains(f.Modules, e.Module)
+}
+
+// Tail is an io.Writer for the standard logger. It passes every line on to
+// out and keeps the last lines in a ring buffer. It is safe for concurrent
+// use.
+type Tail struct {
+	out io.Writer
+
+	mu          sync.Mutex
+	entries     []Entry
+	next        int
+	full        bool
+	subscribers map[chan Entry]struct{}
+}
+
+// New creates a tail keeping the last size lines written through it to out
+func New(out io.Writer, size int) *Tail {
+	return &Tail{
+		out:         out,
+		entries:     make([]Entry, size),
+		subscribers: make(map[chan Entry]struct{}),
+	}
+}
+
+// Write records one log line and passes it on. The standard logger writes
+// each line in a single call.
+func (t *Tail) Write(p []byte) (int, error) {
+	entry := parse(string(p))
+
+	t.mu.Lock()
+	if len(t.entries) > 0 {
+		t.entries[t.next] = entry
+		t.next = (t.next + 1) % len(t.entries)
+		t.full = t.full || t.next == 0
+	}
+	for ch := range t.subscribers {
+		// A slow reader misses lines rather than holding up logging
+		select {
+		case ch <- entry:
+		default:
+		}
+	}
+	t.mu.Unlock()
+
+	return t.out.Write(p)
+}
+
+// Recent returns the kept lines, oldest first
+func (t *Tail) Recent() []Entry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.full {
+		return slices.Clone(t.entries[:t.next])
+	}
+	return append(slices.Clone(t.entries[t.next:]), t.entries[:t.next]...)
+}
+
+// Subscribe delivers lines written from now on until unsubscribe is called.
+// Lines are dropped while the channel's buffer is full.
+func (t *Tail) Subscribe(buffer int) (lines <-chan Entry, unsubscribe func()) {
+	ch := make(chan Entry, buffer)
+	t.mu.Lock()
+	t.subscribers[ch] = struct{}{}
+	t.mu.Unlock()
+
+	return ch, func() {
+		t.mu.Lock()
+		delete(t.subscribers, ch)
+		t.mu.Unlock()
+	}
+}
+
+// parse splits a line written with Flags into its parts. Lines in any other
+// form are kept whole as the message.
+func parse(line string) Entry {
+	line = strings.TrimRight(line, "\n")
+	entry := Entry{Time: time.Now().UTC(), Message: line}
+
+	const stamp = "2006/01/02 15:04:05"
+	if len(line) > len(stamp) {
+		if logged, err := time.ParseInLocation(stamp, line[:len(stamp)], time.Local); err == nil {
+			entry.Time = logged.UTC()
+			line = line[len(stamp)+1:]
+		}
+	}
+	if end := strings.Index(line, ".go:"); end >= 0 {
+		if sep := strings.Index(line[end:], ": "); sep >= 0 {
+			entry.Module = path.Base(path.Dir(line[:end]))
+			line = line[end+sep+2:]
+		}
+	}
+	entry.Message = line
+	entry.Level = level(line)
+	return entry
+}
+
+// level infers a line's level from the wording the server's log messages
+// use, since the standard logger has no levels
+func level(message string) string {
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "retrying"):
+		return LevelWarn
+	case strings.HasPrefix(message, "Couldn't"), strings.HasPrefix(message, "Error"),
+		strings.Contains(lower, "failed"), strings.Contains(lower, "panicked"),
+		strings.Contains(lower, "5xx"):
+		return LevelError
+	default:
+		return LevelInfo
+	}
+}
@@ -0,0 +1,81 @@
+package logtail
+
+import (
+	"bytes"
+	"log"
+	"testing"
+)
+
+func TestTailParsesLogLines(t *testing.T) {
+	var out bytes.Buffer
+	tail := New(&out, 10)
+	logger := log.New(tail, "", Flags)
+
+	logger.Printf("Published %d scheduled chirps", 3)
+	logger.Printf("Couldn't flush chirp views: %s", "connection refused")
+
+	entries := tail.Recent()
+	if len(entries) != 2 {
+		t.Fatalf("Recent() returned %d entries, want 2", len(entries))
+	}
+	if entries[0].Module != "logtail" || entries[0].Level != LevelInfo || entries[0].Message != "Published 3 scheduled chirps" {
+		t.Errorf("first entry = %+v", entries[0])
+	}
+	if entries[1].Level != LevelError {
+		t.Errorf("second entry level = %q, want %q", entries[1].Level, LevelError)
+	}
+	if entries[0].Time.IsZero() {
+		t.Error("entry has no time")
+	}
+	if out.Len() == 0 {
+		t.Error("lines weren't passed on")
+	}
+}
+
+func TestTailKeepsLastLines(t *testing.T) {
+	tail := New(&bytes.Buffer{}, 2)
+	for _, line := range []string{"one\n", "two\n", "three\n"} {
+		tail.Write([]byte(line))
+	}
+
+	entries := tail.Recent()
+	if len(entries) != 2 || entries[0].Message != "two" || entries[1].Message != "three" {
+		t.Errorf("Recent() = %+v, want two and three", entries)
+	}
+}
+
+func TestTailSubscribe(t *testing.T) {
+	tail := New(&bytes.Buffer{}, 0)
+	lines, unsubscribe := tail.Subscribe(1)
+	tail.Write([]byte("first\n"))
+	tail.Write([]byte("dropped\n"))
+	unsubscribe()
+	tail.Write([]byte("after\n"))
+
+	if entry := <-lines; entry.Message != "first" {
+		t.Errorf("received %q, want first", entry.Message)
+	}
+	select {
+	case entry := <-lines:
+		t.Errorf("received %q after the buffer filled", entry.Message)
+	default:
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	entry := Entry{Level: LevelWarn, Module: "jobs"}
+	tests := []struct {
+		filter Filter
+		want   bool
+	}{
+		{Filter{Level: LevelInfo}, true},
+		{Filter{Level: LevelWarn, Modules: []string{"chirp", "jobs"}}, true},
+		{Filter{Level: LevelError}, false},
+		{Filter{Level: LevelInfo, Modules: []string{"chirp"}}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(entry); got != tt.want {
+			t.Errorf("%+v.Match() = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kai-xlr/neo_chirpy/internal/jobs"
+)
+
+// Common mailer errors
+var (
+	ErrNoRecipients = errors.New("message has no recipients")
+)
+
+// Message is a single email with optional plain-text and HTML bodies
+type Message struct {
+	To       []string
+	Subject  string
+	TextBody string
+	HTMLBody string
+}
+
+// Mailer sends email messages
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogMailer writes messages to an io.Writer instead of sending them. It is
+// meant for development, where the output usually goes to stdout.
+type LogMailer struct {
+	Out io.Writer
+}
+
+// Send writes the message to the configured writer
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return ErrNoRecipients
+	}
+	_, err := fmt.Fprintf(m.Out, "--- email ---\nTo: %s\nSubject: %s\n\n%s\n--- end email ---\n",
+		strings.Join(msg.To, ", "), msg.Subject, messageBody(msg))
+	return err
+}
+
+// messageBody prefers the plain-text body for logging
+func messageBody(msg Message) string {
+	if msg.TextBody != "" {
+		return msg.TextBody
+	}
+	return msg.HTMLBody
+}
+
+// QueuedMailer hands messages to the job runner so callers aren't blocked by
+// slow mail servers, and failed sends are retried with backoff
+type QueuedMailer struct {
+	Mailer      Mailer
+	Runner      *jobs.Runner
+	MaxAttempts int
+}
+
+// Send enqueues the message and returns immediately
+func (m *QueuedMailer) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return ErrNoRecipients
+	}
+	m.Runner.Enqueue("send email", m.MaxAttempts, func(ctx context.Context) error {
+		return m.Mailer.Send(ctx, msg)
+	})
+	return nil
+}
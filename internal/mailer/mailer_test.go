@@ -0,0 +1,177 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/httpclient"
+	"github.com/kai-xlr/neo_chirpy/internal/jobs"
+)
+
+func TestLogMailer(t *testing.T) {
+	var out bytes.Buffer
+	mailer := &LogMailer{Out: &out}
+
+	err := mailer.Send(context.Background(), Message{
+		To:       []string{"user@example.com"},
+		Subject:  "Welcome",
+		TextBody: "Hello there",
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	for _, want := range []string{"To: user@example.com", "Subject: Welcome", "Hello there"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output %q does not contain %q", out.String(), want)
+		}
+	}
+
+	if err := mailer.Send(context.Background(), Message{Subject: "Nobody"}); err != ErrNoRecipients {
+		t.Errorf("Send() without recipients error = %v, want %v", err, ErrNoRecipients)
+	}
+}
+
+func TestTemplateRender(t *testing.T) {
+	tmpl, err := NewTemplate("Hi {{.Name}}", "Hello {{.Name}}", "<p>Hello {{.Name}}</p>")
+	if err != nil {
+		t.Fatalf("NewTemplate() error = %v", err)
+	}
+
+	msg, err := tmpl.Render([]string{"user@example.com"}, map[string]string{"Name": "<b>Sam</b>"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	if msg.Subject != "Hi <b>Sam</b>" {
+		t.Errorf("Subject = %q, want %q", msg.Subject, "Hi <b>Sam</b>")
+	}
+	if msg.TextBody != "Hello <b>Sam</b>" {
+		t.Errorf("TextBody = %q, want %q", msg.TextBody, "Hello <b>Sam</b>")
+	}
+	if msg.HTMLBody != "<p>Hello &lt;b&gt;Sam&lt;/b&gt;</p>" {
+		t.Errorf("HTMLBody = %q, want escaped HTML", msg.HTMLBody)
+	}
+}
+
+func TestBuildMIME(t *testing.T) {
+	msg := Message{
+		To:       []string{"a@example.com", "b@example.com"},
+		Subject:  "Café news",
+		TextBody: "plain body",
+		HTMLBody: "<p>html body</p>",
+	}
+
+	raw, err := buildMIME("chirpy@example.com", msg, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("buildMIME() error = %v", err)
+	}
+
+	body := string(raw)
+	for _, want := range []string{
+		"From: chirpy@example.com\r\n",
+		"To: a@example.com, b@example.com\r\n",
+		"Subject: =?utf-8?q?Caf=C3=A9_news?=\r\n",
+		"Content-Type: multipart/alternative; boundary=",
+		"plain body",
+		"<p>html body</p>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("MIME message does not contain %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestSignV4(t *testing.T) {
+	// "get-vanilla" case from the AWS Signature Version 4 test suite
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	signV4(req, nil, "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "service", now)
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %q, want %q", got, want)
+	}
+}
+
+func TestSESMailerSend(t *testing.T) {
+	var gotPath, gotAuth, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.Write([]byte(`{"MessageId":"abc"}`))
+	}))
+	defer server.Close()
+
+	mailer := &SESMailer{
+		Client:          httpclient.New(httpclient.DefaultConfig()),
+		Region:          "us-east-1",
+		From:            "chirpy@example.com",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		endpoint:        server.URL,
+	}
+
+	err := mailer.Send(context.Background(), Message{
+		To:       []string{"user@example.com"},
+		Subject:  "Hello",
+		TextBody: "Plain",
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if gotPath != "/v2/email/outbound-emails" {
+		t.Errorf("path = %q, want %q", gotPath, "/v2/email/outbound-emails")
+	}
+	if !strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKID/") {
+		t.Errorf("Authorization = %q, want a SigV4 header", gotAuth)
+	}
+	if !strings.Contains(gotBody, `"ToAddresses":["user@example.com"]`) {
+		t.Errorf("body = %s, want the recipient address", gotBody)
+	}
+}
+
+type flakyMailer struct {
+	mu       sync.Mutex
+	failures int
+	sent     []Message
+}
+
+func (m *flakyMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failures > 0 {
+		m.failures--
+		return io.ErrUnexpectedEOF
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func TestQueuedMailerRetries(t *testing.T) {
+	runner := jobs.NewRunner(time.Millisecond, 5*time.Millisecond)
+	backend := &flakyMailer{failures: 2}
+	mailer := &QueuedMailer{Mailer: backend, Runner: runner, MaxAttempts: 3}
+
+	if err := mailer.Send(context.Background(), Message{To: []string{"user@example.com"}}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	runner.Wait()
+
+	if len(backend.sent) != 1 {
+		t.Errorf("sent %d messages, want 1", len(backend.sent))
+	}
+}
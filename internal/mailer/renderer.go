@@ -0,0 +1,172 @@
+package mailer
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Embedded email templates, laid out as templates/<name>/v<N>/ with
+// subject.txt, body.txt, body.html and sample.json. Locale overrides live in
+// templates/<name>/v<N>/<locale>/ and replace individual files.
+//
+//go:embed templates
+var embeddedTemplates embed.FS
+
+// ErrTemplateNotFound is returned for unknown template names or versions
+var ErrTemplateNotFound = errors.New("email template not found")
+
+// Renderer renders the embedded email templates
+type Renderer struct {
+	fsys fs.FS
+}
+
+// NewRenderer creates a renderer over the embedded templates directory
+func NewRenderer() *Renderer {
+	sub, err := fs.Sub(embeddedTemplates, "templates")
+	if err != nil {
+		panic(err)
+	}
+	return &Renderer{fsys: sub}
+}
+
+// Names lists the available templates
+func (r *Renderer) Names() ([]string, error) {
+	entries, err := fs.ReadDir(r.fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// Render renders a template for the recipients. The reference is either a
+// bare name ("verification"), which uses the latest version, or a pinned
+// version ("verification@v1"). The locale ("pt-BR") falls back to its base
+// language ("pt") and then to the default files.
+func (r *Renderer) Render(ref, locale string, to []string, data any) (Message, error) {
+	tmpl, err := r.load(ref, locale)
+	if err != nil {
+		return Message{}, err
+	}
+	return tmpl.Render(to, data)
+}
+
+// Sample returns the sample data shipped with a template for previews
+func (r *Renderer) Sample(ref string) (map[string]any, error) {
+	dir, err := r.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := fs.ReadFile(r.fsys, path.Join(dir, "sample.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]any{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sample map[string]any
+	if err := json.Unmarshal(raw, &sample); err != nil {
+		return nil, fmt.Errorf("parsing sample for %s: %w", ref, err)
+	}
+	return sample, nil
+}
+
+// load parses the template files for a reference and locale
+func (r *Renderer) load(ref, locale string) (*Template, error) {
+	dir, err := r.resolve(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]string, 3)
+	for _, file := range []string{"subject.txt", "body.txt", "body.html"} {
+		content, err := r.readLocalized(dir, file, locale)
+		if err != nil {
+			return nil, err
+		}
+		files[file] = content
+	}
+	return NewTemplate(files["subject.txt"], files["body.txt"], files["body.html"])
+}
+
+// readLocalized reads the most specific version of a file for the locale.
+// Missing files are treated as empty so templates may omit a body.
+func (r *Renderer) readLocalized(dir, file, locale string) (string, error) {
+	for _, candidate := range localeCandidates(locale) {
+		content, err := fs.ReadFile(r.fsys, path.Join(dir, candidate, file))
+		if err == nil {
+			return string(content), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// localeCandidates lists directories to try, most specific first
+func localeCandidates(locale string) []string {
+	candidates := []string{}
+	if locale != "" {
+		candidates = append(candidates, locale)
+		if base, _, found := strings.Cut(locale, "-"); found {
+			candidates = append(candidates, base)
+		}
+	}
+	return append(candidates, ".")
+}
+
+// resolve maps a template reference to its version directory
+func (r *Renderer) resolve(ref string) (string, error) {
+	name, version, pinned := strings.Cut(ref, "@")
+	if name == "" || strings.Contains(name, "/") || strings.Contains(name, "..") {
+		return "", ErrTemplateNotFound
+	}
+
+	if pinned {
+		dir := path.Join(name, version)
+		if info, err := fs.Stat(r.fsys, dir); err != nil || !info.IsDir() || !isVersion(version) {
+			return "", ErrTemplateNotFound
+		}
+		return dir, nil
+	}
+
+	entries, err := fs.ReadDir(r.fsys, name)
+	if err != nil {
+		return "", ErrTemplateNotFound
+	}
+	versions := []int{}
+	for _, entry := range entries {
+		if entry.IsDir() && isVersion(entry.Name()) {
+			number, _ := strconv.Atoi(entry.Name()[1:])
+			versions = append(versions, number)
+		}
+	}
+	if len(versions) == 0 {
+		return "", ErrTemplateNotFound
+	}
+	sort.Ints(versions)
+	return path.Join(name, "v"+strconv.Itoa(versions[len(versions)-1])), nil
+}
+
+// isVersion reports whether a directory name looks like "v3"
+func isVersion(name string) bool {
+	if len(name) < 2 || name[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(name[1:])
+	return err == nil
+}
@@ -0,0 +1,107 @@
+package mailer
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRendererEmbeddedTemplates(t *testing.T) {
+	renderer := NewRenderer()
+
+	names, err := renderer.Names()
+	if err != nil {
+		t.Fatalf("Names() error = %v", err)
+	}
+
+	for _, want := range []string{"appeal-decided", "chirp-removed", "digest", "invitation", "login-alert", "operator-alert", "reset", "verification"} {
+		found := false
+		for _, name := range names {
+			found = found || name == want
+		}
+		if !found {
+			t.Errorf("Names() = %v, missing %q", names, want)
+		}
+	}
+
+	// Every embedded template must render with its own sample data
+	for _, name := range names {
+		sample, err := renderer.Sample(name)
+		if err != nil {
+			t.Fatalf("Sample(%q) error = %v", name, err)
+		}
+		msg, err := renderer.Render(name, "", []string{"user@example.com"}, sample)
+		if err != nil {
+			t.Fatalf("Render(%q) error = %v", name, err)
+		}
+		if msg.Subject == "" || msg.TextBody == "" || msg.HTMLBody == "" {
+			t.Errorf("Render(%q) = %+v, want subject and both bodies", name, msg)
+		}
+	}
+}
+
+func TestRendererLocaleFallback(t *testing.T) {
+	renderer := &Renderer{fsys: fstest.MapFS{
+		"welcome/v1/subject.txt":    {Data: []byte("Welcome")},
+		"welcome/v1/body.txt":       {Data: []byte("Hello")},
+		"welcome/v1/es/subject.txt": {Data: []byte("Bienvenido")},
+	}}
+
+	tests := []struct {
+		locale      string
+		wantSubject string
+	}{
+		{locale: "", wantSubject: "Welcome"},
+		{locale: "es", wantSubject: "Bienvenido"},
+		{locale: "es-MX", wantSubject: "Bienvenido"},
+		{locale: "fr", wantSubject: "Welcome"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			msg, err := renderer.Render("welcome", tt.locale, nil, nil)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if msg.Subject != tt.wantSubject {
+				t.Errorf("Subject = %q, want %q", msg.Subject, tt.wantSubject)
+			}
+			// Files without an override fall back to the default
+			if msg.TextBody != "Hello" {
+				t.Errorf("TextBody = %q, want %q", msg.TextBody, "Hello")
+			}
+		})
+	}
+}
+
+func TestRendererVersions(t *testing.T) {
+	renderer := &Renderer{fsys: fstest.MapFS{
+		"welcome/v1/subject.txt":  {Data: []byte("Old")},
+		"welcome/v2/subject.txt":  {Data: []byte("New")},
+		"welcome/v10/subject.txt": {Data: []byte("Newest")},
+	}}
+
+	tests := []struct {
+		ref         string
+		wantSubject string
+		wantErr     error
+	}{
+		{ref: "welcome", wantSubject: "Newest"},
+		{ref: "welcome@v1", wantSubject: "Old"},
+		{ref: "welcome@v3", wantErr: ErrTemplateNotFound},
+		{ref: "missing", wantErr: ErrTemplateNotFound},
+		{ref: "../welcome", wantErr: ErrTemplateNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			msg, err := renderer.Render(tt.ref, "", nil, nil)
+			if err != tt.wantErr {
+				t.Fatalf("Render() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.EqualFold(msg.Subject, tt.wantSubject) {
+				t.Errorf("Subject = %q, want %q", msg.Subject, tt.wantSubject)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/httpclient"
+)
+
+// SESMailer sends messages with the Amazon SES v2 API, signing requests
+// with AWS Signature Version 4
+type SESMailer struct {
+	Client          *httpclient.Client
+	Region          string
+	From            string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// endpoint overrides the regional API endpoint in tests
+	endpoint string
+}
+
+type sesContent struct {
+	Data    string `json:"Data"`
+	Charset string `json:"Charset"`
+}
+
+type sesRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	Content struct {
+		Simple struct {
+			Subject sesContent            `json:"Subject"`
+			Body    map[string]sesContent `json:"Body"`
+		} `json:"Simple"`
+	} `json:"Content"`
+}
+
+// Send delivers the message through SES
+func (m *SESMailer) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return ErrNoRecipients
+	}
+
+	var request sesRequest
+	request.FromEmailAddress = m.From
+	request.Destination.ToAddresses = msg.To
+	request.Content.Simple.Subject = sesContent{Data: msg.Subject, Charset: "UTF-8"}
+	request.Content.Simple.Body = map[string]sesContent{}
+	if msg.TextBody != "" {
+		request.Content.Simple.Body["Text"] = sesContent{Data: msg.TextBody, Charset: "UTF-8"}
+	}
+	if msg.HTMLBody != "" {
+		request.Content.Simple.Body["Html"] = sesContent{Data: msg.HTMLBody, Charset: "UTF-8"}
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	endpoint := m.endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://email.%s.amazonaws.com", m.Region)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/v2/email/outbound-emails", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", m.SessionToken)
+	}
+	signV4(req, payload, m.AccessKeyID, m.SecretAccessKey, m.Region, "ses", time.Now().UTC())
+
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("ses: unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// signV4 adds AWS Signature Version 4 headers to the request
+func signV4(req *http.Request, payload []byte, accessKeyID, secretAccessKey, region, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	// Canonical headers must be lowercase and sorted by name
+	headerNames := []string{"host", "x-amz-date"}
+	headerValues := map[string]string{"host": req.URL.Host, "x-amz-date": amzDate}
+	if contentType := req.Header.Get("Content-Type"); contentType != "" {
+		headerNames = []string{"content-type", "host", "x-amz-date"}
+		headerValues["content-type"] = contentType
+	}
+	if token := req.Header.Get("X-Amz-Security-Token"); token != "" {
+		headerNames = append(headerNames, "x-amz-security-token")
+		headerValues["x-amz-security-token"] = token
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range headerNames {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headerValues[name]) + "\n")
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hashHex(payload),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyID, scope, signedHeaders, signature))
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
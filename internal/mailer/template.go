@@ -0,0 +1,55 @@
+package mailer
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Template renders a Message from data. The HTML body uses html/template so
+// user-supplied values are escaped.
+type Template struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// NewTemplate parses the subject, plain-text and HTML templates. Either body
+// may be empty.
+func NewTemplate(subject, text, html string) (*Template, error) {
+	subjectTmpl, err := texttemplate.New("subject").Parse(subject)
+	if err != nil {
+		return nil, err
+	}
+	textTmpl, err := texttemplate.New("text").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	htmlTmpl, err := htmltemplate.New("html").Parse(html)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{subject: subjectTmpl, text: textTmpl, html: htmlTmpl}, nil
+}
+
+// Render builds a message for the given recipients
+func (t *Template) Render(to []string, data any) (Message, error) {
+	var subject, text, html bytes.Buffer
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return Message{}, err
+	}
+	if err := t.text.Execute(&text, data); err != nil {
+		return Message{}, err
+	}
+	if err := t.html.Execute(&html, data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:       to,
+		Subject:  strings.TrimSpace(subject.String()),
+		TextBody: text.String(),
+		HTMLBody: html.String(),
+	}, nil
+}
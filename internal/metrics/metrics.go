@@ -0,0 +1,177 @@
+// Package metrics keeps in-process counts of the requests a replica serves,
+// their latencies and failing webhook deliveries, for alert rules to read.
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds of the latency histogram; slower
+// requests fall in one more bucket past the end
+var latencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Registry records requests and webhook deliveries. A nil Registry records
+// nothing.
+type Registry struct {
+	mu              sync.Mutex
+	requests        int64
+	serverErrors    int64
+	latencies       []int64
+	webhookFailures int64
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{latencies: make([]int64, len(latencyBuckets)+1)}
+}
+
+// ObserveRequest records a served request with its status and how long it
+// took
+func (r *Registry) ObserveRequest(status int, elapsed time.Duration) {
+	if r == nil {
+		return
+	}
+	bucket := len(latencyBuckets)
+	for i, bound := range latencyBuckets {
+		if elapsed <= bound {
+			bucket = i
+			break
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests++
+	if status >= 500 {
+		r.serverErrors++
+	}
+	r.latencies[bucket]++
+}
+
+// ObserveWebhook records the status an incoming webhook was answered with.
+// A 5xx extends the failure streak; anything else ends it.
+func (r *Registry) ObserveWebhook(status int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if status >= 500 {
+		r.webhookFailures++
+	} else {
+		r.webhookFailures = 0
+	}
+}
+
+// WebhookFailureStreak returns how many incoming webhooks in a row failed
+func (r *Registry) WebhookFailureStreak() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.webhookFailures
+}
+
+// Snapshot copies the request counts recorded so far
+func (r *Registry) Snapshot() Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Snapshot{
+		Requests:     r.requests,
+		ServerErrors: r.serverErrors,
+		Latencies:    append([]int64(nil), r.latencies...),
+	}
+}
+
+// Snapshot holds request counts, either running totals or the requests
+// served between two snapshots
+type Snapshot struct {
+	Requests     int64
+	ServerErrors int64
+	// Latencies counts requests per latency bucket
+	Latencies []int64
+}
+
+// Sub returns the requests recorded in s but not yet in previous
+func (s Snapshot) Sub(previous Snapshot) Snapshot {
+	delta := Snapshot{
+		Requests:     s.Requests - previous.Requests,
+		ServerErrors: s.ServerErrors - previous.ServerErrors,
+		Latencies:    make([]int64, len(s.Latencies)),
+	}
+	for i := range s.Latencies {
+		delta.Latencies[i] = s.Latencies[i]
+		if i < len(previous.Latencies) {
+			delta.Latencies[i] -= previous.Latencies[i]
+		}
+	}
+	return delta
+}
+
+// ErrorRate returns the percentage of requests answered with a 5xx status,
+// or 0 without requests
+func (s Snapshot) ErrorRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.ServerErrors) * 100 / float64(s.Requests)
+}
+
+// Quantile returns the latency that fraction q of requests finished within,
+// rounded up to a histogram bucket bound, or 0 without requests. Requests
+// slower than the last bound count as twice that bound.
+func (s Snapshot) Quantile(q float64) time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	target := int64(q * float64(s.Requests))
+	if float64(target) < q*float64(s.Requests) {
+		target++
+	}
+	var seen int64
+	for i, count := range s.Latencies {
+		seen += count
+		if seen >= target {
+			if i < len(latencyBuckets) {
+				return latencyBuckets[i]
+			}
+			break
+		}
+	}
+	return 2 * latencyBuckets[len(latencyBuckets)-1]
+}
+
+// Window reports the requests recorded between one call to Next and the
+// following one
+type Window struct {
+	registry *Registry
+	mu       sync.Mutex
+	last     Snapshot
+}
+
+// Window starts a window at the requests recorded so far
+func (r *Registry) Window() *Window {
+	return &Window{registry: r, last: r.Snapshot()}
+}
+
+// Next returns the requests recorded since the previous call and starts the
+// next window
+func (w *Window) Next() Snapshot {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	current := w.registry.Snapshot()
+	delta := current.Sub(w.last)
+	w.last = current
+	return delta
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWindow(t *testing.T) {
+	registry := NewRegistry()
+	registry.ObserveRequest(http.StatusOK, time.Millisecond)
+	window := registry.Window()
+
+	// 18 fast requests, one slow and one failing
+	for range 18 {
+		registry.ObserveRequest(http.StatusOK, 20*time.Millisecond)
+	}
+	registry.ObserveRequest(http.StatusOK, 3*time.Second)
+	registry.ObserveRequest(http.StatusBadGateway, 40*time.Millisecond)
+
+	got := window.Next()
+	if got.Requests != 20 || got.ServerErrors != 1 {
+		t.Errorf("window = %d requests, %d errors, want 20, 1", got.Requests, got.ServerErrors)
+	}
+	if rate := got.ErrorRate(); rate != 5 {
+		t.Errorf("ErrorRate() = %v, want 5", rate)
+	}
+	if p95 := got.Quantile(0.95); p95 != 50*time.Millisecond {
+		t.Errorf("Quantile(0.95) = %v, want 50ms", p95)
+	}
+	if p100 := got.Quantile(1); p100 != 5*time.Second {
+		t.Errorf("Quantile(1) = %v, want 5s", p100)
+	}
+
+	empty := window.Next()
+	if empty.Requests != 0 || empty.ErrorRate() != 0 || empty.Quantile(0.95) != 0 {
+		t.Errorf("empty window = %+v", empty)
+	}
+}
+
+func TestWebhookFailureStreak(t *testing.T) {
+	registry := NewRegistry()
+	for _, status := range []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusNoContent, http.StatusServiceUnavailable} {
+		registry.ObserveWebhook(status)
+	}
+	if streak := registry.WebhookFailureStreak(); streak != 1 {
+		t.Errorf("WebhookFailureStreak() = %d, want 1", streak)
+	}
+
+	// A nil registry records nothing
+	var disabled *Registry
+	disabled.ObserveRequest(http.StatusOK, time.Millisecond)
+	disabled.ObserveWebhook(http.StatusInternalServerError)
+}
@@ -0,0 +1,105 @@
+// Package migration signs account bundles with an instance's Ed25519 key, so
+// the instance an account moves to can check which instance a bundle came
+// from before importing it.
+package migration
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/kai-xlr/neo_chirpy/internal/httpclient"
+)
+
+var (
+	ErrInvalidKey       = errors.New("migration key must be a base64 Ed25519 seed of 32 bytes")
+	ErrInvalidSignature = errors.New("bundle signature is invalid")
+	ErrInsecureOrigin   = errors.New("origin must be a public https URL")
+	ErrNoKey            = errors.New("origin doesn't advertise a migration key")
+)
+
+// ParseKey decodes a signing key from its base64 seed, the form it is
+// configured in
+func ParseKey(encoded string) (ed25519.PrivateKey, error) {
+	seed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, ErrInvalidKey
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// PublicKey encodes the public half of key for other instances to fetch
+func PublicKey(key ed25519.PrivateKey) string {
+	return base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+}
+
+// Sign returns payload and its signature, both base64 encoded. Signing the
+// exact bytes sent means the bundle can't be changed by re-encoding it.
+func Sign(key ed25519.PrivateKey, payload []byte) (string, string) {
+	return base64.StdEncoding.EncodeToString(payload),
+		base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+}
+
+// Open checks a payload signed by Sign against the signer's public key and
+// returns the decoded payload
+func Open(payload, signature string, publicKey ed25519.PublicKey) ([]byte, error) {
+	decoded, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || !ed25519.Verify(publicKey, decoded, sig) {
+		return nil, ErrInvalidSignature
+	}
+	return decoded, nil
+}
+
+// FetchKey reads the public migration key an instance advertises in GET
+// /api/instance. Only https origins are contacted; origins come from the
+// bundles users post, so client should have PublicOnly set to keep them
+// from reaching internal hosts.
+func FetchKey(ctx context.Context, client *httpclient.Client, origin string) (ed25519.PublicKey, error) {
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return nil, ErrInsecureOrigin
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(origin, "/")+"/api/instance", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if errors.Is(err, httpclient.ErrNonPublicAddress) {
+		return nil, ErrInsecureOrigin
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching instance of %s: %s", origin, resp.Status)
+	}
+
+	var instance struct {
+		MigrationKey string `json:"migration_key"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&instance); err != nil {
+		return nil, err
+	}
+	if instance.MigrationKey == "" {
+		return nil, ErrNoKey
+	}
+	key, err := base64.StdEncoding.DecodeString(instance.MigrationKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, ErrNoKey
+	}
+	return ed25519.PublicKey(key), nil
+}
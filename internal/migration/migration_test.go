@@ -0,0 +1,71 @@
+package migration
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kai-xlr/neo_chirpy/internal/httpclient"
+)
+
+func TestSignAndOpen(t *testing.T) {
+	key, err := ParseKey(base64.StdEncoding.EncodeToString(make([]byte, ed25519.SeedSize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKey, err := base64.StdEncoding.DecodeString(PublicKey(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, signature := Sign(key, []byte(`{"chirps":[]}`))
+	opened, err := Open(payload, signature, publicKey)
+	if err != nil || string(opened) != `{"chirps":[]}` {
+		t.Fatalf("Open() = %q, %v", opened, err)
+	}
+
+	tampered := base64.StdEncoding.EncodeToString([]byte(`{"chirps":[1]}`))
+	if _, err := Open(tampered, signature, publicKey); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Open(tampered) error = %v, want %v", err, ErrInvalidSignature)
+	}
+
+	other, _, _ := ed25519.GenerateKey(nil)
+	if _, err := Open(payload, signature, other); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Open(other key) error = %v, want %v", err, ErrInvalidSignature)
+	}
+}
+
+func TestParseKeyRejectsBadSeeds(t *testing.T) {
+	for _, encoded := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte(strings.Repeat("x", 16)))} {
+		if _, err := ParseKey(encoded); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("ParseKey(%q) error = %v, want %v", encoded, err, ErrInvalidKey)
+		}
+	}
+}
+
+func TestFetchKeyRequiresHTTPS(t *testing.T) {
+	client := httpclient.New(httpclient.DefaultConfig())
+	for _, origin := range []string{"http://chirpy.example.com", "file:///etc/passwd", "chirpy.example.com"} {
+		if _, err := FetchKey(context.Background(), client, origin); !errors.Is(err, ErrInsecureOrigin) {
+			t.Errorf("FetchKey(%q) error = %v, want %v", origin, err, ErrInsecureOrigin)
+		}
+	}
+}
+
+func TestFetchKeyRefusesInternalHosts(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"migration_key":"` + base64.StdEncoding.EncodeToString(make([]byte, ed25519.PublicKeySize)) + `"}`))
+	}))
+	defer server.Close()
+
+	cfg := httpclient.DefaultConfig()
+	cfg.PublicOnly = true
+	if _, err := FetchKey(context.Background(), httpclient.New(cfg), server.URL); !errors.Is(err, ErrInsecureOrigin) {
+		t.Errorf("FetchKey(%q) error = %v, want %v", server.URL, err, ErrInsecureOrigin)
+	}
+}
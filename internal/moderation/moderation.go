@@ -0,0 +1,88 @@
+// Package moderation scores chirps with an external classifier. Chirps are
+// published before they are scored, so a slow or unavailable classifier
+// never holds up posting; its verdicts are reconciled afterwards.
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/httpclient"
+)
+
+// ErrUnknownVerdict is returned when the classifier answers with a verdict
+// other than allow, flag or remove
+var ErrUnknownVerdict = errors.New("classifier returned an unknown verdict")
+
+// Verdicts a classifier can reach on a chirp
+const (
+	// Allow leaves the chirp alone
+	Allow = "allow"
+	// Flag leaves the chirp up but queues it for moderator review
+	Flag = "flag"
+	// Remove hides the chirp until a moderator reviews it
+	Remove = "remove"
+)
+
+// maxResponseBytes is how much of the classifier's response is read
+const maxResponseBytes = 64 << 10
+
+// Verdict is the classifier's decision on one chirp. Label names the policy
+// it matched, such as "spam", and Score is its confidence.
+type Verdict struct {
+	Verdict string  `json:"verdict"`
+	Label   string  `json:"label"`
+	Score   float64 `json:"score"`
+}
+
+// Classifier scores chirps by posting {"id", "body"} as JSON to URL and
+// reading a Verdict back. Token, when set, is sent as a bearer token.
+type Classifier struct {
+	URL    string
+	Token  string
+	Client *httpclient.Client
+}
+
+// Classify asks the classifier for its verdict on a chirp. Failed requests
+// return an error so the caller can retry them.
+func (c *Classifier) Classify(ctx context.Context, chirpID uuid.UUID, body string) (Verdict, error) {
+	payload, err := json.Marshal(map[string]string{"id": chirpID.String(), "body": body})
+	if err != nil {
+		return Verdict{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(payload))
+	if err != nil {
+		return Verdict{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return Verdict{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+		return Verdict{}, fmt.Errorf("classifier returned %s", resp.Status)
+	}
+
+	var verdict Verdict
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&verdict); err != nil {
+		return Verdict{}, err
+	}
+	switch verdict.Verdict {
+	case Allow, Flag, Remove:
+		return verdict, nil
+	}
+	return Verdict{}, ErrUnknownVerdict
+}
@@ -0,0 +1,55 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/httpclient"
+)
+
+func TestClassify(t *testing.T) {
+	chirpID := uuid.New()
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		want     Verdict
+		wantErr  bool
+	}{
+		{name: "remove", status: http.StatusOK, response: `{"verdict":"remove","label":"spam","score":0.97}`, want: Verdict{Verdict: Remove, Label: "spam", Score: 0.97}},
+		{name: "allow", status: http.StatusOK, response: `{"verdict":"allow"}`, want: Verdict{Verdict: Allow}},
+		{name: "unknown verdict", status: http.StatusOK, response: `{"verdict":"delete"}`, wantErr: true},
+		{name: "malformed", status: http.StatusOK, response: `remove`, wantErr: true},
+		{name: "client error", status: http.StatusUnauthorized, response: `{}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+					t.Errorf("Authorization = %q", got)
+				}
+				var payload map[string]string
+				json.NewDecoder(r.Body).Decode(&payload)
+				if payload["id"] != chirpID.String() || payload["body"] != "Buy now" {
+					t.Errorf("payload = %v", payload)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			classifier := &Classifier{URL: server.URL, Token: "secret", Client: httpclient.New(httpclient.DefaultConfig())}
+			got, err := classifier.Classify(context.Background(), chirpID, "Buy now")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Classify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Classify() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/kai-xlr/neo_chirpy/internal/httpclient"
+)
+
+// testIdP is a minimal OpenID Connect provider that answers every code with
+// the ID token built by idToken
+type testIdP struct {
+	server  *httptest.Server
+	key     *rsa.PrivateKey
+	idToken func(issuer string) jwt.MapClaims
+
+	// lastForm is the most recent token request
+	lastForm url.Values
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	idp := &testIdP{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test-key",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, ok := r.BasicAuth(); !ok || id != "chirpy" || secret != "s3cret" {
+			http.Error(w, "invalid_client", http.StatusUnauthorized)
+			return
+		}
+		r.ParseForm()
+		idp.lastForm = r.PostForm
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, idp.idToken(idp.server.URL))
+		token.Header["kid"] = "test-key"
+		signed, _ := token.SignedString(key)
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *testIdP) provider() *Provider {
+	return &Provider{
+		Issuer:       idp.server.URL,
+		ClientID:     "chirpy",
+		ClientSecret: "s3cret",
+		Client:       httpclient.New(httpclient.DefaultConfig()),
+	}
+}
+
+func validClaims(issuer string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            issuer,
+		"sub":            "user-123",
+		"aud":            "chirpy",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          "n0nce",
+		"email":          "ada@example.com",
+		"email_verified": true,
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	idp := newTestIdP(t)
+	authURL, err := idp.provider().AuthCodeURL(context.Background(), "https://chirpy.example.com/api/sso/callback", "st4te", "n0nce", "verifier")
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+
+	parsed, _ := url.Parse(authURL)
+	if got := parsed.Scheme + "://" + parsed.Host + parsed.Path; got != idp.server.URL+"/authorize" {
+		t.Errorf("AuthCodeURL() endpoint = %q", got)
+	}
+	query := parsed.Query()
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             "chirpy",
+		"redirect_uri":          "https://chirpy.example.com/api/sso/callback",
+		"state":                 "st4te",
+		"nonce":                 "n0nce",
+		"code_challenge":        CodeChallenge("verifier"),
+		"code_challenge_method": "S256",
+	}
+	for param, value := range want {
+		if got := query.Get(param); got != value {
+			t.Errorf("%s = %q, want %q", param, got, value)
+		}
+	}
+}
+
+func TestExchange(t *testing.T) {
+	idp := newTestIdP(t)
+	idp.idToken = validClaims
+
+	claims, err := idp.provider().Exchange(context.Background(), "c0de", "https://chirpy.example.com/api/sso/callback", "verifier", "n0nce")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if claims.Subject != "user-123" || claims.Email != "ada@example.com" || !claims.EmailVerified {
+		t.Errorf("Exchange() claims = %+v", claims)
+	}
+	if idp.lastForm.Get("code") != "c0de" || idp.lastForm.Get("code_verifier") != "verifier" {
+		t.Errorf("token request form = %v", idp.lastForm)
+	}
+}
+
+func TestExchangeRejectsInvalidTokens(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(claims jwt.MapClaims)
+	}{
+		{"wrong nonce", func(claims jwt.MapClaims) { claims["nonce"] = "replayed" }},
+		{"wrong audience", func(claims jwt.MapClaims) { claims["aud"] = "another-app" }},
+		{"wrong issuer", func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" }},
+		{"expired", func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"no expiry", func(claims jwt.MapClaims) { delete(claims, "exp") }},
+		{"no subject", func(claims jwt.MapClaims) { delete(claims, "sub") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := newTestIdP(t)
+			idp.idToken = func(issuer string) jwt.MapClaims {
+				claims := validClaims(issuer)
+				tt.modify(claims)
+				return claims
+			}
+
+			_, err := idp.provider().Exchange(context.Background(), "c0de", "https://chirpy.example.com/api/sso/callback", "verifier", "n0nce")
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Exchange() error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsForeignKey(t *testing.T) {
+	idp := newTestIdP(t)
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims(idp.server.URL))
+	token.Header["kid"] = "test-key"
+	signed, _ := token.SignedString(other)
+
+	if _, err := idp.provider().Verify(context.Background(), signed, "n0nce"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify() error = %v, want ErrInvalidToken", err)
+	}
+}
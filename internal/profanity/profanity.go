@@ -0,0 +1,115 @@
+// Package profanity masks banned words in chirp text. The banned words are
+// kept in the database and cached in memory; Reload picks up changes made
+// by other replicas.
+package profanity
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+)
+
+// Mask replaces each banned word
+const Mask = "****"
+
+// DefaultWords are banned until the list is first loaded from the database
+var DefaultWords = []string{"fornax", "kerfuffle", "sharbert"}
+
+// Filter holds the banned words. A nil filter bans nothing.
+type Filter struct {
+	db *database.Queries
+
+	mu    sync.RWMutex
+	words map[string]struct{}
+}
+
+// New creates a filter that loads its words from db, starting with
+// DefaultWords
+func New(db *database.Queries) *Filter {
+	f := &Filter{db: db}
+	f.Set(DefaultWords)
+	return f
+}
+
+// Reload replaces the cached words with the ones in the database
+func (f *Filter) Reload(ctx context.Context) error {
+	words, err := f.db.GetBannedWords(ctx)
+	if err != nil {
+		return err
+	}
+	f.Set(words)
+	return nil
+}
+
+// Set replaces the cached words. Words are matched case-insensitively.
+func (f *Filter) Set(words []string) {
+	set := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		set[strings.ToLower(word)] = struct{}{}
+	}
+	f.mu.Lock()
+	f.words = set
+	f.mu.Unlock()
+}
+
+// Words returns the banned words in alphabetical order
+func (f *Filter) Words() []string {
+	if f == nil {
+		return []string{}
+	}
+	f.mu.RLock()
+	words := make([]string, 0, len(f.words))
+	for word := range f.words {
+		words = append(words, word)
+	}
+	f.mu.RUnlock()
+	slices.Sort(words)
+	return words
+}
+
+// Clean masks banned words in text. A word is a run of letters and
+// numbers, so punctuation around it is kept: "Kerfuffle!" becomes "****!".
+func (f *Filter) Clean(text string) string {
+	if f == nil {
+		return text
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if len(f.words) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	start := -1
+	flush := func(end int) {
+		if _, banned := f.words[strings.ToLower(text[start:end])]; banned {
+			b.WriteString(Mask)
+		} else {
+			b.WriteString(text[start:end])
+		}
+		start = -1
+	}
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if start < 0 {
+				start = i
+			}
+		} else {
+			if start >= 0 {
+				flush(i)
+			}
+			b.WriteString(text[i : i+size])
+		}
+		i += size
+	}
+	if start >= 0 {
+		flush(len(text))
+	}
+	return b.String()
+}
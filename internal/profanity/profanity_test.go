@@ -0,0 +1,46 @@
+package profanity
+
+import "testing"
+
+func TestClean(t *testing.T) {
+	f := &Filter{}
+	f.Set(DefaultWords)
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"I had a kerfuffle this morning", "I had a **** this morning"},
+		{"Kerfuffle!", "****!"},
+		{"what a (sharbert), FORNAX.", "what a (****), ****."},
+		{"kerfuffles aren't banned", "kerfuffles aren't banned"},
+		{"spacing  is\tkept", "spacing  is\tkept"},
+		{"émoji 🎉 kerfuffle🎉", "émoji 🎉 ****🎉"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := f.Clean(tt.text); got != tt.want {
+			t.Errorf("Clean(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCleanNilFilter(t *testing.T) {
+	var f *Filter
+	if got := f.Clean("kerfuffle"); got != "kerfuffle" {
+		t.Errorf("Clean() = %q, want the text unchanged", got)
+	}
+}
+
+func TestSetReplacesWords(t *testing.T) {
+	f := &Filter{}
+	f.Set(DefaultWords)
+	f.Set([]string{"Gosh"})
+
+	if got := f.Clean("gosh, a kerfuffle"); got != "****, a kerfuffle" {
+		t.Errorf("Clean() = %q", got)
+	}
+	if words := f.Words(); len(words) != 1 || words[0] != "gosh" {
+		t.Errorf("Words() = %v, want [gosh]", words)
+	}
+}
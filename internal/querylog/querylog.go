@@ -0,0 +1,140 @@
+// Package querylog instruments database access: it times every query, logs
+// those slower than a threshold without their parameter values, and counts
+// queries per request so they can be reported in the request log.
+package querylog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+)
+
+// DB wraps a database.DBTX with timing and slow query logging
+type DB struct {
+	db   database.DBTX
+	slow time.Duration
+	logf func(format string, args ...any)
+
+	// latency is a moving average of query time in nanoseconds
+	latency atomic.Int64
+}
+
+var _ database.DBTX = (*DB)(nil)
+
+// New wraps db, logging queries that take longer than slow. A zero
+// threshold disables slow query logging but keeps per-request counts.
+func New(db database.DBTX, slow time.Duration) *DB {
+	return &DB{db: db, slow: slow, logf: log.Printf}
+}
+
+// ExecContext runs a statement that returns no rows
+func (d *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer d.observe(ctx, query, args, time.Now())
+	return d.db.ExecContext(ctx, query, args...)
+}
+
+// PrepareContext prepares a statement. Preparation is counted as a query.
+func (d *DB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	defer d.observe(ctx, query, nil, time.Now())
+	return d.db.PrepareContext(ctx, query)
+}
+
+// QueryContext runs a query returning rows. Only the time until the first
+// rows are available is measured, not the caller's iteration.
+func (d *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer d.observe(ctx, query, args, time.Now())
+	return d.db.QueryContext(ctx, query, args...)
+}
+
+// QueryRowContext runs a query returning at most one row
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer d.observe(ctx, query, args, time.Now())
+	return d.db.QueryRowContext(ctx, query, args...)
+}
+
+// observe records a finished query against the request and logs it if slow
+func (d *DB) observe(ctx context.Context, query string, args []interface{}, start time.Time) {
+	elapsed := time.Since(start)
+	d.recordLatency(elapsed)
+	if stats, ok := ctx.Value(statsKey{}).(*Stats); ok {
+		stats.queries.Add(1)
+		stats.duration.Add(int64(elapsed))
+	}
+	if d.slow > 0 && elapsed >= d.slow {
+		d.logf("Slow query %s took %s %s", queryName(query), elapsed.Round(time.Microsecond), redactArgs(args))
+	}
+}
+
+// latencyWeight is how many queries it takes for the moving average to
+// mostly forget an old value
+const latencyWeight = 16
+
+// recordLatency folds a query's time into the moving average
+func (d *DB) recordLatency(elapsed time.Duration) {
+	for {
+		old := d.latency.Load()
+		next := old + (int64(elapsed)-old)/latencyWeight
+		if old == 0 {
+			next = int64(elapsed)
+		}
+		if d.latency.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
+
+// Latency returns the moving average of recent query times, weighted
+// towards the most recent queries
+func (d *DB) Latency() time.Duration {
+	return time.Duration(d.latency.Load())
+}
+
+// queryName returns the sqlc query name, or the first line of ad hoc SQL
+func queryName(query string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(query), "\n")
+	if name, found := strings.CutPrefix(line, "-- name: "); found {
+		name, _, _ = strings.Cut(name, " ")
+		return name
+	}
+	return line
+}
+
+// redactArgs describes parameters by type only, so values such as emails,
+// password hashes and tokens never reach the logs
+func redactArgs(args []interface{}) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = fmt.Sprintf("$%d=<%T>", i+1, arg)
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
+type statsKey struct{}
+
+// Stats accumulates the queries made while handling one request
+type Stats struct {
+	queries  atomic.Int64
+	duration atomic.Int64
+}
+
+// WithStats returns a context that counts queries made with it
+func WithStats(ctx context.Context) (context.Context, *Stats) {
+	stats := &Stats{}
+	return context.WithValue(ctx, statsKey{}, stats), stats
+}
+
+// Queries returns the number of queries made
+func (s *Stats) Queries() int64 {
+	return s.queries.Load()
+}
+
+// Duration returns the total time spent waiting on queries
+func (s *Stats) Duration() time.Duration {
+	return time.Duration(s.duration.Load())
+}
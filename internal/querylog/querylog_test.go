@@ -0,0 +1,121 @@
+package querylog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeDB answers every call after an optional delay
+type fakeDB struct {
+	delay time.Duration
+}
+
+func (f *fakeDB) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	time.Sleep(f.delay)
+	return nil, nil
+}
+
+func (f *fakeDB) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	time.Sleep(f.delay)
+	return nil, nil
+}
+
+func (f *fakeDB) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	time.Sleep(f.delay)
+	return nil, nil
+}
+
+func (f *fakeDB) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	time.Sleep(f.delay)
+	return nil
+}
+
+func TestSlowQueryLogging(t *testing.T) {
+	tests := []struct {
+		name    string
+		delay   time.Duration
+		slow    time.Duration
+		wantLog bool
+	}{
+		{name: "fast query", delay: 0, slow: time.Second, wantLog: false},
+		{name: "slow query", delay: 5 * time.Millisecond, slow: time.Millisecond, wantLog: true},
+		{name: "threshold disabled", delay: 5 * time.Millisecond, slow: 0, wantLog: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logged []string
+			db := New(&fakeDB{delay: tt.delay}, tt.slow)
+			db.logf = func(format string, args ...any) {
+				logged = append(logged, fmt.Sprintf(format, args...))
+			}
+
+			query := "-- name: GetUserByEmail :one\nSELECT * FROM users WHERE email = $1"
+			db.QueryRowContext(context.Background(), query, "secret@example.com")
+
+			if (len(logged) > 0) != tt.wantLog {
+				t.Fatalf("logged = %v, wantLog %v", logged, tt.wantLog)
+			}
+			if tt.wantLog {
+				if !strings.Contains(logged[0], "GetUserByEmail") || !strings.Contains(logged[0], "$1=<string>") {
+					t.Errorf("log line = %q, want query name and redacted parameter", logged[0])
+				}
+				if strings.Contains(logged[0], "secret@example.com") {
+					t.Errorf("log line = %q leaks a parameter value", logged[0])
+				}
+			}
+		})
+	}
+}
+
+func TestStatsCountsQueries(t *testing.T) {
+	db := New(&fakeDB{}, 0)
+	ctx, stats := WithStats(context.Background())
+
+	db.ExecContext(ctx, "DELETE FROM users")
+	db.QueryContext(ctx, "SELECT 1")
+	db.QueryRowContext(ctx, "SELECT 1")
+	// Queries outside the request context are not counted
+	db.QueryRowContext(context.Background(), "SELECT 1")
+
+	if got := stats.Queries(); got != 3 {
+		t.Errorf("Queries() = %d, want 3", got)
+	}
+}
+
+func TestLatencyAverage(t *testing.T) {
+	db := New(&fakeDB{}, 0)
+	if got := db.Latency(); got != 0 {
+		t.Fatalf("Latency() before any query = %s, want 0", got)
+	}
+
+	// The first query sets the average, later ones pull it towards them
+	db.recordLatency(160 * time.Millisecond)
+	if got := db.Latency(); got != 160*time.Millisecond {
+		t.Errorf("Latency() = %s, want 160ms", got)
+	}
+	db.recordLatency(0)
+	if got := db.Latency(); got != 150*time.Millisecond {
+		t.Errorf("Latency() = %s, want 150ms", got)
+	}
+}
+
+func TestQueryName(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "-- name: GetChirpsAsc :many\nSELECT 1", want: "GetChirpsAsc"},
+		{query: "  SELECT 1\nFROM dual", want: "SELECT 1"},
+	}
+
+	for _, tt := range tests {
+		if got := queryName(tt.query); got != tt.want {
+			t.Errorf("queryName(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
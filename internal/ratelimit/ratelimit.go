@@ -0,0 +1,58 @@
+// Package ratelimit counts requests per client in fixed time windows kept in
+// a cache.Store, so every replica sharing a Redis store enforces one limit.
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
+)
+
+// Result describes a client's standing in the current window
+type Result struct {
+	// Allowed is false once the client has used up the window's limit
+	Allowed bool
+	// Limit is the number of requests allowed per window
+	Limit int
+	// Remaining is how many more requests the window allows
+	Remaining int
+	// Reset is when the current window ends and the count starts over
+	Reset time.Time
+}
+
+// Limiter allows a fixed number of requests per client per window
+type Limiter struct {
+	store  cache.Store
+	limit  int
+	window time.Duration
+	now    func() time.Time
+}
+
+// New creates a limiter allowing limit requests per window
+func New(store cache.Store, limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		store:  store,
+		limit:  limit,
+		window: window,
+		now:    time.Now,
+	}
+}
+
+// Allow records a request from the client identified by key and reports
+// whether it fits within the current window
+func (l *Limiter) Allow(ctx context.Context, key string) (Result, error) {
+	start := l.now().Truncate(l.window)
+	count, err := l.store.Incr(ctx, "ratelimit:"+key+":"+strconv.FormatInt(start.Unix(), 10), l.window)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Allowed:   count <= int64(l.limit),
+		Limit:     l.limit,
+		Remaining: max(l.limit-int(count), 0),
+		Reset:     start.Add(l.window),
+	}, nil
+}
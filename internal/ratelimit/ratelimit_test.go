@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	limiter := New(cache.NewMemory(), 2, time.Minute)
+	now := time.Date(2025, 1, 1, 0, 0, 30, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	steps := []struct {
+		key           string
+		wantAllowed   bool
+		wantRemaining int
+	}{
+		{key: "alice", wantAllowed: true, wantRemaining: 1},
+		{key: "alice", wantAllowed: true, wantRemaining: 0},
+		{key: "alice", wantAllowed: false, wantRemaining: 0},
+		{key: "bob", wantAllowed: true, wantRemaining: 1},
+	}
+	for i, step := range steps {
+		result, err := limiter.Allow(ctx, step.key)
+		if err != nil {
+			t.Fatalf("step %d: Allow() error = %v", i, err)
+		}
+		if result.Allowed != step.wantAllowed || result.Remaining != step.wantRemaining {
+			t.Errorf("step %d: Allow(%q) = allowed %v remaining %d, want %v %d",
+				i, step.key, result.Allowed, result.Remaining, step.wantAllowed, step.wantRemaining)
+		}
+		if want := time.Date(2025, 1, 1, 0, 1, 0, 0, time.UTC); !result.Reset.Equal(want) {
+			t.Errorf("step %d: Reset = %v, want %v", i, result.Reset, want)
+		}
+	}
+
+	// The next window starts from zero
+	now = now.Add(time.Minute)
+	result, err := limiter.Allow(ctx, "alice")
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	if !result.Allowed || result.Remaining != 1 {
+		t.Errorf("Allow() in next window = allowed %v remaining %d, want true 1", result.Allowed, result.Remaining)
+	}
+}
@@ -0,0 +1,90 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakePolicy records how it was applied
+type fakePolicy struct {
+	counted, deleted bool
+	cutoff           time.Time
+}
+
+func (f *fakePolicy) policy(name string, maxAge time.Duration) Policy {
+	return Policy{
+		Name:   name,
+		MaxAge: maxAge,
+		Count: func(_ context.Context, cutoff time.Time) (int64, error) {
+			f.counted, f.cutoff = true, cutoff
+			return 42, nil
+		},
+		Delete: func(_ context.Context, cutoff time.Time) (int64, error) {
+			f.deleted, f.cutoff = true, cutoff
+			return 42, nil
+		},
+	}
+}
+
+type auditEntry struct {
+	action, details string
+}
+
+func TestEngineDryRunOnlyCounts(t *testing.T) {
+	var entries []auditEntry
+	tokens := &fakePolicy{}
+	engine := &Engine{
+		Policies: []Policy{tokens.policy("revoked_tokens", 30*24*time.Hour)},
+		DryRun:   true,
+		Audit: func(_ context.Context, action, details string) error {
+			entries = append(entries, auditEntry{action, details})
+			return nil
+		},
+	}
+
+	if err := engine.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if !tokens.counted || tokens.deleted {
+		t.Errorf("counted = %v, deleted = %v, want only counted", tokens.counted, tokens.deleted)
+	}
+	if age := time.Since(tokens.cutoff); age < 30*24*time.Hour || age > 30*24*time.Hour+time.Minute {
+		t.Errorf("cutoff is %s ago, want 30 days", age)
+	}
+	want := auditEntry{ActionDryRun, "revoked_tokens: would delete 42 rows older than 720h0m0s"}
+	if len(entries) != 1 || entries[0] != want {
+		t.Errorf("audit entries = %+v, want %+v", entries, want)
+	}
+}
+
+func TestEngineDeletesAndContinuesAfterFailure(t *testing.T) {
+	var entries []auditEntry
+	tokens := &fakePolicy{}
+	failing := Policy{
+		Name:   "audit_log",
+		MaxAge: time.Hour,
+		Delete: func(context.Context, time.Time) (int64, error) {
+			return 0, errors.New("connection reset")
+		},
+	}
+	engine := &Engine{
+		Policies: []Policy{failing, tokens.policy("revoked_tokens", time.Hour)},
+		Audit: func(_ context.Context, action, details string) error {
+			entries = append(entries, auditEntry{action, details})
+			return nil
+		},
+	}
+
+	if err := engine.Run(context.Background()); err == nil {
+		t.Error("Run() error = nil, want the failing policy reported")
+	}
+	if !tokens.deleted {
+		t.Error("policy after the failing one was not applied")
+	}
+	if len(entries) != 1 || entries[0].action != ActionDelete {
+		t.Errorf("audit entries = %+v, want one %s entry", entries, ActionDelete)
+	}
+}
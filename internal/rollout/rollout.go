@@ -0,0 +1,132 @@
+// Package rollout turns features on for a stable percentage of users, so a
+// launch can be widened gradually. Each user falls into one of 100 buckets
+// per feature; raising a feature's percentage only ever adds users.
+package rollout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Buckets is the number of cohorts users are split into for each feature
+const Buckets = 100
+
+// Flags holds the percentage of users each feature is enabled for
+type Flags struct {
+	percents map[string]int
+	names    []string
+}
+
+// Parse reads flags written as "feature=percent", such as
+// "pagination_envelope=10"
+func Parse(specs []string) (*Flags, error) {
+	flags := &Flags{percents: make(map[string]int, len(specs))}
+	for _, spec := range specs {
+		name, raw, found := strings.Cut(spec, "=")
+		name = strings.TrimSpace(name)
+		if !found || name == "" {
+			return nil, fmt.Errorf("rollout %q must be written as feature=percent", spec)
+		}
+		if strings.ContainsAny(name, ",; ") {
+			return nil, fmt.Errorf("rollout feature %q can't contain commas, semicolons or spaces", name)
+		}
+		percent, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil || percent < 0 || percent > 100 {
+			return nil, fmt.Errorf("rollout %s: percent must be a whole number between 0 and 100", name)
+		}
+		if _, dup := flags.percents[name]; dup {
+			return nil, errors.New("rollout feature " + name + " is listed twice")
+		}
+		flags.percents[name] = percent
+		flags.names = append(flags.names, name)
+	}
+	sort.Strings(flags.names)
+	return flags, nil
+}
+
+// Bucket places a user in one of Buckets cohorts for a feature. The feature
+// name is part of the hash, so the first users to get one feature aren't
+// also the first to get every other.
+func Bucket(feature string, userID uuid.UUID) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(feature))
+	hash.Write([]byte{':'})
+	hash.Write(userID[:])
+	return int(hash.Sum32() % Buckets)
+}
+
+// Enabled reports whether the feature is on for the user. Anonymous users
+// (uuid.Nil) only get features rolled out to everyone, and unknown features
+// are off.
+func (f *Flags) Enabled(feature string, userID uuid.UUID) bool {
+	if f == nil {
+		return false
+	}
+	percent, found := f.percents[feature]
+	if !found {
+		return false
+	}
+	if percent >= 100 {
+		return true
+	}
+	if userID == uuid.Nil {
+		return false
+	}
+	return Bucket(feature, userID) < percent
+}
+
+// Assignment is the set of features a user gets, fixed for one request
+type Assignment map[string]bool
+
+// Assign decides every feature for the user
+func (f *Flags) Assign(userID uuid.UUID) Assignment {
+	if f == nil || len(f.names) == 0 {
+		return nil
+	}
+	assignment := make(Assignment, len(f.names))
+	for _, name := range f.names {
+		assignment[name] = f.Enabled(name, userID)
+	}
+	return assignment
+}
+
+// String lists the features as "name=on" or "name=off", sorted by name, in
+// the form sent in the X-Chirpy-Variant header
+func (a Assignment) String() string {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		state := "off"
+		if a[name] {
+			state = "on"
+		}
+		parts[i] = name + "=" + state
+	}
+	return strings.Join(parts, ",")
+}
+
+type contextKey struct{}
+
+// WithAssignment returns a context carrying the request's assignment
+func WithAssignment(ctx context.Context, a Assignment) context.Context {
+	return context.WithValue(ctx, contextKey{}, a)
+}
+
+// Enabled reports whether the feature is on for the request's user. It is
+// off when the request wasn't assigned features.
+func Enabled(ctx context.Context, feature string) bool {
+	a, _ := ctx.Value(contextKey{}).(Assignment)
+	return a[feature]
+}
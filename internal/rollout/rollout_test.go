@@ -0,0 +1,117 @@
+package rollout
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []string
+		wantErr string
+	}{
+		{name: "valid", specs: []string{"pagination_envelope=10", " new_feed = 100 "}},
+		{name: "none"},
+		{name: "missing percent", specs: []string{"pagination_envelope"}, wantErr: "feature=percent"},
+		{name: "missing name", specs: []string{"=10"}, wantErr: "feature=percent"},
+		{name: "not a number", specs: []string{"new_feed=half"}, wantErr: "between 0 and 100"},
+		{name: "over 100", specs: []string{"new_feed=101"}, wantErr: "between 0 and 100"},
+		{name: "duplicate", specs: []string{"new_feed=10", "new_feed=20"}, wantErr: "listed twice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.specs)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Parse() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEnabledPercentages(t *testing.T) {
+	flags, err := Parse([]string{"none=0", "some=25", "all=100"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const users = 4000
+	counts := map[string]int{}
+	for range users {
+		userID := uuid.New()
+		for _, feature := range []string{"none", "some", "all", "unknown"} {
+			if flags.Enabled(feature, userID) {
+				counts[feature]++
+			}
+		}
+	}
+
+	if counts["none"] != 0 || counts["unknown"] != 0 {
+		t.Errorf("none = %d, unknown = %d, want 0", counts["none"], counts["unknown"])
+	}
+	if counts["all"] != users {
+		t.Errorf("all = %d, want %d", counts["all"], users)
+	}
+	if counts["some"] < users*20/100 || counts["some"] > users*30/100 {
+		t.Errorf("some = %d of %d, want about 25%%", counts["some"], users)
+	}
+
+	if flags.Enabled("some", uuid.Nil) || !flags.Enabled("all", uuid.Nil) {
+		t.Error("anonymous users should only get features rolled out to everyone")
+	}
+}
+
+func TestEnabledIsStableAsRolloutWidens(t *testing.T) {
+	narrow, err := Parse([]string{"new_feed=10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wide, err := Parse([]string{"new_feed=50"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 1000 {
+		userID := uuid.New()
+		if narrow.Enabled("new_feed", userID) && !wide.Enabled("new_feed", userID) {
+			t.Fatalf("user %s lost new_feed when the rollout widened", userID)
+		}
+		if narrow.Enabled("new_feed", userID) != narrow.Enabled("new_feed", userID) {
+			t.Fatalf("user %s got different answers for the same rollout", userID)
+		}
+	}
+}
+
+func TestAssignment(t *testing.T) {
+	flags, err := Parse([]string{"zeta=100", "alpha=0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assignment := flags.Assign(uuid.New())
+	if got := assignment.String(); got != "alpha=off,zeta=on" {
+		t.Errorf("String() = %q, want alpha=off,zeta=on", got)
+	}
+
+	ctx := WithAssignment(context.Background(), assignment)
+	if !Enabled(ctx, "zeta") || Enabled(ctx, "alpha") {
+		t.Error("Enabled() doesn't match the assignment")
+	}
+	if Enabled(context.Background(), "zeta") {
+		t.Error("Enabled() without an assignment should be off")
+	}
+
+	var none *Flags
+	if none.Assign(uuid.New()) != nil {
+		t.Error("nil flags should assign nothing")
+	}
+}
@@ -0,0 +1,233 @@
+// Package tenant identifies which community a request belongs to. A single
+// deployment can host several isolated communities; each request is resolved
+// to one of them from its host name or the X-Chirpy-Tenant header. Verified
+// custom domains resolve to their community, and to a profile when a user
+// added them.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+)
+
+// Header selects a tenant explicitly, taking precedence over the host name
+const Header = "X-Chirpy-Tenant"
+
+// DefaultSlug is the slug of the community every single-tenant deployment
+// uses, and which existing data was assigned to
+const DefaultSlug = "default"
+
+// DefaultID is the ID of the default community, created by the migration
+var DefaultID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// ErrUnknownTenant is returned when a request names a tenant that doesn't exist
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// cacheTTL bounds how long a resolved tenant is reused before being looked up again
+const cacheTTL = time.Minute
+
+// Tenant is a community hosted by this deployment
+type Tenant struct {
+	ID          uuid.UUID
+	Slug        string
+	Name        string
+	Description string
+
+	// Profile is the user whose custom domain the request arrived on, or
+	// uuid.Nil
+	Profile uuid.UUID
+}
+
+// IsDefault reports whether t is the default community
+func (t Tenant) IsDefault() bool {
+	return t.ID == DefaultID
+}
+
+// Default returns the default community
+func Default() Tenant {
+	return Tenant{ID: DefaultID, Slug: DefaultSlug}
+}
+
+type contextKey struct{}
+
+// WithTenant returns a context carrying t
+func WithTenant(ctx context.Context, t Tenant) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext returns the request's tenant, or the default community when
+// none was resolved (single-tenant mode, background jobs and tests)
+func FromContext(ctx context.Context) Tenant {
+	if t, ok := ctx.Value(contextKey{}).(Tenant); ok {
+		return t
+	}
+	return Default()
+}
+
+// Resolver maps requests to tenants. When disabled every request belongs to
+// the default community.
+type Resolver struct {
+	DB         *database.Queries
+	Enabled    bool
+	BaseDomain string
+
+	// CustomDomains routes requests on verified custom domains, even when
+	// multi-tenancy is disabled
+	CustomDomains bool
+	// PublicHost is the deployment's own host name, which is never looked
+	// up as a custom domain
+	PublicHost string
+
+	mu      sync.Mutex
+	cache   map[string]cachedTenant
+	domains map[string]cachedTenant
+}
+
+type cachedTenant struct {
+	tenant  Tenant
+	found   bool
+	expires time.Time
+}
+
+// Resolve returns the tenant a request is addressed to. A custom domain
+// takes precedence over the X-Chirpy-Tenant header.
+func (res *Resolver) Resolve(r *http.Request) (Tenant, error) {
+	if host := requestHost(r); res.CustomDomains && res.isCustomHost(host) {
+		t, found, err := res.lookupDomain(r.Context(), host)
+		if err != nil {
+			return Tenant{}, err
+		}
+		if found {
+			return t, nil
+		}
+	}
+
+	if !res.Enabled {
+		return Default(), nil
+	}
+
+	slug := SlugFromRequest(r, res.BaseDomain)
+	if slug == "" || slug == DefaultSlug {
+		return Default(), nil
+	}
+	return res.lookup(r.Context(), slug)
+}
+
+// lookup loads a tenant by slug, reusing recent results
+func (res *Resolver) lookup(ctx context.Context, slug string) (Tenant, error) {
+	res.mu.Lock()
+	cached, found := res.cache[slug]
+	res.mu.Unlock()
+	if found && time.Now().Before(cached.expires) {
+		return cached.tenant, nil
+	}
+
+	dbTenant, err := res.DB.GetTenantBySlug(ctx, slug)
+	if err != nil {
+		if err.Error() == "sql: no rows in result set" {
+			return Tenant{}, ErrUnknownTenant
+		}
+		return Tenant{}, err
+	}
+
+	t := Tenant{
+		ID:          dbTenant.ID,
+		Slug:        dbTenant.Slug,
+		Name:        dbTenant.Name,
+		Description: dbTenant.Description,
+	}
+	res.mu.Lock()
+	if res.cache == nil {
+		res.cache = make(map[string]cachedTenant)
+	}
+	res.cache[slug] = cachedTenant{tenant: t, expires: time.Now().Add(cacheTTL)}
+	res.mu.Unlock()
+	return t, nil
+}
+
+// isCustomHost reports whether host could be a custom domain rather than
+// one of the deployment's own names
+func (res *Resolver) isCustomHost(host string) bool {
+	if !strings.Contains(host, ".") || net.ParseIP(host) != nil {
+		return false
+	}
+	if host == strings.ToLower(res.PublicHost) {
+		return false
+	}
+	if base := strings.ToLower(res.BaseDomain); base != "" && (host == base || strings.HasSuffix(host, "."+base)) {
+		return false
+	}
+	return true
+}
+
+// lookupDomain loads the tenant of a verified custom domain, reusing recent
+// results. Unknown domains are remembered too, so stray Host headers don't
+// reach the database on every request.
+func (res *Resolver) lookupDomain(ctx context.Context, host string) (Tenant, bool, error) {
+	res.mu.Lock()
+	cached, hit := res.domains[host]
+	res.mu.Unlock()
+	if hit && time.Now().Before(cached.expires) {
+		return cached.tenant, cached.found, nil
+	}
+
+	var t Tenant
+	row, err := res.DB.GetVerifiedCustomDomain(ctx, host)
+	found := err == nil
+	if err != nil && err.Error() != "sql: no rows in result set" {
+		return Tenant{}, false, err
+	}
+	if found {
+		t = Tenant{
+			ID:          row.TenantID,
+			Slug:        row.Slug,
+			Name:        row.Name,
+			Description: row.Description,
+			Profile:     row.UserID.UUID,
+		}
+	}
+
+	res.mu.Lock()
+	if res.domains == nil {
+		res.domains = make(map[string]cachedTenant)
+	}
+	res.domains[host] = cachedTenant{tenant: t, found: found, expires: time.Now().Add(cacheTTL)}
+	res.mu.Unlock()
+	return t, found, nil
+}
+
+// SlugFromRequest returns the tenant slug named by the X-Chirpy-Tenant
+// header, or else by the subdomain of baseDomain in the Host header. An empty
+// result means the default community.
+func SlugFromRequest(r *http.Request, baseDomain string) string {
+	if slug := strings.TrimSpace(r.Header.Get(Header)); slug != "" {
+		return strings.ToLower(slug)
+	}
+	if baseDomain == "" {
+		return ""
+	}
+
+	subdomain, found := strings.CutSuffix(requestHost(r), "."+strings.ToLower(baseDomain))
+	if !found || strings.Contains(subdomain, ".") {
+		return ""
+	}
+	return subdomain
+}
+
+// requestHost returns the lowercased host name of the Host header, without
+// port or trailing dot
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
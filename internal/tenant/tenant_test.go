@@ -0,0 +1,85 @@
+package tenant
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSlugFromRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		host       string
+		header     string
+		baseDomain string
+		want       string
+	}{
+		{name: "subdomain", host: "birds.chirpy.example", baseDomain: "chirpy.example", want: "birds"},
+		{name: "subdomain with port", host: "Birds.chirpy.example:8080", baseDomain: "chirpy.example", want: "birds"},
+		{name: "base domain itself", host: "chirpy.example", baseDomain: "chirpy.example", want: ""},
+		{name: "nested subdomain", host: "a.b.chirpy.example", baseDomain: "chirpy.example", want: ""},
+		{name: "other domain", host: "birds.elsewhere.example", baseDomain: "chirpy.example", want: ""},
+		{name: "no base domain", host: "birds.chirpy.example", want: ""},
+		{name: "header wins", host: "birds.chirpy.example", header: "Bees", baseDomain: "chirpy.example", want: "bees"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/api/chirps", nil)
+			r.Host = tt.host
+			if tt.header != "" {
+				r.Header.Set(Header, tt.header)
+			}
+			if got := SlugFromRequest(r, tt.baseDomain); got != tt.want {
+				t.Errorf("SlugFromRequest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveDisabled(t *testing.T) {
+	r := httptest.NewRequest("GET", "/api/chirps", nil)
+	r.Header.Set(Header, "birds")
+
+	got, err := (&Resolver{}).Resolve(r)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if !got.IsDefault() {
+		t.Errorf("Resolve() = %+v, want default tenant when disabled", got)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if got := FromContext(context.Background()); !got.IsDefault() {
+		t.Errorf("FromContext() without tenant = %+v, want default", got)
+	}
+
+	birds := Tenant{Slug: "birds"}
+	if got := FromContext(WithTenant(context.Background(), birds)); got.Slug != "birds" {
+		t.Errorf("FromContext() = %+v, want birds", got)
+	}
+}
+
+func TestIsCustomHost(t *testing.T) {
+	res := &Resolver{BaseDomain: "chirpy.example", PublicHost: "www.chirpy.example"}
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{host: "chirps.birds.example", want: true},
+		{host: "chirpy.example", want: false},
+		{host: "birds.chirpy.example", want: false},
+		{host: "www.chirpy.example", want: false},
+		{host: "localhost", want: false},
+		{host: "10.0.0.1", want: false},
+		{host: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := res.isCustomHost(tt.host); got != tt.want {
+				t.Errorf("isCustomHost(%q) = %v, want %v", tt.host, got, tt.want)
+			}
+		})
+	}
+}
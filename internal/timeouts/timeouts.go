@@ -0,0 +1,95 @@
+// Package timeouts decides how long a request may take. Budgets are set per
+// route group, written as "[METHOD ]/path/prefix=duration", and the budget
+// with the longest matching prefix applies.
+package timeouts
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Budget is the time allowed for requests whose path starts with Prefix.
+// An empty Method matches every method; GET also matches HEAD. A zero
+// Timeout exempts the routes from any deadline.
+type Budget struct {
+	Method  string
+	Prefix  string
+	Timeout time.Duration
+}
+
+// Budgets holds the per-route budgets and the default for other routes
+type Budgets struct {
+	Default time.Duration
+
+	// budgets is sorted by descending prefix length, with method-specific
+	// budgets before method-less ones for the same prefix
+	budgets []Budget
+}
+
+// Parse reads budgets such as "GET /api/=2s" or "/api/firehose=0s". Routes
+// without a budget get def, where zero means no deadline.
+func Parse(specs []string, def time.Duration) (*Budgets, error) {
+	if def < 0 {
+		return nil, errors.New("default request timeout can't be negative")
+	}
+	b := &Budgets{Default: def, budgets: make([]Budget, 0, len(specs))}
+	seen := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		route, raw, found := strings.Cut(spec, "=")
+		route = strings.TrimSpace(route)
+		if !found || route == "" {
+			return nil, fmt.Errorf("route timeout %q must be written as [METHOD ]/path=duration", spec)
+		}
+
+		var budget Budget
+		if method, prefix, hasMethod := strings.Cut(route, " "); hasMethod {
+			budget.Method, budget.Prefix = strings.ToUpper(method), strings.TrimSpace(prefix)
+		} else {
+			budget.Prefix = route
+		}
+		if !strings.HasPrefix(budget.Prefix, "/") {
+			return nil, fmt.Errorf("route timeout %q: path must start with /", spec)
+		}
+
+		timeout, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil || timeout < 0 {
+			return nil, fmt.Errorf("route timeout %q: duration must be like 2s or 500ms", spec)
+		}
+		budget.Timeout = timeout
+
+		key := budget.Method + " " + budget.Prefix
+		if seen[key] {
+			return nil, fmt.Errorf("route timeout for %s is listed twice", strings.TrimSpace(key))
+		}
+		seen[key] = true
+		b.budgets = append(b.budgets, budget)
+	}
+
+	sort.SliceStable(b.budgets, func(i, j int) bool {
+		if len(b.budgets[i].Prefix) != len(b.budgets[j].Prefix) {
+			return len(b.budgets[i].Prefix) > len(b.budgets[j].Prefix)
+		}
+		return b.budgets[i].Method != "" && b.budgets[j].Method == ""
+	})
+	return b, nil
+}
+
+// For returns the budget for a request, zero meaning no deadline
+func (b *Budgets) For(method, path string) time.Duration {
+	if b == nil {
+		return 0
+	}
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	for _, budget := range b.budgets {
+		if strings.HasPrefix(path, budget.Prefix) && (budget.Method == "" || budget.Method == method) {
+			return budget.Timeout
+		}
+	}
+	return b.Default
+}
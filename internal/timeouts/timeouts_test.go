@@ -0,0 +1,60 @@
+package timeouts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBudgetsFor(t *testing.T) {
+	budgets, err := Parse([]string{
+		"GET /api/=2s",
+		"/api/=5s",
+		"/api/firehose=0s",
+		"post /api/chirps=3s",
+	}, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		want   time.Duration
+	}{
+		{"GET", "/api/chirps", 2 * time.Second},
+		{"HEAD", "/api/chirps", 2 * time.Second},
+		{"DELETE", "/api/chirps/abc", 5 * time.Second},
+		{"POST", "/api/chirps", 3 * time.Second},
+		{"GET", "/api/firehose", 0},
+		{"GET", "/admin/metrics", 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := budgets.For(tt.method, tt.path); got != tt.want {
+			t.Errorf("For(%s %s) = %s, want %s", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestBudgetsForNil(t *testing.T) {
+	var budgets *Budgets
+	if got := budgets.For("GET", "/api/chirps"); got != 0 {
+		t.Errorf("For() = %s, want no deadline", got)
+	}
+}
+
+func TestParseRejectsInvalidBudgets(t *testing.T) {
+	for _, spec := range []string{
+		"/api/",
+		"=2s",
+		"api/=2s",
+		"/api/=soon",
+		"/api/=-1s",
+	} {
+		if _, err := Parse([]string{spec}, 0); err == nil {
+			t.Errorf("Parse(%q) succeeded", spec)
+		}
+	}
+	if _, err := Parse([]string{"GET /api/=2s", "GET /api/=3s"}, 0); err == nil {
+		t.Error("Parse() accepted a duplicate route")
+	}
+}
@@ -0,0 +1,63 @@
+// Package version exposes build metadata injected at link time:
+//
+//	go build -ldflags "-X github.com/kai-xlr/neo_chirpy/internal/version.Version=v1.2.0 \
+//	  -X github.com/kai-xlr/neo_chirpy/internal/version.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/kai-xlr/neo_chirpy/internal/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)" \
+//	  ./cmd/web
+//
+// Values that are not injected fall back to the VCS stamp Go embeds in the
+// binary, so plain `go build` still reports the commit.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set with -ldflags "-X"
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information, filling gaps from debug.BuildInfo
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if buildInfo, ok := debug.ReadBuildInfo(); ok {
+		fillFromBuildInfo(&info, buildInfo)
+	}
+	return info
+}
+
+// fillFromBuildInfo copies VCS settings into fields left unset by ldflags
+func fillFromBuildInfo(info *Info, buildInfo *debug.BuildInfo) {
+	settings := make(map[string]string, len(buildInfo.Settings))
+	for _, setting := range buildInfo.Settings {
+		settings[setting.Key] = setting.Value
+	}
+
+	if info.Commit == "" && settings["vcs.revision"] != "" {
+		info.Commit = settings["vcs.revision"]
+		if settings["vcs.modified"] == "true" {
+			info.Commit += "-dirty"
+		}
+	}
+	if info.BuildTime == "" {
+		info.BuildTime = settings["vcs.time"]
+	}
+}
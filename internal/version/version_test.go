@@ -0,0 +1,41 @@
+package version
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestFillFromBuildInfo(t *testing.T) {
+	buildInfo := &debug.BuildInfo{Settings: []debug.BuildSetting{
+		{Key: "vcs.modified", Value: "true"},
+		{Key: "vcs.revision", Value: "abc123"},
+		{Key: "vcs.time", Value: "2025-01-02T03:04:05Z"},
+	}}
+
+	tests := []struct {
+		name string
+		info Info
+		want Info
+	}{
+		{
+			name: "fills unset fields",
+			info: Info{Version: "dev"},
+			want: Info{Version: "dev", Commit: "abc123-dirty", BuildTime: "2025-01-02T03:04:05Z"},
+		},
+		{
+			name: "ldflags take precedence",
+			info: Info{Version: "v1.0.0", Commit: "def456", BuildTime: "2025-06-01T00:00:00Z"},
+			want: Info{Version: "v1.0.0", Commit: "def456", BuildTime: "2025-06-01T00:00:00Z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := tt.info
+			fillFromBuildInfo(&info, buildInfo)
+			if info != tt.want {
+				t.Errorf("fillFromBuildInfo() = %+v, want %+v", info, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Package worddiff compares two texts word by word, the way `git diff
+// --word-diff` does, so short edits such as a chirp revision read as the
+// words that changed rather than whole changed lines.
+package worddiff
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Ops a Span can have
+const (
+	Equal  = "equal"
+	Delete = "delete"
+	Insert = "insert"
+)
+
+// Span is a run of text that is in both texts, only the old one or only the
+// new one
+type Span struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// Diff returns the spans that turn old into new: the text of the Equal and
+// Delete spans in order is old, and of the Equal and Insert spans is new.
+// Whitespace is kept, so both texts can be rebuilt exactly.
+func Diff(old, new string) []Span {
+	a, b := tokens(old), tokens(new)
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var spans []Span
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			spans = appendSpan(spans, Equal, a[i])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			spans = appendSpan(spans, Delete, a[i])
+			i++
+		default:
+			spans = appendSpan(spans, Insert, b[j])
+			j++
+		}
+	}
+	return spans
+}
+
+// Unified renders spans as one text with deletions marked [-like this-]
+// and insertions {+like this+}
+func Unified(spans []Span) string {
+	var b strings.Builder
+	for _, span := range spans {
+		switch span.Op {
+		case Delete:
+			b.WriteString("[-" + span.Text + "-]")
+		case Insert:
+			b.WriteString("{+" + span.Text + "+}")
+		default:
+			b.WriteString(span.Text)
+		}
+	}
+	return b.String()
+}
+
+// appendSpan adds text to the last span if it has the same op
+func appendSpan(spans []Span, op, text string) []Span {
+	if n := len(spans); n > 0 && spans[n-1].Op == op {
+		spans[n-1].Text += text
+		return spans
+	}
+	return append(spans, Span{Op: op, Text: text})
+}
+
+// tokens splits text into words and the whitespace between them
+func tokens(text string) []string {
+	var out []string
+	start, inSpace := 0, false
+	for i, r := range text {
+		space := unicode.IsSpace(r)
+		if i > start && space != inSpace {
+			out = append(out, text[start:i])
+			start = i
+		}
+		inSpace = space
+	}
+	if start < len(text) {
+		out = append(out, text[start:])
+	}
+	return out
+}
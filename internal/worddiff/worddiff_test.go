@@ -0,0 +1,44 @@
+package worddiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     string
+	}{
+		{old: "same text", new: "same text", want: "same text"},
+		{old: "a b c", new: "a x c", want: "a [-b-]{+x+} c"},
+		{old: "I love Go", new: "I really love Go!", want: "I {+really +}love [-Go-]{+Go!+}"},
+		{old: "remove the last word", new: "remove the last", want: "remove the last[- word-]"},
+		{old: "", new: "brand new", want: "{+brand new+}"},
+		{old: "日本語 です", new: "日本語　です", want: "日本語[- -]{+　+}です"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.new, func(t *testing.T) {
+			if got := Unified(Diff(tt.old, tt.new)); got != tt.want {
+				t.Errorf("Unified(Diff(%q, %q)) = %q, want %q", tt.old, tt.new, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffRebuildsBothTexts(t *testing.T) {
+	old := "Just setting up my chirpy,  this is the first one"
+	new := "Just set up my\tchirpy, this was the first one ever"
+	var before, after strings.Builder
+	for _, span := range Diff(old, new) {
+		if span.Op != Insert {
+			before.WriteString(span.Text)
+		}
+		if span.Op != Delete {
+			after.WriteString(span.Text)
+		}
+	}
+	if before.String() != old || after.String() != new {
+		t.Errorf("spans rebuild %q and %q, want %q and %q", before.String(), after.String(), old, new)
+	}
+}
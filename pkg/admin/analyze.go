@@ -0,0 +1,99 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// planNode is the subset of a PostgreSQL EXPLAIN (FORMAT JSON) node we inspect
+type planNode struct {
+	NodeType     string     `json:"Node Type"`
+	RelationName string     `json:"Relation Name"`
+	Filter       string     `json:"Filter"`
+	SortKey      []string   `json:"Sort Key"`
+	TotalCost    float64    `json:"Total Cost"`
+	Plans        []planNode `json:"Plans"`
+}
+
+// HandlerAnalyze handles GET /admin/db/analyze requests. It runs EXPLAIN
+// (without ANALYZE, so nothing is executed) on the canonical listing and
+// lookup queries and flags plans that scan or sort whole tables. Only
+// available in the dev environment.
+func (cfg *Config) HandlerAnalyze(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+	if cfg.Platform != "dev" {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("Query analysis is only allowed in dev environment."))
+		return
+	}
+
+	var response types.DBAnalyzeResponse
+	for _, query := range database.CanonicalQueries() {
+		var planJSON []byte
+		err := cfg.SQL.QueryRowContext(r.Context(), "EXPLAIN (FORMAT JSON)\n"+query.SQL, query.Args...).Scan(&planJSON)
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't explain query "+query.Name, err)
+			return
+		}
+
+		plan, err := analyzePlan(query.Name, planJSON)
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't parse plan for query "+query.Name, err)
+			return
+		}
+		response.Queries = append(response.Queries, plan)
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// analyzePlan decodes an EXPLAIN (FORMAT JSON) result and collects warnings
+// for sequential scans and explicit sorts, which usually point at a missing
+// index. Tiny tables are scanned sequentially regardless of indexes, so the
+// warnings are only meaningful against realistic data volumes.
+func analyzePlan(name string, planJSON []byte) (types.QueryPlan, error) {
+	var explained []struct {
+		Plan planNode `json:"Plan"`
+	}
+	if err := json.Unmarshal(planJSON, &explained); err != nil {
+		return types.QueryPlan{}, err
+	}
+	if len(explained) == 0 {
+		return types.QueryPlan{}, fmt.Errorf("empty plan")
+	}
+
+	root := explained[0].Plan
+	result := types.QueryPlan{
+		Name:      name,
+		TotalCost: root.TotalCost,
+		Plan:      json.RawMessage(planJSON),
+		Warnings:  []string{},
+	}
+	walkPlan(root, func(node planNode) {
+		switch node.NodeType {
+		case "Seq Scan":
+			warning := "Sequential scan on " + node.RelationName
+			if node.Filter != "" {
+				warning += " filtered by " + node.Filter
+			}
+			result.Warnings = append(result.Warnings, warning+"; an index may be missing")
+		case "Sort":
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Explicit sort on %v; an index in this order would avoid it", node.SortKey))
+		}
+	})
+	return result, nil
+}
+
+// walkPlan calls visit for node and each of its descendants
+func walkPlan(node planNode, visit func(planNode)) {
+	visit(node)
+	for _, child := range node.Plans {
+		walkPlan(child, visit)
+	}
+}
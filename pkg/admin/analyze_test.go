@@ -0,0 +1,52 @@
+package admin
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzePlan(t *testing.T) {
+	tests := []struct {
+		name         string
+		plan         string
+		wantWarnings []string
+		wantErr      bool
+	}{
+		{
+			name: "index scan",
+			plan: `[{"Plan": {"Node Type": "Index Scan", "Relation Name": "chirps", "Total Cost": 8.3}}]`,
+		},
+		{
+			name: "sorted sequential scan",
+			plan: `[{"Plan": {"Node Type": "Sort", "Sort Key": ["chirps.created_at"], "Total Cost": 42.1,
+				"Plans": [{"Node Type": "Seq Scan", "Relation Name": "chirps", "Filter": "(published_at <= now())"}]}}]`,
+			wantWarnings: []string{
+				"Explicit sort on [chirps.created_at]; an index in this order would avoid it",
+				"Sequential scan on chirps filtered by (published_at <= now()); an index may be missing",
+			},
+		},
+		{
+			name:    "empty plan",
+			plan:    `[]`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := analyzePlan("GetChirpsAsc", []byte(tt.plan))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("analyzePlan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.wantWarnings == nil {
+				tt.wantWarnings = []string{}
+			}
+			if !reflect.DeepEqual(got.Warnings, tt.wantWarnings) {
+				t.Errorf("Warnings = %q, want %q", got.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// HandlerAPIKeys handles /admin/api-keys requests: GET lists the
+// community's firehose API keys with their usage, POST registers a new one
+// and DELETE /admin/api-keys/{id} revokes one
+func (cfg *Config) HandlerAPIKeys(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(handlers.ExtractIDFromPath(r.URL.Path, "/admin/api-keys"), "/")
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		cfg.handlerAPIKeysList(w, r)
+	case id == "" && r.Method == http.MethodPost:
+		cfg.handlerAPIKeysCreate(w, r)
+	case id != "" && r.Method == http.MethodDelete:
+		cfg.handlerAPIKeysRevoke(w, r, id)
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+	}
+}
+
+func (cfg *Config) handlerAPIKeysList(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireAdmin(w, r); !ok {
+		return
+	}
+
+	keys, err := cfg.DB.ListAPIKeys(r.Context(), tenant.FromContext(r.Context()).ID)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve API keys", err)
+		return
+	}
+
+	response := make([]types.APIKey, len(keys))
+	for i, key := range keys {
+		response[i] = buildAPIKeyResponse(key)
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *Config) handlerAPIKeysCreate(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireAdmin(w, r); !ok {
+		return
+	}
+
+	var request types.APIKeyRequest
+	if !handlers.DecodeJSON(w, r, &request) {
+		return
+	}
+	name := strings.TrimSpace(request.Name)
+	if request.Tier == "" {
+		request.Tier = types.APIKeyTierStandard
+	}
+
+	apiKey, err := auth.MakeRefreshToken()
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't generate API key", err)
+		return
+	}
+	key, err := cfg.DB.CreateAPIKey(r.Context(), database.CreateAPIKeyParams{
+		KeyHash:  auth.HashAPIKey(apiKey),
+		Name:     name,
+		Tier:     request.Tier,
+		TenantID: tenant.FromContext(r.Context()).ID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't create API key", err)
+		return
+	}
+
+	response := buildAPIKeyResponse(key)
+	response.Key = apiKey
+	handlers.RespondWithJSON(w, http.StatusCreated, response)
+}
+
+func (cfg *Config) handlerAPIKeysRevoke(w http.ResponseWriter, r *http.Request, id string) {
+	if _, ok := cfg.requireAdmin(w, r); !ok {
+		return
+	}
+
+	keyID, err := uuid.Parse(id)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid API key ID", err)
+		return
+	}
+	revoked, err := cfg.DB.RevokeAPIKey(r.Context(), database.RevokeAPIKeyParams{
+		ID:       keyID,
+		TenantID: tenant.FromContext(r.Context()).ID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't revoke API key", err)
+		return
+	}
+	if revoked == 0 {
+		handlers.RespondWithError(w, http.StatusNotFound, "API key not found", nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func buildAPIKeyResponse(key database.ApiKey) types.APIKey {
+	response := types.APIKey{
+		ID:              key.ID,
+		Name:            key.Name,
+		Tier:            key.Tier,
+		CreatedAt:       types.NewTimestamp(key.CreatedAt),
+		Requests:        key.Requests,
+		ChirpsDelivered: key.ChirpsDelivered,
+		Revoked:         key.RevokedAt.Valid,
+	}
+	if key.LastUsedAt.Valid {
+		lastUsed := types.NewTimestamp(key.LastUsedAt.Time)
+		response.LastUsedAt = &lastUsed
+	}
+	return response
+}
@@ -0,0 +1,190 @@
+package admin
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+const appealsPrefix = "/admin/appeals/"
+
+// Audit log action for deciding an appeal
+const auditActionDecideAppeal = "appeal.decide"
+
+// HandlerAppeals handles GET /admin/appeals, the appeal queue, and POST
+// /admin/appeals/{id}/decide requests
+func (cfg *Config) HandlerAppeals(w http.ResponseWriter, r *http.Request) {
+	idString, subresource := handlers.SplitResourcePath(r.URL.Path, appealsPrefix)
+	if idString == "" {
+		if !handlers.RequireMethod(w, r, http.MethodGet) {
+			return
+		}
+		cfg.handlerAppealsList(w, r)
+		return
+	}
+
+	appealID, err := uuid.Parse(idString)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid appeal ID", err)
+		return
+	}
+
+	switch subresource {
+	case "decide":
+		if !handlers.RequireMethod(w, r, http.MethodPost) {
+			return
+		}
+		cfg.handlerAppealDecide(w, r, appealID)
+	default:
+		handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+	}
+}
+
+// handlerAppealsList lists open appeals oldest first, or decided ones with
+// ?status=decided, paged with limit and offset
+func (cfg *Config) handlerAppealsList(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireModerator(w, r); !ok {
+		return
+	}
+
+	var open bool
+	switch r.URL.Query().Get("status") {
+	case "", "open":
+		open = true
+	case "decided":
+		open = false
+	default:
+		handlers.RespondWithError(w, http.StatusBadRequest, "status must be open or decided", nil)
+		return
+	}
+
+	limit, offset, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := cfg.DB.GetAppeals(r.Context(), database.GetAppealsParams{
+		TenantID:   tenant.FromContext(r.Context()).ID,
+		Open:       open,
+		PageSize:   limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve appeals", err)
+		return
+	}
+
+	response := make([]types.Appeal, len(rows))
+	for i, row := range rows {
+		response[i] = handlers.BuildAppealResponse(row.Appeal)
+		response[i].ChirpBody = row.ChirpBody
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// handlerAppealDecide approves or denies an appeal and emails the author the
+// outcome. Approving reverses the removal and shows the chirp again;
+// denying upholds it, leaving the chirp to the purge job, and the chirp
+// can't be appealed again. Every decision is recorded in the audit log.
+func (cfg *Config) handlerAppealDecide(w http.ResponseWriter, r *http.Request, appealID uuid.UUID) {
+	actorID, ok := cfg.requireModerator(w, r)
+	if !ok {
+		return
+	}
+
+	var request types.AppealDecisionRequest
+	if !handlers.DecodeJSON(w, r, &request) {
+		return
+	}
+
+	tenantID := tenant.FromContext(r.Context()).ID
+	decided, err := cfg.DB.DecideAppeal(r.Context(), database.DecideAppealParams{
+		ActorID:  actorID,
+		Decision: request.Decision,
+		ID:       appealID,
+		TenantID: tenantID,
+		Action:   auditActionDecideAppeal,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			cfg.respondAppealNotDecided(w, r, appealID, tenantID)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't decide appeal", err)
+		}
+		return
+	}
+
+	appeal := database.Appeal(decided)
+
+	if request.Decision == types.AppealApproved {
+		cfg.Events.Publish(events.Event{
+			Type:    events.ChirpRestored,
+			UserID:  appeal.UserID,
+			ChirpID: appeal.ChirpID,
+		})
+	}
+	// The decision is already recorded; failing the request over the email
+	// would only make the moderator decide again
+	if err := cfg.notifyAppealDecided(r.Context(), appeal); err != nil {
+		log.Printf("Couldn't email the decision on appeal %s: %s", appeal.ID, err)
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, handlers.BuildAppealResponse(appeal))
+}
+
+// respondAppealNotDecided tells apart an appeal that doesn't exist from one
+// another moderator decided first
+func (cfg *Config) respondAppealNotDecided(w http.ResponseWriter, r *http.Request, appealID, tenantID uuid.UUID) {
+	_, err := cfg.DB.GetAppeal(r.Context(), database.GetAppealParams{
+		ID:       appealID,
+		TenantID: tenantID,
+	})
+	switch {
+	case err == nil:
+		handlers.RespondWithError(w, http.StatusConflict, "Appeal already decided", nil)
+	case err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set":
+		handlers.RespondWithError(w, http.StatusNotFound, "Appeal not found", nil)
+	default:
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve appeal", err)
+	}
+}
+
+// notifyAppealDecided emails the author of an appealed chirp the decision.
+// Without a mailer the author isn't told.
+func (cfg *Config) notifyAppealDecided(ctx context.Context, appeal database.Appeal) error {
+	if cfg.Mailer == nil || cfg.Templates == nil {
+		return nil
+	}
+	author, err := cfg.DB.GetUserByID(ctx, database.GetUserByIDParams{
+		TenantID: appeal.TenantID,
+		ID:       appeal.UserID,
+	})
+	if err != nil {
+		return err
+	}
+	// A denied appeal leaves the chirp removed, out of GetChirpByID's reach
+	chirpBody, err := cfg.DB.GetAppealedChirpBody(ctx, database.GetAppealedChirpBodyParams{
+		ID:       appeal.ID,
+		TenantID: appeal.TenantID,
+	})
+	if err != nil {
+		return err
+	}
+
+	msg, err := cfg.Templates.Render("appeal-decided", "", []string{author.Email}, map[string]any{
+		"Email":     author.Email,
+		"ChirpBody": chirpBody,
+		"Approved":  appeal.Decision.String == types.AppealApproved,
+	})
+	if err != nil {
+		return err
+	}
+	return cfg.Mailer.Send(ctx, msg)
+}
@@ -0,0 +1,35 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// HandlerBackups handles GET /admin/backups requests, listing the stored
+// database backups newest first. Only admins of the default community can
+// see them, since a backup covers every community.
+func (cfg *Config) HandlerBackups(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+	if _, ok := cfg.requireInstanceAdmin(w, r); !ok {
+		return
+	}
+	if cfg.Backups == nil {
+		handlers.RespondWithError(w, http.StatusNotFound, "Backups are not enabled", nil)
+		return
+	}
+
+	backups, err := cfg.Backups.List()
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't list backups", err)
+		return
+	}
+	response := make([]types.Backup, len(backups))
+	for i, b := range backups {
+		response[i] = types.Backup{Name: b.Name, Size: b.Size, CreatedAt: types.NewTimestamp(b.CreatedAt)}
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,59 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// HandlerBanEvasion handles GET /admin/ban-evasion requests, which list the
+// accounts suspended over SCIM that share an IP address or device with
+// other accounts, paged over suspended accounts with limit and offset.
+// Fingerprints are compared by their keyed hashes, so the addresses and
+// device IDs themselves are never shown.
+func (cfg *Config) HandlerBanEvasion(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+	if _, ok := cfg.requireModerator(w, r); !ok {
+		return
+	}
+
+	limit, offset, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := cfg.DB.GetBanEvasionMatches(r.Context(), database.GetBanEvasionMatchesParams{
+		TenantID:   tenant.FromContext(r.Context()).ID,
+		PageSize:   limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve ban evasion matches", err)
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, buildBanEvasionClusters(rows))
+}
+
+// buildBanEvasionClusters groups matches by suspended account, relying on
+// the query to return each account's matches together
+func buildBanEvasionClusters(rows []database.GetBanEvasionMatchesRow) []types.BanEvasionCluster {
+	clusters := []types.BanEvasionCluster{}
+	for _, row := range rows {
+		if len(clusters) == 0 || clusters[len(clusters)-1].SuspendedUserID != row.SuspendedUserID {
+			clusters = append(clusters, types.BanEvasionCluster{SuspendedUserID: row.SuspendedUserID})
+		}
+		cluster := &clusters[len(clusters)-1]
+		cluster.Accounts = append(cluster.Accounts, types.BanEvasionMatch{
+			UserID:     row.UserID,
+			Username:   row.Username.String,
+			Shared:     row.Shared,
+			LastSeenAt: types.NewTimestamp(row.LastSeenAt),
+		})
+	}
+	return clusters
+}
@@ -0,0 +1,186 @@
+package admin
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+// Audit log action for replacing the banned word list
+const auditActionBannedWords = "banned_words.update"
+
+// HandlerBannedWords handles GET /admin/banned-words, which lists the words
+// masked in chirp bodies, and PUT, which replaces them, along with the
+// export and import sub-resources. The list is instance-wide, so only
+// admins of the default community may manage it.
+func (cfg *Config) HandlerBannedWords(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimPrefix(r.URL.Path, "/admin/banned-words") {
+	case "":
+	case "/export":
+		cfg.handlerBannedWordsExport(w, r)
+		return
+	case "/import":
+		cfg.handlerBannedWordsImport(w, r)
+		return
+	default:
+		handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPut {
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+		return
+	}
+	actorID, ok := cfg.requireInstanceAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		words, err := cfg.bannedWords(r)
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve banned words", err)
+			return
+		}
+		handlers.RespondWithJSON(w, http.StatusOK, types.BannedWords{Words: words})
+		return
+	}
+
+	var request types.BannedWords
+	if !handlers.DecodeJSON(w, r, &request) {
+		return
+	}
+	words, ok := cfg.replaceBannedWords(w, r, actorID, request.Words)
+	if !ok {
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, types.BannedWords{Words: words})
+}
+
+// handlerBannedWordsExport handles GET /admin/banned-words/export requests,
+// returning the list as a file another instance can import
+func (cfg *Config) handlerBannedWordsExport(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+	if _, ok := cfg.requireInstanceAdmin(w, r); !ok {
+		return
+	}
+
+	words, err := cfg.bannedWords(r)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve banned words", err)
+		return
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="banned-words.json"`)
+	handlers.RespondWithJSON(w, http.StatusOK, types.WordList{
+		Kind:       types.WordListBanned,
+		Version:    types.WordListVersion,
+		ExportedAt: types.NewTimestamp(time.Now()),
+		Source:     cfg.InstanceName,
+		Words:      words,
+	})
+}
+
+// handlerBannedWordsImport handles POST /admin/banned-words/import requests.
+// The exported list is merged into the current one, or replaces it with
+// ?mode=replace.
+func (cfg *Config) handlerBannedWordsImport(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodPost) {
+		return
+	}
+	actorID, ok := cfg.requireInstanceAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if err := validation.ValidateImportMode(mode); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	var list types.WordList
+	if !handlers.DecodeJSON(w, r, &list) {
+		return
+	}
+	if err := validation.ValidateWordList(list, types.WordListBanned); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	current, err := cfg.bannedWords(r)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve banned words", err)
+		return
+	}
+	words := list.Words
+	if mode != validation.ImportReplace {
+		words = append(slices.Clone(current), list.Words...)
+	}
+	words, ok = cfg.replaceBannedWords(w, r, actorID, words)
+	if !ok {
+		return
+	}
+
+	added := 0
+	for _, word := range words {
+		if !slices.Contains(current, word) {
+			added++
+		}
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, types.WordListImportResponse{Added: added, Words: words})
+}
+
+// bannedWords returns the stored banned words, sorted
+func (cfg *Config) bannedWords(r *http.Request) ([]string, error) {
+	words, err := cfg.DB.GetBannedWords(r.Context())
+	if words == nil {
+		words = []string{}
+	}
+	return words, err
+}
+
+// replaceBannedWords validates, normalizes and stores a new banned word
+// list, applying it on this server at once. On failure it responds with the
+// error and returns false.
+func (cfg *Config) replaceBannedWords(w http.ResponseWriter, r *http.Request, actorID uuid.UUID, requested []string) ([]string, bool) {
+	if err := validation.ValidateBannedWords(requested); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return nil, false
+	}
+
+	words := make([]string, len(requested))
+	for i, word := range requested {
+		words[i] = strings.ToLower(word)
+	}
+	slices.Sort(words)
+	words = slices.Compact(words)
+
+	if err := cfg.DB.ReplaceBannedWords(r.Context(), words); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update banned words", err)
+		return nil, false
+	}
+	// Other replicas pick the change up on their next reload
+	if cfg.Profanity != nil {
+		cfg.Profanity.Set(words)
+	}
+
+	err := cfg.DB.CreateAuditLogEntry(r.Context(), database.CreateAuditLogEntryParams{
+		ActorID: actorID,
+		Action:  auditActionBannedWords,
+		Details: strconv.Itoa(len(words)) + " words",
+	})
+	if err != nil {
+		log.Printf("Couldn't record banned word change in the audit log: %s", err)
+	}
+	return words, true
+}
@@ -0,0 +1,282 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+const (
+	// maxBulkUsers caps the rows of one bulk provisioning request
+	maxBulkUsers = 1000
+
+	// bulkReportTTL is how long a bulk job's report can be fetched
+	bulkReportTTL = 24 * time.Hour
+
+	// invitationTTL is how long an invited user has to choose a password
+	invitationTTL = 7 * 24 * time.Hour
+)
+
+// handlerUsersBulk handles POST /admin/users/bulk, which queues accounts to
+// be created and invited, and GET /admin/users/bulk/{id}, which reports on
+// the job
+func (cfg *Config) handlerUsersBulk(w http.ResponseWriter, r *http.Request, jobID string) {
+	switch {
+	case jobID == "" && r.Method == http.MethodPost:
+		cfg.handlerUsersBulkCreate(w, r)
+	case jobID != "" && r.Method == http.MethodGet:
+		cfg.handlerUsersBulkReport(w, r, jobID)
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+	}
+}
+
+func (cfg *Config) handlerUsersBulkCreate(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireAdmin(w, r); !ok {
+		return
+	}
+	// Invitation links can't come from the request's Host header, which
+	// anyone can set to their own site
+	if cfg.PublicURL == "" {
+		handlers.RespondWithError(w, http.StatusNotFound, "Invitations need PUBLIC_URL to be configured", nil)
+		return
+	}
+
+	users, err := parseBulkUsers(r)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if len(users) == 0 {
+		handlers.RespondWithError(w, http.StatusBadRequest, "No users given", nil)
+		return
+	}
+	if len(users) > maxBulkUsers {
+		handlers.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("At most %d users can be provisioned at once", maxBulkUsers), nil)
+		return
+	}
+
+	report := types.BulkUserReport{
+		ID:        uuid.New(),
+		Status:    types.BulkJobPending,
+		CreatedAt: types.NewTimestamp(time.Now().UTC()),
+		Total:     len(users),
+		Results:   []types.BulkUserResult{},
+	}
+	tenantID := tenant.FromContext(r.Context()).ID
+	if err := cfg.saveBulkReport(r.Context(), tenantID, report); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't start provisioning", err)
+		return
+	}
+
+	resetURL := strings.TrimRight(cfg.PublicURL, "/") + "/reset"
+	cfg.Jobs.Enqueue("provision users", 1, func(ctx context.Context) error {
+		return cfg.provisionUsers(ctx, tenantID, resetURL, report, users)
+	})
+
+	w.Header().Set("Location", usersPrefix+"bulk/"+report.ID.String())
+	handlers.RespondWithJSON(w, http.StatusAccepted, report)
+}
+
+func (cfg *Config) handlerUsersBulkReport(w http.ResponseWriter, r *http.Request, jobID string) {
+	if _, ok := cfg.requireAdmin(w, r); !ok {
+		return
+	}
+
+	id, err := uuid.Parse(jobID)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid job ID", err)
+		return
+	}
+	data, err := cfg.Store.Get(r.Context(), bulkReportKey(tenant.FromContext(r.Context()).ID, id))
+	if err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			handlers.RespondWithError(w, http.StatusNotFound, "Job not found", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve job", err)
+		}
+		return
+	}
+
+	var report types.BulkUserReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve job", err)
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, report)
+}
+
+// parseBulkUsers reads the rows of a bulk request: CSV with an "email"
+// column and an optional "username" column when the body is text/csv, JSON
+// otherwise
+func parseBulkUsers(r *http.Request) ([]types.BulkUser, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "text/csv" {
+		var request types.BulkUserRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			return nil, errors.New("Body must be JSON or CSV")
+		}
+		return request.Users, nil
+	}
+
+	reader := csv.NewReader(r.Body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("Invalid CSV")
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+	emailCol, usernameCol := slices.Index(header, "email"), slices.Index(header, "username")
+	if emailCol < 0 {
+		return nil, errors.New("CSV must have an email column")
+	}
+
+	var users []types.BulkUser
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return users, nil
+		}
+		if err != nil {
+			return nil, errors.New("Invalid CSV")
+		}
+		var user types.BulkUser
+		if emailCol < len(record) {
+			user.Email = record[emailCol]
+		}
+		if usernameCol >= 0 && usernameCol < len(record) {
+			user.Username = record[usernameCol]
+		}
+		users = append(users, user)
+	}
+}
+
+// provisionUsers creates and invites each user in turn, saving the report
+// after every row so progress can be followed
+func (cfg *Config) provisionUsers(ctx context.Context, tenantID uuid.UUID, resetURL string, report types.BulkUserReport, users []types.BulkUser) error {
+	for i, user := range users {
+		result := cfg.provisionUser(ctx, tenantID, resetURL, user)
+		result.Row = i + 1
+		report.Results = append(report.Results, result)
+		if i == len(users)-1 {
+			report.Status = types.BulkJobDone
+		}
+		if err := cfg.saveBulkReport(ctx, tenantID, report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// provisionUser creates one account without a password and emails the user
+// a link to choose one
+func (cfg *Config) provisionUser(ctx context.Context, tenantID uuid.UUID, resetURL string, user types.BulkUser) types.BulkUserResult {
+	email := strings.TrimSpace(user.Email)
+	result := types.BulkUserResult{Email: email}
+	if err := validation.ValidateEmail(email); err != nil {
+		result.Status, result.Error = types.BulkRowInvalid, err.Error()
+		return result
+	}
+	var username sql.NullString
+	if handle := validation.NormalizeHandle(user.Username); handle != "" {
+		// Admins may hand out reserved handles
+		if err := validation.ValidateHandle(handle, nil); err != nil {
+			result.Status, result.Error = types.BulkRowInvalid, err.Error()
+			return result
+		}
+		username = sql.NullString{String: handle, Valid: true}
+	}
+
+	created, err := cfg.DB.CreateInvitedUser(ctx, database.CreateInvitedUserParams{
+		Email:    email,
+		Username: username,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			result.Status, result.Error = types.BulkRowExists, "Email or handle is already taken"
+		} else {
+			result.Status, result.Error = types.BulkRowFailed, "Couldn't create user"
+		}
+		return result
+	}
+	result.Status, result.UserID = types.BulkRowCreated, &created.ID
+
+	cfg.Events.Publish(events.Event{
+		Type:   events.UserCreated,
+		UserID: created.ID,
+	})
+
+	if err := cfg.sendInvitation(ctx, created, resetURL); err != nil {
+		result.Error = "Account created, but the invitation couldn't be sent"
+	}
+	return result
+}
+
+// sendInvitation issues a password reset token for a new account and emails
+// it to the user
+func (cfg *Config) sendInvitation(ctx context.Context, user database.User, resetURL string) error {
+	token, err := auth.MakeRefreshToken()
+	if err != nil {
+		return err
+	}
+	err = cfg.DB.CreatePasswordResetToken(ctx, database.CreatePasswordResetTokenParams{
+		TokenHash: auth.HashAPIKey(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().UTC().Add(invitationTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	msg, err := cfg.Templates.Render("invitation", "", []string{user.Email}, map[string]any{
+		"Email":          user.Email,
+		"InstanceName":   cfg.InstanceName,
+		"SetPasswordURL": resetURL + "?token=" + url.QueryEscape(token),
+		"ExpiresInDays":  int(invitationTTL / (24 * time.Hour)),
+	})
+	if err != nil {
+		return err
+	}
+	return cfg.Mailer.Send(ctx, msg)
+}
+
+// saveBulkReport stores the report under the community that started the job
+func (cfg *Config) saveBulkReport(ctx context.Context, tenantID uuid.UUID, report types.BulkUserReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return cfg.Store.Set(ctx, bulkReportKey(tenantID, report.ID), data, bulkReportTTL)
+}
+
+// bulkReportKey scopes reports to their community
+func bulkReportKey(tenantID, jobID uuid.UUID) string {
+	return "bulk-users:" + tenantID.String() + ":" + jobID.String()
+}
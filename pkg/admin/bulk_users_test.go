@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestParseBulkUsers(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        []types.BulkUser
+		wantErr     bool
+	}{
+		{
+			name:        "json",
+			contentType: "application/json",
+			body:        `{"users": [{"email": "a@example.com"}, {"email": "b@example.com", "username": "bee"}]}`,
+			want: []types.BulkUser{
+				{Email: "a@example.com"},
+				{Email: "b@example.com", Username: "bee"},
+			},
+		},
+		{
+			name:        "csv with reordered columns",
+			contentType: "text/csv; charset=utf-8",
+			body:        "Username, Email\nbee, b@example.com\n,c@example.com\n",
+			want: []types.BulkUser{
+				{Email: "b@example.com", Username: "bee"},
+				{Email: "c@example.com"},
+			},
+		},
+		{
+			name:        "csv with short rows",
+			contentType: "text/csv",
+			body:        "email,username\na@example.com\n",
+			want:        []types.BulkUser{{Email: "a@example.com"}},
+		},
+		{
+			name:        "csv header only",
+			contentType: "text/csv",
+			body:        "email\n",
+		},
+		{
+			name:        "csv without email column",
+			contentType: "text/csv",
+			body:        "name\nalice\n",
+			wantErr:     true,
+		},
+		{
+			name:        "invalid json",
+			contentType: "application/json",
+			body:        "a@example.com",
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/admin/users/bulk", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			got, err := parseBulkUsers(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBulkUsers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseBulkUsers() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
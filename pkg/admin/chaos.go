@@ -0,0 +1,40 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/kai-xlr/neo_chirpy/internal/chaos"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// HandlerChaos handles /admin/chaos requests: GET returns the active fault
+// injection rules, PUT replaces them and DELETE turns injection off. Only
+// available in the dev environment, where Chaos is set.
+func (cfg *Config) HandlerChaos(w http.ResponseWriter, r *http.Request) {
+	if cfg.Platform != "dev" || cfg.Chaos == nil {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("Fault injection is only allowed in dev environment."))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		handlers.RespondWithJSON(w, http.StatusOK, cfg.Chaos.Rules())
+	case http.MethodPut:
+		var rules []chaos.Rule
+		if !handlers.DecodeJSON(w, r, &rules) {
+			return
+		}
+		if err := cfg.Chaos.SetRules(rules); err != nil {
+			handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		handlers.RespondWithJSON(w, http.StatusOK, cfg.Chaos.Rules())
+	case http.MethodDelete:
+		cfg.Chaos.SetRules(nil)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+	}
+}
@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+const chirpsPrefix = "/admin/chirps/"
+
+// Audit log actions on chirps
+const (
+	auditActionLock   = "chirp.lock"
+	auditActionUnlock = "chirp.unlock"
+)
+
+// HandlerChirps handles /admin/chirps/{id}/{action} requests
+func (cfg *Config) HandlerChirps(w http.ResponseWriter, r *http.Request) {
+	idString, subresource := handlers.SplitResourcePath(r.URL.Path, chirpsPrefix)
+	chirpID, err := uuid.Parse(idString)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	switch subresource {
+	case "lock":
+		cfg.handlerChirpLock(w, r, chirpID)
+	default:
+		handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+	}
+}
+
+// handlerChirpLock handles POST (lock) and DELETE (unlock) on
+// /admin/chirps/{id}/lock. A locked chirp takes no new replies, reactions
+// or likes. Moderators and admins may lock chirps, and every change is
+// recorded in the audit log.
+func (cfg *Config) handlerChirpLock(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
+	var locked bool
+	var action string
+	switch r.Method {
+	case http.MethodPost:
+		locked, action = true, auditActionLock
+	case http.MethodDelete:
+		locked, action = false, auditActionUnlock
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+		return
+	}
+
+	actorID, ok := cfg.requireModerator(w, r)
+	if !ok {
+		return
+	}
+
+	chirp, err := cfg.DB.SetChirpLocked(r.Context(), database.SetChirpLockedParams{
+		ID:       chirpID,
+		Locked:   locked,
+		ActorID:  actorID,
+		Action:   action,
+		TenantID: tenant.FromContext(r.Context()).ID,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		}
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, handlers.BuildChirpResponse(database.Chirp(chirp)))
+}
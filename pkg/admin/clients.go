@@ -0,0 +1,39 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// HandlerClients handles GET /admin/clients requests, reporting how many
+// chirps and authors each app has posted for in the community, busiest
+// first. Chirps posted without a User-Agent are grouped under an empty source.
+func (cfg *Config) HandlerClients(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+	if _, ok := cfg.requireAdmin(w, r); !ok {
+		return
+	}
+
+	rows, err := cfg.DB.GetClientUsage(r.Context(), tenant.FromContext(r.Context()).ID)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve client usage", err)
+		return
+	}
+
+	usage := make([]types.ClientUsage, len(rows))
+	for i, row := range rows {
+		usage[i] = types.ClientUsage{
+			Source:      row.Source,
+			ClientID:    row.OauthClientID.String,
+			Chirps:      row.Chirps,
+			Authors:     row.Authors,
+			LastChirpAt: types.NewTimestamp(row.LastChirpAt),
+		}
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, usage)
+}
@@ -0,0 +1,20 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+)
+
+// HandlerConfig handles GET /admin/config requests. It returns the effective
+// runtime configuration, with the source of each value and secrets masked,
+// so operators can check what the server actually loaded.
+func (cfg *Config) HandlerConfig(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+	if _, ok := cfg.requireInstanceAdmin(w, r); !ok {
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, cfg.RuntimeConfig.Settings())
+}
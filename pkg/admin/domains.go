@@ -0,0 +1,118 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/domains"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// HandlerDomains handles /admin/domains requests: GET lists every custom
+// domain, those of profiles included, POST adds a domain for a community
+// and DELETE /admin/domains/{domain} removes any domain. Only admins of the
+// default community can manage domains.
+func (cfg *Config) HandlerDomains(w http.ResponseWriter, r *http.Request) {
+	if !cfg.CustomDomains {
+		handlers.RespondWithError(w, http.StatusNotFound, "Custom domains are not enabled", nil)
+		return
+	}
+
+	name := strings.Trim(handlers.ExtractIDFromPath(r.URL.Path, "/admin/domains"), "/")
+	switch {
+	case name == "" && r.Method == http.MethodGet:
+		cfg.handlerDomainsList(w, r)
+	case name == "" && r.Method == http.MethodPost:
+		cfg.handlerDomainsCreate(w, r)
+	case name != "" && r.Method == http.MethodDelete:
+		cfg.handlerDomainsDelete(w, r, name)
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+	}
+}
+
+func (cfg *Config) handlerDomainsList(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireInstanceAdmin(w, r); !ok {
+		return
+	}
+
+	dbDomains, err := cfg.DB.ListCustomDomains(r.Context())
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve custom domains", err)
+		return
+	}
+
+	response := make([]types.CustomDomain, len(dbDomains))
+	for i, dbDomain := range dbDomains {
+		response[i] = handlers.BuildCustomDomainResponse(dbDomain)
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *Config) handlerDomainsCreate(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireInstanceAdmin(w, r); !ok {
+		return
+	}
+
+	var request types.CustomDomainRequest
+	if !handlers.DecodeJSON(w, r, &request) {
+		return
+	}
+	name, err := domains.Normalize(request.Domain)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	tenantID := tenant.DefaultID
+	if slug := strings.ToLower(strings.TrimSpace(request.Tenant)); slug != "" && slug != tenant.DefaultSlug {
+		dbTenant, err := cfg.DB.GetTenantBySlug(r.Context(), slug)
+		if err != nil {
+			if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+				handlers.RespondWithError(w, http.StatusBadRequest, "Unknown tenant", nil)
+			} else {
+				handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't add custom domain", err)
+			}
+			return
+		}
+		tenantID = dbTenant.ID
+	}
+
+	dbDomain, err := cfg.DB.CreateCustomDomain(r.Context(), database.CreateCustomDomainParams{
+		Domain:            name,
+		TenantID:          tenantID,
+		UserID:            uuid.NullUUID{},
+		VerificationToken: domains.NewToken(),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "custom_domains_pkey") {
+			handlers.RespondWithError(w, http.StatusConflict, "Domain is already in use", err)
+			return
+		}
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't add custom domain", err)
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusCreated, handlers.BuildCustomDomainResponse(dbDomain))
+}
+
+func (cfg *Config) handlerDomainsDelete(w http.ResponseWriter, r *http.Request, name string) {
+	if _, ok := cfg.requireInstanceAdmin(w, r); !ok {
+		return
+	}
+
+	deleted, err := cfg.DB.DeleteCustomDomain(r.Context(), strings.ToLower(name))
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't remove custom domain", err)
+		return
+	}
+	if deleted == 0 {
+		handlers.RespondWithError(w, http.StatusNotFound, "Custom domain not found", nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -3,18 +3,63 @@ package admin
 import (
 	"fmt"
 	"net/http"
-	"sync/atomic"
 
+	"github.com/kai-xlr/neo_chirpy/internal/backup"
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
+	"github.com/kai-xlr/neo_chirpy/internal/chaos"
+	"github.com/kai-xlr/neo_chirpy/internal/config"
 	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/jobs"
+	"github.com/kai-xlr/neo_chirpy/internal/logtail"
+	"github.com/kai-xlr/neo_chirpy/internal/mailer"
+	"github.com/kai-xlr/neo_chirpy/internal/profanity"
 	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
 	"github.com/kai-xlr/neo_chirpy/pkg/types"
 )
 
 // Config holds configuration needed for admin handlers
 type Config struct {
-	FileserverHits atomic.Int32
+	FileserverHits *cache.Counter
 	DB             *database.Queries
+	SQL            database.DBTX
 	Platform       string
+	JWTSecret      string
+	Templates      *mailer.Renderer
+	RuntimeConfig  *config.Config
+
+	// Backups is nil when BACKUP_DIR is unset
+	Backups *backup.Manager
+
+	// Chaos holds the fault injection rules; nil outside the dev environment
+	Chaos *chaos.Injector
+
+	// Logs keeps recent log lines for streaming; nil outside the dev and
+	// staging environments
+	Logs *logtail.Tail
+
+	// Done is closed on shutdown to end open log streams
+	Done <-chan struct{}
+
+	// Profanity is the chirp handlers' banned word filter, updated when the
+	// list changes
+	Profanity *profanity.Filter
+
+	// Store, Jobs and Mailer run bulk user provisioning and send its
+	// invitations
+	Store  cache.Store
+	Jobs   *jobs.Runner
+	Mailer mailer.Mailer
+	Events *events.Bus
+
+	// PublicURL and InstanceName go into invitation emails; without
+	// PublicURL invitations can't be sent
+	PublicURL    string
+	InstanceName string
+
+	// CustomDomains enables managing the domains routed to communities and
+	// profiles
+	CustomDomains bool
 }
 
 // HandlerMetrics handles GET /admin/metrics requests
@@ -22,13 +67,18 @@ func (cfg *Config) HandlerMetrics(w http.ResponseWriter, r *http.Request) {
 	if !handlers.RequireMethod(w, r, http.MethodGet) {
 		return
 	}
+	hits, err := cfg.FileserverHits.Value(r.Context())
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't load metrics", err)
+		return
+	}
 	w.Header().Set("Content-Type", types.ContentTypeTextHTML)
 	fmt.Fprintf(w, `<html>
   <body>
     <h1>Welcome, Chirpy Admin</h1>
     <p>Chirpy has been visited %d times!</p>
   </body>
-</html>`, cfg.FileserverHits.Load())
+</html>`, hits)
 }
 
 // HandlerReset handles POST /admin/reset requests
@@ -41,8 +91,13 @@ func (cfg *Config) HandlerReset(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("Reset is only allowed in dev environment."))
 		return
 	}
-	cfg.FileserverHits.Store(0)
-	err := cfg.DB.Reset(r.Context())
+	err := cfg.FileserverHits.Reset(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Failed to reset hits: " + err.Error()))
+		return
+	}
+	err = cfg.DB.Reset(r.Context())
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte("Failed to reset the database: " + err.Error()))
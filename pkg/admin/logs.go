@@ -0,0 +1,111 @@
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/logtail"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+)
+
+const (
+	// logStreamBuffer is how many lines a slow client may fall behind by
+	// before lines are dropped
+	logStreamBuffer = 256
+	// logStreamHeartbeat keeps idle connections open through proxies
+	logStreamHeartbeat = 30 * time.Second
+)
+
+// HandlerLogStream handles GET /admin/logs/stream requests. It sends the
+// recently kept log lines and then new ones as server-sent events, one JSON
+// entry per event. ?level= sets the lowest level sent (default info) and
+// ?module= limits lines to a comma-separated list of modules. Only available
+// in the dev and staging environments, where Logs is set.
+func (cfg *Config) HandlerLogStream(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+	if (cfg.Platform != "dev" && cfg.Platform != "staging") || cfg.Logs == nil {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("Log streaming is only allowed in dev and staging environments."))
+		return
+	}
+	if _, ok := cfg.requireInstanceAdmin(w, r); !ok {
+		return
+	}
+
+	filter := logtail.Filter{Level: r.URL.Query().Get("level")}
+	if filter.Level == "" {
+		filter.Level = logtail.LevelInfo
+	}
+	if !logtail.ValidLevel(filter.Level) {
+		handlers.RespondWithError(w, http.StatusBadRequest, "level must be info, warn or error", nil)
+		return
+	}
+	for _, module := range strings.Split(r.URL.Query().Get("module"), ",") {
+		if module = strings.TrimSpace(module); module != "" {
+			filter.Modules = append(filter.Modules, module)
+		}
+	}
+
+	// Subscribe before reading the backlog so no line falls in between;
+	// one written meanwhile may be sent twice
+	lines, unsubscribe := cfg.Logs.Subscribe(logStreamBuffer)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	controller := http.NewResponseController(w)
+
+	for _, entry := range cfg.Logs.Recent() {
+		if filter.Match(entry) {
+			if err := writeLogEvent(w, entry); err != nil {
+				return
+			}
+		}
+	}
+	if err := controller.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(logStreamHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-cfg.Done:
+			return
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case entry := <-lines:
+			if !filter.Match(entry) {
+				continue
+			}
+			if err := writeLogEvent(w, entry); err != nil {
+				return
+			}
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeLogEvent writes one log entry as a server-sent event
+func writeLogEvent(w io.Writer, entry logtail.Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: log\ndata: %s\n\n", data)
+	return err
+}
@@ -0,0 +1,256 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+const reportsPrefix = "/admin/reports/"
+
+// Audit log action for resolving the reports on a chirp
+const auditActionResolveReports = "report.resolve"
+
+const (
+	reportsDefaultLimit = 50
+	reportsMaxLimit     = 100
+)
+
+// parsePage reads the limit and offset that page through the moderation
+// queues, responding 400 when either is out of range
+func parsePage(w http.ResponseWriter, r *http.Request) (limit, offset int32, ok bool) {
+	limit = reportsDefaultLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > reportsMaxLimit {
+			handlers.RespondWithError(w, http.StatusBadRequest, "limit must be between 1 and 100", err)
+			return 0, 0, false
+		}
+		limit = int32(parsed)
+	}
+	if raw := r.URL.Query().Get("offset"); raw != "" {
+		parsed, err := strconv.ParseInt(raw, 10, 32)
+		if err != nil || parsed < 0 {
+			handlers.RespondWithError(w, http.StatusBadRequest, "offset must be a non-negative number", err)
+			return 0, 0, false
+		}
+		offset = int32(parsed)
+	}
+	return limit, offset, true
+}
+
+// HandlerReports handles GET /admin/reports, the moderation queue, and POST
+// /admin/reports/{id}/resolve requests
+func (cfg *Config) HandlerReports(w http.ResponseWriter, r *http.Request) {
+	idString, subresource := handlers.SplitResourcePath(r.URL.Path, reportsPrefix)
+	if idString == "" {
+		if !handlers.RequireMethod(w, r, http.MethodGet) {
+			return
+		}
+		cfg.handlerReportsList(w, r)
+		return
+	}
+
+	reportID, err := uuid.Parse(idString)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid report ID", err)
+		return
+	}
+
+	switch subresource {
+	case "resolve":
+		if !handlers.RequireMethod(w, r, http.MethodPost) {
+			return
+		}
+		cfg.handlerReportResolve(w, r, reportID)
+	default:
+		handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+	}
+}
+
+// handlerReportsList lists open reports oldest first, or resolved ones with
+// ?status=resolved, paged with limit and offset
+func (cfg *Config) handlerReportsList(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireModerator(w, r); !ok {
+		return
+	}
+
+	var open bool
+	switch r.URL.Query().Get("status") {
+	case "", "open":
+		open = true
+	case "resolved":
+		open = false
+	default:
+		handlers.RespondWithError(w, http.StatusBadRequest, "status must be open or resolved", nil)
+		return
+	}
+
+	limit, offset, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := cfg.DB.GetReports(r.Context(), database.GetReportsParams{
+		TenantID:   tenant.FromContext(r.Context()).ID,
+		Open:       open,
+		PageSize:   limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve reports", err)
+		return
+	}
+
+	response := make([]types.Report, len(rows))
+	for i, row := range rows {
+		response[i] = buildReportResponse(database.Report{
+			ID:         row.ID,
+			CreatedAt:  row.CreatedAt,
+			TenantID:   row.TenantID,
+			ChirpID:    row.ChirpID,
+			ReporterID: row.ReporterID,
+			Reason:     row.Reason,
+			Details:    row.Details,
+			ResolvedAt: row.ResolvedAt,
+			ResolvedBy: row.ResolvedBy,
+			Resolution: row.Resolution,
+		})
+		response[i].ChirpBody = row.ChirpBody
+		response[i].ChirpAuthorID = row.ChirpAuthorID
+		response[i].ChirpReportCount = row.ChirpReportCount
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// handlerReportResolve acts on a reported chirp and resolves every open
+// report on it: dismissed leaves the chirp alone, locked locks it and
+// removed deletes it for good. Every resolution is recorded in the audit
+// log. Removed chirps can't be restored by their author.
+func (cfg *Config) handlerReportResolve(w http.ResponseWriter, r *http.Request, reportID uuid.UUID) {
+	actorID, ok := cfg.requireModerator(w, r)
+	if !ok {
+		return
+	}
+
+	var request types.ReportResolveRequest
+	if !handlers.DecodeJSON(w, r, &request) {
+		return
+	}
+
+	tenantID := tenant.FromContext(r.Context()).ID
+	report, err := cfg.DB.GetReport(r.Context(), database.GetReportParams{
+		ID:       reportID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusNotFound, "Report not found", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve report", err)
+		}
+		return
+	}
+	if report.ResolvedAt.Valid {
+		handlers.RespondWithError(w, http.StatusConflict, "Report already resolved", nil)
+		return
+	}
+
+	var chirp database.Chirp
+	switch request.Resolution {
+	case types.ReportLocked:
+		_, err = cfg.DB.SetChirpLocked(r.Context(), database.SetChirpLockedParams{
+			ID:       report.ChirpID,
+			Locked:   true,
+			ActorID:  actorID,
+			Action:   auditActionLock,
+			TenantID: tenantID,
+		})
+		if err != nil {
+			if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+				handlers.RespondWithError(w, http.StatusConflict, "The chirp was deleted; dismiss the report instead", nil)
+			} else {
+				handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+			}
+			return
+		}
+	case types.ReportRemoved:
+		chirp, err = cfg.DB.GetChirpByID(r.Context(), report.ChirpID)
+		if err != nil {
+			if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+				handlers.RespondWithError(w, http.StatusConflict, "The chirp was deleted; dismiss the report instead", nil)
+			} else {
+				handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirp, err)
+			}
+			return
+		}
+		if err := cfg.DB.SoftDeleteChirp(r.Context(), chirp.ID); err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't delete chirp", err)
+			return
+		}
+	}
+
+	resolved, err := cfg.DB.ResolveReports(r.Context(), database.ResolveReportsParams{
+		ActorID:    actorID,
+		Resolution: request.Resolution,
+		ChirpID:    report.ChirpID,
+		TenantID:   tenantID,
+		Action:     auditActionResolveReports,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't resolve reports", err)
+		return
+	}
+	if len(resolved) == 0 {
+		// Another moderator got there first
+		handlers.RespondWithError(w, http.StatusConflict, "Report already resolved", nil)
+		return
+	}
+
+	if request.Resolution == types.ReportRemoved {
+		cfg.Events.Publish(events.Event{
+			Type:    events.ChirpDeleted,
+			UserID:  chirp.UserID,
+			ChirpID: chirp.ID,
+		})
+		cfg.Events.Publish(events.Event{
+			Type:     events.ChirpRemoved,
+			TenantID: tenantID,
+			UserID:   chirp.UserID,
+			ChirpID:  chirp.ID,
+		})
+	}
+
+	response := make([]types.Report, len(resolved))
+	for i, row := range resolved {
+		response[i] = buildReportResponse(database.Report(row))
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// buildReportResponse converts a report without its chirp's details
+func buildReportResponse(report database.Report) types.Report {
+	response := types.Report{
+		ID:         report.ID,
+		CreatedAt:  types.NewTimestamp(report.CreatedAt),
+		ChirpID:    report.ChirpID,
+		ReporterID: report.ReporterID,
+		Reason:     report.Reason,
+		Details:    report.Details,
+		Resolution: report.Resolution.String,
+	}
+	if report.ResolvedAt.Valid {
+		resolvedAt := types.NewTimestamp(report.ResolvedAt.Time)
+		response.ResolvedAt = &resolvedAt
+	}
+	if report.ResolvedBy.Valid {
+		response.ResolvedBy = &report.ResolvedBy.UUID
+	}
+	return response
+}
@@ -0,0 +1,53 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/kai-xlr/neo_chirpy/internal/mailer"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+const templatePreviewPrefix = "/admin/templates/preview/"
+
+// HandlerTemplatePreview handles GET /admin/templates/preview/{name} requests.
+// It renders an email template with its sample data so that copy and layout
+// can be checked in a browser. Only available in the dev environment.
+func (cfg *Config) HandlerTemplatePreview(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+	if cfg.Platform != "dev" {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("Template preview is only allowed in dev environment."))
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, templatePreviewPrefix)
+	sample, err := cfg.Templates.Sample(name)
+	if errors.Is(err, mailer.ErrTemplateNotFound) {
+		handlers.RespondWithError(w, http.StatusNotFound, "Template not found", err)
+		return
+	}
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't load template sample", err)
+		return
+	}
+
+	msg, err := cfg.Templates.Render(name, r.URL.Query().Get("locale"), []string{"preview@example.com"}, sample)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't render template", err)
+		return
+	}
+
+	w.Header().Set("X-Email-Subject", msg.Subject)
+	if r.URL.Query().Get("format") == "text" {
+		w.Header().Set("Content-Type", types.ContentTypeTextPlain)
+		w.Write([]byte(msg.TextBody))
+		return
+	}
+	w.Header().Set("Content-Type", types.ContentTypeTextHTML)
+	w.Write([]byte(msg.HTMLBody))
+}
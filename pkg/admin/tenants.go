@@ -0,0 +1,105 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// maxTenantSlugLength keeps slugs usable as a single DNS label
+const maxTenantSlugLength = 63
+
+var errInvalidTenantSlug = errors.New("Slug must be 1-63 lowercase letters, digits or hyphens, not starting or ending with a hyphen")
+
+// HandlerTenants handles GET and POST /admin/tenants requests, listing and
+// creating the communities hosted by this deployment. Only admins of the
+// default community can manage tenants.
+func (cfg *Config) HandlerTenants(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		cfg.handlerTenantsList(w, r)
+	case http.MethodPost:
+		cfg.handlerTenantsCreate(w, r)
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+	}
+}
+
+func (cfg *Config) handlerTenantsList(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireInstanceAdmin(w, r); !ok {
+		return
+	}
+
+	dbTenants, err := cfg.DB.ListTenants(r.Context())
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve tenants", err)
+		return
+	}
+
+	response := make([]types.Tenant, len(dbTenants))
+	for i, dbTenant := range dbTenants {
+		response[i] = buildTenantResponse(dbTenant)
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *Config) handlerTenantsCreate(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireInstanceAdmin(w, r); !ok {
+		return
+	}
+
+	var request types.TenantRequest
+	if !handlers.DecodeJSON(w, r, &request) {
+		return
+	}
+
+	slug := strings.ToLower(strings.TrimSpace(request.Slug))
+	if err := validateTenantSlug(slug); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	name := strings.TrimSpace(request.Name)
+
+	dbTenant, err := cfg.DB.CreateTenant(r.Context(), database.CreateTenantParams{
+		Slug:        slug,
+		Name:        name,
+		Description: strings.TrimSpace(request.Description),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "tenants_slug_key") {
+			handlers.RespondWithError(w, http.StatusConflict, "Slug is already taken", err)
+			return
+		}
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't create tenant", err)
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusCreated, buildTenantResponse(dbTenant))
+}
+
+// validateTenantSlug checks a slug can be used as a subdomain
+func validateTenantSlug(slug string) error {
+	if slug == "" || len(slug) > maxTenantSlugLength || slug[0] == '-' || slug[len(slug)-1] == '-' {
+		return errInvalidTenantSlug
+	}
+	for _, c := range slug {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return errInvalidTenantSlug
+		}
+	}
+	return nil
+}
+
+func buildTenantResponse(dbTenant database.Tenant) types.Tenant {
+	return types.Tenant{
+		ID:          dbTenant.ID,
+		CreatedAt:   types.NewTimestamp(dbTenant.CreatedAt),
+		Slug:        dbTenant.Slug,
+		Name:        dbTenant.Name,
+		Description: dbTenant.Description,
+	}
+}
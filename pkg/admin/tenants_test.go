@@ -0,0 +1,28 @@
+package admin
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateTenantSlug(t *testing.T) {
+	tests := []struct {
+		slug    string
+		wantErr bool
+	}{
+		{slug: "birds", wantErr: false},
+		{slug: "bird-watchers-2", wantErr: false},
+		{slug: "", wantErr: true},
+		{slug: "-birds", wantErr: true},
+		{slug: "birds-", wantErr: true},
+		{slug: "bird.watchers", wantErr: true},
+		{slug: "Birds", wantErr: true},
+		{slug: strings.Repeat("a", maxTenantSlugLength+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := validateTenantSlug(tt.slug); (err != nil) != tt.wantErr {
+			t.Errorf("validateTenantSlug(%q) error = %v, wantErr %v", tt.slug, err, tt.wantErr)
+		}
+	}
+}
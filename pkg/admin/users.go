@@ -0,0 +1,193 @@
+package admin
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+const usersPrefix = "/admin/users/"
+
+// Audit log actions
+const (
+	auditActionVerify           = "user.verify"
+	auditActionUnverify         = "user.unverify"
+	auditActionLegalHold        = "user.legal_hold"
+	auditActionLegalHoldRelease = "user.legal_hold_release"
+)
+
+// HandlerUsers handles /admin/users/{id}/{action} requests and the
+// /admin/users/bulk provisioning jobs
+func (cfg *Config) HandlerUsers(w http.ResponseWriter, r *http.Request) {
+	idString, subresource := handlers.SplitResourcePath(r.URL.Path, usersPrefix)
+	if idString == "bulk" {
+		cfg.handlerUsersBulk(w, r, subresource)
+		return
+	}
+	userID, err := uuid.Parse(idString)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	switch subresource {
+	case "verify":
+		cfg.handlerUserVerify(w, r, userID)
+	case "legal-hold":
+		cfg.handlerUserLegalHold(w, r, userID)
+	default:
+		handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+	}
+}
+
+// handlerUserVerify handles POST (grant) and DELETE (revoke) on
+// /admin/users/{id}/verify. Only admins may change the verified badge, and
+// every change is recorded in the audit log.
+func (cfg *Config) handlerUserVerify(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	var verified bool
+	var action string
+	switch r.Method {
+	case http.MethodPost:
+		verified, action = true, auditActionVerify
+	case http.MethodDelete:
+		verified, action = false, auditActionUnverify
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+		return
+	}
+
+	actorID, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := cfg.DB.SetUserVerified(r.Context(), database.SetUserVerifiedParams{
+		ID:       userID,
+		Verified: verified,
+		ActorID:  actorID,
+		Action:   action,
+		TenantID: tenant.FromContext(r.Context()).ID,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusNotFound, "User not found", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update user", err)
+		}
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, buildAdminUserResponse(database.User(user)))
+}
+
+// handlerUserLegalHold handles POST (place) and DELETE (release) on
+// /admin/users/{id}/legal-hold. While on hold, the user's data is exempt
+// from the deactivation purge and retention policies and their chirps can't
+// be deleted. Every change is recorded in the audit log.
+func (cfg *Config) handlerUserLegalHold(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	var legalHold bool
+	var action string
+	switch r.Method {
+	case http.MethodPost:
+		legalHold, action = true, auditActionLegalHold
+	case http.MethodDelete:
+		legalHold, action = false, auditActionLegalHoldRelease
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+		return
+	}
+
+	actorID, ok := cfg.requireAdmin(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := cfg.DB.SetUserLegalHold(r.Context(), database.SetUserLegalHoldParams{
+		ID:        userID,
+		LegalHold: legalHold,
+		ActorID:   actorID,
+		Action:    action,
+		TenantID:  tenant.FromContext(r.Context()).ID,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusNotFound, "User not found", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update user", err)
+		}
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, buildAdminUserResponse(database.User(user)))
+}
+
+// buildAdminUserResponse converts a user for admin responses, which also
+// report the legal hold
+func buildAdminUserResponse(user database.User) types.UserResponse {
+	return types.UserResponse{
+		User: types.User{
+			ID:          user.ID,
+			CreatedAt:   types.NewTimestamp(user.CreatedAt),
+			UpdatedAt:   types.NewTimestamp(user.UpdatedAt),
+			Email:       user.Email,
+			Username:    user.Username.String,
+			IsChirpyRed: user.IsChirpyRed,
+			Verified:    user.Verified,
+			LegalHold:   user.LegalHold,
+			DisplayName: user.DisplayName,
+			Bio:         user.Bio,
+			Location:    user.Location,
+			Website:     user.Website,
+			AvatarURL:   user.AvatarUrl,
+		},
+	}
+}
+
+// requireAdmin authenticates the request and checks the caller has the admin
+// role, writing an error response and returning false otherwise
+func (cfg *Config) requireAdmin(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	return cfg.requireRole(w, r, "Admin role required", types.RoleAdmin)
+}
+
+// requireModerator is requireAdmin for actions moderators may also take
+func (cfg *Config) requireModerator(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	return cfg.requireRole(w, r, "Moderator role required", types.RoleModerator, types.RoleAdmin)
+}
+
+// requireRole authenticates the request and checks the caller has one of
+// the given roles, responding with forbidden otherwise
+func (cfg *Config) requireRole(w http.ResponseWriter, r *http.Request, forbidden string, roles ...string) (uuid.UUID, bool) {
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return uuid.Nil, false
+	}
+
+	role, err := principal.Role(r.Context(), cfg.DB.GetUserRole)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't check permissions", err)
+		return uuid.Nil, false
+	}
+	if !slices.Contains(roles, role) {
+		handlers.RespondWithError(w, http.StatusForbidden, forbidden, nil)
+		return uuid.Nil, false
+	}
+
+	return principal.UserID, true
+}
+
+// requireInstanceAdmin is requireAdmin for instance-wide operations, which
+// are only available to admins of the default community
+func (cfg *Config) requireInstanceAdmin(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	if !tenant.FromContext(r.Context()).IsDefault() {
+		handlers.RespondWithError(w, http.StatusForbidden, "Only available to admins of the default community", nil)
+		return uuid.Nil, false
+	}
+	return cfg.requireAdmin(w, r)
+}
@@ -0,0 +1,186 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/moderation"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+const verdictsPrefix = "/admin/verdicts/"
+
+// Audit log action for reviewing a classifier verdict
+const auditActionReviewVerdict = "verdict.review"
+
+// HandlerVerdicts handles GET /admin/verdicts, the queue of classifier
+// verdicts awaiting review, and POST /admin/verdicts/{id}/review requests
+func (cfg *Config) HandlerVerdicts(w http.ResponseWriter, r *http.Request) {
+	idString, subresource := handlers.SplitResourcePath(r.URL.Path, verdictsPrefix)
+	if idString == "" {
+		if !handlers.RequireMethod(w, r, http.MethodGet) {
+			return
+		}
+		cfg.handlerVerdictsList(w, r)
+		return
+	}
+
+	verdictID, err := uuid.Parse(idString)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid verdict ID", err)
+		return
+	}
+
+	switch subresource {
+	case "review":
+		if !handlers.RequireMethod(w, r, http.MethodPost) {
+			return
+		}
+		cfg.handlerVerdictReview(w, r, verdictID)
+	default:
+		handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+	}
+}
+
+// handlerVerdictsList lists unreviewed verdicts oldest first, or reviewed
+// ones with ?status=reviewed, paged with limit and offset
+func (cfg *Config) handlerVerdictsList(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireModerator(w, r); !ok {
+		return
+	}
+
+	var open bool
+	switch r.URL.Query().Get("status") {
+	case "", "open":
+		open = true
+	case "reviewed":
+		open = false
+	default:
+		handlers.RespondWithError(w, http.StatusBadRequest, "status must be open or reviewed", nil)
+		return
+	}
+
+	limit, offset, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	rows, err := cfg.DB.GetModerationVerdicts(r.Context(), database.GetModerationVerdictsParams{
+		TenantID:   tenant.FromContext(r.Context()).ID,
+		Open:       open,
+		PageSize:   limit,
+		PageOffset: offset,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve verdicts", err)
+		return
+	}
+
+	response := make([]types.ModerationVerdict, len(rows))
+	for i, row := range rows {
+		response[i] = buildVerdictResponse(database.ModerationVerdict{
+			ID:         row.ID,
+			CreatedAt:  row.CreatedAt,
+			TenantID:   row.TenantID,
+			ChirpID:    row.ChirpID,
+			Verdict:    row.Verdict,
+			Label:      row.Label,
+			Score:      row.Score,
+			ReviewedAt: row.ReviewedAt,
+			ReviewedBy: row.ReviewedBy,
+			Outcome:    row.Outcome,
+		})
+		response[i].ChirpBody = row.ChirpBody
+		response[i].ChirpAuthorID = row.ChirpAuthorID
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// handlerVerdictReview records whether a moderator agrees with a verdict.
+// Overturning a remove verdict shows the hidden chirp again; upholding it
+// leaves the chirp to the purge job. Every review is recorded in the audit
+// log.
+func (cfg *Config) handlerVerdictReview(w http.ResponseWriter, r *http.Request, verdictID uuid.UUID) {
+	actorID, ok := cfg.requireModerator(w, r)
+	if !ok {
+		return
+	}
+
+	var request types.VerdictReviewRequest
+	if !handlers.DecodeJSON(w, r, &request) {
+		return
+	}
+
+	tenantID := tenant.FromContext(r.Context()).ID
+	verdict, err := cfg.DB.GetModerationVerdict(r.Context(), database.GetModerationVerdictParams{
+		ID:       verdictID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusNotFound, "Verdict not found", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve verdict", err)
+		}
+		return
+	}
+
+	reviewed, err := cfg.DB.ReviewModerationVerdict(r.Context(), database.ReviewModerationVerdictParams{
+		ActorID:  actorID,
+		Outcome:  request.Outcome,
+		ID:       verdict.ID,
+		TenantID: tenantID,
+		Action:   auditActionReviewVerdict,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			// Another moderator got there first
+			handlers.RespondWithError(w, http.StatusConflict, "Verdict already reviewed", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't review verdict", err)
+		}
+		return
+	}
+
+	if reviewed.Verdict == moderation.Remove && request.Outcome == types.VerdictOverturned {
+		authorID, err := cfg.DB.UnhideChirp(r.Context(), reviewed.ChirpID)
+		switch {
+		case err == nil:
+			cfg.Events.Publish(events.Event{
+				Type:    events.ChirpRestored,
+				UserID:  authorID,
+				ChirpID: reviewed.ChirpID,
+			})
+		case err.Error() != "no rows in result set" && err.Error() != "sql: no rows in result set":
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't restore chirp", err)
+			return
+		}
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, buildVerdictResponse(database.ModerationVerdict(reviewed)))
+}
+
+// buildVerdictResponse converts a verdict without its chirp's details
+func buildVerdictResponse(verdict database.ModerationVerdict) types.ModerationVerdict {
+	response := types.ModerationVerdict{
+		ID:        verdict.ID,
+		CreatedAt: types.NewTimestamp(verdict.CreatedAt),
+		ChirpID:   verdict.ChirpID,
+		Verdict:   verdict.Verdict,
+		Label:     verdict.Label,
+		Score:     verdict.Score,
+		Outcome:   verdict.Outcome.String,
+	}
+	if verdict.ReviewedAt.Valid {
+		reviewedAt := types.NewTimestamp(verdict.ReviewedAt.Time)
+		response.ReviewedAt = &reviewedAt
+	}
+	if verdict.ReviewedBy.Valid {
+		response.ReviewedBy = &verdict.ReviewedBy.UUID
+	}
+	return response
+}
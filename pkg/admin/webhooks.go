@@ -0,0 +1,113 @@
+package admin
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// HandlerWebhooks handles /admin/webhooks requests: GET lists the
+// community's moderation webhooks, POST registers a new one and DELETE
+// /admin/webhooks/{id} removes one
+func (cfg *Config) HandlerWebhooks(w http.ResponseWriter, r *http.Request) {
+	id := strings.Trim(handlers.ExtractIDFromPath(r.URL.Path, "/admin/webhooks"), "/")
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		cfg.handlerWebhooksList(w, r)
+	case id == "" && r.Method == http.MethodPost:
+		cfg.handlerWebhooksCreate(w, r)
+	case id != "" && r.Method == http.MethodDelete:
+		cfg.handlerWebhooksDelete(w, r, id)
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+	}
+}
+
+func (cfg *Config) handlerWebhooksList(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireAdmin(w, r); !ok {
+		return
+	}
+
+	hooks, err := cfg.DB.ListModerationWebhooks(r.Context(), tenant.FromContext(r.Context()).ID)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve webhooks", err)
+		return
+	}
+
+	response := make([]types.ModerationWebhook, len(hooks))
+	for i, hook := range hooks {
+		response[i] = buildWebhookResponse(hook)
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *Config) handlerWebhooksCreate(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.requireAdmin(w, r); !ok {
+		return
+	}
+
+	var request types.ModerationWebhookRequest
+	if !handlers.DecodeJSON(w, r, &request) {
+		return
+	}
+
+	secret, err := auth.MakeRefreshToken()
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't generate webhook secret", err)
+		return
+	}
+	hook, err := cfg.DB.CreateModerationWebhook(r.Context(), database.CreateModerationWebhookParams{
+		TenantID: tenant.FromContext(r.Context()).ID,
+		Url:      request.URL,
+		Secret:   secret,
+		Events:   request.Events,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't create webhook", err)
+		return
+	}
+
+	response := buildWebhookResponse(hook)
+	response.Secret = secret
+	handlers.RespondWithJSON(w, http.StatusCreated, response)
+}
+
+func (cfg *Config) handlerWebhooksDelete(w http.ResponseWriter, r *http.Request, id string) {
+	if _, ok := cfg.requireAdmin(w, r); !ok {
+		return
+	}
+
+	hookID, err := uuid.Parse(id)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid webhook ID", err)
+		return
+	}
+	deleted, err := cfg.DB.DeleteModerationWebhook(r.Context(), database.DeleteModerationWebhookParams{
+		ID:       hookID,
+		TenantID: tenant.FromContext(r.Context()).ID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't delete webhook", err)
+		return
+	}
+	if deleted == 0 {
+		handlers.RespondWithError(w, http.StatusNotFound, "Webhook not found", nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func buildWebhookResponse(hook database.ModerationWebhook) types.ModerationWebhook {
+	return types.ModerationWebhook{
+		ID:        hook.ID,
+		URL:       hook.Url,
+		Events:    hook.Events,
+		CreatedAt: types.NewTimestamp(hook.CreatedAt),
+	}
+}
@@ -0,0 +1,117 @@
+// Package bootstrap serves everything a client needs to render its first
+// screen in a single response.
+package bootstrap
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/chirp"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/user"
+)
+
+// feedPageSize is how many chirps the bootstrap feed holds
+const feedPageSize = 20
+
+// Config holds configuration needed for the bootstrap handler
+type Config struct {
+	DB        *database.Queries
+	JWTSecret string
+	Users     *user.Config
+	Chirps    *chirp.Config
+}
+
+// HandlerBootstrap handles GET /api/bootstrap requests, returning the user's
+// profile, preferences, pending co-author invite count and the first page of
+// their feed. The parts are loaded concurrently.
+func (cfg *Config) HandlerBootstrap(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	ctx := r.Context()
+	var (
+		response types.Bootstrap
+		dbUser   database.User
+	)
+	err = gather(
+		func() (err error) {
+			dbUser, err = cfg.DB.GetUserByID(ctx, database.GetUserByIDParams{
+				TenantID: tenant.FromContext(ctx).ID,
+				ID:       userID,
+			})
+			return err
+		},
+		func() (err error) {
+			response.Preferences, err = cfg.Users.Preferences(ctx, userID)
+			return err
+		},
+		func() (err error) {
+			response.PendingCoauthorInvites, err = cfg.DB.CountPendingCoauthorInvites(ctx, userID)
+			return err
+		},
+		func() (err error) {
+			response.Feed, err = cfg.Chirps.LatestChirps(ctx, userID, feedPageSize)
+			return err
+		},
+	)
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusNotFound, "User not found", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't load bootstrap data", err)
+		}
+		return
+	}
+
+	response.User = types.User{
+		ID:          dbUser.ID,
+		CreatedAt:   types.NewTimestamp(dbUser.CreatedAt),
+		UpdatedAt:   types.NewTimestamp(dbUser.UpdatedAt),
+		Email:       dbUser.Email,
+		Username:    dbUser.Username.String,
+		IsChirpyRed: dbUser.IsChirpyRed,
+		Verified:    dbUser.Verified,
+		DisplayName: dbUser.DisplayName,
+		Bio:         dbUser.Bio,
+		Location:    dbUser.Location,
+		Website:     dbUser.Website,
+		AvatarURL:   dbUser.AvatarUrl,
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// gather runs fns concurrently and waits for all of them, returning the
+// first error in argument order
+func gather(fns ...func() error) error {
+	errs := make([]error, len(fns))
+	var wg sync.WaitGroup
+	for i, fn := range fns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = fn()
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package bootstrap
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGather(t *testing.T) {
+	var ran atomic.Int32
+	errFirst, errSecond := errors.New("first"), errors.New("second")
+
+	err := gather(
+		func() error { ran.Add(1); return nil },
+		func() error { ran.Add(1); return errFirst },
+		func() error { ran.Add(1); return errSecond },
+	)
+	if err != errFirst {
+		t.Errorf("gather() error = %v, want %v", err, errFirst)
+	}
+	if ran.Load() != 3 {
+		t.Errorf("gather() ran %d functions, want 3", ran.Load())
+	}
+
+	if err := gather(func() error { return nil }); err != nil {
+		t.Errorf("gather() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,66 @@
+package chirp
+
+import (
+	"net/http"
+
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// HandlerAppeals handles /api/appeals requests. POST appeals the removal of
+// one of the user's chirps, by a moderator resolving its reports or by the
+// classifier, and GET lists the user's appeals, newest first.
+func (cfg *Config) HandlerAppeals(w http.ResponseWriter, r *http.Request) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+	tenantID := tenant.FromContext(r.Context()).ID
+
+	switch r.Method {
+	case http.MethodGet:
+		appeals, err := cfg.DB.GetUserAppeals(r.Context(), database.GetUserAppealsParams{
+			UserID:   userID,
+			TenantID: tenantID,
+		})
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve appeals", err)
+			return
+		}
+		response := make([]types.Appeal, len(appeals))
+		for i, appeal := range appeals {
+			response[i] = handlers.BuildAppealResponse(appeal)
+		}
+		handlers.RespondWithJSON(w, http.StatusOK, response)
+	case http.MethodPost:
+		var request types.AppealRequest
+		if !handlers.DecodeJSON(w, r, &request) {
+			return
+		}
+		appeal, err := cfg.DB.CreateAppeal(r.Context(), database.CreateAppealParams{
+			Reason:   request.Reason,
+			ChirpID:  request.ChirpID,
+			UserID:   userID,
+			TenantID: tenantID,
+		})
+		if err != nil {
+			if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+				// Not the user's chirp, not removed by moderation, or
+				// already appealed
+				handlers.RespondWithError(w, http.StatusConflict, "The chirp can't be appealed", nil)
+			} else {
+				handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't create appeal", err)
+			}
+			return
+		}
+		handlers.RespondWithJSON(w, http.StatusCreated, handlers.BuildAppealResponse(appeal))
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+	}
+}
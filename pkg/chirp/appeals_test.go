@@ -0,0 +1,60 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestHandlerAppeals(t *testing.T) {
+	cfg := newBenchConfig(0)
+	chirpID := uuid.NewString()
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		body   string
+		want   int
+	}{
+		{name: "removed chirp", userID: benchUserID, body: `{"chirp_id":"` + chirpID + `","reason":"It was a joke between friends"}`, want: http.StatusCreated},
+		{name: "missing reason", userID: benchUserID, body: `{"chirp_id":"` + chirpID + `","reason":"  "}`, want: http.StatusBadRequest},
+		{name: "missing chirp", userID: benchUserID, body: `{"reason":"It was a joke"}`, want: http.StatusBadRequest},
+		{name: "not removed", userID: uuid.New(), body: `{"chirp_id":"` + chirpID + `","reason":"It was a joke"}`, want: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := auth.MakeJWT(tt.userID, benchSecret, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/api/appeals", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			cfg.HandlerAppeals(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want != http.StatusCreated {
+				return
+			}
+
+			var appeal types.Appeal
+			if err := json.Unmarshal(rec.Body.Bytes(), &appeal); err != nil {
+				t.Fatal(err)
+			}
+			if appeal.ChirpID.String() != chirpID || appeal.UserID != benchUserID || appeal.Reason != "It was a joke between friends" {
+				t.Errorf("appeal = %+v", appeal)
+			}
+			if appeal.DecidedAt != nil || appeal.Decision != "" {
+				t.Error("new appeal is already decided")
+			}
+		})
+	}
+}
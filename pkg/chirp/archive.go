@@ -0,0 +1,105 @@
+package chirp
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// archiveBatchSize bounds how many chirps a single archive statement moves,
+// keeping each transaction short while the job drains the backlog
+const archiveBatchSize = 500
+
+// ArchiveOldChirps moves chirps older than ArchiveAfterMonths, with their
+// media and revisions, out of the hot chirps table into the archive tables.
+// Archived chirps no longer appear in listings but stay reachable by ID.
+func (cfg *Config) ArchiveOldChirps(ctx context.Context) error {
+	if cfg.ArchiveAfterMonths <= 0 {
+		return nil
+	}
+
+	cutoff := time.Now().UTC().AddDate(0, -cfg.ArchiveAfterMonths, 0)
+	var total int64
+	for ctx.Err() == nil {
+		moved, err := cfg.DB.ArchiveChirps(ctx, database.ArchiveChirpsParams{
+			Cutoff:    cutoff,
+			BatchSize: archiveBatchSize,
+		})
+		if err != nil {
+			return err
+		}
+		total += moved
+		if moved < archiveBatchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("Archived %d chirps created before %s", total, cutoff.Format(time.DateOnly))
+	}
+	return nil
+}
+
+// getChirp looks a chirp up in the live table and falls back to the archive,
+// so permalinks keep working after a chirp has been archived. The second
+// return value reports whether the chirp came from the archive. Chirps of
+// other tenants are reported as not found.
+func (cfg *Config) getChirp(ctx context.Context, chirpID uuid.UUID) (database.Chirp, bool, error) {
+	archived := false
+	dbChirp, err := cfg.DB.GetChirpByID(ctx, chirpID)
+	if err != nil {
+		if err.Error() != "no rows in result set" && err.Error() != "sql: no rows in result set" {
+			return database.Chirp{}, false, err
+		}
+
+		archivedChirp, err := cfg.DB.GetArchivedChirpByID(ctx, chirpID)
+		if err != nil {
+			return database.Chirp{}, false, err
+		}
+		dbChirp, archived = database.Chirp(archivedChirp), true
+	}
+
+	if dbChirp.TenantID != tenant.FromContext(ctx).ID {
+		return database.Chirp{}, false, sql.ErrNoRows
+	}
+	return dbChirp, archived, nil
+}
+
+// attachArchivedMedia loads media for a single archived chirp response
+func (cfg *Config) attachArchivedMedia(ctx context.Context, chirp *types.ChirpCreateResponse) error {
+	dbMedia, err := cfg.DB.GetArchivedMediaForChirp(ctx, chirp.ID)
+	if err != nil {
+		return err
+	}
+
+	media := make([]database.ChirpMedium, len(dbMedia))
+	for i, medium := range dbMedia {
+		media[i] = database.ChirpMedium(medium)
+	}
+	chirp.Media = handlers.BuildMediaResponse(media)
+	return nil
+}
+
+// getRevisions returns a chirp's previous versions from the live or archive table
+func (cfg *Config) getRevisions(ctx context.Context, chirpID uuid.UUID, archived bool) ([]database.ChirpRevision, error) {
+	if !archived {
+		return cfg.DB.GetChirpRevisions(ctx, chirpID)
+	}
+
+	dbRevisions, err := cfg.DB.GetArchivedChirpRevisions(ctx, chirpID)
+	if err != nil {
+		return nil, err
+	}
+	revisions := make([]database.ChirpRevision, len(dbRevisions))
+	for i, revision := range dbRevisions {
+		revisions[i] = database.ChirpRevision(revision)
+	}
+	return revisions, nil
+}
@@ -0,0 +1,56 @@
+package chirp
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/dataloader"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// attachAuthors loads the public author profile for the given chirp responses
+// through the request's author loader, so each author is fetched at most once
+func (cfg *Config) attachAuthors(ctx context.Context, chirps []types.ChirpCreateResponse) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+
+	userIDs := make([]uuid.UUID, len(chirps))
+	for i, chirp := range chirps {
+		userIDs[i] = chirp.UserID
+	}
+
+	authors, err := cfg.authorLoader(ctx).LoadMany(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+	for i := range chirps {
+		chirps[i].Author = authors[chirps[i].UserID]
+	}
+	return nil
+}
+
+// authorLoader returns the request's loader for chirp authors by user ID
+func (cfg *Config) authorLoader(ctx context.Context) *dataloader.Loader[uuid.UUID, *types.ChirpAuthor] {
+	return dataloader.For(ctx, "chirp.authors", func(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]*types.ChirpAuthor, error) {
+		dbAuthors, err := cfg.DB.GetChirpAuthors(ctx, userIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		authors := make(map[uuid.UUID]*types.ChirpAuthor, len(dbAuthors))
+		for _, author := range dbAuthors {
+			authors[author.ID] = &types.ChirpAuthor{
+				ID:          author.ID,
+				Username:    author.Username.String,
+				Verified:    author.Verified,
+				DisplayName: author.DisplayName,
+				Bio:         author.Bio,
+				Location:    author.Location,
+				Website:     author.Website,
+				AvatarURL:   author.AvatarUrl,
+			}
+		}
+		return authors, nil
+	})
+}
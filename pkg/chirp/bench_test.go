@@ -0,0 +1,587 @@
+package chirp
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+)
+
+// Benchmarks run the real handlers against an in-memory database/sql driver
+// that returns canned rows, so they measure decoding, validation, response
+// building and encoding without a Postgres round trip.
+
+const benchSecret = "bench-secret"
+
+var benchUserID = uuid.MustParse("5b4f8e8a-6a7e-4d5c-9a3b-2f1e0d9c8b7a")
+
+// benchScheduleStart is when the first scheduled chirp is published
+var benchScheduleStart = time.Now().UTC().Truncate(24 * time.Hour).Add(26 * time.Hour)
+
+// benchExportStart is when the first exported chirp was created
+var benchExportStart = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// benchUserCreatedAt is when benchUserID registered, an hour before the
+// tests started
+var benchUserCreatedAt = time.Now().UTC().Add(-time.Hour)
+
+func BenchmarkHandlerCreate(b *testing.B) {
+	cfg := newBenchConfig(0)
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		b.Fatal(err)
+	}
+	body := `{"body":"I had something interesting for breakfast, a kerfuffle of eggs"}`
+
+	b.ReportAllocs()
+	for b.Loop() {
+		req := httptest.NewRequest(http.MethodPost, "/api/chirps", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		cfg.HandlerCreate(rec, req)
+		if rec.Code != http.StatusCreated {
+			b.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+		}
+	}
+}
+
+func BenchmarkHandlerGet(b *testing.B) {
+	for _, size := range []int{10, 100, 1000} {
+		b.Run(fmt.Sprintf("chirps=%d", size), func(b *testing.B) {
+			cfg := newBenchConfig(size)
+
+			b.ReportAllocs()
+			for b.Loop() {
+				req := httptest.NewRequest(http.MethodGet, "/api/chirps?sort=desc", nil)
+				rec := httptest.NewRecorder()
+				cfg.HandlerGet(rec, req)
+				if rec.Code != http.StatusOK {
+					b.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+				}
+			}
+		})
+	}
+}
+
+// allocHeadroom is how far above its measured allocations a hot path may
+// go before TestAllocationBudget fails, leaving room for Go releases and
+// dependency updates that shift a few allocations
+const allocHeadroom = 1.10
+
+// TestAllocationBudget fails when the hot paths allocate noticeably more
+// than they did when last measured. Update a measurement only when the extra
+// allocations are intended, and say why in the commit that does.
+func TestAllocationBudget(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector's instrumentation allocates")
+	}
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		// measured is the allocations per request when last measured
+		measured float64
+		cfg      *Config
+		run      func(cfg *Config) int
+	}{
+		{
+			name:     "create",
+			measured: 148,
+			cfg:      newBenchConfig(0),
+			run: func(cfg *Config) int {
+				req := httptest.NewRequest(http.MethodPost, "/api/chirps", strings.NewReader(`{"body":"hello world"}`))
+				req.Header.Set("Authorization", "Bearer "+token)
+				rec := httptest.NewRecorder()
+				cfg.HandlerCreate(rec, req)
+				return rec.Code
+			},
+		},
+		{
+			// Mostly the fixed cost of the query and the batched lookups;
+			// what each further chirp costs is budgeted below
+			name:     "list 10",
+			measured: 563,
+			cfg:      newBenchConfig(10),
+			run:      listChirps,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := tt.run(tt.cfg); code >= 300 {
+				t.Fatalf("status = %d", code)
+			}
+			allocs := testing.AllocsPerRun(20, func() { tt.run(tt.cfg) })
+			if budget := tt.measured * allocHeadroom; allocs > budget {
+				t.Errorf("allocs per request = %.0f, budget %.0f (measured %.0f)", allocs, budget, tt.measured)
+			}
+		})
+	}
+
+	// Each chirp in a list costs about 31 allocations:
+	//   - 16 encoding its ID into the uuid[] arguments of the four batched
+	//     lookups (media, coauthors, reactions and views), 4 in each
+	//   - 12 in the fake driver building its row, where a real driver
+	//     would spend them decoding it
+	//   - 1 scanning the row and 1 building its response
+	//   - the rest in the dataloaders and JSON encoding
+	// Like, reply and repost counts come with the row, and the anonymous
+	// request skips muted words and likes.
+	t.Run("list per chirp", func(t *testing.T) {
+		const measured = 31
+		small, large := newBenchConfig(10), newBenchConfig(100)
+		perChirp := (testing.AllocsPerRun(20, func() { listChirps(large) }) -
+			testing.AllocsPerRun(20, func() { listChirps(small) })) / 90
+		if budget := measured * allocHeadroom; perChirp > budget {
+			t.Errorf("allocs per listed chirp = %.1f, budget %.1f (measured %d)", perChirp, budget, measured)
+		}
+	})
+}
+
+// listChirps lists chirps anonymously, returning the response status
+func listChirps(cfg *Config) int {
+	rec := httptest.NewRecorder()
+	cfg.HandlerGet(rec, httptest.NewRequest(http.MethodGet, "/api/chirps", nil))
+	return rec.Code
+}
+
+// newBenchConfig returns a handler config whose list queries return size chirps
+func newBenchConfig(size int) *Config {
+	db := sql.OpenDB(&benchConnector{
+		listSize: size,
+		likes:    map[[2]string]bool{},
+		follows:  map[[2]string]bool{},
+		views:    map[string]int64{},
+		links:    map[string]string{},
+		previews: map[string][3]string{},
+		verdicts: &sync.Map{},
+	})
+	return &Config{
+		DB:        database.New(db),
+		JWTSecret: benchSecret,
+	}
+}
+
+// benchConnector hands out connections that answer sqlc queries by name
+type benchConnector struct {
+	listSize int
+
+	// likes holds {chirp ID, user ID} pairs, shared by every connection
+	likes map[[2]string]bool
+
+	// follows holds {follower ID, followee ID} pairs, shared by every
+	// connection
+	follows map[[2]string]bool
+
+	// views holds view counts by chirp ID, shared by every connection
+	views map[string]int64
+
+	// links holds the previewed link by chirp ID, and previews the title,
+	// description and image by link, shared by every connection
+	links    map[string]string
+	previews map[string][3]string
+
+	// verdicts holds the recorded classifier verdict by chirp ID, shared by
+	// every connection and written from background jobs
+	verdicts *sync.Map
+
+	// locked makes every chirp looked up by ID locked
+	locked bool
+
+	// threaded makes every chirp looked up by ID a reply, two levels below
+	// the root of its thread
+	threaded bool
+}
+
+func (c *benchConnector) Connect(context.Context) (driver.Conn, error) {
+	return &benchConn{listSize: c.listSize, likes: c.likes, follows: c.follows, views: c.views, links: c.links, previews: c.previews, verdicts: c.verdicts, locked: c.locked, threaded: c.threaded}, nil
+}
+
+func (c *benchConnector) Driver() driver.Driver { return benchDriver{} }
+
+type benchDriver struct{}
+
+func (benchDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("bench driver must be used through sql.OpenDB")
+}
+
+type benchConn struct {
+	listSize int
+	likes    map[[2]string]bool
+	follows  map[[2]string]bool
+	views    map[string]int64
+	links    map[string]string
+	previews map[string][3]string
+	verdicts *sync.Map
+	locked   bool
+	threaded bool
+}
+
+func (c *benchConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("bench driver does not support prepared statements")
+}
+
+func (c *benchConn) Close() error { return nil }
+
+func (c *benchConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("bench driver does not support transactions")
+}
+
+// QueryContext dispatches on the "-- name:" comment sqlc puts on every query
+func (c *benchConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	now := time.Now().Add(-time.Minute)
+	chirpColumns := []string{"id", "created_at", "updated_at", "body", "user_id", "published_at", "tenant_id", "sensitive", "source", "oauth_client_id", "parent_chirp_id", "locked", "repost_of_chirp_id", "deleted_at", "language", "content_warning", "like_count", "reply_count", "repost_count"}
+	chirpRow := func(body string) []driver.Value {
+		return []driver.Value{uuid.NewString(), now, now, body, benchUserID.String(), now, tenant.DefaultID.String(), false, "", nil, nil, false, nil, nil, "", "", int64(0), int64(0), int64(0)}
+	}
+
+	switch queryName(query) {
+	case "CreateChirp":
+		row := chirpRow(args[1].Value.(string))
+		row[0] = args[0].Value
+		row[7] = args[5].Value
+		row[10] = args[8].Value
+		row[14] = args[9].Value
+		row[15] = args[10].Value
+		return &benchRows{columns: chirpColumns, values: [][]driver.Value{row}}, nil
+	case "CreateRepost":
+		row := chirpRow("")
+		row[0] = args[0].Value
+		row[12] = args[5].Value
+		return &benchRows{columns: chirpColumns, values: [][]driver.Value{row}}, nil
+	case "ScheduleChirp":
+		row := chirpRow("Just setting up my chirpy, this is chirp body text")
+		row[0] = args[1].Value
+		row[5] = args[0].Value
+		return &benchRows{columns: chirpColumns, values: [][]driver.Value{row}}, nil
+	case "GetScheduledChirps":
+		// Three chirps a day, all in the same minute, from 02:00 UTC tomorrow
+		values := make([][]driver.Value, c.listSize)
+		for i := range values {
+			values[i] = chirpRow("Just setting up my chirpy, this is chirp body text")
+			values[i][5] = benchScheduleStart.AddDate(0, 0, i/3)
+		}
+		return &benchRows{columns: chirpColumns, values: values}, nil
+	case "UpdateScheduledChirp":
+		// Only the bench user has scheduled chirps
+		if args[5].Value != benchUserID.String() {
+			return &benchRows{columns: chirpColumns}, nil
+		}
+		row := chirpRow("Just setting up my chirpy, this is chirp body text")
+		row[0] = args[4].Value
+		row[5] = benchScheduleStart
+		if args[0].Value != nil {
+			row[3] = args[0].Value
+		}
+		if args[1].Value != nil {
+			row[14] = args[1].Value
+		}
+		if args[3].Value != nil {
+			row[5] = args[3].Value
+		}
+		return &benchRows{columns: chirpColumns, values: [][]driver.Value{row}}, nil
+	case "RestoreChirp":
+		// Only the bench user has deleted chirps
+		if args[1].Value != benchUserID.String() {
+			return &benchRows{columns: chirpColumns}, nil
+		}
+		row := chirpRow("Just setting up my chirpy, this is chirp body text")
+		row[0] = args[0].Value
+		return &benchRows{columns: chirpColumns, values: [][]driver.Value{row}}, nil
+	case "GetChirpsByIDs":
+		rows := &benchRows{columns: chirpColumns}
+		for _, chirpID := range strings.Split(strings.Trim(args[1].Value.(string), "{}"), ",") {
+			row := chirpRow("Just setting up my chirpy, this is chirp body text")
+			row[0] = strings.Trim(chirpID, `"`)
+			rows.values = append(rows.values, row)
+		}
+		return rows, nil
+	case "GetChirpByID":
+		row := chirpRow("Just setting up my chirpy, this is chirp body text")
+		row[0] = args[0].Value
+		row[11] = c.locked
+		// The like counter follows the likes, as LikeChirp keeps it
+		for like := range c.likes {
+			if like[0] == args[0].Value {
+				row[16] = row[16].(int64) + 1
+			}
+		}
+		if c.threaded {
+			row[10] = uuid.NewString()
+		}
+		return &benchRows{columns: chirpColumns, values: [][]driver.Value{row}}, nil
+	case "GetChirpAncestors":
+		// The root, then the parent answering it
+		root := chirpRow("Just setting up my chirpy, this is chirp body text")
+		parent := chirpRow("Just setting up my chirpy, this is chirp body text")
+		parent[0] = args[0].Value
+		parent[10] = root[0]
+		return &benchRows{columns: chirpColumns, values: [][]driver.Value{root, parent}}, nil
+	case "GetConversationReplies":
+		// listSize replies alternating between depths 1 and 2
+		rows := &benchRows{columns: append(chirpColumns, "depth")}
+		for i := 0; i < c.listSize && len(rows.values) < int(args[3].Value.(int64)); i++ {
+			rows.values = append(rows.values, append(chirpRow("Just setting up my chirpy, this is chirp body text"), int64(i%2+1)))
+		}
+		return rows, nil
+	case "IsChirpReplyTo":
+		return &benchRows{columns: []string{"reply"}, values: [][]driver.Value{{args[0].Value != args[1].Value}}}, nil
+	case "GetChirpsForExport":
+		// listSize chirps a second apart, the odd ones archived
+		start := 0
+		if after := args[1].Value.(time.Time); !after.IsZero() {
+			start = int(after.Sub(benchExportStart)/time.Second) + 1
+		}
+		rows := &benchRows{columns: []string{"id", "created_at", "updated_at", "published_at", "body", "sensitive", "content_warning", "language", "source", "parent_chirp_id", "repost_of_chirp_id", "archived"}}
+		for i := start; i < c.listSize && len(rows.values) < int(args[3].Value.(int64)); i++ {
+			createdAt := benchExportStart.Add(time.Duration(i) * time.Second)
+			rows.values = append(rows.values, []driver.Value{uuid.NewString(), createdAt, createdAt, createdAt, "chirp, \"quoted\"\n" + strconv.Itoa(i), false, "", "", "", nil, nil, i%2 == 1})
+		}
+		return rows, nil
+	case "IsUserOnLegalHold":
+		return &benchRows{columns: []string{"legal_hold"}, values: [][]driver.Value{{false}}}, nil
+	case "IsUserActive":
+		return &benchRows{columns: []string{"active"}, values: [][]driver.Value{{true}}}, nil
+	case "IsActiveUserInTenant":
+		return &benchRows{columns: []string{"found"}, values: [][]driver.Value{{args[0].Value == benchUserID.String()}}}, nil
+	case "GetChirpsAsc", "GetChirpsDesc", "GetChirpsByAuthorAsc", "GetChirpsByAuthorDesc", "GetChirpReplies", "SearchChirps",
+		"GetChirpsByHashtagAsc", "GetChirpsByHashtagDesc", "GetChirpsMentioningUser", "GetUserTimeline":
+		values := make([][]driver.Value, c.listSize)
+		for i := range values {
+			values[i] = chirpRow("Just setting up my chirpy, this is chirp body text")
+		}
+		return &benchRows{columns: chirpColumns, values: values}, nil
+	case "GetMediaForChirps":
+		return &benchRows{columns: []string{"id", "created_at", "chirp_id", "position", "url", "alt_text"}}, nil
+	case "GetReactionCounts":
+		return &benchRows{columns: []string{"chirp_id", "emoji", "count"}}, nil
+	case "GetTrendingHashtags":
+		return &benchRows{columns: []string{"tag", "chirps"}, values: [][]driver.Value{{"golang", int64(3)}}}, nil
+	case "GetMutedWords":
+		return &benchRows{columns: []string{"phrase"}}, nil
+	case "GetUserPreferences":
+		return &benchRows{columns: []string{"user_id", "sensitive_content"}}, nil
+	case "GetLikedChirps":
+		viewerID := args[0].Value.(string)
+		rows := &benchRows{columns: []string{"chirp_id"}}
+		for _, chirpID := range strings.Split(strings.Trim(args[1].Value.(string), "{}"), ",") {
+			chirpID = strings.Trim(chirpID, `"`)
+			if c.likes[[2]string{chirpID, viewerID}] {
+				rows.values = append(rows.values, []driver.Value{chirpID})
+			}
+		}
+		return rows, nil
+	case "GetViewCounts":
+		rows := &benchRows{columns: []string{"chirp_id", "view_count"}}
+		for _, chirpID := range strings.Split(strings.Trim(args[0].Value.(string), "{}"), ",") {
+			chirpID = strings.Trim(chirpID, `"`)
+			if count := c.views[chirpID]; count > 0 {
+				rows.values = append(rows.values, []driver.Value{chirpID, count})
+			}
+		}
+		return rows, nil
+	case "GetDraft":
+		// Only the bench user has drafts
+		draftColumns := []string{"id", "created_at", "updated_at", "user_id", "body", "sensitive", "parent_chirp_id"}
+		if args[1].Value != benchUserID.String() {
+			return &benchRows{columns: draftColumns}, nil
+		}
+		return &benchRows{
+			columns: draftColumns,
+			values:  [][]driver.Value{{args[0].Value, now, now, benchUserID.String(), "A draft worth publishing", true, nil}},
+		}, nil
+	case "CreateReport":
+		return &benchRows{
+			columns: []string{"id", "created_at", "tenant_id", "chirp_id", "reporter_id", "reason", "details", "resolved_at", "resolved_by", "resolution"},
+			values:  [][]driver.Value{{uuid.NewString(), now, args[0].Value, args[1].Value, args[2].Value, args[3].Value, args[4].Value, nil, nil, nil}},
+		}, nil
+	case "CreateAppeal":
+		// Only the bench user has removed chirps
+		appealColumns := []string{"id", "created_at", "tenant_id", "user_id", "chirp_id", "reason", "decided_at", "decided_by", "decision"}
+		if args[2].Value != benchUserID.String() {
+			return &benchRows{columns: appealColumns}, nil
+		}
+		return &benchRows{
+			columns: appealColumns,
+			values:  [][]driver.Value{{uuid.NewString(), now, args[3].Value, args[2].Value, args[1].Value, args[0].Value, nil, nil, nil}},
+		}, nil
+	case "CreateModerationVerdict":
+		// A chirp already awaiting review isn't queued again
+		if _, queued := c.verdicts.LoadOrStore(args[1].Value.(string), args[2].Value.(string)); queued {
+			return &benchRows{columns: []string{"id", "created_at", "tenant_id", "chirp_id", "verdict", "label", "score", "reviewed_at", "reviewed_by", "outcome"}}, nil
+		}
+		return &benchRows{
+			columns: []string{"id", "created_at", "tenant_id", "chirp_id", "verdict", "label", "score", "reviewed_at", "reviewed_by", "outcome"},
+			values:  [][]driver.Value{{uuid.NewString(), now, args[0].Value, args[1].Value, args[2].Value, args[3].Value, args[4].Value, nil, nil, nil}},
+		}, nil
+	case "GetLatestChirps":
+		// Every chirp is the bench user's, seen by them and their followers
+		if !c.inFeed(args[1].Value.(string)) {
+			return &benchRows{columns: chirpColumns}, nil
+		}
+		values := make([][]driver.Value, min(c.listSize, int(args[2].Value.(int64))))
+		for i := range values {
+			values[i] = chirpRow("Just setting up my chirpy, this is chirp body text")
+		}
+		return &benchRows{columns: chirpColumns, values: values}, nil
+	case "GetFeedCandidates":
+		// An older chirp with more engagement, then a new one without any
+		rows := &benchRows{columns: []string{"id", "user_id", "published_at", "like_count", "reply_count", "repost_count"}}
+		if c.inFeed(args[1].Value.(string)) {
+			rows.values = [][]driver.Value{
+				{uuid.NewString(), benchUserID.String(), now, int64(0), int64(0), int64(0)},
+				{uuid.NewString(), benchUserID.String(), now.Add(-time.Hour), int64(40), int64(5), int64(2)},
+			}
+		}
+		return rows, nil
+	case "GetAuthorAffinity":
+		return &benchRows{columns: []string{"author_id", "interactions"}}, nil
+	case "GetChirpRevisions":
+		return &benchRows{
+			columns: []string{"id", "created_at", "chirp_id", "revision", "body"},
+			values:  [][]driver.Value{{uuid.NewString(), now, args[0].Value, int64(1), "Just setting up my chirp"}},
+		}, nil
+	case "GetUserByID":
+		return &benchRows{
+			columns: []string{"id", "created_at", "updated_at", "email", "hashed_password", "is_chirpy_red", "role", "deactivated_at", "username", "verified", "tenant_id", "legal_hold", "display_name", "bio", "location", "website", "avatar_url"},
+			values:  [][]driver.Value{{args[1].Value, benchUserCreatedAt, benchUserCreatedAt, "bench@example.com", "", false, "user", nil, nil, false, args[0].Value, false, "", "", "", "", ""}},
+		}, nil
+	case "GetUserRole":
+		return &benchRows{columns: []string{"role"}, values: [][]driver.Value{{"user"}}}, nil
+	case "GetAcceptedCoauthors":
+		return &benchRows{columns: []string{"chirp_id", "id", "username", "verified", "display_name", "bio", "location", "website", "avatar_url"}}, nil
+	case "IsLinkPreviewFresh":
+		_, found := c.previews[args[0].Value.(string)]
+		return &benchRows{columns: []string{"exists"}, values: [][]driver.Value{{found}}}, nil
+	case "GetLinkPreviews":
+		rows := &benchRows{columns: []string{"chirp_id", "url", "title", "description", "image_url"}}
+		for _, chirpID := range strings.Split(strings.Trim(args[0].Value.(string), "{}"), ",") {
+			chirpID = strings.Trim(chirpID, `"`)
+			if link, ok := c.links[chirpID]; ok {
+				if preview, ok := c.previews[link]; ok {
+					rows.values = append(rows.values, []driver.Value{chirpID, link, preview[0], preview[1], preview[2]})
+				}
+			}
+		}
+		return rows, nil
+	case "GetFollow":
+		rows := &benchRows{columns: []string{"follower_id", "followee_id", "created_at"}}
+		if c.follows[[2]string{args[0].Value.(string), args[1].Value.(string)}] {
+			rows.values = append(rows.values, []driver.Value{args[0].Value, args[1].Value, now})
+		}
+		return rows, nil
+	case "GetFollowers", "GetFollowing":
+		// Followers are matched on the followee, and following on the follower
+		listed, matched := 0, 1
+		if queryName(query) == "GetFollowing" {
+			listed, matched = 1, 0
+		}
+		rows := &benchRows{columns: []string{"id", "created_at", "username", "verified", "display_name", "bio", "location", "website", "avatar_url", "followed_at"}}
+		for follow := range c.follows {
+			if follow[matched] == args[0].Value && len(rows.values) < int(args[3].Value.(int64)) {
+				rows.values = append(rows.values, []driver.Value{follow[listed], benchUserCreatedAt, nil, false, "", "", "", "", "", now})
+			}
+		}
+		return rows, nil
+	case "GetChirpAuthors":
+		return &benchRows{
+			columns: []string{"id", "username", "verified", "display_name", "bio", "location", "website", "avatar_url"},
+			values:  [][]driver.Value{{benchUserID.String(), "bench_user", true, "Bench User", "", "", "", ""}},
+		}, nil
+	}
+	return nil, errors.New("bench driver: unexpected query " + queryName(query))
+}
+
+// ExecContext applies the statements that change state kept by the driver
+func (c *benchConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	switch queryName(query) {
+	case "LikeChirp":
+		c.likes[[2]string{args[0].Value.(string), args[1].Value.(string)}] = true
+		return driver.RowsAffected(1), nil
+	case "UnlikeChirp":
+		delete(c.likes, [2]string{args[0].Value.(string), args[1].Value.(string)})
+		return driver.RowsAffected(1), nil
+	case "FollowUser":
+		c.follows[[2]string{args[0].Value.(string), args[1].Value.(string)}] = true
+		return driver.RowsAffected(1), nil
+	case "UnfollowUser":
+		delete(c.follows, [2]string{args[0].Value.(string), args[1].Value.(string)})
+		return driver.RowsAffected(1), nil
+	case "SetChirpHashtags", "SetChirpMentions":
+		return driver.RowsAffected(0), nil
+	case "DeleteDraft", "SoftDeleteChirp":
+		return driver.RowsAffected(1), nil
+	case "SetChirpLink":
+		c.links[args[0].Value.(string)] = args[1].Value.(string)
+		return driver.RowsAffected(1), nil
+	case "DeleteChirpLink":
+		delete(c.links, args[0].Value.(string))
+		return driver.RowsAffected(1), nil
+	case "UpsertLinkPreview":
+		c.previews[args[0].Value.(string)] = [3]string{args[1].Value.(string), args[2].Value.(string), args[3].Value.(string)}
+		return driver.RowsAffected(1), nil
+	case "AddChirpViews":
+		chirpIDs := strings.Split(strings.Trim(args[0].Value.(string), "{}"), ",")
+		counts := strings.Split(strings.Trim(args[1].Value.(string), "{}"), ",")
+		for i, chirpID := range chirpIDs {
+			count, err := strconv.ParseInt(counts[i], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			c.views[strings.Trim(chirpID, `"`)] += count
+		}
+		return driver.RowsAffected(int64(len(chirpIDs))), nil
+	}
+	return nil, errors.New("bench driver: unexpected statement " + queryName(query))
+}
+
+// inFeed reports whether the bench user's chirps are in the viewer's feed
+func (c *benchConn) inFeed(viewerID string) bool {
+	return viewerID == benchUserID.String() || c.follows[[2]string{viewerID, benchUserID.String()}]
+}
+
+// queryName extracts the sqlc query name from the leading comment
+func queryName(query string) string {
+	line, _, _ := strings.Cut(query, "\n")
+	fields := strings.Fields(strings.TrimPrefix(line, "-- name:"))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+type benchRows struct {
+	columns []string
+	values  [][]driver.Value
+	pos     int
+}
+
+func (r *benchRows) Columns() []string { return r.columns }
+
+func (r *benchRows) Close() error { return nil }
+
+func (r *benchRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.pos])
+	r.pos++
+	return nil
+}
@@ -0,0 +1,194 @@
+package chirp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/dataloader"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// Co-author invite states. An invite starts pending; the invited user can
+// accept or decline it, and can later step down from an accepted invite.
+// Declined is final.
+const (
+	CoauthorPending  = "pending"
+	CoauthorAccepted = "accepted"
+	CoauthorDeclined = "declined"
+)
+
+var (
+	ErrCoauthorSelf       = errors.New("You can't invite yourself as a co-author")
+	ErrCoauthorNotFound   = errors.New("Co-author not found")
+	ErrCoauthorTransition = errors.New("Invite can't change to that status")
+)
+
+// coauthorTransitions lists the states each invite state can move to
+var coauthorTransitions = map[string][]string{
+	CoauthorPending:  {CoauthorAccepted, CoauthorDeclined},
+	CoauthorAccepted: {CoauthorDeclined},
+}
+
+// validateCoauthorTransition checks an invite may move from one state to another
+func validateCoauthorTransition(from, to string) error {
+	for _, allowed := range coauthorTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return ErrCoauthorTransition
+}
+
+// inviteCoauthor records a pending co-author invite for a new chirp and
+// announces it, so the invited user can be notified
+func (cfg *Config) inviteCoauthor(ctx context.Context, chirp database.Chirp, coauthorID uuid.UUID) error {
+	if coauthorID == chirp.UserID {
+		return ErrCoauthorSelf
+	}
+
+	found, err := cfg.DB.IsActiveUserInTenant(ctx, database.IsActiveUserInTenantParams{
+		ID:       coauthorID,
+		TenantID: tenant.FromContext(ctx).ID,
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrCoauthorNotFound
+	}
+
+	if err := cfg.DB.CreateChirpCoauthor(ctx, database.CreateChirpCoauthorParams{
+		ChirpID: chirp.ID,
+		UserID:  coauthorID,
+	}); err != nil {
+		return err
+	}
+
+	cfg.Events.Publish(events.Event{
+		Type:    events.ChirpCoauthorInvited,
+		UserID:  coauthorID,
+		ChirpID: chirp.ID,
+	})
+	return nil
+}
+
+// handlerCoauthor handles PUT /api/chirps/{id}/coauthor requests, letting the
+// invited user accept or decline an invite, or step down as co-author
+func (cfg *Config) handlerCoauthor(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	var request types.CoauthorUpdateRequest
+	if !handlers.DecodeJSON(w, r, &request) {
+		return
+	}
+
+	invite, err := cfg.DB.GetChirpCoauthor(r.Context(), database.GetChirpCoauthorParams{
+		ChirpID: chirpID,
+		UserID:  userID,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusNotFound, "Invite not found", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve invite", err)
+		}
+		return
+	}
+
+	if err := validateCoauthorTransition(invite.Status, request.Status); err != nil {
+		handlers.RespondWithError(w, http.StatusConflict, err.Error(), err)
+		return
+	}
+
+	if _, err := cfg.DB.UpdateChirpCoauthorStatus(r.Context(), database.UpdateChirpCoauthorStatusParams{
+		Status:  request.Status,
+		ChirpID: chirpID,
+		UserID:  userID,
+	}); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update invite", err)
+		return
+	}
+
+	cfg.handlerByIDGet(w, r, chirpID)
+}
+
+// attachCoauthors adds the accepted co-author to the given chirp responses
+// through the request's co-author loader
+func (cfg *Config) attachCoauthors(ctx context.Context, chirps []types.ChirpCreateResponse) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+
+	chirpIDs := make([]uuid.UUID, len(chirps))
+	for i, chirp := range chirps {
+		chirpIDs[i] = chirp.ID
+	}
+
+	coauthors, err := cfg.coauthorLoader(ctx).LoadMany(ctx, chirpIDs)
+	if err != nil {
+		return err
+	}
+	for i := range chirps {
+		chirps[i].Coauthor = coauthors[chirps[i].ID]
+	}
+	return nil
+}
+
+// coauthorLoader returns the request's loader for accepted co-authors by chirp ID
+func (cfg *Config) coauthorLoader(ctx context.Context) *dataloader.Loader[uuid.UUID, *types.ChirpAuthor] {
+	return dataloader.For(ctx, "chirp.coauthors", func(ctx context.Context, chirpIDs []uuid.UUID) (map[uuid.UUID]*types.ChirpAuthor, error) {
+		dbCoauthors, err := cfg.DB.GetAcceptedCoauthors(ctx, chirpIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		coauthors := make(map[uuid.UUID]*types.ChirpAuthor, len(dbCoauthors))
+		for _, coauthor := range dbCoauthors {
+			coauthors[coauthor.ChirpID] = &types.ChirpAuthor{
+				ID:          coauthor.ID,
+				Username:    coauthor.Username.String,
+				Verified:    coauthor.Verified,
+				DisplayName: coauthor.DisplayName,
+				Bio:         coauthor.Bio,
+				Location:    coauthor.Location,
+				Website:     coauthor.Website,
+				AvatarURL:   coauthor.AvatarUrl,
+			}
+		}
+		return coauthors, nil
+	})
+}
+
+// attachArchivedCoauthor adds the accepted co-author to a single archived chirp response
+func (cfg *Config) attachArchivedCoauthor(ctx context.Context, chirp *types.ChirpCreateResponse) error {
+	dbCoauthors, err := cfg.DB.GetArchivedAcceptedCoauthors(ctx, []uuid.UUID{chirp.ID})
+	if err != nil {
+		return err
+	}
+	for _, coauthor := range dbCoauthors {
+		chirp.Coauthor = &types.ChirpAuthor{
+			ID:          coauthor.ID,
+			Username:    coauthor.Username.String,
+			Verified:    coauthor.Verified,
+			DisplayName: coauthor.DisplayName,
+			Bio:         coauthor.Bio,
+			Location:    coauthor.Location,
+			Website:     coauthor.Website,
+			AvatarURL:   coauthor.AvatarUrl,
+		}
+	}
+	return nil
+}
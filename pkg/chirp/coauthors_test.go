@@ -0,0 +1,25 @@
+package chirp
+
+import "testing"
+
+func TestValidateCoauthorTransition(t *testing.T) {
+	tests := []struct {
+		from    string
+		to      string
+		wantErr bool
+	}{
+		{from: CoauthorPending, to: CoauthorAccepted, wantErr: false},
+		{from: CoauthorPending, to: CoauthorDeclined, wantErr: false},
+		{from: CoauthorAccepted, to: CoauthorDeclined, wantErr: false},
+		{from: CoauthorPending, to: CoauthorPending, wantErr: true},
+		{from: CoauthorAccepted, to: CoauthorPending, wantErr: true},
+		{from: CoauthorDeclined, to: CoauthorAccepted, wantErr: true},
+		{from: CoauthorPending, to: "maybe", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := validateCoauthorTransition(tt.from, tt.to); (err != nil) != tt.wantErr {
+			t.Errorf("validateCoauthorTransition(%q, %q) error = %v, wantErr %v", tt.from, tt.to, err, tt.wantErr)
+		}
+	}
+}
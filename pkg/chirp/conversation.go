@@ -0,0 +1,143 @@
+package chirp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// handlerConversation handles GET /api/chirps/{id}/conversation requests,
+// returning the chirp with the root of its thread, the chirps between the
+// two, and the replies below it at any depth, depth first with siblings
+// oldest first. Replies come in pages of ?limit= (default 20, at most 100);
+// ?after_id= continues after the last reply of the previous page, and a
+// Link header points at the next page while there may be one.
+func (cfg *Config) handlerConversation(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
+	dbChirp, archived, ok := cfg.getVisibleChirp(w, r, chirpID)
+	if !ok {
+		return
+	}
+
+	limit := timelineDefaultLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > timelineMaxLimit {
+			handlers.RespondWithError(w, http.StatusBadRequest, "limit must be between 1 and 100", err)
+			return
+		}
+		limit = parsed
+	}
+
+	// An invalid token was already accepted as anonymous when the chirp
+	// was looked up, so it only loses liked_by_me here
+	viewerID, authenticated, _ := cfg.optionalViewer(r)
+
+	tenantID := tenant.FromContext(r.Context()).ID
+	params := database.GetConversationRepliesParams{
+		ChirpID:  dbChirp.ID,
+		TenantID: tenantID,
+		PageSize: int32(limit),
+	}
+	if afterID := r.URL.Query().Get("after_id"); afterID != "" {
+		parsedID, err := uuid.Parse(afterID)
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusBadRequest, "Invalid after_id format", err)
+			return
+		}
+		reply, err := cfg.DB.IsChirpReplyTo(r.Context(), database.IsChirpReplyToParams{
+			ChirpID:    parsedID,
+			AncestorID: dbChirp.ID,
+		})
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+			return
+		}
+		if !reply {
+			handlers.RespondWithError(w, http.StatusBadRequest, "after_id must be one of the chirp's replies", nil)
+			return
+		}
+		params.AfterID = uuid.NullUUID{UUID: parsedID, Valid: true}
+	}
+
+	var dbAncestors []database.Chirp
+	if dbChirp.ParentChirpID.Valid {
+		var err error
+		dbAncestors, err = cfg.DB.GetChirpAncestors(r.Context(), database.GetChirpAncestorsParams{
+			ParentChirpID: dbChirp.ParentChirpID.UUID,
+			TenantID:      tenantID,
+		})
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+			return
+		}
+	}
+
+	rows, err := cfg.DB.GetConversationReplies(r.Context(), params)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+		return
+	}
+
+	// The cursor comes from the last reply fetched, since muted replies may
+	// be dropped from the response
+	if len(rows) == limit {
+		next := url.Values{
+			"after_id": {rows[len(rows)-1].Chirp.ID.String()},
+			"limit":    {strconv.Itoa(limit)},
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, next.Encode()))
+	}
+
+	chirp, err := cfg.chirpResponse(r.Context(), dbChirp, archived, viewerID, authenticated)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirp, err)
+		return
+	}
+	if !archived {
+		cfg.Views.Record([]types.ChirpCreateResponse{chirp})
+	}
+
+	// Ancestors and replies share one list so related records are loaded
+	// in one batch, then are told apart by ID
+	depths := make(map[uuid.UUID]int, len(rows))
+	dbChirps := dbAncestors
+	for _, row := range rows {
+		depths[row.Chirp.ID] = int(row.Depth)
+		dbChirps = append(dbChirps, row.Chirp)
+	}
+	list, err := cfg.buildChirpList(r.Context(), dbChirps, viewerID, authenticated)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+		return
+	}
+
+	response := types.ConversationResponse{
+		Ancestors: types.ChirpListResponse{},
+		Chirp:     chirp,
+		Replies:   []types.ConversationReply{},
+	}
+	if !dbChirp.ParentChirpID.Valid {
+		response.Root = &response.Chirp
+	}
+	// Only a chirp that isn't a reply can be the root; when the real root
+	// is hidden the topmost visible ancestor is just an ancestor
+	for i := range list {
+		depth, isReply := depths[list[i].ID]
+		switch {
+		case isReply:
+			response.Replies = append(response.Replies, types.ConversationReply{Depth: depth, Chirp: list[i]})
+		case list[i].ParentChirpID == nil:
+			response.Root = &list[i]
+		default:
+			response.Ancestors = append(response.Ancestors, list[i])
+		}
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
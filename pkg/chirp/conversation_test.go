@@ -0,0 +1,83 @@
+package chirp
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestHandlerConversation(t *testing.T) {
+	chirpID := uuid.NewString()
+	base := "/api/chirps/" + chirpID + "/conversation"
+
+	tests := []struct {
+		name          string
+		threaded      bool
+		method        string
+		path          string
+		wantStatus    int
+		wantRoot      bool
+		wantAncestors int
+		wantDepths    []int
+		wantNext      bool
+	}{
+		{name: "root", method: http.MethodGet, path: base, wantStatus: http.StatusOK, wantRoot: true, wantDepths: []int{1, 2, 1}},
+		{name: "reply", threaded: true, method: http.MethodGet, path: base, wantStatus: http.StatusOK, wantRoot: true, wantAncestors: 1, wantDepths: []int{1, 2, 1}},
+		{name: "full page", method: http.MethodGet, path: base + "?limit=2", wantStatus: http.StatusOK, wantRoot: true, wantDepths: []int{1, 2}, wantNext: true},
+		{name: "next page", method: http.MethodGet, path: base + "?after_id=" + uuid.NewString(), wantStatus: http.StatusOK, wantRoot: true, wantDepths: []int{1, 2, 1}},
+		{name: "after_id not a reply", method: http.MethodGet, path: base + "?after_id=" + chirpID, wantStatus: http.StatusBadRequest},
+		{name: "invalid after_id", method: http.MethodGet, path: base + "?after_id=nobody", wantStatus: http.StatusBadRequest},
+		{name: "invalid limit", method: http.MethodGet, path: base + "?limit=0", wantStatus: http.StatusBadRequest},
+		{name: "wrong method", method: http.MethodPost, path: base, wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newBenchConfig(3)
+			cfg.DB = database.New(sql.OpenDB(&benchConnector{listSize: 3, likes: map[[2]string]bool{}, views: map[string]int64{}, links: map[string]string{}, verdicts: &sync.Map{}, threaded: tt.threaded}))
+
+			rec := httptest.NewRecorder()
+			cfg.HandlerByID(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var response types.ConversationResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Chirp.ID.String() != chirpID {
+				t.Errorf("chirp = %v, want %s", response.Chirp.ID, chirpID)
+			}
+			if got := response.Root != nil; got != tt.wantRoot {
+				t.Errorf("root = %v, want present %v", response.Root, tt.wantRoot)
+			}
+			if response.Root != nil && response.Root.ParentChirpID != nil {
+				t.Errorf("root is a reply to %v", response.Root.ParentChirpID)
+			}
+			if len(response.Ancestors) != tt.wantAncestors {
+				t.Errorf("%d ancestors, want %d", len(response.Ancestors), tt.wantAncestors)
+			}
+			if len(response.Replies) != len(tt.wantDepths) {
+				t.Fatalf("%d replies, want %d", len(response.Replies), len(tt.wantDepths))
+			}
+			for i, reply := range response.Replies {
+				if reply.Depth != tt.wantDepths[i] {
+					t.Errorf("replies[%d].depth = %d, want %d", i, reply.Depth, tt.wantDepths[i])
+				}
+			}
+			if next := rec.Header().Get("Link") != ""; next != tt.wantNext {
+				t.Errorf("Link header = %q, want next page %v", rec.Header().Get("Link"), tt.wantNext)
+			}
+		})
+	}
+}
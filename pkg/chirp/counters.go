@@ -0,0 +1,25 @@
+package chirp
+
+import (
+	"context"
+	"log"
+)
+
+// ReconcileCounters recounts the like, reply and repost counters of live and
+// archived chirps. The statements that add or remove a like, reply or repost
+// keep the counters current; this fixes the drift they can't see, such as
+// accounts being deactivated or deleted.
+func (cfg *Config) ReconcileCounters(ctx context.Context) error {
+	fixed, err := cfg.DB.ReconcileChirpCounters(ctx)
+	if err != nil {
+		return err
+	}
+	fixedArchived, err := cfg.DB.ReconcileArchivedChirpCounters(ctx)
+	if err != nil {
+		return err
+	}
+	if fixed+fixedArchived > 0 {
+		log.Printf("Fixed the counters of %d chirps and %d archived chirps", fixed, fixedArchived)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package chirp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+var (
+	ErrChirpNotFound  = errors.New("Chirp not found")
+	ErrNotChirpAuthor = errors.New("Only the author can delete a chirp")
+	ErrChirpLegalHold = errors.New("Chirps can't be deleted while the account is under legal hold")
+)
+
+// deleteChirp deletes one of userID's chirps. Live chirps are soft deleted
+// so they can be restored; archived ones are removed straight away.
+func (cfg *Config) deleteChirp(ctx context.Context, userID, chirpID uuid.UUID) error {
+	// Retrieve chirp from database to verify ownership
+	dbChirp, archived, err := cfg.getChirp(ctx, chirpID)
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			return ErrChirpNotFound
+		}
+		return err
+	}
+	if dbChirp.UserID != userID {
+		return ErrNotChirpAuthor
+	}
+
+	// Data under legal hold must be preserved
+	legalHold, err := cfg.DB.IsUserOnLegalHold(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if legalHold {
+		return ErrChirpLegalHold
+	}
+
+	if archived {
+		err = cfg.DB.DeleteArchivedChirp(ctx, chirpID)
+	} else {
+		err = cfg.DB.SoftDeleteChirp(ctx, chirpID)
+	}
+	if err != nil {
+		return err
+	}
+
+	cfg.Events.Publish(events.Event{
+		Type:    events.ChirpDeleted,
+		UserID:  dbChirp.UserID,
+		ChirpID: chirpID,
+	})
+	return nil
+}
+
+// HandlerBatchDelete handles POST /api/chirps/delete, which deletes up to
+// 100 of the user's chirps at once. Each chirp is deleted as DELETE
+// /api/chirps/{id} would delete it, and the response reports every one.
+func (cfg *Config) HandlerBatchDelete(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	var request types.ChirpBatchDeleteRequest
+	if !handlers.DecodeJSON(w, r, &request) {
+		return
+	}
+
+	results := make([]types.BatchResult, 0, len(request.ChirpIDs))
+	for _, id := range request.ChirpIDs {
+		chirpID, err := uuid.Parse(id)
+		if err != nil {
+			results = append(results, handlers.BatchError(id, http.StatusBadRequest, "", "Invalid chirp ID format", nil))
+			continue
+		}
+		switch err := cfg.deleteChirp(r.Context(), userID, chirpID); {
+		case err == nil:
+			results = append(results, handlers.BatchSuccess(id, http.StatusNoContent))
+		case errors.Is(err, ErrChirpNotFound):
+			results = append(results, handlers.BatchError(id, http.StatusNotFound, "", err.Error(), nil))
+		case errors.Is(err, ErrNotChirpAuthor):
+			results = append(results, handlers.BatchError(id, http.StatusForbidden, "", err.Error(), nil))
+		case errors.Is(err, ErrChirpLegalHold):
+			results = append(results, handlers.BatchError(id, http.StatusConflict, "", err.Error(), nil))
+		default:
+			results = append(results, handlers.BatchError(id, http.StatusInternalServerError, "", "Couldn't delete chirp", err))
+		}
+	}
+	handlers.RespondWithBatch(w, results)
+}
@@ -0,0 +1,65 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestHandlerBatchDelete(t *testing.T) {
+	cfg := newBenchConfig(0)
+	deleteAs := func(userID uuid.UUID, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		token, err := auth.MakeJWT(userID, benchSecret, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/api/chirps/delete", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		cfg.HandlerBatchDelete(rec, req)
+		return rec
+	}
+	statuses := func(rec *httptest.ResponseRecorder) []int {
+		t.Helper()
+		var response types.BatchResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		var got []int
+		for _, result := range response.Results {
+			got = append(got, result.Status)
+		}
+		return got
+	}
+
+	chirpID := uuid.NewString()
+	rec := deleteAs(benchUserID, `{"chirp_ids":["`+chirpID+`","not-a-uuid"]}`)
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("partial failure status = %d, want %d", rec.Code, http.StatusMultiStatus)
+	}
+	if got := statuses(rec); len(got) != 2 || got[0] != http.StatusNoContent || got[1] != http.StatusBadRequest {
+		t.Errorf("item statuses = %v, want [204 400]", got)
+	}
+
+	if rec := deleteAs(benchUserID, `{"chirp_ids":["`+chirpID+`"]}`); rec.Code != http.StatusOK {
+		t.Errorf("all deleted status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	// Every chirp in the bench database belongs to the bench user
+	rec = deleteAs(uuid.New(), `{"chirp_ids":["`+chirpID+`"]}`)
+	if got := statuses(rec); rec.Code != http.StatusMultiStatus || len(got) != 1 || got[0] != http.StatusForbidden {
+		t.Errorf("other user's chirp: status = %d, items = %v", rec.Code, got)
+	}
+
+	if rec := deleteAs(benchUserID, `{"chirp_ids":[]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("empty batch status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
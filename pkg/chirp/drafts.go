@@ -0,0 +1,226 @@
+package chirp
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+const draftsPrefix = "/api/drafts/"
+
+// HandlerDrafts handles /api/drafts requests. Drafts belong to the
+// authenticated user and appear nowhere else: GET lists them, most recently
+// edited first, and POST saves a new one. GET, PUT and DELETE on
+// /api/drafts/{id} read, replace and discard a draft, and POST
+// /api/drafts/{id}/publish turns it into a chirp.
+func (cfg *Config) HandlerDrafts(w http.ResponseWriter, r *http.Request) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	idString, subresource := handlers.SplitResourcePath(r.URL.Path, draftsPrefix)
+	if idString == "" {
+		switch r.Method {
+		case http.MethodGet:
+			cfg.handlerDraftsList(w, r, userID)
+		case http.MethodPost:
+			cfg.handlerDraftsCreate(w, r, userID)
+		default:
+			handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+		}
+		return
+	}
+
+	draftID, err := uuid.Parse(idString)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid draft ID", err)
+		return
+	}
+
+	switch {
+	case subresource == "publish":
+		if !handlers.RequireMethod(w, r, http.MethodPost) {
+			return
+		}
+		cfg.handlerDraftPublish(w, r, userID, draftID)
+	case subresource != "":
+		handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+	case r.Method == http.MethodGet:
+		draft, ok := cfg.getDraft(w, r, userID, draftID)
+		if ok {
+			handlers.RespondWithJSON(w, http.StatusOK, buildDraftResponse(draft))
+		}
+	case r.Method == http.MethodPut:
+		cfg.handlerDraftUpdate(w, r, userID, draftID)
+	case r.Method == http.MethodDelete:
+		cfg.handlerDraftDelete(w, r, userID, draftID)
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+	}
+}
+
+func (cfg *Config) handlerDraftsList(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	drafts, err := cfg.DB.GetDrafts(r.Context(), userID)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve drafts", err)
+		return
+	}
+
+	response := make([]types.Draft, len(drafts))
+	for i, draft := range drafts {
+		response[i] = buildDraftResponse(draft)
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *Config) handlerDraftsCreate(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	request, ok := cfg.decodeDraftRequest(w, r)
+	if !ok {
+		return
+	}
+
+	draft, err := cfg.DB.CreateDraft(r.Context(), database.CreateDraftParams{
+		UserID:        userID,
+		Body:          request.Body,
+		Sensitive:     request.Sensitive,
+		ParentChirpID: nullUUID(request.ParentChirpID),
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't save draft", err)
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusCreated, buildDraftResponse(draft))
+}
+
+func (cfg *Config) handlerDraftUpdate(w http.ResponseWriter, r *http.Request, userID, draftID uuid.UUID) {
+	request, ok := cfg.decodeDraftRequest(w, r)
+	if !ok {
+		return
+	}
+
+	draft, err := cfg.DB.UpdateDraft(r.Context(), database.UpdateDraftParams{
+		ID:            draftID,
+		UserID:        userID,
+		Body:          request.Body,
+		Sensitive:     request.Sensitive,
+		ParentChirpID: nullUUID(request.ParentChirpID),
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusNotFound, "Draft not found", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't save draft", err)
+		}
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, buildDraftResponse(draft))
+}
+
+func (cfg *Config) handlerDraftDelete(w http.ResponseWriter, r *http.Request, userID, draftID uuid.UUID) {
+	deleted, err := cfg.DB.DeleteDraft(r.Context(), database.DeleteDraftParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't delete draft", err)
+		return
+	}
+	if deleted == 0 {
+		handlers.RespondWithError(w, http.StatusNotFound, "Draft not found", nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerDraftPublish creates a chirp from the draft, held to the same rules
+// as POST /api/chirps, and discards the draft
+func (cfg *Config) handlerDraftPublish(w http.ResponseWriter, r *http.Request, userID, draftID uuid.UUID) {
+	draft, ok := cfg.getDraft(w, r, userID, draftID)
+	if !ok {
+		return
+	}
+
+	request := types.ChirpCreateRequest{
+		Body:      draft.Body,
+		Sensitive: draft.Sensitive,
+	}
+	if draft.ParentChirpID.Valid {
+		request.ParentChirpID = &draft.ParentChirpID.UUID
+	}
+	response, ok := cfg.createChirp(w, r, userID, request)
+	if !ok {
+		return
+	}
+
+	// The chirp is out, so a draft left behind is only clutter
+	if _, err := cfg.DB.DeleteDraft(r.Context(), database.DeleteDraftParams{
+		ID:     draftID,
+		UserID: userID,
+	}); err != nil {
+		log.Printf("Couldn't delete published draft %s: %v", draftID, err)
+	}
+	handlers.RespondWithJSON(w, http.StatusCreated, response)
+}
+
+// getDraft loads one of the user's drafts, responding 404 for drafts that
+// don't exist or belong to someone else
+func (cfg *Config) getDraft(w http.ResponseWriter, r *http.Request, userID, draftID uuid.UUID) (database.Draft, bool) {
+	draft, err := cfg.DB.GetDraft(r.Context(), database.GetDraftParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusNotFound, "Draft not found", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve draft", err)
+		}
+		return database.Draft{}, false
+	}
+	return draft, true
+}
+
+// decodeDraftRequest reads a draft from the request body. Drafts must fit in
+// a chirp; the remaining checks wait until the draft is published.
+func (cfg *Config) decodeDraftRequest(w http.ResponseWriter, r *http.Request) (types.DraftRequest, bool) {
+	var request types.DraftRequest
+	if !handlers.DecodeJSON(w, r, &request) {
+		return request, false
+	}
+	if err := validation.ValidateChirpBody(request.Body, cfg.Counting); err != nil {
+		cfg.respondBodyError(w, request.Body, err)
+		return request, false
+	}
+	return request, true
+}
+
+func buildDraftResponse(draft database.Draft) types.Draft {
+	response := types.Draft{
+		ID:        draft.ID,
+		CreatedAt: types.NewTimestamp(draft.CreatedAt),
+		UpdatedAt: types.NewTimestamp(draft.UpdatedAt),
+		Body:      draft.Body,
+		Sensitive: draft.Sensitive,
+	}
+	if draft.ParentChirpID.Valid {
+		response.ParentChirpID = &draft.ParentChirpID.UUID
+	}
+	return response
+}
+
+func nullUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
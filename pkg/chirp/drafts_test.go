@@ -0,0 +1,58 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestHandlerDraftPublish(t *testing.T) {
+	cfg := newBenchConfig(0)
+	path := "/api/drafts/" + uuid.NewString() + "/publish"
+
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	cfg.HandlerDrafts(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body = %s", rec.Code, http.StatusCreated, rec.Body)
+	}
+	var chirp types.ChirpCreateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &chirp); err != nil {
+		t.Fatal(err)
+	}
+	if chirp.Body != "A draft worth publishing" || !chirp.Sensitive {
+		t.Errorf("chirp = %q (sensitive %v), want the draft's body and flag", chirp.Body, chirp.Sensitive)
+	}
+
+	// Someone else's draft is indistinguishable from a missing one
+	otherToken, err := auth.MakeJWT(uuid.New(), benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = httptest.NewRequest(http.MethodPost, path, nil)
+	req.Header.Set("Authorization", "Bearer "+otherToken)
+	rec = httptest.NewRecorder()
+	cfg.HandlerDrafts(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("other user status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec = httptest.NewRecorder()
+	cfg.HandlerDrafts(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
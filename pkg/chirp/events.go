@@ -0,0 +1,38 @@
+package chirp
+
+import (
+	"context"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+)
+
+// publishChirpCreated announces a new chirp once it becomes visible. Chirps
+// inside an undo window are announced when the window closes, unless the
+// author cancelled them in the meantime.
+func (cfg *Config) publishChirpCreated(chirp database.Chirp) {
+	if cfg.Events == nil {
+		return
+	}
+
+	event := events.Event{
+		Type:       events.ChirpCreated,
+		OccurredAt: chirp.PublishedAt,
+		UserID:     chirp.UserID,
+		ChirpID:    chirp.ID,
+	}
+
+	delay := time.Until(chirp.PublishedAt)
+	if delay <= 0 {
+		cfg.Events.Publish(event)
+		return
+	}
+
+	time.AfterFunc(delay, func() {
+		if _, err := cfg.DB.GetChirpByID(context.Background(), chirp.ID); err != nil {
+			return
+		}
+		cfg.Events.Publish(event)
+	})
+}
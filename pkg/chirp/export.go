@@ -0,0 +1,201 @@
+package chirp
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+const (
+	// exportPageSize is how many chirps an export reads from the database
+	// at a time
+	exportPageSize = 500
+
+	contentTypeNDJSON = "application/x-ndjson"
+	contentTypeCSV    = "text/csv; charset=utf-8"
+)
+
+// exportCSVHeader names the columns of a CSV export, in order
+var exportCSVHeader = []string{
+	"id", "created_at", "updated_at", "published_at", "body", "sensitive", "content_warning",
+	"language", "source", "parent_chirp_id", "repost_of_chirp_id", "archived",
+}
+
+// HandlerExport handles GET /api/users/me/chirps/export requests, streaming
+// every live and archived chirp of the user, oldest first. The Accept header
+// picks the format: NDJSON (the default) or CSV. Chirps are read a page at a
+// time, so large accounts don't have to fit in memory.
+func (cfg *Config) HandlerExport(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	contentType, ok := exportContentType(r.Header.Get("Accept"))
+	if !ok {
+		handlers.RespondWithError(w, http.StatusNotAcceptable, "Exports are available as application/x-ndjson or text/csv", nil)
+		return
+	}
+
+	// Read the first page before committing to a 200, so a failing
+	// database still gets a proper error response
+	page, err := cfg.DB.GetChirpsForExport(r.Context(), database.GetChirpsForExportParams{
+		UserID:   userID,
+		AfterID:  uuid.Nil,
+		PageSize: exportPageSize,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+		return
+	}
+
+	extension := "ndjson"
+	if contentType == contentTypeCSV {
+		extension = "csv"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="chirps.`+extension+`"`)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(w)
+	encoder := json.NewEncoder(w)
+	csvWriter := csv.NewWriter(w)
+	if contentType == contentTypeCSV {
+		if err := csvWriter.Write(exportCSVHeader); err != nil {
+			return
+		}
+	}
+
+	// Headers are sent, so a failure from here on can only cut the export
+	// short
+	for len(page) > 0 {
+		for _, row := range page {
+			chirp := buildChirpExport(row)
+			if contentType == contentTypeCSV {
+				err = csvWriter.Write(chirpExportRecord(chirp))
+			} else {
+				err = encoder.Encode(chirp)
+			}
+			if err != nil {
+				return
+			}
+		}
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+		if len(page) < exportPageSize {
+			return
+		}
+
+		last := page[len(page)-1]
+		page, err = cfg.DB.GetChirpsForExport(r.Context(), database.GetChirpsForExportParams{
+			UserID:         userID,
+			AfterCreatedAt: last.CreatedAt,
+			AfterID:        last.ID,
+			PageSize:       exportPageSize,
+		})
+		if err != nil {
+			log.Printf("Couldn't export chirps of user %s: %s", userID, err)
+			return
+		}
+	}
+	csvWriter.Flush()
+}
+
+// exportContentType picks the export format from an Accept header: the first
+// listed media type that is supported, with NDJSON for wildcards and a
+// missing header
+func exportContentType(accept string) (string, bool) {
+	if accept == "" {
+		return contentTypeNDJSON, true
+	}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "application/x-ndjson", "application/*", "*/*":
+			return contentTypeNDJSON, true
+		case "text/csv", "text/*":
+			return contentTypeCSV, true
+		}
+	}
+	return "", false
+}
+
+// buildChirpExport converts an exported database row to its API form
+func buildChirpExport(row database.GetChirpsForExportRow) types.ChirpExport {
+	chirp := types.ChirpExport{
+		ID:             row.ID,
+		CreatedAt:      types.NewTimestamp(row.CreatedAt),
+		UpdatedAt:      types.NewTimestamp(row.UpdatedAt),
+		PublishedAt:    types.NewTimestamp(row.PublishedAt),
+		Body:           row.Body,
+		Sensitive:      row.Sensitive,
+		ContentWarning: row.ContentWarning,
+		Language:       row.Language,
+		Source:         row.Source,
+		Archived:       row.Archived,
+	}
+	if row.ParentChirpID.Valid {
+		chirp.ParentChirpID = &row.ParentChirpID.UUID
+	}
+	if row.RepostOfChirpID.Valid {
+		chirp.RepostOfChirpID = &row.RepostOfChirpID.UUID
+	}
+	return chirp
+}
+
+// chirpExportRecord lays out an exported chirp as exportCSVHeader's columns.
+// Missing IDs are left empty.
+func chirpExportRecord(chirp types.ChirpExport) []string {
+	optionalID := func(id *uuid.UUID) string {
+		if id == nil {
+			return ""
+		}
+		return id.String()
+	}
+	return []string{
+		chirp.ID.String(),
+		formatExportTime(chirp.CreatedAt.Time),
+		formatExportTime(chirp.UpdatedAt.Time),
+		formatExportTime(chirp.PublishedAt.Time),
+		chirp.Body,
+		strconv.FormatBool(chirp.Sensitive),
+		chirp.ContentWarning,
+		chirp.Language,
+		chirp.Source,
+		optionalID(chirp.ParentChirpID),
+		optionalID(chirp.RepostOfChirpID),
+		strconv.FormatBool(chirp.Archived),
+	}
+}
+
+// formatExportTime writes a time the way timestamps in JSON responses are
+func formatExportTime(t time.Time) string {
+	return t.UTC().Format(types.TimestampLayout)
+}
@@ -0,0 +1,82 @@
+package chirp
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestHandlerExport(t *testing.T) {
+	// Enough chirps for three pages, the last one partial
+	cfg := newBenchConfig(2*exportPageSize + 7)
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	export := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/users/me/chirps/export", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		cfg.HandlerExport(rec, req)
+		return rec
+	}
+
+	t.Run("ndjson", func(t *testing.T) {
+		rec := export("")
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != contentTypeNDJSON {
+			t.Fatalf("status = %d, Content-Type = %q", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		var chirps []types.ChirpExport
+		scanner := bufio.NewScanner(rec.Body)
+		for scanner.Scan() {
+			var chirp types.ChirpExport
+			if err := json.Unmarshal(scanner.Bytes(), &chirp); err != nil {
+				t.Fatalf("line %d: %v", len(chirps)+1, err)
+			}
+			chirps = append(chirps, chirp)
+		}
+		if len(chirps) != 2*exportPageSize+7 {
+			t.Fatalf("exported %d chirps, want %d", len(chirps), 2*exportPageSize+7)
+		}
+		for i := 1; i < len(chirps); i++ {
+			if !chirps[i].CreatedAt.After(chirps[i-1].CreatedAt.Time) {
+				t.Fatalf("chirp %d is not newer than the one before it", i)
+			}
+		}
+		if chirps[0].Archived || !chirps[1].Archived {
+			t.Errorf("archived = %v, %v, want false, true", chirps[0].Archived, chirps[1].Archived)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		rec := export("text/html;q=0.9, text/csv")
+		if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != contentTypeCSV {
+			t.Fatalf("status = %d, Content-Type = %q", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		records, err := csv.NewReader(rec.Body).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 2*exportPageSize+8 || strings.Join(records[0], ",") != strings.Join(exportCSVHeader, ",") {
+			t.Fatalf("got %d records starting with %v", len(records), records[0])
+		}
+		if records[1][4] != "chirp, \"quoted\"\n0" || records[1][1] != "2024-01-01T00:00:00.000Z" || records[2][11] != "true" {
+			t.Errorf("first rows = %q, %q", records[1], records[2])
+		}
+	})
+
+	if rec := export("image/png"); rec.Code != http.StatusNotAcceptable {
+		t.Errorf("unsupported Accept status = %d, want %d", rec.Code, http.StatusNotAcceptable)
+	}
+}
@@ -0,0 +1,163 @@
+package chirp
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// Feed orderings for ?ranking=
+const (
+	RankingLatest = "latest"
+	RankingTop    = "top"
+)
+
+const (
+	// feedWindow is how far back the ranked feed looks for chirps
+	feedWindow = 48 * time.Hour
+
+	// feedMaxCandidates bounds how many chirps the ranked feed scores
+	feedMaxCandidates = 500
+
+	// affinityWindow is how far back the viewer's likes and replies count
+	// towards their affinity for an author
+	affinityWindow = 30 * 24 * time.Hour
+
+	// feedHalfLife is the age at which a chirp's score has halved
+	feedHalfLife = 6 * time.Hour
+)
+
+// HandlerFeed handles GET /api/feed requests: the chirps of the users the
+// viewer follows and their own, as the viewer sees them, newest first, or
+// with ?ranking=top ordered by score.
+// ?limit= sets how many are returned (default 20, at most 100).
+func (cfg *Config) HandlerFeed(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	limit := timelineDefaultLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > timelineMaxLimit {
+			handlers.RespondWithError(w, http.StatusBadRequest, "limit must be between 1 and 100", err)
+			return
+		}
+		limit = parsed
+	}
+
+	var response types.ChirpListResponse
+	switch ranking := r.URL.Query().Get("ranking"); ranking {
+	case "", RankingLatest:
+		response, err = cfg.LatestChirps(r.Context(), userID, int32(limit))
+	case RankingTop:
+		response, err = cfg.topChirps(r.Context(), userID, limit)
+	default:
+		handlers.RespondWithError(w, http.StatusBadRequest, "ranking must be latest or top", nil)
+		return
+	}
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// topChirps returns the highest scoring recent chirps, at most limit of
+// them, best first as the viewer sees them. Candidates are loaded with a
+// bounded query and scored in process.
+func (cfg *Config) topChirps(ctx context.Context, viewerID uuid.UUID, limit int) (types.ChirpListResponse, error) {
+	now := time.Now()
+	candidates, err := cfg.DB.GetFeedCandidates(ctx, database.GetFeedCandidatesParams{
+		TenantID:      tenant.FromContext(ctx).ID,
+		ViewerID:      viewerID,
+		Since:         now.Add(-feedWindow),
+		MaxCandidates: feedMaxCandidates,
+	})
+	if err != nil {
+		return nil, err
+	}
+	affinities, err := cfg.DB.GetAuthorAffinity(ctx, database.GetAuthorAffinityParams{
+		ViewerID: viewerID,
+		Since:    now.Add(-affinityWindow),
+	})
+	if err != nil {
+		return nil, err
+	}
+	affinity := make(map[uuid.UUID]int64, len(affinities))
+	for _, row := range affinities {
+		affinity[row.AuthorID] = row.Interactions
+	}
+
+	ids := rankCandidates(candidates, affinity, now)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	if len(ids) == 0 {
+		return types.ChirpListResponse{}, nil
+	}
+
+	dbChirps, err := cfg.DB.GetChirpsByIDs(ctx, database.GetChirpsByIDsParams{
+		TenantID: tenant.FromContext(ctx).ID,
+		Ids:      ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+	rank := make(map[uuid.UUID]int, len(ids))
+	for i, id := range ids {
+		rank[id] = i
+	}
+	slices.SortFunc(dbChirps, func(a, b database.Chirp) int {
+		return cmp.Compare(rank[a.ID], rank[b.ID])
+	})
+	return cfg.buildChirpList(ctx, dbChirps, viewerID, true)
+}
+
+// rankCandidates orders chirp IDs by score, best first. Candidates come
+// newest first, which breaks ties.
+func rankCandidates(candidates []database.GetFeedCandidatesRow, affinity map[uuid.UUID]int64, now time.Time) []uuid.UUID {
+	scores := make(map[uuid.UUID]float64, len(candidates))
+	for _, candidate := range candidates {
+		scores[candidate.ID] = feedScore(candidate, affinity[candidate.UserID], now)
+	}
+	slices.SortStableFunc(candidates, func(a, b database.GetFeedCandidatesRow) int {
+		return cmp.Compare(scores[b.ID], scores[a.ID])
+	})
+
+	ids := make([]uuid.UUID, len(candidates))
+	for i, candidate := range candidates {
+		ids[i] = candidate.ID
+	}
+	return ids
+}
+
+// feedScore weighs a chirp's engagement, where reposts count more than
+// replies and replies more than likes, by the viewer's affinity for its
+// author, and halves the result every feedHalfLife. Both are dampened
+// logarithmically so a viral chirp or a favourite author can't take over
+// the feed.
+func feedScore(candidate database.GetFeedCandidatesRow, affinity int64, now time.Time) float64 {
+	engagement := float64(candidate.LikeCount) + 2*float64(candidate.ReplyCount) + 3*float64(candidate.RepostCount)
+	recency := math.Exp2(-now.Sub(candidate.PublishedAt).Hours() / feedHalfLife.Hours())
+	return (1 + math.Log1p(engagement)) * (1 + math.Log1p(float64(affinity))) * recency
+}
@@ -0,0 +1,132 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestRankCandidates(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	friend, stranger := uuid.New(), uuid.New()
+	candidate := func(author uuid.UUID, age time.Duration, likes int64) database.GetFeedCandidatesRow {
+		return database.GetFeedCandidatesRow{ID: uuid.New(), UserID: author, PublishedAt: now.Add(-age), LikeCount: likes}
+	}
+
+	fresh := candidate(stranger, 0, 0)
+	popular := candidate(stranger, 2*time.Hour, 50)
+	stale := candidate(stranger, 40*time.Hour, 500)
+	fromFriend := candidate(friend, 30*time.Minute, 0)
+	tied := candidate(stranger, 0, 0)
+
+	// Newest first, as the query returns them
+	candidates := []database.GetFeedCandidatesRow{fresh, tied, fromFriend, popular, stale}
+	got := rankCandidates(candidates, map[uuid.UUID]int64{friend: 10}, now)
+	want := []uuid.UUID{popular.ID, fromFriend.ID, fresh.ID, tied.ID, stale.ID}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("rankCandidates() position %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestHandlerFeed(t *testing.T) {
+	cfg := newBenchConfig(3)
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantChirps int
+	}{
+		{query: "", wantStatus: http.StatusOK, wantChirps: 3},
+		{query: "?ranking=latest&limit=2", wantStatus: http.StatusOK, wantChirps: 2},
+		{query: "?ranking=top", wantStatus: http.StatusOK, wantChirps: 2},
+		{query: "?ranking=top&limit=1", wantStatus: http.StatusOK, wantChirps: 1},
+		{query: "?ranking=random", wantStatus: http.StatusBadRequest},
+		{query: "?limit=101", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/feed"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			cfg.HandlerFeed(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			var chirps []types.ChirpCreateResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &chirps); err != nil {
+				t.Fatal(err)
+			}
+			if len(chirps) != tt.wantChirps {
+				t.Errorf("got %d chirps, want %d", len(chirps), tt.wantChirps)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	cfg.HandlerFeed(rec, httptest.NewRequest(http.MethodGet, "/api/feed", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestHandlerFeedOnlyFollowed(t *testing.T) {
+	cfg := newBenchConfig(3)
+	follower := uuid.New()
+	token, err := auth.MakeJWT(follower, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := func(query string) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/feed"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		cfg.HandlerFeed(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d, want %d; body = %s", query, rec.Code, http.StatusOK, rec.Body)
+		}
+		var chirps []types.ChirpCreateResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &chirps); err != nil {
+			t.Fatal(err)
+		}
+		return len(chirps)
+	}
+
+	// Chirps of users the viewer doesn't follow stay out of both orderings
+	for _, query := range []string{"?ranking=latest", "?ranking=top"} {
+		if got := feed(query); got != 0 {
+			t.Errorf("%s before following: got %d chirps, want 0", query, got)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/users/"+benchUserID.String()+"/follow", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	cfg.HandlerFollow(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("follow: status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+
+	for query, want := range map[string]int{"?ranking=latest": 3, "?ranking=top": 2} {
+		if got := feed(query); got != want {
+			t.Errorf("%s after following: got %d chirps, want %d", query, got, want)
+		}
+	}
+}
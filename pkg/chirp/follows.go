@@ -0,0 +1,210 @@
+package chirp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// HandlerFollow handles POST /api/users/{id}/follow requests, which follow
+// the user, and DELETE requests, which unfollow them. Both are idempotent.
+func (cfg *Config) HandlerFollow(w http.ResponseWriter, r *http.Request) {
+	idString, subresource := handlers.SplitResourcePath(r.URL.Path, "/api/users/")
+	if subresource != "follow" {
+		handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+		return
+	}
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+		return
+	}
+
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	followeeID, err := uuid.Parse(idString)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		// Unfollowing works even once the user has been deactivated
+		err = cfg.DB.UnfollowUser(r.Context(), database.UnfollowUserParams{
+			FollowerID: userID,
+			FolloweeID: followeeID,
+		})
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update follow", err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if followeeID == userID {
+		handlers.RespondWithError(w, http.StatusBadRequest, "You can't follow yourself", nil)
+		return
+	}
+	found, err := cfg.DB.IsActiveUserInTenant(r.Context(), database.IsActiveUserInTenantParams{
+		ID:       followeeID,
+		TenantID: tenant.FromContext(r.Context()).ID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update follow", err)
+		return
+	}
+	if !found {
+		handlers.RespondWithError(w, http.StatusNotFound, "User not found", nil)
+		return
+	}
+
+	err = cfg.DB.FollowUser(r.Context(), database.FollowUserParams{
+		FollowerID: userID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update follow", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandlerFollowList handles GET /api/users/{id}/followers and
+// /api/users/{id}/following requests, listing the active users who follow
+// the user or whom the user follows, most recent follow first. Pages hold
+// ?limit= users (default 20, at most 100); ?before_id= continues after the
+// last user of the previous page, and a Link header points at the next page
+// while there may be one.
+func (cfg *Config) HandlerFollowList(w http.ResponseWriter, r *http.Request) {
+	idString, subresource := handlers.SplitResourcePath(r.URL.Path, "/api/users/")
+	if subresource != "followers" && subresource != "following" {
+		handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+		return
+	}
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	userID, err := uuid.Parse(idString)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	limit := timelineDefaultLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > timelineMaxLimit {
+			handlers.RespondWithError(w, http.StatusBadRequest, "limit must be between 1 and 100", err)
+			return
+		}
+		limit = parsed
+	}
+
+	found, err := cfg.DB.IsActiveUserInTenant(r.Context(), database.IsActiveUserInTenantParams{
+		ID:       userID,
+		TenantID: tenant.FromContext(r.Context()).ID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve follows", err)
+		return
+	}
+	if !found {
+		handlers.RespondWithError(w, http.StatusNotFound, "User not found", nil)
+		return
+	}
+
+	// Start from the most recent follow, or after the cursor user's
+	params := database.GetFollowersParams{
+		UserID:           userID,
+		BeforeFollowedAt: time.Now().UTC().Add(time.Hour),
+		BeforeID:         uuid.Max,
+		PageSize:         int32(limit),
+	}
+	if beforeID := r.URL.Query().Get("before_id"); beforeID != "" {
+		parsedID, err := uuid.Parse(beforeID)
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusBadRequest, "Invalid before_id format", err)
+			return
+		}
+		cursor := database.GetFollowParams{FollowerID: parsedID, FolloweeID: userID}
+		if subresource == "following" {
+			cursor = database.GetFollowParams{FollowerID: userID, FolloweeID: parsedID}
+		}
+		follow, err := cfg.DB.GetFollow(r.Context(), cursor)
+		if err != nil {
+			if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+				handlers.RespondWithError(w, http.StatusBadRequest, "before_id must be one of the listed users", nil)
+			} else {
+				handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve follows", err)
+			}
+			return
+		}
+		params.BeforeFollowedAt, params.BeforeID = follow.CreatedAt, parsedID
+	}
+
+	var entries []types.FollowListEntry
+	if subresource == "followers" {
+		rows, err := cfg.DB.GetFollowers(r.Context(), params)
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve follows", err)
+			return
+		}
+		entries = make([]types.FollowListEntry, len(rows))
+		for i, row := range rows {
+			entries[i] = followListEntry(database.GetFollowingRow(row))
+		}
+	} else {
+		rows, err := cfg.DB.GetFollowing(r.Context(), database.GetFollowingParams(params))
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve follows", err)
+			return
+		}
+		entries = make([]types.FollowListEntry, len(rows))
+		for i, row := range rows {
+			entries[i] = followListEntry(row)
+		}
+	}
+
+	if len(entries) == limit {
+		next := url.Values{
+			"before_id": {entries[len(entries)-1].ID.String()},
+			"limit":     {strconv.Itoa(limit)},
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, next.Encode()))
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, entries)
+}
+
+// followListEntry converts a row of a follower or following list
+func followListEntry(row database.GetFollowingRow) types.FollowListEntry {
+	return types.FollowListEntry{
+		UserProfile: types.UserProfile{
+			ID:          row.ID,
+			CreatedAt:   types.NewTimestamp(row.CreatedAt),
+			Username:    row.Username.String,
+			Verified:    row.Verified,
+			DisplayName: row.DisplayName,
+			Bio:         row.Bio,
+			Location:    row.Location,
+			Website:     row.Website,
+			AvatarURL:   row.AvatarUrl,
+		},
+		FollowedAt: types.NewTimestamp(row.FollowedAt),
+	}
+}
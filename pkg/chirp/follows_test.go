@@ -0,0 +1,137 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestHandlerFollow(t *testing.T) {
+	cfg := newBenchConfig(0)
+	follower := uuid.New()
+	token, err := auth.MakeJWT(follower, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	selfToken, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	followers := func() []types.FollowListEntry {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		cfg.HandlerUsers(rec, httptest.NewRequest(http.MethodGet, "/api/users/"+benchUserID.String()+"/followers", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("followers: status = %d, want %d; body = %s", rec.Code, http.StatusOK, rec.Body)
+		}
+		var entries []types.FollowListEntry
+		if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+			t.Fatalf("followers response is not a list: %v", err)
+		}
+		return entries
+	}
+
+	steps := []struct {
+		name          string
+		method        string
+		userID        string
+		token         string
+		wantStatus    int
+		wantFollowers int
+	}{
+		{name: "anonymous", method: http.MethodPost, userID: benchUserID.String(), wantStatus: http.StatusUnauthorized},
+		{name: "follow", method: http.MethodPost, userID: benchUserID.String(), token: token, wantStatus: http.StatusNoContent, wantFollowers: 1},
+		{name: "follow again", method: http.MethodPost, userID: benchUserID.String(), token: token, wantStatus: http.StatusNoContent, wantFollowers: 1},
+		{name: "follow self", method: http.MethodPost, userID: benchUserID.String(), token: selfToken, wantStatus: http.StatusBadRequest, wantFollowers: 1},
+		{name: "unknown user", method: http.MethodPost, userID: uuid.NewString(), token: token, wantStatus: http.StatusNotFound, wantFollowers: 1},
+		{name: "invalid id", method: http.MethodPost, userID: "nobody", token: token, wantStatus: http.StatusBadRequest, wantFollowers: 1},
+		{name: "wrong method", method: http.MethodPut, userID: benchUserID.String(), token: token, wantStatus: http.StatusMethodNotAllowed, wantFollowers: 1},
+		{name: "unfollow", method: http.MethodDelete, userID: benchUserID.String(), token: token, wantStatus: http.StatusNoContent, wantFollowers: 0},
+		{name: "unfollow again", method: http.MethodDelete, userID: benchUserID.String(), token: token, wantStatus: http.StatusNoContent, wantFollowers: 0},
+	}
+	for _, step := range steps {
+		req := httptest.NewRequest(step.method, "/api/users/"+step.userID+"/follow", nil)
+		if step.token != "" {
+			req.Header.Set("Authorization", "Bearer "+step.token)
+		}
+		rec := httptest.NewRecorder()
+		cfg.HandlerUsers(rec, req)
+		if rec.Code != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d; body = %s", step.name, rec.Code, step.wantStatus, rec.Body)
+		}
+
+		entries := followers()
+		if len(entries) != step.wantFollowers {
+			t.Fatalf("%s: %d followers, want %d", step.name, len(entries), step.wantFollowers)
+		}
+		if len(entries) == 1 && (entries[0].ID != follower || entries[0].FollowedAt.IsZero()) {
+			t.Errorf("%s: follower = %+v, want %v with followed_at", step.name, entries[0], follower)
+		}
+	}
+}
+
+func TestHandlerFollowList(t *testing.T) {
+	cfg := newBenchConfig(0)
+	for range 3 {
+		token, err := auth.MakeJWT(uuid.New(), benchSecret, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/api/users/"+benchUserID.String()+"/follow", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		cfg.HandlerFollow(rec, req)
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("follow: status = %d, want %d", rec.Code, http.StatusNoContent)
+		}
+	}
+
+	base := "/api/users/" + benchUserID.String()
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantCount  int
+		wantNext   bool
+	}{
+		{name: "followers", method: http.MethodGet, path: base + "/followers", wantStatus: http.StatusOK, wantCount: 3},
+		{name: "full page", method: http.MethodGet, path: base + "/followers?limit=2", wantStatus: http.StatusOK, wantCount: 2, wantNext: true},
+		{name: "following", method: http.MethodGet, path: base + "/following", wantStatus: http.StatusOK, wantCount: 0},
+		{name: "unknown user", method: http.MethodGet, path: "/api/users/" + uuid.NewString() + "/followers", wantStatus: http.StatusNotFound},
+		{name: "invalid limit", method: http.MethodGet, path: base + "/followers?limit=101", wantStatus: http.StatusBadRequest},
+		{name: "invalid before_id", method: http.MethodGet, path: base + "/followers?before_id=nobody", wantStatus: http.StatusBadRequest},
+		{name: "before_id not listed", method: http.MethodGet, path: base + "/followers?before_id=" + uuid.NewString(), wantStatus: http.StatusBadRequest},
+		{name: "wrong method", method: http.MethodPost, path: base + "/followers", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			cfg.HandlerUsers(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var entries []types.FollowListEntry
+			if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil || entries == nil {
+				t.Fatalf("response is not a list: %v; body = %s", err, rec.Body)
+			}
+			if len(entries) != tt.wantCount {
+				t.Errorf("%d users, want %d", len(entries), tt.wantCount)
+			}
+			if next := rec.Header().Get("Link") != ""; next != tt.wantNext {
+				t.Errorf("Link header = %q, want next page %v", rec.Header().Get("Link"), tt.wantNext)
+			}
+		})
+	}
+}
@@ -1,13 +1,28 @@
 package chirp
 
 import (
-	"encoding/json"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"math"
 	"net/http"
-	"sort"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
 	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/entitlements"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/jobs"
+	"github.com/kai-xlr/neo_chirpy/internal/linkpreview"
+	"github.com/kai-xlr/neo_chirpy/internal/mailer"
+	"github.com/kai-xlr/neo_chirpy/internal/moderation"
+	"github.com/kai-xlr/neo_chirpy/internal/profanity"
+	"github.com/kai-xlr/neo_chirpy/internal/ratelimit"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
 	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
 	"github.com/kai-xlr/neo_chirpy/pkg/types"
 	"github.com/kai-xlr/neo_chirpy/pkg/validation"
@@ -15,8 +30,74 @@ import (
 
 // Config holds the configuration needed for chirp handlers
 type Config struct {
-	DB        *database.Queries
-	JWTSecret string
+	DB             *database.Queries
+	JWTSecret      string
+	RequireAltText bool
+	AllowEdits     bool
+	Events         *events.Bus
+
+	// Reactions are the emoji users may react to chirps with
+	Reactions validation.Reactions
+
+	// Counting measures chirp length against the limit; the zero value
+	// counts runes
+	Counting validation.Counting
+
+	// ArchiveAfterMonths moves chirps older than this many months to the
+	// archive tables. Zero disables archiving.
+	ArchiveAfterMonths int
+
+	// SortableIDs gives new chirps time-ordered UUIDv7 IDs instead of
+	// random UUIDv4 ones
+	SortableIDs bool
+
+	// Views counts impressions of served chirps; nil disables counting
+	Views *ViewBuffer
+
+	// Profanity masks banned words in chirp bodies; nil masks nothing
+	Profanity *profanity.Filter
+
+	// Store keeps chirps created with an Idempotency-Key for retries; nil
+	// ignores the header
+	Store cache.Store
+
+	// Languages detects the language of new and edited chirps; nil leaves
+	// it unset
+	Languages LanguageDetector
+
+	// Previews fetches previews of links in chirps; nil disables them
+	Previews *linkpreview.Fetcher
+
+	// Jobs runs background work such as fetching link previews
+	Jobs *jobs.Runner
+
+	// PostLimiter caps how many chirps each user may post per minute; nil
+	// disables the cap
+	PostLimiter *ratelimit.Limiter
+
+	// Entitlements restricts accounts on probation, which post under
+	// ProbationLimiter instead of PostLimiter
+	Entitlements     entitlements.Policy
+	ProbationLimiter *ratelimit.Limiter
+
+	// Classifier scores chirps after they are published, hiding the ones
+	// it removes and emailing their author through Mailer with Templates;
+	// nil disables scoring
+	Classifier *moderation.Classifier
+	Mailer     mailer.Mailer
+	Templates  *mailer.Renderer
+}
+
+// HandlerChirps dispatches /api/chirps requests based on HTTP method
+func (cfg *Config) HandlerChirps(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		cfg.HandlerGet(w, r)
+	case http.MethodPost:
+		cfg.HandlerCreate(w, r)
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+	}
 }
 
 // HandlerCreate handles POST /api/chirps requests.
@@ -26,46 +107,253 @@ func (cfg *Config) HandlerCreate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Extract and validate JWT token
-	tokenString, err := auth.GetBearerToken(r.Header)
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
 	if err != nil {
 		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
 		return
 	}
+	userID := principal.UserID
 
-	userID, err := auth.ValidateJWT(tokenString, cfg.JWTSecret)
-	if err != nil {
-		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+	// Parse JSON from request body into our struct, keeping the body to
+	// recognise retries
+	body, readErr := io.ReadAll(r.Body)
+	if readErr != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgDecodeParams, readErr)
 		return
 	}
-
 	var request types.ChirpCreateRequest
-	// Parse JSON from request body into our struct
-	decodeErr := json.NewDecoder(r.Body).Decode(&request)
-	if decodeErr != nil {
-		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgDecodeParams, decodeErr)
+	if !handlers.UnmarshalJSON(w, body, &request) {
 		return
 	}
 
-	// Validate chirp body against business rules (max length, empty check)
-	if validationErr := validation.ValidateChirpBody(request.Body); validationErr != nil {
-		handlers.RespondWithError(w, http.StatusBadRequest, validationErr.Error(), validationErr)
+	// A retry with the same Idempotency-Key gets the chirp created first
+	idempotent, ok := cfg.startIdempotent(w, r, userID, body)
+	if !ok {
+		return
+	}
+
+	// Replays above don't reach createChirp, so they don't count against
+	// the posting limit
+	response, ok := cfg.createChirp(w, r, userID, request)
+	if !ok {
+		idempotent.finish(r.Context(), nil)
 		return
 	}
+	idempotent.finish(r.Context(), &response)
+	handlers.RespondWithJSON(w, http.StatusCreated, response)
+}
+
+// createChirp validates and stores a new chirp for userID, counting it
+// against the posting limit. On failure it responds with the error and
+// returns false; on success the caller writes the response.
+func (cfg *Config) createChirp(w http.ResponseWriter, r *http.Request, userID uuid.UUID, request types.ChirpCreateRequest) (types.ChirpCreateResponse, bool) {
+	if !cfg.allowPost(w, r, userID) {
+		return types.ChirpCreateResponse{}, false
+	}
+
+	// Validate chirp body against business rules (max length, empty check)
+	if validationErr := validation.ValidateChirpBody(request.Body, cfg.Counting); validationErr != nil {
+		cfg.respondBodyError(w, request.Body, validationErr)
+		return types.ChirpCreateResponse{}, false
+	}
+
+	// Cap mentions and hashtags to prevent mention-bombing
+	if tagErr := validation.ValidateChirpTags(request.Body); tagErr != nil {
+		respondTagError(w, tagErr)
+		return types.ChirpCreateResponse{}, false
+	}
+
+	// Accounts on probation can't post links
+	if !cfg.allowLinks(w, r, userID, request.Body) {
+		return types.ChirpCreateResponse{}, false
+	}
+
+	// Validate the optional undo window
+	if delayErr := validation.ValidateChirpDelay(request.DelaySeconds); delayErr != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, delayErr.Error(), delayErr)
+		return types.ChirpCreateResponse{}, false
+	}
+
+	// A scheduled chirp stays pending until its time, like one in an undo
+	// window, and is announced by the scheduler job
+	delaySeconds := request.DelaySeconds
+	if request.ScheduledAt != nil {
+		if delaySeconds != 0 {
+			handlers.RespondWithError(w, http.StatusBadRequest, "delay_seconds and scheduled_at can't be combined", nil)
+			return types.ChirpCreateResponse{}, false
+		}
+		if scheduleErr := validation.ValidateChirpSchedule(*request.ScheduledAt, time.Now()); scheduleErr != nil {
+			handlers.RespondWithError(w, http.StatusBadRequest, scheduleErr.Error(), scheduleErr)
+			return types.ChirpCreateResponse{}, false
+		}
+		delaySeconds = int32(math.Ceil(time.Until(*request.ScheduledAt).Seconds()))
+	}
+
+	// A content warning marks the chirp as sensitive
+	contentWarning, warningErr := validation.NormalizeContentWarning(request.ContentWarning)
+	if warningErr != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, warningErr.Error(), warningErr)
+		return types.ChirpCreateResponse{}, false
+	}
+
+	// Validate media attachments and their alt text
+	if mediaErr := validateMedia(request.Media, cfg.RequireAltText); mediaErr != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, mediaErr.Error(), mediaErr)
+		return types.ChirpCreateResponse{}, false
+	}
+
+	// Replies must be to a chirp the user can see
+	var parentChirpID uuid.NullUUID
+	if request.ParentChirpID != nil {
+		if parentErr := cfg.checkReplyParent(r.Context(), *request.ParentChirpID, userID); parentErr != nil {
+			if errors.Is(parentErr, ErrParentNotFound) {
+				handlers.RespondWithError(w, http.StatusBadRequest, parentErr.Error(), parentErr)
+				return types.ChirpCreateResponse{}, false
+			}
+			if errors.Is(parentErr, ErrThreadLocked) {
+				respondThreadLocked(w)
+				return types.ChirpCreateResponse{}, false
+			}
+			handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgCreateChirp, parentErr)
+			return types.ChirpCreateResponse{}, false
+		}
+		parentChirpID = uuid.NullUUID{UUID: *request.ParentChirpID, Valid: true}
+	}
 
 	// Remove profanity from the chirp body
-	cleanedBody := CleanChirp(request.Body)
+	cleanedBody := cfg.Profanity.Clean(request.Body)
+
+	// Attribute the chirp to the app it was posted from
+	source, oauthClientID, sourceErr := cfg.chirpSource(r)
+	if sourceErr != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgCreateChirp, sourceErr)
+		return types.ChirpCreateResponse{}, false
+	}
+
+	chirpID, idErr := cfg.newChirpID()
+	if idErr != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgCreateChirp, idErr)
+		return types.ChirpCreateResponse{}, false
+	}
 
 	// Insert chirp into database using generated sqlc code
 	createdChirp, dbErr := cfg.DB.CreateChirp(r.Context(), database.CreateChirpParams{
-		Body:   cleanedBody,
-		UserID: userID,
+		ID:             chirpID,
+		Body:           cleanedBody,
+		UserID:         userID,
+		DelaySeconds:   delaySeconds,
+		TenantID:       tenant.FromContext(r.Context()).ID,
+		Sensitive:      request.Sensitive || contentWarning != "",
+		Source:         source,
+		OauthClientID:  oauthClientID,
+		ParentChirpID:  parentChirpID,
+		Language:       cfg.detectLanguage(cleanedBody),
+		ContentWarning: contentWarning,
 	})
 	if dbErr != nil {
 		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgCreateChirp, dbErr)
+		return types.ChirpCreateResponse{}, false
+	}
+
+	// Queue a scheduled chirp for its exact time, removing it again if that
+	// fails
+	if request.ScheduledAt != nil {
+		scheduledChirp, scheduleErr := cfg.DB.ScheduleChirp(r.Context(), database.ScheduleChirpParams{
+			ChirpID:   createdChirp.ID,
+			PublishAt: request.ScheduledAt.UTC(),
+		})
+		if scheduleErr != nil {
+			cfg.DB.DeleteChirp(r.Context(), createdChirp.ID)
+			handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgCreateChirp, scheduleErr)
+			return types.ChirpCreateResponse{}, false
+		}
+		createdChirp = scheduledChirp
+	}
+
+	// Store media attachments, removing the chirp again if that fails
+	createdMedia, mediaErr := cfg.createMedia(r.Context(), createdChirp.ID, request.Media)
+	if mediaErr != nil {
+		cfg.DB.DeleteChirp(r.Context(), createdChirp.ID)
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgCreateChirp, mediaErr)
+		return types.ChirpCreateResponse{}, false
+	}
+
+	// Store hashtags, removing the chirp again if that fails
+	if tags := validation.Hashtags(createdChirp.Body); len(tags) > 0 {
+		if tagErr := cfg.setHashtags(r.Context(), createdChirp.ID, tags); tagErr != nil {
+			cfg.DB.DeleteChirp(r.Context(), createdChirp.ID)
+			handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgCreateChirp, tagErr)
+			return types.ChirpCreateResponse{}, false
+		}
+	}
+
+	// Store mentions, removing the chirp again if that fails
+	if handles := validation.Mentions(createdChirp.Body); len(handles) > 0 {
+		if mentionErr := cfg.setMentions(r.Context(), createdChirp, handles); mentionErr != nil {
+			cfg.DB.DeleteChirp(r.Context(), createdChirp.ID)
+			handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgCreateChirp, mentionErr)
+			return types.ChirpCreateResponse{}, false
+		}
+	}
+
+	// Store the link to preview, removing the chirp again if that fails
+	if linkErr := cfg.setChirpLink(r.Context(), createdChirp.ID, createdChirp.Body); linkErr != nil {
+		cfg.DB.DeleteChirp(r.Context(), createdChirp.ID)
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgCreateChirp, linkErr)
+		return types.ChirpCreateResponse{}, false
+	}
+
+	// Invite the co-author, removing the chirp again if that fails
+	if request.CoauthorID != nil {
+		if inviteErr := cfg.inviteCoauthor(r.Context(), createdChirp, *request.CoauthorID); inviteErr != nil {
+			cfg.DB.DeleteChirp(r.Context(), createdChirp.ID)
+			if errors.Is(inviteErr, ErrCoauthorSelf) || errors.Is(inviteErr, ErrCoauthorNotFound) {
+				handlers.RespondWithError(w, http.StatusBadRequest, inviteErr.Error(), inviteErr)
+				return types.ChirpCreateResponse{}, false
+			}
+			handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgCreateChirp, inviteErr)
+			return types.ChirpCreateResponse{}, false
+		}
+	}
+
+	if request.ScheduledAt == nil {
+		cfg.publishChirpCreated(createdChirp)
+	}
+	cfg.classifyChirp(createdChirp)
+
+	response := []types.ChirpCreateResponse{handlers.BuildChirpResponse(createdChirp)}
+	response[0].Media = handlers.BuildMediaResponse(createdMedia)
+	if err := cfg.attachAuthors(r.Context(), response); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirp, err)
+		return types.ChirpCreateResponse{}, false
+	}
+	return response[0], true
+}
+
+// respondBodyError reports an invalid chirp body. A body over the limit
+// gets an error code and its counted length, so clients can show how far
+// over it is.
+func (cfg *Config) respondBodyError(w http.ResponseWriter, body string, err error) {
+	if err != validation.ErrChirpTooLong {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
 		return
 	}
+	handlers.RespondWithJSON(w, http.StatusBadRequest, types.ChirpTooLongError{
+		Error:     err.Error(),
+		Code:      types.ErrCodeChirpTooLong,
+		Length:    cfg.Counting.Length(body),
+		MaxLength: validation.MaxChirpLength,
+	})
+}
 
-	handlers.RespondWithJSON(w, http.StatusCreated, handlers.BuildChirpResponse(createdChirp))
+// respondTagError reports a chirp with too many mentions or hashtags with
+// an error code clients can match on
+func respondTagError(w http.ResponseWriter, err error) {
+	errCode := types.ErrCodeTooManyHashtags
+	if err == validation.ErrTooManyMentions {
+		errCode = types.ErrCodeTooManyMentions
+	}
+	handlers.RespondWithErrorCode(w, http.StatusBadRequest, errCode, err.Error(), err)
 }
 
 // HandlerGet handles GET /api/chirps requests.
@@ -89,47 +377,183 @@ func (cfg *Config) HandlerGet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var dbChirps []database.Chirp
-	var dbErr error
+	// Identify the viewer, if any, so their muted words can be applied
+	viewerID, authenticated, err := cfg.optionalViewer(r)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
 
+	var authorID uuid.NullUUID
 	if authorIDStr != "" {
 		// Parse author_id as UUID
-		authorID, parseErr := uuid.Parse(authorIDStr)
+		parsedID, parseErr := uuid.Parse(authorIDStr)
 		if parseErr != nil {
 			handlers.RespondWithError(w, http.StatusBadRequest, "Invalid author_id format", parseErr)
 			return
 		}
+		authorID = uuid.NullUUID{UUID: parsedID, Valid: true}
+	}
 
-		// Retrieve chirps for specific author (ascending order is fine, we'll sort in-memory)
-		dbChirps, dbErr = cfg.DB.GetChirpsByAuthorAsc(r.Context(), authorID)
-	} else {
-		// Retrieve all chirps (ascending order is fine, we'll sort in-memory)
-		dbChirps, dbErr = cfg.DB.GetChirpsAsc(r.Context())
+	// Optional hashtag filter, with or without the leading #
+	tag := r.URL.Query().Get("tag")
+	if tag != "" {
+		var ok bool
+		if tag, ok = validation.NormalizeHashtag(tag); !ok {
+			handlers.RespondWithError(w, http.StatusBadRequest, "Invalid tag", nil)
+			return
+		}
+	}
+
+	// Optional language filter, matching the language detected when each
+	// chirp was posted
+	var language sql.NullString
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		code, ok := validation.NormalizeLanguage(lang)
+		if !ok {
+			handlers.RespondWithError(w, http.StatusBadRequest, "Invalid lang", nil)
+			return
+		}
+		language = sql.NullString{String: code, Valid: true}
+	}
+
+	// include_sensitive=false leaves out sensitive chirps altogether,
+	// whatever the viewer's preference
+	includeSensitive := true
+	if include := r.URL.Query().Get("include_sensitive"); include != "" {
+		parsed, parseErr := strconv.ParseBool(include)
+		if parseErr != nil {
+			handlers.RespondWithError(w, http.StatusBadRequest, "Invalid include_sensitive", parseErr)
+			return
+		}
+		includeSensitive = parsed
+	}
+
+	var dbChirps []database.Chirp
+	var dbErr error
+
+	switch {
+	case tag != "":
+		// Retrieve chirps with the tag, optionally by one author
+		params := database.GetChirpsByHashtagAscParams{
+			TenantID: tenant.FromContext(r.Context()).ID,
+			Tag:      tag,
+			UserID:   authorID,
+			Language: language,
+		}
+		if sortParam == "desc" {
+			dbChirps, dbErr = cfg.DB.GetChirpsByHashtagDesc(r.Context(), database.GetChirpsByHashtagDescParams(params))
+		} else {
+			dbChirps, dbErr = cfg.DB.GetChirpsByHashtagAsc(r.Context(), params)
+		}
+	case authorID.Valid:
+		// Retrieve chirps for specific author, ordered by the database
+		params := database.GetChirpsByAuthorAscParams{
+			TenantID: tenant.FromContext(r.Context()).ID,
+			UserID:   authorID.UUID,
+			Language: language,
+		}
+		if sortParam == "desc" {
+			dbChirps, dbErr = cfg.DB.GetChirpsByAuthorDesc(r.Context(), database.GetChirpsByAuthorDescParams(params))
+		} else {
+			dbChirps, dbErr = cfg.DB.GetChirpsByAuthorAsc(r.Context(), params)
+		}
+	default:
+		params := database.GetChirpsAscParams{
+			TenantID: tenant.FromContext(r.Context()).ID,
+			Language: language,
+		}
+		if sortParam == "desc" {
+			dbChirps, dbErr = cfg.DB.GetChirpsDesc(r.Context(), database.GetChirpsDescParams(params))
+		} else {
+			dbChirps, dbErr = cfg.DB.GetChirpsAsc(r.Context(), params)
+		}
 	}
 
 	if dbErr != nil {
 		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, dbErr)
 		return
 	}
+	if !includeSensitive {
+		dbChirps = withoutSensitive(dbChirps)
+	}
 
-	// Sort chirps in-memory based on the sort parameter
-	if sortParam == "desc" {
-		sort.Slice(dbChirps, func(i, j int) bool {
-			return dbChirps[i].CreatedAt.After(dbChirps[j].CreatedAt)
-		})
-	} else {
-		// sortParam == "asc" - already sorted this way, but ensure consistency
-		sort.Slice(dbChirps, func(i, j int) bool {
-			return dbChirps[i].CreatedAt.Before(dbChirps[j].CreatedAt)
-		})
+	response, err := cfg.buildChirpList(r.Context(), dbChirps, viewerID, authenticated)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// buildChirpList converts chirps to a list response as the viewer sees it:
+// chirps matching their muted words are dropped, related records attached
+// and sensitive content they chose to hide omitted
+func (cfg *Config) buildChirpList(ctx context.Context, dbChirps []database.Chirp, viewerID uuid.UUID, authenticated bool) (types.ChirpListResponse, error) {
+	// Exclude chirps matching the viewer's muted words
+	if authenticated {
+		mutedWords, err := cfg.DB.GetMutedWords(ctx, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		dbChirps = FilterMuted(dbChirps, mutedWords)
 	}
 
 	// Convert database chirps to API response format using helper function
 	response := handlers.BuildChirpListResponse(dbChirps)
-	handlers.RespondWithJSON(w, http.StatusOK, response)
+	if err := cfg.attachMedia(ctx, response); err != nil {
+		return nil, err
+	}
+	if err := cfg.attachLinkPreviews(ctx, response); err != nil {
+		return nil, err
+	}
+	if err := cfg.attachAuthors(ctx, response); err != nil {
+		return nil, err
+	}
+	if err := cfg.attachCoauthors(ctx, response); err != nil {
+		return nil, err
+	}
+	if err := cfg.attachReactions(ctx, response); err != nil {
+		return nil, err
+	}
+	if err := cfg.attachLikes(ctx, response, viewerID); err != nil {
+		return nil, err
+	}
+	if err := cfg.attachViews(ctx, response); err != nil {
+		return nil, err
+	}
+	if err := cfg.attachOriginals(ctx, response, viewerID, authenticated); err != nil {
+		return nil, err
+	}
+	response = dropOrphanedReposts(response)
+
+	// Omit sensitive content the viewer chose to hide
+	preference, err := cfg.sensitivePreference(ctx, viewerID, authenticated)
+	if err != nil {
+		return nil, err
+	}
+	HideSensitive(response, viewerID, preference)
+
+	cfg.Views.Record(response)
+	return types.ChirpListResponse(response), nil
 }
 
-// HandlerByID handles GET and DELETE /api/chirps/{id} requests.
+// LatestChirps returns the newest chirps of the users the viewer follows and
+// their own, at most limit of them, newest first as the viewer sees them
+func (cfg *Config) LatestChirps(ctx context.Context, viewerID uuid.UUID, limit int32) (types.ChirpListResponse, error) {
+	dbChirps, err := cfg.DB.GetLatestChirps(ctx, database.GetLatestChirpsParams{
+		TenantID: tenant.FromContext(ctx).ID,
+		ViewerID: viewerID,
+		PageSize: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cfg.buildChirpList(ctx, dbChirps, viewerID, true)
+}
+
+// HandlerByID handles GET, PUT and DELETE /api/chirps/{id} requests and
+// dispatches sub-resources such as /api/chirps/{id}/history.
 func (cfg *Config) HandlerByID(w http.ResponseWriter, r *http.Request) {
 	// Extract chirp ID from URL path (common to both GET and DELETE)
 	path := r.URL.Path
@@ -138,8 +562,8 @@ func (cfg *Config) HandlerByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Extract ID from path "/api/chirps/{id}"
-	chirpID := handlers.ExtractIDFromPath(path, "/api/chirps/")
+	// Extract ID and optional sub-resource from path "/api/chirps/{id}[/{subresource}]"
+	chirpID, subresource := handlers.SplitResourcePath(path, "/api/chirps/")
 	if chirpID == "" {
 		handlers.RespondWithError(w, http.StatusBadRequest, "Chirp ID is required", nil)
 		return
@@ -152,9 +576,86 @@ func (cfg *Config) HandlerByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	switch subresource {
+	case "":
+	case "history":
+		if !handlers.RequireMethod(w, r, http.MethodGet) {
+			return
+		}
+		cfg.handlerHistory(w, r, parsedID)
+		return
+	case "stats":
+		if !handlers.RequireMethod(w, r, http.MethodGet) {
+			return
+		}
+		cfg.handlerStats(w, r, parsedID)
+		return
+	case "coauthor":
+		if !handlers.RequireMethod(w, r, http.MethodPut) {
+			return
+		}
+		cfg.handlerCoauthor(w, r, parsedID)
+		return
+	case "reactions":
+		cfg.handlerReactions(w, r, parsedID)
+		return
+	case "like":
+		cfg.handlerLike(w, r, parsedID)
+		return
+	case "repost":
+		if !handlers.RequireMethod(w, r, http.MethodPost) {
+			return
+		}
+		cfg.handlerRepost(w, r, parsedID)
+		return
+	case "replies":
+		if !handlers.RequireMethod(w, r, http.MethodGet) {
+			return
+		}
+		cfg.handlerReplies(w, r, parsedID)
+		return
+	case "conversation":
+		if !handlers.RequireMethod(w, r, http.MethodGet) {
+			return
+		}
+		cfg.handlerConversation(w, r, parsedID)
+		return
+	case "sensitive":
+		if !handlers.RequireMethod(w, r, http.MethodPut) {
+			return
+		}
+		cfg.handlerSensitive(w, r, parsedID)
+		return
+	case "restore":
+		if !handlers.RequireMethod(w, r, http.MethodPost) {
+			return
+		}
+		cfg.handlerRestore(w, r, parsedID)
+		return
+	case "report":
+		if !handlers.RequireMethod(w, r, http.MethodPost) {
+			return
+		}
+		cfg.handlerReport(w, r, parsedID)
+		return
+	default:
+		// /api/chirps/{id}/history/{rev}/diff
+		if rev, ok := revisionDiffPath(subresource); ok {
+			if !handlers.RequireMethod(w, r, http.MethodGet) {
+				return
+			}
+			cfg.handlerRevisionDiff(w, r, parsedID, rev)
+			return
+		}
+		handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		cfg.handlerByIDGet(w, r, parsedID)
+	case http.MethodPut:
+		cfg.handlerByIDUpdate(w, r, parsedID)
 	case http.MethodDelete:
 		cfg.handlerByIDDelete(w, r, parsedID)
 	default:
@@ -164,59 +665,156 @@ func (cfg *Config) HandlerByID(w http.ResponseWriter, r *http.Request) {
 
 // handlerByIDGet handles GET /api/chirps/{id} requests.
 func (cfg *Config) handlerByIDGet(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
-	// Retrieve chirp from database
-	dbChirp, err := cfg.DB.GetChirpByID(r.Context(), chirpID)
-	if err != nil {
-		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
-			handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
-		} else {
-			handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirp, err)
-		}
+	dbChirp, archived, ok := cfg.getVisibleChirp(w, r, chirpID)
+	if !ok {
 		return
 	}
 
-	handlers.RespondWithJSON(w, http.StatusOK, handlers.BuildChirpResponse(dbChirp))
-}
+	// An invalid token was already accepted as anonymous when the chirp
+	// was looked up, so it only loses liked_by_me here
+	viewerID, authenticated, _ := cfg.optionalViewer(r)
 
-// handlerByIDDelete handles DELETE /api/chirps/{id} requests.
-func (cfg *Config) handlerByIDDelete(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
-	// Extract and validate JWT token
-	tokenString, err := auth.GetBearerToken(r.Header)
+	response, err := cfg.chirpResponse(r.Context(), dbChirp, archived, viewerID, authenticated)
 	if err != nil {
-		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirp, err)
 		return
 	}
+	// Chirps returned after a like, reaction or other change don't count
+	// as views, and archived chirps keep the count they were archived with
+	if r.Method == http.MethodGet && !archived {
+		cfg.Views.Record([]types.ChirpCreateResponse{response})
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
 
-	userID, err := auth.ValidateJWT(tokenString, cfg.JWTSecret)
+// chirpResponse converts a chirp looked up by ID, possibly archived, to its
+// response with related records attached. Unlike buildChirpList it doesn't
+// apply the viewer's muted words or sensitive content preference.
+func (cfg *Config) chirpResponse(ctx context.Context, dbChirp database.Chirp, archived bool, viewerID uuid.UUID, authenticated bool) (types.ChirpCreateResponse, error) {
+	var err error
+	response := []types.ChirpCreateResponse{handlers.BuildChirpResponse(dbChirp)}
+	if archived {
+		err = cfg.attachArchivedMedia(ctx, &response[0])
+		if err == nil {
+			err = cfg.attachArchivedCoauthor(ctx, &response[0])
+		}
+		if err == nil {
+			err = cfg.attachArchivedReactions(ctx, &response[0])
+		}
+		if err == nil {
+			err = cfg.attachArchivedLikes(ctx, &response[0], viewerID)
+		}
+		if err == nil {
+			err = cfg.attachArchivedViews(ctx, &response[0])
+		}
+	} else {
+		err = cfg.attachMedia(ctx, response)
+		if err == nil {
+			err = cfg.attachLinkPreviews(ctx, response)
+		}
+		if err == nil {
+			err = cfg.attachCoauthors(ctx, response)
+		}
+		if err == nil {
+			err = cfg.attachReactions(ctx, response)
+		}
+		if err == nil {
+			err = cfg.attachLikes(ctx, response, viewerID)
+		}
+		if err == nil {
+			err = cfg.attachViews(ctx, response)
+		}
+	}
+	if err == nil {
+		err = cfg.attachOriginals(ctx, response, viewerID, authenticated)
+	}
+	if err == nil {
+		err = cfg.attachAuthors(ctx, response)
+	}
 	if err != nil {
-		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
-		return
+		return types.ChirpCreateResponse{}, err
 	}
+	return response[0], nil
+}
 
-	// Retrieve chirp from database to verify ownership
-	dbChirp, err := cfg.DB.GetChirpByID(r.Context(), chirpID)
+// getVisibleChirp retrieves a chirp, including the archive, that the request
+// may see. When it isn't visible an error response is written and ok is false.
+func (cfg *Config) getVisibleChirp(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) (database.Chirp, bool, bool) {
+	// Retrieve chirp from database, including the archive
+	dbChirp, archived, err := cfg.getChirp(r.Context(), chirpID)
 	if err != nil {
 		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
 			handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
 		} else {
 			handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirp, err)
 		}
-		return
+		return database.Chirp{}, false, false
 	}
 
-	// Check if user is the author of the chirp
-	if dbChirp.UserID != userID {
-		handlers.RespondWithError(w, http.StatusForbidden, "Forbidden", nil)
-		return
+	// Chirps from deactivated accounts are hidden from everyone
+	active, err := cfg.DB.IsUserActive(r.Context(), dbChirp.UserID)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirp, err)
+		return database.Chirp{}, false, false
+	}
+	if !active {
+		handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+		return database.Chirp{}, false, false
 	}
 
-	// Delete chirp from database
-	err = cfg.DB.DeleteChirp(r.Context(), chirpID)
+	// Chirps still inside their undo window are only visible to the author
+	if dbChirp.PublishedAt.After(time.Now()) {
+		viewerID, authenticated, err := cfg.optionalViewer(r)
+		if err != nil || !authenticated || viewerID != dbChirp.UserID {
+			handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+			return database.Chirp{}, false, false
+		}
+	}
+
+	return dbChirp, archived, true
+}
+
+// handlerByIDDelete handles DELETE /api/chirps/{id} requests. Live chirps
+// are only marked deleted, so the author can restore them within the restore
+// window; archived chirps are removed at once.
+func (cfg *Config) handlerByIDDelete(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
 	if err != nil {
-		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't delete chirp", err)
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	if err := cfg.deleteChirp(r.Context(), userID, chirpID); err != nil {
+		switch {
+		case errors.Is(err, ErrChirpNotFound):
+			handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+		case errors.Is(err, ErrNotChirpAuthor):
+			handlers.RespondWithError(w, http.StatusForbidden, "Forbidden", nil)
+		case errors.Is(err, ErrChirpLegalHold):
+			handlers.RespondWithError(w, http.StatusConflict, err.Error(), nil)
+		default:
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't delete chirp", err)
+		}
 		return
 	}
 
 	// Return 204 No Content for successful deletion
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// optionalViewer returns the authenticated user ID when the request carries an
+// Authorization header. Anonymous requests are allowed, but a header with an
+// invalid token is reported as an error.
+func (cfg *Config) optionalViewer(r *http.Request) (uuid.UUID, bool, error) {
+	if r.Header.Get("Authorization") == "" {
+		return uuid.Nil, false, nil
+	}
+
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+	return principal.UserID, true, nil
+}
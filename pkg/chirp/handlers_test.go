@@ -0,0 +1,59 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+func TestHandlerCreateCountsLength(t *testing.T) {
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		counting   validation.Counting
+		body       string
+		wantStatus int
+		wantLength int
+	}{
+		{name: "emoji", counting: validation.CountRunes, body: strings.Repeat("🐦", 140), wantStatus: http.StatusCreated},
+		{name: "too long", counting: validation.CountRunes, body: strings.Repeat("🐦", 141), wantStatus: http.StatusBadRequest, wantLength: 141},
+		{name: "flags as runes", counting: validation.CountRunes, body: strings.Repeat("🇩🇪", 100), wantStatus: http.StatusBadRequest, wantLength: 200},
+		{name: "flags as graphemes", counting: validation.CountGraphemes, body: strings.Repeat("🇩🇪", 100), wantStatus: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newBenchConfig(0)
+			cfg.Counting = tt.counting
+			payload, _ := json.Marshal(types.ChirpCreateRequest{Body: tt.body})
+			req := httptest.NewRequest(http.MethodPost, "/api/chirps", strings.NewReader(string(payload)))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			cfg.HandlerCreate(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantLength == 0 {
+				return
+			}
+			var response types.ChirpTooLongError
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Code != types.ErrCodeChirpTooLong || response.Length != tt.wantLength || response.MaxLength != validation.MaxChirpLength {
+				t.Errorf("response = %+v", response)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package chirp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+const (
+	// trendingDefaultWindow is how far back trending tags look without ?window=
+	trendingDefaultWindow = 24 * time.Hour
+
+	// trendingMaxWindow caps ?window=
+	trendingMaxWindow = 7 * 24 * time.Hour
+
+	// trendingDefaultLimit and trendingMaxLimit bound ?limit=
+	trendingDefaultLimit = 10
+	trendingMaxLimit     = 50
+)
+
+// HandlerTrendingHashtags handles GET /api/hashtags/trending requests,
+// listing the tags used by the most chirps published within ?window= (a Go
+// duration such as "6h", default 24h, at most a week). ?limit= caps the list
+// at 1 to 50 tags, 10 by default.
+func (cfg *Config) HandlerTrendingHashtags(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	window := trendingDefaultWindow
+	if raw := r.URL.Query().Get("window"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 || parsed > trendingMaxWindow {
+			handlers.RespondWithError(w, http.StatusBadRequest, "window must be a duration up to 168h", err)
+			return
+		}
+		window = parsed
+	}
+
+	limit := trendingDefaultLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > trendingMaxLimit {
+			handlers.RespondWithError(w, http.StatusBadRequest, "limit must be between 1 and 50", err)
+			return
+		}
+		limit = parsed
+	}
+
+	// Chirps of accounts on probation don't trend
+	now := time.Now().UTC()
+	rows, err := cfg.DB.GetTrendingHashtags(r.Context(), database.GetTrendingHashtagsParams{
+		TenantID:          tenant.FromContext(r.Context()).ID,
+		Since:             now.Add(-window),
+		EstablishedBefore: cfg.Entitlements.EstablishedBefore(now),
+		MaxTags:           int32(limit),
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve hashtags", err)
+		return
+	}
+
+	trending := make([]types.TrendingHashtag, len(rows))
+	for i, row := range rows {
+		trending[i] = types.TrendingHashtag{Tag: row.Tag, Chirps: row.Chirps}
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, trending)
+}
+
+// setHashtags stores the hashtags of a chirp body, replacing those the chirp
+// had before
+func (cfg *Config) setHashtags(ctx context.Context, chirpID uuid.UUID, tags []string) error {
+	if tags == nil {
+		tags = []string{}
+	}
+	return cfg.DB.SetChirpHashtags(ctx, database.SetChirpHashtagsParams{
+		ChirpID: chirpID,
+		Tags:    tags,
+	})
+}
@@ -0,0 +1,69 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestHandlerTrendingHashtags(t *testing.T) {
+	cfg := newBenchConfig(0)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "defaults", wantStatus: http.StatusOK},
+		{name: "window and limit", query: "?window=6h&limit=50", wantStatus: http.StatusOK},
+		{name: "window too long", query: "?window=169h", wantStatus: http.StatusBadRequest},
+		{name: "bad window", query: "?window=yesterday", wantStatus: http.StatusBadRequest},
+		{name: "limit too high", query: "?limit=51", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			cfg.HandlerTrendingHashtags(rec, httptest.NewRequest(http.MethodGet, "/api/hashtags/trending"+tt.query, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			var trending []types.TrendingHashtag
+			if err := json.Unmarshal(rec.Body.Bytes(), &trending); err != nil {
+				t.Fatal(err)
+			}
+			if len(trending) != 1 || trending[0].Tag != "golang" || trending[0].Chirps != 3 {
+				t.Errorf("trending = %+v, want [{golang 3}]", trending)
+			}
+		})
+	}
+}
+
+func TestHandlerGetByTag(t *testing.T) {
+	cfg := newBenchConfig(2)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "tag", query: "?tag=golang", wantStatus: http.StatusOK},
+		{name: "tag with hash", query: "?tag=%23GoLang&sort=desc", wantStatus: http.StatusOK},
+		{name: "tag and author", query: "?tag=golang&author_id=" + benchUserID.String(), wantStatus: http.StatusOK},
+		{name: "invalid tag", query: "?tag=go-lang", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			cfg.HandlerGet(rec, httptest.NewRequest(http.MethodGet, "/api/chirps"+tt.query, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
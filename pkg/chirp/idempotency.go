@@ -0,0 +1,125 @@
+package chirp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+const (
+	// idempotencyTTL is how long a created chirp is returned for retries
+	// with the same Idempotency-Key
+	idempotencyTTL = 24 * time.Hour
+
+	// idempotencyLockTTL bounds how long a crashed request can hold a key
+	idempotencyLockTTL = time.Minute
+
+	// maxIdempotencyKeyLength caps the Idempotency-Key header
+	maxIdempotencyKeyLength = 255
+)
+
+// storedCreate is the response kept for an Idempotency-Key, with a hash of
+// the request that created it
+type storedCreate struct {
+	RequestHash string          `json:"request_hash"`
+	Response    json.RawMessage `json:"response"`
+}
+
+// idempotentCreate is a chirp creation holding an Idempotency-Key
+type idempotentCreate struct {
+	store       cache.Store
+	key         string
+	requestHash string
+}
+
+// startIdempotent handles the Idempotency-Key header of a chirp creation.
+// A retry of a request that already succeeded gets the original response
+// again, and startIdempotent returns false. Otherwise the key is held until
+// finish is called; without a header or a store it returns nil and true.
+func (cfg *Config) startIdempotent(w http.ResponseWriter, r *http.Request, userID uuid.UUID, body []byte) (*idempotentCreate, bool) {
+	key := r.Header.Get("Idempotency-Key")
+	if key == "" || cfg.Store == nil {
+		return nil, true
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Idempotency-Key is too long", nil)
+		return nil, false
+	}
+
+	hash := sha256.Sum256(body)
+	create := &idempotentCreate{
+		store:       cfg.Store,
+		key:         "idempotency:chirps:" + userID.String() + ":" + key,
+		requestHash: hex.EncodeToString(hash[:]),
+	}
+
+	raw, err := cfg.Store.Get(r.Context(), create.key)
+	if err == nil {
+		var stored storedCreate
+		if err := json.Unmarshal(raw, &stored); err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't check Idempotency-Key", err)
+			return nil, false
+		}
+		if stored.RequestHash != create.requestHash {
+			handlers.RespondWithErrorCode(w, http.StatusUnprocessableEntity, types.ErrCodeIdempotencyKeyReused, "Idempotency-Key was already used for a different chirp", nil)
+			return nil, false
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		handlers.RespondWithJSON(w, http.StatusCreated, stored.Response)
+		return nil, false
+	}
+	if !errors.Is(err, cache.ErrNotFound) {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't check Idempotency-Key", err)
+		return nil, false
+	}
+
+	// Hold the key so a retry sent while this request is still running
+	// doesn't create a second chirp
+	holders, err := cfg.Store.Incr(r.Context(), create.key+":lock", idempotencyLockTTL)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't check Idempotency-Key", err)
+		return nil, false
+	}
+	if holders > 1 {
+		handlers.RespondWithErrorCode(w, http.StatusConflict, types.ErrCodeIdempotencyKeyInUse, "A request with this Idempotency-Key is still in progress", nil)
+		return nil, false
+	}
+	return create, true
+}
+
+// finish stores the response of a created chirp for retries, if there is
+// one, and releases the key
+func (c *idempotentCreate) finish(ctx context.Context, response *types.ChirpCreateResponse) {
+	if c == nil {
+		return
+	}
+	defer func() {
+		if err := c.store.Delete(ctx, c.key+":lock"); err != nil {
+			log.Printf("Couldn't release Idempotency-Key: %s", err)
+		}
+	}()
+	if response == nil {
+		return
+	}
+
+	encoded, err := json.Marshal(response)
+	if err == nil {
+		encoded, err = json.Marshal(storedCreate{RequestHash: c.requestHash, Response: encoded})
+	}
+	if err == nil {
+		err = c.store.Set(ctx, c.key, encoded, idempotencyTTL)
+	}
+	if err != nil {
+		log.Printf("Couldn't store Idempotency-Key response: %s", err)
+	}
+}
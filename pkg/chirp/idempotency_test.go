@@ -0,0 +1,70 @@
+package chirp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestHandlerCreateIdempotencyKey(t *testing.T) {
+	cfg := newBenchConfig(0)
+	cfg.Store = cache.NewMemory()
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/chirps", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Idempotency-Key", key)
+		rec := httptest.NewRecorder()
+		cfg.HandlerCreate(rec, req)
+		return rec
+	}
+	chirpID := func(rec *httptest.ResponseRecorder) string {
+		var response types.ChirpCreateResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		return response.ID.String()
+	}
+
+	first := create("retry-1", `{"body":"hello"}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first status = %d, body = %s", first.Code, first.Body)
+	}
+
+	retry := create("retry-1", `{"body":"hello"}`)
+	if retry.Code != http.StatusCreated || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("retry status = %d, Idempotent-Replayed = %q", retry.Code, retry.Header().Get("Idempotent-Replayed"))
+	}
+	if chirpID(retry) != chirpID(first) {
+		t.Error("retry created a different chirp")
+	}
+
+	if rec := create("retry-1", `{"body":"goodbye"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+
+	if rec := create("retry-2", `{"body":"hello"}`); rec.Code != http.StatusCreated || chirpID(rec) == chirpID(first) {
+		t.Errorf("new key status = %d, want a new chirp", rec.Code)
+	}
+
+	// A key held by a request still running
+	lock := "idempotency:chirps:" + benchUserID.String() + ":retry-3:lock"
+	if _, err := cfg.Store.Incr(context.Background(), lock, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if rec := create("retry-3", `{"body":"hello"}`); rec.Code != http.StatusConflict {
+		t.Errorf("in-progress key status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
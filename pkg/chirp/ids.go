@@ -0,0 +1,12 @@
+package chirp
+
+import "github.com/google/uuid"
+
+// newChirpID returns the ID for a new chirp. Listings order by creation
+// time and only break ties by ID, so both kinds of ID can be mixed freely.
+func (cfg *Config) newChirpID() (uuid.UUID, error) {
+	if cfg.SortableIDs {
+		return uuid.NewV7()
+	}
+	return uuid.NewRandom()
+}
@@ -0,0 +1,39 @@
+package chirp
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewChirpID(t *testing.T) {
+	tests := []struct {
+		name        string
+		sortable    bool
+		wantVersion int
+	}{
+		{name: "random", wantVersion: 4},
+		{name: "sortable", sortable: true, wantVersion: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{SortableIDs: tt.sortable}
+			previous, err := cfg.newChirpID()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for range 100 {
+				id, err := cfg.newChirpID()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if int(id.Version()) != tt.wantVersion {
+					t.Fatalf("version = %d, want %d", id.Version(), tt.wantVersion)
+				}
+				if tt.sortable && bytes.Compare(id[:], previous[:]) <= 0 {
+					t.Fatalf("%s doesn't sort after %s", id, previous)
+				}
+				previous = id
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package chirp
+
+import (
+	"context"
+
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+// IndexImported treats chirps imported from another instance like newly
+// posted ones: their hashtags and mentions are stored and the moderation
+// classifier reviews them. Imported chirps don't raise chirp.created events.
+func (cfg *Config) IndexImported(ctx context.Context, chirps []database.Chirp) error {
+	for _, chirp := range chirps {
+		if tags := validation.Hashtags(chirp.Body); len(tags) > 0 {
+			if err := cfg.setHashtags(ctx, chirp.ID, tags); err != nil {
+				return err
+			}
+		}
+		if handles := validation.Mentions(chirp.Body); len(handles) > 0 {
+			if err := cfg.setMentions(ctx, chirp, handles); err != nil {
+				return err
+			}
+		}
+		cfg.classifyChirp(chirp)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package chirp
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/httpclient"
+	"github.com/kai-xlr/neo_chirpy/internal/jobs"
+	"github.com/kai-xlr/neo_chirpy/internal/moderation"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+)
+
+func TestIndexImported(t *testing.T) {
+	classifier := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		json.NewDecoder(r.Body).Decode(&payload)
+		if strings.Contains(payload["body"], "crypto") {
+			w.Write([]byte(`{"verdict":"flag","label":"spam","score":0.6}`))
+			return
+		}
+		w.Write([]byte(`{"verdict":"allow"}`))
+	}))
+	defer classifier.Close()
+
+	runner := jobs.NewRunner(time.Millisecond, time.Millisecond)
+	conn := &benchConnector{likes: map[[2]string]bool{}, verdicts: &sync.Map{}}
+	cfg := newBenchConfig(0)
+	cfg.DB = database.New(sql.OpenDB(conn))
+	cfg.Jobs = runner
+	cfg.Classifier = &moderation.Classifier{URL: classifier.URL, Client: httpclient.New(httpclient.DefaultConfig())}
+
+	// Imported chirps are classified like newly posted ones
+	spam := database.Chirp{ID: uuid.New(), Body: "Buy #crypto now @bench_user", UserID: benchUserID, TenantID: tenant.DefaultID}
+	fine := database.Chirp{ID: uuid.New(), Body: "Lovely weather", UserID: benchUserID, TenantID: tenant.DefaultID}
+	if err := cfg.IndexImported(context.Background(), []database.Chirp{spam, fine}); err != nil {
+		t.Fatalf("IndexImported() error = %v", err)
+	}
+	runner.Wait()
+
+	if recorded, _ := conn.verdicts.Load(spam.ID.String()); recorded != moderation.Flag {
+		t.Errorf("verdict on imported spam = %v, want %q", recorded, moderation.Flag)
+	}
+	if recorded, ok := conn.verdicts.Load(fine.ID.String()); ok {
+		t.Errorf("verdict on allowed chirp = %v, want none", recorded)
+	}
+}
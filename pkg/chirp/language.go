@@ -0,0 +1,142 @@
+package chirp
+
+import (
+	"strings"
+	"unicode"
+)
+
+// LanguageDetector guesses the language a chirp is written in
+type LanguageDetector interface {
+	// Detect returns the ISO 639-1 code of the language of body, or ""
+	// when it can't tell
+	Detect(body string) string
+}
+
+// detectLanguage returns the language of body, or "" without a detector
+func (cfg *Config) detectLanguage(body string) string {
+	if cfg.Languages == nil {
+		return ""
+	}
+	return cfg.Languages.Detect(body)
+}
+
+// StopwordDetector detects languages without a trained model. Text mostly
+// in a script used by a single language is that language; Latin script
+// text is matched against the most common words of a few European
+// languages. Mentions, hashtags and links are ignored, and a chirp with too
+// few clues is left undetected rather than guessed.
+type StopwordDetector struct{}
+
+// scriptLanguages maps scripts to the language chirps in them are assumed
+// to be in. Han without kana is taken to be Chinese.
+var scriptLanguages = []struct {
+	script   *unicode.RangeTable
+	language string
+}{
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Hangul, "ko"},
+	{unicode.Han, "zh"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Greek, "el"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Thai, "th"},
+}
+
+// stopwords are words common enough in each language to show up in most
+// chirps written in it
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "was", "to", "of", "that", "it", "for", "with", "on", "this", "you", "my", "i", "have", "be", "not", "but", "just", "what", "at", "so", "we", "they", "your", "me", "up", "today"},
+	"es": {"el", "los", "las", "que", "y", "un", "una", "es", "por", "con", "para", "lo", "se", "del", "al", "pero", "muy", "como", "mi", "yo", "está", "esto", "hoy"},
+	"fr": {"le", "les", "des", "et", "est", "une", "je", "tu", "il", "nous", "vous", "pas", "qui", "pour", "dans", "sur", "avec", "ce", "mon", "très", "aujourd'hui", "c'est"},
+	"de": {"der", "die", "das", "und", "ist", "ich", "nicht", "ein", "eine", "zu", "mit", "auf", "den", "dem", "es", "sie", "wir", "du", "mein", "auch", "aber", "heute", "für", "sehr"},
+	"pt": {"o", "os", "as", "que", "e", "um", "uma", "é", "não", "com", "para", "por", "do", "da", "em", "eu", "meu", "muito", "mas", "hoje", "você"},
+	"it": {"il", "lo", "gli", "che", "di", "e", "un", "una", "è", "non", "per", "con", "sono", "mi", "ma", "molto", "oggi", "questo", "anche", "io", "della"},
+	"nl": {"het", "een", "en", "is", "ik", "niet", "van", "op", "te", "dat", "die", "met", "voor", "zijn", "maar", "ook", "mijn", "vandaag", "heel", "wij", "je"},
+}
+
+// stopwordLanguages indexes stopwords by word
+var stopwordLanguages = func() map[string][]string {
+	index := make(map[string][]string)
+	for language, words := range stopwords {
+		for _, word := range words {
+			index[word] = append(index[word], language)
+		}
+	}
+	return index
+}()
+
+// Detect implements LanguageDetector
+func (StopwordDetector) Detect(body string) string {
+	words := languageWords(body)
+
+	// Letters per script; a chirp mostly in one script is in its language
+	scripts := make(map[string]int)
+	letters := 0
+	for _, word := range words {
+		for _, r := range word {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			letters++
+			for _, sl := range scriptLanguages {
+				if unicode.Is(sl.script, r) {
+					scripts[sl.language]++
+					break
+				}
+			}
+		}
+	}
+	if scripts["zh"] > 0 && scripts["ja"] > 0 {
+		// Japanese mixes kanji with kana
+		scripts["ja"] += scripts["zh"]
+		delete(scripts, "zh")
+	}
+	for language, count := range scripts {
+		if count*2 > letters {
+			return language
+		}
+	}
+
+	// Latin script: the language with the most stopwords, if there's a
+	// clear winner
+	scores := make(map[string]int)
+	for _, word := range words {
+		for _, language := range stopwordLanguages[word] {
+			scores[language]++
+		}
+	}
+	best, bestScore, tied := "", 0, false
+	for language, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = language, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+	if tied || bestScore < 2 {
+		return ""
+	}
+	return best
+}
+
+// languageWords splits body into lowercase words, leaving out mentions,
+// hashtags and links, which are the same in every language
+func languageWords(body string) []string {
+	var words []string
+	for _, field := range strings.Fields(strings.ToLower(body)) {
+		if strings.HasPrefix(field, "@") || strings.HasPrefix(field, "#") || strings.Contains(field, "://") {
+			continue
+		}
+		field = strings.TrimFunc(field, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		})
+		if field != "" {
+			words = append(words, field)
+		}
+	}
+	return words
+}
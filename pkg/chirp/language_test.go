@@ -0,0 +1,89 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestStopwordDetector(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{body: "Just setting up my chirpy, this is the first one", want: "en"},
+		{body: "Hoy es un día muy bonito para salir con los amigos", want: "es"},
+		{body: "Je suis très content, c'est une belle journée", want: "fr"},
+		{body: "Ich bin heute nicht zu Hause, aber morgen", want: "de"},
+		{body: "Ik ben vandaag niet thuis maar morgen wel", want: "nl"},
+		{body: "今日はとても良い天気ですね", want: "ja"},
+		{body: "今天天气很好", want: "zh"},
+		{body: "오늘 날씨가 정말 좋네요", want: "ko"},
+		{body: "Сегодня очень хорошая погода", want: "ru"},
+		{body: "Привет @alice #golang https://example.com/the/and/is", want: "ru"},
+		{body: "kerfuffle!", want: ""},
+		{body: "@alice #golang https://example.com", want: ""},
+		{body: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			if got := (StopwordDetector{}).Detect(tt.body); got != tt.want {
+				t.Errorf("Detect(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlerCreateDetectsLanguage(t *testing.T) {
+	cfg := newBenchConfig(0)
+	cfg.Languages = StopwordDetector{}
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/chirps", strings.NewReader(`{"body":"Hoy es un día muy bonito"}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	cfg.HandlerCreate(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var response types.ChirpCreateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Language != "es" {
+		t.Errorf("language = %q, want %q", response.Language, "es")
+	}
+}
+
+func TestHandlerGetByLanguage(t *testing.T) {
+	cfg := newBenchConfig(2)
+
+	tests := []struct {
+		query      string
+		wantStatus int
+	}{
+		{query: "?lang=en", wantStatus: http.StatusOK},
+		{query: "?lang=EN&sort=desc", wantStatus: http.StatusOK},
+		{query: "?lang=en&tag=golang", wantStatus: http.StatusOK},
+		{query: "?lang=english", wantStatus: http.StatusBadRequest},
+		{query: "?lang=e1", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			cfg.HandlerGet(rec, httptest.NewRequest(http.MethodGet, "/api/chirps"+tt.query, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
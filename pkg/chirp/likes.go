@@ -0,0 +1,131 @@
+package chirp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/dataloader"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// handlerLike handles POST /api/chirps/{id}/like requests, which like the
+// chirp, and DELETE requests, which unlike it. Both are idempotent and
+// respond with the updated chirp.
+func (cfg *Config) handlerLike(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+		return
+	}
+
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	dbChirp, archived, ok := cfg.getVisibleChirp(w, r, chirpID)
+	if !ok {
+		return
+	}
+	if archived {
+		handlers.RespondWithError(w, http.StatusConflict, "Archived chirps can't be liked", nil)
+		return
+	}
+	if dbChirp.Locked && r.Method == http.MethodPost {
+		respondThreadLocked(w)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		err = cfg.DB.LikeChirp(r.Context(), database.LikeChirpParams{
+			ChirpID: dbChirp.ID,
+			UserID:  userID,
+		})
+	} else {
+		err = cfg.DB.UnlikeChirp(r.Context(), database.UnlikeChirpParams{
+			ChirpID: dbChirp.ID,
+			UserID:  userID,
+		})
+	}
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update like", err)
+		return
+	}
+
+	if r.Method == http.MethodPost && userID != dbChirp.UserID {
+		cfg.Events.Publish(events.Event{
+			Type:    events.ChirpLiked,
+			UserID:  userID,
+			ChirpID: dbChirp.ID,
+		})
+	}
+
+	cfg.handlerByIDGet(w, r, chirpID)
+}
+
+// attachLikes marks the given chirp responses that viewerID liked, through
+// the request's like loader. Like counts come with the chirps themselves.
+// Anonymous viewers pass uuid.Nil and have liked nothing.
+func (cfg *Config) attachLikes(ctx context.Context, chirps []types.ChirpCreateResponse, viewerID uuid.UUID) error {
+	if len(chirps) == 0 || viewerID == uuid.Nil {
+		return nil
+	}
+
+	chirpIDs := make([]uuid.UUID, len(chirps))
+	for i := range chirps {
+		chirpIDs[i] = chirps[i].ID
+	}
+	liked, err := cfg.likeLoader(ctx, viewerID).LoadMany(ctx, chirpIDs)
+	if err != nil {
+		return err
+	}
+	for i := range chirps {
+		chirps[i].LikedByMe = liked[chirps[i].ID]
+	}
+	return nil
+}
+
+// likeLoader returns the request's loader for whether the viewer liked each
+// chirp by ID. A request has a single viewer, so the viewer is fixed when
+// the loader is first created.
+func (cfg *Config) likeLoader(ctx context.Context, viewerID uuid.UUID) *dataloader.Loader[uuid.UUID, bool] {
+	return dataloader.For(ctx, "chirp.likes", func(ctx context.Context, chirpIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+		likedIDs, err := cfg.DB.GetLikedChirps(ctx, database.GetLikedChirpsParams{
+			ViewerID: viewerID,
+			ChirpIds: chirpIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		liked := make(map[uuid.UUID]bool, len(likedIDs))
+		for _, chirpID := range likedIDs {
+			liked[chirpID] = true
+		}
+		return liked, nil
+	})
+}
+
+// attachArchivedLikes marks a single archived chirp response if viewerID
+// liked it
+func (cfg *Config) attachArchivedLikes(ctx context.Context, chirp *types.ChirpCreateResponse, viewerID uuid.UUID) error {
+	if viewerID == uuid.Nil {
+		return nil
+	}
+	liked, err := cfg.DB.IsArchivedChirpLiked(ctx, database.IsArchivedChirpLikedParams{
+		ChirpID:  chirp.ID,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		return err
+	}
+	chirp.LikedByMe = liked
+	return nil
+}
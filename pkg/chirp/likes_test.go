@@ -0,0 +1,93 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestHandlerLike(t *testing.T) {
+	cfg := newBenchConfig(0)
+	chirpID := uuid.New()
+	liker := uuid.New()
+	token, err := auth.MakeJWT(liker, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(method, token string) (int, types.ChirpCreateResponse) {
+		t.Helper()
+		req := httptest.NewRequest(method, "/api/chirps/"+chirpID.String()+"/like", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		cfg.HandlerByID(rec, req)
+
+		var chirp types.ChirpCreateResponse
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &chirp); err != nil {
+				t.Fatalf("response is not a chirp: %v", err)
+			}
+		}
+		return rec.Code, chirp
+	}
+
+	steps := []struct {
+		name          string
+		method        string
+		token         string
+		wantStatus    int
+		wantLikeCount int64
+		wantLikedByMe bool
+	}{
+		{name: "anonymous", method: http.MethodPost, wantStatus: http.StatusUnauthorized},
+		{name: "like", method: http.MethodPost, token: token, wantStatus: http.StatusOK, wantLikeCount: 1, wantLikedByMe: true},
+		{name: "like again", method: http.MethodPost, token: token, wantStatus: http.StatusOK, wantLikeCount: 1, wantLikedByMe: true},
+		{name: "unlike", method: http.MethodDelete, token: token, wantStatus: http.StatusOK, wantLikeCount: 0, wantLikedByMe: false},
+		{name: "unlike again", method: http.MethodDelete, token: token, wantStatus: http.StatusOK, wantLikeCount: 0, wantLikedByMe: false},
+		{name: "wrong method", method: http.MethodPut, token: token, wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, step := range steps {
+		status, chirp := request(step.method, step.token)
+		if status != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d", step.name, status, step.wantStatus)
+		}
+		if status != http.StatusOK {
+			continue
+		}
+		if chirp.ID != chirpID || chirp.LikeCount != step.wantLikeCount || chirp.LikedByMe != step.wantLikedByMe {
+			t.Errorf("%s: chirp %s like_count = %d, liked_by_me = %v, want %d, %v",
+				step.name, chirp.ID, chirp.LikeCount, chirp.LikedByMe, step.wantLikeCount, step.wantLikedByMe)
+		}
+	}
+}
+
+func TestLikedByMeIsPerViewer(t *testing.T) {
+	cfg := newBenchConfig(0)
+	chirpID := uuid.New()
+	token, err := auth.MakeJWT(uuid.New(), benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/chirps/"+chirpID.String()+"/like", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	cfg.HandlerByID(httptest.NewRecorder(), req)
+
+	rec := httptest.NewRecorder()
+	cfg.HandlerByID(rec, httptest.NewRequest(http.MethodGet, "/api/chirps/"+chirpID.String(), nil))
+	var chirp types.ChirpCreateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &chirp); err != nil {
+		t.Fatalf("status %d, response is not a chirp: %v", rec.Code, err)
+	}
+	if chirp.LikeCount != 1 || chirp.LikedByMe {
+		t.Errorf("anonymous view: like_count = %d, liked_by_me = %v, want 1, false", chirp.LikeCount, chirp.LikedByMe)
+	}
+}
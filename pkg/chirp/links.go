@@ -0,0 +1,123 @@
+package chirp
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/dataloader"
+	"github.com/kai-xlr/neo_chirpy/internal/linkpreview"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+// linkPreviewTTL is how long a fetched preview is used before the page is
+// fetched again
+const linkPreviewTTL = 24 * time.Hour
+
+// setChirpLink stores the first link in a chirp body to an allowed host,
+// replacing the one stored before, and fetches its preview in the
+// background. Chirps without such a link have theirs removed. Without a
+// fetcher links aren't stored.
+func (cfg *Config) setChirpLink(ctx context.Context, chirpID uuid.UUID, body string) error {
+	if cfg.Previews == nil {
+		return nil
+	}
+	link := ""
+	for _, candidate := range validation.Links(body) {
+		if cfg.Previews.Allowed(candidate) {
+			link = candidate
+			break
+		}
+	}
+	if link == "" {
+		return cfg.DB.DeleteChirpLink(ctx, chirpID)
+	}
+
+	if err := cfg.DB.SetChirpLink(ctx, database.SetChirpLinkParams{ChirpID: chirpID, Url: link}); err != nil {
+		return err
+	}
+	if cfg.Jobs != nil {
+		cfg.Jobs.Enqueue("fetch link preview", 3, func(ctx context.Context) error {
+			return cfg.fetchLinkPreview(ctx, link)
+		})
+	}
+	return nil
+}
+
+// fetchLinkPreview fetches and stores the preview of link, unless a recent
+// one is stored already. A page without metadata is stored as an empty
+// preview, so it isn't fetched again for every chirp linking to it.
+func (cfg *Config) fetchLinkPreview(ctx context.Context, link string) error {
+	fresh, err := cfg.DB.IsLinkPreviewFresh(ctx, database.IsLinkPreviewFreshParams{
+		Url:   link,
+		Since: time.Now().UTC().Add(-linkPreviewTTL),
+	})
+	if err != nil || fresh {
+		return err
+	}
+
+	preview, err := cfg.Previews.Fetch(ctx, link)
+	if errors.Is(err, linkpreview.ErrHostNotAllowed) {
+		// The allowlist changed since the link was stored
+		return nil
+	}
+	if err != nil && !errors.Is(err, linkpreview.ErrNoMetadata) {
+		return err
+	}
+	return cfg.DB.UpsertLinkPreview(ctx, database.UpsertLinkPreviewParams{
+		Url:         link,
+		Title:       preview.Title,
+		Description: preview.Description,
+		ImageUrl:    preview.Image,
+	})
+}
+
+// attachLinkPreviews adds the fetched link preview, if any, to the given
+// chirp responses through the request's preview loader. Without a fetcher
+// no previews are shown.
+func (cfg *Config) attachLinkPreviews(ctx context.Context, chirps []types.ChirpCreateResponse) error {
+	if len(chirps) == 0 || cfg.Previews == nil {
+		return nil
+	}
+
+	chirpIDs := make([]uuid.UUID, len(chirps))
+	for i := range chirps {
+		chirpIDs[i] = chirps[i].ID
+	}
+	previews, err := cfg.linkPreviewLoader(ctx).LoadMany(ctx, chirpIDs)
+	if err != nil {
+		return err
+	}
+	for i := range chirps {
+		chirps[i].LinkPreview = previews[chirps[i].ID]
+	}
+	return nil
+}
+
+// linkPreviewLoader returns the request's loader for link previews by chirp
+// ID. Previews of pages without metadata are left out.
+func (cfg *Config) linkPreviewLoader(ctx context.Context) *dataloader.Loader[uuid.UUID, *types.LinkPreview] {
+	return dataloader.For(ctx, "chirp.link_previews", func(ctx context.Context, chirpIDs []uuid.UUID) (map[uuid.UUID]*types.LinkPreview, error) {
+		rows, err := cfg.DB.GetLinkPreviews(ctx, chirpIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		previews := make(map[uuid.UUID]*types.LinkPreview, len(rows))
+		for _, row := range rows {
+			if row.Title == "" && row.Description == "" && row.ImageUrl == "" {
+				continue
+			}
+			previews[row.ChirpID] = &types.LinkPreview{
+				URL:         row.Url,
+				Title:       row.Title,
+				Description: row.Description,
+				Image:       row.ImageUrl,
+			}
+		}
+		return previews, nil
+	})
+}
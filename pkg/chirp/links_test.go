@@ -0,0 +1,66 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/httpclient"
+	"github.com/kai-xlr/neo_chirpy/internal/jobs"
+	"github.com/kai-xlr/neo_chirpy/internal/linkpreview"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestLinkPreviews(t *testing.T) {
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<meta property="og:title" content="Chirpy docs"><meta property="og:image" content="/card.png">`))
+	}))
+	defer page.Close()
+	host, _ := url.Parse(page.URL)
+
+	runner := jobs.NewRunner(time.Millisecond, time.Millisecond)
+	cfg := newBenchConfig(0)
+	clientCfg := httpclient.DefaultConfig()
+	clientCfg.Timeout = time.Second
+	cfg.Previews = linkpreview.New([]string{host.Hostname()}, clientCfg)
+	cfg.Jobs = runner
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the first link to an allowed host is previewed
+	body := `{"body":"See https://blocked.example/ and ` + page.URL + `/docs."}`
+	req := httptest.NewRequest(http.MethodPost, "/api/chirps", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	cfg.HandlerCreate(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var created types.ChirpCreateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.LinkPreview != nil {
+		t.Errorf("created chirp has a preview before it was fetched: %+v", created.LinkPreview)
+	}
+
+	runner.Wait()
+	rec = httptest.NewRecorder()
+	cfg.HandlerByID(rec, httptest.NewRequest(http.MethodGet, "/api/chirps/"+created.ID.String(), nil))
+	var fetched types.ChirpCreateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &fetched); err != nil {
+		t.Fatal(err)
+	}
+	want := types.LinkPreview{URL: page.URL + "/docs", Title: "Chirpy docs", Image: page.URL + "/card.png"}
+	if fetched.LinkPreview == nil || *fetched.LinkPreview != want {
+		t.Errorf("link_preview = %+v, want %+v", fetched.LinkPreview, want)
+	}
+}
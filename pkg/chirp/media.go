@@ -0,0 +1,84 @@
+package chirp
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/dataloader"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+// validateMedia checks the number of attachments and each attachment's URL and alt text
+func validateMedia(media []types.MediaRequest, requireAltText bool) error {
+	if len(media) > validation.MaxMediaAttachments {
+		return validation.ErrTooManyMedia
+	}
+	for _, attachment := range media {
+		if err := validation.ValidateMediaAttachment(attachment.URL, attachment.AltText, requireAltText); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createMedia stores the attachments for a chirp, preserving their order
+func (cfg *Config) createMedia(ctx context.Context, chirpID uuid.UUID, media []types.MediaRequest) ([]database.ChirpMedium, error) {
+	if len(media) == 0 {
+		return nil, nil
+	}
+
+	urls := make([]string, len(media))
+	altTexts := make([]string, len(media))
+	for i, attachment := range media {
+		urls[i] = strings.TrimSpace(attachment.URL)
+		altTexts[i] = strings.TrimSpace(attachment.AltText)
+	}
+
+	return cfg.DB.CreateChirpMedia(ctx, database.CreateChirpMediaParams{
+		ChirpID:  chirpID,
+		Urls:     urls,
+		AltTexts: altTexts,
+	})
+}
+
+// attachMedia loads media for the given chirp responses through the
+// request's media loader, so each chirp's media is fetched at most once
+func (cfg *Config) attachMedia(ctx context.Context, chirps []types.ChirpCreateResponse) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+
+	chirpIDs := make([]uuid.UUID, len(chirps))
+	for i, chirp := range chirps {
+		chirpIDs[i] = chirp.ID
+	}
+
+	mediaByChirp, err := cfg.mediaLoader(ctx).LoadMany(ctx, chirpIDs)
+	if err != nil {
+		return err
+	}
+	for i := range chirps {
+		chirps[i].Media = handlers.BuildMediaResponse(mediaByChirp[chirps[i].ID])
+	}
+	return nil
+}
+
+// mediaLoader returns the request's loader for chirp media by chirp ID
+func (cfg *Config) mediaLoader(ctx context.Context) *dataloader.Loader[uuid.UUID, []database.ChirpMedium] {
+	return dataloader.For(ctx, "chirp.media", func(ctx context.Context, chirpIDs []uuid.UUID) (map[uuid.UUID][]database.ChirpMedium, error) {
+		dbMedia, err := cfg.DB.GetMediaForChirps(ctx, chirpIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		mediaByChirp := make(map[uuid.UUID][]database.ChirpMedium)
+		for _, media := range dbMedia {
+			mediaByChirp[media.ChirpID] = append(mediaByChirp[media.ChirpID], media)
+		}
+		return mediaByChirp, nil
+	})
+}
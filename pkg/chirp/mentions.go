@@ -0,0 +1,80 @@
+package chirp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// HandlerUserMentions handles GET /api/users/{id}/mentions requests, listing
+// the chirps that mention the user, newest first
+func (cfg *Config) HandlerUserMentions(w http.ResponseWriter, r *http.Request) {
+	idString, subresource := handlers.SplitResourcePath(r.URL.Path, "/api/users/")
+	if subresource != "mentions" {
+		handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+		return
+	}
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	userID, err := uuid.Parse(idString)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	viewerID, authenticated, err := cfg.optionalViewer(r)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	found, err := cfg.DB.IsActiveUserInTenant(r.Context(), database.IsActiveUserInTenantParams{
+		ID:       userID,
+		TenantID: tenant.FromContext(r.Context()).ID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+		return
+	}
+	if !found {
+		handlers.RespondWithError(w, http.StatusNotFound, "User not found", nil)
+		return
+	}
+
+	dbChirps, err := cfg.DB.GetChirpsMentioningUser(r.Context(), database.GetChirpsMentioningUserParams{
+		TenantID: tenant.FromContext(r.Context()).ID,
+		UserID:   userID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+		return
+	}
+
+	response, err := cfg.buildChirpList(r.Context(), dbChirps, viewerID, authenticated)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// setMentions stores the users mentioned by handle in a chirp, replacing
+// those mentioned before. Handles that don't belong to an active user of
+// the chirp's community are ignored.
+func (cfg *Config) setMentions(ctx context.Context, chirp database.Chirp, handles []string) error {
+	if handles == nil {
+		handles = []string{}
+	}
+	return cfg.DB.SetChirpMentions(ctx, database.SetChirpMentionsParams{
+		ChirpID:  chirp.ID,
+		TenantID: chirp.TenantID,
+		Handles:  handles,
+	})
+}
@@ -0,0 +1,35 @@
+package chirp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestHandlerUserMentions(t *testing.T) {
+	cfg := newBenchConfig(2)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "mentions", method: http.MethodGet, path: "/api/users/" + benchUserID.String() + "/mentions", wantStatus: http.StatusOK},
+		{name: "unknown user", method: http.MethodGet, path: "/api/users/" + uuid.NewString() + "/mentions", wantStatus: http.StatusNotFound},
+		{name: "invalid id", method: http.MethodGet, path: "/api/users/nobody/mentions", wantStatus: http.StatusBadRequest},
+		{name: "other subresource", method: http.MethodGet, path: "/api/users/" + benchUserID.String() + "/likes", wantStatus: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodPost, path: "/api/users/" + benchUserID.String() + "/mentions", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			cfg.HandlerUserMentions(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package chirp
+
+import (
+	"context"
+	"log"
+
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/moderation"
+)
+
+// classifyChirp has the classifier score a new or edited chirp in the
+// background, so it is published without waiting for the verdict. Without
+// a classifier or job runner chirps aren't scored.
+func (cfg *Config) classifyChirp(chirp database.Chirp) {
+	if cfg.Classifier == nil || cfg.Jobs == nil {
+		return
+	}
+	cfg.Jobs.Enqueue("classify chirp", 3, func(ctx context.Context) error {
+		return cfg.reconcileVerdict(ctx, chirp)
+	})
+}
+
+// reconcileVerdict acts on the classifier's verdict on a chirp. Flagged
+// chirps are queued for moderator review; removed ones are also hidden, in
+// the same statement that records the verdict, and their author is
+// emailed. A chirp has at most one verdict awaiting review, so a retry
+// after a failure records nothing and doesn't announce the removal again.
+func (cfg *Config) reconcileVerdict(ctx context.Context, chirp database.Chirp) error {
+	verdict, err := cfg.Classifier.Classify(ctx, chirp.ID, chirp.Body)
+	if err != nil || verdict.Verdict == moderation.Allow {
+		return err
+	}
+
+	if _, err := cfg.DB.CreateModerationVerdict(ctx, database.CreateModerationVerdictParams{
+		TenantID: chirp.TenantID,
+		ChirpID:  chirp.ID,
+		Verdict:  verdict.Verdict,
+		Label:    verdict.Label,
+		Score:    verdict.Score,
+	}); err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			return nil
+		}
+		return err
+	}
+	if verdict.Verdict != moderation.Remove {
+		return nil
+	}
+
+	cfg.Events.Publish(events.Event{
+		Type:    events.ChirpDeleted,
+		UserID:  chirp.UserID,
+		ChirpID: chirp.ID,
+	})
+	cfg.Events.Publish(events.Event{
+		Type:     events.ChirpRemoved,
+		TenantID: chirp.TenantID,
+		UserID:   chirp.UserID,
+		ChirpID:  chirp.ID,
+	})
+	// The chirp is already hidden; retrying for the email would find its
+	// verdict recorded and never send it
+	if err := cfg.notifyChirpHidden(ctx, chirp, verdict.Label); err != nil {
+		log.Printf("Couldn't email the author of hidden chirp %s: %s", chirp.ID, err)
+	}
+	return nil
+}
+
+// notifyChirpHidden emails the author of a chirp the classifier hid.
+// Without a mailer the author isn't told.
+func (cfg *Config) notifyChirpHidden(ctx context.Context, chirp database.Chirp, label string) error {
+	if cfg.Mailer == nil || cfg.Templates == nil {
+		return nil
+	}
+	author, err := cfg.DB.GetUserByID(ctx, database.GetUserByIDParams{
+		TenantID: chirp.TenantID,
+		ID:       chirp.UserID,
+	})
+	if err != nil {
+		return err
+	}
+
+	msg, err := cfg.Templates.Render("chirp-removed", "", []string{author.Email}, map[string]any{
+		"Email":     author.Email,
+		"ChirpBody": chirp.Body,
+		"Label":     label,
+	})
+	if err != nil {
+		return err
+	}
+	return cfg.Mailer.Send(ctx, msg)
+}
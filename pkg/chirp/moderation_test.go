@@ -0,0 +1,94 @@
+package chirp
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/httpclient"
+	"github.com/kai-xlr/neo_chirpy/internal/jobs"
+	"github.com/kai-xlr/neo_chirpy/internal/mailer"
+	"github.com/kai-xlr/neo_chirpy/internal/moderation"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestClassifyChirp(t *testing.T) {
+	classifier := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		json.NewDecoder(r.Body).Decode(&payload)
+		switch {
+		case strings.Contains(payload["body"], "free phone"):
+			w.Write([]byte(`{"verdict":"remove","label":"spam","score":0.98}`))
+		case strings.Contains(payload["body"], "crypto"):
+			w.Write([]byte(`{"verdict":"flag","label":"spam","score":0.6}`))
+		default:
+			w.Write([]byte(`{"verdict":"allow"}`))
+		}
+	}))
+	defer classifier.Close()
+
+	runner := jobs.NewRunner(time.Millisecond, time.Millisecond)
+	var mail bytes.Buffer
+	conn := &benchConnector{likes: map[[2]string]bool{}, verdicts: &sync.Map{}}
+	cfg := newBenchConfig(0)
+	cfg.DB = database.New(sql.OpenDB(conn))
+	cfg.Jobs = runner
+	cfg.Classifier = &moderation.Classifier{URL: classifier.URL, Client: httpclient.New(httpclient.DefaultConfig())}
+	cfg.Mailer = &mailer.LogMailer{Out: &mail}
+	cfg.Templates = mailer.NewRenderer()
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every chirp is published straight away, whatever its verdict
+	created := map[string]string{}
+	for _, body := range []string{"Win a free phone today", "Buy crypto now", "Lovely weather"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/chirps", strings.NewReader(`{"body":"`+body+`"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		cfg.HandlerCreate(rec, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body)
+		}
+		var chirp types.ChirpCreateResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &chirp); err != nil {
+			t.Fatal(err)
+		}
+		created[body] = chirp.ID.String()
+	}
+	runner.Wait()
+
+	// Allowed chirps aren't recorded
+	want := map[string]string{"Win a free phone today": moderation.Remove, "Buy crypto now": moderation.Flag, "Lovely weather": ""}
+	for body, verdict := range want {
+		recorded, _ := conn.verdicts.Load(created[body])
+		if got, _ := recorded.(string); got != verdict {
+			t.Errorf("verdict on %q = %v, want %q", body, recorded, verdict)
+		}
+	}
+
+	// Only the author of the hidden chirp is emailed
+	if got := mail.String(); strings.Count(got, "--- email ---") != 1 || !strings.Contains(got, "Win a free phone today") || !strings.Contains(got, "likely spam") {
+		t.Errorf("emails sent = %q", got)
+	}
+
+	// A retried classification finds the verdict recorded and leaves it be
+	removed := database.Chirp{ID: uuid.MustParse(created["Win a free phone today"]), UserID: benchUserID, Body: "Win a free phone today"}
+	if err := cfg.reconcileVerdict(context.Background(), removed); err != nil {
+		t.Fatalf("reconcileVerdict() retry error = %v", err)
+	}
+	if got := strings.Count(mail.String(), "--- email ---"); got != 1 {
+		t.Errorf("emails sent after retry = %d, want 1", got)
+	}
+}
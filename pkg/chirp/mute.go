@@ -0,0 +1,63 @@
+package chirp
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+)
+
+// tokenize splits text into lowercase words, dropping punctuation
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// IsMuted reports whether the body contains any of the muted words or phrases.
+// Matching is case-insensitive and only considers whole words, so "cat" does
+// not mute "concatenate" but does mute "Cat!".
+func IsMuted(body string, mutedWords []string) bool {
+	if len(mutedWords) == 0 {
+		return false
+	}
+
+	words := tokenize(body)
+	for _, muted := range mutedWords {
+		phrase := tokenize(muted)
+		if len(phrase) == 0 || len(phrase) > len(words) {
+			continue
+		}
+		for start := 0; start+len(phrase) <= len(words); start++ {
+			if containsPhraseAt(words, phrase, start) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsPhraseAt checks whether phrase appears in words at the given offset
+func containsPhraseAt(words, phrase []string, start int) bool {
+	for i, word := range phrase {
+		if words[start+i] != word {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterMuted removes chirps whose body matches any of the muted words
+func FilterMuted(chirps []database.Chirp, mutedWords []string) []database.Chirp {
+	if len(mutedWords) == 0 {
+		return chirps
+	}
+
+	filtered := make([]database.Chirp, 0, len(chirps))
+	for _, chirp := range chirps {
+		if !IsMuted(chirp.Body, mutedWords) {
+			filtered = append(filtered, chirp)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,77 @@
+package chirp
+
+import (
+	"testing"
+
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+)
+
+func TestIsMuted(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		mutedWords []string
+		want       bool
+	}{
+		{
+			name:       "no muted words",
+			body:       "Spoilers ahead",
+			mutedWords: nil,
+			want:       false,
+		},
+		{
+			name:       "single word match",
+			body:       "Spoilers ahead",
+			mutedWords: []string{"spoilers"},
+			want:       true,
+		},
+		{
+			name:       "punctuation adjacent match",
+			body:       "No more spoilers!",
+			mutedWords: []string{"spoilers"},
+			want:       true,
+		},
+		{
+			name:       "partial word does not match",
+			body:       "Let's concatenate strings",
+			mutedWords: []string{"cat"},
+			want:       false,
+		},
+		{
+			name:       "phrase match",
+			body:       "That was a Hot Take, honestly",
+			mutedWords: []string{"hot take"},
+			want:       true,
+		},
+		{
+			name:       "phrase words out of order",
+			body:       "Take it while it's hot",
+			mutedWords: []string{"hot take"},
+			want:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsMuted(tt.body, tt.mutedWords); got != tt.want {
+				t.Errorf("IsMuted() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterMuted(t *testing.T) {
+	chirps := []database.Chirp{
+		{Body: "Big game tonight"},
+		{Body: "Finale spoilers inside"},
+		{Body: "Coffee time"},
+	}
+
+	filtered := FilterMuted(chirps, []string{"spoilers", "game"})
+	if len(filtered) != 1 {
+		t.Fatalf("FilterMuted() returned %d chirps, want 1", len(filtered))
+	}
+	if filtered[0].Body != "Coffee time" {
+		t.Errorf("FilterMuted() kept %q, want %q", filtered[0].Body, "Coffee time")
+	}
+}
@@ -0,0 +1,116 @@
+package chirp
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+const (
+	// pollTimeout is how long a poll waits for new chirps before returning
+	// an empty list
+	pollTimeout = 30 * time.Second
+
+	// pollLimit caps the chirps returned by one poll; clients poll again
+	// with the last ID to get the rest
+	pollLimit = 100
+)
+
+// HandlerPoll handles GET /api/chirps/poll?since_id= requests, a long-polling
+// fallback for clients that can't hold a stream open. Chirps published after
+// since_id (or after the request, without it) are returned oldest first as
+// soon as there are any; after 30 seconds without one the response is an
+// empty list.
+func (cfg *Config) HandlerPoll(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	viewerID, authenticated, err := cfg.optionalViewer(r)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	since, afterID := time.Now().UTC(), uuid.Max
+	if sinceID := r.URL.Query().Get("since_id"); sinceID != "" {
+		parsedID, err := uuid.Parse(sinceID)
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusBadRequest, "Invalid since_id format", err)
+			return
+		}
+		dbChirp, err := cfg.DB.GetChirpByID(r.Context(), parsedID)
+		if err != nil {
+			if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+				handlers.RespondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			} else {
+				handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+			}
+			return
+		}
+		if dbChirp.TenantID != tenant.FromContext(r.Context()).ID {
+			handlers.RespondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			return
+		}
+		since, afterID = dbChirp.PublishedAt, dbChirp.ID
+	}
+
+	// Subscribe before the first query so a chirp published in between
+	// still wakes the poll
+	wake := make(chan struct{}, 1)
+	if cfg.Events != nil {
+		unsubscribe := cfg.Events.Subscribe(func(context.Context, events.Event) {
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		}, events.ChirpCreated)
+		defer unsubscribe()
+	}
+
+	timeout := time.NewTimer(pollTimeout)
+	defer timeout.Stop()
+
+	for {
+		dbChirps, err := cfg.DB.GetChirpsPublishedSince(r.Context(), database.GetChirpsPublishedSinceParams{
+			TenantID:    tenant.FromContext(r.Context()).ID,
+			PublishedAt: since,
+			AfterID:     afterID,
+			MaxChirps:   pollLimit,
+		})
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+			return
+		}
+		if len(dbChirps) > 0 {
+			response, err := cfg.buildChirpList(r.Context(), dbChirps, viewerID, authenticated)
+			if err != nil {
+				handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+				return
+			}
+			if len(response) > 0 {
+				handlers.RespondWithJSON(w, http.StatusOK, response)
+				return
+			}
+			// Everything new was muted; keep waiting past it
+			last := dbChirps[len(dbChirps)-1]
+			since, afterID = last.PublishedAt, last.ID
+		}
+
+		select {
+		case <-wake:
+		case <-timeout.C:
+			handlers.RespondWithJSON(w, http.StatusOK, types.ChirpListResponse{})
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package chirp
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/entitlements"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+// userEntitlements returns what userID may currently do. The account is
+// only loaded when probation is enabled.
+func (cfg *Config) userEntitlements(ctx context.Context, userID uuid.UUID) (entitlements.Entitlements, error) {
+	if !cfg.Entitlements.Enabled() {
+		return entitlements.Full, nil
+	}
+	user, err := cfg.DB.GetUserByID(ctx, database.GetUserByIDParams{
+		TenantID: tenant.FromContext(ctx).ID,
+		ID:       userID,
+	})
+	if err != nil {
+		return entitlements.Entitlements{}, err
+	}
+	return cfg.Entitlements.For(user.CreatedAt, time.Now()), nil
+}
+
+// allowLinks refuses a body with links from an account that may not post
+// them, responding 403. Bodies without links never load the account.
+func (cfg *Config) allowLinks(w http.ResponseWriter, r *http.Request, userID uuid.UUID, body string) bool {
+	if !cfg.Entitlements.Enabled() || len(validation.Links(body)) == 0 {
+		return true
+	}
+	ent, err := cfg.userEntitlements(r.Context(), userID)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't check account standing", err)
+		return false
+	}
+	if !ent.PostLinks {
+		handlers.RespondWithErrorCode(w, http.StatusForbidden, types.ErrCodeProbation, "New accounts can't post links yet", nil)
+		return false
+	}
+	return true
+}
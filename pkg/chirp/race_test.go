@@ -0,0 +1,7 @@
+//go:build race
+
+package chirp
+
+// raceEnabled reports whether the tests run under the race detector, whose
+// instrumentation allocates on its own
+const raceEnabled = true
@@ -0,0 +1,197 @@
+package chirp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/dataloader"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+// handlerReactions dispatches /api/chirps/{id}/reactions requests based on HTTP method
+func (cfg *Config) handlerReactions(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
+	switch r.Method {
+	case http.MethodGet:
+		cfg.handlerReactionsGet(w, r, chirpID)
+	case http.MethodPost, http.MethodDelete:
+		cfg.handlerReact(w, r, chirpID)
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+	}
+}
+
+// handlerReact handles POST /api/chirps/{id}/reactions requests, which add
+// the caller's reaction, and DELETE /api/chirps/{id}/reactions?emoji=
+// requests, which remove it. Both respond with the updated chirp.
+func (cfg *Config) handlerReact(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	emoji := r.URL.Query().Get("emoji")
+	if r.Method == http.MethodPost {
+		var request types.ReactionRequest
+		if !handlers.DecodeJSON(w, r, &request) {
+			return
+		}
+		emoji = request.Emoji
+	}
+	emoji = strings.TrimSpace(emoji)
+
+	if err := validation.ValidateReaction(emoji, cfg.Reactions); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	dbChirp, archived, ok := cfg.getVisibleChirp(w, r, chirpID)
+	if !ok {
+		return
+	}
+	if archived {
+		handlers.RespondWithError(w, http.StatusConflict, "Archived chirps can't be reacted to", nil)
+		return
+	}
+	if dbChirp.Locked && r.Method == http.MethodPost {
+		respondThreadLocked(w)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		err = cfg.DB.AddChirpReaction(r.Context(), database.AddChirpReactionParams{
+			ChirpID: dbChirp.ID,
+			UserID:  userID,
+			Emoji:   emoji,
+		})
+	} else {
+		err = cfg.DB.RemoveChirpReaction(r.Context(), database.RemoveChirpReactionParams{
+			ChirpID: dbChirp.ID,
+			UserID:  userID,
+			Emoji:   emoji,
+		})
+	}
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update reaction", err)
+		return
+	}
+
+	if r.Method == http.MethodPost && userID != dbChirp.UserID {
+		cfg.Events.Publish(events.Event{
+			Type:    events.ChirpReacted,
+			UserID:  userID,
+			ChirpID: dbChirp.ID,
+		})
+	}
+
+	cfg.handlerByIDGet(w, r, chirpID)
+}
+
+// handlerReactionsGet handles GET /api/chirps/{id}/reactions requests, listing
+// who reacted and with what, oldest first. An emoji query parameter limits
+// the list to one reaction.
+func (cfg *Config) handlerReactionsGet(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
+	dbChirp, archived, ok := cfg.getVisibleChirp(w, r, chirpID)
+	if !ok {
+		return
+	}
+
+	var rows []database.ListChirpReactionsRow
+	var err error
+	if archived {
+		var archivedRows []database.ListArchivedChirpReactionsRow
+		archivedRows, err = cfg.DB.ListArchivedChirpReactions(r.Context(), dbChirp.ID)
+		for _, row := range archivedRows {
+			rows = append(rows, database.ListChirpReactionsRow(row))
+		}
+	} else {
+		rows, err = cfg.DB.ListChirpReactions(r.Context(), dbChirp.ID)
+	}
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve reactions", err)
+		return
+	}
+
+	emoji := strings.TrimSpace(r.URL.Query().Get("emoji"))
+	reactions := make([]types.Reaction, 0, len(rows))
+	for _, row := range rows {
+		if emoji != "" && row.Emoji != emoji {
+			continue
+		}
+		reactions = append(reactions, types.Reaction{
+			Emoji: row.Emoji,
+			User: types.ChirpAuthor{
+				ID:       row.UserID,
+				Username: row.Username.String,
+				Verified: row.Verified,
+			},
+			CreatedAt: types.NewTimestamp(row.CreatedAt),
+		})
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, reactions)
+}
+
+// attachReactions adds per-emoji reaction counts to the given chirp responses
+// through the request's reaction loader
+func (cfg *Config) attachReactions(ctx context.Context, chirps []types.ChirpCreateResponse) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+
+	chirpIDs := make([]uuid.UUID, len(chirps))
+	for i, chirp := range chirps {
+		chirpIDs[i] = chirp.ID
+	}
+
+	reactions, err := cfg.reactionLoader(ctx).LoadMany(ctx, chirpIDs)
+	if err != nil {
+		return err
+	}
+	for i := range chirps {
+		chirps[i].Reactions = reactions[chirps[i].ID]
+	}
+	return nil
+}
+
+// reactionLoader returns the request's loader for reaction counts by chirp ID
+func (cfg *Config) reactionLoader(ctx context.Context) *dataloader.Loader[uuid.UUID, []types.ReactionCount] {
+	return dataloader.For(ctx, "chirp.reactions", func(ctx context.Context, chirpIDs []uuid.UUID) (map[uuid.UUID][]types.ReactionCount, error) {
+		counts, err := cfg.DB.GetReactionCounts(ctx, chirpIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		reactions := make(map[uuid.UUID][]types.ReactionCount)
+		for _, count := range counts {
+			reactions[count.ChirpID] = append(reactions[count.ChirpID], types.ReactionCount{
+				Emoji: count.Emoji,
+				Count: count.Count,
+			})
+		}
+		return reactions, nil
+	})
+}
+
+// attachArchivedReactions adds reaction counts to a single archived chirp response
+func (cfg *Config) attachArchivedReactions(ctx context.Context, chirp *types.ChirpCreateResponse) error {
+	counts, err := cfg.DB.GetArchivedReactionCounts(ctx, []uuid.UUID{chirp.ID})
+	if err != nil {
+		return err
+	}
+	for _, count := range counts {
+		chirp.Reactions = append(chirp.Reactions, types.ReactionCount{
+			Emoji: count.Emoji,
+			Count: count.Count,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package chirp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+var (
+	ErrParentNotFound = errors.New("Parent chirp not found")
+	ErrThreadLocked   = errors.New("This thread is locked")
+)
+
+// handlerReplies handles GET /api/chirps/{id}/replies requests, listing the
+// direct replies to a chirp oldest first
+func (cfg *Config) handlerReplies(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
+	dbChirp, _, ok := cfg.getVisibleChirp(w, r, chirpID)
+	if !ok {
+		return
+	}
+
+	viewerID, authenticated, err := cfg.optionalViewer(r)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	dbReplies, err := cfg.DB.GetChirpReplies(r.Context(), database.GetChirpRepliesParams{
+		TenantID:      tenant.FromContext(r.Context()).ID,
+		ParentChirpID: dbChirp.ID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+		return
+	}
+
+	response, err := cfg.buildChirpList(r.Context(), dbReplies, viewerID, authenticated)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// checkReplyParent reports ErrParentNotFound unless userID may see the chirp
+// they are replying to, and ErrThreadLocked if a moderator locked it.
+// Archived chirps can still be replied to.
+func (cfg *Config) checkReplyParent(ctx context.Context, parentID, userID uuid.UUID) error {
+	parent, _, err := cfg.getChirp(ctx, parentID)
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			return ErrParentNotFound
+		}
+		return err
+	}
+	if parent.PublishedAt.After(time.Now()) && parent.UserID != userID {
+		return ErrParentNotFound
+	}
+
+	active, err := cfg.DB.IsUserActive(ctx, parent.UserID)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return ErrParentNotFound
+	}
+	if parent.Locked {
+		return ErrThreadLocked
+	}
+	return nil
+}
+
+// respondThreadLocked rejects a reply, reaction or like on a locked chirp
+func respondThreadLocked(w http.ResponseWriter) {
+	handlers.RespondWithErrorCode(w, http.StatusForbidden, types.ErrCodeThreadLocked, ErrThreadLocked.Error(), nil)
+}
@@ -0,0 +1,121 @@
+package chirp
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+func TestCreateReply(t *testing.T) {
+	cfg := newBenchConfig(0)
+	parentID := uuid.New()
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/chirps", strings.NewReader(`{"body":"me too","parent_chirp_id":"`+parentID.String()+`"}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	cfg.HandlerCreate(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body = %s", rec.Code, http.StatusCreated, rec.Body)
+	}
+
+	var reply types.ChirpCreateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.ParentChirpID == nil || *reply.ParentChirpID != parentID {
+		t.Errorf("parent_chirp_id = %v, want %s", reply.ParentChirpID, parentID)
+	}
+}
+
+func TestHandlerReplies(t *testing.T) {
+	cfg := newBenchConfig(3)
+	path := "/api/chirps/" + uuid.NewString() + "/replies"
+
+	rec := httptest.NewRecorder()
+	cfg.HandlerByID(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body = %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	var replies []map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &replies); err != nil {
+		t.Fatal(err)
+	}
+	if len(replies) != 3 {
+		t.Fatalf("got %d replies, want 3", len(replies))
+	}
+	if _, ok := replies[0]["reply_count"]; !ok {
+		t.Errorf("reply is missing reply_count: %v", replies[0])
+	}
+
+	rec = httptest.NewRecorder()
+	cfg.HandlerByID(rec, httptest.NewRequest(http.MethodPost, path, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestLockedChirp(t *testing.T) {
+	cfg := newBenchConfig(0)
+	cfg.DB = database.New(sql.OpenDB(&benchConnector{likes: map[[2]string]bool{}, locked: true}))
+	cfg.Reactions = validation.NewReactions(nil)
+	chirpID := uuid.NewString()
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{name: "reply", method: http.MethodPost, path: "/api/chirps", body: `{"body":"me too","parent_chirp_id":"` + chirpID + `"}`, wantStatus: http.StatusForbidden},
+		{name: "react", method: http.MethodPost, path: "/api/chirps/" + chirpID + "/reactions", body: `{"emoji":"` + cfg.Reactions[0] + `"}`, wantStatus: http.StatusForbidden},
+		{name: "like", method: http.MethodPost, path: "/api/chirps/" + chirpID + "/like", wantStatus: http.StatusForbidden},
+		{name: "repost", method: http.MethodPost, path: "/api/chirps/" + chirpID + "/repost", wantStatus: http.StatusForbidden},
+		{name: "unlike", method: http.MethodDelete, path: "/api/chirps/" + chirpID + "/like", wantStatus: http.StatusOK},
+		{name: "view", method: http.MethodGet, path: "/api/chirps/" + chirpID, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			if tt.path == "/api/chirps" {
+				cfg.HandlerCreate(rec, req)
+			} else {
+				cfg.HandlerByID(rec, req)
+			}
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+
+			var response map[string]any
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantStatus == http.StatusForbidden && response["code"] != types.ErrCodeThreadLocked {
+				t.Errorf("code = %v, want %s", response["code"], types.ErrCodeThreadLocked)
+			}
+			if tt.wantStatus == http.StatusOK && response["locked"] != true {
+				t.Errorf("locked = %v, want true", response["locked"])
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package chirp
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// handlerReport handles POST /api/chirps/{id}/report requests, which flag a
+// chirp for moderators. A user can have one open report per chirp.
+func (cfg *Config) handlerReport(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	var request types.ReportRequest
+	if !handlers.DecodeJSON(w, r, &request) {
+		return
+	}
+
+	dbChirp, archived, ok := cfg.getVisibleChirp(w, r, chirpID)
+	if !ok {
+		return
+	}
+	if archived {
+		handlers.RespondWithError(w, http.StatusConflict, "Archived chirps can't be reported", nil)
+		return
+	}
+	if dbChirp.UserID == userID {
+		handlers.RespondWithError(w, http.StatusBadRequest, "You can't report your own chirp", nil)
+		return
+	}
+
+	report, err := cfg.DB.CreateReport(r.Context(), database.CreateReportParams{
+		TenantID:   tenant.FromContext(r.Context()).ID,
+		ChirpID:    dbChirp.ID,
+		ReporterID: userID,
+		Reason:     request.Reason,
+		Details:    request.Details,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusConflict, "You already reported this chirp", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't report chirp", err)
+		}
+		return
+	}
+
+	cfg.Events.Publish(events.Event{
+		Type:     events.ChirpReported,
+		TenantID: report.TenantID,
+		UserID:   userID,
+		ChirpID:  dbChirp.ID,
+	})
+
+	handlers.RespondWithJSON(w, http.StatusCreated, types.Report{
+		ID:            report.ID,
+		CreatedAt:     types.NewTimestamp(report.CreatedAt),
+		ChirpID:       report.ChirpID,
+		ChirpAuthorID: dbChirp.UserID,
+		ReporterID:    report.ReporterID,
+		Reason:        report.Reason,
+		Details:       report.Details,
+	})
+}
@@ -0,0 +1,60 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestHandlerReport(t *testing.T) {
+	cfg := newBenchConfig(0)
+	chirpID := uuid.NewString()
+	reporterID := uuid.New()
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		body   string
+		want   int
+	}{
+		{name: "spam", userID: reporterID, body: `{"reason":"spam","details":"Same link in every reply"}`, want: http.StatusCreated},
+		{name: "unknown reason", userID: reporterID, body: `{"reason":"boring"}`, want: http.StatusBadRequest},
+		{name: "own chirp", userID: benchUserID, body: `{"reason":"spam"}`, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := auth.MakeJWT(tt.userID, benchSecret, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/api/chirps/"+chirpID+"/report", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			cfg.HandlerByID(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want != http.StatusCreated {
+				return
+			}
+
+			var report types.Report
+			if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+				t.Fatal(err)
+			}
+			if report.ChirpID.String() != chirpID || report.ReporterID != reporterID || report.Reason != "spam" {
+				t.Errorf("report = %+v", report)
+			}
+			if report.ResolvedAt != nil {
+				t.Error("new report is already resolved")
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package chirp
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// handlerRepost handles POST /api/chirps/{id}/repost requests, which share
+// the chirp as a new chirp of the user's own. Reposting a repost shares its
+// original instead.
+func (cfg *Config) handlerRepost(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	dbChirp, archived, ok := cfg.getVisibleChirp(w, r, chirpID)
+	if !ok {
+		return
+	}
+	if dbChirp.RepostOfChirpID.Valid && !archived {
+		if dbChirp, archived, ok = cfg.getVisibleChirp(w, r, dbChirp.RepostOfChirpID.UUID); !ok {
+			return
+		}
+	}
+	if archived {
+		handlers.RespondWithError(w, http.StatusConflict, "Archived chirps can't be reposted", nil)
+		return
+	}
+	if dbChirp.PublishedAt.After(time.Now()) {
+		handlers.RespondWithError(w, http.StatusConflict, "Pending chirps can't be reposted", nil)
+		return
+	}
+	if dbChirp.Locked {
+		respondThreadLocked(w)
+		return
+	}
+
+	source, oauthClientID, err := cfg.chirpSource(r)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't repost chirp", err)
+		return
+	}
+	repostID, err := cfg.newChirpID()
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't repost chirp", err)
+		return
+	}
+	repost, err := cfg.DB.CreateRepost(r.Context(), database.CreateRepostParams{
+		ID:              repostID,
+		UserID:          userID,
+		TenantID:        tenant.FromContext(r.Context()).ID,
+		Source:          source,
+		OauthClientID:   oauthClientID,
+		RepostOfChirpID: dbChirp.ID,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusConflict, "Chirp already reposted", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't repost chirp", err)
+		}
+		return
+	}
+
+	cfg.publishChirpCreated(repost)
+
+	response := []types.ChirpCreateResponse{handlers.BuildChirpResponse(repost)}
+	err = cfg.attachAuthors(r.Context(), response)
+	if err == nil {
+		err = cfg.attachOriginals(r.Context(), response, userID, true)
+	}
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirp, err)
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusCreated, response[0])
+}
+
+// attachOriginals embeds the chirp each repost shares, built as the viewer
+// would see it in a list. Originals the viewer can't see, because they were
+// archived, deleted, muted or their author left, are not embedded.
+func (cfg *Config) attachOriginals(ctx context.Context, chirps []types.ChirpCreateResponse, viewerID uuid.UUID, authenticated bool) error {
+	var originalIDs []uuid.UUID
+	for i := range chirps {
+		if chirps[i].RepostOfChirpID != nil {
+			originalIDs = append(originalIDs, *chirps[i].RepostOfChirpID)
+		}
+	}
+	if len(originalIDs) == 0 {
+		return nil
+	}
+
+	dbOriginals, err := cfg.DB.GetChirpsByIDs(ctx, database.GetChirpsByIDsParams{
+		TenantID: tenant.FromContext(ctx).ID,
+		Ids:      originalIDs,
+	})
+	if err != nil {
+		return err
+	}
+	// Originals are never reposts themselves, so this doesn't recurse further
+	originals, err := cfg.buildChirpList(ctx, dbOriginals, viewerID, authenticated)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[uuid.UUID]*types.ChirpCreateResponse, len(originals))
+	for i := range originals {
+		byID[originals[i].ID] = &originals[i]
+	}
+	for i := range chirps {
+		if chirps[i].RepostOfChirpID != nil {
+			chirps[i].OriginalChirp = byID[*chirps[i].RepostOfChirpID]
+		}
+	}
+	return nil
+}
+
+// dropOrphanedReposts removes reposts whose original wasn't embedded, which
+// would otherwise show up in lists as empty chirps
+func dropOrphanedReposts(chirps []types.ChirpCreateResponse) []types.ChirpCreateResponse {
+	kept := chirps[:0]
+	for _, chirp := range chirps {
+		if chirp.RepostOfChirpID == nil || chirp.OriginalChirp != nil {
+			kept = append(kept, chirp)
+		}
+	}
+	return kept
+}
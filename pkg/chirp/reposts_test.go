@@ -0,0 +1,77 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestHandlerRepost(t *testing.T) {
+	cfg := newBenchConfig(0)
+	chirpID := uuid.NewString()
+	path := "/api/chirps/" + chirpID + "/repost"
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	cfg.HandlerByID(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body = %s", rec.Code, http.StatusCreated, rec.Body)
+	}
+
+	var repost types.ChirpCreateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &repost); err != nil {
+		t.Fatal(err)
+	}
+	if repost.RepostOfChirpID == nil || repost.RepostOfChirpID.String() != chirpID {
+		t.Errorf("repost_of_chirp_id = %v, want %s", repost.RepostOfChirpID, chirpID)
+	}
+	if repost.OriginalChirp == nil || repost.OriginalChirp.ID.String() != chirpID {
+		t.Fatalf("original_chirp = %+v, want chirp %s", repost.OriginalChirp, chirpID)
+	}
+	if repost.OriginalChirp.Body == "" {
+		t.Error("original_chirp is missing its body")
+	}
+
+	rec = httptest.NewRecorder()
+	cfg.HandlerByID(rec, httptest.NewRequest(http.MethodPost, path, nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	rec = httptest.NewRecorder()
+	cfg.HandlerByID(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestDropOrphanedReposts(t *testing.T) {
+	originalID := uuid.New()
+	chirps := []types.ChirpCreateResponse{
+		{ID: uuid.New()},
+		{ID: uuid.New(), RepostOfChirpID: &originalID},
+		{ID: uuid.New(), RepostOfChirpID: &originalID, OriginalChirp: &types.ChirpCreateResponse{ID: originalID}},
+	}
+	want := []uuid.UUID{chirps[0].ID, chirps[2].ID}
+
+	got := dropOrphanedReposts(chirps)
+	if len(got) != len(want) {
+		t.Fatalf("len = %d, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].ID != want[i] {
+			t.Errorf("chirp %d = %s, want %s", i, got[i].ID, want[i])
+		}
+	}
+}
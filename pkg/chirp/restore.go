@@ -0,0 +1,72 @@
+package chirp
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+)
+
+// RestoreWindow is how long after deleting a chirp its author can restore
+// it; the purge job removes it for good afterwards
+const RestoreWindow = 30 * 24 * time.Hour
+
+// handlerRestore handles POST /api/chirps/{id}/restore requests, which undo
+// the author's deletion of a chirp within the restore window and respond
+// with the chirp
+func (cfg *Config) handlerRestore(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	restored, err := cfg.DB.RestoreChirp(r.Context(), database.RestoreChirpParams{
+		ID:       chirpID,
+		UserID:   userID,
+		TenantID: tenant.FromContext(r.Context()).ID,
+		Cutoff:   time.Now().UTC().Add(-RestoreWindow),
+	})
+	if err != nil {
+		switch {
+		case err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set":
+			handlers.RespondWithError(w, http.StatusNotFound, "No deleted chirp to restore", nil)
+		case strings.Contains(err.Error(), "idx_chirps_repost_of_chirp_id"):
+			// The chirp was reposted again since this repost was deleted
+			handlers.RespondWithError(w, http.StatusConflict, "Chirp already reposted", err)
+		default:
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't restore chirp", err)
+		}
+		return
+	}
+
+	cfg.Events.Publish(events.Event{
+		Type:    events.ChirpRestored,
+		UserID:  restored.UserID,
+		ChirpID: restored.ID,
+	})
+	cfg.handlerByIDGet(w, r, restored.ID)
+}
+
+// PurgeDeletedChirps permanently deletes chirps deleted longer than the
+// restore window ago. Their media, reactions and other rows go by cascade.
+func (cfg *Config) PurgeDeletedChirps(ctx context.Context) error {
+	purged, err := cfg.DB.PurgeDeletedChirps(ctx, time.Now().UTC().Add(-RestoreWindow))
+	if err != nil {
+		return err
+	}
+	if purged > 0 {
+		log.Printf("Permanently deleted %d chirps", purged)
+	}
+	return nil
+}
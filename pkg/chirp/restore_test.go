@@ -0,0 +1,63 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestHandlerRestore(t *testing.T) {
+	cfg := newBenchConfig(0)
+	chirpID := uuid.NewString()
+	path := "/api/chirps/" + chirpID + "/restore"
+
+	tests := []struct {
+		name   string
+		method string
+		userID uuid.UUID
+		want   int
+	}{
+		{name: "author", method: http.MethodPost, userID: benchUserID, want: http.StatusOK},
+		{name: "someone else", method: http.MethodPost, userID: uuid.New(), want: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodGet, userID: benchUserID, want: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := auth.MakeJWT(tt.userID, benchSecret, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(tt.method, path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			cfg.HandlerByID(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			var chirp types.ChirpCreateResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &chirp); err != nil {
+				t.Fatal(err)
+			}
+			if chirp.ID.String() != chirpID {
+				t.Errorf("restored chirp = %s, want %s", chirp.ID, chirpID)
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	cfg.HandlerByID(rec, httptest.NewRequest(http.MethodPost, path, nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
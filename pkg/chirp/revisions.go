@@ -0,0 +1,245 @@
+package chirp
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/worddiff"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+// handlerByIDUpdate handles PUT /api/chirps/{id} requests.
+// The previous body is kept in chirp_revisions so edits stay auditable.
+func (cfg *Config) handlerByIDUpdate(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
+	if !cfg.AllowEdits {
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, "Chirp editing is disabled", nil)
+		return
+	}
+
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	var request types.ChirpUpdateRequest
+	if !handlers.DecodeJSON(w, r, &request) {
+		return
+	}
+
+	if err := validation.ValidateChirpBody(request.Body, cfg.Counting); err != nil {
+		cfg.respondBodyError(w, request.Body, err)
+		return
+	}
+	if err := validation.ValidateChirpTags(request.Body); err != nil {
+		respondTagError(w, err)
+		return
+	}
+	if !cfg.allowLinks(w, r, userID, request.Body) {
+		return
+	}
+
+	// Retrieve chirp from database to verify ownership
+	dbChirp, err := cfg.DB.GetChirpByID(r.Context(), chirpID)
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirp, err)
+		}
+		return
+	}
+
+	// Only the author can edit a chirp
+	if dbChirp.UserID != userID {
+		handlers.RespondWithError(w, http.StatusForbidden, "Forbidden", nil)
+		return
+	}
+	if dbChirp.RepostOfChirpID.Valid {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Reposts can't be edited", nil)
+		return
+	}
+
+	cleanedBody := cfg.Profanity.Clean(request.Body)
+	updatedChirp, err := cfg.DB.UpdateChirpBody(r.Context(), database.UpdateChirpBodyParams{
+		ID:       chirpID,
+		Body:     cleanedBody,
+		Language: cfg.detectLanguage(cleanedBody),
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+	if err := cfg.setHashtags(r.Context(), updatedChirp.ID, validation.Hashtags(updatedChirp.Body)); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+	if err := cfg.setMentions(r.Context(), updatedChirp, validation.Mentions(updatedChirp.Body)); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+	if err := cfg.setChirpLink(r.Context(), updatedChirp.ID, updatedChirp.Body); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+	cfg.classifyChirp(updatedChirp)
+
+	response, err := cfg.buildChirpList(r.Context(), []database.Chirp{updatedChirp}, userID, true)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirp, err)
+		return
+	}
+	// The author still gets their chirp back when it matches their muted words
+	if len(response) == 0 {
+		handlers.RespondWithJSON(w, http.StatusOK, handlers.BuildChirpResponse(updatedChirp))
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response[0])
+}
+
+// handlerHistory handles GET /api/chirps/{id}/history requests.
+// Only the author and moderators can see previous versions.
+func (cfg *Config) handlerHistory(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
+	revisions, ok := cfg.visibleRevisions(w, r, chirpID)
+	if !ok {
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, types.ChirpHistoryResponse{
+		ChirpID:   chirpID,
+		Revisions: revisions,
+	})
+}
+
+// handlerRevisionDiff handles GET /api/chirps/{id}/history/{rev}/diff
+// requests, showing the words changed from the previous revision, or from
+// ?from=N, to revision rev. Revision 0 is the empty text before the first.
+func (cfg *Config) handlerRevisionDiff(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID, rev string) {
+	to, err := strconv.Atoi(rev)
+	if err != nil || to < 1 {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid revision", err)
+		return
+	}
+	from := to - 1
+	if fromParam := r.URL.Query().Get("from"); fromParam != "" {
+		from, err = strconv.Atoi(fromParam)
+		if err != nil || from < 0 || from >= to {
+			handlers.RespondWithError(w, http.StatusBadRequest, "from must be an earlier revision", err)
+			return
+		}
+	}
+
+	revisions, ok := cfg.visibleRevisions(w, r, chirpID)
+	if !ok {
+		return
+	}
+	if to > len(revisions) {
+		handlers.RespondWithError(w, http.StatusNotFound, "Revision not found", nil)
+		return
+	}
+
+	var fromBody string
+	if from > 0 {
+		fromBody = revisions[from-1].Body
+	}
+	spans := worddiff.Diff(fromBody, revisions[to-1].Body)
+	changes := make([]types.DiffSpan, len(spans))
+	for i, span := range spans {
+		changes[i] = types.DiffSpan(span)
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, types.ChirpRevisionDiff{
+		ChirpID:      chirpID,
+		FromRevision: int32(from),
+		ToRevision:   int32(to),
+		Unified:      worddiff.Unified(spans),
+		Changes:      changes,
+	})
+}
+
+// visibleRevisions returns every version of a chirp, oldest first, if the
+// requester is its author or a moderator. Otherwise it responds with the
+// error and returns false.
+func (cfg *Config) visibleRevisions(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) ([]types.ChirpRevision, bool) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return nil, false
+	}
+	userID := principal.UserID
+
+	dbChirp, archived, err := cfg.getChirp(r.Context(), chirpID)
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirp, err)
+		}
+		return nil, false
+	}
+
+	if dbChirp.UserID != userID {
+		isModerator, err := cfg.isModerator(r.Context(), principal)
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve user role", err)
+			return nil, false
+		}
+		if !isModerator {
+			handlers.RespondWithError(w, http.StatusForbidden, "Forbidden", nil)
+			return nil, false
+		}
+	}
+
+	dbRevisions, err := cfg.getRevisions(r.Context(), chirpID, archived)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp history", err)
+		return nil, false
+	}
+	return buildRevisions(dbChirp, dbRevisions), true
+}
+
+// revisionDiffPath returns the revision in a "history/{rev}/diff"
+// sub-resource path
+func revisionDiffPath(subresource string) (string, bool) {
+	rest, ok := strings.CutPrefix(subresource, "history/")
+	if !ok {
+		return "", false
+	}
+	rev, ok := strings.CutSuffix(rest, "/diff")
+	return rev, ok && rev != "" && !strings.Contains(rev, "/")
+}
+
+// buildRevisions lists every version of a chirp oldest first, ending with the
+// current body as the latest revision
+func buildRevisions(current database.Chirp, dbRevisions []database.ChirpRevision) []types.ChirpRevision {
+	revisions := make([]types.ChirpRevision, 0, len(dbRevisions)+1)
+	for _, revision := range dbRevisions {
+		revisions = append(revisions, types.ChirpRevision{
+			Revision:  revision.Revision,
+			CreatedAt: types.NewTimestamp(revision.CreatedAt),
+			Body:      revision.Body,
+		})
+	}
+	return append(revisions, types.ChirpRevision{
+		Revision:  int32(len(dbRevisions) + 1),
+		CreatedAt: types.NewTimestamp(current.UpdatedAt),
+		Body:      current.Body,
+	})
+}
+
+// isModerator reports whether the principal has a moderator or admin role
+func (cfg *Config) isModerator(ctx context.Context, principal *auth.Principal) (bool, error) {
+	role, err := principal.Role(ctx, cfg.DB.GetUserRole)
+	if err != nil {
+		return false, err
+	}
+	return role == types.RoleModerator || role == types.RoleAdmin, nil
+}
@@ -0,0 +1,88 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestBuildRevisions(t *testing.T) {
+	created := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	edited := created.Add(time.Hour)
+
+	current := database.Chirp{Body: "second version", UpdatedAt: edited}
+	stored := []database.ChirpRevision{
+		{Revision: 1, Body: "first version", CreatedAt: created},
+	}
+
+	revisions := buildRevisions(current, stored)
+	if len(revisions) != 2 {
+		t.Fatalf("buildRevisions() returned %d revisions, want 2", len(revisions))
+	}
+
+	if revisions[0].Revision != 1 || revisions[0].Body != "first version" {
+		t.Errorf("revisions[0] = %+v, want revision 1 with the original body", revisions[0])
+	}
+
+	latest := revisions[1]
+	if latest.Revision != 2 || latest.Body != "second version" || !latest.CreatedAt.Equal(edited) {
+		t.Errorf("revisions[1] = %+v, want revision 2 with the current body", latest)
+	}
+}
+
+func TestHandlerRevisionDiff(t *testing.T) {
+	cfg := newBenchConfig(0)
+	author, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := auth.MakeJWT(uuid.New(), benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chirpID := uuid.NewString()
+
+	tests := []struct {
+		name        string
+		path        string
+		token       string
+		wantStatus  int
+		wantUnified string
+	}{
+		{name: "previous revision", path: "/history/2/diff", token: author, wantStatus: http.StatusOK, wantUnified: "Just setting up my {+chirpy, this is +}chirp{+ body text+}"},
+		{name: "first revision", path: "/history/1/diff", token: author, wantStatus: http.StatusOK, wantUnified: "{+Just setting up my chirp+}"},
+		{name: "from revision", path: "/history/2/diff?from=0", token: author, wantStatus: http.StatusOK, wantUnified: "{+Just setting up my chirpy, this is chirp body text+}"},
+		{name: "from a later revision", path: "/history/1/diff?from=2", token: author, wantStatus: http.StatusBadRequest},
+		{name: "missing revision", path: "/history/3/diff", token: author, wantStatus: http.StatusNotFound},
+		{name: "invalid revision", path: "/history/latest/diff", token: author, wantStatus: http.StatusBadRequest},
+		{name: "not the author", path: "/history/2/diff", token: other, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/chirps/"+chirpID+tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			cfg.HandlerByID(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+			var diff types.ChirpRevisionDiff
+			if err := json.Unmarshal(rec.Body.Bytes(), &diff); err != nil {
+				t.Fatal(err)
+			}
+			if diff.Unified != tt.wantUnified {
+				t.Errorf("unified = %q, want %q", diff.Unified, tt.wantUnified)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package chirp
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+const scheduledPrefix = "/api/scheduled-chirps/"
+
+// scheduleConflictThreshold is how many chirps scheduled in the same minute
+// earn a warning; followers see them as a burst
+const scheduleConflictThreshold = 3
+
+// HandlerScheduledChirps handles /api/scheduled-chirps requests for the
+// authenticated user's chirps that are waiting to be published. GET lists
+// them by day, PUT /api/scheduled-chirps/{id} edits or reschedules one and
+// DELETE cancels it.
+func (cfg *Config) HandlerScheduledChirps(w http.ResponseWriter, r *http.Request) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	idString, subresource := handlers.SplitResourcePath(r.URL.Path, scheduledPrefix)
+	if idString == "" {
+		if !handlers.RequireMethod(w, r, http.MethodGet) {
+			return
+		}
+		cfg.handlerScheduledList(w, r, userID)
+		return
+	}
+	if subresource != "" {
+		handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+		return
+	}
+
+	chirpID, err := uuid.Parse(idString)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid chirp ID format", err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		cfg.handlerScheduledUpdate(w, r, userID, chirpID)
+	case http.MethodDelete:
+		cfg.handlerScheduledCancel(w, r, userID, chirpID)
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+	}
+}
+
+// handlerScheduledList groups the user's scheduled chirps by date in the
+// ?tz= time zone (default UTC) and flags minutes with too many of them
+func (cfg *Config) handlerScheduledList(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	location := time.UTC
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		var err error
+		if location, err = time.LoadLocation(tz); err != nil {
+			handlers.RespondWithError(w, http.StatusBadRequest, "Invalid time zone", err)
+			return
+		}
+	}
+
+	dbChirps, err := cfg.DB.GetScheduledChirps(r.Context(), userID)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve scheduled chirps", err)
+		return
+	}
+	chirps, err := cfg.buildScheduledResponses(r.Context(), dbChirps)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve scheduled chirps", err)
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, types.ScheduledChirpsResponse{
+		TimeZone:  location.String(),
+		Days:      groupByDay(chirps, dbChirps, location),
+		Conflicts: findConflicts(dbChirps),
+	})
+}
+
+func (cfg *Config) handlerScheduledUpdate(w http.ResponseWriter, r *http.Request, userID, chirpID uuid.UUID) {
+	var request types.ScheduledChirpUpdateRequest
+	if !handlers.DecodeJSON(w, r, &request) {
+		return
+	}
+
+	params := database.UpdateScheduledChirpParams{
+		ID:     chirpID,
+		UserID: userID,
+	}
+	if request.Body != nil {
+		if err := validation.ValidateChirpBody(*request.Body, cfg.Counting); err != nil {
+			cfg.respondBodyError(w, *request.Body, err)
+			return
+		}
+		if err := validation.ValidateChirpTags(*request.Body); err != nil {
+			respondTagError(w, err)
+			return
+		}
+		if !cfg.allowLinks(w, r, userID, *request.Body) {
+			return
+		}
+		params.Body = sql.NullString{String: cfg.Profanity.Clean(*request.Body), Valid: true}
+		params.Language = sql.NullString{String: cfg.detectLanguage(params.Body.String), Valid: true}
+	}
+	if request.Sensitive != nil {
+		params.Sensitive = sql.NullBool{Bool: *request.Sensitive, Valid: true}
+	}
+	if request.ScheduledAt != nil {
+		if err := validation.ValidateChirpSchedule(*request.ScheduledAt, time.Now()); err != nil {
+			handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		params.PublishAt = sql.NullTime{Time: request.ScheduledAt.UTC(), Valid: true}
+	}
+
+	updated, err := cfg.DB.UpdateScheduledChirp(r.Context(), params)
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusNotFound, "Scheduled chirp not found", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		}
+		return
+	}
+	if request.Body != nil {
+		if err := cfg.setHashtags(r.Context(), updated.ID, validation.Hashtags(updated.Body)); err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+			return
+		}
+		if err := cfg.setMentions(r.Context(), updated, validation.Mentions(updated.Body)); err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+			return
+		}
+		if err := cfg.setChirpLink(r.Context(), updated.ID, updated.Body); err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+			return
+		}
+		cfg.classifyChirp(updated)
+	}
+
+	response, err := cfg.buildScheduledResponses(r.Context(), []database.Chirp{updated})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirp, err)
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response[0])
+}
+
+// handlerScheduledCancel deletes a scheduled chirp the way DELETE
+// /api/chirps/{id} does, so it can still be restored
+func (cfg *Config) handlerScheduledCancel(w http.ResponseWriter, r *http.Request, userID, chirpID uuid.UUID) {
+	_, err := cfg.DB.GetScheduledChirp(r.Context(), database.GetScheduledChirpParams{
+		ID:     chirpID,
+		UserID: userID,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusNotFound, "Scheduled chirp not found", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirp, err)
+		}
+		return
+	}
+	cfg.handlerByIDDelete(w, r, chirpID)
+}
+
+// buildScheduledResponses converts the author's own scheduled chirps to
+// responses. They have no engagement yet, so only media and authors are
+// attached.
+func (cfg *Config) buildScheduledResponses(ctx context.Context, dbChirps []database.Chirp) ([]types.ChirpCreateResponse, error) {
+	response := handlers.BuildChirpListResponse(dbChirps)
+	if err := cfg.attachMedia(ctx, response); err != nil {
+		return nil, err
+	}
+	if err := cfg.attachLinkPreviews(ctx, response); err != nil {
+		return nil, err
+	}
+	if err := cfg.attachAuthors(ctx, response); err != nil {
+		return nil, err
+	}
+	if err := cfg.attachCoauthors(ctx, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// groupByDay splits chirps, ordered by publish time, into dates in location
+func groupByDay(chirps []types.ChirpCreateResponse, dbChirps []database.Chirp, location *time.Location) []types.ScheduledDay {
+	days := []types.ScheduledDay{}
+	for i := range chirps {
+		date := dbChirps[i].PublishedAt.In(location).Format(time.DateOnly)
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, types.ScheduledDay{Date: date})
+		}
+		days[len(days)-1].Chirps = append(days[len(days)-1].Chirps, chirps[i])
+	}
+	return days
+}
+
+// findConflicts reports the minutes, soonest first, with at least
+// scheduleConflictThreshold chirps scheduled in them
+func findConflicts(dbChirps []database.Chirp) []types.ScheduleConflict {
+	conflicts := []types.ScheduleConflict{}
+	for start := 0; start < len(dbChirps); {
+		minute := dbChirps[start].PublishedAt.Truncate(time.Minute)
+		end := start
+		var chirpIDs []uuid.UUID
+		for end < len(dbChirps) && dbChirps[end].PublishedAt.Truncate(time.Minute).Equal(minute) {
+			chirpIDs = append(chirpIDs, dbChirps[end].ID)
+			end++
+		}
+		if len(chirpIDs) >= scheduleConflictThreshold {
+			conflicts = append(conflicts, types.ScheduleConflict{
+				Minute:   types.NewTimestamp(minute),
+				ChirpIDs: chirpIDs,
+			})
+		}
+		start = end
+	}
+	return conflicts
+}
+
+// PublishScheduledChirps announces scheduled chirps whose time has come.
+// Dequeuing and announcing happen together, so with several replicas each
+// chirp is announced once.
+func (cfg *Config) PublishScheduledChirps(ctx context.Context) error {
+	due, err := cfg.DB.TakeDueScheduledChirps(ctx)
+	if err != nil {
+		return err
+	}
+	for _, chirp := range due {
+		cfg.Events.Publish(events.Event{
+			Type:       events.ChirpCreated,
+			OccurredAt: chirp.PublishedAt,
+			UserID:     chirp.UserID,
+			ChirpID:    chirp.ID,
+		})
+	}
+	if len(due) > 0 {
+		log.Printf("Published %d scheduled chirps", len(due))
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestCreateScheduledChirp(t *testing.T) {
+	cfg := newBenchConfig(0)
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduledAt := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second).Format(time.RFC3339)
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "scheduled", body: `{"body":"See you tomorrow","scheduled_at":"` + scheduledAt + `"}`, want: http.StatusCreated},
+		{name: "in the past", body: `{"body":"Too late","scheduled_at":"2020-01-01T00:00:00Z"}`, want: http.StatusBadRequest},
+		{name: "with a delay", body: `{"body":"Both","scheduled_at":"` + scheduledAt + `","delay_seconds":30}`, want: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/chirps", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			cfg.HandlerCreate(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want != http.StatusCreated {
+				return
+			}
+
+			var chirp types.ChirpCreateResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &chirp); err != nil {
+				t.Fatal(err)
+			}
+			if !chirp.Pending {
+				t.Error("scheduled chirp isn't pending")
+			}
+			if got := chirp.PublishedAt.Time.UTC().Format(time.RFC3339); got != scheduledAt {
+				t.Errorf("published_at = %s, want %s", got, scheduledAt)
+			}
+		})
+	}
+}
+
+func TestHandlerScheduledChirpsList(t *testing.T) {
+	cfg := newBenchConfig(4)
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/scheduled-chirps?tz=America/New_York", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	cfg.HandlerScheduledChirps(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	var response types.ScheduledChirpsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Days) != 2 {
+		t.Fatalf("got %d days, want 2", len(response.Days))
+	}
+	// 02:00 UTC is the evening before in New York
+	if want := benchScheduleStart.In(newYork).Format(time.DateOnly); response.Days[0].Date != want {
+		t.Errorf("first day = %s, want %s", response.Days[0].Date, want)
+	}
+	if len(response.Days[0].Chirps) != 3 || len(response.Days[1].Chirps) != 1 {
+		t.Errorf("chirps per day = %d, %d, want 3, 1", len(response.Days[0].Chirps), len(response.Days[1].Chirps))
+	}
+	if len(response.Conflicts) != 1 || len(response.Conflicts[0].ChirpIDs) != 3 {
+		t.Fatalf("conflicts = %+v, want one of three chirps", response.Conflicts)
+	}
+	if !response.Conflicts[0].Minute.Time.Equal(benchScheduleStart) {
+		t.Errorf("conflict minute = %s, want %s", response.Conflicts[0].Minute.Time, benchScheduleStart)
+	}
+}
+
+func TestHandlerScheduledChirpsInvalidTimeZone(t *testing.T) {
+	cfg := newBenchConfig(0)
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/scheduled-chirps?tz=Mars/Olympus_Mons", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	cfg.HandlerScheduledChirps(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandlerScheduledChirpsUpdate(t *testing.T) {
+	cfg := newBenchConfig(0)
+	chirpID := uuid.NewString()
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		body   string
+		want   int
+	}{
+		{name: "new body", userID: benchUserID, body: `{"body":"Changed my mind"}`, want: http.StatusOK},
+		{name: "too far ahead", userID: benchUserID, body: `{"scheduled_at":"` + time.Now().AddDate(0, 2, 0).Format(time.RFC3339) + `"}`, want: http.StatusBadRequest},
+		{name: "someone else's", userID: uuid.New(), body: `{"body":"Changed my mind"}`, want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := auth.MakeJWT(tt.userID, benchSecret, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(http.MethodPut, "/api/scheduled-chirps/"+chirpID, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			cfg.HandlerScheduledChirps(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			var chirp types.ChirpCreateResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &chirp); err != nil {
+				t.Fatal(err)
+			}
+			if chirp.Body != "Changed my mind" {
+				t.Errorf("body = %q", chirp.Body)
+			}
+		})
+	}
+}
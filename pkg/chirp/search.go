@@ -0,0 +1,85 @@
+package chirp
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+const (
+	// searchDefaultLimit is the page size when ?limit= is not given
+	searchDefaultLimit = 20
+
+	// searchMaxLimit caps ?limit=
+	searchMaxLimit = 100
+
+	// searchMaxQueryLength caps ?q= so a search stays cheap to parse
+	searchMaxQueryLength = 256
+)
+
+// HandlerSearch handles GET /api/chirps/search?q= requests, returning the
+// chirps that best match the keywords. q accepts web search syntax: quoted
+// phrases, "or" and a leading "-" to exclude a word. Results are paged with
+// ?limit= (default 20, at most 100) and ?offset=.
+func (cfg *Config) HandlerSearch(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Search query is required", nil)
+		return
+	}
+	if len(query) > searchMaxQueryLength {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Search query is too long", nil)
+		return
+	}
+
+	limit, offset := searchDefaultLimit, 0
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > searchMaxLimit {
+			handlers.RespondWithError(w, http.StatusBadRequest, "limit must be between 1 and 100", err)
+			return
+		}
+		limit = parsed
+	}
+	if raw := r.URL.Query().Get("offset"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			handlers.RespondWithError(w, http.StatusBadRequest, "offset must be a non-negative number", err)
+			return
+		}
+		offset = parsed
+	}
+
+	viewerID, authenticated, err := cfg.optionalViewer(r)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	dbChirps, err := cfg.DB.SearchChirps(r.Context(), database.SearchChirpsParams{
+		TenantID:   tenant.FromContext(r.Context()).ID,
+		Query:      query,
+		PageSize:   int32(limit),
+		PageOffset: int32(offset),
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+		return
+	}
+
+	response, err := cfg.buildChirpList(r.Context(), dbChirps, viewerID, authenticated)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
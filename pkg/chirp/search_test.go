@@ -0,0 +1,37 @@
+package chirp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestHandlerSearch(t *testing.T) {
+	cfg := newBenchConfig(2)
+
+	tests := []struct {
+		name       string
+		query      url.Values
+		wantStatus int
+	}{
+		{name: "match", query: url.Values{"q": {"chirpy"}}, wantStatus: http.StatusOK},
+		{name: "paged", query: url.Values{"q": {`"body text" -spam`}, "limit": {"100"}, "offset": {"20"}}, wantStatus: http.StatusOK},
+		{name: "missing query", query: url.Values{"q": {"  "}}, wantStatus: http.StatusBadRequest},
+		{name: "long query", query: url.Values{"q": {strings.Repeat("a", searchMaxQueryLength+1)}}, wantStatus: http.StatusBadRequest},
+		{name: "zero limit", query: url.Values{"q": {"chirpy"}, "limit": {"0"}}, wantStatus: http.StatusBadRequest},
+		{name: "limit too high", query: url.Values{"q": {"chirpy"}, "limit": {"101"}}, wantStatus: http.StatusBadRequest},
+		{name: "negative offset", query: url.Values{"q": {"chirpy"}, "offset": {"-1"}}, wantStatus: http.StatusBadRequest},
+		{name: "bad offset", query: url.Values{"q": {"chirpy"}, "offset": {"ten"}}, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			cfg.HandlerSearch(rec, httptest.NewRequest(http.MethodGet, "/api/chirps/search?"+tt.query.Encode(), nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
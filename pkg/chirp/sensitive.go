@@ -0,0 +1,118 @@
+package chirp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+// handlerSensitive handles PUT /api/chirps/{id}/sensitive requests, letting
+// the author or a moderator mark a chirp as sensitive or clear the flag
+func (cfg *Config) handlerSensitive(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	var request types.ChirpSensitiveRequest
+	if !handlers.DecodeJSON(w, r, &request) {
+		return
+	}
+	contentWarning, err := validation.NormalizeContentWarning(request.ContentWarning)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	sensitive := request.Sensitive || contentWarning != ""
+
+	dbChirp, archived, err := cfg.getChirp(r.Context(), chirpID)
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirp, err)
+		}
+		return
+	}
+
+	if dbChirp.UserID != userID {
+		isModerator, err := cfg.isModerator(r.Context(), principal)
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve user role", err)
+			return
+		}
+		if !isModerator {
+			handlers.RespondWithError(w, http.StatusForbidden, "Forbidden", nil)
+			return
+		}
+	}
+
+	if archived {
+		handlers.RespondWithError(w, http.StatusConflict, "Archived chirps can't be changed", nil)
+		return
+	}
+
+	if _, err := cfg.DB.SetChirpSensitive(r.Context(), database.SetChirpSensitiveParams{
+		ID:             chirpID,
+		Sensitive:      sensitive,
+		ContentWarning: contentWarning,
+	}); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+
+	cfg.handlerByIDGet(w, r, chirpID)
+}
+
+// sensitivePreference returns how the viewer wants sensitive chirps shown.
+// Anonymous viewers and users who never chose get blurred chirps.
+func (cfg *Config) sensitivePreference(ctx context.Context, viewerID uuid.UUID, authenticated bool) (string, error) {
+	if !authenticated {
+		return types.SensitiveBlur, nil
+	}
+
+	preferences, err := cfg.DB.GetUserPreferences(ctx, viewerID)
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			return types.SensitiveBlur, nil
+		}
+		return "", err
+	}
+	return preferences.SensitiveContent, nil
+}
+
+// withoutSensitive drops the chirps marked as sensitive
+func withoutSensitive(chirps []database.Chirp) []database.Chirp {
+	kept := chirps[:0]
+	for _, chirp := range chirps {
+		if !chirp.Sensitive {
+			kept = append(kept, chirp)
+		}
+	}
+	return kept
+}
+
+// HideSensitive omits the body and media of sensitive chirps for viewers who
+// chose to hide them. The chirps stay in the list, flagged as sensitive and
+// with their content warning, so clients can offer to reveal them. Viewers
+// always see their own chirps.
+func HideSensitive(chirps []types.ChirpCreateResponse, viewerID uuid.UUID, preference string) {
+	if preference != types.SensitiveHide {
+		return
+	}
+	for i := range chirps {
+		if chirps[i].Sensitive && chirps[i].UserID != viewerID {
+			chirps[i].Body = ""
+			chirps[i].Media = []types.MediaAttachment{}
+		}
+	}
+}
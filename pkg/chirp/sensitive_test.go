@@ -0,0 +1,112 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestHideSensitive(t *testing.T) {
+	viewerID := uuid.New()
+	otherID := uuid.New()
+	media := []types.MediaAttachment{{ID: uuid.New(), URL: "https://cdn.example.com/a.png"}}
+
+	newChirps := func() []types.ChirpCreateResponse {
+		return []types.ChirpCreateResponse{
+			{UserID: otherID, Body: "sensitive", Media: media, Sensitive: true},
+			{UserID: otherID, Body: "plain", Media: media},
+			{UserID: viewerID, Body: "own sensitive", Media: media, Sensitive: true},
+		}
+	}
+
+	tests := []struct {
+		preference string
+		wantBodies []string
+	}{
+		{preference: types.SensitiveHide, wantBodies: []string{"", "plain", "own sensitive"}},
+		{preference: types.SensitiveBlur, wantBodies: []string{"sensitive", "plain", "own sensitive"}},
+		{preference: types.SensitiveShow, wantBodies: []string{"sensitive", "plain", "own sensitive"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preference, func(t *testing.T) {
+			chirps := newChirps()
+			HideSensitive(chirps, viewerID, tt.preference)
+			for i, chirp := range chirps {
+				if chirp.Body != tt.wantBodies[i] {
+					t.Errorf("chirps[%d].Body = %q, want %q", i, chirp.Body, tt.wantBodies[i])
+				}
+				if chirp.Body == "" && len(chirp.Media) != 0 {
+					t.Errorf("chirps[%d] hid its body but kept %d media", i, len(chirp.Media))
+				}
+			}
+		})
+	}
+}
+
+func TestWithoutSensitive(t *testing.T) {
+	chirps := []database.Chirp{
+		{Body: "spoilers", Sensitive: true},
+		{Body: "plain"},
+		{Body: "more spoilers", Sensitive: true, ContentWarning: "spoilers"},
+	}
+	kept := withoutSensitive(chirps)
+	if len(kept) != 1 || kept[0].Body != "plain" {
+		t.Errorf("withoutSensitive() = %+v, want only the plain chirp", kept)
+	}
+}
+
+func TestHandlerCreateContentWarning(t *testing.T) {
+	cfg := newBenchConfig(0)
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/chirps", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		cfg.HandlerCreate(rec, req)
+		return rec
+	}
+
+	rec := create(`{"body":"The butler did it","content_warning":"  Spoilers for the finale "}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var response types.ChirpCreateResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if !response.Sensitive || response.ContentWarning != "Spoilers for the finale" {
+		t.Errorf("sensitive = %v, content_warning = %q; want a sensitive chirp with the trimmed warning", response.Sensitive, response.ContentWarning)
+	}
+
+	if rec := create(`{"body":"hi","content_warning":"` + strings.Repeat("a", 101) + `"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("long warning status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandlerGetIncludeSensitive(t *testing.T) {
+	cfg := newBenchConfig(2)
+	for query, want := range map[string]int{
+		"include_sensitive=false": http.StatusOK,
+		"include_sensitive=true":  http.StatusOK,
+		"include_sensitive=maybe": http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		cfg.HandlerGet(rec, httptest.NewRequest(http.MethodGet, "/api/chirps?"+query, nil))
+		if rec.Code != want {
+			t.Errorf("GET /api/chirps?%s status = %d, want %d", query, rec.Code, want)
+		}
+	}
+}
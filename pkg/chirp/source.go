@@ -0,0 +1,67 @@
+package chirp
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+)
+
+// maxSourceLength caps labels taken from User-Agent headers
+const maxSourceLength = 32
+
+// chirpSource returns the label of the app a chirp is posted from. Chirps
+// posted with an OAuth access token are attributed to the registered client;
+// anything else is labelled from its User-Agent header.
+func (cfg *Config) chirpSource(r *http.Request) (string, uuid.NullUUID, error) {
+	clientID := auth.ClientIDFromContext(r.Context())
+	if clientID == "" {
+		return SourceLabel(r.UserAgent()), uuid.NullUUID{}, nil
+	}
+
+	client, err := cfg.DB.GetOAuthClient(r.Context(), database.GetOAuthClientParams{
+		ClientID: clientID,
+		TenantID: tenant.FromContext(r.Context()).ID,
+	})
+	if err != nil {
+		// The client was deleted after issuing the token
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			return SourceLabel(r.UserAgent()), uuid.NullUUID{}, nil
+		}
+		return "", uuid.NullUUID{}, err
+	}
+	return client.Name, uuid.NullUUID{UUID: client.ID, Valid: true}, nil
+}
+
+// SourceLabel derives a source label from a User-Agent header. Browsers are
+// labelled "Web"; apps are labelled with their product name, so
+// "ChirpDeck/2.1 (iOS 18)" becomes "ChirpDeck". Characters other than
+// letters, digits, spaces, dots, hyphens and underscores are dropped and the
+// label is cut to 32 bytes. An empty header gives an empty label.
+func SourceLabel(userAgent string) string {
+	userAgent = strings.TrimSpace(userAgent)
+	if strings.HasPrefix(userAgent, "Mozilla/") {
+		return "Web"
+	}
+
+	product, _, _ := strings.Cut(userAgent, "/")
+	if i := strings.IndexAny(product, "(;"); i >= 0 {
+		product = product[:i]
+	}
+
+	var label strings.Builder
+	for _, r := range product {
+		if label.Len() >= maxSourceLength {
+			break
+		}
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == ' ', r == '.', r == '-', r == '_':
+			label.WriteRune(r)
+		}
+	}
+	return strings.TrimSpace(label.String())
+}
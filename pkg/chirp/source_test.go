@@ -0,0 +1,27 @@
+package chirp
+
+import "testing"
+
+func TestSourceLabel(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      string
+	}{
+		{userAgent: "ChirpDeck/2.1 (iOS 18)", want: "ChirpDeck"},
+		{userAgent: "Mozilla/5.0 (X11; Linux x86_64) Gecko/20100101 Firefox/131.0", want: "Web"},
+		{userAgent: "curl/8.5.0", want: "curl"},
+		{userAgent: "Chirp Bot", want: "Chirp Bot"},
+		{userAgent: "<script>alert(1)</script>", want: "scriptalert"},
+		{userAgent: "AVeryLongClientNameThatKeepsGoingAndGoing/1.0", want: "AVeryLongClientNameThatKeepsGoin"},
+		{userAgent: "", want: ""},
+		{userAgent: "   ", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.userAgent, func(t *testing.T) {
+			if got := SourceLabel(tt.userAgent); got != tt.want {
+				t.Errorf("SourceLabel(%q) = %q, want %q", tt.userAgent, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package chirp
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/ratelimit"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// allowPost counts a new chirp against its author's per-minute limit,
+// refusing it with 429 once the limit is used up. Accounts on probation
+// count against the lower probation limit instead. If the limiter's store
+// or the account lookup fails the chirp is let through, like the API-wide
+// limit.
+func (cfg *Config) allowPost(w http.ResponseWriter, r *http.Request, userID uuid.UUID) bool {
+	limiter, key := cfg.postLimiter(r, userID)
+	if limiter == nil {
+		return true
+	}
+	result, err := limiter.Allow(r.Context(), key+userID.String())
+	if err != nil {
+		log.Printf("Couldn't check chirp rate limit: %s", err)
+		return true
+	}
+	if !result.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(result.Reset).Seconds())+1))
+		handlers.RespondWithErrorCode(w, http.StatusTooManyRequests, types.ErrCodeRateLimited, "Too many chirps, try again later", nil)
+		return false
+	}
+	return true
+}
+
+// postLimiter picks the limiter a chirp by userID counts against and the
+// prefix of its key
+func (cfg *Config) postLimiter(r *http.Request, userID uuid.UUID) (*ratelimit.Limiter, string) {
+	if cfg.ProbationLimiter == nil {
+		return cfg.PostLimiter, "chirps:"
+	}
+	ent, err := cfg.userEntitlements(r.Context(), userID)
+	if err != nil {
+		log.Printf("Couldn't check account standing: %s", err)
+		return cfg.PostLimiter, "chirps:"
+	}
+	if ent.Probation {
+		return cfg.ProbationLimiter, "chirps:probation:"
+	}
+	return cfg.PostLimiter, "chirps:"
+}
@@ -0,0 +1,134 @@
+package chirp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
+	"github.com/kai-xlr/neo_chirpy/internal/entitlements"
+	"github.com/kai-xlr/neo_chirpy/internal/ratelimit"
+)
+
+func TestHandlerCreatePostLimit(t *testing.T) {
+	cfg := newBenchConfig(0)
+	cfg.Store = cache.NewMemory()
+	cfg.PostLimiter = ratelimit.New(cfg.Store, 2, time.Minute)
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/chirps", strings.NewReader(`{"body":"spam"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		cfg.HandlerCreate(rec, req)
+		return rec
+	}
+
+	if rec := create("first"); rec.Code != http.StatusCreated {
+		t.Fatalf("first status = %d, body = %s", rec.Code, rec.Body)
+	}
+	// A replayed retry doesn't use up the limit
+	if rec := create("first"); rec.Code != http.StatusCreated {
+		t.Fatalf("retry status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if rec := create(""); rec.Code != http.StatusCreated {
+		t.Fatalf("second status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	rec := create("")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("third status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+	if err != nil || retryAfter < 1 || retryAfter > 61 {
+		t.Errorf("Retry-After = %q, want 1-61 seconds", rec.Header().Get("Retry-After"))
+	}
+	if !strings.Contains(rec.Body.String(), "RATE_LIMITED") {
+		t.Errorf("body = %s, want code RATE_LIMITED", rec.Body)
+	}
+}
+
+func TestHandlerDraftPublishPostLimit(t *testing.T) {
+	cfg := newBenchConfig(0)
+	cfg.Store = cache.NewMemory()
+	cfg.PostLimiter = ratelimit.New(cfg.Store, 1, time.Minute)
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publish := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/drafts/"+uuid.NewString()+"/publish", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		cfg.HandlerDrafts(rec, req)
+		return rec
+	}
+
+	if rec := publish(); rec.Code != http.StatusCreated {
+		t.Fatalf("first status = %d, body = %s", rec.Code, rec.Body)
+	}
+	rec := publish()
+	if rec.Code != http.StatusTooManyRequests || !strings.Contains(rec.Body.String(), "RATE_LIMITED") {
+		t.Fatalf("second status = %d, body = %s; want 429 RATE_LIMITED", rec.Code, rec.Body)
+	}
+}
+
+func TestProbation(t *testing.T) {
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	create := func(cfg *Config, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/chirps", strings.NewReader(`{"body":"`+body+`"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		cfg.HandlerCreate(rec, req)
+		return rec
+	}
+
+	newConfig := func(probation time.Duration) *Config {
+		cfg := newBenchConfig(0)
+		cfg.Store = cache.NewMemory()
+		cfg.PostLimiter = ratelimit.New(cfg.Store, 5, time.Minute)
+		cfg.ProbationLimiter = ratelimit.New(cfg.Store, 2, time.Minute)
+		cfg.Entitlements = entitlements.Policy{Probation: probation}
+		return cfg
+	}
+
+	t.Run("on probation", func(t *testing.T) {
+		cfg := newConfig(24 * time.Hour)
+		rec := create(cfg, "buy now at https://spam.example")
+		if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "ACCOUNT_ON_PROBATION") {
+			t.Fatalf("link status = %d, body = %s; want 403 ACCOUNT_ON_PROBATION", rec.Code, rec.Body)
+		}
+		// The refused chirp counted against the limit of 2 too
+		if rec := create(cfg, "hello"); rec.Code != http.StatusCreated {
+			t.Fatalf("first status = %d, body = %s", rec.Code, rec.Body)
+		}
+		if rec := create(cfg, "hello again"); rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("second status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+		}
+	})
+
+	t.Run("out of probation", func(t *testing.T) {
+		cfg := newConfig(30 * time.Minute)
+		if rec := create(cfg, "read https://example.com"); rec.Code != http.StatusCreated {
+			t.Fatalf("link status = %d, body = %s", rec.Code, rec.Body)
+		}
+		if rec := create(cfg, "hello"); rec.Code != http.StatusCreated {
+			t.Fatalf("second status = %d, body = %s", rec.Code, rec.Body)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package chirp
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+const (
+	// timelineDefaultLimit is the page size when ?limit= is not given
+	timelineDefaultLimit = 20
+
+	// timelineMaxLimit caps ?limit=
+	timelineMaxLimit = 100
+)
+
+// HandlerUsers handles the /api/users/{id}/... requests: a user's timeline,
+// the chirps mentioning them, and who they follow and are followed by
+func (cfg *Config) HandlerUsers(w http.ResponseWriter, r *http.Request) {
+	_, subresource := handlers.SplitResourcePath(r.URL.Path, "/api/users/")
+	switch subresource {
+	case "chirps":
+		cfg.HandlerUserTimeline(w, r)
+	case "follow":
+		cfg.HandlerFollow(w, r)
+	case "followers", "following":
+		cfg.HandlerFollowList(w, r)
+	default:
+		cfg.HandlerUserMentions(w, r)
+	}
+}
+
+// HandlerUserTimeline handles GET /api/users/{id}/chirps requests, listing
+// the user's chirps newest first. Pages hold ?limit= chirps (default 20, at
+// most 100); ?before_id= continues after the last chirp of the previous
+// page, and a Link header points at the next page while there may be one.
+func (cfg *Config) HandlerUserTimeline(w http.ResponseWriter, r *http.Request) {
+	idString, subresource := handlers.SplitResourcePath(r.URL.Path, "/api/users/")
+	if subresource != "chirps" {
+		handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+		return
+	}
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	userID, err := uuid.Parse(idString)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	limit := timelineDefaultLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > timelineMaxLimit {
+			handlers.RespondWithError(w, http.StatusBadRequest, "limit must be between 1 and 100", err)
+			return
+		}
+		limit = parsed
+	}
+
+	viewerID, authenticated, err := cfg.optionalViewer(r)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	tenantID := tenant.FromContext(r.Context()).ID
+	found, err := cfg.DB.IsActiveUserInTenant(r.Context(), database.IsActiveUserInTenantParams{
+		ID:       userID,
+		TenantID: tenantID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+		return
+	}
+	if !found {
+		handlers.RespondWithError(w, http.StatusNotFound, "User not found", nil)
+		return
+	}
+
+	// Start from the newest chirp, or after the cursor chirp
+	params := database.GetUserTimelineParams{
+		TenantID:        tenantID,
+		UserID:          userID,
+		BeforeCreatedAt: time.Now().UTC().Add(time.Hour),
+		BeforeID:        uuid.Max,
+		PageSize:        int32(limit),
+	}
+	if beforeID := r.URL.Query().Get("before_id"); beforeID != "" {
+		parsedID, err := uuid.Parse(beforeID)
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusBadRequest, "Invalid before_id format", err)
+			return
+		}
+		cursor, err := cfg.DB.GetChirpByID(r.Context(), parsedID)
+		if err != nil {
+			if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+				handlers.RespondWithError(w, http.StatusNotFound, "Chirp not found", nil)
+			} else {
+				handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+			}
+			return
+		}
+		if cursor.TenantID != tenantID || cursor.UserID != userID {
+			handlers.RespondWithError(w, http.StatusBadRequest, "before_id must be one of the user's chirps", nil)
+			return
+		}
+		params.BeforeCreatedAt, params.BeforeID = cursor.CreatedAt, cursor.ID
+	}
+
+	dbChirps, err := cfg.DB.GetUserTimeline(r.Context(), params)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+		return
+	}
+
+	// The cursor comes from the last chirp fetched, since muted chirps may
+	// be dropped from the response
+	if len(dbChirps) == limit {
+		next := url.Values{
+			"before_id": {dbChirps[len(dbChirps)-1].ID.String()},
+			"limit":     {strconv.Itoa(limit)},
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, next.Encode()))
+	}
+
+	response, err := cfg.buildChirpList(r.Context(), dbChirps, viewerID, authenticated)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgRetrieveChirps, err)
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
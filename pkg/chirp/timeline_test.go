@@ -0,0 +1,57 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestHandlerUserTimeline(t *testing.T) {
+	cfg := newBenchConfig(5)
+	path := "/api/users/" + benchUserID.String() + "/chirps"
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantChirps int
+		wantNext   bool
+	}{
+		{name: "first page", method: http.MethodGet, path: path, wantStatus: http.StatusOK, wantChirps: 5},
+		{name: "full page", method: http.MethodGet, path: path + "?limit=5", wantStatus: http.StatusOK, wantChirps: 5, wantNext: true},
+		{name: "after a chirp", method: http.MethodGet, path: path + "?before_id=" + uuid.NewString(), wantStatus: http.StatusOK, wantChirps: 5},
+		{name: "invalid cursor", method: http.MethodGet, path: path + "?before_id=latest", wantStatus: http.StatusBadRequest},
+		{name: "invalid limit", method: http.MethodGet, path: path + "?limit=500", wantStatus: http.StatusBadRequest},
+		{name: "unknown user", method: http.MethodGet, path: "/api/users/" + uuid.NewString() + "/chirps", wantStatus: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodPost, path: path, wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			cfg.HandlerUsers(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			var chirps []json.RawMessage
+			if err := json.Unmarshal(rec.Body.Bytes(), &chirps); err != nil {
+				t.Fatal(err)
+			}
+			if len(chirps) != tt.wantChirps {
+				t.Errorf("got %d chirps, want %d", len(chirps), tt.wantChirps)
+			}
+			link := rec.Header().Get("Link")
+			if hasNext := strings.Contains(link, `rel="next"`); hasNext != tt.wantNext {
+				t.Errorf("Link = %q, want next page %v", link, tt.wantNext)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package chirp
+
+import (
+	"net/http"
+
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+// HandlerValidate handles POST /api/chirps/validate requests. It checks a
+// chirp the way POST /api/chirps would and reports every rule it breaks,
+// along with its length, mentions and hashtags as the server counts them
+// and the body as it would be stored, so client-side counters match the
+// server. Nothing is stored.
+func (cfg *Config) HandlerValidate(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	// Extract and validate JWT token
+	if _, err := auth.Authenticate(r, cfg.JWTSecret); err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+
+	var request types.ChirpCreateRequest
+	if !handlers.DecodeJSON(w, r, &request) {
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, cfg.validateChirp(request))
+}
+
+// validateChirp applies the checks of createChirp that depend only on the
+// request, collecting every failure instead of stopping at the first
+func (cfg *Config) validateChirp(request types.ChirpCreateRequest) types.ChirpValidation {
+	cleanedBody := cfg.Profanity.Clean(request.Body)
+	counting := cfg.Counting
+	if counting == "" {
+		counting = validation.CountRunes
+	}
+	result := types.ChirpValidation{
+		Body:      cleanedBody,
+		Length:    cfg.Counting.Length(request.Body),
+		MaxLength: validation.MaxChirpLength,
+		Counting:  string(counting),
+		Mentions:  emptyIfNil(validation.Mentions(request.Body)),
+		Hashtags:  emptyIfNil(validation.Hashtags(request.Body)),
+		Language:  cfg.detectLanguage(cleanedBody),
+		Errors:    []types.ChirpValidationError{},
+	}
+
+	if err := validation.ValidateChirpBody(request.Body, cfg.Counting); err != nil {
+		code := ""
+		if err == validation.ErrChirpTooLong {
+			code = types.ErrCodeChirpTooLong
+		}
+		result.Errors = append(result.Errors, types.ChirpValidationError{Error: err.Error(), Code: code})
+	}
+	if len(result.Mentions) > validation.MaxChirpMentions {
+		result.Errors = append(result.Errors, types.ChirpValidationError{
+			Error: validation.ErrTooManyMentions.Error(),
+			Code:  types.ErrCodeTooManyMentions,
+		})
+	}
+	if len(result.Hashtags) > validation.MaxChirpHashtags {
+		result.Errors = append(result.Errors, types.ChirpValidationError{
+			Error: validation.ErrTooManyHashtags.Error(),
+			Code:  types.ErrCodeTooManyHashtags,
+		})
+	}
+	if _, err := validation.NormalizeContentWarning(request.ContentWarning); err != nil {
+		result.Errors = append(result.Errors, types.ChirpValidationError{Error: err.Error()})
+	}
+	if err := validateMedia(request.Media, cfg.RequireAltText); err != nil {
+		result.Errors = append(result.Errors, types.ChirpValidationError{Error: err.Error()})
+	}
+
+	result.Valid = len(result.Errors) == 0
+	return result
+}
+
+// emptyIfNil returns items, or an empty slice instead of nil so it encodes
+// as []
+func emptyIfNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
@@ -0,0 +1,66 @@
+package chirp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+func TestHandlerValidate(t *testing.T) {
+	cfg := newBenchConfig(0)
+	cfg.Counting = validation.CountGraphemes
+	token, err := auth.MakeJWT(benchUserID, benchSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validate := func(body string) types.ChirpValidation {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/chirps/validate", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		cfg.HandlerValidate(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+		}
+		var result types.ChirpValidation
+		if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	// A flag is one grapheme but two runes
+	result := validate(`{"body":"Hi @kai 🇳🇱 #go"}`)
+	if !result.Valid || result.Length != 13 || result.Counting != "graphemes" || len(result.Errors) != 0 {
+		t.Errorf("valid chirp = %+v", result)
+	}
+	if strings.Join(result.Mentions, ",") != "kai" || strings.Join(result.Hashtags, ",") != "go" {
+		t.Errorf("mentions = %v, hashtags = %v", result.Mentions, result.Hashtags)
+	}
+
+	// Every broken rule is reported, not only the first
+	body := "@a @b @c @d @e @f @g @h @i @j @k " + strings.Repeat("x", validation.MaxChirpLength)
+	result = validate(`{"body":"` + body + `","content_warning":"` + strings.Repeat("w", validation.MaxContentWarning+1) + `"}`)
+	var codes []string
+	for _, e := range result.Errors {
+		codes = append(codes, e.Code)
+	}
+	want := []string{types.ErrCodeChirpTooLong, types.ErrCodeTooManyMentions, ""}
+	if result.Valid || strings.Join(codes, ",") != strings.Join(want, ",") {
+		t.Errorf("invalid chirp errors = %+v", result.Errors)
+	}
+
+	rec := httptest.NewRecorder()
+	cfg.HandlerValidate(rec, httptest.NewRequest(http.MethodPost, "/api/chirps/validate", strings.NewReader(`{"body":"hi"}`)))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
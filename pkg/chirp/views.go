@@ -0,0 +1,189 @@
+package chirp
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/dataloader"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// ViewBuffer counts chirp impressions in memory until they are flushed, so
+// serving chirps doesn't cost a write per read. A nil buffer counts nothing.
+type ViewBuffer struct {
+	mu     sync.Mutex
+	counts map[uuid.UUID]int64
+}
+
+// Record counts one impression of each chirp
+func (b *ViewBuffer) Record(chirps []types.ChirpCreateResponse) {
+	if b == nil || len(chirps) == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.counts == nil {
+		b.counts = make(map[uuid.UUID]int64)
+	}
+	for i := range chirps {
+		b.counts[chirps[i].ID]++
+	}
+}
+
+// take empties the buffer and returns what it held
+func (b *ViewBuffer) take() map[uuid.UUID]int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	counts := b.counts
+	b.counts = nil
+	return counts
+}
+
+// putBack returns counts that couldn't be written, to be retried with the
+// next flush
+func (b *ViewBuffer) putBack(counts map[uuid.UUID]int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.counts == nil {
+		b.counts = make(map[uuid.UUID]int64, len(counts))
+	}
+	for chirpID, count := range counts {
+		b.counts[chirpID] += count
+	}
+}
+
+// FlushViews adds the buffered impressions to the chirps' view counts in a
+// single statement. It runs periodically and once more on shutdown.
+func (cfg *Config) FlushViews(ctx context.Context) error {
+	if cfg.Views == nil {
+		return nil
+	}
+	counts := cfg.Views.take()
+	if len(counts) == 0 {
+		return nil
+	}
+
+	params := database.AddChirpViewsParams{
+		ChirpIds:   make([]uuid.UUID, 0, len(counts)),
+		ViewCounts: make([]int64, 0, len(counts)),
+	}
+	for chirpID, count := range counts {
+		params.ChirpIds = append(params.ChirpIds, chirpID)
+		params.ViewCounts = append(params.ViewCounts, count)
+	}
+	if err := cfg.DB.AddChirpViews(ctx, params); err != nil {
+		cfg.Views.putBack(counts)
+		return err
+	}
+	return nil
+}
+
+// attachViews adds view counts to the given chirp responses through the
+// request's view loader
+func (cfg *Config) attachViews(ctx context.Context, chirps []types.ChirpCreateResponse) error {
+	if len(chirps) == 0 {
+		return nil
+	}
+
+	chirpIDs := make([]uuid.UUID, len(chirps))
+	for i := range chirps {
+		chirpIDs[i] = chirps[i].ID
+	}
+	counts, err := cfg.viewLoader(ctx).LoadMany(ctx, chirpIDs)
+	if err != nil {
+		return err
+	}
+	for i := range chirps {
+		chirps[i].ViewCount = counts[chirps[i].ID]
+	}
+	return nil
+}
+
+// viewLoader returns the request's loader for view counts by chirp ID
+func (cfg *Config) viewLoader(ctx context.Context) *dataloader.Loader[uuid.UUID, int64] {
+	return dataloader.For(ctx, "chirp.views", func(ctx context.Context, chirpIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+		rows, err := cfg.DB.GetViewCounts(ctx, chirpIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		counts := make(map[uuid.UUID]int64, len(rows))
+		for _, row := range rows {
+			counts[row.ChirpID] = row.ViewCount
+		}
+		return counts, nil
+	})
+}
+
+// attachArchivedViews adds the view count to a single archived chirp
+// response. Archived chirps keep the count they had when archived.
+func (cfg *Config) attachArchivedViews(ctx context.Context, chirp *types.ChirpCreateResponse) error {
+	count, err := cfg.DB.GetArchivedViewCount(ctx, chirp.ID)
+	if err != nil {
+		return err
+	}
+	chirp.ViewCount = count
+	return nil
+}
+
+// handlerStats handles GET /api/chirps/{id}/stats requests, which show the
+// chirp's author how many times it was viewed, liked, replied to, reposted
+// and reacted to
+func (cfg *Config) handlerStats(w http.ResponseWriter, r *http.Request, chirpID uuid.UUID) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	dbChirp, archived, ok := cfg.getVisibleChirp(w, r, chirpID)
+	if !ok {
+		return
+	}
+	if dbChirp.UserID != userID {
+		handlers.RespondWithError(w, http.StatusForbidden, "Forbidden", nil)
+		return
+	}
+
+	response := []types.ChirpCreateResponse{handlers.BuildChirpResponse(dbChirp)}
+	if archived {
+		err = cfg.attachArchivedReactions(r.Context(), &response[0])
+		if err == nil {
+			err = cfg.attachArchivedLikes(r.Context(), &response[0], userID)
+		}
+		if err == nil {
+			err = cfg.attachArchivedViews(r.Context(), &response[0])
+		}
+	} else {
+		err = cfg.attachReactions(r.Context(), response)
+		if err == nil {
+			err = cfg.attachLikes(r.Context(), response, userID)
+		}
+		if err == nil {
+			err = cfg.attachViews(r.Context(), response)
+		}
+	}
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirp stats", err)
+		return
+	}
+
+	stats := types.ChirpStats{
+		ChirpID:     chirpID,
+		ViewCount:   response[0].ViewCount,
+		LikeCount:   response[0].LikeCount,
+		ReplyCount:  response[0].ReplyCount,
+		RepostCount: response[0].RepostCount,
+	}
+	for _, reaction := range response[0].Reactions {
+		stats.ReactionCount += reaction.Count
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, stats)
+}
@@ -0,0 +1,114 @@
+package chirp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestViewsAreBufferedUntilFlushed(t *testing.T) {
+	cfg := newBenchConfig(0)
+	cfg.Views = &ViewBuffer{}
+	chirpID := uuid.New()
+
+	get := func() types.ChirpCreateResponse {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		cfg.HandlerByID(rec, httptest.NewRequest(http.MethodGet, "/api/chirps/"+chirpID.String(), nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		var chirp types.ChirpCreateResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &chirp); err != nil {
+			t.Fatal(err)
+		}
+		return chirp
+	}
+
+	for range 3 {
+		if chirp := get(); chirp.ViewCount != 0 {
+			t.Fatalf("view_count = %d before a flush, want 0", chirp.ViewCount)
+		}
+	}
+	if err := cfg.FlushViews(context.Background()); err != nil {
+		t.Fatalf("FlushViews() error = %v", err)
+	}
+	if chirp := get(); chirp.ViewCount != 3 {
+		t.Errorf("view_count = %d after a flush, want 3", chirp.ViewCount)
+	}
+
+	// The view above is written by the next flush; flushing an empty
+	// buffer writes nothing
+	if err := cfg.FlushViews(context.Background()); err != nil {
+		t.Fatalf("FlushViews() error = %v", err)
+	}
+	if err := cfg.FlushViews(context.Background()); err != nil {
+		t.Fatalf("FlushViews() error = %v", err)
+	}
+	if chirp := get(); chirp.ViewCount != 4 {
+		t.Errorf("view_count = %d, want 4", chirp.ViewCount)
+	}
+}
+
+func TestListedChirpsCountAsViews(t *testing.T) {
+	cfg := newBenchConfig(5)
+	cfg.Views = &ViewBuffer{}
+
+	rec := httptest.NewRecorder()
+	cfg.HandlerGet(rec, httptest.NewRequest(http.MethodGet, "/api/chirps", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if counts := cfg.Views.take(); len(counts) != 5 {
+		t.Errorf("buffered views for %d chirps, want 5", len(counts))
+	}
+}
+
+func TestHandlerStats(t *testing.T) {
+	cfg := newBenchConfig(0)
+	chirpID := uuid.NewString()
+
+	tests := []struct {
+		name   string
+		userID uuid.UUID
+		method string
+		want   int
+	}{
+		{name: "author", userID: benchUserID, method: http.MethodGet, want: http.StatusOK},
+		{name: "someone else", userID: uuid.New(), method: http.MethodGet, want: http.StatusForbidden},
+		{name: "wrong method", userID: benchUserID, method: http.MethodPost, want: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := auth.MakeJWT(tt.userID, benchSecret, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(tt.method, "/api/chirps/"+chirpID+"/stats", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			cfg.HandlerByID(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.want, rec.Body)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			var stats types.ChirpStats
+			if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+				t.Fatal(err)
+			}
+			if stats.ChirpID.String() != chirpID {
+				t.Errorf("chirp_id = %s, want %s", stats.ChirpID, chirpID)
+			}
+		})
+	}
+}
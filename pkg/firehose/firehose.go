@@ -0,0 +1,259 @@
+// Package firehose streams every public chirp of a community as it is
+// published, as newline-delimited JSON, to holders of a registered API key.
+package firehose
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/ratelimit"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// Tier sets what an API key may do
+type Tier struct {
+	// Connections is how many streams the key may open per hour
+	Connections int
+	// Backfill is the most past chirps a stream replays for ?since=
+	Backfill int32
+}
+
+// Tiers maps the tier names keys are registered with to their limits
+var Tiers = map[string]Tier{
+	types.APIKeyTierStandard: {Connections: 10, Backfill: 100},
+	types.APIKeyTierResearch: {Connections: 60, Backfill: 5000},
+}
+
+const (
+	// heartbeatInterval is how often an idle stream writes an empty line so
+	// proxies don't close it
+	heartbeatInterval = 30 * time.Second
+
+	// liveBuffer is how many published chirps a stream may fall behind
+	// before it is closed
+	liveBuffer = 256
+)
+
+// Config holds configuration needed for the firehose handler
+type Config struct {
+	DB     *database.Queries
+	Events *events.Bus
+
+	// Store holds the per-key connection counts; nil disables the limits
+	Store cache.Store
+
+	// Done closes open streams, so a graceful shutdown doesn't wait for them
+	Done <-chan struct{}
+}
+
+// HandlerFirehose handles GET /api/firehose requests. Chirps are written as
+// they are published, one JSON object per line; ?since= first replays chirps
+// published after that time, up to the key's tier limit. A stream that falls
+// too far behind is closed, and the client should reconnect with ?since= set
+// to the last chirp it received.
+func (cfg *Config) HandlerFirehose(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	apiKey, err := auth.GetAPIKey(r.Header)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid API key", err)
+		return
+	}
+	key, err := cfg.DB.UseAPIKey(r.Context(), auth.HashAPIKey(apiKey))
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid API key", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't check API key", err)
+		}
+		return
+	}
+	tenantID := tenant.FromContext(r.Context()).ID
+	if key.TenantID != tenantID {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid API key", nil)
+		return
+	}
+	tier, ok := Tiers[key.Tier]
+	if !ok {
+		handlers.RespondWithError(w, http.StatusForbidden, "API key tier is not available", nil)
+		return
+	}
+	if !cfg.allowConnection(w, r, key.ID, tier) {
+		return
+	}
+
+	var backfill []database.Chirp
+	if since := r.URL.Query().Get("since"); since != "" {
+		sinceTime, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusBadRequest, "since must be an RFC 3339 timestamp", err)
+			return
+		}
+		backfill, err = cfg.DB.GetChirpsPublishedSince(r.Context(), database.GetChirpsPublishedSinceParams{
+			TenantID:    tenantID,
+			PublishedAt: sinceTime.UTC(),
+			AfterID:     uuid.Max,
+			MaxChirps:   tier.Backfill,
+		})
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve chirps", err)
+			return
+		}
+	}
+
+	// Subscribe before replaying so nothing published meanwhile is missed
+	live, behind, unsubscribe := subscribe(cfg.Events)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	controller := http.NewResponseController(w)
+	s := &stream{
+		w:        w,
+		flush:    controller.Flush,
+		fetch:    cfg.DB.GetChirpByID,
+		tenantID: tenantID,
+		sent:     make(map[uuid.UUID]bool, len(backfill)),
+	}
+	defer func() {
+		if s.delivered == 0 {
+			return
+		}
+		cfg.DB.AddAPIKeyChirpsDelivered(context.Background(), database.AddAPIKeyChirpsDeliveredParams{
+			Chirps: s.delivered,
+			ID:     key.ID,
+		})
+	}()
+
+	if err := s.replay(backfill); err != nil {
+		return
+	}
+	s.run(r.Context(), cfg.Done, live, behind, heartbeatInterval)
+}
+
+// allowConnection counts a new stream against the key's hourly limit,
+// refusing it with 429 once the limit is used up
+func (cfg *Config) allowConnection(w http.ResponseWriter, r *http.Request, keyID uuid.UUID, tier Tier) bool {
+	if cfg.Store == nil {
+		return true
+	}
+	result, err := ratelimit.New(cfg.Store, tier.Connections, time.Hour).Allow(r.Context(), "firehose:"+keyID.String())
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't check rate limit", err)
+		return false
+	}
+	if !result.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(result.Reset).Seconds())+1))
+		handlers.RespondWithErrorCode(w, http.StatusTooManyRequests, types.ErrCodeRateLimited, "Too many firehose connections for this API key", nil)
+		return false
+	}
+	return true
+}
+
+// subscribe delivers the IDs of published chirps on live. behind is closed
+// once the buffer overflows.
+func subscribe(bus *events.Bus) (live <-chan uuid.UUID, behind <-chan struct{}, unsubscribe func()) {
+	ids := make(chan uuid.UUID, liveBuffer)
+	overflow := make(chan struct{})
+	var once sync.Once
+	if bus == nil {
+		return ids, overflow, func() {}
+	}
+	unsubscribe = bus.Subscribe(func(_ context.Context, event events.Event) {
+		select {
+		case ids <- event.ChirpID:
+		default:
+			once.Do(func() { close(overflow) })
+		}
+	}, events.ChirpCreated)
+	return ids, overflow, unsubscribe
+}
+
+// stream writes chirps to one firehose client
+type stream struct {
+	w        io.Writer
+	flush    func() error
+	fetch    func(ctx context.Context, id uuid.UUID) (database.Chirp, error)
+	tenantID uuid.UUID
+
+	// sent holds replayed chirps, which may also arrive live
+	sent      map[uuid.UUID]bool
+	delivered int64
+}
+
+// replay writes chirps published before the stream started
+func (s *stream) replay(chirps []database.Chirp) error {
+	for _, chirp := range chirps {
+		if err := s.write(chirp); err != nil {
+			return err
+		}
+		s.sent[chirp.ID] = true
+	}
+	return s.flush()
+}
+
+// run writes chirps as they are published until the client disconnects,
+// done is closed or the stream falls behind
+func (s *stream) run(ctx context.Context, done <-chan struct{}, live <-chan uuid.UUID, behind <-chan struct{}, heartbeat time.Duration) {
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		case <-behind:
+			return
+		case <-ticker.C:
+			if _, err := io.WriteString(s.w, "\n"); err != nil {
+				return
+			}
+		case id := <-live:
+			if s.sent[id] {
+				continue
+			}
+			chirp, err := s.fetch(ctx, id)
+			if err != nil || chirp.TenantID != s.tenantID {
+				continue
+			}
+			if err := s.write(chirp); err != nil {
+				return
+			}
+		}
+		if err := s.flush(); err != nil {
+			return
+		}
+	}
+}
+
+// write encodes one chirp as a line of JSON
+func (s *stream) write(chirp database.Chirp) error {
+	line, err := json.Marshal(handlers.BuildChirpResponse(chirp))
+	if err != nil {
+		return err
+	}
+	if _, err := s.w.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	s.delivered++
+	return nil
+}
@@ -0,0 +1,95 @@
+package firehose
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+)
+
+func TestStreamReplaysThenFollowsLiveChirps(t *testing.T) {
+	tenantID, otherTenant := uuid.New(), uuid.New()
+	replayed := database.Chirp{ID: uuid.New(), TenantID: tenantID, Body: "replayed"}
+	chirps := map[uuid.UUID]database.Chirp{
+		replayed.ID: replayed,
+	}
+	newChirp := func(body string, tenant uuid.UUID) uuid.UUID {
+		chirp := database.Chirp{ID: uuid.New(), TenantID: tenant, Body: body}
+		chirps[chirp.ID] = chirp
+		return chirp.ID
+	}
+	live := []uuid.UUID{
+		replayed.ID,
+		newChirp("other community", otherTenant),
+		uuid.New(), // deleted before it could be fetched
+		newChirp("live", tenantID),
+	}
+
+	var out bytes.Buffer
+	s := &stream{
+		w:     &out,
+		flush: func() error { return nil },
+		fetch: func(_ context.Context, id uuid.UUID) (database.Chirp, error) {
+			chirp, ok := chirps[id]
+			if !ok {
+				return database.Chirp{}, errors.New("sql: no rows in result set")
+			}
+			return chirp, nil
+		},
+		tenantID: tenantID,
+		sent:     map[uuid.UUID]bool{},
+	}
+
+	if err := s.replay([]database.Chirp{replayed}); err != nil {
+		t.Fatalf("replay() error = %v", err)
+	}
+	ids := make(chan uuid.UUID, len(live))
+	for _, id := range live {
+		ids <- id
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s.run(ctx, nil, ids, nil, time.Hour)
+
+	var bodies []string
+	for _, line := range strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n") {
+		var chirp struct{ Body string }
+		if err := json.Unmarshal([]byte(line), &chirp); err != nil {
+			t.Fatalf("line %q is not JSON: %v", line, err)
+		}
+		bodies = append(bodies, chirp.Body)
+	}
+	if strings.Join(bodies, ",") != "replayed,live" {
+		t.Errorf("streamed %v, want [replayed live]", bodies)
+	}
+	if s.delivered != 2 {
+		t.Errorf("delivered = %d, want 2", s.delivered)
+	}
+}
+
+func TestSubscribeReportsSlowConsumers(t *testing.T) {
+	bus := events.NewBus()
+	live, behind, unsubscribe := subscribe(bus)
+	defer unsubscribe()
+
+	for range liveBuffer + 1 {
+		bus.Publish(events.Event{Type: events.ChirpCreated, ChirpID: uuid.New()})
+	}
+	bus.Wait()
+
+	select {
+	case <-behind:
+	default:
+		t.Error("behind not closed after the buffer overflowed")
+	}
+	if len(live) != liveBuffer {
+		t.Errorf("buffered %d chirps, want %d", len(live), liveBuffer)
+	}
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// BatchSuccess records an item of a batch request that succeeded with status
+func BatchSuccess(id string, status int) types.BatchResult {
+	return types.BatchResult{ID: id, Status: status}
+}
+
+// BatchError records an item of a batch request that failed, logging err
+// the way RespondWithErrorCode does for a single request
+func BatchError(id string, status int, errCode, msg string, err error) types.BatchResult {
+	if err != nil {
+		log.Println(err)
+	}
+	if status > 499 {
+		log.Printf("Batch item failed with 5XX error: %s", msg)
+	}
+	return types.BatchResult{ID: id, Status: status, Code: errCode, Error: msg}
+}
+
+// RespondWithBatch sends the outcome of every item of a batch request. The
+// response is 200 when every item succeeded and 207 Multi-Status when any
+// failed, so clients only need to look at the items after a 207.
+func RespondWithBatch(w http.ResponseWriter, results []types.BatchResult) {
+	code := http.StatusOK
+	for _, result := range results {
+		if result.Status < 200 || result.Status > 299 {
+			code = http.StatusMultiStatus
+			break
+		}
+	}
+	if results == nil {
+		results = []types.BatchResult{}
+	}
+	RespondWithJSON(w, code, types.BatchResponse{Results: results})
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+// DecodeJSON decodes a request body into dst and validates it: first the
+// validate tags on its fields, then its Validate method if it is a
+// types.Validator. On failure it responds and returns false. A body that
+// isn't valid JSON gets the same 500 the handlers have always returned, and
+// a failed check a 400 with the VALIDATION_FAILED code and, for a
+// validate tag, the field that failed.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		RespondWithError(w, http.StatusInternalServerError, types.ErrMsgDecodeParams, err)
+		return false
+	}
+	return validateRequest(w, dst)
+}
+
+// UnmarshalJSON is DecodeJSON for handlers that read the body themselves,
+// such as to hash or journal it
+func UnmarshalJSON(w http.ResponseWriter, body []byte, dst any) bool {
+	if err := json.Unmarshal(body, dst); err != nil {
+		RespondWithError(w, http.StatusInternalServerError, types.ErrMsgDecodeParams, err)
+		return false
+	}
+	return validateRequest(w, dst)
+}
+
+// validateRequest runs the checks of DecodeJSON on a decoded request
+func validateRequest(w http.ResponseWriter, dst any) bool {
+	err := validation.Struct(dst)
+	if validator, ok := dst.(types.Validator); ok && err == nil {
+		err = validator.Validate()
+	}
+	if err != nil {
+		response := errorResponse{Error: err.Error(), Code: types.ErrCodeValidation}
+		var fieldErr *validation.FieldError
+		if errors.As(err, &fieldErr) {
+			response.Field = fieldErr.Field
+		}
+		RespondWithJSON(w, http.StatusBadRequest, response)
+		return false
+	}
+	return true
+}
@@ -1,11 +1,16 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
 	"log"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/domains"
 	"github.com/kai-xlr/neo_chirpy/pkg/types"
 )
 
@@ -18,12 +23,32 @@ func RequireMethod(w http.ResponseWriter, r *http.Request, method string) bool {
 	return true
 }
 
+// RequestOrigin returns the scheme and host a request was made to, for
+// URLs in the response that point back at the server. The Host header is
+// whatever the client sent, so links that outlive the request, such as
+// those in emails, must use PUBLIC_URL instead.
+func RequestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
 type errorResponse struct {
 	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
+	Field string `json:"field,omitempty"`
 }
 
 // RespondWithError sends an error response in JSON format
 func RespondWithError(w http.ResponseWriter, code int, msg string, err error) {
+	RespondWithErrorCode(w, code, "", msg, err)
+}
+
+// RespondWithErrorCode sends an error response with a machine-readable error
+// code so clients can react to specific failures without parsing messages
+func RespondWithErrorCode(w http.ResponseWriter, code int, errCode, msg string, err error) {
 	// Log the actual error for debugging purposes
 	if err != nil {
 		log.Println(err)
@@ -35,31 +60,82 @@ func RespondWithError(w http.ResponseWriter, code int, msg string, err error) {
 	// Send error response in JSON format
 	RespondWithJSON(w, code, errorResponse{
 		Error: msg,
+		Code:  errCode,
 	})
 }
 
-// RespondWithJSON sends a JSON response
+// maxPooledBufferSize keeps unusually large responses from pinning memory
+// in the buffer pool
+const maxPooledBufferSize = 64 << 10
+
+var jsonBufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// RespondWithJSON sends a JSON response. Payloads are encoded into pooled
+// buffers to avoid allocating a fresh byte slice per response.
 func RespondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	buf := jsonBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			jsonBufferPool.Put(buf)
+		}
+	}()
+
 	w.Header().Set("Content-Type", types.ContentTypeJSON)
-	dat, err := json.Marshal(payload)
-	if err != nil {
+	if err := json.NewEncoder(buf).Encode(payload); err != nil {
 		log.Printf("Error marshalling JSON: %s", err)
 		w.WriteHeader(500)
 		return
 	}
+	// Encode appends a newline that json.Marshal did not
+	buf.Truncate(buf.Len() - 1)
+
 	w.WriteHeader(code)
-	w.Write(dat)
+	w.Write(buf.Bytes())
 }
 
 // BuildChirpResponse converts a database chirp to API response format
 func BuildChirpResponse(dbChirp database.Chirp) types.ChirpCreateResponse {
-	return types.ChirpCreateResponse{
-		ID:        dbChirp.ID,
-		CreatedAt: dbChirp.CreatedAt,
-		UpdatedAt: dbChirp.UpdatedAt,
-		Body:      dbChirp.Body,
-		UserID:    dbChirp.UserID,
+	response := types.ChirpCreateResponse{
+		ID:             dbChirp.ID,
+		CreatedAt:      types.NewTimestamp(dbChirp.CreatedAt),
+		UpdatedAt:      types.NewTimestamp(dbChirp.UpdatedAt),
+		Body:           dbChirp.Body,
+		UserID:         dbChirp.UserID,
+		Media:          []types.MediaAttachment{},
+		Sensitive:      dbChirp.Sensitive,
+		ContentWarning: dbChirp.ContentWarning,
+		Locked:         dbChirp.Locked,
+		Source:         dbChirp.Source,
+		Language:       dbChirp.Language,
+		PublishedAt:    types.NewTimestamp(dbChirp.PublishedAt),
+		Pending:        dbChirp.PublishedAt.After(time.Now()),
+		LikeCount:      dbChirp.LikeCount,
+		ReplyCount:     dbChirp.ReplyCount,
+		RepostCount:    dbChirp.RepostCount,
+	}
+	if dbChirp.ParentChirpID.Valid {
+		response.ParentChirpID = &dbChirp.ParentChirpID.UUID
+	}
+	if dbChirp.RepostOfChirpID.Valid {
+		response.RepostOfChirpID = &dbChirp.RepostOfChirpID.UUID
 	}
+	return response
+}
+
+// BuildMediaResponse converts database media attachments to API response format
+func BuildMediaResponse(dbMedia []database.ChirpMedium) []types.MediaAttachment {
+	response := make([]types.MediaAttachment, len(dbMedia))
+	for mediaIdx, media := range dbMedia {
+		response[mediaIdx] = types.MediaAttachment{
+			ID:      media.ID,
+			URL:     media.Url,
+			AltText: media.AltText,
+		}
+	}
+	return response
 }
 
 // BuildChirpListResponse converts a slice of database chirps to API response format
@@ -71,11 +147,65 @@ func BuildChirpListResponse(dbChirps []database.Chirp) []types.ChirpCreateRespon
 	return response
 }
 
+// BuildCustomDomainResponse converts a database custom domain to API
+// response format, including the TXT record that verifies it
+func BuildCustomDomainResponse(dbDomain database.CustomDomain) types.CustomDomain {
+	response := types.CustomDomain{
+		Domain:      dbDomain.Domain,
+		CreatedAt:   types.NewTimestamp(dbDomain.CreatedAt),
+		TenantID:    dbDomain.TenantID,
+		RecordName:  domains.RecordName(dbDomain.Domain),
+		RecordValue: domains.RecordValue(dbDomain.VerificationToken),
+	}
+	if dbDomain.UserID.Valid {
+		response.UserID = &dbDomain.UserID.UUID
+	}
+	if dbDomain.VerifiedAt.Valid {
+		verifiedAt := types.NewTimestamp(dbDomain.VerifiedAt.Time)
+		response.VerifiedAt = &verifiedAt
+	}
+	if dbDomain.CheckedAt.Valid {
+		checkedAt := types.NewTimestamp(dbDomain.CheckedAt.Time)
+		response.CheckedAt = &checkedAt
+	}
+	return response
+}
+
+// BuildAppealResponse converts a database appeal to API response format,
+// without its chirp's body
+func BuildAppealResponse(dbAppeal database.Appeal) types.Appeal {
+	response := types.Appeal{
+		ID:        dbAppeal.ID,
+		CreatedAt: types.NewTimestamp(dbAppeal.CreatedAt),
+		ChirpID:   dbAppeal.ChirpID,
+		UserID:    dbAppeal.UserID,
+		Reason:    dbAppeal.Reason,
+		Decision:  dbAppeal.Decision.String,
+	}
+	if dbAppeal.DecidedAt.Valid {
+		decidedAt := types.NewTimestamp(dbAppeal.DecidedAt.Time)
+		response.DecidedAt = &decidedAt
+	}
+	if dbAppeal.DecidedBy.Valid {
+		response.DecidedBy = &dbAppeal.DecidedBy.UUID
+	}
+	return response
+}
+
 // PathMatch checks if the path starts with the given prefix
 func PathMatch(path, prefix string) bool {
 	return len(path) > len(prefix) && path[:len(prefix)] == prefix
 }
 
+// SplitResourcePath splits a path like "/api/chirps/{id}/history" into the ID
+// and the remaining sub-resource ("history"). The sub-resource is empty for
+// paths that address the resource itself.
+func SplitResourcePath(path, prefix string) (string, string) {
+	rest := ExtractIDFromPath(path, prefix)
+	id, subresource, _ := strings.Cut(rest, "/")
+	return id, subresource
+}
+
 // ExtractIDFromPath extracts the ID part from a path like "/api/chirps/{id}"
 func ExtractIDFromPath(path, prefix string) string {
 	if len(path) <= len(prefix) {
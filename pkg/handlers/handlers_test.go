@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestRespondWithJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	RespondWithJSON(rec, http.StatusCreated, map[string]string{"body": "<b>hi</b>"})
+
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	// Output must match json.Marshal: HTML escaped and no trailing newline
+	want := `{"body":"\u003cb\u003ehi\u003c/b\u003e"}`
+	if got := rec.Body.String(); got != want {
+		t.Errorf("body = %q, want %q", got, want)
+	}
+}
+
+func TestRequestOrigin(t *testing.T) {
+	tests := []struct {
+		name  string
+		url   string
+		proto string
+		want  string
+	}{
+		{name: "plain", url: "http://chirpy.example.com/api/users", want: "http://chirpy.example.com"},
+		{name: "behind TLS proxy", url: "http://chirpy.example.com/api/users", proto: "https", want: "https://chirpy.example.com"},
+		{name: "TLS", url: "https://chirpy.example.com:8443/api/users", want: "https://chirpy.example.com:8443"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.proto != "" {
+				req.Header.Set("X-Forwarded-Proto", tt.proto)
+			}
+			if got := RequestOrigin(req); got != tt.want {
+				t.Errorf("RequestOrigin() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRespondWithBatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []types.BatchResult
+		want    int
+		body    string
+	}{
+		{"empty", nil, http.StatusOK, `{"results":[]}`},
+		{"all succeeded", []types.BatchResult{BatchSuccess("a", http.StatusNoContent)}, http.StatusOK, `{"results":[{"id":"a","status":204}]}`},
+		{
+			"partial failure",
+			[]types.BatchResult{
+				BatchSuccess("a", http.StatusNoContent),
+				BatchError("b", http.StatusTooManyRequests, types.ErrCodeRateLimited, "Slow down", nil),
+			},
+			http.StatusMultiStatus,
+			`{"results":[{"id":"a","status":204},{"id":"b","status":429,"code":"RATE_LIMITED","error":"Slow down"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			RespondWithBatch(rec, tt.results)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if got := rec.Body.String(); got != tt.body {
+				t.Errorf("body = %s, want %s", got, tt.body)
+			}
+		})
+	}
+}
+
+func BenchmarkRespondWithJSON(b *testing.B) {
+	now := time.Now()
+	dbChirps := make([]database.Chirp, 100)
+	for i := range dbChirps {
+		dbChirps[i] = database.Chirp{
+			ID:          uuid.New(),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			Body:        "Just setting up my chirpy, this is chirp body text",
+			UserID:      uuid.New(),
+			PublishedAt: now,
+		}
+	}
+	response := BuildChirpListResponse(dbChirps)
+
+	b.ReportAllocs()
+	for b.Loop() {
+		RespondWithJSON(httptest.NewRecorder(), http.StatusOK, response)
+	}
+}
+
+type decodeRequest struct {
+	Name string `json:"name" validate:"required,max=10"`
+	Min  int    `json:"min"`
+	Max  int    `json:"max"`
+}
+
+func (r decodeRequest) Validate() error {
+	if r.Min > r.Max {
+		return errors.New("min cannot be more than max")
+	}
+	return nil
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantOK     bool
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "valid", body: `{"name":"chirpy","min":1,"max":2}`, wantOK: true},
+		{name: "malformed", body: `{"name":`, wantStatus: http.StatusInternalServerError},
+		{name: "tag", body: `{"min":1,"max":2}`, wantStatus: http.StatusBadRequest, wantBody: `{"error":"name is required","code":"VALIDATION_FAILED","field":"name"}`},
+		{name: "validate method", body: `{"name":"chirpy","min":3,"max":2}`, wantStatus: http.StatusBadRequest, wantBody: `{"error":"min cannot be more than max","code":"VALIDATION_FAILED"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			var dst decodeRequest
+			if got := DecodeJSON(rec, req, &dst); got != tt.wantOK {
+				t.Fatalf("DecodeJSON() = %v, want %v", got, tt.wantOK)
+			}
+			if tt.wantOK {
+				return
+			}
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %s, want %s", rec.Body, tt.wantBody)
+			}
+
+			// Bodies the handler read itself get the same checks
+			rec = httptest.NewRecorder()
+			if UnmarshalJSON(rec, []byte(tt.body), &decodeRequest{}) || rec.Code != tt.wantStatus {
+				t.Errorf("UnmarshalJSON() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
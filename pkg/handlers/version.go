@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/kai-xlr/neo_chirpy/internal/version"
+)
+
+// HandlerVersion responds to GET /api/version with the running build's
+// version, commit and build time
+func HandlerVersion(w http.ResponseWriter, r *http.Request) {
+	if !RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, version.Get())
+}
@@ -0,0 +1,58 @@
+package instance
+
+import (
+	"net/http"
+
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+// Config holds the instance metadata advertised to client apps
+type Config struct {
+	Name             string
+	Description      string
+	Version          string
+	RegistrationMode string
+	Features         types.InstanceFeatures
+	Reactions        []string
+	Counting         validation.Counting
+	// MigrationKey is the public key account bundles exported from this
+	// instance are signed with; empty when export is disabled
+	MigrationKey string
+}
+
+// HandlerInstance handles GET /api/instance requests. Clients use it to
+// configure themselves (limits, enabled features) against any deployment.
+func (cfg *Config) HandlerInstance(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	// Each hosted community advertises its own name and description
+	name, description := cfg.Name, cfg.Description
+	if t := tenant.FromContext(r.Context()); !t.IsDefault() {
+		name, description = t.Name, t.Description
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, types.InstanceResponse{
+		Name:             name,
+		Description:      description,
+		Version:          cfg.Version,
+		RegistrationMode: cfg.RegistrationMode,
+		Limits: types.InstanceLimits{
+			MaxChirpLength:       validation.MaxChirpLength,
+			ChirpLengthCounting:  string(cfg.Counting),
+			MaxMediaAttachments:  validation.MaxMediaAttachments,
+			MaxAltTextLength:     validation.MaxAltTextLength,
+			MaxChirpDelaySeconds: validation.MaxChirpDelaySeconds,
+			MaxMutedWords:        validation.MaxMutedWords,
+			MinHandleLength:      validation.MinHandleLength,
+			MaxHandleLength:      validation.MaxHandleLength,
+		},
+		Features:     cfg.Features,
+		Reactions:    cfg.Reactions,
+		MigrationKey: cfg.MigrationKey,
+	})
+}
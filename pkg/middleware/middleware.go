@@ -1,19 +1,480 @@
 package middleware
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net"
 	"net/http"
-	"sync/atomic"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
+	"github.com/kai-xlr/neo_chirpy/internal/chaos"
+	"github.com/kai-xlr/neo_chirpy/internal/dataloader"
+	"github.com/kai-xlr/neo_chirpy/internal/loadshed"
+	"github.com/kai-xlr/neo_chirpy/internal/metrics"
+	"github.com/kai-xlr/neo_chirpy/internal/querylog"
+	"github.com/kai-xlr/neo_chirpy/internal/ratelimit"
+	"github.com/kai-xlr/neo_chirpy/internal/rollout"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/internal/timeouts"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
 )
 
 // Config holds configuration needed for middleware
 type Config struct {
-	FileserverHits atomic.Int32
+	FileserverHits *cache.Counter
+	Version        string
+	Tenants        *tenant.Resolver
+	JWTSecret      string
+
+	// RateLimiter limits /api/ requests per client; nil disables limiting
+	RateLimiter *ratelimit.Limiter
+
+	// ServerErrors counts responses with a 5xx status for alerting
+	ServerErrors *cache.Counter
+
+	// Metrics records every request's status and latency for alerting; nil
+	// records nothing
+	Metrics *metrics.Registry
+
+	// FaultInjector injects faults into requests; only set in the dev environment
+	FaultInjector *chaos.Injector
+
+	// Rollout decides which users get features being launched gradually;
+	// nil rolls nothing out
+	Rollout *rollout.Flags
+
+	// Timeouts gives requests a deadline per route group; nil sets none
+	Timeouts *timeouts.Budgets
+
+	// LoadShedder turns away low priority requests while the job backlog or
+	// database latency is too high; nil sheds nothing
+	LoadShedder *loadshed.Shedder
+
+	// ActiveUser reports whether a user's account is active, so access
+	// tokens of deactivated and deleted accounts stop working at once; nil
+	// skips the check
+	ActiveUser func(ctx context.Context, userID uuid.UUID) (bool, error)
 }
 
 // MetricsInc increments the file server hits counter
 func (cfg *Config) MetricsInc(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		cfg.FileserverHits.Add(1)
+		if _, err := cfg.FileserverHits.Inc(r.Context()); err != nil {
+			log.Printf("Couldn't record file server hit: %s", err)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// VersionHeader adds the X-Chirpy-Version header to every response
+func (cfg *Config) VersionHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Chirpy-Version", cfg.Version)
 		next.ServeHTTP(w, r)
 	})
 }
+
+// Tenant resolves the community a request is addressed to and stores it in
+// the request context. The root of a profile's custom domain is routed to
+// the profile's timeline. Access tokens issued by another community are
+// rejected here, so handlers only ever see users of the resolved tenant.
+func (cfg *Config) Tenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t, err := cfg.Tenants.Resolve(r)
+		if errors.Is(err, tenant.ErrUnknownTenant) {
+			handlers.RespondWithError(w, http.StatusNotFound, "Unknown community", nil)
+			return
+		}
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't resolve community", err)
+			return
+		}
+
+		// Invalid tokens and refresh tokens are left for the handlers to reject
+		if principal, err := auth.Authenticate(r, cfg.JWTSecret); err == nil {
+			tokenTenant := principal.Tenant
+			if tokenTenant == "" {
+				tokenTenant = tenant.DefaultSlug
+			}
+			if tokenTenant != t.Slug {
+				handlers.RespondWithError(w, http.StatusUnauthorized, "Token was issued for another community", nil)
+				return
+			}
+		}
+
+		r = r.WithContext(tenant.WithTenant(r.Context(), t))
+		if t.Profile != uuid.Nil {
+			r = profileRoot(r, t.Profile)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// profileRoot serves the root of a profile's custom domain as the profile's
+// timeline
+func profileRoot(r *http.Request, profile uuid.UUID) *http.Request {
+	if r.URL.Path != "/" {
+		return r
+	}
+	u := *r.URL
+	u.Path = "/api/users/" + profile.String() + "/chirps"
+	u.RawPath = ""
+	r.URL = &u
+	return r
+}
+
+// Authenticate reads the principal from a request's access token and
+// stores it in the request context, so handlers and later middleware share
+// one identity instead of each validating the token. Requests without a
+// valid access token pass through anonymously for handlers to reject.
+// Tokens of accounts that are deactivated, or gone, are refused.
+func (cfg *Config) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := auth.GetBearerToken(r.Header)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		principal, err := auth.ParsePrincipal(token, cfg.JWTSecret)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if cfg.ActiveUser != nil {
+			active, err := cfg.ActiveUser(r.Context(), principal.UserID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't check account", err)
+				return
+			}
+			if !active {
+				handlers.RespondWithError(w, http.StatusUnauthorized, "Account is deactivated", nil)
+				return
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// Scopes limits access tokens issued to third-party clients to the scopes
+// the user granted: read for GET and HEAD requests, write for everything
+// else. Account settings, client management and admin routes are off limits
+// to clients whatever their scopes. Tokens from the user's own sessions pass
+// through unchanged.
+func (cfg *Config) Scopes(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := auth.Authenticate(r, cfg.JWTSecret)
+		if err != nil || principal.Kind != auth.TokenClient {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		required := auth.ScopeWrite
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			required = auth.ScopeRead
+		}
+		if clientForbidden(r) || !principal.HasScope(required) {
+			w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+required+`"`)
+			handlers.RespondWithErrorCode(w, http.StatusForbidden, types.ErrCodeInsufficientScope, "The token's scopes don't allow this request", nil)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// clientForbidden reports whether a request is reserved for the user's own
+// sessions, so third-party clients can't take over or manage the account
+func clientForbidden(r *http.Request) bool {
+	path := r.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/admin/"), strings.HasPrefix(path, "/api/oauth/"):
+		return true
+	case path == "/api/users/me", path == "/api/users/me/deactivate", path == "/api/users/me/domain", path == "/api/users/me/migration":
+		return true
+	case path == "/api/users/me/linked-accounts", strings.HasPrefix(path, "/api/users/me/linked-accounts/"):
+		return true
+	case path == "/api/users" && (r.Method == http.MethodPut || r.Method == http.MethodPatch):
+		return true
+	}
+	return false
+}
+
+// RateLimit counts /api/ requests against the client's limit and reports the
+// client's standing in X-RateLimit-* headers on every response, so clients
+// can slow down before they are refused. Authenticated clients are limited
+// per user, anonymous ones per IP address. If the limiter's store fails the
+// request is let through.
+func (cfg *Config) RateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.RateLimiter == nil || !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		result, err := cfg.RateLimiter.Allow(r.Context(), cfg.rateLimitKey(r))
+		if err != nil {
+			log.Printf("Couldn't check rate limit: %s", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(result.Reset.Unix(), 10))
+		if !result.Allowed {
+			retryAfter := int(time.Until(result.Reset).Seconds()) + 1
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			handlers.RespondWithErrorCode(w, http.StatusTooManyRequests, types.ErrCodeRateLimited, "Too many requests", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitKey identifies the client a request counts against: the user for
+// requests with a valid access token, otherwise the remote IP address
+func (cfg *Config) rateLimitKey(r *http.Request) string {
+	if userID, ok := cfg.tokenUser(r); ok {
+		return "user:" + userID.String()
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// tokenUser returns the user of a request with a valid access token
+func (cfg *Config) tokenUser(r *http.Request) (uuid.UUID, bool) {
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return principal.UserID, true
+}
+
+// Variant assigns the user of each /api/ request to the features being
+// rolled out, for handlers to check with rollout.Enabled, and reports the
+// assignment in the X-Chirpy-Variant header so behaviour can be traced back
+// to a cohort. Anonymous requests only get features rolled out to everyone.
+func (cfg *Config) Variant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.Rollout == nil || !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userID, _ := cfg.tokenUser(r)
+		assignment := cfg.Rollout.Assign(userID)
+		if len(assignment) > 0 {
+			w.Header().Set("X-Chirpy-Variant", assignment.String())
+		}
+		next.ServeHTTP(w, r.WithContext(rollout.WithAssignment(r.Context(), assignment)))
+	})
+}
+
+// CountServerErrors increments ServerErrors for every response with a 5xx
+// status, so alert rules can watch for error spikes
+func (cfg *Config) CountServerErrors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		if rec.status >= 500 {
+			if _, err := cfg.ServerErrors.Inc(r.Context()); err != nil {
+				log.Printf("Couldn't record server error: %s", err)
+			}
+		}
+	})
+}
+
+// streamingPaths hold their response open for as long as the client
+// listens or the data lasts, so their latencies say nothing about load
+var streamingPaths = map[string]bool{
+	"/api/chirps/poll":            true,
+	"/api/firehose":               true,
+	"/api/users/me/chirps/export": true,
+	"/admin/logs/stream":          true,
+}
+
+// RecordMetrics records the status and latency of every request in Metrics,
+// apart from streaming ones, and the outcome of every Polka webhook
+func (cfg *Config) RecordMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		if r.URL.Path == "/api/polka/webhooks" {
+			cfg.Metrics.ObserveWebhook(rec.status)
+		}
+		if !streamingPaths[r.URL.Path] {
+			cfg.Metrics.ObserveRequest(rec.status, time.Since(start))
+		}
+	})
+}
+
+// Chaos delays, fails or drops requests matching the rules set through
+// /admin/chaos. Requests to /admin/chaos itself are never touched, so the
+// rules can always be turned off again.
+func (cfg *Config) Chaos(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.FaultInjector == nil || strings.HasPrefix(r.URL.Path, "/admin/chaos") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		rule, ok := cfg.FaultInjector.Match(r.URL.Path)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if rule.LatencyMS > 0 {
+			timer := time.NewTimer(time.Duration(rule.LatencyMS) * time.Millisecond)
+			select {
+			case <-timer.C:
+			case <-r.Context().Done():
+				timer.Stop()
+				return
+			}
+		}
+		switch rule.Fault {
+		case chaos.FaultError:
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Injected fault", nil)
+		case chaos.FaultDrop:
+			// net/http closes the connection without writing a response
+			panic(http.ErrAbortHandler)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// shedRetryAfter is how long shed clients are asked to wait, long enough
+// for a backlog to drain a little
+const shedRetryAfter = 5 * time.Second
+
+// Shed responds 503 with the code OVERLOADED to requests whose route is
+// less important than the server's current load allows, asking the client
+// to retry later. Critical routes such as login and posting are always let
+// through.
+func (cfg *Config) Shed(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !cfg.LoadShedder.Shed(r.Method, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(int(shedRetryAfter.Seconds())))
+		handlers.RespondWithErrorCode(w, http.StatusServiceUnavailable, types.ErrCodeOverloaded, "Server is busy, try again shortly", nil)
+	})
+}
+
+// Timeout puts a deadline on the request context from the route's budget.
+// Handlers can't be interrupted, but their database queries and outbound
+// requests fail once it passes; if the handler then responds with a 5xx
+// error, the client gets 503 with the code TIMEOUT instead. Streaming
+// routes are never given a deadline.
+func (cfg *Config) Timeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		budget := cfg.Timeouts.For(r.Method, r.URL.Path)
+		if budget <= 0 || streamingPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), budget)
+		defer cancel()
+		next.ServeHTTP(&timeoutWriter{ResponseWriter: w, ctx: ctx}, r.WithContext(ctx))
+	})
+}
+
+// timeoutWriter replaces a handler's error response with 503 once the
+// request's deadline has passed
+type timeoutWriter struct {
+	http.ResponseWriter
+	ctx         context.Context
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	if tw.timedOut {
+		return
+	}
+	if !tw.wroteHeader && status >= 500 && errors.Is(tw.ctx.Err(), context.DeadlineExceeded) {
+		tw.wroteHeader, tw.timedOut = true, true
+		handlers.RespondWithErrorCode(tw.ResponseWriter, http.StatusServiceUnavailable, types.ErrCodeTimeout, "Request timed out", nil)
+		return
+	}
+	tw.wroteHeader = true
+	tw.ResponseWriter.WriteHeader(status)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	if tw.timedOut {
+		// The handler's own error body is dropped
+		return len(b), nil
+	}
+	return tw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to flush
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
+
+// DataLoaders gives each request its own dataloader scope, so related
+// records embedded in a response are fetched once per request
+func (cfg *Config) DataLoaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(dataloader.WithScope(r.Context())))
+	})
+}
+
+// RequestLog logs one line per request with its status, duration and the
+// number of database queries it made
+func (cfg *Config) RequestLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ctx, stats := querylog.WithStats(r.Context())
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		log.Printf("%s %s %d %s queries=%d db=%s",
+			r.Method, r.URL.Path, rec.status,
+			time.Since(start).Round(time.Microsecond),
+			stats.Queries(), stats.Duration().Round(time.Microsecond))
+	})
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to flush
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
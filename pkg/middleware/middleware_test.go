@@ -0,0 +1,464 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
+	"github.com/kai-xlr/neo_chirpy/internal/chaos"
+	"github.com/kai-xlr/neo_chirpy/internal/loadshed"
+	"github.com/kai-xlr/neo_chirpy/internal/metrics"
+	"github.com/kai-xlr/neo_chirpy/internal/ratelimit"
+	"github.com/kai-xlr/neo_chirpy/internal/rollout"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/internal/timeouts"
+)
+
+func TestTenantRejectsForeignTokens(t *testing.T) {
+	const secret = "test-secret"
+	cfg := &Config{Tenants: &tenant.Resolver{}, JWTSecret: secret}
+
+	defaultToken, err := auth.MakeJWT(uuid.New(), secret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreignToken, err := auth.MakeTenantJWT(uuid.New(), "birds", secret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		auth       string
+		wantStatus int
+	}{
+		{name: "anonymous", auth: "", wantStatus: http.StatusOK},
+		{name: "default community token", auth: "Bearer " + defaultToken, wantStatus: http.StatusOK},
+		{name: "other community token", auth: "Bearer " + foreignToken, wantStatus: http.StatusUnauthorized},
+		{name: "opaque refresh token", auth: "Bearer 0123abcd", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resolved tenant.Tenant
+			handler := cfg.Tenant(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				resolved = tenant.FromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/chirps", nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && !resolved.IsDefault() {
+				t.Errorf("resolved tenant = %+v, want default", resolved)
+			}
+		})
+	}
+}
+
+func TestProfileRoot(t *testing.T) {
+	profile := uuid.New()
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/", want: "/api/users/" + profile.String() + "/chirps"},
+		{path: "/?cursor=abc", want: "/api/users/" + profile.String() + "/chirps"},
+		{path: "/api/chirps", want: "/api/chirps"},
+		{path: "/app/", want: "/app/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			got := profileRoot(req, profile)
+			if got.URL.Path != tt.want {
+				t.Errorf("path = %q, want %q", got.URL.Path, tt.want)
+			}
+			if got.URL.RawQuery != req.URL.RawQuery {
+				t.Errorf("query = %q, want %q", got.URL.RawQuery, req.URL.RawQuery)
+			}
+		})
+	}
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	cfg := &Config{RateLimiter: ratelimit.New(cache.NewMemory(), 2, time.Minute), JWTSecret: "test-secret"}
+	handler := cfg.RateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for i, want := range []struct {
+		status    int
+		remaining string
+	}{
+		{status: http.StatusOK, remaining: "1"},
+		{status: http.StatusOK, remaining: "0"},
+		{status: http.StatusTooManyRequests, remaining: "0"},
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/chirps", nil))
+
+		if rec.Code != want.status {
+			t.Fatalf("request %d: status = %d, want %d", i, rec.Code, want.status)
+		}
+		if got := rec.Header().Get("X-RateLimit-Limit"); got != "2" {
+			t.Errorf("request %d: X-RateLimit-Limit = %q, want %q", i, got, "2")
+		}
+		if got := rec.Header().Get("X-RateLimit-Remaining"); got != want.remaining {
+			t.Errorf("request %d: X-RateLimit-Remaining = %q, want %q", i, got, want.remaining)
+		}
+		if rec.Header().Get("X-RateLimit-Reset") == "" {
+			t.Errorf("request %d: missing X-RateLimit-Reset", i)
+		}
+		if limited := rec.Header().Get("Retry-After") != ""; limited != (want.status == http.StatusTooManyRequests) {
+			t.Errorf("request %d: Retry-After = %q", i, rec.Header().Get("Retry-After"))
+		}
+	}
+
+	// Users are limited separately from the address they connect from
+	token, err := auth.MakeJWT(uuid.New(), cfg.JWTSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/api/chirps", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("authenticated status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	// Only API routes are limited
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/app/", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("X-RateLimit-Limit") != "" {
+		t.Errorf("/app/ status = %d, X-RateLimit-Limit = %q", rec.Code, rec.Header().Get("X-RateLimit-Limit"))
+	}
+}
+
+func TestScopes(t *testing.T) {
+	const secret = "test-secret"
+	cfg := &Config{JWTSecret: secret}
+	var clientID string
+	handler := cfg.Scopes(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID = auth.ClientIDFromContext(r.Context())
+	}))
+
+	sessionToken, err := auth.MakeJWT(uuid.New(), secret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readToken, err := auth.MakeScopedJWT(uuid.New(), "", "client", []string{auth.ScopeRead}, secret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeToken, err := auth.MakeScopedJWT(uuid.New(), "", "client", []string{auth.ScopeRead, auth.ScopeWrite}, secret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+		wantClient string
+	}{
+		{name: "session token writes", method: http.MethodPost, path: "/api/chirps", token: sessionToken, wantStatus: http.StatusOK},
+		{name: "session token manages clients", method: http.MethodGet, path: "/api/oauth/clients", token: sessionToken, wantStatus: http.StatusOK},
+		{name: "read scope reads", method: http.MethodGet, path: "/api/chirps", token: readToken, wantStatus: http.StatusOK, wantClient: "client"},
+		{name: "read scope can't write", method: http.MethodPost, path: "/api/chirps", token: readToken, wantStatus: http.StatusForbidden},
+		{name: "write scope writes", method: http.MethodPost, path: "/api/chirps", token: writeToken, wantStatus: http.StatusOK, wantClient: "client"},
+		{name: "client can't change account", method: http.MethodPut, path: "/api/users", token: writeToken, wantStatus: http.StatusForbidden},
+		{name: "client can't patch account", method: http.MethodPatch, path: "/api/users", token: writeToken, wantStatus: http.StatusForbidden},
+		{name: "client can't deactivate", method: http.MethodPost, path: "/api/users/me/deactivate", token: writeToken, wantStatus: http.StatusForbidden},
+		{name: "client can't delete the account", method: http.MethodDelete, path: "/api/users/me", token: writeToken, wantStatus: http.StatusForbidden},
+		{name: "client can't set a custom domain", method: http.MethodPut, path: "/api/users/me/domain", token: writeToken, wantStatus: http.StatusForbidden},
+		{name: "client can't export the account", method: http.MethodGet, path: "/api/users/me/migration", token: writeToken, wantStatus: http.StatusForbidden},
+		{name: "client can't import over the account", method: http.MethodPost, path: "/api/users/me/migration", token: writeToken, wantStatus: http.StatusForbidden},
+		{name: "client can't link accounts", method: http.MethodPost, path: "/api/users/me/linked-accounts", token: writeToken, wantStatus: http.StatusForbidden},
+		{name: "client can't switch accounts", method: http.MethodPost, path: "/api/users/me/linked-accounts/" + uuid.NewString() + "/token", token: writeToken, wantStatus: http.StatusForbidden},
+		{name: "client can't manage clients", method: http.MethodGet, path: "/api/oauth/clients", token: writeToken, wantStatus: http.StatusForbidden},
+		{name: "client can't reach admin", method: http.MethodGet, path: "/admin/metrics", token: writeToken, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientID = ""
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if clientID != tt.wantClient {
+				t.Errorf("client ID in context = %q, want %q", clientID, tt.wantClient)
+			}
+			if forbidden := rec.Header().Get("WWW-Authenticate") != ""; forbidden != (tt.wantStatus == http.StatusForbidden) {
+				t.Errorf("WWW-Authenticate = %q", rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestCountServerErrors(t *testing.T) {
+	cfg := &Config{ServerErrors: cache.NewCounter(cache.NewMemory(), "server_errors")}
+
+	for _, status := range []int{http.StatusOK, http.StatusNotFound, http.StatusInternalServerError, http.StatusBadGateway} {
+		handler := cfg.CountServerErrors(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/chirps", nil))
+	}
+
+	count, err := cfg.ServerErrors.Value(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("server errors = %d, want 2", count)
+	}
+}
+
+func TestRecordMetrics(t *testing.T) {
+	cfg := &Config{Metrics: metrics.NewRegistry()}
+	serve := func(path string, status int) {
+		handler := cfg.RecordMetrics(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, path, nil))
+	}
+
+	serve("/api/chirps", http.StatusCreated)
+	serve("/api/chirps/poll", http.StatusOK)
+	serve("/api/polka/webhooks", http.StatusInternalServerError)
+	serve("/api/polka/webhooks", http.StatusInternalServerError)
+
+	snapshot := cfg.Metrics.Snapshot()
+	if snapshot.Requests != 3 || snapshot.ServerErrors != 2 {
+		t.Errorf("recorded %d requests, %d errors, want 3, 2", snapshot.Requests, snapshot.ServerErrors)
+	}
+	if streak := cfg.Metrics.WebhookFailureStreak(); streak != 2 {
+		t.Errorf("webhook failure streak = %d, want 2", streak)
+	}
+}
+
+func TestChaos(t *testing.T) {
+	injector := chaos.New()
+	err := injector.SetRules([]chaos.Rule{
+		{Path: "/api/chirps", Percent: 100, Fault: chaos.FaultError},
+		{Path: "/api/users", Percent: 100, Fault: chaos.FaultDrop},
+		{Path: "/admin/", Percent: 100, Fault: chaos.FaultError},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{FaultInjector: injector}
+	handler := cfg.Chaos(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantPanic  bool
+	}{
+		{path: "/api/chirps", wantStatus: http.StatusInternalServerError},
+		{path: "/api/users", wantPanic: true},
+		{path: "/admin/chaos", wantStatus: http.StatusOK},
+		{path: "/api/healthz", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r == http.ErrAbortHandler) != tt.wantPanic {
+					t.Errorf("recovered %v, want abort %v", r, tt.wantPanic)
+				}
+			}()
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	budgets, err := timeouts.Parse([]string{"/api/slow=10ms", "/api/health=0s"}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{Timeouts: budgets}
+	handler := cfg.Timeout(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, hasDeadline := r.Context().Deadline(); !hasDeadline {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.URL.Path == "/api/slow" {
+			<-r.Context().Done()
+		}
+		http.Error(w, "database unavailable", http.StatusInternalServerError)
+	}))
+
+	tests := []struct {
+		path       string
+		wantStatus int
+	}{
+		{path: "/api/slow", wantStatus: http.StatusServiceUnavailable},
+		{path: "/api/chirps", wantStatus: http.StatusInternalServerError},
+		{path: "/api/health", wantStatus: http.StatusNoContent},
+		{path: "/api/firehose", wantStatus: http.StatusNoContent},
+		{path: "/admin/logs/stream", wantStatus: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAuthenticateRefusesDeactivatedAccounts(t *testing.T) {
+	const secret = "test-secret"
+	activeID, deactivatedID := uuid.New(), uuid.New()
+	cfg := &Config{
+		JWTSecret: secret,
+		ActiveUser: func(_ context.Context, userID uuid.UUID) (bool, error) {
+			switch userID {
+			case activeID:
+				return true, nil
+			case deactivatedID:
+				return false, nil
+			}
+			return false, sql.ErrNoRows
+		},
+	}
+	handler := cfg.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name       string
+		userID     uuid.UUID
+		wantStatus int
+	}{
+		{name: "active", userID: activeID, wantStatus: http.StatusNoContent},
+		{name: "deactivated", userID: deactivatedID, wantStatus: http.StatusUnauthorized},
+		{name: "deleted", userID: uuid.New(), wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := auth.MakeJWT(tt.userID, secret, time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/api/chirps", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestShed(t *testing.T) {
+	priorities, err := loadshed.ParsePriorities(loadshed.DefaultPriorities)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{LoadShedder: &loadshed.Shedder{
+		Priorities: priorities,
+		Backlog:    func() int { return 150 },
+		MaxBacklog: 100,
+	}}
+	handler := cfg.Shed(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{method: http.MethodGet, path: "/api/chirps/search", wantStatus: http.StatusServiceUnavailable},
+		{method: http.MethodGet, path: "/api/chirps", wantStatus: http.StatusNoContent},
+		{method: http.MethodPost, path: "/api/login", wantStatus: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusServiceUnavailable && rec.Header().Get("Retry-After") == "" {
+				t.Error("shed response has no Retry-After header")
+			}
+		})
+	}
+}
+
+func TestVariant(t *testing.T) {
+	const secret = "test-secret"
+	flags, err := rollout.Parse([]string{"everyone=100", "nobody=0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &Config{JWTSecret: secret, Rollout: flags}
+	token, err := auth.MakeJWT(uuid.New(), secret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		auth        string
+		wantVariant string
+	}{
+		{name: "authenticated", path: "/api/chirps", auth: "Bearer " + token, wantVariant: "everyone=on,nobody=off"},
+		{name: "anonymous", path: "/api/chirps", wantVariant: "everyone=on,nobody=off"},
+		{name: "outside the API", path: "/app/", auth: "Bearer " + token},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var enabled bool
+			handler := cfg.Variant(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				enabled = rollout.Enabled(r.Context(), "everyone")
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("X-Chirpy-Variant"); got != tt.wantVariant {
+				t.Errorf("X-Chirpy-Variant = %q, want %q", got, tt.wantVariant)
+			}
+			if enabled != (tt.wantVariant != "") {
+				t.Errorf("rollout.Enabled() = %v in the handler", enabled)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package oauth
+
+import (
+	"embed"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+//go:embed templates/authorize.html
+var templateFS embed.FS
+
+var authorizeTemplate = template.Must(template.ParseFS(templateFS, "templates/authorize.html"))
+
+// scopeDescriptions explain each scope on the authorize page
+var scopeDescriptions = map[string]string{
+	auth.ScopeRead:  "Read chirps and your account's settings",
+	auth.ScopeWrite: "Post, edit and delete chirps and react on your behalf",
+}
+
+// authorizeRequest is a validated authorization request
+type authorizeRequest struct {
+	client              database.OauthClient
+	redirectURI         string
+	scopes              []string
+	state               string
+	codeChallenge       string
+	codeChallengeMethod string
+}
+
+// authorizePage is the data rendered by the authorize template
+type authorizePage struct {
+	Fatal               string
+	Error               string
+	ClientName          string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	ScopeDescriptions   []string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Email               string
+}
+
+// HandlerAuthorize handles /api/oauth/authorize requests. GET shows the
+// consent page, where the user signs in and allows or denies the client;
+// POST receives that form and redirects back to the client with an
+// authorization code or an error.
+func (cfg *Config) HandlerAuthorize(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		cfg.handlerAuthorizeGet(w, r)
+	case http.MethodPost:
+		cfg.handlerAuthorizePost(w, r)
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+	}
+}
+
+// handlerAuthorizeGet handles GET /api/oauth/authorize requests
+func (cfg *Config) handlerAuthorizeGet(w http.ResponseWriter, r *http.Request) {
+	request, ok := cfg.parseAuthorizeRequest(w, r, r.URL.Query())
+	if !ok {
+		return
+	}
+	renderAuthorizePage(w, http.StatusOK, request.page())
+}
+
+// handlerAuthorizePost handles POST /api/oauth/authorize requests
+func (cfg *Config) handlerAuthorizePost(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		renderAuthorizePage(w, http.StatusBadRequest, authorizePage{Fatal: "The request couldn't be read."})
+		return
+	}
+
+	request, ok := cfg.parseAuthorizeRequest(w, r, r.PostForm)
+	if !ok {
+		return
+	}
+
+	if r.PostForm.Get("decision") != "allow" {
+		redirectToClient(w, r, request.redirectURI, url.Values{
+			"error": {"access_denied"},
+			"state": {request.state},
+		})
+		return
+	}
+
+	// Third-party apps can't reactivate a deactivated account
+	email := r.PostForm.Get("email")
+	user, err := cfg.DB.GetUserByEmail(r.Context(), database.GetUserByEmailParams{
+		TenantID: tenant.FromContext(r.Context()).ID,
+		Email:    email,
+	})
+	if err == nil {
+		err = auth.VerifyPassword(r.PostForm.Get("password"), user.HashedPassword)
+	}
+	if err != nil || user.DeactivatedAt.Valid {
+		page := request.page()
+		page.Email = email
+		page.Error = "Incorrect email or password."
+		renderAuthorizePage(w, http.StatusUnauthorized, page)
+		return
+	}
+
+	code, err := auth.MakeRefreshToken()
+	if err != nil {
+		renderAuthorizePage(w, http.StatusInternalServerError, authorizePage{Fatal: "Something went wrong, please try again."})
+		return
+	}
+	if err := cfg.DB.CreateOAuthCode(r.Context(), database.CreateOAuthCodeParams{
+		Code:          code,
+		ClientID:      request.client.ID,
+		UserID:        user.ID,
+		RedirectUri:   request.redirectURI,
+		Scopes:        request.scopes,
+		CodeChallenge: request.codeChallenge,
+		ExpiresAt:     time.Now().UTC().Add(codeLifetime),
+	}); err != nil {
+		log.Printf("Couldn't create authorization code: %s", err)
+		renderAuthorizePage(w, http.StatusInternalServerError, authorizePage{Fatal: "Something went wrong, please try again."})
+		return
+	}
+
+	redirectToClient(w, r, request.redirectURI, url.Values{
+		"code":  {code},
+		"state": {request.state},
+	})
+}
+
+// parseAuthorizeRequest validates the authorization request parameters. An
+// unknown client or redirect URI is shown as an error page, since the user
+// can't safely be sent anywhere; other errors are reported to the client's
+// redirect URI. ok is false when a response has been written.
+func (cfg *Config) parseAuthorizeRequest(w http.ResponseWriter, r *http.Request, values url.Values) (authorizeRequest, bool) {
+	client, err := cfg.DB.GetOAuthClient(r.Context(), database.GetOAuthClientParams{
+		ClientID: values.Get("client_id"),
+		TenantID: tenant.FromContext(r.Context()).ID,
+	})
+	if err != nil {
+		renderAuthorizePage(w, http.StatusBadRequest, authorizePage{Fatal: "This app isn't registered."})
+		return authorizeRequest{}, false
+	}
+
+	redirectURI := values.Get("redirect_uri")
+	if redirectURI == "" && len(client.RedirectUris) == 1 {
+		redirectURI = client.RedirectUris[0]
+	}
+	if !slices.Contains(client.RedirectUris, redirectURI) {
+		renderAuthorizePage(w, http.StatusBadRequest, authorizePage{Fatal: "The app sent an unregistered redirect URI."})
+		return authorizeRequest{}, false
+	}
+
+	request := authorizeRequest{
+		client:              client,
+		redirectURI:         redirectURI,
+		state:               values.Get("state"),
+		codeChallenge:       values.Get("code_challenge"),
+		codeChallengeMethod: values.Get("code_challenge_method"),
+	}
+
+	fail := func(code, description string) (authorizeRequest, bool) {
+		redirectToClient(w, r, redirectURI, url.Values{
+			"error":             {code},
+			"error_description": {description},
+			"state":             {request.state},
+		})
+		return authorizeRequest{}, false
+	}
+
+	if values.Get("response_type") != "code" {
+		return fail("unsupported_response_type", "Only the authorization code flow is supported")
+	}
+	if request.scopes, err = validation.ParseScopes(values.Get("scope")); err != nil {
+		return fail("invalid_scope", err.Error())
+	}
+	if request.codeChallenge != "" && request.codeChallengeMethod != "S256" {
+		return fail("invalid_request", "code_challenge_method must be S256")
+	}
+	return request, true
+}
+
+// page returns the consent page for the request
+func (req authorizeRequest) page() authorizePage {
+	descriptions := make([]string, len(req.scopes))
+	for i, scope := range req.scopes {
+		descriptions[i] = scopeDescriptions[scope]
+	}
+	return authorizePage{
+		ClientName:          req.client.Name,
+		ClientID:            req.client.ClientID,
+		RedirectURI:         req.redirectURI,
+		Scope:               strings.Join(req.scopes, " "),
+		ScopeDescriptions:   descriptions,
+		State:               req.state,
+		CodeChallenge:       req.codeChallenge,
+		CodeChallengeMethod: req.codeChallengeMethod,
+	}
+}
+
+// renderAuthorizePage writes the consent page. It must never be framed,
+// so another site can't trick users into clicking Allow.
+func renderAuthorizePage(w http.ResponseWriter, status int, page authorizePage) {
+	w.Header().Set("Content-Type", types.ContentTypeTextHTML)
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "frame-ancestors 'none'")
+	w.WriteHeader(status)
+	if err := authorizeTemplate.Execute(w, page); err != nil {
+		log.Printf("Couldn't render authorize page: %s", err)
+	}
+}
+
+// redirectToClient sends the user back to the client's redirect URI with the
+// given parameters added to its query. Empty parameters are left out.
+func redirectToClient(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values) {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		renderAuthorizePage(w, http.StatusBadRequest, authorizePage{Fatal: "The app's redirect URI is invalid."})
+		return
+	}
+
+	query := target.Query()
+	for key, values := range params {
+		if len(values) > 0 && values[0] != "" {
+			query.Set(key, values[0])
+		}
+	}
+	target.RawQuery = query.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
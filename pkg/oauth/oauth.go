@@ -0,0 +1,247 @@
+// Package oauth lets Chirpy act as an OAuth2 provider. Users register
+// third-party clients, grant them scoped access through the authorization
+// code flow and can revoke that access later.
+package oauth
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+// Lifetimes of the credentials issued by the provider
+const (
+	codeLifetime         = 10 * time.Minute
+	accessTokenLifetime  = time.Hour
+	refreshTokenLifetime = 60 * 24 * time.Hour
+)
+
+// Config holds the configuration needed for OAuth handlers
+type Config struct {
+	DB        *database.Queries
+	JWTSecret string
+}
+
+// HandlerClients dispatches /api/oauth/clients requests based on HTTP method
+func (cfg *Config) HandlerClients(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		cfg.handlerClientsGet(w, r)
+	case http.MethodPost:
+		cfg.handlerClientsCreate(w, r)
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+	}
+}
+
+// handlerClientsCreate handles POST /api/oauth/clients requests. The client
+// secret is only returned in this response; the server keeps a hash.
+func (cfg *Config) handlerClientsCreate(w http.ResponseWriter, r *http.Request) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	var request types.OAuthClientRequest
+	if !handlers.DecodeJSON(w, r, &request) {
+		return
+	}
+
+	if err := validation.ValidateClientName(request.Name); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if err := validation.ValidateRedirectURIs(request.RedirectURIs); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	clientID, err := auth.MakeRefreshToken()
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't create client", err)
+		return
+	}
+	clientID = clientID[:32]
+
+	clientSecret, err := auth.MakeRefreshToken()
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't create client", err)
+		return
+	}
+	secretHash, err := auth.HashPassword(clientSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't create client", err)
+		return
+	}
+
+	client, err := cfg.DB.CreateOAuthClient(r.Context(), database.CreateOAuthClientParams{
+		ClientID:     clientID,
+		SecretHash:   secretHash,
+		Name:         strings.TrimSpace(request.Name),
+		RedirectUris: request.RedirectURIs,
+		UserID:       userID,
+		TenantID:     tenant.FromContext(r.Context()).ID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't create client", err)
+		return
+	}
+
+	response := buildClientResponse(client)
+	response.ClientSecret = clientSecret
+	handlers.RespondWithJSON(w, http.StatusCreated, response)
+}
+
+// handlerClientsGet handles GET /api/oauth/clients requests, listing the
+// clients the user registered
+func (cfg *Config) handlerClientsGet(w http.ResponseWriter, r *http.Request) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	dbClients, err := cfg.DB.ListOAuthClientsByOwner(r.Context(), userID)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve clients", err)
+		return
+	}
+
+	clients := make([]types.OAuthClient, len(dbClients))
+	for i, client := range dbClients {
+		clients[i] = buildClientResponse(client)
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, clients)
+}
+
+// HandlerClientByID handles DELETE /api/oauth/clients/{client_id} requests.
+// Deleting a client also removes every token issued to it.
+func (cfg *Config) HandlerClientByID(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodDelete) {
+		return
+	}
+
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	deleted, err := cfg.DB.DeleteOAuthClient(r.Context(), database.DeleteOAuthClientParams{
+		ClientID: handlers.ExtractIDFromPath(r.URL.Path, "/api/oauth/clients/"),
+		UserID:   userID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't delete client", err)
+		return
+	}
+	if deleted == 0 {
+		handlers.RespondWithError(w, http.StatusNotFound, "Client not found", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandlerGrants handles GET /api/oauth/grants requests, listing the clients
+// that currently hold tokens for the user
+func (cfg *Config) HandlerGrants(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	dbGrants, err := cfg.DB.ListOAuthGrants(r.Context(), userID)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve authorized apps", err)
+		return
+	}
+
+	grants := make([]types.OAuthGrant, len(dbGrants))
+	for i, grant := range dbGrants {
+		grants[i] = types.OAuthGrant{
+			ClientID:     grant.ClientID,
+			Name:         grant.Name,
+			AuthorizedAt: types.NewTimestamp(grant.AuthorizedAt),
+		}
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, grants)
+}
+
+// HandlerGrantByID handles DELETE /api/oauth/grants/{client_id} requests,
+// revoking the client's refresh tokens for the user. Access tokens already
+// issued stay valid until they expire, at most an hour later.
+func (cfg *Config) HandlerGrantByID(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodDelete) {
+		return
+	}
+
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	revoked, err := cfg.DB.RevokeOAuthGrant(r.Context(), database.RevokeOAuthGrantParams{
+		ClientID: handlers.ExtractIDFromPath(r.URL.Path, "/api/oauth/grants/"),
+		UserID:   userID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't revoke access", err)
+		return
+	}
+	if revoked == 0 {
+		handlers.RespondWithError(w, http.StatusNotFound, "Authorized app not found", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PurgeExpiredTokens deletes authorization codes and refresh tokens past
+// their expiry
+func (cfg *Config) PurgeExpiredTokens(ctx context.Context) error {
+	deleted, err := cfg.DB.DeleteExpiredOAuthTokens(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Printf("Deleted %d expired OAuth tokens", deleted)
+	}
+	return nil
+}
+
+// buildClientResponse converts a database client to API response format
+func buildClientResponse(client database.OauthClient) types.OAuthClient {
+	return types.OAuthClient{
+		ClientID:     client.ClientID,
+		CreatedAt:    types.NewTimestamp(client.CreatedAt),
+		Name:         client.Name,
+		RedirectURIs: client.RedirectUris,
+	}
+}
@@ -0,0 +1,78 @@
+package oauth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	const verifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	sum := sha256.Sum256([]byte(verifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	tests := []struct {
+		name      string
+		challenge string
+		verifier  string
+		want      bool
+	}{
+		{name: "matching verifier", challenge: challenge, verifier: verifier, want: true},
+		{name: "wrong verifier", challenge: challenge, verifier: "wrong", want: false},
+		{name: "missing verifier", challenge: challenge, verifier: "", want: false},
+		{name: "no challenge", challenge: "", verifier: "", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verifyCodeChallenge(tt.challenge, tt.verifier); got != tt.want {
+				t.Errorf("verifyCodeChallenge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedirectToClient(t *testing.T) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/oauth/authorize", nil)
+	redirectToClient(rec, req, "https://app.example.com/callback?from=chirpy", url.Values{
+		"code":  {"abc"},
+		"state": {""},
+	})
+
+	if rec.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusFound)
+	}
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := location.Query()
+	if query.Get("from") != "chirpy" || query.Get("code") != "abc" {
+		t.Errorf("query = %v, want the redirect URI's own parameters and the code", query)
+	}
+	if query.Has("state") {
+		t.Errorf("empty state was added to the redirect: %v", query)
+	}
+}
+
+func TestRenderAuthorizePage(t *testing.T) {
+	rec := httptest.NewRecorder()
+	renderAuthorizePage(rec, http.StatusOK, authorizePage{
+		ClientName: `<script>alert("hi")</script>`,
+		ClientID:   "client",
+		State:      `"><script>`,
+	})
+
+	body := rec.Body.String()
+	if strings.Contains(body, "<script>") {
+		t.Errorf("page contains unescaped client input:\n%s", body)
+	}
+	if rec.Header().Get("X-Frame-Options") != "DENY" {
+		t.Errorf("X-Frame-Options = %q, want DENY", rec.Header().Get("X-Frame-Options"))
+	}
+}
@@ -0,0 +1,159 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// HandlerToken handles POST /api/oauth/token requests. Clients authenticate
+// with HTTP Basic auth or client_id and client_secret form fields, and
+// exchange an authorization code or a refresh token for new tokens. Refresh
+// tokens are single use: each refresh returns a replacement.
+func (cfg *Config) HandlerToken(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodPost) {
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err := r.ParseForm(); err != nil {
+		respondWithOAuthError(w, http.StatusBadRequest, "invalid_request", "The request body couldn't be read")
+		return
+	}
+
+	client, ok := cfg.authenticateClient(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="chirpy"`)
+		respondWithOAuthError(w, http.StatusUnauthorized, "invalid_client", "Client authentication failed")
+		return
+	}
+
+	var userID uuid.UUID
+	var scopes []string
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		code, err := cfg.DB.ConsumeOAuthCode(r.Context(), database.ConsumeOAuthCodeParams{
+			Code:     r.PostForm.Get("code"),
+			ClientID: client.ID,
+		})
+		if err != nil || code.RedirectUri != r.PostForm.Get("redirect_uri") {
+			respondWithOAuthError(w, http.StatusBadRequest, "invalid_grant", "The authorization code is invalid or expired")
+			return
+		}
+		if !verifyCodeChallenge(code.CodeChallenge, r.PostForm.Get("code_verifier")) {
+			respondWithOAuthError(w, http.StatusBadRequest, "invalid_grant", "The code verifier doesn't match")
+			return
+		}
+		userID, scopes = code.UserID, code.Scopes
+	case "refresh_token":
+		token, err := cfg.DB.RotateOAuthToken(r.Context(), database.RotateOAuthTokenParams{
+			Token:    r.PostForm.Get("refresh_token"),
+			ClientID: client.ID,
+		})
+		if err != nil {
+			respondWithOAuthError(w, http.StatusBadRequest, "invalid_grant", "The refresh token is invalid or expired")
+			return
+		}
+		userID, scopes = token.UserID, token.Scopes
+	default:
+		respondWithOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "Use authorization_code or refresh_token")
+		return
+	}
+
+	response, err := cfg.issueTokens(r.Context(), client, userID, scopes)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't create tokens", err)
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// authenticateClient looks up the client of the request's community and
+// checks its secret
+func (cfg *Config) authenticateClient(r *http.Request) (database.OauthClient, bool) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID == "" || clientSecret == "" {
+		return database.OauthClient{}, false
+	}
+
+	client, err := cfg.DB.GetOAuthClient(r.Context(), database.GetOAuthClientParams{
+		ClientID: clientID,
+		TenantID: tenant.FromContext(r.Context()).ID,
+	})
+	if err != nil {
+		return database.OauthClient{}, false
+	}
+	if err := auth.VerifyPassword(clientSecret, client.SecretHash); err != nil {
+		return database.OauthClient{}, false
+	}
+	return client, true
+}
+
+// issueTokens creates a scoped access token and a refresh token for the client
+func (cfg *Config) issueTokens(ctx context.Context, client database.OauthClient, userID uuid.UUID, scopes []string) (types.OAuthTokenResponse, error) {
+	t := tenant.FromContext(ctx)
+	slug := t.Slug
+	if t.IsDefault() {
+		slug = ""
+	}
+	accessToken, err := auth.MakeScopedJWT(userID, slug, client.ClientID, scopes, cfg.JWTSecret, accessTokenLifetime)
+	if err != nil {
+		return types.OAuthTokenResponse{}, err
+	}
+
+	refreshToken, err := auth.MakeRefreshToken()
+	if err != nil {
+		return types.OAuthTokenResponse{}, err
+	}
+	if _, err := cfg.DB.CreateOAuthToken(ctx, database.CreateOAuthTokenParams{
+		Token:     refreshToken,
+		ClientID:  client.ID,
+		UserID:    userID,
+		Scopes:    scopes,
+		ExpiresAt: time.Now().UTC().Add(refreshTokenLifetime),
+	}); err != nil {
+		return types.OAuthTokenResponse{}, err
+	}
+
+	return types.OAuthTokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(accessTokenLifetime.Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        strings.Join(scopes, " "),
+	}, nil
+}
+
+// verifyCodeChallenge checks a PKCE code verifier against the S256 challenge
+// sent with the authorization request. Codes issued without a challenge
+// need no verifier.
+func verifyCodeChallenge(challenge, verifier string) bool {
+	if challenge == "" {
+		return true
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// respondWithOAuthError writes an RFC 6749 error response
+func respondWithOAuthError(w http.ResponseWriter, code int, oauthErr, description string) {
+	handlers.RespondWithJSON(w, code, types.OAuthError{
+		Error:            oauthErr,
+		ErrorDescription: description,
+	})
+}
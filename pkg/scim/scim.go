@@ -0,0 +1,514 @@
+// Package scim lets identity providers such as Okta and Azure AD manage a
+// community's accounts over SCIM 2.0 (RFC 7643 and RFC 7644). Providers
+// authenticate with an API key of the scim tier.
+package scim
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+// SCIM schema URNs
+const (
+	schemaUser  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	schemaList  = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	schemaError = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// SCIM error types, from RFC 7644 section 3.12
+const (
+	errInvalidFilter = "invalidFilter"
+	errInvalidSyntax = "invalidSyntax"
+	errInvalidValue  = "invalidValue"
+	errUniqueness    = "uniqueness"
+)
+
+const (
+	usersPrefix = "/scim/v2/Users/"
+
+	// defaultCount and maxCount bound the users in one list response
+	defaultCount = 100
+	maxCount     = 200
+)
+
+// filterPattern matches the one filter providers need to find an account
+// before creating it: userName eq "value"
+var filterPattern = regexp.MustCompile(`(?i)^\s*userName\s+eq\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// Config holds configuration needed for the SCIM handlers
+type Config struct {
+	DB     *database.Queries
+	Events *events.Bus
+}
+
+// userAttributes are the account fields a provider controls. Null username
+// and hashedPassword keep the current values.
+type userAttributes struct {
+	email          string
+	username       sql.NullString
+	hashedPassword sql.NullString
+	active         bool
+	externalID     sql.NullString
+}
+
+// HandlerUsers handles /scim/v2/Users requests: GET lists the community's
+// users (optionally filtered by userName) and POST creates one. GET, PUT,
+// PATCH and DELETE on /scim/v2/Users/{id} read, replace, update and
+// deactivate a user.
+func (cfg *Config) HandlerUsers(w http.ResponseWriter, r *http.Request) {
+	if !cfg.authenticate(w, r) {
+		return
+	}
+
+	id := strings.Trim(handlers.ExtractIDFromPath(r.URL.Path, usersPrefix), "/")
+	if id == "" {
+		switch r.Method {
+		case http.MethodGet:
+			cfg.handlerList(w, r)
+		case http.MethodPost:
+			cfg.handlerCreate(w, r)
+		default:
+			respondError(w, http.StatusMethodNotAllowed, "", types.ErrMsgMethodNotAllowed, nil)
+		}
+		return
+	}
+
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		respondError(w, http.StatusNotFound, "", "User not found", nil)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		cfg.respondUser(w, r, http.StatusOK, userID)
+	case http.MethodPut:
+		cfg.handlerReplace(w, r, userID)
+	case http.MethodPatch:
+		cfg.handlerPatch(w, r, userID)
+	case http.MethodDelete:
+		cfg.handlerDelete(w, r, userID)
+	default:
+		respondError(w, http.StatusMethodNotAllowed, "", types.ErrMsgMethodNotAllowed, nil)
+	}
+}
+
+// authenticate checks the bearer token is a scim API key of the request's
+// community, responding 401 when it isn't
+func (cfg *Config) authenticate(w http.ResponseWriter, r *http.Request) bool {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondError(w, http.StatusUnauthorized, "", "Invalid provisioning token", err)
+		return false
+	}
+	key, err := cfg.DB.UseAPIKey(r.Context(), auth.HashAPIKey(token))
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			respondError(w, http.StatusUnauthorized, "", "Invalid provisioning token", nil)
+		} else {
+			respondError(w, http.StatusInternalServerError, "", "Couldn't check provisioning token", err)
+		}
+		return false
+	}
+	if key.TenantID != tenant.FromContext(r.Context()).ID || key.Tier != types.APIKeyTierSCIM {
+		respondError(w, http.StatusUnauthorized, "", "Invalid provisioning token", nil)
+		return false
+	}
+	return true
+}
+
+func (cfg *Config) handlerList(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	userName, err := parseFilter(query.Get("filter"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, errInvalidFilter, err.Error(), nil)
+		return
+	}
+
+	// Out of range paging values are clamped, as RFC 7644 asks
+	startIndex, count := 1, defaultCount
+	if value, err := strconv.Atoi(query.Get("startIndex")); err == nil && value > 1 {
+		startIndex = value
+	}
+	if value, err := strconv.Atoi(query.Get("count")); err == nil {
+		count = min(max(value, 0), maxCount)
+	}
+
+	tenantID := tenant.FromContext(r.Context()).ID
+	total, err := cfg.DB.CountSCIMUsers(r.Context(), database.CountSCIMUsersParams{
+		TenantID: tenantID,
+		UserName: userName,
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "", "Couldn't retrieve users", err)
+		return
+	}
+	rows, err := cfg.DB.ListSCIMUsers(r.Context(), database.ListSCIMUsersParams{
+		TenantID: tenantID,
+		UserName: userName,
+		Skip:     int32(startIndex - 1),
+		MaxUsers: int32(count),
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "", "Couldn't retrieve users", err)
+		return
+	}
+
+	base := handlers.RequestOrigin(r)
+	response := types.SCIMListResponse{
+		Schemas:      []string{schemaList},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(rows),
+		Resources:    make([]types.SCIMUser, len(rows)),
+	}
+	for i, row := range rows {
+		response.Resources[i] = buildUserResponse(database.GetSCIMUserRow(row), base)
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *Config) handlerCreate(w http.ResponseWriter, r *http.Request) {
+	var request types.SCIMUser
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, errInvalidSyntax, "Invalid user resource", err)
+		return
+	}
+	attrs, err := parseUser(request)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, errInvalidValue, err.Error(), nil)
+		return
+	}
+
+	userID, err := cfg.DB.CreateSCIMUser(r.Context(), database.CreateSCIMUserParams{
+		Email:          attrs.email,
+		HashedPassword: attrs.hashedPassword,
+		Username:       attrs.username,
+		TenantID:       tenant.FromContext(r.Context()).ID,
+		Active:         attrs.active,
+		ExternalID:     attrs.externalID,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			respondError(w, http.StatusConflict, errUniqueness, "userName or nickName is already taken", nil)
+		} else {
+			respondError(w, http.StatusInternalServerError, "", "Couldn't create user", err)
+		}
+		return
+	}
+
+	cfg.Events.Publish(events.Event{
+		Type:   events.UserCreated,
+		UserID: userID,
+	})
+	cfg.respondUser(w, r, http.StatusCreated, userID)
+}
+
+func (cfg *Config) handlerReplace(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	var request types.SCIMUser
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, errInvalidSyntax, "Invalid user resource", err)
+		return
+	}
+	attrs, err := parseUser(request)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, errInvalidValue, err.Error(), nil)
+		return
+	}
+	if cfg.saveUser(w, r, userID, attrs) {
+		cfg.respondUser(w, r, http.StatusOK, userID)
+	}
+}
+
+// handlerPatch applies add and replace operations. Attributes Chirpy doesn't
+// store, such as name and title, are accepted and ignored so providers can
+// send their default mappings.
+func (cfg *Config) handlerPatch(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	var request types.SCIMPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respondError(w, http.StatusBadRequest, errInvalidSyntax, "Invalid patch request", err)
+		return
+	}
+	user, ok := cfg.getUser(w, r, userID)
+	if !ok {
+		return
+	}
+
+	attrs := currentAttributes(user)
+	for _, op := range request.Operations {
+		if err := applyOperation(&attrs, op); err != nil {
+			respondError(w, http.StatusBadRequest, errInvalidValue, err.Error(), nil)
+			return
+		}
+	}
+	if cfg.saveUser(w, r, userID, attrs) {
+		cfg.respondUser(w, r, http.StatusOK, userID)
+	}
+}
+
+// handlerDelete deactivates the user. The account is kept, like any other
+// deactivated account, until the purge job deletes it.
+func (cfg *Config) handlerDelete(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	user, ok := cfg.getUser(w, r, userID)
+	if !ok {
+		return
+	}
+	attrs := currentAttributes(user)
+	attrs.active = false
+	if cfg.saveUser(w, r, userID, attrs) {
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// saveUser stores the user's new attributes, signing the user out when the
+// account is deactivated and raising user.suspended when it wasn't already
+func (cfg *Config) saveUser(w http.ResponseWriter, r *http.Request, userID uuid.UUID, attrs userAttributes) bool {
+	wasSuspended, err := cfg.DB.IsUserSuspended(r.Context(), userID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "", "Couldn't update user", err)
+		return false
+	}
+
+	_, err = cfg.DB.ReplaceSCIMUser(r.Context(), database.ReplaceSCIMUserParams{
+		Email:          attrs.email,
+		HashedPassword: attrs.hashedPassword,
+		Username:       attrs.username,
+		Active:         attrs.active,
+		ID:             userID,
+		TenantID:       tenant.FromContext(r.Context()).ID,
+		ExternalID:     attrs.externalID,
+	})
+	if err != nil {
+		switch {
+		case err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set":
+			respondError(w, http.StatusNotFound, "", "User not found", nil)
+		case strings.Contains(err.Error(), "duplicate key"):
+			respondError(w, http.StatusConflict, errUniqueness, "userName or nickName is already taken", err)
+		default:
+			respondError(w, http.StatusInternalServerError, "", "Couldn't update user", err)
+		}
+		return false
+	}
+
+	if !attrs.active {
+		if err := cfg.DB.RevokeUserRefreshTokens(r.Context(), userID); err != nil {
+			respondError(w, http.StatusInternalServerError, "", "Couldn't revoke sessions", err)
+			return false
+		}
+		if !wasSuspended {
+			cfg.Events.Publish(events.Event{
+				Type:     events.UserSuspended,
+				TenantID: tenant.FromContext(r.Context()).ID,
+				UserID:   userID,
+			})
+		}
+	}
+	return true
+}
+
+func (cfg *Config) getUser(w http.ResponseWriter, r *http.Request, userID uuid.UUID) (database.GetSCIMUserRow, bool) {
+	user, err := cfg.DB.GetSCIMUser(r.Context(), database.GetSCIMUserParams{
+		ID:       userID,
+		TenantID: tenant.FromContext(r.Context()).ID,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			respondError(w, http.StatusNotFound, "", "User not found", nil)
+		} else {
+			respondError(w, http.StatusInternalServerError, "", "Couldn't retrieve user", err)
+		}
+		return database.GetSCIMUserRow{}, false
+	}
+	return user, true
+}
+
+func (cfg *Config) respondUser(w http.ResponseWriter, r *http.Request, code int, userID uuid.UUID) {
+	user, ok := cfg.getUser(w, r, userID)
+	if !ok {
+		return
+	}
+	response := buildUserResponse(user, handlers.RequestOrigin(r))
+	w.Header().Set("Location", response.Meta.Location)
+	handlers.RespondWithJSON(w, code, response)
+}
+
+// parseFilter returns the userName a list filter asks for. An empty filter
+// lists everyone.
+func parseFilter(filter string) (sql.NullString, error) {
+	if filter == "" {
+		return sql.NullString{}, nil
+	}
+	match := filterPattern.FindStringSubmatch(filter)
+	if match == nil {
+		return sql.NullString{}, errors.New(`Only userName eq "value" filters are supported`)
+	}
+	var userName string
+	if err := json.Unmarshal([]byte(match[1]), &userName); err != nil {
+		return sql.NullString{}, errors.New("Invalid filter value")
+	}
+	return sql.NullString{String: userName, Valid: true}, nil
+}
+
+// parseUser validates a user resource sent to create or replace a user.
+// Users are active unless active is false.
+func parseUser(user types.SCIMUser) (userAttributes, error) {
+	attrs := userAttributes{
+		active:     user.Active == nil || *user.Active,
+		externalID: sql.NullString{String: user.ExternalID, Valid: user.ExternalID != ""},
+	}
+	if err := setAttribute(&attrs, "userName", user.UserName); err != nil {
+		return userAttributes{}, err
+	}
+	if user.NickName != "" {
+		if err := setAttribute(&attrs, "nickName", user.NickName); err != nil {
+			return userAttributes{}, err
+		}
+	}
+	if user.Password != "" {
+		if err := setAttribute(&attrs, "password", user.Password); err != nil {
+			return userAttributes{}, err
+		}
+	}
+	return attrs, nil
+}
+
+// currentAttributes are a stored user's attributes, for changes that start
+// from them
+func currentAttributes(user database.GetSCIMUserRow) userAttributes {
+	return userAttributes{
+		email:      user.Email,
+		active:     !user.DeactivatedAt.Valid,
+		externalID: user.ExternalID,
+	}
+}
+
+// applyOperation applies one patch operation. Without a path the value is
+// an object of attributes.
+func applyOperation(attrs *userAttributes, op types.SCIMPatchOperation) error {
+	if kind := strings.ToLower(op.Op); kind != "add" && kind != "replace" {
+		return errors.New("Only add and replace operations are supported")
+	}
+	if op.Path != "" {
+		return applyValue(attrs, op.Path, op.Value)
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(op.Value, &values); err != nil {
+		return errors.New("Operations without a path need an object value")
+	}
+	for path, value := range values {
+		if err := applyValue(attrs, path, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyValue sets one attribute from a patch value
+func applyValue(attrs *userAttributes, path string, value json.RawMessage) error {
+	switch strings.ToLower(path) {
+	case "active":
+		// Azure AD sends booleans as the strings "True" and "False"
+		var active any
+		if err := json.Unmarshal(value, &active); err != nil {
+			return errors.New("active must be a boolean")
+		}
+		switch active := active.(type) {
+		case bool:
+			attrs.active = active
+		case string:
+			parsed, err := strconv.ParseBool(active)
+			if err != nil {
+				return errors.New("active must be a boolean")
+			}
+			attrs.active = parsed
+		default:
+			return errors.New("active must be a boolean")
+		}
+		return nil
+	case "username", "nickname", "password", "externalid":
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return errors.New(path + " must be a string")
+		}
+		return setAttribute(attrs, path, s)
+	}
+	return nil
+}
+
+// setAttribute validates and sets one of the string attributes
+func setAttribute(attrs *userAttributes, attr, value string) error {
+	switch strings.ToLower(attr) {
+	case "username":
+		email := strings.TrimSpace(value)
+		if email == "" {
+			return errors.New("userName is required")
+		}
+		if err := validation.ValidateEmail(email); err != nil {
+			return errors.New("userName must be an email address")
+		}
+		attrs.email = email
+	case "nickname":
+		handle := validation.NormalizeHandle(value)
+		// The provider is trusted with reserved handles, as admins are
+		if err := validation.ValidateHandle(handle, nil); err != nil {
+			return err
+		}
+		attrs.username = sql.NullString{String: handle, Valid: true}
+	case "password":
+		hashedPassword, err := auth.HashPassword(value)
+		if err != nil {
+			return err
+		}
+		attrs.hashedPassword = sql.NullString{String: hashedPassword, Valid: true}
+	case "externalid":
+		attrs.externalID = sql.NullString{String: value, Valid: value != ""}
+	}
+	return nil
+}
+
+func buildUserResponse(user database.GetSCIMUserRow, base string) types.SCIMUser {
+	active := !user.DeactivatedAt.Valid
+	return types.SCIMUser{
+		Schemas:    []string{schemaUser},
+		ID:         user.ID,
+		ExternalID: user.ExternalID.String,
+		UserName:   user.Email,
+		NickName:   user.Username.String,
+		Emails:     []types.SCIMEmail{{Value: user.Email, Type: "work", Primary: true}},
+		Active:     &active,
+		Meta: &types.SCIMMeta{
+			ResourceType: "User",
+			Created:      types.NewTimestamp(user.CreatedAt),
+			LastModified: types.NewTimestamp(user.UpdatedAt),
+			Location:     base + usersPrefix + user.ID.String(),
+		},
+	}
+}
+
+// respondError sends an error in the SCIM format
+func respondError(w http.ResponseWriter, code int, scimType, detail string, err error) {
+	if err != nil {
+		log.Println(err)
+	}
+	handlers.RespondWithJSON(w, code, types.SCIMError{
+		Schemas:  []string{schemaError},
+		Status:   strconv.Itoa(code),
+		SCIMType: scimType,
+		Detail:   detail,
+	})
+}
@@ -0,0 +1,115 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		filter  string
+		want    string
+		wantSet bool
+		wantErr bool
+	}{
+		{filter: ""},
+		{filter: `userName eq "jane@example.com"`, want: "jane@example.com", wantSet: true},
+		{filter: `USERNAME Eq "jane@example.com" `, want: "jane@example.com", wantSet: true},
+		{filter: `userName eq "a\"b@example.com"`, want: `a"b@example.com`, wantSet: true},
+		{filter: `userName sw "jane"`, wantErr: true},
+		{filter: `emails.value eq "jane@example.com"`, wantErr: true},
+		{filter: `userName eq "a" or userName eq "b"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			got, err := parseFilter(tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFilter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.String != tt.want || got.Valid != tt.wantSet {
+				t.Errorf("parseFilter() = %+v, want %q (set %v)", got, tt.want, tt.wantSet)
+			}
+		})
+	}
+}
+
+func TestApplyOperation(t *testing.T) {
+	tests := []struct {
+		name       string
+		op         string
+		wantEmail  string
+		wantHandle string
+		wantActive bool
+		wantErr    bool
+	}{
+		{
+			name:       "okta deactivate",
+			op:         `{"op": "replace", "value": {"active": false}}`,
+			wantEmail:  "jane@example.com",
+			wantActive: false,
+		},
+		{
+			name:       "azure deactivate",
+			op:         `{"op": "Replace", "path": "active", "value": "False"}`,
+			wantEmail:  "jane@example.com",
+			wantActive: false,
+		},
+		{
+			name:       "rename",
+			op:         `{"op": "replace", "path": "userName", "value": "janet@example.com"}`,
+			wantEmail:  "janet@example.com",
+			wantActive: true,
+		},
+		{
+			name:       "set handle",
+			op:         `{"op": "add", "value": {"nickName": "Janet", "name": {"givenName": "Janet"}}}`,
+			wantEmail:  "jane@example.com",
+			wantHandle: "janet",
+			wantActive: true,
+		},
+		{
+			name:       "unstored attribute",
+			op:         `{"op": "replace", "path": "title", "value": "Engineer"}`,
+			wantEmail:  "jane@example.com",
+			wantActive: true,
+		},
+		{
+			name:    "invalid email",
+			op:      `{"op": "replace", "path": "userName", "value": "jane"}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid active",
+			op:      `{"op": "replace", "path": "active", "value": "maybe"}`,
+			wantErr: true,
+		},
+		{
+			name:    "remove",
+			op:      `{"op": "remove", "path": "nickName"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var op types.SCIMPatchOperation
+			if err := json.Unmarshal([]byte(tt.op), &op); err != nil {
+				t.Fatal(err)
+			}
+			attrs := userAttributes{email: "jane@example.com", active: true}
+			err := applyOperation(&attrs, op)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyOperation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if attrs.email != tt.wantEmail || attrs.username.String != tt.wantHandle || attrs.active != tt.wantActive {
+				t.Errorf("attributes = %+v, want email %q, handle %q, active %v", attrs, tt.wantEmail, tt.wantHandle, tt.wantActive)
+			}
+		})
+	}
+}
@@ -13,3 +13,107 @@ const (
 	ErrMsgRetrieveChirp    = "Couldn't retrieve chirp"
 	ErrMsgMethodNotAllowed = "Method not allowed"
 )
+
+const (
+	// Machine-readable error codes
+	ErrCodeHandleReserved       = "HANDLE_RESERVED"
+	ErrCodeHandleTaken          = "HANDLE_TAKEN"
+	ErrCodeEmailTaken           = "EMAIL_TAKEN"
+	ErrCodeRateLimited          = "RATE_LIMITED"
+	ErrCodeProbation            = "ACCOUNT_ON_PROBATION"
+	ErrCodeInsufficientScope    = "INSUFFICIENT_SCOPE"
+	ErrCodeTooManyMentions      = "TOO_MANY_MENTIONS"
+	ErrCodeTooManyHashtags      = "TOO_MANY_HASHTAGS"
+	ErrCodeThreadLocked         = "THREAD_LOCKED"
+	ErrCodeTimeout              = "TIMEOUT"
+	ErrCodeChirpTooLong         = "CHIRP_TOO_LONG"
+	ErrCodeIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+	ErrCodeIdempotencyKeyInUse  = "IDEMPOTENCY_KEY_IN_USE"
+	ErrCodeValidation           = "VALIDATION_FAILED"
+	ErrCodeOverloaded           = "OVERLOADED"
+	ErrCodeReauthRequired       = "REAUTHENTICATION_REQUIRED"
+)
+
+const (
+	// User roles
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+const (
+	// Registration modes
+	RegistrationOpen   = "open"
+	RegistrationClosed = "closed"
+)
+
+const (
+	// Ways a viewer can have sensitive chirps shown in listings
+	SensitiveHide = "hide"
+	SensitiveBlur = "blur"
+	SensitiveShow = "show"
+)
+
+const (
+	// Periods a recap can cover
+	RecapWeek  = "week"
+	RecapMonth = "month"
+	RecapYear  = "year"
+)
+
+const (
+	// Firehose API key tiers
+	APIKeyTierStandard = "standard"
+	APIKeyTierResearch = "research"
+
+	// APIKeyTierSCIM keys provision users over SCIM instead of reading the
+	// firehose
+	APIKeyTierSCIM = "scim"
+)
+
+const (
+	// Reasons a chirp can be reported for
+	ReportSpam           = "spam"
+	ReportHarassment     = "harassment"
+	ReportHate           = "hate"
+	ReportViolence       = "violence"
+	ReportSexual         = "sexual"
+	ReportMisinformation = "misinformation"
+	ReportOther          = "other"
+)
+
+const (
+	// Ways a moderator can resolve a report
+	ReportDismissed = "dismissed"
+	ReportLocked    = "locked"
+	ReportRemoved   = "removed"
+
+	// ReportReinstated marks the reports of a removed chirp whose author
+	// won an appeal
+	ReportReinstated = "reinstated"
+)
+
+const (
+	// Kinds of account fingerprints
+	FingerprintIP     = "ip"
+	FingerprintDevice = "device"
+)
+
+const (
+	// Ways a moderator can decide an appeal
+	AppealApproved = "approved"
+	AppealDenied   = "denied"
+)
+
+const (
+	// Ways a moderator can review a classifier verdict
+	VerdictUpheld     = "upheld"
+	VerdictOverturned = "overturned"
+)
+
+const (
+	// Events moderation webhooks can subscribe to
+	WebhookReportCreated = "report.created"
+	WebhookChirpRemoved  = "chirp.removed"
+	WebhookUserSuspended = "user.suspended"
+)
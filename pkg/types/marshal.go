@@ -0,0 +1,277 @@
+package types
+
+import (
+	"encoding/hex"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Chirp responses make up most timeline traffic, so they are encoded by hand
+// instead of through reflection. The output is byte-for-byte identical to
+// encoding/json; marshal_test.go compares the two, so a field added to these
+// types but not here fails the tests.
+
+// ChirpListResponse is the array returned by chirp list endpoints
+type ChirpListResponse []ChirpCreateResponse
+
+// MarshalJSON encodes the list without per-element reflection
+func (l ChirpListResponse) MarshalJSON() ([]byte, error) {
+	if l == nil {
+		return []byte("null"), nil
+	}
+	buf := make([]byte, 0, len(l)*chirpSizeHint+2)
+	buf = append(buf, '[')
+	for i := range l {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		var err error
+		if buf, err = l[i].appendJSON(buf); err != nil {
+			return nil, err
+		}
+	}
+	return append(buf, ']'), nil
+}
+
+// chirpSizeHint is a typical encoded chirp size used to presize buffers
+const chirpSizeHint = 384
+
+// MarshalJSON encodes the chirp without reflection
+func (c ChirpCreateResponse) MarshalJSON() ([]byte, error) {
+	return c.appendJSON(make([]byte, 0, chirpSizeHint))
+}
+
+func (c *ChirpCreateResponse) appendJSON(buf []byte) ([]byte, error) {
+	var err error
+	buf = append(buf, `{"id":`...)
+	buf = appendUUID(buf, c.ID)
+	buf = append(buf, `,"created_at":`...)
+	if buf, err = appendTime(buf, c.CreatedAt.Time); err != nil {
+		return nil, err
+	}
+	buf = append(buf, `,"updated_at":`...)
+	if buf, err = appendTime(buf, c.UpdatedAt.Time); err != nil {
+		return nil, err
+	}
+	buf = append(buf, `,"user_id":`...)
+	buf = appendUUID(buf, c.UserID)
+	if c.Author != nil {
+		buf = append(buf, `,"author":`...)
+		buf = c.Author.appendJSON(buf)
+	}
+	if c.Coauthor != nil {
+		buf = append(buf, `,"coauthor":`...)
+		buf = c.Coauthor.appendJSON(buf)
+	}
+	if c.ParentChirpID != nil {
+		buf = append(buf, `,"parent_chirp_id":`...)
+		buf = appendUUID(buf, *c.ParentChirpID)
+	}
+	if c.RepostOfChirpID != nil {
+		buf = append(buf, `,"repost_of_chirp_id":`...)
+		buf = appendUUID(buf, *c.RepostOfChirpID)
+	}
+	buf = append(buf, `,"body":`...)
+	buf = appendString(buf, c.Body)
+	buf = append(buf, `,"media":`...)
+	if c.Media == nil {
+		buf = append(buf, "null"...)
+	} else {
+		buf = append(buf, '[')
+		for i, media := range c.Media {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = append(buf, `{"id":`...)
+			buf = appendUUID(buf, media.ID)
+			buf = append(buf, `,"url":`...)
+			buf = appendString(buf, media.URL)
+			buf = append(buf, `,"alt_text":`...)
+			buf = appendString(buf, media.AltText)
+			buf = append(buf, '}')
+		}
+		buf = append(buf, ']')
+	}
+	if c.LinkPreview != nil {
+		buf = append(buf, `,"link_preview":`...)
+		buf = c.LinkPreview.appendJSON(buf)
+	}
+	if len(c.Reactions) > 0 {
+		buf = append(buf, `,"reactions":[`...)
+		for i, reaction := range c.Reactions {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = append(buf, `{"emoji":`...)
+			buf = appendString(buf, reaction.Emoji)
+			buf = append(buf, `,"count":`...)
+			buf = strconv.AppendInt(buf, reaction.Count, 10)
+			buf = append(buf, '}')
+		}
+		buf = append(buf, ']')
+	}
+	buf = append(buf, `,"like_count":`...)
+	buf = strconv.AppendInt(buf, c.LikeCount, 10)
+	buf = append(buf, `,"liked_by_me":`...)
+	buf = appendBool(buf, c.LikedByMe)
+	buf = append(buf, `,"reply_count":`...)
+	buf = strconv.AppendInt(buf, c.ReplyCount, 10)
+	buf = append(buf, `,"repost_count":`...)
+	buf = strconv.AppendInt(buf, c.RepostCount, 10)
+	buf = append(buf, `,"view_count":`...)
+	buf = strconv.AppendInt(buf, c.ViewCount, 10)
+	buf = append(buf, `,"sensitive":`...)
+	buf = appendBool(buf, c.Sensitive)
+	if c.ContentWarning != "" {
+		buf = append(buf, `,"content_warning":`...)
+		buf = appendString(buf, c.ContentWarning)
+	}
+	buf = append(buf, `,"locked":`...)
+	buf = appendBool(buf, c.Locked)
+	if c.Source != "" {
+		buf = append(buf, `,"source":`...)
+		buf = appendString(buf, c.Source)
+	}
+	if c.Language != "" {
+		buf = append(buf, `,"language":`...)
+		buf = appendString(buf, c.Language)
+	}
+	buf = append(buf, `,"published_at":`...)
+	if buf, err = appendTime(buf, c.PublishedAt.Time); err != nil {
+		return nil, err
+	}
+	buf = append(buf, `,"pending":`...)
+	buf = appendBool(buf, c.Pending)
+	if c.OriginalChirp != nil {
+		buf = append(buf, `,"original_chirp":`...)
+		if buf, err = c.OriginalChirp.appendJSON(buf); err != nil {
+			return nil, err
+		}
+	}
+	return append(buf, '}'), nil
+}
+
+func (a *ChirpAuthor) appendJSON(buf []byte) []byte {
+	buf = append(buf, `{"id":`...)
+	buf = appendUUID(buf, a.ID)
+	if a.Username != "" {
+		buf = append(buf, `,"username":`...)
+		buf = appendString(buf, a.Username)
+	}
+	buf = append(buf, `,"verified":`...)
+	buf = appendBool(buf, a.Verified)
+	return append(buf, '}')
+}
+
+func (p *LinkPreview) appendJSON(buf []byte) []byte {
+	buf = append(buf, `{"url":`...)
+	buf = appendString(buf, p.URL)
+	if p.Title != "" {
+		buf = append(buf, `,"title":`...)
+		buf = appendString(buf, p.Title)
+	}
+	if p.Description != "" {
+		buf = append(buf, `,"description":`...)
+		buf = appendString(buf, p.Description)
+	}
+	if p.Image != "" {
+		buf = append(buf, `,"image":`...)
+		buf = appendString(buf, p.Image)
+	}
+	return append(buf, '}')
+}
+
+// appendUUID appends the quoted canonical form of id
+func appendUUID(buf []byte, id uuid.UUID) []byte {
+	buf = append(buf, '"')
+	buf = hex.AppendEncode(buf, id[0:4])
+	buf = append(buf, '-')
+	buf = hex.AppendEncode(buf, id[4:6])
+	buf = append(buf, '-')
+	buf = hex.AppendEncode(buf, id[6:8])
+	buf = append(buf, '-')
+	buf = hex.AppendEncode(buf, id[8:10])
+	buf = append(buf, '-')
+	buf = hex.AppendEncode(buf, id[10:16])
+	return append(buf, '"')
+}
+
+// appendTime appends t in UTC as TimestampLayout, rejecting years that
+// RFC 3339 cannot represent the way time.Time.MarshalJSON does
+func appendTime(buf []byte, t time.Time) ([]byte, error) {
+	t = t.UTC()
+	if year := t.Year(); year < 0 || year > 9999 {
+		_, err := t.MarshalJSON()
+		return nil, err
+	}
+	buf = append(buf, '"')
+	buf = t.AppendFormat(buf, TimestampLayout)
+	return append(buf, '"'), nil
+}
+
+func appendBool(buf []byte, b bool) []byte {
+	if b {
+		return append(buf, "true"...)
+	}
+	return append(buf, "false"...)
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendString appends s as a JSON string, escaping exactly like
+// encoding/json: HTML-sensitive characters, control characters, U+2028 and
+// U+2029 are escaped and invalid UTF-8 is replaced with U+FFFD
+func appendString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch b {
+			case '\\', '"':
+				buf = append(buf, '\\', b)
+			case '\b':
+				buf = append(buf, '\\', 'b')
+			case '\f':
+				buf = append(buf, '\\', 'f')
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
@@ -0,0 +1,209 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// reflectChirp has the fields of ChirpCreateResponse without its MarshalJSON,
+// so json.Marshal falls back to reflection
+type reflectChirp ChirpCreateResponse
+
+var marshalStrings = []string{
+	"",
+	"plain chirp",
+	"<script>alert('x') && true</script>",
+	"quote \" and backslash \\",
+	"controls \b\f\n\r\t\x00\x1f\x7f",
+	"separators   ",
+	"invalid \xff\xfe utf-8",
+	"unicode é 日本語 🐦",
+}
+
+func sampleChirps() []ChirpCreateResponse {
+	when := time.Date(2025, 3, 4, 5, 6, 7, 890123456, time.UTC)
+	chirps := []ChirpCreateResponse{}
+	for i, text := range marshalStrings {
+		chirp := ChirpCreateResponse{
+			ID:          uuid.New(),
+			CreatedAt:   NewTimestamp(when),
+			UpdatedAt:   NewTimestamp(when.Add(time.Second).In(time.FixedZone("X", -5*3600))),
+			UserID:      uuid.New(),
+			Body:        text,
+			PublishedAt: NewTimestamp(when.Add(time.Minute)),
+			Pending:     i%2 == 0,
+			Sensitive:   i%3 == 1,
+		}
+		if chirp.Sensitive && i%2 == 0 {
+			chirp.ContentWarning = text
+		}
+		switch i % 3 {
+		case 0:
+			chirp.Media = []MediaAttachment{}
+			chirp.Reactions = []ReactionCount{}
+		case 1:
+			chirp.Media = []MediaAttachment{
+				{ID: uuid.New(), URL: "https://cdn.example.com/a.png?x=1&y=<2>", AltText: text},
+				{ID: uuid.New(), URL: "https://cdn.example.com/b.png"},
+			}
+			chirp.Author = &ChirpAuthor{ID: chirp.UserID, Username: "kai_xlr", Verified: true}
+			chirp.Reactions = []ReactionCount{{Emoji: "👍", Count: 12}, {Emoji: text, Count: 1}}
+			chirp.Language = "en"
+			chirp.LinkPreview = &LinkPreview{URL: "https://example.com/?a=1&b=<2>", Title: text, Image: "https://example.com/og.png"}
+			chirp.LikeCount = 7
+			chirp.LikedByMe = true
+			chirp.RepostCount = 2
+		case 2:
+			chirp.Author = &ChirpAuthor{ID: chirp.UserID}
+			chirp.Coauthor = &ChirpAuthor{ID: uuid.New(), Username: text}
+			chirp.Source = text
+			chirp.ParentChirpID = &chirp.Coauthor.ID
+			chirp.ReplyCount = 3
+			chirp.Locked = true
+			chirp.LinkPreview = &LinkPreview{URL: "https://example.com", Description: text}
+		}
+		if i%4 == 3 && len(chirps) > 0 {
+			original := chirps[len(chirps)-1]
+			chirp.RepostOfChirpID = &original.ID
+			chirp.OriginalChirp = &original
+		}
+		chirps = append(chirps, chirp)
+	}
+	return chirps
+}
+
+func TestChirpMarshalJSONMatchesReflection(t *testing.T) {
+	for _, chirp := range sampleChirps() {
+		got, err := json.Marshal(chirp)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		want, err := json.Marshal(reflectChirp(chirp))
+		if err != nil {
+			t.Fatalf("json.Marshal(reflect) error = %v", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("MarshalJSON() drifted from reflection\n got: %s\nwant: %s", got, want)
+		}
+	}
+}
+
+func TestChirpListMarshalJSONMatchesReflection(t *testing.T) {
+	chirps := sampleChirps()
+	reflected := make([]reflectChirp, len(chirps))
+	for i, chirp := range chirps {
+		reflected[i] = reflectChirp(chirp)
+	}
+
+	tests := []struct {
+		name string
+		list ChirpListResponse
+		want any
+	}{
+		{name: "nil", list: nil, want: []reflectChirp(nil)},
+		{name: "empty", list: ChirpListResponse{}, want: []reflectChirp{}},
+		{name: "populated", list: ChirpListResponse(chirps), want: reflected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.list)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			want, err := json.Marshal(tt.want)
+			if err != nil {
+				t.Fatalf("json.Marshal(reflect) error = %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("MarshalJSON() drifted from reflection\n got: %s\nwant: %s", got, want)
+			}
+		})
+	}
+}
+
+func TestTimestampJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		time time.Time
+		want string
+	}{
+		{"utc", time.Date(2025, 3, 4, 5, 6, 7, 890123456, time.UTC), `"2025-03-04T05:06:07.890Z"`},
+		{"whole seconds", time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC), `"2025-03-04T05:06:07.000Z"`},
+		{"offset", time.Date(2025, 3, 4, 0, 6, 7, 0, time.FixedZone("X", -5*3600)), `"2025-03-04T05:06:07.000Z"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(NewTimestamp(tt.time))
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("json.Marshal() = %s, want %s", got, tt.want)
+			}
+
+			var parsed Timestamp
+			if err := json.Unmarshal(got, &parsed); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if !parsed.Equal(tt.time.Truncate(time.Millisecond)) || parsed.Location() != time.UTC {
+				t.Errorf("json.Unmarshal() = %v, want %v in UTC", parsed.Time, tt.time)
+			}
+		})
+	}
+}
+
+func TestChirpMarshalJSONInvalidTime(t *testing.T) {
+	chirp := ChirpCreateResponse{CreatedAt: NewTimestamp(time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC))}
+	if _, err := json.Marshal(chirp); err == nil {
+		t.Error("json.Marshal() error = nil, want error for out of range year")
+	}
+}
+
+func benchmarkChirpList() ChirpListResponse {
+	when := time.Now()
+	list := make(ChirpListResponse, 100)
+	for i := range list {
+		list[i] = ChirpCreateResponse{
+			ID:          uuid.New(),
+			CreatedAt:   NewTimestamp(when),
+			UpdatedAt:   NewTimestamp(when),
+			UserID:      uuid.New(),
+			Author:      &ChirpAuthor{ID: uuid.New(), Username: "bench_user", Verified: true},
+			Body:        "Just setting up my chirpy, this is chirp body text",
+			Media:       []MediaAttachment{},
+			PublishedAt: NewTimestamp(when),
+		}
+	}
+	return list
+}
+
+func BenchmarkChirpListMarshal(b *testing.B) {
+	list := benchmarkChirpList()
+
+	b.Run("generated", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := json.Marshal(list); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("reflection", func(b *testing.B) {
+		reflected := make([]reflectChirp, len(list))
+		for i, chirp := range list {
+			reflected[i] = reflectChirp(chirp)
+		}
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := json.Marshal(reflected); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
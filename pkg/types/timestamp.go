@@ -0,0 +1,42 @@
+package types
+
+import (
+	"errors"
+	"time"
+)
+
+// TimestampLayout is how every timestamp in an API response is written:
+// RFC 3339 in UTC with exactly three fractional digits
+const TimestampLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// Timestamp is a time.Time that encodes as TimestampLayout in UTC, so clients
+// never see local offsets or a varying number of fractional digits
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp wraps t for use in a response
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{Time: t}
+}
+
+// MarshalJSON encodes t in UTC with millisecond precision
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return appendTime(make([]byte, 0, len(TimestampLayout)+2), t.Time)
+}
+
+// UnmarshalJSON accepts any RFC 3339 timestamp and converts it to UTC
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return errors.New("Timestamp.UnmarshalJSON: input is not a JSON string")
+	}
+	parsed, err := time.Parse(time.RFC3339, string(data[1:len(data)-1]))
+	if err != nil {
+		return err
+	}
+	t.Time = parsed.UTC()
+	return nil
+}
@@ -1,11 +1,20 @@
 package types
 
 import (
+	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Validator is implemented by request types with checks their validate
+// tags can't express, such as rules spanning several fields.
+// handlers.DecodeJSON calls Validate after the tags pass.
+type Validator interface {
+	Validate() error
+}
+
 // Chirp types
 type ChirpRequest struct {
 	Body string `json:"body"`
@@ -16,29 +25,270 @@ type ChirpResponse struct {
 }
 
 type ChirpCreateRequest struct {
-	Body string `json:"body"`
+	Body         string         `json:"body"`
+	Media        []MediaRequest `json:"media"`
+	DelaySeconds int32          `json:"delay_seconds"`
+	CoauthorID   *uuid.UUID     `json:"coauthor_id"`
+	Sensitive    bool           `json:"sensitive"`
+
+	// ContentWarning is shown in place of the chirp, and marks it as
+	// sensitive
+	ContentWarning string `json:"content_warning"`
+
+	// ParentChirpID makes the chirp a reply to that chirp
+	ParentChirpID *uuid.UUID `json:"parent_chirp_id"`
+
+	// ScheduledAt holds the chirp back until that time instead of
+	// publishing it now
+	ScheduledAt *time.Time `json:"scheduled_at"`
+}
+
+// ScheduledChirpUpdateRequest edits a scheduled chirp; omitted fields keep
+// their value
+type ScheduledChirpUpdateRequest struct {
+	Body        *string    `json:"body"`
+	Sensitive   *bool      `json:"sensitive"`
+	ScheduledAt *time.Time `json:"scheduled_at"`
+}
+
+// ScheduledChirpsResponse is a user's queue of scheduled chirps by day
+type ScheduledChirpsResponse struct {
+	TimeZone  string             `json:"time_zone"`
+	Days      []ScheduledDay     `json:"days"`
+	Conflicts []ScheduleConflict `json:"conflicts"`
+}
+
+// ScheduledDay holds the chirps scheduled on one date, soonest first
+type ScheduledDay struct {
+	Date   string                `json:"date"`
+	Chirps []ChirpCreateResponse `json:"chirps"`
+}
+
+// ScheduleConflict warns that many chirps are scheduled in the same minute
+type ScheduleConflict struct {
+	Minute   Timestamp   `json:"minute"`
+	ChirpIDs []uuid.UUID `json:"chirp_ids"`
+}
+
+// DraftRequest creates or replaces a draft; the fields match the chirp the
+// draft publishes as
+type DraftRequest struct {
+	Body          string     `json:"body"`
+	Sensitive     bool       `json:"sensitive"`
+	ParentChirpID *uuid.UUID `json:"parent_chirp_id"`
+}
+
+// Draft is an unpublished chirp only its author can see
+type Draft struct {
+	ID            uuid.UUID  `json:"id"`
+	CreatedAt     Timestamp  `json:"created_at"`
+	UpdatedAt     Timestamp  `json:"updated_at"`
+	Body          string     `json:"body"`
+	Sensitive     bool       `json:"sensitive"`
+	ParentChirpID *uuid.UUID `json:"parent_chirp_id,omitempty"`
 }
 
 type ChirpCreateResponse struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	UserID    uuid.UUID `json:"user_id"`
+	ID              uuid.UUID            `json:"id"`
+	CreatedAt       Timestamp            `json:"created_at"`
+	UpdatedAt       Timestamp            `json:"updated_at"`
+	UserID          uuid.UUID            `json:"user_id"`
+	Author          *ChirpAuthor         `json:"author,omitempty"`
+	Coauthor        *ChirpAuthor         `json:"coauthor,omitempty"`
+	ParentChirpID   *uuid.UUID           `json:"parent_chirp_id,omitempty"`
+	RepostOfChirpID *uuid.UUID           `json:"repost_of_chirp_id,omitempty"`
+	Body            string               `json:"body"`
+	Media           []MediaAttachment    `json:"media"`
+	LinkPreview     *LinkPreview         `json:"link_preview,omitempty"`
+	Reactions       []ReactionCount      `json:"reactions,omitempty"`
+	LikeCount       int64                `json:"like_count"`
+	LikedByMe       bool                 `json:"liked_by_me"`
+	ReplyCount      int64                `json:"reply_count"`
+	RepostCount     int64                `json:"repost_count"`
+	ViewCount       int64                `json:"view_count"`
+	Sensitive       bool                 `json:"sensitive"`
+	ContentWarning  string               `json:"content_warning,omitempty"`
+	Locked          bool                 `json:"locked"`
+	Source          string               `json:"source,omitempty"`
+	Language        string               `json:"language,omitempty"`
+	PublishedAt     Timestamp            `json:"published_at"`
+	Pending         bool                 `json:"pending"`
+	OriginalChirp   *ChirpCreateResponse `json:"original_chirp,omitempty"`
+}
+
+// ConversationResponse is a chirp with the thread around it: the chirp that
+// started the thread, the chirps between it and this one, and a page of the
+// replies below it
+type ConversationResponse struct {
+	// Root is the chirp itself when it isn't a reply, and null when the
+	// root was deleted or is hidden from the viewer
+	Root      *ChirpCreateResponse `json:"root"`
+	Ancestors ChirpListResponse    `json:"ancestors"`
+	Chirp     ChirpCreateResponse  `json:"chirp"`
+	Replies   []ConversationReply  `json:"replies"`
+}
+
+// ConversationReply is a reply in a conversation; depth is 1 for direct
+// replies to the conversation's chirp
+type ConversationReply struct {
+	Depth int                 `json:"depth"`
+	Chirp ChirpCreateResponse `json:"chirp"`
+}
+
+// ChirpAuthor is the public profile embedded in chirp responses
+type ChirpAuthor struct {
+	ID          uuid.UUID `json:"id"`
+	Username    string    `json:"username,omitempty"`
+	Verified    bool      `json:"verified"`
+	DisplayName string    `json:"display_name,omitempty"`
+	Bio         string    `json:"bio,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	Website     string    `json:"website,omitempty"`
+	AvatarURL   string    `json:"avatar_url,omitempty"`
+}
+
+// ChirpSensitiveRequest marks or unmarks a chirp as sensitive, optionally
+// with a content warning
+type ChirpSensitiveRequest struct {
+	Sensitive      bool   `json:"sensitive"`
+	ContentWarning string `json:"content_warning"`
+}
+
+type ChirpUpdateRequest struct {
+	Body string `json:"body"`
+}
+
+type ChirpRevision struct {
+	Revision  int32     `json:"revision"`
+	CreatedAt Timestamp `json:"created_at"`
+	Body      string    `json:"body"`
+}
+
+// CoauthorUpdateRequest accepts or declines a co-author invite
+type CoauthorUpdateRequest struct {
+	Status string `json:"status" validate:"required,oneof=accepted declined"`
+}
+
+// CoauthorInvite is a pending request to be named as a chirp's co-author
+type CoauthorInvite struct {
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	AuthorID  uuid.UUID `json:"author_id"`
 	Body      string    `json:"body"`
+	CreatedAt Timestamp `json:"created_at"`
+}
+
+// ReactionRequest adds or removes an emoji reaction
+type ReactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+// ReactionCount is how many users reacted to a chirp with one emoji
+type ReactionCount struct {
+	Emoji string `json:"emoji"`
+	Count int64  `json:"count"`
+}
+
+// Reaction is one user's emoji reaction to a chirp
+type Reaction struct {
+	Emoji     string      `json:"emoji"`
+	User      ChirpAuthor `json:"user"`
+	CreatedAt Timestamp   `json:"created_at"`
+}
+
+type ChirpHistoryResponse struct {
+	ChirpID   uuid.UUID       `json:"chirp_id"`
+	Revisions []ChirpRevision `json:"revisions"`
+}
+
+// ChirpRevisionDiff is a word-level diff between two versions of a chirp.
+// Unified marks removed words [-like this-] and added ones {+like this+};
+// Changes holds the same diff as spans whose op is "equal", "delete" or
+// "insert".
+type ChirpRevisionDiff struct {
+	ChirpID      uuid.UUID  `json:"chirp_id"`
+	FromRevision int32      `json:"from_revision"`
+	ToRevision   int32      `json:"to_revision"`
+	Unified      string     `json:"unified"`
+	Changes      []DiffSpan `json:"changes"`
+}
+
+// DiffSpan is a run of text kept, removed or added between two versions
+type DiffSpan struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// ChirpStats is the engagement a chirp has had, shown to its author
+type ChirpStats struct {
+	ChirpID       uuid.UUID `json:"chirp_id"`
+	ViewCount     int64     `json:"view_count"`
+	LikeCount     int64     `json:"like_count"`
+	ReplyCount    int64     `json:"reply_count"`
+	RepostCount   int64     `json:"repost_count"`
+	ReactionCount int64     `json:"reaction_count"`
+}
+
+// Media types
+type MediaRequest struct {
+	URL     string `json:"url"`
+	AltText string `json:"alt_text"`
+}
+
+type MediaAttachment struct {
+	ID      uuid.UUID `json:"id"`
+	URL     string    `json:"url"`
+	AltText string    `json:"alt_text"`
+}
+
+// LinkPreview is the page metadata shown for the first link in a chirp
+type LinkPreview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Image       string `json:"image,omitempty"`
 }
 
 // User types
 type UserRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+	Username string `json:"username"`
 }
 
 type User struct {
 	ID          uuid.UUID `json:"id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	CreatedAt   Timestamp `json:"created_at"`
+	UpdatedAt   Timestamp `json:"updated_at"`
 	Email       string    `json:"email"`
+	Username    string    `json:"username,omitempty"`
 	IsChirpyRed bool      `json:"is_chirpy_red"`
+	Verified    bool      `json:"verified"`
+	LegalHold   bool      `json:"legal_hold,omitempty"`
+	DisplayName string    `json:"display_name,omitempty"`
+	Bio         string    `json:"bio,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	Website     string    `json:"website,omitempty"`
+	AvatarURL   string    `json:"avatar_url,omitempty"`
+}
+
+// UserProfile is the public view of a user, without their email
+type UserProfile struct {
+	ID          uuid.UUID `json:"id"`
+	CreatedAt   Timestamp `json:"created_at"`
+	Username    string    `json:"username"`
+	Verified    bool      `json:"verified"`
+	DisplayName string    `json:"display_name,omitempty"`
+	Bio         string    `json:"bio,omitempty"`
+	Location    string    `json:"location,omitempty"`
+	Website     string    `json:"website,omitempty"`
+	AvatarURL   string    `json:"avatar_url,omitempty"`
+}
+
+// FollowListEntry is a user in a follower or following list, with when the
+// follow began
+type FollowListEntry struct {
+	UserProfile
+	FollowedAt Timestamp `json:"followed_at"`
 }
 
 type UserResponse struct {
@@ -46,29 +296,371 @@ type UserResponse struct {
 }
 
 type LoginRequest struct {
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// SSOTokenRequest redeems the one-time code a single sign-on callback hands
+// the client for a login response
+type SSOTokenRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// PasswordResetRequest sets a new password with an emailed reset token
+type PasswordResetRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// ChirpExport is one chirp in an author's export of their chirps
+type ChirpExport struct {
+	ID              uuid.UUID  `json:"id"`
+	CreatedAt       Timestamp  `json:"created_at"`
+	UpdatedAt       Timestamp  `json:"updated_at"`
+	PublishedAt     Timestamp  `json:"published_at"`
+	Body            string     `json:"body"`
+	Sensitive       bool       `json:"sensitive"`
+	ContentWarning  string     `json:"content_warning"`
+	Language        string     `json:"language"`
+	Source          string     `json:"source"`
+	ParentChirpID   *uuid.UUID `json:"parent_chirp_id"`
+	RepostOfChirpID *uuid.UUID `json:"repost_of_chirp_id"`
+	Archived        bool       `json:"archived"`
+}
+
+// AccountBundle is what moves with an account to another instance: its
+// profile, chirps and follows, without credentials. URIs identify the
+// account, its chirps and the accounts it follows on the instance they came
+// from. Destination is the URI of the account on the new instance, the only
+// one the bundle can be imported into.
+type AccountBundle struct {
+	Version     int                  `json:"version"`
+	Origin      string               `json:"origin"`
+	Account     string               `json:"account"`
+	Destination string               `json:"destination"`
+	ExportedAt  Timestamp            `json:"exported_at"`
+	Profile     AccountBundleProfile `json:"profile"`
+	Chirps      []AccountBundleChirp `json:"chirps"`
+	Follows     []string             `json:"follows,omitempty"`
+}
+
+// AccountBundleProfile is the public profile of a bundled account
+type AccountBundleProfile struct {
+	Username  string    `json:"username,omitempty"`
+	CreatedAt Timestamp `json:"created_at"`
+}
+
+// AccountBundleChirp is one published chirp of a bundled account
+type AccountBundleChirp struct {
+	URI            string    `json:"uri"`
+	CreatedAt      Timestamp `json:"created_at"`
+	Body           string    `json:"body"`
+	Sensitive      bool      `json:"sensitive"`
+	ContentWarning string    `json:"content_warning,omitempty"`
+	Language       string    `json:"language,omitempty"`
+}
+
+// SignedAccountBundle carries an AccountBundle, base64 encoded, with the
+// origin instance's Ed25519 signature of it
+type SignedAccountBundle struct {
+	Origin    string `json:"origin" validate:"required"`
+	Bundle    string `json:"bundle" validate:"required"`
+	Signature string `json:"signature" validate:"required"`
+}
+
+// AccountImportResponse reports what importing a bundle changed
+type AccountImportResponse struct {
+	ChirpsImported int64  `json:"chirps_imported"`
+	ChirpsSkipped  int    `json:"chirps_skipped"`
+	Username       string `json:"username,omitempty"`
+}
+
+// BatchResult is the outcome for one item of a batch request: the status
+// and error it would have got as a request of its own
+type BatchResult struct {
+	ID     string `json:"id"`
+	Status int    `json:"status"`
+	Code   string `json:"code,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BatchResponse reports every item of a batch request in the order they
+// were submitted
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+// ChirpBatchDeleteRequest lists chirps to delete at once, at most 100 of
+// them
+type ChirpBatchDeleteRequest struct {
+	ChirpIDs []string `json:"chirp_ids" validate:"required,max=100"`
+}
+
+// BulkUser is one account to provision through /admin/users/bulk
+type BulkUser struct {
 	Email    string `json:"email"`
-	Password string `json:"password"`
+	Username string `json:"username,omitempty"`
+}
+
+// BulkUserRequest is the JSON form of a bulk provisioning request
+type BulkUserRequest struct {
+	Users []BulkUser `json:"users"`
+}
+
+// Bulk provisioning job states
+const (
+	BulkJobPending = "pending"
+	BulkJobDone    = "done"
+)
+
+// Outcomes of provisioning one bulk row
+const (
+	BulkRowCreated = "created"
+	BulkRowExists  = "exists"
+	BulkRowInvalid = "invalid"
+	BulkRowFailed  = "failed"
+)
+
+// BulkUserResult is the outcome for one row of a bulk provisioning request.
+// Rows are numbered from 1 in the order they were submitted.
+type BulkUserResult struct {
+	Row    int        `json:"row"`
+	Email  string     `json:"email"`
+	Status string     `json:"status"`
+	UserID *uuid.UUID `json:"user_id,omitempty"`
+	Error  string     `json:"error,omitempty"`
+}
+
+// BulkUserReport tracks a bulk provisioning job
+type BulkUserReport struct {
+	ID        uuid.UUID        `json:"id"`
+	Status    string           `json:"status"`
+	CreatedAt Timestamp        `json:"created_at"`
+	Total     int              `json:"total"`
+	Results   []BulkUserResult `json:"results"`
 }
 
 type LoginResponse struct {
 	ID           uuid.UUID `json:"id"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	CreatedAt    Timestamp `json:"created_at"`
+	UpdatedAt    Timestamp `json:"updated_at"`
 	Email        string    `json:"email"`
+	Username     string    `json:"username,omitempty"`
 	IsChirpyRed  bool      `json:"is_chirpy_red"`
+	Verified     bool      `json:"verified"`
 	Token        string    `json:"token"`
 	RefreshToken string    `json:"refresh_token"`
 }
 
+// LinkedAccount is another account the user has linked, which the client
+// can switch to without signing in again
+type LinkedAccount struct {
+	ID       uuid.UUID `json:"id"`
+	Email    string    `json:"email"`
+	Username string    `json:"username,omitempty"`
+	Verified bool      `json:"verified"`
+	LinkedAt Timestamp `json:"linked_at"`
+}
+
 type RefreshResponse struct {
 	Token string `json:"token"`
 }
 
 type UserUpdateRequest struct {
-	Email    string `json:"email"`
+	Email       string  `json:"email" validate:"required,email"`
+	Password    string  `json:"password" validate:"required"`
+	Username    string  `json:"username"`
+	DisplayName *string `json:"display_name" validate:"max=50"`
+	Bio         *string `json:"bio" validate:"max=160"`
+	Location    *string `json:"location" validate:"max=30"`
+	Website     *string `json:"website" validate:"max=100,url"`
+	AvatarURL   *string `json:"avatar_url" validate:"max=500,url"`
+}
+
+// AccountDeletionRequest confirms the password of an account being deleted
+type AccountDeletionRequest struct {
 	Password string `json:"password"`
 }
 
+// AccountDeletion says when a deleted account will be purged
+type AccountDeletion struct {
+	DeleteAfter Timestamp `json:"delete_after"`
+}
+
+// UserPatchRequest changes only the fields it sets. Changing the password
+// needs the current one.
+type UserPatchRequest struct {
+	Email           *string `json:"email" validate:"notblank,email"`
+	Username        *string `json:"username"`
+	Password        *string `json:"password" validate:"notblank"`
+	CurrentPassword string  `json:"current_password"`
+	DisplayName     *string `json:"display_name" validate:"max=50"`
+	Bio             *string `json:"bio" validate:"max=160"`
+	Location        *string `json:"location" validate:"max=30"`
+	Website         *string `json:"website" validate:"max=100,url"`
+	AvatarURL       *string `json:"avatar_url" validate:"max=500,url"`
+}
+
+type MutedWordsRequest struct {
+	MutedWords []string `json:"muted_words"`
+}
+
+type MutedWordsResponse struct {
+	MutedWords []string `json:"muted_words"`
+}
+
+// BannedWords is the instance's list of words masked in chirp bodies
+type BannedWords struct {
+	Words []string `json:"words"`
+}
+
+// Kinds of exported word list
+const (
+	WordListBanned = "banned_words"
+	WordListMuted  = "muted_words"
+
+	// WordListVersion is the version of the export format
+	WordListVersion = 1
+)
+
+// WordList is a banned or muted word list exported for import elsewhere.
+// Kind keeps one kind of list from being imported as the other.
+type WordList struct {
+	Kind       string    `json:"kind"`
+	Version    int       `json:"version"`
+	ExportedAt Timestamp `json:"exported_at"`
+	Source     string    `json:"source,omitempty"`
+	Words      []string  `json:"words"`
+}
+
+// WordListImportResponse is the list after an import and how many words
+// the import added to it
+type WordListImportResponse struct {
+	Added int      `json:"added"`
+	Words []string `json:"words"`
+}
+
+// UserPreferences are per-user display settings. SensitiveContent is one of
+// "hide", "blur" or "show".
+type UserPreferences struct {
+	SensitiveContent string `json:"sensitive_content" validate:"required,oneof=hide blur show"`
+}
+
+// TrendingHashtag is a tag and how many recent chirps used it
+type TrendingHashtag struct {
+	Tag    string `json:"tag"`
+	Chirps int64  `json:"chirps"`
+}
+
+// Bootstrap is everything a client needs for its first screen: the user,
+// their preferences, how many co-author invites await them and the first
+// page of their feed
+type Bootstrap struct {
+	User                   User              `json:"user"`
+	Preferences            UserPreferences   `json:"preferences"`
+	PendingCoauthorInvites int64             `json:"pending_coauthor_invites"`
+	Feed                   ChirpListResponse `json:"feed"`
+}
+
+// Recap summarises a user's chirps over one completed week, month or year.
+// MostLikedChirpID is the chirp with the most reactions, if any had one.
+type Recap struct {
+	Period             string     `json:"period"`
+	PeriodStart        Timestamp  `json:"period_start"`
+	PeriodEnd          Timestamp  `json:"period_end"`
+	TotalChirps        int32      `json:"total_chirps"`
+	MostLikedChirpID   *uuid.UUID `json:"most_liked_chirp_id"`
+	MostLikedReactions int32      `json:"most_liked_reactions"`
+	TopHashtags        []string   `json:"top_hashtags"`
+}
+
+// Instance types
+type InstanceResponse struct {
+	Name             string           `json:"name"`
+	Description      string           `json:"description"`
+	Version          string           `json:"version"`
+	RegistrationMode string           `json:"registration_mode"`
+	Limits           InstanceLimits   `json:"limits"`
+	Features         InstanceFeatures `json:"features"`
+	Reactions        []string         `json:"reactions"`
+	MigrationKey     string           `json:"migration_key,omitempty"`
+}
+
+type InstanceLimits struct {
+	MaxChirpLength       int    `json:"max_chirp_length"`
+	ChirpLengthCounting  string `json:"chirp_length_counting"`
+	MaxMediaAttachments  int    `json:"max_media_attachments"`
+	MaxAltTextLength     int    `json:"max_alt_text_length"`
+	MaxChirpDelaySeconds int    `json:"max_chirp_delay_seconds"`
+	MaxMutedWords        int    `json:"max_muted_words"`
+	MinHandleLength      int    `json:"min_handle_length"`
+	MaxHandleLength      int    `json:"max_handle_length"`
+}
+
+type InstanceFeatures struct {
+	ChirpEdits     bool `json:"chirp_edits"`
+	RequireAltText bool `json:"require_alt_text"`
+	Media          bool `json:"media"`
+	MutedWords     bool `json:"muted_words"`
+	UndoSend       bool `json:"undo_send"`
+	Usernames      bool `json:"usernames"`
+	VerifiedBadges bool `json:"verified_badges"`
+	MultiTenant    bool `json:"multi_tenant"`
+	Reactions      bool `json:"reactions"`
+	Sensitive      bool `json:"sensitive"`
+	Likes          bool `json:"likes"`
+	SSO            bool `json:"sso"`
+	CustomDomains  bool `json:"custom_domains"`
+}
+
+// Admin types
+type DBAnalyzeResponse struct {
+	Queries []QueryPlan `json:"queries"`
+}
+
+type TenantRequest struct {
+	Slug        string `json:"slug"`
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description"`
+}
+
+type Tenant struct {
+	ID          uuid.UUID `json:"id"`
+	CreatedAt   Timestamp `json:"created_at"`
+	Slug        string    `json:"slug"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+}
+
+// CustomDomainRequest adds a custom domain. Tenant is the slug of the
+// community it serves; admins may set it, and it defaults to the default
+// community.
+type CustomDomainRequest struct {
+	Domain string `json:"domain"`
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// CustomDomain is a domain routed to a community, or to a single profile
+// when UserID is set. The domain is only routed once a TXT record named
+// RecordName holding RecordValue has been found.
+type CustomDomain struct {
+	Domain      string     `json:"domain"`
+	CreatedAt   Timestamp  `json:"created_at"`
+	TenantID    uuid.UUID  `json:"tenant_id"`
+	UserID      *uuid.UUID `json:"user_id,omitempty"`
+	RecordName  string     `json:"record_name"`
+	RecordValue string     `json:"record_value"`
+	VerifiedAt  *Timestamp `json:"verified_at"`
+	CheckedAt   *Timestamp `json:"checked_at"`
+}
+
+type QueryPlan struct {
+	Name      string          `json:"name"`
+	TotalCost float64         `json:"total_cost"`
+	Warnings  []string        `json:"warnings"`
+	Plan      json.RawMessage `json:"plan"`
+}
+
 // Webhook types
 type WebhookRequest struct {
 	Event string      `json:"event"`
@@ -78,3 +670,302 @@ type WebhookRequest struct {
 type WebhookData struct {
 	UserID uuid.UUID `json:"user_id"`
 }
+
+// OAuth types
+type OAuthClientRequest struct {
+	Name         string   `json:"name"`
+	RedirectURIs []string `json:"redirect_uris"`
+}
+
+// OAuthClient is a registered third-party app. ClientSecret is only
+// returned once, when the client is created.
+type OAuthClient struct {
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"client_secret,omitempty"`
+	CreatedAt    Timestamp `json:"created_at"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+}
+
+// OAuthGrant is a client the user has given access to their account
+type OAuthGrant struct {
+	ClientID     string    `json:"client_id"`
+	Name         string    `json:"name"`
+	AuthorizedAt Timestamp `json:"authorized_at"`
+}
+
+// ClientUsage counts the chirps posted from one app. ClientID is set for
+// registered OAuth clients and empty for apps labelled by User-Agent.
+type ClientUsage struct {
+	Source      string    `json:"source"`
+	ClientID    string    `json:"client_id,omitempty"`
+	Chirps      int64     `json:"chirps"`
+	Authors     int64     `json:"authors"`
+	LastChirpAt Timestamp `json:"last_chirp_at"`
+}
+
+// APIKeyRequest registers a firehose API key
+type APIKeyRequest struct {
+	Name string `json:"name" validate:"required"`
+	Tier string `json:"tier" validate:"oneof=standard research scim"`
+}
+
+// APIKey is a key for the public firehose with its usage so far. Key is
+// only returned once, when the key is created.
+type APIKey struct {
+	ID              uuid.UUID  `json:"id"`
+	Key             string     `json:"key,omitempty"`
+	Name            string     `json:"name"`
+	Tier            string     `json:"tier"`
+	CreatedAt       Timestamp  `json:"created_at"`
+	Requests        int64      `json:"requests"`
+	ChirpsDelivered int64      `json:"chirps_delivered"`
+	LastUsedAt      *Timestamp `json:"last_used_at"`
+	Revoked         bool       `json:"revoked"`
+}
+
+// ReportRequest flags a chirp for moderators
+type ReportRequest struct {
+	Reason  string `json:"reason" validate:"required,oneof=spam harassment hate violence sexual misinformation other"`
+	Details string `json:"details" validate:"max=1000"`
+}
+
+// ReportResolveRequest says how a moderator acted on a report
+type ReportResolveRequest struct {
+	Resolution string `json:"resolution" validate:"required,oneof=dismissed locked removed"`
+}
+
+// Report is a chirp flagged by a user. ChirpReportCount is the number of
+// reports on the chirp in the same state, open or resolved.
+type Report struct {
+	ID               uuid.UUID  `json:"id"`
+	CreatedAt        Timestamp  `json:"created_at"`
+	ChirpID          uuid.UUID  `json:"chirp_id"`
+	ChirpBody        string     `json:"chirp_body,omitempty"`
+	ChirpAuthorID    uuid.UUID  `json:"chirp_author_id"`
+	ChirpReportCount int64      `json:"chirp_report_count,omitempty"`
+	ReporterID       uuid.UUID  `json:"reporter_id"`
+	Reason           string     `json:"reason"`
+	Details          string     `json:"details,omitempty"`
+	ResolvedAt       *Timestamp `json:"resolved_at"`
+	ResolvedBy       *uuid.UUID `json:"resolved_by,omitempty"`
+	Resolution       string     `json:"resolution,omitempty"`
+}
+
+// AppealRequest asks moderators to reconsider the removal of one of the
+// user's chirps
+type AppealRequest struct {
+	ChirpID uuid.UUID `json:"chirp_id" validate:"required"`
+	Reason  string    `json:"reason" validate:"required,max=1000"`
+}
+
+// AppealDecisionRequest says how a moderator decided an appeal
+type AppealDecisionRequest struct {
+	Decision string `json:"decision" validate:"required,oneof=approved denied"`
+}
+
+// Appeal is a user's request to reverse the removal of their chirp
+type Appeal struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt Timestamp  `json:"created_at"`
+	ChirpID   uuid.UUID  `json:"chirp_id"`
+	ChirpBody string     `json:"chirp_body,omitempty"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Reason    string     `json:"reason"`
+	DecidedAt *Timestamp `json:"decided_at"`
+	DecidedBy *uuid.UUID `json:"decided_by,omitempty"`
+	Decision  string     `json:"decision,omitempty"`
+}
+
+// BanEvasionCluster is a suspended account and the accounts that signed in
+// with the same IP address or device
+type BanEvasionCluster struct {
+	SuspendedUserID uuid.UUID         `json:"suspended_user_id"`
+	Accounts        []BanEvasionMatch `json:"accounts"`
+}
+
+// BanEvasionMatch is an account sharing fingerprints with a suspended one.
+// Shared lists the kinds it shares, ip and device, and LastSeenAt is when
+// it last signed in with one of them.
+type BanEvasionMatch struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Username   string    `json:"username,omitempty"`
+	Shared     []string  `json:"shared"`
+	LastSeenAt Timestamp `json:"last_seen_at"`
+}
+
+// VerdictReviewRequest says whether a moderator agrees with a classifier
+// verdict
+type VerdictReviewRequest struct {
+	Outcome string `json:"outcome" validate:"required,oneof=upheld overturned"`
+}
+
+// ModerationVerdict is the classifier's verdict on a chirp, flag or remove,
+// and the moderator's review of it
+type ModerationVerdict struct {
+	ID            uuid.UUID  `json:"id"`
+	CreatedAt     Timestamp  `json:"created_at"`
+	ChirpID       uuid.UUID  `json:"chirp_id"`
+	ChirpBody     string     `json:"chirp_body,omitempty"`
+	ChirpAuthorID uuid.UUID  `json:"chirp_author_id"`
+	Verdict       string     `json:"verdict"`
+	Label         string     `json:"label,omitempty"`
+	Score         float64    `json:"score"`
+	ReviewedAt    *Timestamp `json:"reviewed_at"`
+	ReviewedBy    *uuid.UUID `json:"reviewed_by,omitempty"`
+	Outcome       string     `json:"outcome,omitempty"`
+}
+
+// ModerationWebhookRequest registers an endpoint for the community's
+// moderation events
+type ModerationWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,url"`
+	Events []string `json:"events" validate:"required,max=3"`
+}
+
+// Validate checks that every event is one webhooks can subscribe to
+func (r ModerationWebhookRequest) Validate() error {
+	for _, event := range r.Events {
+		if event != WebhookReportCreated && event != WebhookChirpRemoved && event != WebhookUserSuspended {
+			return errors.New("events must be report.created, chirp.removed or user.suspended")
+		}
+	}
+	return nil
+}
+
+// ModerationWebhook is an endpoint receiving moderation events. Secret signs
+// the deliveries and is only returned once, when the webhook is registered.
+type ModerationWebhook struct {
+	ID        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt Timestamp `json:"created_at"`
+}
+
+// ModerationWebhookEvent is the body of a moderation webhook delivery.
+// UserID is the reporter of a report.created event, the author of the
+// removed chirp of a chirp.removed one and the suspended account of a
+// user.suspended one, which has no ChirpID. ID stays the same across retries.
+type ModerationWebhookEvent struct {
+	ID         uuid.UUID  `json:"id"`
+	Event      string     `json:"event"`
+	OccurredAt Timestamp  `json:"occurred_at"`
+	ChirpID    *uuid.UUID `json:"chirp_id,omitempty"`
+	UserID     uuid.UUID  `json:"user_id"`
+}
+
+// SCIMUser is a user resource of the SCIM 2.0 API (RFC 7643). userName is
+// the account's email and nickName its handle. Password is only read.
+type SCIMUser struct {
+	Schemas    []string    `json:"schemas"`
+	ID         uuid.UUID   `json:"id"`
+	ExternalID string      `json:"externalId,omitempty"`
+	UserName   string      `json:"userName"`
+	NickName   string      `json:"nickName,omitempty"`
+	Emails     []SCIMEmail `json:"emails,omitempty"`
+	Active     *bool       `json:"active,omitempty"`
+	Password   string      `json:"password,omitempty"`
+	Meta       *SCIMMeta   `json:"meta,omitempty"`
+}
+
+// SCIMEmail is one of a SCIM user's email addresses
+type SCIMEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMMeta describes a SCIM resource
+type SCIMMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      Timestamp `json:"created"`
+	LastModified Timestamp `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+// SCIMListResponse is a page of SCIM resources; StartIndex is 1-based
+type SCIMListResponse struct {
+	Schemas      []string   `json:"schemas"`
+	TotalResults int64      `json:"totalResults"`
+	StartIndex   int        `json:"startIndex"`
+	ItemsPerPage int        `json:"itemsPerPage"`
+	Resources    []SCIMUser `json:"Resources"`
+}
+
+// SCIMPatchRequest changes attributes of a SCIM resource
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+// SCIMPatchOperation is one change of a SCIMPatchRequest. Without a path,
+// Value is an object of attributes to set.
+type SCIMPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// SCIMError is the SCIM error response body
+type SCIMError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	SCIMType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+}
+
+// ChirpTooLongError is the 400 response for a chirp body over the length
+// limit, with the length as the server counted it
+type ChirpTooLongError struct {
+	Error     string `json:"error"`
+	Code      string `json:"code"`
+	Length    int    `json:"length"`
+	MaxLength int    `json:"max_length"`
+}
+
+// ChirpValidation is the result of checking a chirp against the rules
+// applied when it is posted, without posting it
+type ChirpValidation struct {
+	Valid bool `json:"valid"`
+
+	// Body is the body as it would be stored, with banned words masked
+	Body      string   `json:"body"`
+	Length    int      `json:"length"`
+	MaxLength int      `json:"max_length"`
+	Counting  string   `json:"counting"`
+	Mentions  []string `json:"mentions"`
+	Hashtags  []string `json:"hashtags"`
+	Language  string   `json:"language,omitempty"`
+
+	Errors []ChirpValidationError `json:"errors"`
+}
+
+// ChirpValidationError is one rule a chirp breaks, with the error code the
+// create endpoint would respond with, if it has one
+type ChirpValidationError struct {
+	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
+}
+
+// Backup is a stored database dump listed by the admin API
+type Backup struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt Timestamp `json:"created_at"`
+}
+
+// OAuthTokenResponse is the RFC 6749 token endpoint response
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+}
+
+// OAuthError is the RFC 6749 error response used by the token endpoint
+type OAuthError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
@@ -2,11 +2,21 @@ package user
 
 import (
 	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"log"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
 	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/entitlements"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/oidc"
+	"github.com/kai-xlr/neo_chirpy/internal/profanity"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
 	"github.com/kai-xlr/neo_chirpy/pkg/types"
 	"github.com/kai-xlr/neo_chirpy/pkg/validation"
 )
@@ -15,45 +25,76 @@ import (
 type Config struct {
 	DB        *database.Queries
 	JWTSecret string
-}
+	Events    *events.Bus
 
-// validateLoginRequest checks if login request is valid
-func validateLoginRequest(req types.LoginRequest) error {
-	if req.Email == "" {
-		return validation.ErrEmailEmpty
-	}
-	if req.Password == "" {
-		return auth.ErrPasswordEmpty
-	}
-	return nil
-}
+	// ReservedHandles cannot be claimed at registration or handle change
+	ReservedHandles validation.ReservedHandles
 
-// validateUserCreationRequest checks if user creation request is valid
-func validateUserCreationRequest(req types.UserRequest) error {
-	if err := validation.ValidateEmail(req.Email); err != nil {
-		return err
-	}
-	if req.Password == "" {
-		return auth.ErrPasswordEmpty
-	}
-	return nil
+	// RegistrationMode is types.RegistrationOpen or types.RegistrationClosed
+	RegistrationMode string
+
+	// SSO signs users in through an OpenID Connect provider; nil disables
+	// single sign-on
+	SSO *oidc.Provider
+	// SSORedirectURL is the callback URL registered with the provider. When
+	// empty it is built from the host of each request.
+	SSORedirectURL string
+	// SSOJITProvisioning creates an account on first sign-in for identities
+	// that match no existing account
+	SSOJITProvisioning bool
+	// Store holds single sign-ons in progress
+	Store cache.Store
+
+	// MigrationKey signs exported account bundles; nil disables export
+	MigrationKey ed25519.PrivateKey
+	// PublicURL is the base URL this instance is reached at, used as the
+	// origin of exported bundles
+	PublicURL string
+	// MigrationKeys looks up the migration key another instance advertises
+	MigrationKeys func(ctx context.Context, origin string) (ed25519.PublicKey, error)
+	// Counting measures imported chirps against the length limit
+	Counting validation.Counting
+	// Profanity masks banned words in imported chirps; nil masks nothing
+	Profanity *profanity.Filter
+	// IndexImported stores the hashtags and mentions of imported chirps and
+	// queues them for moderation; nil skips it
+	IndexImported func(ctx context.Context, chirps []database.Chirp) error
+	// Entitlements keeps links out of chirps imported into accounts on
+	// probation
+	Entitlements entitlements.Policy
+
+	// CustomDomains lets users point a domain of their own at their profile
+	CustomDomains bool
+
+	// DeletionGracePeriod is how long an account whose owner deleted it can
+	// be restored by logging in before it is purged; zero uses
+	// DeactivationGracePeriod
+	DeletionGracePeriod time.Duration
+
+	// FingerprintSecret keys the hashes of the IP addresses and device IDs
+	// users sign in from; empty disables recording them
+	FingerprintSecret string
+	// FingerprintRetention is how long a fingerprint is kept after it was
+	// last seen
+	FingerprintRetention time.Duration
 }
 
-// validateUserUpdateRequest checks if user update request is valid
-func validateUserUpdateRequest(req types.UserUpdateRequest) error {
-	if err := validation.ValidateEmail(req.Email); err != nil {
-		return err
-	}
-	if strings.TrimSpace(req.Password) == "" {
-		return auth.ErrPasswordEmpty
+// optionalText trims an optional profile field. An omitted field is NULL so
+// the stored value is kept, while an empty one clears it.
+func optionalText(field *string) sql.NullString {
+	if field == nil {
+		return sql.NullString{}
 	}
-	return nil
+	return sql.NullString{String: strings.TrimSpace(*field), Valid: true}
 }
 
 // authenticateUser verifies user credentials and returns user if valid
 func (cfg *Config) authenticateUser(ctx context.Context, email, password string) (database.User, error) {
 	// Get user from database
-	user, err := cfg.DB.GetUserByEmail(ctx, email)
+	user, err := cfg.DB.GetUserByEmail(ctx, database.GetUserByEmailParams{
+		TenantID: tenant.FromContext(ctx).ID,
+		Email:    email,
+	})
 	if err != nil {
 		return database.User{}, auth.ErrInvalidCredentials
 	}
@@ -67,10 +108,11 @@ func (cfg *Config) authenticateUser(ctx context.Context, email, password string)
 	return user, nil
 }
 
-// createTokens creates both access and refresh tokens for a user
-func (cfg *Config) createTokens(ctx context.Context, user database.User) (string, string, error) {
+// createTokens creates both access and refresh tokens for a user. signedIn
+// marks the access token as issued at sign-in.
+func (cfg *Config) createTokens(ctx context.Context, user database.User, signedIn bool) (string, string, error) {
 	// Create access token (JWT) that expires in 1 hour
-	accessToken, err := auth.MakeJWT(user.ID, cfg.JWTSecret, time.Hour)
+	accessToken, err := cfg.makeAccessToken(ctx, user.ID, signedIn)
 	if err != nil {
 		return "", "", err
 	}
@@ -94,3 +136,47 @@ func (cfg *Config) createTokens(ctx context.Context, user database.User) (string
 
 	return accessToken, refreshTokenString, nil
 }
+
+// buildLoginResponse describes a signed-in user with their new tokens
+func buildLoginResponse(user database.User, accessToken, refreshToken string) types.LoginResponse {
+	return types.LoginResponse{
+		ID:           user.ID,
+		CreatedAt:    types.NewTimestamp(user.CreatedAt),
+		UpdatedAt:    types.NewTimestamp(user.UpdatedAt),
+		Email:        user.Email,
+		Username:     user.Username.String,
+		IsChirpyRed:  user.IsChirpyRed,
+		Verified:     user.Verified,
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+	}
+}
+
+// makeAccessToken creates a one hour access token accepted only by the
+// request's tenant. Tokens for the default community carry no tenant, so they
+// stay valid when multi-tenancy is switched on. signedIn records the sign-in
+// time, for tokens issued when the user proved who they are.
+func (cfg *Config) makeAccessToken(ctx context.Context, userID uuid.UUID, signedIn bool) (string, error) {
+	t := tenant.FromContext(ctx)
+	slug := t.Slug
+	if t.IsDefault() {
+		slug = ""
+	}
+	if signedIn {
+		return auth.MakeSignInJWT(userID, slug, cfg.JWTSecret, time.Hour)
+	}
+	return auth.MakeTenantJWT(userID, slug, cfg.JWTSecret, time.Hour)
+}
+
+// PurgeExpiredRefreshTokens deletes refresh tokens past their expiry, which
+// can no longer be used to obtain access tokens
+func (cfg *Config) PurgeExpiredRefreshTokens(ctx context.Context) error {
+	deleted, err := cfg.DB.DeleteExpiredRefreshTokens(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Printf("Deleted %d expired refresh tokens", deleted)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// HandlerCoauthorInvites handles GET /api/users/me/coauthor-invites requests,
+// listing the chirps the user has been asked to co-author, newest first
+func (cfg *Config) HandlerCoauthorInvites(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	dbInvites, err := cfg.DB.GetPendingCoauthorInvites(r.Context(), userID)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve invites", err)
+		return
+	}
+
+	invites := make([]types.CoauthorInvite, len(dbInvites))
+	for i, invite := range dbInvites {
+		invites[i] = types.CoauthorInvite{
+			ChirpID:   invite.ChirpID,
+			AuthorID:  invite.AuthorID,
+			Body:      invite.Body,
+			CreatedAt: types.NewTimestamp(invite.CreatedAt),
+		}
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, invites)
+}
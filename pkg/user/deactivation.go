@@ -0,0 +1,187 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// DeactivationGracePeriod is how long a deactivated account can be restored
+// by logging in again before it is permanently deleted
+const DeactivationGracePeriod = 30 * 24 * time.Hour
+
+// HandlerDeactivate handles POST /api/users/me/deactivate requests.
+// The account and its chirps are hidden and all sessions are revoked.
+func (cfg *Config) HandlerDeactivate(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	if err := cfg.DB.DeactivateUser(r.Context(), userID); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't deactivate account", err)
+		return
+	}
+
+	if err := cfg.DB.RevokeUserRefreshTokens(r.Context(), userID); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't revoke sessions", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// recentSignInWindow is how recently accounts without a password must have
+// signed in to delete themselves or set a password
+const recentSignInWindow = 5 * time.Minute
+
+// HandlerDeleteAccount handles DELETE /api/users/me requests, which must
+// confirm the account's password, or for accounts without one come from a
+// sign-in in the last few minutes. The account is deactivated and signed out
+// at once, and deleted with its chirps when the deletion grace period ends;
+// logging in before then cancels the deletion.
+func (cfg *Config) HandlerDeleteAccount(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodDelete) {
+		return
+	}
+
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	var params types.AccountDeletionRequest
+	if !handlers.DecodeJSON(w, r, &params) {
+		return
+	}
+
+	user, err := cfg.DB.GetUserByID(r.Context(), database.GetUserByIDParams{
+		TenantID: tenant.FromContext(r.Context()).ID,
+		ID:       userID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+		return
+	}
+	// Accounts created through single sign-on may have no password to
+	// confirm, so they confirm by having just signed in instead
+	if err := auth.VerifyPassword(params.Password, user.HashedPassword); errors.Is(err, auth.ErrPasswordNotSet) {
+		if !principal.SignedInWithin(recentSignInWindow) {
+			handlers.RespondWithErrorCode(w, http.StatusForbidden, types.ErrCodeReauthRequired, "Sign in again to delete this account", nil)
+			return
+		}
+	} else if err != nil {
+		handlers.RespondWithError(w, http.StatusForbidden, "Password is incorrect", err)
+		return
+	}
+
+	deletion, err := cfg.DB.ScheduleAccountDeletion(r.Context(), database.ScheduleAccountDeletionParams{
+		UserID:      userID,
+		DeleteAfter: time.Now().UTC().Add(cfg.deletionGracePeriod()),
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't delete account", err)
+		return
+	}
+
+	if err := cfg.DB.RevokeUserRefreshTokens(r.Context(), userID); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't revoke sessions", err)
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusAccepted, types.AccountDeletion{
+		DeleteAfter: types.NewTimestamp(deletion.DeleteAfter),
+	})
+}
+
+// deletionGracePeriod is how long a deleted account can still be restored,
+// the deactivation grace period unless configured
+func (cfg *Config) deletionGracePeriod() time.Duration {
+	if cfg.DeletionGracePeriod > 0 {
+		return cfg.DeletionGracePeriod
+	}
+	return DeactivationGracePeriod
+}
+
+// reactivateIfDeactivated restores a deactivated account on login, cancelling
+// its deletion if one was requested. Accounts past their grace period are
+// treated as gone until the purge job removes them, and accounts suspended
+// by an identity provider stay deactivated until it reactivates them.
+func (cfg *Config) reactivateIfDeactivated(ctx context.Context, user database.User) (database.User, error) {
+	if !user.DeactivatedAt.Valid {
+		return user, nil
+	}
+
+	deletion, err := cfg.DB.GetAccountDeletion(ctx, user.ID)
+	deletionRequested := err == nil
+	switch {
+	case deletionRequested:
+		if time.Now().After(deletion.DeleteAfter) {
+			return database.User{}, auth.ErrInvalidCredentials
+		}
+	case err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set":
+		if time.Since(user.DeactivatedAt.Time) > DeactivationGracePeriod {
+			return database.User{}, auth.ErrInvalidCredentials
+		}
+	default:
+		return database.User{}, err
+	}
+
+	suspended, err := cfg.DB.IsUserSuspended(ctx, user.ID)
+	if err != nil {
+		return database.User{}, err
+	}
+	if suspended {
+		return database.User{}, auth.ErrInvalidCredentials
+	}
+	if deletionRequested {
+		if err := cfg.DB.CancelAccountDeletion(ctx, user.ID); err != nil {
+			return database.User{}, err
+		}
+	}
+	return cfg.DB.ReactivateUser(ctx, user.ID)
+}
+
+// PurgeDeactivatedUsers permanently deletes accounts deactivated longer than
+// the grace period. Chirps and other user data are removed by cascade.
+func (cfg *Config) PurgeDeactivatedUsers(ctx context.Context) error {
+	deleted, err := cfg.DB.DeleteDeactivatedUsers(ctx, time.Now().UTC().Add(-DeactivationGracePeriod))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Printf("Permanently deleted %d deactivated accounts", deleted)
+	}
+	return nil
+}
+
+// PurgeDeletedAccounts permanently deletes accounts whose owners asked for
+// deletion once their grace period has passed. Chirps and other user data
+// are removed by cascade.
+func (cfg *Config) PurgeDeletedAccounts(ctx context.Context) error {
+	deleted, err := cfg.DB.DeleteScheduledAccounts(ctx)
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Printf("Permanently deleted %d accounts at their owners' request", deleted)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package user
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/domains"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// HandlerCustomDomain handles /api/users/me/domain requests. GET returns
+// the domain the user pointed at their profile, PUT replaces it and DELETE
+// removes it. A new domain is routed once its TXT record has been found.
+func (cfg *Config) HandlerCustomDomain(w http.ResponseWriter, r *http.Request) {
+	if !cfg.CustomDomains {
+		handlers.RespondWithError(w, http.StatusNotFound, "Custom domains are not enabled", nil)
+		return
+	}
+
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	switch r.Method {
+	case http.MethodGet:
+		cfg.handlerCustomDomainGet(w, r, userID)
+	case http.MethodPut:
+		cfg.handlerCustomDomainSet(w, r, userID)
+	case http.MethodDelete:
+		cfg.handlerCustomDomainDelete(w, r, userID)
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+	}
+}
+
+func (cfg *Config) handlerCustomDomainGet(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	domain, err := cfg.DB.GetUserCustomDomain(r.Context(), uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusNotFound, "No custom domain", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve custom domain", err)
+		}
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, handlers.BuildCustomDomainResponse(domain))
+}
+
+// handlerCustomDomainSet replaces the user's domain. Setting the domain the
+// user already has keeps its token and verification.
+func (cfg *Config) handlerCustomDomainSet(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	var request types.CustomDomainRequest
+	if !handlers.DecodeJSON(w, r, &request) {
+		return
+	}
+	name, err := domains.Normalize(request.Domain)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	owner := uuid.NullUUID{UUID: userID, Valid: true}
+	if current, err := cfg.DB.GetUserCustomDomain(r.Context(), owner); err == nil && current.Domain == name {
+		handlers.RespondWithJSON(w, http.StatusOK, handlers.BuildCustomDomainResponse(current))
+		return
+	}
+	if _, err := cfg.DB.DeleteUserCustomDomain(r.Context(), owner); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't set custom domain", err)
+		return
+	}
+
+	domain, err := cfg.DB.CreateCustomDomain(r.Context(), database.CreateCustomDomainParams{
+		Domain:            name,
+		TenantID:          tenant.FromContext(r.Context()).ID,
+		UserID:            owner,
+		VerificationToken: domains.NewToken(),
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "custom_domains_pkey") {
+			handlers.RespondWithError(w, http.StatusConflict, "Domain is already in use", err)
+			return
+		}
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't set custom domain", err)
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, handlers.BuildCustomDomainResponse(domain))
+}
+
+func (cfg *Config) handlerCustomDomainDelete(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	deleted, err := cfg.DB.DeleteUserCustomDomain(r.Context(), uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't remove custom domain", err)
+		return
+	}
+	if deleted == 0 {
+		handlers.RespondWithError(w, http.StatusNotFound, "No custom domain", nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
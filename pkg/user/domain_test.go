@@ -0,0 +1,50 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+)
+
+func TestHandlerCustomDomain(t *testing.T) {
+	cfg := &Config{JWTSecret: "secret", CustomDomains: true}
+	token, err := auth.MakeJWT(uuid.New(), cfg.JWTSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		disabled   bool
+		method     string
+		token      string
+		body       string
+		wantStatus int
+	}{
+		{name: "disabled", disabled: true, method: http.MethodGet, token: token, wantStatus: http.StatusNotFound},
+		{name: "no token", method: http.MethodGet, wantStatus: http.StatusUnauthorized},
+		{name: "wrong method", method: http.MethodPost, token: token, wantStatus: http.StatusMethodNotAllowed},
+		{name: "invalid domain", method: http.MethodPut, token: token, body: `{"domain":"https://me.example"}`, wantStatus: http.StatusBadRequest},
+		{name: "single label", method: http.MethodPut, token: token, body: `{"domain":"localhost"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := *cfg
+			cfg.CustomDomains = !tt.disabled
+			req := httptest.NewRequest(tt.method, "/api/users/me/domain", strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			cfg.HandlerCustomDomain(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package user
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// DeviceIDHeader carries the ID a client app generates once per install.
+// Clients that don't send it are fingerprinted by IP address alone.
+const DeviceIDHeader = "X-Device-ID"
+
+// recordFingerprints stores keyed hashes of the IP address and device ID a
+// user signed up or signed in from, for moderators looking for ban
+// evasion. The raw values are never stored. Failures are logged rather
+// than failing the sign-in.
+func (cfg *Config) recordFingerprints(r *http.Request, userID uuid.UUID) {
+	if cfg.FingerprintSecret == "" {
+		return
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	values := map[string]string{types.FingerprintIP: host}
+	if deviceID := r.Header.Get(DeviceIDHeader); deviceID != "" {
+		values[types.FingerprintDevice] = deviceID
+	}
+
+	for kind, value := range values {
+		if err := cfg.DB.RecordAccountFingerprint(r.Context(), database.RecordAccountFingerprintParams{
+			UserID: userID,
+			Kind:   kind,
+			Hash:   auth.HashFingerprint(value, cfg.FingerprintSecret),
+		}); err != nil {
+			log.Printf("Couldn't record %s fingerprint of user %s: %s", kind, userID, err)
+		}
+	}
+}
+
+// PurgeStaleFingerprints deletes fingerprints not seen within
+// FingerprintRetention, which runs as an hourly job
+func (cfg *Config) PurgeStaleFingerprints(ctx context.Context) error {
+	deleted, err := cfg.DB.PurgeStaleAccountFingerprints(ctx, time.Now().UTC().Add(-cfg.FingerprintRetention))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		log.Printf("Deleted %d stale account fingerprints", deleted)
+	}
+	return nil
+}
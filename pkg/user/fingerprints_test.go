@@ -0,0 +1,51 @@
+package user
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestRecordFingerprints(t *testing.T) {
+	userID := uuid.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/login", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	req.Header.Set(DeviceIDHeader, "install-1234")
+
+	t.Run("hashed", func(t *testing.T) {
+		recorded := map[string]string{}
+		cfg := &Config{
+			DB:                database.New(sql.OpenDB(userConnector{id: userID, fingerprints: recorded})),
+			FingerprintSecret: "fingerprint-secret",
+		}
+		cfg.recordFingerprints(req, userID)
+
+		want := map[string]string{
+			types.FingerprintIP:     auth.HashFingerprint("203.0.113.7", cfg.FingerprintSecret),
+			types.FingerprintDevice: auth.HashFingerprint("install-1234", cfg.FingerprintSecret),
+		}
+		if len(recorded) != len(want) {
+			t.Fatalf("recorded = %v, want %v", recorded, want)
+		}
+		for kind, hash := range want {
+			if recorded[kind] != hash {
+				t.Errorf("%s fingerprint = %q, want %q", kind, recorded[kind], hash)
+			}
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		recorded := map[string]string{}
+		cfg := &Config{DB: database.New(sql.OpenDB(userConnector{id: userID, fingerprints: recorded}))}
+		cfg.recordFingerprints(req, userID)
+		if len(recorded) != 0 {
+			t.Errorf("recorded %v without a secret", recorded)
+		}
+	})
+}
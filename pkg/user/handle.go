@@ -0,0 +1,86 @@
+package user
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+// parseHandle normalizes and validates an optional handle. An empty handle
+// is returned as NULL so the stored value is left unset or unchanged.
+func (cfg *Config) parseHandle(raw string) (sql.NullString, error) {
+	handle := validation.NormalizeHandle(raw)
+	if handle == "" {
+		return sql.NullString{}, nil
+	}
+	if err := validation.ValidateHandle(handle, cfg.ReservedHandles); err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: handle, Valid: true}, nil
+}
+
+// respondHandleError reports an invalid handle, tagging reserved handles
+// with a specific error code
+func respondHandleError(w http.ResponseWriter, err error) {
+	if err == validation.ErrHandleReserved {
+		handlers.RespondWithErrorCode(w, http.StatusBadRequest, types.ErrCodeHandleReserved, err.Error(), err)
+		return
+	}
+	handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+}
+
+// isHandleTaken reports whether a database error is a username conflict
+func isHandleTaken(err error) bool {
+	return strings.Contains(err.Error(), "users_tenant_username_key")
+}
+
+// isEmailTaken reports whether a database error is an email conflict
+func isEmailTaken(err error) bool {
+	return strings.Contains(err.Error(), "users_tenant_email_key")
+}
+
+// HandlerUserByUsername handles GET /api/users/by-username/{handle}
+// requests, returning the public profile of the active user with that
+// handle. A leading @ and letter case are ignored.
+func (cfg *Config) HandlerUserByUsername(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	handle := validation.NormalizeHandle(handlers.ExtractIDFromPath(r.URL.Path, "/api/users/by-username/"))
+	if err := validation.ValidateHandle(handle, nil); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	user, err := cfg.DB.GetUserByUsername(r.Context(), database.GetUserByUsernameParams{
+		TenantID: tenant.FromContext(r.Context()).ID,
+		Username: sql.NullString{String: handle, Valid: true},
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusNotFound, "User not found", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+		}
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, types.UserProfile{
+		ID:          user.ID,
+		CreatedAt:   types.NewTimestamp(user.CreatedAt),
+		Username:    user.Username.String,
+		Verified:    user.Verified,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		Location:    user.Location,
+		Website:     user.Website,
+		AvatarURL:   user.AvatarUrl,
+	})
+}
@@ -0,0 +1,31 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandlerUserByUsernameRejectsBadRequests(t *testing.T) {
+	cfg := &Config{}
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "wrong method", method: http.MethodPost, path: "/api/users/by-username/birdie", wantStatus: http.StatusMethodNotAllowed},
+		{name: "empty handle", method: http.MethodGet, path: "/api/users/by-username/", wantStatus: http.StatusBadRequest},
+		{name: "invalid characters", method: http.MethodGet, path: "/api/users/by-username/bird.ie", wantStatus: http.StatusBadRequest},
+		{name: "nested path", method: http.MethodGet, path: "/api/users/by-username/birdie/chirps", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			cfg.HandlerUserByUsername(rec, httptest.NewRequest(tt.method, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
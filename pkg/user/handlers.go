@@ -1,12 +1,14 @@
 package user
 
 import (
-	"encoding/json"
+	"database/sql"
+	"errors"
 	"net/http"
-	"time"
 
 	"github.com/kai-xlr/neo_chirpy/internal/auth"
 	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
 	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
 	"github.com/kai-xlr/neo_chirpy/pkg/types"
 )
@@ -18,6 +20,8 @@ func (cfg *Config) HandlerUsers(w http.ResponseWriter, r *http.Request) {
 		cfg.handlerUsersCreate(w, r)
 	case http.MethodPut:
 		cfg.handlerUsersUpdate(w, r)
+	case http.MethodPatch:
+		cfg.handlerUsersPatch(w, r)
 	default:
 		handlers.RespondWithError(w, http.StatusMethodNotAllowed, "Method not allowed", nil)
 	}
@@ -25,16 +29,20 @@ func (cfg *Config) HandlerUsers(w http.ResponseWriter, r *http.Request) {
 
 // handlerUsersCreate handles user registration requests
 func (cfg *Config) handlerUsersCreate(w http.ResponseWriter, r *http.Request) {
+	if cfg.RegistrationMode == types.RegistrationClosed {
+		handlers.RespondWithError(w, http.StatusForbidden, "Registration is closed", nil)
+		return
+	}
+
 	// Parse request body
 	var params types.UserRequest
-	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
-		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+	if !handlers.DecodeJSON(w, r, &params) {
 		return
 	}
 
-	// Validate input
-	if err := validateUserCreationRequest(params); err != nil {
-		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+	username, err := cfg.parseHandle(params.Username)
+	if err != nil {
+		respondHandleError(w, err)
 		return
 	}
 
@@ -49,22 +57,26 @@ func (cfg *Config) handlerUsersCreate(w http.ResponseWriter, r *http.Request) {
 	user, err := cfg.DB.CreateUserWithPassword(r.Context(), database.CreateUserWithPasswordParams{
 		Email:          params.Email,
 		HashedPassword: hashedPassword,
+		Username:       username,
+		TenantID:       tenant.FromContext(r.Context()).ID,
 	})
 	if err != nil {
+		if isHandleTaken(err) {
+			handlers.RespondWithErrorCode(w, http.StatusConflict, types.ErrCodeHandleTaken, "Handle is already taken", err)
+			return
+		}
 		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't create user", err)
 		return
 	}
 
-	// Return user response (excluding sensitive data)
-	handlers.RespondWithJSON(w, http.StatusCreated, types.UserResponse{
-		User: types.User{
-			ID:          user.ID,
-			CreatedAt:   user.CreatedAt,
-			UpdatedAt:   user.UpdatedAt,
-			Email:       user.Email,
-			IsChirpyRed: user.IsChirpyRed,
-		},
+	cfg.Events.Publish(events.Event{
+		Type:   events.UserCreated,
+		UserID: user.ID,
 	})
+	cfg.recordFingerprints(r, user.ID)
+
+	// Return user response (excluding sensitive data)
+	handlers.RespondWithJSON(w, http.StatusCreated, buildUserResponse(user))
 }
 
 // HandlerLogin handles user authentication requests
@@ -75,14 +87,7 @@ func (cfg *Config) HandlerLogin(w http.ResponseWriter, r *http.Request) {
 
 	// Parse request body
 	var params types.LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
-		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
-		return
-	}
-
-	// Validate input
-	if err := validateLoginRequest(params); err != nil {
-		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+	if !handlers.DecodeJSON(w, r, &params) {
 		return
 	}
 
@@ -93,23 +98,28 @@ func (cfg *Config) HandlerLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Logging in within the grace period restores a deactivated account
+	user, err = cfg.reactivateIfDeactivated(r.Context(), user)
+	if err == auth.ErrInvalidCredentials {
+		handlers.RespondWithError(w, http.StatusUnauthorized, auth.ErrInvalidCredentials.Error(), err)
+		return
+	}
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't reactivate account", err)
+		return
+	}
+
 	// Create tokens
-	accessToken, refreshTokenString, err := cfg.createTokens(r.Context(), user)
+	accessToken, refreshTokenString, err := cfg.createTokens(r.Context(), user, true)
 	if err != nil {
 		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't create tokens", err)
 		return
 	}
 
+	cfg.recordFingerprints(r, user.ID)
+
 	// Return authentication response with both tokens
-	handlers.RespondWithJSON(w, http.StatusOK, types.LoginResponse{
-		ID:           user.ID,
-		CreatedAt:    user.CreatedAt,
-		UpdatedAt:    user.UpdatedAt,
-		Email:        user.Email,
-		IsChirpyRed:  user.IsChirpyRed,
-		Token:        accessToken,
-		RefreshToken: refreshTokenString,
-	})
+	handlers.RespondWithJSON(w, http.StatusOK, buildLoginResponse(user, accessToken, refreshTokenString))
 }
 
 // HandlerRefresh handles POST /api/refresh requests
@@ -126,14 +136,17 @@ func (cfg *Config) HandlerRefresh(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get user from refresh token (validates token exists, not expired, not revoked)
-	user, err := cfg.DB.GetUserFromRefreshToken(r.Context(), refreshTokenString)
+	user, err := cfg.DB.GetUserFromRefreshToken(r.Context(), database.GetUserFromRefreshTokenParams{
+		Token:    refreshTokenString,
+		TenantID: tenant.FromContext(r.Context()).ID,
+	})
 	if err != nil {
 		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid or expired refresh token", err)
 		return
 	}
 
 	// Create new access token that expires in 1 hour
-	accessToken, err := auth.MakeJWT(user.ID, cfg.JWTSecret, time.Hour)
+	accessToken, err := cfg.makeAccessToken(r.Context(), user.ID, false)
 	if err != nil {
 		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't create access token", err)
 		return
@@ -176,28 +189,23 @@ func (cfg *Config) handlerUsersUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Extract and validate JWT token
-	tokenString, err := auth.GetBearerToken(r.Header)
-	if err != nil {
-		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
-		return
-	}
-
-	userID, err := auth.ValidateJWT(tokenString, cfg.JWTSecret)
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
 	if err != nil {
 		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
 		return
 	}
+	userID := principal.UserID
 
 	// Parse request body
 	var params types.UserUpdateRequest
-	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
-		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+	if !handlers.DecodeJSON(w, r, &params) {
 		return
 	}
 
-	// Validate input
-	if err := validateUserUpdateRequest(params); err != nil {
-		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+	// An omitted username keeps the current handle
+	username, err := cfg.parseHandle(params.Username)
+	if err != nil {
+		respondHandleError(w, err)
 		return
 	}
 
@@ -211,22 +219,132 @@ func (cfg *Config) handlerUsersUpdate(w http.ResponseWriter, r *http.Request) {
 	// Update user in database
 	updatedUser, err := cfg.DB.UpdateUser(r.Context(), database.UpdateUserParams{
 		ID:             userID,
-		Email:          params.Email,
-		HashedPassword: hashedPassword,
+		Email:          sql.NullString{String: params.Email, Valid: true},
+		HashedPassword: sql.NullString{String: hashedPassword, Valid: true},
+		Username:       username,
+		DisplayName:    optionalText(params.DisplayName),
+		Bio:            optionalText(params.Bio),
+		Location:       optionalText(params.Location),
+		Website:        optionalText(params.Website),
+		AvatarUrl:      optionalText(params.AvatarURL),
 	})
 	if err != nil {
-		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update user", err)
+		respondUpdateError(w, err)
 		return
 	}
 
 	// Return updated user response (excluding sensitive data)
-	handlers.RespondWithJSON(w, http.StatusOK, types.UserResponse{
+	handlers.RespondWithJSON(w, http.StatusOK, buildUserResponse(updatedUser))
+}
+
+// handlerUsersPatch handles PATCH /api/users requests, which change only the
+// fields given. A new password must come with the current one, or a recent
+// sign-in if the account has none yet, and signs out every session's
+// refresh token.
+func (cfg *Config) handlerUsersPatch(w http.ResponseWriter, r *http.Request) {
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	var params types.UserPatchRequest
+	if !handlers.DecodeJSON(w, r, &params) {
+		return
+	}
+
+	update := database.UpdateUserParams{
+		ID:          userID,
+		DisplayName: optionalText(params.DisplayName),
+		Bio:         optionalText(params.Bio),
+		Location:    optionalText(params.Location),
+		Website:     optionalText(params.Website),
+		AvatarUrl:   optionalText(params.AvatarURL),
+	}
+	if params.Email != nil {
+		update.Email = sql.NullString{String: *params.Email, Valid: true}
+	}
+	if params.Username != nil {
+		if update.Username, err = cfg.parseHandle(*params.Username); err != nil {
+			respondHandleError(w, err)
+			return
+		}
+	}
+	if params.Password != nil {
+		user, err := cfg.DB.GetUserByID(r.Context(), database.GetUserByIDParams{
+			TenantID: tenant.FromContext(r.Context()).ID,
+			ID:       userID,
+		})
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve user", err)
+			return
+		}
+		// Accounts without a password confirm by having just signed in,
+		// as they do to delete the account
+		if err := auth.VerifyPassword(params.CurrentPassword, user.HashedPassword); errors.Is(err, auth.ErrPasswordNotSet) {
+			if !principal.SignedInWithin(recentSignInWindow) {
+				handlers.RespondWithErrorCode(w, http.StatusForbidden, types.ErrCodeReauthRequired, "Sign in again to set a password", nil)
+				return
+			}
+		} else if err != nil {
+			handlers.RespondWithError(w, http.StatusForbidden, "Current password is incorrect", err)
+			return
+		}
+
+		hashedPassword, err := auth.HashPassword(*params.Password)
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't hash password", err)
+			return
+		}
+		update.HashedPassword = sql.NullString{String: hashedPassword, Valid: true}
+	}
+
+	updatedUser, err := cfg.DB.UpdateUser(r.Context(), update)
+	if err != nil {
+		respondUpdateError(w, err)
+		return
+	}
+	if update.HashedPassword.Valid {
+		if err := cfg.DB.RevokeUserRefreshTokens(r.Context(), userID); err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't sign out other sessions", err)
+			return
+		}
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, buildUserResponse(updatedUser))
+}
+
+// respondUpdateError reports a failed user update, telling which unique
+// field is already taken
+func respondUpdateError(w http.ResponseWriter, err error) {
+	switch {
+	case isHandleTaken(err):
+		handlers.RespondWithErrorCode(w, http.StatusConflict, types.ErrCodeHandleTaken, "Handle is already taken", err)
+	case isEmailTaken(err):
+		handlers.RespondWithErrorCode(w, http.StatusConflict, types.ErrCodeEmailTaken, "Email is already in use", err)
+	default:
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update user", err)
+	}
+}
+
+// buildUserResponse converts a user for responses to the user themselves,
+// without sensitive data
+func buildUserResponse(user database.User) types.UserResponse {
+	return types.UserResponse{
 		User: types.User{
-			ID:          updatedUser.ID,
-			CreatedAt:   updatedUser.CreatedAt,
-			UpdatedAt:   updatedUser.UpdatedAt,
-			Email:       updatedUser.Email,
-			IsChirpyRed: updatedUser.IsChirpyRed,
+			ID:          user.ID,
+			CreatedAt:   types.NewTimestamp(user.CreatedAt),
+			UpdatedAt:   types.NewTimestamp(user.UpdatedAt),
+			Email:       user.Email,
+			Username:    user.Username.String,
+			IsChirpyRed: user.IsChirpyRed,
+			Verified:    user.Verified,
+			DisplayName: user.DisplayName,
+			Bio:         user.Bio,
+			Location:    user.Location,
+			Website:     user.Website,
+			AvatarURL:   user.AvatarUrl,
 		},
-	})
+	}
 }
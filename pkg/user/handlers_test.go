@@ -0,0 +1,207 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestHandlerUsersPatchRejectsBadRequests(t *testing.T) {
+	cfg := &Config{JWTSecret: "secret"}
+	token, err := auth.MakeJWT(uuid.New(), cfg.JWTSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		body       string
+		wantStatus int
+	}{
+		{name: "no token", body: `{"email":"new@example.com"}`, wantStatus: http.StatusUnauthorized},
+		{name: "invalid email", token: token, body: `{"email":"not-an-email"}`, wantStatus: http.StatusBadRequest},
+		{name: "empty password", token: token, body: `{"password":"  ","current_password":"old"}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid handle", token: token, body: `{"username":"bird.ie"}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid website", token: token, body: `{"website":"javascript:alert(1)"}`, wantStatus: http.StatusBadRequest},
+		{name: "bio too long", token: token, body: `{"bio":"` + strings.Repeat("a", 161) + `"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/api/users", strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			cfg.HandlerUsers(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+func TestHandlerUsersPatchPassword(t *testing.T) {
+	hashed, err := auth.HashPassword("old-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	userID := uuid.New()
+	signedIn, err := auth.MakeSignInJWT(userID, "", "secret", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshed, err := auth.MakeJWT(userID, "secret", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		hashed     string
+		token      string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "current password", hashed: hashed, token: refreshed, body: `{"password":"new-password","current_password":"old-password"}`, wantStatus: http.StatusOK},
+		{name: "wrong current password", hashed: hashed, token: signedIn, body: `{"password":"new-password","current_password":"guess"}`, wantStatus: http.StatusForbidden},
+		{name: "no password, recent sign-in", token: signedIn, body: `{"password":"new-password"}`, wantStatus: http.StatusOK},
+		{name: "no password, stale token", token: refreshed, body: `{"password":"new-password"}`, wantStatus: http.StatusForbidden, wantCode: types.ErrCodeReauthRequired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{JWTSecret: "secret", DB: database.New(sql.OpenDB(userConnector{id: userID, hashedPassword: tt.hashed}))}
+			req := httptest.NewRequest(http.MethodPatch, "/api/users", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			cfg.HandlerUsers(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			var body struct {
+				Code string `json:"code"`
+			}
+			json.Unmarshal(rec.Body.Bytes(), &body)
+			if body.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestHandlerDeleteAccountRejectsBadRequests(t *testing.T) {
+	cfg := &Config{JWTSecret: "secret"}
+	token, err := auth.MakeJWT(uuid.New(), cfg.JWTSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		token      string
+		body       string
+		wantStatus int
+	}{
+		{name: "wrong method", method: http.MethodPost, token: token, body: `{}`, wantStatus: http.StatusMethodNotAllowed},
+		{name: "no token", method: http.MethodDelete, body: `{"password":"secret"}`, wantStatus: http.StatusUnauthorized},
+		{name: "malformed body", method: http.MethodDelete, token: token, body: `{"password":`, wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/users/me", strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			cfg.HandlerDeleteAccount(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+// userConnector is a database/sql connector serving a single user, enough
+// for handlers that look the user up and update them. Fingerprints are
+// recorded in fingerprints, when set, by kind.
+type userConnector struct {
+	id             uuid.UUID
+	hashedPassword string
+	fingerprints   map[string]string
+}
+
+func (c userConnector) Connect(context.Context) (driver.Conn, error) { return userConn(c), nil }
+
+func (c userConnector) Driver() driver.Driver { return nil }
+
+type userConn userConnector
+
+func (c userConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("user driver does not support prepared statements")
+}
+
+func (c userConn) Close() error { return nil }
+
+func (c userConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("user driver does not support transactions")
+}
+
+// QueryContext answers GetUserByID and UpdateUser with the user
+func (c userConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if !strings.HasPrefix(query, "-- name: GetUserByID ") && !strings.HasPrefix(query, "-- name: UpdateUser ") {
+		return nil, errors.New("user driver: unexpected query " + query)
+	}
+	now := time.Now()
+	return &userRows{values: []driver.Value{
+		c.id.String(), now, now, "user@example.com", c.hashedPassword, false, "user", nil, nil, true,
+		tenant.DefaultID.String(), false, "", "", "", "", "",
+	}}, nil
+}
+
+// ExecContext accepts RevokeUserRefreshTokens and RecordAccountFingerprint
+func (c userConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	switch {
+	case strings.HasPrefix(query, "-- name: RevokeUserRefreshTokens "):
+	case strings.HasPrefix(query, "-- name: RecordAccountFingerprint ") && c.fingerprints != nil:
+		c.fingerprints[args[1].Value.(string)] = args[2].Value.(string)
+	default:
+		return nil, errors.New("user driver: unexpected statement " + query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+type userRows struct {
+	values []driver.Value
+}
+
+func (r *userRows) Columns() []string {
+	return []string{"id", "created_at", "updated_at", "email", "hashed_password", "is_chirpy_red", "role", "deactivated_at", "username", "verified",
+		"tenant_id", "legal_hold", "display_name", "bio", "location", "website", "avatar_url"}
+}
+
+func (r *userRows) Close() error { return nil }
+
+func (r *userRows) Next(dest []driver.Value) error {
+	if r.values == nil {
+		return io.EOF
+	}
+	copy(dest, r.values)
+	r.values = nil
+	return nil
+}
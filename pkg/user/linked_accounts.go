@@ -0,0 +1,190 @@
+package user
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// MaxLinkedAccounts caps how many other accounts one account can link
+const MaxLinkedAccounts = 5
+
+// HandlerLinkedAccounts dispatches /api/users/me/linked-accounts requests.
+// Clients holding sessions for several accounts link them once, proving
+// they own each with its password, and can then exchange the token of any
+// of them for tokens of another.
+func (cfg *Config) HandlerLinkedAccounts(w http.ResponseWriter, r *http.Request) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	// Switching hands out unrestricted sessions, so only the user's own
+	// sessions may link and switch accounts
+	if principal.Kind != auth.TokenSession {
+		handlers.RespondWithError(w, http.StatusForbidden, "Only available to the account's own sessions", nil)
+		return
+	}
+	userID := principal.UserID
+
+	if strings.TrimSuffix(r.URL.Path, "/") == "/api/users/me/linked-accounts" {
+		switch r.Method {
+		case http.MethodGet:
+			cfg.handlerLinkedAccountsGet(w, r, userID)
+		case http.MethodPost:
+			cfg.handlerLinkedAccountsCreate(w, r, userID)
+		default:
+			handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+		}
+		return
+	}
+
+	idString, subresource := handlers.SplitResourcePath(r.URL.Path, "/api/users/me/linked-accounts/")
+	linkedUserID, err := uuid.Parse(idString)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid account ID", err)
+		return
+	}
+	switch subresource {
+	case "":
+		if handlers.RequireMethod(w, r, http.MethodDelete) {
+			cfg.handlerLinkedAccountsDelete(w, r, userID, linkedUserID)
+		}
+	case "token":
+		if handlers.RequireMethod(w, r, http.MethodPost) {
+			cfg.handlerLinkedAccountToken(w, r, userID, linkedUserID)
+		}
+	default:
+		handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+	}
+}
+
+// handlerLinkedAccountsGet handles GET /api/users/me/linked-accounts
+// requests. Deactivated accounts are left out until they are reactivated.
+func (cfg *Config) handlerLinkedAccountsGet(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	rows, err := cfg.DB.GetLinkedAccounts(r.Context(), userID)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve linked accounts", err)
+		return
+	}
+
+	accounts := make([]types.LinkedAccount, len(rows))
+	for i, row := range rows {
+		accounts[i] = types.LinkedAccount{
+			ID:       row.ID,
+			Email:    row.Email,
+			Username: row.Username.String,
+			Verified: row.Verified,
+			LinkedAt: types.NewTimestamp(row.LinkedAt),
+		}
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, accounts)
+}
+
+// handlerLinkedAccountsCreate handles POST /api/users/me/linked-accounts
+// requests, linking the account whose email and password are given
+func (cfg *Config) handlerLinkedAccountsCreate(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	var params types.LoginRequest
+	if !handlers.DecodeJSON(w, r, &params) {
+		return
+	}
+
+	// The other account's credentials prove the user owns it. A failure
+	// doesn't invalidate the user's own session, so it isn't a 401.
+	linked, err := cfg.authenticateUser(r.Context(), params.Email, params.Password)
+	if err == nil && linked.DeactivatedAt.Valid {
+		err = auth.ErrInvalidCredentials
+	}
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusForbidden, auth.ErrInvalidCredentials.Error(), err)
+		return
+	}
+	if linked.ID == userID {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Can't link an account to itself", nil)
+		return
+	}
+
+	for _, id := range []uuid.UUID{userID, linked.ID} {
+		count, err := cfg.DB.CountLinkedAccounts(r.Context(), id)
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't link account", err)
+			return
+		}
+		if count >= MaxLinkedAccounts {
+			handlers.RespondWithError(w, http.StatusBadRequest, "Too many linked accounts", nil)
+			return
+		}
+	}
+
+	added, err := cfg.DB.LinkAccounts(r.Context(), database.LinkAccountsParams{
+		UserID:       userID,
+		LinkedUserID: linked.ID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't link account", err)
+		return
+	}
+	if added == 0 {
+		handlers.RespondWithError(w, http.StatusConflict, "Account is already linked", nil)
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusCreated, types.LinkedAccount{
+		ID:       linked.ID,
+		Email:    linked.Email,
+		Username: linked.Username.String,
+		Verified: linked.Verified,
+		LinkedAt: types.NewTimestamp(time.Now()),
+	})
+}
+
+// handlerLinkedAccountsDelete handles DELETE
+// /api/users/me/linked-accounts/{id} requests, unlinking the accounts in
+// both directions. Sessions already switched to stay valid until revoked.
+func (cfg *Config) handlerLinkedAccountsDelete(w http.ResponseWriter, r *http.Request, userID, linkedUserID uuid.UUID) {
+	removed, err := cfg.DB.UnlinkAccounts(r.Context(), database.UnlinkAccountsParams{
+		UserID:       userID,
+		LinkedUserID: linkedUserID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't unlink account", err)
+		return
+	}
+	if removed == 0 {
+		handlers.RespondWithError(w, http.StatusNotFound, "Linked account not found", nil)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerLinkedAccountToken handles POST
+// /api/users/me/linked-accounts/{id}/token requests, exchanging the user's
+// access token for a new session of a linked account
+func (cfg *Config) handlerLinkedAccountToken(w http.ResponseWriter, r *http.Request, userID, linkedUserID uuid.UUID) {
+	linked, err := cfg.DB.GetLinkedUser(r.Context(), database.GetLinkedUserParams{
+		UserID:       userID,
+		LinkedUserID: linkedUserID,
+	})
+	if err != nil {
+		if isNoRows(err) {
+			handlers.RespondWithError(w, http.StatusNotFound, "Linked account not found", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't switch account", err)
+		}
+		return
+	}
+
+	accessToken, refreshToken, err := cfg.createTokens(r.Context(), linked, false)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't create tokens", err)
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, buildLoginResponse(linked, accessToken, refreshToken))
+}
@@ -0,0 +1,53 @@
+package user
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+)
+
+func TestHandlerLinkedAccountsRouting(t *testing.T) {
+	cfg := &Config{JWTSecret: "secret"}
+	token, err := auth.MakeJWT(uuid.New(), cfg.JWTSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientToken, err := auth.MakeScopedJWT(uuid.New(), "", "client-1", []string{auth.ScopeRead, auth.ScopeWrite}, cfg.JWTSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountPath := "/api/users/me/linked-accounts/" + uuid.NewString()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{name: "no token", method: http.MethodGet, path: "/api/users/me/linked-accounts", wantStatus: http.StatusUnauthorized},
+		{name: "wrong method", method: http.MethodPut, path: "/api/users/me/linked-accounts", token: token, wantStatus: http.StatusMethodNotAllowed},
+		{name: "invalid id", method: http.MethodDelete, path: "/api/users/me/linked-accounts/nobody", token: token, wantStatus: http.StatusBadRequest},
+		{name: "unknown subresource", method: http.MethodPost, path: accountPath + "/session", token: token, wantStatus: http.StatusNotFound},
+		{name: "client can't switch accounts", method: http.MethodPost, path: accountPath + "/token", token: clientToken, wantStatus: http.StatusForbidden},
+		{name: "client can't link accounts", method: http.MethodPost, path: "/api/users/me/linked-accounts", token: clientToken, wantStatus: http.StatusForbidden},
+		{name: "token needs post", method: http.MethodGet, path: accountPath + "/token", token: token, wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			cfg.HandlerLinkedAccounts(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
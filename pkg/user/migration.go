@@ -0,0 +1,350 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/migration"
+	"github.com/kai-xlr/neo_chirpy/internal/profanity"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+const (
+	// bundleVersion is the AccountBundle format this instance writes and reads
+	bundleVersion = 2
+
+	// maxBundleSize caps the body of an import request
+	maxBundleSize = 64 << 20
+
+	// bundlePageSize is how many chirps an export reads at a time
+	bundlePageSize = 500
+)
+
+// HandlerMigration handles /api/users/me/migration requests. GET exports the
+// user's account as a bundle signed with this instance's migration key; POST
+// imports a bundle exported by another instance into the user's account,
+// after checking its signature against the key that instance advertises.
+func (cfg *Config) HandlerMigration(w http.ResponseWriter, r *http.Request) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	switch r.Method {
+	case http.MethodGet:
+		cfg.handlerMigrationExport(w, r, userID)
+	case http.MethodPost:
+		cfg.handlerMigrationImport(w, r, userID)
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+	}
+}
+
+// handlerMigrationExport handles GET /api/users/me/migration requests. The
+// bundle holds the user's profile and published chirps, live and archived;
+// reposts are left out as they only make sense next to their original. It is
+// made out to the account given in ?destination, so nobody else who gets
+// hold of it can import it.
+func (cfg *Config) handlerMigrationExport(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	if cfg.MigrationKey == nil || cfg.PublicURL == "" {
+		handlers.RespondWithError(w, http.StatusNotFound, "Account migration is not configured", nil)
+		return
+	}
+	origin := strings.TrimRight(cfg.PublicURL, "/")
+
+	destination := r.URL.Query().Get("destination")
+	if parsed, err := url.Parse(destination); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		handlers.RespondWithError(w, http.StatusBadRequest, "destination must be the https URI of your account on the new instance", err)
+		return
+	}
+
+	user, err := cfg.DB.GetUserByID(r.Context(), database.GetUserByIDParams{
+		TenantID: tenant.FromContext(r.Context()).ID,
+		ID:       userID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't export account", err)
+		return
+	}
+
+	bundle := types.AccountBundle{
+		Version:     bundleVersion,
+		Origin:      origin,
+		Account:     origin + "/api/users/" + user.ID.String(),
+		Destination: destination,
+		ExportedAt:  types.NewTimestamp(time.Now()),
+		Profile: types.AccountBundleProfile{
+			Username:  user.Username.String,
+			CreatedAt: types.NewTimestamp(user.CreatedAt),
+		},
+		Chirps: []types.AccountBundleChirp{},
+	}
+	if err := cfg.bundleChirps(r.Context(), &bundle, userID); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't export account", err)
+		return
+	}
+	followedIDs, err := cfg.DB.GetFollowedUserIDs(r.Context(), userID)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't export account", err)
+		return
+	}
+	for _, followedID := range followedIDs {
+		bundle.Follows = append(bundle.Follows, origin+"/api/users/"+followedID.String())
+	}
+
+	payload, err := json.Marshal(bundle)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't export account", err)
+		return
+	}
+	encoded, signature := migration.Sign(cfg.MigrationKey, payload)
+
+	w.Header().Set("Content-Disposition", `attachment; filename="account.json"`)
+	handlers.RespondWithJSON(w, http.StatusOK, types.SignedAccountBundle{
+		Origin:    origin,
+		Bundle:    encoded,
+		Signature: signature,
+	})
+}
+
+// bundleChirps adds the user's published chirps to bundle, oldest first,
+// reading them a page at a time
+func (cfg *Config) bundleChirps(ctx context.Context, bundle *types.AccountBundle, userID uuid.UUID) error {
+	now := time.Now()
+	params := database.GetChirpsForExportParams{
+		UserID:   userID,
+		AfterID:  uuid.Nil,
+		PageSize: bundlePageSize,
+	}
+	for {
+		page, err := cfg.DB.GetChirpsForExport(ctx, params)
+		if err != nil {
+			return err
+		}
+		for _, row := range page {
+			if row.RepostOfChirpID.Valid || row.PublishedAt.After(now) {
+				continue
+			}
+			bundle.Chirps = append(bundle.Chirps, types.AccountBundleChirp{
+				URI:            bundle.Origin + "/api/chirps/" + row.ID.String(),
+				CreatedAt:      types.NewTimestamp(row.CreatedAt),
+				Body:           row.Body,
+				Sensitive:      row.Sensitive,
+				ContentWarning: row.ContentWarning,
+				Language:       row.Language,
+			})
+		}
+		if len(page) < bundlePageSize {
+			return nil
+		}
+		last := page[len(page)-1]
+		params.AfterCreatedAt, params.AfterID = last.CreatedAt, last.ID
+	}
+}
+
+// handlerMigrationImport handles POST /api/users/me/migration requests. Only
+// the account a bundle was made out to can import it. The bundle's chirps
+// are added to the user's account with their original times, kept between
+// the join date and the export, and its username is claimed if the account
+// has none and the username is free. Chirps that break this instance's
+// rules, including links from accounts on probation, are skipped. Each
+// bundle can be imported once.
+func (cfg *Config) handlerMigrationImport(w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+	if cfg.MigrationKeys == nil || cfg.PublicURL == "" {
+		handlers.RespondWithError(w, http.StatusNotFound, "Account migration is not configured", nil)
+		return
+	}
+	self := strings.TrimRight(cfg.PublicURL, "/")
+
+	var signed types.SignedAccountBundle
+	r.Body = http.MaxBytesReader(w, r.Body, maxBundleSize)
+	if !handlers.DecodeJSON(w, r, &signed) {
+		return
+	}
+	origin := strings.TrimRight(signed.Origin, "/")
+	if origin == self {
+		handlers.RespondWithError(w, http.StatusBadRequest, "The bundle was exported from this instance", nil)
+		return
+	}
+
+	publicKey, err := cfg.MigrationKeys(r.Context(), origin)
+	if err != nil {
+		if errors.Is(err, migration.ErrInsecureOrigin) {
+			handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		} else {
+			handlers.RespondWithError(w, http.StatusBadGateway, "Couldn't fetch the migration key of "+origin, err)
+		}
+		return
+	}
+	payload, err := migration.Open(signed.Bundle, signed.Signature, publicKey)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	var bundle types.AccountBundle
+	if err := json.Unmarshal(payload, &bundle); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid bundle", err)
+		return
+	}
+	if bundle.Version != bundleVersion || bundle.Origin != origin || bundle.Account == "" {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Unsupported bundle", nil)
+		return
+	}
+	if bundle.Destination != self+"/api/users/"+userID.String() {
+		handlers.RespondWithError(w, http.StatusForbidden, "The bundle was exported for another account", nil)
+		return
+	}
+
+	tenantID := tenant.FromContext(r.Context()).ID
+	rules := importRules{
+		counting:  cfg.Counting,
+		filter:    cfg.Profanity,
+		postLinks: true,
+		latest:    time.Now(),
+	}
+	// The account is only loaded when probation is enabled
+	if cfg.Entitlements.Enabled() {
+		user, err := cfg.DB.GetUserByID(r.Context(), database.GetUserByIDParams{
+			TenantID: tenantID,
+			ID:       userID,
+		})
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't import account", err)
+			return
+		}
+		rules.postLinks = cfg.Entitlements.For(user.CreatedAt, rules.latest).PostLinks
+	}
+
+	claimed, err := cfg.DB.ClaimAccountMigration(r.Context(), database.ClaimAccountMigrationParams{
+		Origin:        origin,
+		SourceAccount: bundle.Account,
+		UserID:        userID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't import account", err)
+		return
+	}
+	if claimed == 0 {
+		handlers.RespondWithError(w, http.StatusConflict, "This bundle was already imported", nil)
+		return
+	}
+
+	params, skipped := rules.params(bundle)
+	params.UserID = userID
+	params.TenantID = tenantID
+	imported, err := cfg.DB.ImportChirps(r.Context(), params)
+	if err != nil {
+		// Release the bundle so the import can be retried
+		if deleteErr := cfg.DB.DeleteAccountMigration(r.Context(), database.DeleteAccountMigrationParams{
+			Origin:        origin,
+			SourceAccount: bundle.Account,
+		}); deleteErr != nil {
+			log.Printf("Couldn't release migration of %s: %s", bundle.Account, deleteErr)
+		}
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't import account", err)
+		return
+	}
+
+	// The chirps are in; failing to index them doesn't undo the import
+	if cfg.IndexImported != nil {
+		if err := cfg.IndexImported(r.Context(), imported); err != nil {
+			log.Printf("Couldn't index chirps imported from %s: %s", bundle.Account, err)
+		}
+	}
+
+	response := types.AccountImportResponse{ChirpsImported: int64(len(imported)), ChirpsSkipped: skipped}
+	if username, err := cfg.parseHandle(bundle.Profile.Username); err == nil && username.Valid {
+		if cfg.claimUsername(r.Context(), userID, username) {
+			response.Username = username.String
+		}
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// importRules are the checks the chirps of a bundle go through on this
+// instance
+type importRules struct {
+	counting validation.Counting
+	// filter masks banned words; nil masks nothing
+	filter *profanity.Filter
+	// postLinks allows chirps with links, which accounts on probation can't
+	// post
+	postLinks bool
+	// latest is the time no imported chirp may be later than
+	latest time.Time
+}
+
+// params lays out the bundled chirps that pass validation as the columns of
+// ImportChirps, counting the ones that don't. Banned words are masked as in
+// newly posted chirps. The origin signs whatever times it likes, so chirps
+// are kept between the account's join date and the export, and never in
+// the future, where they would stay unpublished and then jump to the top of
+// timelines.
+func (rules importRules) params(bundle types.AccountBundle) (database.ImportChirpsParams, int) {
+	latest := rules.latest
+	if !bundle.ExportedAt.IsZero() && bundle.ExportedAt.Before(latest) {
+		latest = bundle.ExportedAt.Time
+	}
+	earliest := bundle.Profile.CreatedAt.Time
+	if earliest.After(latest) {
+		earliest = latest
+	}
+
+	var params database.ImportChirpsParams
+	skipped := 0
+	for _, chirp := range bundle.Chirps {
+		warning, err := validation.NormalizeContentWarning(chirp.ContentWarning)
+		if err != nil || validation.ValidateChirpBody(chirp.Body, rules.counting) != nil || validation.ValidateChirpTags(chirp.Body) != nil {
+			skipped++
+			continue
+		}
+		if !rules.postLinks && len(validation.Links(chirp.Body)) > 0 {
+			skipped++
+			continue
+		}
+		createdAt := chirp.CreatedAt.Time
+		if createdAt.Before(earliest) {
+			createdAt = earliest
+		} else if createdAt.After(latest) {
+			createdAt = latest
+		}
+		params.Bodies = append(params.Bodies, rules.filter.Clean(chirp.Body))
+		params.CreatedAts = append(params.CreatedAts, createdAt.UTC())
+		params.Sensitive = append(params.Sensitive, chirp.Sensitive || warning != "")
+		params.Languages = append(params.Languages, chirp.Language)
+		params.ContentWarnings = append(params.ContentWarnings, warning)
+	}
+	return params, skipped
+}
+
+// claimUsername gives the user a username if they have none yet and nobody
+// else has it
+func (cfg *Config) claimUsername(ctx context.Context, userID uuid.UUID, username sql.NullString) bool {
+	updated, err := cfg.DB.SetUsernameIfUnset(ctx, database.SetUsernameIfUnsetParams{
+		ID:       userID,
+		Username: username,
+	})
+	if err != nil {
+		if !isHandleTaken(err) {
+			log.Printf("Couldn't claim username %q: %s", username.String, err)
+		}
+		return false
+	}
+	return updated == 1
+}
@@ -0,0 +1,123 @@
+package user
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/migration"
+	"github.com/kai-xlr/neo_chirpy/internal/profanity"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestHandlerMigration(t *testing.T) {
+	signer := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	other := ed25519.NewKeyFromSeed([]byte(strings.Repeat("x", ed25519.SeedSize)))
+	cfg := &Config{
+		JWTSecret: "secret",
+		PublicURL: "https://new.example",
+		MigrationKeys: func(_ context.Context, origin string) (ed25519.PublicKey, error) {
+			if origin != "https://old.example" {
+				return nil, errors.New("unreachable")
+			}
+			return signer.Public().(ed25519.PublicKey), nil
+		},
+	}
+	userID := uuid.New()
+	token, err := auth.MakeJWT(userID, cfg.JWTSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	self := "https://new.example/api/users/" + userID.String()
+	bundle := func(key ed25519.PrivateKey, origin, destination string) string {
+		payload, _ := json.Marshal(types.AccountBundle{Version: bundleVersion, Origin: origin, Account: origin + "/api/users/1", Destination: destination})
+		encoded, signature := migration.Sign(key, payload)
+		body, _ := json.Marshal(types.SignedAccountBundle{Origin: origin, Bundle: encoded, Signature: signature})
+		return string(body)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		token      string
+		body       string
+		wantStatus int
+	}{
+		{name: "no token", method: http.MethodGet, wantStatus: http.StatusUnauthorized},
+		{name: "wrong method", method: http.MethodPut, token: token, wantStatus: http.StatusMethodNotAllowed},
+		{name: "export not configured", method: http.MethodGet, token: token, wantStatus: http.StatusNotFound},
+		{name: "own origin", method: http.MethodPost, token: token, body: bundle(signer, "https://new.example", self), wantStatus: http.StatusBadRequest},
+		{name: "unreachable origin", method: http.MethodPost, token: token, body: bundle(signer, "https://gone.example", self), wantStatus: http.StatusBadGateway},
+		{name: "wrong signer", method: http.MethodPost, token: token, body: bundle(other, "https://old.example", self), wantStatus: http.StatusBadRequest},
+		{name: "another account's bundle", method: http.MethodPost, token: token, body: bundle(signer, "https://old.example", "https://new.example/api/users/"+uuid.NewString()), wantStatus: http.StatusForbidden},
+		{name: "another instance's bundle", method: http.MethodPost, token: token, body: bundle(signer, "https://old.example", "https://other.example/api/users/"+userID.String()), wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/users/me/migration", strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			cfg.HandlerMigration(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body = %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+func TestImportParams(t *testing.T) {
+	joined := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	exported := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := profanity.New(nil)
+	filter.Set([]string{"kerfuffle"})
+	bundle := types.AccountBundle{
+		ExportedAt: types.NewTimestamp(exported),
+		Profile:    types.AccountBundleProfile{CreatedAt: types.NewTimestamp(joined)},
+		Chirps: []types.AccountBundleChirp{
+			{Body: "hello", CreatedAt: types.NewTimestamp(created), Language: "en"},
+			{Body: strings.Repeat("a", 141)},
+			{Body: "spoilers", ContentWarning: " film ending ", CreatedAt: types.NewTimestamp(joined.AddDate(-10, 0, 0))},
+			{Body: "bad warning", ContentWarning: strings.Repeat("w", 500)},
+			{Body: "#a #b #c #d #e #f #g #h #i #j #k #l #m #n #o #p"},
+			{Body: "what a kerfuffle", CreatedAt: types.NewTimestamp(exported.AddDate(10, 0, 0))},
+			{Body: "read https://example.com", CreatedAt: types.NewTimestamp(created)},
+		},
+	}
+	rules := importRules{filter: filter, latest: exported.AddDate(1, 0, 0)}
+	params, skipped := rules.params(bundle)
+	if skipped != 4 {
+		t.Errorf("skipped = %d, want 4", skipped)
+	}
+	if len(params.Bodies) != 3 || params.Bodies[0] != "hello" || params.Bodies[1] != "spoilers" || params.Bodies[2] != "what a "+profanity.Mask {
+		t.Fatalf("bodies = %q", params.Bodies)
+	}
+	// Times are kept between the join date and the export
+	for i, want := range []time.Time{created, joined, exported} {
+		if !params.CreatedAts[i].Equal(want) {
+			t.Errorf("created_at[%d] = %v, want %v", i, params.CreatedAts[i], want)
+		}
+	}
+	if params.Sensitive[0] || !params.Sensitive[1] {
+		t.Errorf("sensitive = %v, want [false true]", params.Sensitive)
+	}
+	if params.ContentWarnings[1] != "film ending" {
+		t.Errorf("content warning = %q, want %q", params.ContentWarnings[1], "film ending")
+	}
+
+	// Accounts that may post links import chirps with links too
+	rules.postLinks = true
+	if params, _ := rules.params(bundle); len(params.Bodies) != 4 {
+		t.Errorf("bodies with links allowed = %q, want 4", params.Bodies)
+	}
+}
@@ -0,0 +1,213 @@
+package user
+
+import (
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+// HandlerMutedWords dispatches /api/users/me/muted-words requests based on
+// path and HTTP method
+func (cfg *Config) HandlerMutedWords(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimPrefix(r.URL.Path, "/api/users/me/muted-words") {
+	case "":
+	case "/export":
+		cfg.handlerMutedWordsExport(w, r)
+		return
+	case "/import":
+		cfg.handlerMutedWordsImport(w, r)
+		return
+	default:
+		handlers.RespondWithError(w, http.StatusNotFound, "404 page not found", nil)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		cfg.handlerMutedWordsGet(w, r)
+	case http.MethodPut:
+		cfg.handlerMutedWordsPut(w, r)
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+	}
+}
+
+// handlerMutedWordsGet handles GET /api/users/me/muted-words requests
+func (cfg *Config) handlerMutedWordsGet(w http.ResponseWriter, r *http.Request) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	mutedWords, err := cfg.DB.GetMutedWords(r.Context(), userID)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve muted words", err)
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, types.MutedWordsResponse{
+		MutedWords: emptyIfNil(mutedWords),
+	})
+}
+
+// handlerMutedWordsPut handles PUT /api/users/me/muted-words requests.
+// The submitted list replaces the user's existing muted words.
+func (cfg *Config) handlerMutedWordsPut(w http.ResponseWriter, r *http.Request) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	// Parse request body
+	var params types.MutedWordsRequest
+	if !handlers.DecodeJSON(w, r, &params) {
+		return
+	}
+
+	mutedWords, ok := cfg.replaceMutedWords(w, r, userID, params.MutedWords)
+	if !ok {
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, types.MutedWordsResponse{
+		MutedWords: mutedWords,
+	})
+}
+
+// handlerMutedWordsExport handles GET /api/users/me/muted-words/export
+// requests, returning the list as a file the user can import into another
+// account
+func (cfg *Config) handlerMutedWordsExport(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	mutedWords, err := cfg.DB.GetMutedWords(r.Context(), userID)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve muted words", err)
+		return
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="muted-words.json"`)
+	handlers.RespondWithJSON(w, http.StatusOK, types.WordList{
+		Kind:       types.WordListMuted,
+		Version:    types.WordListVersion,
+		ExportedAt: types.NewTimestamp(time.Now()),
+		Words:      emptyIfNil(mutedWords),
+	})
+}
+
+// handlerMutedWordsImport handles POST /api/users/me/muted-words/import
+// requests. The exported list is merged into the user's muted words, or
+// replaces them with ?mode=replace.
+func (cfg *Config) handlerMutedWordsImport(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodPost) {
+		return
+	}
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	mode := r.URL.Query().Get("mode")
+	if err := validation.ValidateImportMode(mode); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	var list types.WordList
+	if !handlers.DecodeJSON(w, r, &list) {
+		return
+	}
+	if err := validation.ValidateWordList(list, types.WordListMuted); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	current, err := cfg.DB.GetMutedWords(r.Context(), userID)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve muted words", err)
+		return
+	}
+	words := list.Words
+	if mode != validation.ImportReplace {
+		words = append(slices.Clone(current), list.Words...)
+	}
+	mutedWords, ok := cfg.replaceMutedWords(w, r, userID, words)
+	if !ok {
+		return
+	}
+
+	added := 0
+	for _, word := range mutedWords {
+		if !slices.Contains(current, word) {
+			added++
+		}
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, types.WordListImportResponse{Added: added, Words: mutedWords})
+}
+
+// replaceMutedWords validates, normalizes and stores the user's new muted
+// words. On failure it responds with the error and returns false.
+func (cfg *Config) replaceMutedWords(w http.ResponseWriter, r *http.Request, userID uuid.UUID, requested []string) ([]string, bool) {
+	if err := validation.ValidateMutedWords(requested); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return nil, false
+	}
+
+	mutedWords := normalizeMutedWords(requested)
+	err := cfg.DB.ReplaceMutedWords(r.Context(), database.ReplaceMutedWordsParams{
+		UserID:  userID,
+		Phrases: mutedWords,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update muted words", err)
+		return nil, false
+	}
+	return mutedWords, true
+}
+
+// normalizeMutedWords lowercases, collapses whitespace, removes duplicate phrases
+// and sorts the result to match the order returned by GetMutedWords
+func normalizeMutedWords(words []string) []string {
+	seen := make(map[string]struct{}, len(words))
+	normalized := make([]string, 0, len(words))
+	for _, word := range words {
+		phrase := strings.ToLower(strings.Join(strings.Fields(word), " "))
+		if _, found := seen[phrase]; found {
+			continue
+		}
+		seen[phrase] = struct{}{}
+		normalized = append(normalized, phrase)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+// emptyIfNil ensures list responses serialize as [] rather than null
+func emptyIfNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
@@ -0,0 +1,49 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// HandlerPasswordReset handles POST /api/password-reset requests, which set
+// a new password with an emailed reset token. Each token works once.
+func (cfg *Config) HandlerPasswordReset(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	var params types.PasswordResetRequest
+	if !handlers.DecodeJSON(w, r, &params) {
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(params.Password)
+	if err != nil {
+		if err == auth.ErrPasswordEmpty {
+			handlers.RespondWithError(w, http.StatusBadRequest, err.Error(), err)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't hash password", err)
+		}
+		return
+	}
+
+	_, err = cfg.DB.ResetPasswordWithToken(r.Context(), database.ResetPasswordWithTokenParams{
+		HashedPassword: hashedPassword,
+		TokenHash:      auth.HashAPIKey(params.Token),
+		TenantID:       tenant.FromContext(r.Context()).ID,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusBadRequest, "Invalid or expired reset token", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't reset password", err)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,93 @@
+package user
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// HandlerPreferences dispatches /api/users/me/preferences requests based on HTTP method
+func (cfg *Config) HandlerPreferences(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		cfg.handlerPreferencesGet(w, r)
+	case http.MethodPut:
+		cfg.handlerPreferencesPut(w, r)
+	default:
+		handlers.RespondWithError(w, http.StatusMethodNotAllowed, types.ErrMsgMethodNotAllowed, nil)
+	}
+}
+
+// defaultPreferences apply until the user saves their own
+var defaultPreferences = types.UserPreferences{
+	SensitiveContent: types.SensitiveBlur,
+}
+
+// handlerPreferencesGet handles GET /api/users/me/preferences requests
+func (cfg *Config) handlerPreferencesGet(w http.ResponseWriter, r *http.Request) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	preferences, err := cfg.Preferences(r.Context(), userID)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve preferences", err)
+		return
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, preferences)
+}
+
+// Preferences returns the user's saved preferences, or the defaults if they
+// haven't saved any
+func (cfg *Config) Preferences(ctx context.Context, userID uuid.UUID) (types.UserPreferences, error) {
+	preferences, err := cfg.DB.GetUserPreferences(ctx, userID)
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			return defaultPreferences, nil
+		}
+		return types.UserPreferences{}, err
+	}
+	return types.UserPreferences{
+		SensitiveContent: preferences.SensitiveContent,
+	}, nil
+}
+
+// handlerPreferencesPut handles PUT /api/users/me/preferences requests.
+// The submitted preferences replace the user's existing ones.
+func (cfg *Config) handlerPreferencesPut(w http.ResponseWriter, r *http.Request) {
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	// Parse request body
+	var params types.UserPreferences
+	if !handlers.DecodeJSON(w, r, &params) {
+		return
+	}
+
+	preferences, err := cfg.DB.UpsertUserPreferences(r.Context(), database.UpsertUserPreferencesParams{
+		UserID:           userID,
+		SensitiveContent: params.SensitiveContent,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't update preferences", err)
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, types.UserPreferences{
+		SensitiveContent: preferences.SensitiveContent,
+	})
+}
@@ -0,0 +1,124 @@
+package user
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// recapPeriods are generated in this order by GenerateRecaps
+var recapPeriods = []string{types.RecapWeek, types.RecapMonth, types.RecapYear}
+
+// HandlerRecap handles GET /api/users/me/recap?period= requests, returning
+// the user's recap for the most recent completed period. The period is
+// "week" (the default), "month" or "year".
+func (cfg *Config) HandlerRecap(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	// Extract and validate JWT token
+	principal, err := auth.Authenticate(r, cfg.JWTSecret)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid token", err)
+		return
+	}
+	userID := principal.UserID
+
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = types.RecapWeek
+	}
+	if _, _, ok := lastCompletedPeriod(period, time.Now()); !ok {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Period must be week, month or year", nil)
+		return
+	}
+
+	recap, err := cfg.DB.GetLatestUserRecap(r.Context(), database.GetLatestUserRecapParams{
+		UserID: userID,
+		Period: period,
+	})
+	if err != nil {
+		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
+			handlers.RespondWithError(w, http.StatusNotFound, "No recap yet for this period", nil)
+		} else {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't retrieve recap", err)
+		}
+		return
+	}
+
+	response := types.Recap{
+		Period:             recap.Period,
+		PeriodStart:        types.NewTimestamp(recap.PeriodStart),
+		PeriodEnd:          types.NewTimestamp(recap.PeriodEnd),
+		TotalChirps:        recap.TotalChirps,
+		MostLikedReactions: recap.MostLikedReactions,
+		TopHashtags:        recap.TopHashtags,
+	}
+	if recap.MostLikedChirpID.Valid {
+		response.MostLikedChirpID = &recap.MostLikedChirpID.UUID
+	}
+	if response.TopHashtags == nil {
+		response.TopHashtags = []string{}
+	}
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// GenerateRecaps summarises the most recent completed week, month and year
+// for every user who chirped in them. A period is generated once; later runs
+// skip it until the next one completes.
+func (cfg *Config) GenerateRecaps(ctx context.Context) error {
+	now := time.Now()
+	for _, period := range recapPeriods {
+		start, end, _ := lastCompletedPeriod(period, now)
+		done, err := cfg.DB.HasUserRecaps(ctx, database.HasUserRecapsParams{
+			Period:      period,
+			PeriodStart: start,
+		})
+		if err != nil {
+			return err
+		}
+		if done {
+			continue
+		}
+
+		generated, err := cfg.DB.GenerateUserRecaps(ctx, database.GenerateUserRecapsParams{
+			Period:      period,
+			PeriodStart: start,
+			PeriodEnd:   end,
+		})
+		if err != nil {
+			return err
+		}
+		if generated > 0 {
+			log.Printf("Generated %d %s recaps starting %s", generated, period, start.Format(time.DateOnly))
+		}
+	}
+	return nil
+}
+
+// lastCompletedPeriod returns the bounds of the latest period of the given
+// kind that ended at or before now. Weeks start on Monday; all bounds are
+// midnight UTC.
+func lastCompletedPeriod(period string, now time.Time) (start, end time.Time, ok bool) {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case types.RecapWeek:
+		end = today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+		return end.AddDate(0, 0, -7), end, true
+	case types.RecapMonth:
+		end = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return end.AddDate(0, -1, 0), end, true
+	case types.RecapYear:
+		end = time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return end.AddDate(-1, 0, 0), end, true
+	}
+	return time.Time{}, time.Time{}, false
+}
@@ -0,0 +1,39 @@
+package user
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLastCompletedPeriod(t *testing.T) {
+	// Wednesday, 10:30 in New York is 15:30 UTC
+	now := time.Date(2026, 3, 4, 10, 30, 0, 0, time.FixedZone("EST", -5*3600))
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		period    string
+		now       time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+		wantOK    bool
+	}{
+		{"week", now, day(2026, 2, 23), day(2026, 3, 2), true},
+		{"week", day(2026, 3, 2), day(2026, 2, 23), day(2026, 3, 2), true},
+		{"week", day(2026, 3, 8), day(2026, 2, 23), day(2026, 3, 2), true},
+		{"month", now, day(2026, 2, 1), day(2026, 3, 1), true},
+		{"month", day(2026, 1, 15), day(2025, 12, 1), day(2026, 1, 1), true},
+		{"year", now, day(2025, 1, 1), day(2026, 1, 1), true},
+		{"day", now, time.Time{}, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period+" "+tt.now.Format(time.DateOnly), func(t *testing.T) {
+			start, end, ok := lastCompletedPeriod(tt.period, tt.now)
+			if ok != tt.wantOK || !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("lastCompletedPeriod() = %s, %s, %v, want %s, %s, %v",
+					start, end, ok, tt.wantStart, tt.wantEnd, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,440 @@
+package user
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/oidc"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+	"github.com/kai-xlr/neo_chirpy/pkg/validation"
+)
+
+const (
+	// ssoStateTTL is how long a user has to finish signing in at the
+	// identity provider
+	ssoStateTTL = 10 * time.Minute
+
+	// ssoCodeTTL is how long the client has to redeem the code the callback
+	// hands it
+	ssoCodeTTL = time.Minute
+
+	// ssoDefaultReturnTo is where users land after signing in when the
+	// client didn't ask for a page
+	ssoDefaultReturnTo = "/app/"
+
+	// ssoCookie ties a sign-in to the browser that started it, so nobody
+	// can have someone else's browser finish their sign-in and use it as
+	// their account (login CSRF). It holds a hash of the state.
+	ssoCookie = "chirpy_sso"
+)
+
+// Errors reported to the client as ?sso_error= on the return page
+const (
+	ssoErrorDenied    = "access_denied"
+	ssoErrorFailed    = "login_failed"
+	ssoErrorNoAccount = "no_account"
+	ssoErrorDisabled  = "account_disabled"
+)
+
+// errSSONoAccount means an identity isn't linked to an account and none may
+// be created for it
+var errSSONoAccount = errors.New("no account for this identity")
+
+// ssoState is what a sign-in in progress at the identity provider needs
+// when it comes back to the callback
+type ssoState struct {
+	TenantID    uuid.UUID `json:"tenant_id"`
+	Nonce       string    `json:"nonce"`
+	Verifier    string    `json:"verifier"`
+	RedirectURI string    `json:"redirect_uri"`
+	ReturnTo    string    `json:"return_to"`
+}
+
+// ssoCode is a finished sign-in waiting for the client to collect its tokens
+type ssoCode struct {
+	TenantID uuid.UUID `json:"tenant_id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Binding  string    `json:"binding"`
+}
+
+// HandlerSSOLogin handles GET /api/sso/login requests, which redirect the
+// browser to the identity provider. ?redirect_uri= names the page on this
+// site to return to afterwards. Providers that start sign-ins themselves
+// (third-party initiated login) should also be pointed here.
+func (cfg *Config) HandlerSSOLogin(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+	if cfg.SSO == nil {
+		handlers.RespondWithError(w, http.StatusNotFound, "Single sign-on is not configured", nil)
+		return
+	}
+
+	returnTo := ssoDefaultReturnTo
+	if requested := r.URL.Query().Get("redirect_uri"); requested != "" {
+		if !isLocalPath(requested) {
+			handlers.RespondWithError(w, http.StatusBadRequest, "redirect_uri must be a path on this site", nil)
+			return
+		}
+		returnTo = requested
+	}
+
+	state, err := auth.MakeRefreshToken()
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't start sign-in", err)
+		return
+	}
+	nonce, err := auth.MakeRefreshToken()
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't start sign-in", err)
+		return
+	}
+	verifier, err := auth.MakeRefreshToken()
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't start sign-in", err)
+		return
+	}
+
+	pending := ssoState{
+		TenantID:    tenant.FromContext(r.Context()).ID,
+		Nonce:       nonce,
+		Verifier:    verifier,
+		RedirectURI: cfg.ssoRedirectURI(r),
+		ReturnTo:    returnTo,
+	}
+	authURL, err := cfg.SSO.AuthCodeURL(r.Context(), pending.RedirectURI, state, nonce, verifier)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadGateway, "Couldn't reach the identity provider", err)
+		return
+	}
+	data, err := json.Marshal(pending)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't start sign-in", err)
+		return
+	}
+	if err := cfg.Store.Set(r.Context(), ssoStateKey(state), data, ssoStateTTL); err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't start sign-in", err)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     ssoCookie,
+		Value:    auth.HashAPIKey(state),
+		Path:     "/api/sso/",
+		MaxAge:   int(ssoStateTTL / time.Second),
+		Secure:   strings.HasPrefix(pending.RedirectURI, "https://"),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// HandlerSSOCallback handles GET /api/sso/callback requests, where the
+// identity provider sends the browser back with an authorization code. The
+// browser is redirected to the page the sign-in started from with a
+// one-time ?sso_code= to redeem at POST /api/sso/token, or with ?sso_error=.
+// Only the browser that started the sign-in can finish it.
+func (cfg *Config) HandlerSSOCallback(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodGet) {
+		return
+	}
+	if cfg.SSO == nil {
+		handlers.RespondWithError(w, http.StatusNotFound, "Single sign-on is not configured", nil)
+		return
+	}
+
+	query := r.URL.Query()
+	binding := ssoBinding(r)
+	if !sameBinding(binding, auth.HashAPIKey(query.Get("state"))) {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Sign-in wasn't started in this browser", nil)
+		return
+	}
+	pending, err := cfg.takeSSOState(r.Context(), query.Get("state"))
+	if err != nil || pending.TenantID != tenant.FromContext(r.Context()).ID {
+		handlers.RespondWithError(w, http.StatusBadRequest, "Invalid or expired sign-in state", err)
+		return
+	}
+	if query.Get("error") != "" {
+		redirectWithParam(w, r, pending.ReturnTo, "sso_error", ssoErrorDenied)
+		return
+	}
+
+	claims, err := cfg.SSO.Exchange(r.Context(), query.Get("code"), pending.RedirectURI, pending.Verifier, pending.Nonce)
+	if err != nil {
+		log.Printf("Couldn't redeem SSO authorization code: %s", err)
+		redirectWithParam(w, r, pending.ReturnTo, "sso_error", ssoErrorFailed)
+		return
+	}
+
+	user, err := cfg.ssoUser(r.Context(), pending.TenantID, claims)
+	if err == errSSONoAccount {
+		redirectWithParam(w, r, pending.ReturnTo, "sso_error", ssoErrorNoAccount)
+		return
+	}
+	if err != nil {
+		log.Printf("Couldn't find account for SSO sign-in: %s", err)
+		redirectWithParam(w, r, pending.ReturnTo, "sso_error", ssoErrorFailed)
+		return
+	}
+
+	// Signing in restores a deactivated account, as a password login does
+	user, err = cfg.reactivateIfDeactivated(r.Context(), user)
+	if err == auth.ErrInvalidCredentials {
+		redirectWithParam(w, r, pending.ReturnTo, "sso_error", ssoErrorDisabled)
+		return
+	}
+	if err != nil {
+		log.Printf("Couldn't reactivate account on SSO sign-in: %s", err)
+		redirectWithParam(w, r, pending.ReturnTo, "sso_error", ssoErrorFailed)
+		return
+	}
+
+	code, err := auth.MakeRefreshToken()
+	if err == nil {
+		var data []byte
+		data, err = json.Marshal(ssoCode{TenantID: pending.TenantID, UserID: user.ID, Binding: binding})
+		if err == nil {
+			err = cfg.Store.Set(r.Context(), ssoCodeKey(code), data, ssoCodeTTL)
+		}
+	}
+	if err != nil {
+		log.Printf("Couldn't store SSO code: %s", err)
+		redirectWithParam(w, r, pending.ReturnTo, "sso_error", ssoErrorFailed)
+		return
+	}
+	redirectWithParam(w, r, pending.ReturnTo, "sso_code", code)
+}
+
+// HandlerSSOToken handles POST /api/sso/token requests, which redeem the
+// one-time code from a single sign-on callback for the same tokens
+// POST /api/login returns. The code must come from the browser that started
+// the sign-in.
+func (cfg *Config) HandlerSSOToken(w http.ResponseWriter, r *http.Request) {
+	if !handlers.RequireMethod(w, r, http.MethodPost) {
+		return
+	}
+	if cfg.SSO == nil {
+		handlers.RespondWithError(w, http.StatusNotFound, "Single sign-on is not configured", nil)
+		return
+	}
+
+	var params types.SSOTokenRequest
+	if !handlers.DecodeJSON(w, r, &params) {
+		return
+	}
+
+	finished, err := cfg.takeSSOCode(r.Context(), params.Code)
+	if err != nil || finished.TenantID != tenant.FromContext(r.Context()).ID || !sameBinding(ssoBinding(r), finished.Binding) {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid or expired code", err)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: ssoCookie, Path: "/api/sso/", MaxAge: -1})
+	user, err := cfg.DB.GetUserByID(r.Context(), database.GetUserByIDParams{
+		TenantID: finished.TenantID,
+		ID:       finished.UserID,
+	})
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "Invalid or expired code", err)
+		return
+	}
+
+	accessToken, refreshTokenString, err := cfg.createTokens(r.Context(), user, true)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't create tokens", err)
+		return
+	}
+	cfg.recordFingerprints(r, user.ID)
+	handlers.RespondWithJSON(w, http.StatusOK, buildLoginResponse(user, accessToken, refreshTokenString))
+}
+
+// ssoUser finds the account an identity is linked to. An unlinked identity
+// is linked to the account with its email when the provider has verified
+// that email, and otherwise gets a new account if JIT provisioning is on.
+func (cfg *Config) ssoUser(ctx context.Context, tenantID uuid.UUID, claims oidc.Claims) (database.User, error) {
+	identity := database.GetUserByIdentityParams{
+		TenantID: tenantID,
+		Issuer:   cfg.SSO.Issuer,
+		Subject:  claims.Subject,
+	}
+	user, err := cfg.DB.GetUserByIdentity(ctx, identity)
+	if err == nil {
+		return user, nil
+	}
+	if !isNoRows(err) {
+		return database.User{}, err
+	}
+
+	email := strings.TrimSpace(claims.Email)
+	if validation.ValidateEmail(email) != nil {
+		return database.User{}, errSSONoAccount
+	}
+
+	user, err = cfg.DB.GetUserByEmail(ctx, database.GetUserByEmailParams{
+		TenantID: tenantID,
+		Email:    email,
+	})
+	switch {
+	case err == nil && claims.EmailVerified:
+		// Linking by email below
+	case err == nil || !isNoRows(err):
+		// An unverified email mustn't take over the account that owns it
+		if err == nil {
+			err = errSSONoAccount
+		}
+		return database.User{}, err
+	case !cfg.SSOJITProvisioning:
+		return database.User{}, errSSONoAccount
+	default:
+		user, err = cfg.provisionSSOUser(ctx, tenantID, email, claims.PreferredUsername)
+		if err != nil {
+			return database.User{}, err
+		}
+	}
+
+	err = cfg.DB.CreateIdentity(ctx, database.CreateIdentityParams{
+		UserID:   user.ID,
+		TenantID: tenantID,
+		Issuer:   identity.Issuer,
+		Subject:  identity.Subject,
+	})
+	if err != nil {
+		return database.User{}, err
+	}
+	return user, nil
+}
+
+// provisionSSOUser creates an account without a password for a first-time
+// single sign-on user. The provider's preferred username becomes the handle
+// when it is valid and free.
+func (cfg *Config) provisionSSOUser(ctx context.Context, tenantID uuid.UUID, email, preferredUsername string) (database.User, error) {
+	params := database.CreateInvitedUserParams{
+		Email:    email,
+		TenantID: tenantID,
+	}
+	if handle := validation.NormalizeHandle(preferredUsername); handle != "" && validation.ValidateHandle(handle, cfg.ReservedHandles) == nil {
+		params.Username = sql.NullString{String: handle, Valid: true}
+	}
+
+	user, err := cfg.DB.CreateInvitedUser(ctx, params)
+	if err != nil && isNoRows(err) && params.Username.Valid {
+		// The handle is taken; the user can pick another later
+		params.Username = sql.NullString{}
+		user, err = cfg.DB.CreateInvitedUser(ctx, params)
+	}
+	if err != nil {
+		if isNoRows(err) {
+			return database.User{}, errSSONoAccount
+		}
+		return database.User{}, err
+	}
+
+	cfg.Events.Publish(events.Event{
+		Type:   events.UserCreated,
+		UserID: user.ID,
+	})
+	return user, nil
+}
+
+// ssoRedirectURI is the callback URL registered with the identity provider
+func (cfg *Config) ssoRedirectURI(r *http.Request) string {
+	if cfg.SSORedirectURL != "" {
+		return cfg.SSORedirectURL
+	}
+	return handlers.RequestOrigin(r) + "/api/sso/callback"
+}
+
+// ssoBinding returns the sign-in binding cookie of a request, or "" without
+// one
+func ssoBinding(r *http.Request) string {
+	cookie, err := r.Cookie(ssoCookie)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// sameBinding reports whether a request's binding cookie matches the one a
+// sign-in was started with
+func sameBinding(got, want string) bool {
+	return got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// takeSSOState loads and forgets a pending sign-in, so a state can only be
+// used once
+func (cfg *Config) takeSSOState(ctx context.Context, state string) (ssoState, error) {
+	var pending ssoState
+	if state == "" {
+		return pending, cache.ErrNotFound
+	}
+	err := cfg.take(ctx, ssoStateKey(state), &pending)
+	return pending, err
+}
+
+// takeSSOCode loads and forgets a finished sign-in's code
+func (cfg *Config) takeSSOCode(ctx context.Context, code string) (ssoCode, error) {
+	var finished ssoCode
+	err := cfg.take(ctx, ssoCodeKey(code), &finished)
+	return finished, err
+}
+
+// take decodes the JSON stored at key and deletes it
+func (cfg *Config) take(ctx context.Context, key string, v any) error {
+	data, err := cfg.Store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Store.Delete(ctx, key); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// ssoStateKey and ssoCodeKey store secrets by their hash, so the store
+// never holds a usable value
+func ssoStateKey(state string) string {
+	return "sso-state:" + auth.HashAPIKey(state)
+}
+
+func ssoCodeKey(code string) string {
+	return "sso-code:" + auth.HashAPIKey(code)
+}
+
+// isLocalPath reports whether target is a path on this site, ruling out
+// protocol-relative URLs that would redirect elsewhere
+func isLocalPath(target string) bool {
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Scheme != "" || parsed.Host != "" {
+		return false
+	}
+	return strings.HasPrefix(target, "/") && !strings.HasPrefix(target, "//") && !strings.Contains(target, "\\")
+}
+
+// redirectWithParam sends the browser to a local path with one query
+// parameter added
+func redirectWithParam(w http.ResponseWriter, r *http.Request, path, name, value string) {
+	target, _ := url.Parse(path)
+	query := target.Query()
+	query.Set(name, value)
+	target.RawQuery = query.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// isNoRows reports whether a query found nothing
+func isNoRows(err error) bool {
+	return err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set"
+}
@@ -0,0 +1,189 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+	"github.com/kai-xlr/neo_chirpy/internal/cache"
+	"github.com/kai-xlr/neo_chirpy/internal/httpclient"
+	"github.com/kai-xlr/neo_chirpy/internal/oidc"
+	"github.com/kai-xlr/neo_chirpy/internal/tenant"
+)
+
+func TestIsLocalPath(t *testing.T) {
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{"/app/", true},
+		{"/app/login?next=/home", true},
+		{"app/", false},
+		{"//evil.example.com/", false},
+		{"/\\evil.example.com", false},
+		{"https://evil.example.com/app/", false},
+		{"javascript:alert(1)", false},
+	}
+	for _, tt := range tests {
+		if got := isLocalPath(tt.target); got != tt.want {
+			t.Errorf("isLocalPath(%q) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
+
+// newSSOConfig returns a config whose provider only serves discovery
+func newSSOConfig(t *testing.T) *Config {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 server.URL,
+			"authorization_endpoint": server.URL + "/authorize",
+			"token_endpoint":         server.URL + "/token",
+			"jwks_uri":               server.URL + "/keys",
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return &Config{
+		SSO: &oidc.Provider{
+			Issuer:       server.URL,
+			ClientID:     "chirpy",
+			ClientSecret: "s3cret",
+			Client:       httpclient.New(httpclient.DefaultConfig()),
+		},
+		Store: cache.NewMemory(),
+	}
+}
+
+func TestHandlerSSOLogin(t *testing.T) {
+	cfg := newSSOConfig(t)
+
+	req := httptest.NewRequest(http.MethodGet, "http://chirpy.example.com/api/sso/login?redirect_uri=/app/feed", nil)
+	rec := httptest.NewRecorder()
+	cfg.HandlerSSOLogin(rec, req)
+
+	if rec.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusFound, rec.Body)
+	}
+	location, _ := url.Parse(rec.Header().Get("Location"))
+	if location.Path != "/authorize" {
+		t.Errorf("redirected to %s, want the authorization endpoint", location)
+	}
+	query := location.Query()
+	if got := query.Get("redirect_uri"); got != "http://chirpy.example.com/api/sso/callback" {
+		t.Errorf("redirect_uri = %q", got)
+	}
+
+	pending, err := cfg.takeSSOState(context.Background(), query.Get("state"))
+	if err != nil {
+		t.Fatalf("state wasn't stored: %v", err)
+	}
+	if pending.ReturnTo != "/app/feed" || pending.Nonce != query.Get("nonce") {
+		t.Errorf("stored state = %+v", pending)
+	}
+	if oidc.CodeChallenge(pending.Verifier) != query.Get("code_challenge") {
+		t.Error("stored verifier doesn't match the code challenge")
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != auth.HashAPIKey(query.Get("state")) || !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteLaxMode {
+		t.Errorf("binding cookie = %+v, want an HttpOnly, SameSite=Lax hash of the state", cookies)
+	}
+	if _, err := cfg.takeSSOState(context.Background(), query.Get("state")); err != cache.ErrNotFound {
+		t.Errorf("state was usable twice: %v", err)
+	}
+}
+
+func TestHandlerSSOLoginRejectsOffsiteRedirect(t *testing.T) {
+	cfg := newSSOConfig(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sso/login?redirect_uri=//evil.example.com/", nil)
+	rec := httptest.NewRecorder()
+	cfg.HandlerSSOLogin(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandlerSSOCallbackRejectsUnknownState(t *testing.T) {
+	cfg := newSSOConfig(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sso/callback?code=c0de&state=forged", nil)
+	req.AddCookie(&http.Cookie{Name: ssoCookie, Value: auth.HashAPIKey("forged")})
+	rec := httptest.NewRecorder()
+	cfg.HandlerSSOCallback(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandlerSSORequiresStartingBrowser(t *testing.T) {
+	cfg := newSSOConfig(t)
+	ctx := context.Background()
+
+	// A state started in another browser can't be finished in this one
+	login := httptest.NewRecorder()
+	cfg.HandlerSSOLogin(login, httptest.NewRequest(http.MethodGet, "/api/sso/login", nil))
+	location, _ := url.Parse(login.Header().Get("Location"))
+	state := location.Query().Get("state")
+	tests := []struct {
+		name   string
+		cookie string
+	}{
+		{name: "no cookie"},
+		{name: "another sign-in's cookie", cookie: auth.HashAPIKey("other-state")},
+	}
+	for _, tt := range tests {
+		t.Run("callback with "+tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/sso/callback?code=c0de&state="+state, nil)
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: ssoCookie, Value: tt.cookie})
+			}
+			rec := httptest.NewRecorder()
+			cfg.HandlerSSOCallback(rec, req)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+		})
+	}
+
+	// Nor can a code handed to another browser be redeemed here
+	for _, tt := range tests {
+		t.Run("token with "+tt.name, func(t *testing.T) {
+			data, _ := json.Marshal(ssoCode{TenantID: tenant.DefaultID, UserID: uuid.New(), Binding: auth.HashAPIKey(state)})
+			if err := cfg.Store.Set(ctx, ssoCodeKey("c0de"), data, time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/api/sso/token", strings.NewReader(`{"code":"c0de"}`))
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: ssoCookie, Value: tt.cookie})
+			}
+			rec := httptest.NewRecorder()
+			cfg.HandlerSSOToken(rec, req)
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+			}
+		})
+	}
+}
+
+func TestHandlerSSODisabled(t *testing.T) {
+	cfg := &Config{}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/sso/login", nil)
+	rec := httptest.NewRecorder()
+	cfg.HandlerSSOLogin(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -1,5 +1,32 @@
 package validation
 
 const (
-	MaxChirpLength = 140
+	MaxChirpLength       = 140
+	MaxMutedWords        = 100
+	MaxMutedWordLength   = 100
+	MaxMediaAttachments  = 4
+	MaxAltTextLength     = 1000
+	MaxChirpDelaySeconds = 300
+	MaxChirpScheduleDays = 30
+	MaxChirpMentions     = 10
+	MaxChirpHashtags     = 15
+	MinHandleLength      = 3
+	MaxHandleLength      = 15
+	MaxClientNameLength  = 100
+	MaxRedirectURIs      = 10
+	MaxReportDetails     = 1000
+	MaxBannedWords       = 1000
+	MaxBannedWordLength  = 50
+	MaxContentWarning    = 100
+	MaxDisplayNameLength = 50
+	MaxBioLength         = 160
+	MaxLocationLength    = 30
+	MaxWebsiteLength     = 100
+	MaxAvatarURLLength   = 500
+)
+
+// How an imported word list is combined with the current one
+const (
+	ImportMerge   = "merge"
+	ImportReplace = "replace"
 )
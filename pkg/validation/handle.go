@@ -0,0 +1,66 @@
+package validation
+
+import "strings"
+
+// defaultReservedHandles are never available for registration: route names,
+// staff-sounding names and values that would be confusing in URLs
+var defaultReservedHandles = []string{
+	"about", "admin", "administrator", "api", "app", "assets", "chirps",
+	"chirpy", "help", "healthz", "login", "logout", "me", "metrics",
+	"moderator", "null", "official", "polka", "refresh", "register", "reset",
+	"revoke", "root", "security", "settings", "signup", "staff", "support",
+	"system", "undefined", "users", "webhooks", "well_known", "www",
+}
+
+// ReservedHandles is a case-insensitive set of handles that cannot be claimed
+type ReservedHandles map[string]struct{}
+
+// NewReservedHandles builds the reserved set from the defaults plus any extra
+// handles, such as those configured through RESERVED_HANDLES
+func NewReservedHandles(extra []string) ReservedHandles {
+	reserved := make(ReservedHandles, len(defaultReservedHandles)+len(extra))
+	for _, handle := range append(defaultReservedHandles, extra...) {
+		handle = NormalizeHandle(handle)
+		if handle != "" {
+			reserved[handle] = struct{}{}
+		}
+	}
+	return reserved
+}
+
+// Contains reports whether the handle is reserved
+func (r ReservedHandles) Contains(handle string) bool {
+	_, found := r[NormalizeHandle(handle)]
+	return found
+}
+
+// NormalizeHandle trims whitespace and a leading @ and lowercases the handle
+func NormalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+}
+
+// ValidateHandle validates a normalized handle. Only ASCII letters, digits
+// and underscores are accepted, which rules out lookalike characters such as
+// Cyrillic "а" or fullwidth "ａ" that would let one user impersonate another.
+func ValidateHandle(handle string, reserved ReservedHandles) error {
+	for _, r := range handle {
+		if !isHandleRune(r) {
+			return ErrHandleInvalid
+		}
+	}
+
+	if len(handle) < MinHandleLength || len(handle) > MaxHandleLength {
+		return ErrHandleLength
+	}
+
+	if reserved.Contains(handle) {
+		return ErrHandleReserved
+	}
+
+	return nil
+}
+
+// isHandleRune reports whether r is allowed in a handle
+func isHandleRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_'
+}
@@ -0,0 +1,89 @@
+package validation
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Counting is how the length of a chirp is measured
+type Counting string
+
+const (
+	// CountRunes counts Unicode code points, so an emoji made of several
+	// code points, such as a flag, counts more than once
+	CountRunes Counting = "runes"
+
+	// CountGraphemes counts user-perceived characters: combining marks,
+	// variation selectors, skin tones and joined emoji sequences count
+	// with the character they modify
+	CountGraphemes Counting = "graphemes"
+)
+
+// ParseCounting reads a Counting from configuration, defaulting to runes
+func ParseCounting(raw string) (Counting, error) {
+	switch Counting(raw) {
+	case "", CountRunes:
+		return CountRunes, nil
+	case CountGraphemes:
+		return CountGraphemes, nil
+	}
+	return "", ErrCountingInvalid
+}
+
+// Length measures body; the zero Counting counts runes
+func (c Counting) Length(body string) int {
+	if c != CountGraphemes {
+		return utf8.RuneCountInString(body)
+	}
+	return graphemeCount(body)
+}
+
+// graphemeCount approximates the extended grapheme clusters of Unicode
+// Standard Annex #29 closely enough for the text chirps contain: it joins
+// extending characters and emoji sequences to the character before them,
+// and pairs regional indicators into flags. Hangul syllables spelled with
+// conjoining jamo still count per jamo.
+func graphemeCount(body string) int {
+	count := 0
+	joinNext, oddIndicator := false, false
+	for _, r := range body {
+		switch {
+		case count > 0 && (joinNext || extendsCluster(r)):
+			joinNext = r == zeroWidthJoiner
+		case isRegionalIndicator(r) && oddIndicator:
+			// Second half of a flag
+			oddIndicator = false
+		default:
+			count++
+			oddIndicator = isRegionalIndicator(r)
+			joinNext = r == zeroWidthJoiner
+		}
+	}
+	return count
+}
+
+const zeroWidthJoiner = '\u200d'
+
+// extendsCluster reports whether r attaches to the character before it
+func extendsCluster(r rune) bool {
+	switch {
+	case r == zeroWidthJoiner:
+		return true
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		// Combining marks, including the keycap enclosure
+		return true
+	case unicode.Is(unicode.Variation_Selector, r):
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF:
+		// Emoji skin tone modifiers
+		return true
+	case r >= 0xE0020 && r <= 0xE007F:
+		// Tag characters used by subdivision flags
+		return true
+	}
+	return false
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
@@ -0,0 +1,72 @@
+package validation
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/kai-xlr/neo_chirpy/internal/auth"
+)
+
+// ValidateClientName validates the display name of an OAuth client
+func ValidateClientName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > MaxClientNameLength {
+		return ErrClientNameInvalid
+	}
+	return nil
+}
+
+// ValidateRedirectURIs validates the redirect URIs registered for an OAuth
+// client. Each must be an absolute https URL without a fragment; plain http
+// is accepted only for loopback addresses, for apps under development.
+func ValidateRedirectURIs(uris []string) error {
+	if len(uris) == 0 || len(uris) > MaxRedirectURIs {
+		return ErrRedirectURIsCount
+	}
+	for _, raw := range uris {
+		parsed, err := url.Parse(raw)
+		if err != nil || parsed.Host == "" || parsed.Fragment != "" || parsed.User != nil {
+			return ErrRedirectURIInvalid
+		}
+		switch parsed.Scheme {
+		case "https":
+		case "http":
+			if host := parsed.Hostname(); host != "localhost" && host != "127.0.0.1" && host != "::1" {
+				return ErrRedirectURIInvalid
+			}
+		default:
+			return ErrRedirectURIInvalid
+		}
+	}
+	return nil
+}
+
+// ParseScopes parses a space-separated OAuth scope parameter into known
+// scopes in canonical order. An empty parameter requests read access.
+func ParseScopes(scope string) ([]string, error) {
+	requested := strings.Fields(scope)
+	if len(requested) == 0 {
+		return []string{auth.ScopeRead}, nil
+	}
+
+	var read, write bool
+	for _, s := range requested {
+		switch s {
+		case auth.ScopeRead:
+			read = true
+		case auth.ScopeWrite:
+			write = true
+		default:
+			return nil, ErrScopeInvalid
+		}
+	}
+
+	scopes := make([]string, 0, 2)
+	if read {
+		scopes = append(scopes, auth.ScopeRead)
+	}
+	if write {
+		scopes = append(scopes, auth.ScopeWrite)
+	}
+	return scopes, nil
+}
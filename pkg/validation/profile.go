@@ -0,0 +1,9 @@
+package validation
+
+import "net/url"
+
+// isWebURL reports whether raw is an absolute http or https URL
+func isWebURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	return err == nil && parsed.Host != "" && (parsed.Scheme == "http" || parsed.Scheme == "https")
+}
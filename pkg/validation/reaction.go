@@ -0,0 +1,43 @@
+package validation
+
+import "strings"
+
+// DefaultReactions are offered when no ALLOWED_REACTIONS are configured
+var DefaultReactions = []string{"👍", "❤️", "😂", "😮", "😢", "🎉"}
+
+// Reactions is the ordered set of emoji users may react to chirps with
+type Reactions []string
+
+// NewReactions builds the allowed set from the configured emoji, falling
+// back to DefaultReactions when none are given. Duplicates are dropped.
+func NewReactions(allowed []string) Reactions {
+	if len(allowed) == 0 {
+		allowed = DefaultReactions
+	}
+	reactions := make(Reactions, 0, len(allowed))
+	for _, emoji := range allowed {
+		emoji = strings.TrimSpace(emoji)
+		if emoji != "" && !reactions.Contains(emoji) {
+			reactions = append(reactions, emoji)
+		}
+	}
+	return reactions
+}
+
+// Contains reports whether the emoji is allowed
+func (r Reactions) Contains(emoji string) bool {
+	for _, allowed := range r {
+		if allowed == emoji {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateReaction checks the emoji is one of the allowed reactions
+func ValidateReaction(emoji string, allowed Reactions) error {
+	if !allowed.Contains(strings.TrimSpace(emoji)) {
+		return ErrReactionNotAllowed
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Struct checks the `validate` tags on the fields of a request struct. A tag
+// is a comma-separated list of rules:
+//
+//	required   the field is set: a non-blank string, a non-nil pointer, a
+//	           non-empty slice or map, or any other non-zero value
+//	notblank   a string, when given, isn't only whitespace
+//	max=N      a string is at most N characters once trimmed, or a slice
+//	           has at most N items
+//	email      a string is an email address
+//	url        a string is an absolute http or https URL
+//	oneof=a b  a string is one of the space-separated values
+//
+// Apart from required, nil pointers and empty strings pass every rule, so
+// optional fields need no special casing. Nested structs and slices of
+// structs are checked too. The first failing rule is returned as a
+// *FieldError named after the field's JSON key.
+func Struct(v any) error {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+	return checkStruct(value, "")
+}
+
+// FieldError is a request field that breaks one of its validate rules
+type FieldError struct {
+	Field string
+	Rule  string
+	Param string
+}
+
+func (e *FieldError) Error() string {
+	switch e.Rule {
+	case "required":
+		return e.Field + " is required"
+	case "notblank":
+		return e.Field + " cannot be blank"
+	case "max":
+		return e.Field + " can be at most " + e.Param + " characters"
+	case "maxitems":
+		return e.Field + " can have at most " + e.Param + " items"
+	case "email":
+		return e.Field + " must be a valid email address"
+	case "url":
+		return e.Field + " must be an absolute http or https URL"
+	case "oneof":
+		values := strings.Fields(e.Param)
+		if len(values) == 1 {
+			return e.Field + " must be " + values[0]
+		}
+		last := len(values) - 1
+		return e.Field + " must be one of " + strings.Join(values[:last], ", ") + " or " + values[last]
+	}
+	return e.Field + " is invalid"
+}
+
+// rule is one parsed entry of a validate tag
+type rule struct {
+	name  string
+	param string
+	limit int
+}
+
+// structField is a field of a request struct and the rules it is checked
+// against
+type structField struct {
+	index int
+	name  string
+	rules []rule
+}
+
+// structFields caches the parsed tags of each struct type, so reflection
+// over tags happens once per type rather than once per request
+var structFields sync.Map // reflect.Type -> []structField
+
+func fieldsOf(t reflect.Type) []structField {
+	if cached, ok := structFields.Load(t); ok {
+		return cached.([]structField)
+	}
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, structField{
+			index: i,
+			name:  name,
+			rules: parseRules(t, field.Name, field.Tag.Get("validate")),
+		})
+	}
+	structFields.Store(t, fields)
+	return fields
+}
+
+// parseRules parses a validate tag. A malformed tag is a programming error,
+// so it panics the first time the struct is validated.
+func parseRules(t reflect.Type, fieldName, tag string) []rule {
+	if tag == "" {
+		return nil
+	}
+	var rules []rule
+	for _, entry := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(entry, "=")
+		r := rule{name: name, param: param}
+		switch name {
+		case "required", "notblank", "email", "url":
+		case "max":
+			limit, err := strconv.Atoi(param)
+			if err != nil || limit < 0 {
+				panic(fmt.Sprintf("validation: bad max %q on %s.%s", param, t, fieldName))
+			}
+			r.limit = limit
+		case "oneof":
+			if strings.TrimSpace(param) == "" {
+				panic(fmt.Sprintf("validation: empty oneof on %s.%s", t, fieldName))
+			}
+		default:
+			panic(fmt.Sprintf("validation: unknown rule %q on %s.%s", name, t, fieldName))
+		}
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+func checkStruct(value reflect.Value, prefix string) error {
+	for _, field := range fieldsOf(value.Type()) {
+		fieldValue := value.Field(field.index)
+		path := prefix + field.name
+		for _, r := range field.rules {
+			if err := checkRule(fieldValue, r); err != nil {
+				err.Field = path
+				return err
+			}
+		}
+		if err := checkNested(fieldValue, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkNested descends into struct fields and slices of structs
+func checkNested(value reflect.Value, path string) error {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		return checkStruct(value, path+".")
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := checkNested(value.Index(i), path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkRule(value reflect.Value, r rule) *FieldError {
+	if r.name == "required" {
+		if isBlank(value) {
+			return &FieldError{Rule: r.name}
+		}
+		return nil
+	}
+
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if r.name == "max" && (value.Kind() == reflect.Slice || value.Kind() == reflect.Map) {
+		if value.Len() > r.limit {
+			return &FieldError{Rule: "maxitems", Param: r.param}
+		}
+		return nil
+	}
+	if value.Kind() != reflect.String {
+		return nil
+	}
+
+	text := value.String()
+	if r.name == "notblank" {
+		if strings.TrimSpace(text) == "" {
+			return &FieldError{Rule: r.name}
+		}
+		return nil
+	}
+	if text == "" {
+		return nil
+	}
+
+	var ok bool
+	switch r.name {
+	case "max":
+		ok = utf8.RuneCountInString(strings.TrimSpace(text)) <= r.limit
+	case "email":
+		ok = ValidateEmail(text) == nil
+	case "url":
+		ok = isWebURL(text)
+	case "oneof":
+		ok = false
+		for _, allowed := range strings.Fields(r.param) {
+			if text == allowed {
+				ok = true
+				break
+			}
+		}
+	}
+	if !ok {
+		return &FieldError{Rule: r.name, Param: r.param}
+	}
+	return nil
+}
+
+// isBlank reports whether a required field was left out
+func isBlank(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return true
+		}
+		// A pointer sets its field even to false or zero, but not to an
+		// empty string
+		elem := value.Elem()
+		return elem.Kind() == reflect.String && isBlank(elem)
+	case reflect.String:
+		return strings.TrimSpace(value.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+	return value.IsZero()
+}
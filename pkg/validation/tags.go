@@ -0,0 +1,90 @@
+package validation
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// mentionPattern matches @handle where the @ doesn't follow a word
+	// character, so email addresses aren't mentions
+	mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@(\w+)`)
+
+	// hashtagPattern matches #tag where the # doesn't follow a word character
+	hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_#])#([\p{L}\p{N}_]+)`)
+
+	// hashtagBodyPattern matches a whole tag without the #
+	hashtagBodyPattern = regexp.MustCompile(`^[\p{L}\p{N}_]+$`)
+
+	// languagePattern matches an ISO 639 language code
+	languagePattern = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+	// linkPattern matches an http or https URL up to the next space
+	linkPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"]+`)
+)
+
+// Mentions returns the distinct handles mentioned in a chirp body, lowercased,
+// in the order they first appear
+func Mentions(body string) []string {
+	return distinctMatches(mentionPattern, body)
+}
+
+// Hashtags returns the distinct hashtags in a chirp body, lowercased and
+// without the #, in the order they first appear
+func Hashtags(body string) []string {
+	return distinctMatches(hashtagPattern, body)
+}
+
+// Links returns the distinct http and https URLs in a chirp body, in the
+// order they first appear. Punctuation ending a sentence after a URL isn't
+// part of it.
+func Links(body string) []string {
+	var found []string
+	seen := map[string]bool{}
+	for _, link := range linkPattern.FindAllString(body, -1) {
+		link = strings.TrimRight(link, ".,;:!?)]}'")
+		if !seen[link] {
+			seen[link] = true
+			found = append(found, link)
+		}
+	}
+	return found
+}
+
+// NormalizeHashtag lowercases a tag and strips a leading #, reporting false
+// if what's left isn't a valid tag
+func NormalizeHashtag(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	return tag, hashtagBodyPattern.MatchString(tag)
+}
+
+// NormalizeLanguage lowercases a language code, reporting false if it isn't
+// a two or three letter ISO 639 code
+func NormalizeLanguage(code string) (string, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return code, languagePattern.MatchString(code)
+}
+
+// ValidateChirpTags caps the distinct mentions and hashtags in a chirp body
+func ValidateChirpTags(body string) error {
+	if len(Mentions(body)) > MaxChirpMentions {
+		return ErrTooManyMentions
+	}
+	if len(Hashtags(body)) > MaxChirpHashtags {
+		return ErrTooManyHashtags
+	}
+	return nil
+}
+
+func distinctMatches(pattern *regexp.Regexp, body string) []string {
+	var found []string
+	seen := map[string]bool{}
+	for _, match := range pattern.FindAllStringSubmatch(body, -1) {
+		tag := strings.ToLower(match[1])
+		if !seen[tag] {
+			seen[tag] = true
+			found = append(found, tag)
+		}
+	}
+	return found
+}
@@ -2,7 +2,13 @@ package validation
 
 import (
 	"errors"
+	"net/url"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
 )
 
 var (
@@ -11,23 +17,92 @@ var (
 	ErrEmailInvalid  = errors.New("Invalid email address")
 	ErrEmailEmpty    = errors.New("Email cannot be empty")
 	ErrUserIDInvalid = errors.New("Invalid user ID")
+
+	ErrCountingInvalid = errors.New("Chirp length counting must be runes or graphemes")
+
+	ErrTooManyMutedWords = errors.New("Too many muted words")
+	ErrMutedWordEmpty    = errors.New("Muted word cannot be empty")
+	ErrMutedWordTooLong  = errors.New("Muted word is too long")
+
+	ErrTooManyBannedWords = errors.New("Too many banned words")
+	ErrBannedWordInvalid  = errors.New("Banned words must be single words of letters and numbers")
+	ErrBannedWordTooLong  = errors.New("Banned word is too long")
+
+	ErrWordListKind    = errors.New("Word list is of the wrong kind")
+	ErrWordListVersion = errors.New("Word list version is not supported")
+	ErrImportMode      = errors.New("Import mode must be merge or replace")
+
+	ErrTooManyMedia    = errors.New("Too many media attachments")
+	ErrMediaURLInvalid = errors.New("Media URL must be an absolute http or https URL")
+	ErrAltTextRequired = errors.New("Media attachments require alt text")
+	ErrAltTextTooLong  = errors.New("Alt text is too long")
+
+	ErrChirpDelayInvalid    = errors.New("Delay must be between 0 and 300 seconds")
+	ErrChirpScheduledPast   = errors.New("Scheduled time must be in the future")
+	ErrChirpScheduledTooFar = errors.New("Chirps can be scheduled at most 30 days ahead")
+
+	ErrTooManyMentions = errors.New("Chirps can mention at most 10 users")
+	ErrTooManyHashtags = errors.New("Chirps can have at most 15 hashtags")
+
+	ErrHandleLength   = errors.New("Handle must be between 3 and 15 characters")
+	ErrHandleInvalid  = errors.New("Handle may only contain letters, numbers and underscores")
+	ErrHandleReserved = errors.New("Handle is reserved")
+
+	ErrReactionNotAllowed = errors.New("Reaction is not allowed")
+
+	ErrContentWarningTooLong = errors.New("Content warning can be at most 100 characters")
+
+	ErrClientNameInvalid  = errors.New("Client name must be between 1 and 100 characters")
+	ErrRedirectURIsCount  = errors.New("Clients need between 1 and 10 redirect URIs")
+	ErrRedirectURIInvalid = errors.New("Redirect URIs must be absolute https URLs, or http on localhost")
+	ErrScopeInvalid       = errors.New("Unknown scope")
 )
 
-// ValidateChirpBody validates a chirp body
-func ValidateChirpBody(body string) error {
+// ValidateChirpBody validates a chirp body, measuring its length with counting
+func ValidateChirpBody(body string, counting Counting) error {
 	trimmed := strings.TrimSpace(body)
 
 	if trimmed == "" {
 		return ErrChirpEmpty
 	}
 
-	if len(body) > MaxChirpLength {
+	if counting.Length(body) > MaxChirpLength {
 		return ErrChirpTooLong
 	}
 
 	return nil
 }
 
+// ValidateChirpDelay validates the undo window requested for a new chirp
+func ValidateChirpDelay(delaySeconds int32) error {
+	if delaySeconds < 0 || delaySeconds > MaxChirpDelaySeconds {
+		return ErrChirpDelayInvalid
+	}
+	return nil
+}
+
+// ValidateChirpSchedule validates the time a chirp is scheduled to be
+// published at
+func ValidateChirpSchedule(scheduledAt, now time.Time) error {
+	if !scheduledAt.After(now) {
+		return ErrChirpScheduledPast
+	}
+	if scheduledAt.After(now.AddDate(0, 0, MaxChirpScheduleDays)) {
+		return ErrChirpScheduledTooFar
+	}
+	return nil
+}
+
+// NormalizeContentWarning trims a chirp's content warning and checks its
+// length
+func NormalizeContentWarning(warning string) (string, error) {
+	warning = strings.TrimSpace(warning)
+	if utf8.RuneCountInString(warning) > MaxContentWarning {
+		return "", ErrContentWarningTooLong
+	}
+	return warning, nil
+}
+
 // ValidateEmail validates an email address
 func ValidateEmail(email string) error {
 	trimmed := strings.TrimSpace(email)
@@ -43,3 +118,83 @@ func ValidateEmail(email string) error {
 
 	return nil
 }
+
+// ValidateMutedWords validates a user's list of muted keywords and phrases
+func ValidateMutedWords(words []string) error {
+	if len(words) > MaxMutedWords {
+		return ErrTooManyMutedWords
+	}
+
+	for _, word := range words {
+		trimmed := strings.TrimSpace(word)
+		if trimmed == "" {
+			return ErrMutedWordEmpty
+		}
+		if len(trimmed) > MaxMutedWordLength {
+			return ErrMutedWordTooLong
+		}
+	}
+
+	return nil
+}
+
+// ValidateBannedWords validates the instance's banned word list. Each entry
+// must be one word, as profanity matching only looks at whole words.
+func ValidateBannedWords(words []string) error {
+	if len(words) > MaxBannedWords {
+		return ErrTooManyBannedWords
+	}
+
+	for _, word := range words {
+		if word == "" || strings.IndexFunc(word, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+		}) >= 0 {
+			return ErrBannedWordInvalid
+		}
+		if utf8.RuneCountInString(word) > MaxBannedWordLength {
+			return ErrBannedWordTooLong
+		}
+	}
+
+	return nil
+}
+
+// ValidateWordList checks that an imported word list is of the expected kind
+// and a supported version; its words are validated like the list it goes to
+func ValidateWordList(list types.WordList, kind string) error {
+	if list.Kind != kind {
+		return ErrWordListKind
+	}
+	if list.Version != types.WordListVersion {
+		return ErrWordListVersion
+	}
+	return nil
+}
+
+// ValidateImportMode validates how an imported list is combined with the
+// current one: merged into it (the default) or replacing it
+func ValidateImportMode(mode string) error {
+	if mode != "" && mode != ImportMerge && mode != ImportReplace {
+		return ErrImportMode
+	}
+	return nil
+}
+
+// ValidateMediaAttachment validates a media attachment URL and its alt text.
+// Alt text length is counted in characters rather than bytes.
+func ValidateMediaAttachment(mediaURL, altText string, requireAltText bool) error {
+	parsed, err := url.Parse(strings.TrimSpace(mediaURL))
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return ErrMediaURLInvalid
+	}
+
+	if requireAltText && strings.TrimSpace(altText) == "" {
+		return ErrAltTextRequired
+	}
+
+	if utf8.RuneCountInString(altText) > MaxAltTextLength {
+		return ErrAltTextTooLong
+	}
+
+	return nil
+}
@@ -3,6 +3,9 @@ package validation
 import (
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
 )
 
 func TestValidateChirpBody(t *testing.T) {
@@ -36,11 +39,16 @@ func TestValidateChirpBody(t *testing.T) {
 			body:    strings.Repeat("a", MaxChirpLength),
 			wantErr: nil,
 		},
+		{
+			name:    "multi-byte characters count once",
+			body:    strings.Repeat("🐦", 100),
+			wantErr: nil,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := ValidateChirpBody(tt.body)
+			err := ValidateChirpBody(tt.body, CountRunes)
 			if err != tt.wantErr {
 				t.Errorf("ValidateChirpBody() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -48,6 +56,34 @@ func TestValidateChirpBody(t *testing.T) {
 	}
 }
 
+func TestCountingLength(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		wantRunes     int
+		wantGraphemes int
+	}{
+		{name: "ascii", body: "hello", wantRunes: 5, wantGraphemes: 5},
+		{name: "accented", body: "café", wantRunes: 4, wantGraphemes: 4},
+		{name: "combining mark", body: "cafe\u0301", wantRunes: 5, wantGraphemes: 4},
+		{name: "flag", body: "🇩🇪🇫🇷", wantRunes: 4, wantGraphemes: 2},
+		{name: "skin tone", body: "👋🏽", wantRunes: 2, wantGraphemes: 1},
+		{name: "family", body: "👨\u200d👩\u200d👧", wantRunes: 5, wantGraphemes: 1},
+		{name: "keycap", body: "1\ufe0f\u20e3", wantRunes: 3, wantGraphemes: 1},
+		{name: "leading mark", body: "\u0301a", wantRunes: 2, wantGraphemes: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CountRunes.Length(tt.body); got != tt.wantRunes {
+				t.Errorf("runes = %d, want %d", got, tt.wantRunes)
+			}
+			if got := CountGraphemes.Length(tt.body); got != tt.wantGraphemes {
+				t.Errorf("graphemes = %d, want %d", got, tt.wantGraphemes)
+			}
+		})
+	}
+}
+
 func TestValidateEmail(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -95,3 +131,476 @@ func TestValidateEmail(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateMutedWords(t *testing.T) {
+	tooMany := make([]string, MaxMutedWords+1)
+	for i := range tooMany {
+		tooMany[i] = "word"
+	}
+
+	tests := []struct {
+		name    string
+		words   []string
+		wantErr error
+	}{
+		{
+			name:    "valid words",
+			words:   []string{"spoilers", "hot take"},
+			wantErr: nil,
+		},
+		{
+			name:    "empty list",
+			words:   []string{},
+			wantErr: nil,
+		},
+		{
+			name:    "whitespace only word",
+			words:   []string{"spoilers", "  "},
+			wantErr: ErrMutedWordEmpty,
+		},
+		{
+			name:    "word too long",
+			words:   []string{strings.Repeat("a", MaxMutedWordLength+1)},
+			wantErr: ErrMutedWordTooLong,
+		},
+		{
+			name:    "too many words",
+			words:   tooMany,
+			wantErr: ErrTooManyMutedWords,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMutedWords(tt.words)
+			if err != tt.wantErr {
+				t.Errorf("ValidateMutedWords() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMediaAttachment(t *testing.T) {
+	tests := []struct {
+		name           string
+		url            string
+		altText        string
+		requireAltText bool
+		wantErr        error
+	}{
+		{
+			name:    "valid attachment",
+			url:     "https://cdn.example.com/cat.png",
+			altText: "A cat asleep on a keyboard",
+			wantErr: nil,
+		},
+		{
+			name:    "missing alt text allowed",
+			url:     "https://cdn.example.com/cat.png",
+			altText: "",
+			wantErr: nil,
+		},
+		{
+			name:           "missing alt text required",
+			url:            "https://cdn.example.com/cat.png",
+			altText:        "   ",
+			requireAltText: true,
+			wantErr:        ErrAltTextRequired,
+		},
+		{
+			name:    "relative url",
+			url:     "/uploads/cat.png",
+			altText: "A cat",
+			wantErr: ErrMediaURLInvalid,
+		},
+		{
+			name:    "unsupported scheme",
+			url:     "javascript:alert(1)",
+			altText: "A cat",
+			wantErr: ErrMediaURLInvalid,
+		},
+		{
+			name:    "alt text at max length",
+			url:     "https://cdn.example.com/cat.png",
+			altText: strings.Repeat("é", MaxAltTextLength),
+			wantErr: nil,
+		},
+		{
+			name:    "alt text too long",
+			url:     "https://cdn.example.com/cat.png",
+			altText: strings.Repeat("a", MaxAltTextLength+1),
+			wantErr: ErrAltTextTooLong,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateMediaAttachment(tt.url, tt.altText, tt.requireAltText)
+			if err != tt.wantErr {
+				t.Errorf("ValidateMediaAttachment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateChirpDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		delay   int32
+		wantErr error
+	}{
+		{name: "no delay", delay: 0, wantErr: nil},
+		{name: "within window", delay: 30, wantErr: nil},
+		{name: "at max window", delay: MaxChirpDelaySeconds, wantErr: nil},
+		{name: "negative delay", delay: -1, wantErr: ErrChirpDelayInvalid},
+		{name: "beyond max window", delay: MaxChirpDelaySeconds + 1, wantErr: ErrChirpDelayInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateChirpDelay(tt.delay)
+			if err != tt.wantErr {
+				t.Errorf("ValidateChirpDelay() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateChirpSchedule(t *testing.T) {
+	now := time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		scheduledAt time.Time
+		wantErr     error
+	}{
+		{name: "next minute", scheduledAt: now.Add(time.Minute), wantErr: nil},
+		{name: "at max window", scheduledAt: now.AddDate(0, 0, MaxChirpScheduleDays), wantErr: nil},
+		{name: "now", scheduledAt: now, wantErr: ErrChirpScheduledPast},
+		{name: "in the past", scheduledAt: now.Add(-time.Hour), wantErr: ErrChirpScheduledPast},
+		{name: "beyond max window", scheduledAt: now.AddDate(0, 0, MaxChirpScheduleDays).Add(time.Second), wantErr: ErrChirpScheduledTooFar},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateChirpSchedule(tt.scheduledAt, now)
+			if err != tt.wantErr {
+				t.Errorf("ValidateChirpSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateHandle(t *testing.T) {
+	reserved := NewReservedHandles([]string{" Chirpy_Team ", ""})
+
+	tests := []struct {
+		name    string
+		handle  string
+		wantErr error
+	}{
+		{name: "valid handle", handle: "kai_xlr", wantErr: nil},
+		{name: "digits allowed", handle: "user42", wantErr: nil},
+		{name: "at min length", handle: strings.Repeat("a", MinHandleLength), wantErr: nil},
+		{name: "at max length", handle: strings.Repeat("a", MaxHandleLength), wantErr: nil},
+		{name: "too short", handle: "ab", wantErr: ErrHandleLength},
+		{name: "too long", handle: strings.Repeat("a", MaxHandleLength+1), wantErr: ErrHandleLength},
+		{name: "hyphen rejected", handle: "kai-xlr", wantErr: ErrHandleInvalid},
+		{name: "cyrillic lookalike rejected", handle: "аdmin", wantErr: ErrHandleInvalid},
+		{name: "fullwidth lookalike rejected", handle: "ａｄｍｉｎ", wantErr: ErrHandleInvalid},
+		{name: "default reserved", handle: "admin", wantErr: ErrHandleReserved},
+		{name: "configured reserved", handle: "chirpy_team", wantErr: ErrHandleReserved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHandle(tt.handle, reserved)
+			if err != tt.wantErr {
+				t.Errorf("ValidateHandle() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeHandle(t *testing.T) {
+	if got := NormalizeHandle("  @Kai_XLR "); got != "kai_xlr" {
+		t.Errorf("NormalizeHandle() = %q, want %q", got, "kai_xlr")
+	}
+}
+
+func TestValidateReaction(t *testing.T) {
+	allowed := NewReactions([]string{" 🔥 ", "👍", "🔥", ""})
+
+	tests := []struct {
+		name    string
+		emoji   string
+		wantErr error
+	}{
+		{name: "configured reaction", emoji: "🔥", wantErr: nil},
+		{name: "surrounding whitespace ignored", emoji: " 👍 ", wantErr: nil},
+		{name: "default not configured", emoji: "😂", wantErr: ErrReactionNotAllowed},
+		{name: "empty", emoji: "", wantErr: ErrReactionNotAllowed},
+		{name: "text", emoji: "like", wantErr: ErrReactionNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateReaction(tt.emoji, allowed)
+			if err != tt.wantErr {
+				t.Errorf("ValidateReaction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if len(allowed) != 2 {
+		t.Errorf("NewReactions() kept %d reactions, want 2", len(allowed))
+	}
+	if got := NewReactions(nil); len(got) != len(DefaultReactions) {
+		t.Errorf("NewReactions(nil) = %v, want defaults", got)
+	}
+}
+
+func TestValidateBannedWords(t *testing.T) {
+	tests := []struct {
+		name    string
+		words   []string
+		wantErr error
+	}{
+		{name: "empty list", words: nil, wantErr: nil},
+		{name: "words", words: []string{"kerfuffle", "Sharbert", "h4x0r", "énorme"}, wantErr: nil},
+		{name: "empty word", words: []string{""}, wantErr: ErrBannedWordInvalid},
+		{name: "phrase", words: []string{"bad word"}, wantErr: ErrBannedWordInvalid},
+		{name: "punctuation", words: []string{"kerfuffle!"}, wantErr: ErrBannedWordInvalid},
+		{name: "too long", words: []string{strings.Repeat("a", MaxBannedWordLength+1)}, wantErr: ErrBannedWordTooLong},
+		{name: "too many", words: make([]string, MaxBannedWords+1), wantErr: ErrTooManyBannedWords},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateBannedWords(tt.words); err != tt.wantErr {
+				t.Errorf("ValidateBannedWords() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRedirectURIs(t *testing.T) {
+	tests := []struct {
+		name    string
+		uris    []string
+		wantErr error
+	}{
+		{name: "https", uris: []string{"https://app.example.com/callback"}, wantErr: nil},
+		{name: "loopback http", uris: []string{"http://localhost:8080/cb", "http://127.0.0.1/cb"}, wantErr: nil},
+		{name: "none", uris: nil, wantErr: ErrRedirectURIsCount},
+		{name: "too many", uris: make([]string, MaxRedirectURIs+1), wantErr: ErrRedirectURIsCount},
+		{name: "remote http", uris: []string{"http://app.example.com/callback"}, wantErr: ErrRedirectURIInvalid},
+		{name: "relative", uris: []string{"/callback"}, wantErr: ErrRedirectURIInvalid},
+		{name: "fragment", uris: []string{"https://app.example.com/cb#token"}, wantErr: ErrRedirectURIInvalid},
+		{name: "custom scheme", uris: []string{"javascript://app.example.com/%0Aalert(1)"}, wantErr: ErrRedirectURIInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRedirectURIs(tt.uris)
+			if err != tt.wantErr {
+				t.Errorf("ValidateRedirectURIs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	tests := []struct {
+		scope   string
+		want    string
+		wantErr error
+	}{
+		{scope: "", want: "read"},
+		{scope: "write", want: "write"},
+		{scope: "write read write", want: "read write"},
+		{scope: "read admin", wantErr: ErrScopeInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			got, err := ParseScopes(tt.scope)
+			if err != tt.wantErr {
+				t.Fatalf("ParseScopes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(got, " ") != tt.want {
+				t.Errorf("ParseScopes() = %v, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMentionsAndHashtags(t *testing.T) {
+	body := "@Kai and @kai, mail kai@example.com about #Go #go #café_2 and issue#3 @sam"
+	if got, want := strings.Join(Mentions(body), ","), "kai,sam"; got != want {
+		t.Errorf("Mentions() = %q, want %q", got, want)
+	}
+	if got, want := strings.Join(Hashtags(body), ","), "go,café_2"; got != want {
+		t.Errorf("Hashtags() = %q, want %q", got, want)
+	}
+}
+
+func TestLinks(t *testing.T) {
+	body := "Read https://example.com/a?b=1. Then (see http://go.dev/doc) and https://example.com/a?b=1, not ftp://example.com"
+	if got, want := strings.Join(Links(body), ","), "https://example.com/a?b=1,http://go.dev/doc"; got != want {
+		t.Errorf("Links() = %q, want %q", got, want)
+	}
+}
+
+func TestValidateChirpTags(t *testing.T) {
+	tags := func(prefix string, n int) string {
+		parts := make([]string, n)
+		for i := range parts {
+			parts[i] = prefix + string(rune('a'+i))
+		}
+		return strings.Join(parts, " ")
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{name: "no tags", body: "just chirping"},
+		{name: "max mentions", body: tags("@u", MaxChirpMentions)},
+		{name: "repeated mentions count once", body: strings.Repeat("@ua ", MaxChirpMentions+1)},
+		{name: "too many mentions", body: tags("@u", MaxChirpMentions+1), wantErr: ErrTooManyMentions},
+		{name: "max hashtags", body: tags("#t", MaxChirpHashtags)},
+		{name: "too many hashtags", body: tags("#t", MaxChirpHashtags+1), wantErr: ErrTooManyHashtags},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateChirpTags(tt.body); err != tt.wantErr {
+				t.Errorf("ValidateChirpTags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateWordList(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    types.WordList
+		wantErr error
+	}{
+		{name: "muted words", list: types.WordList{Kind: types.WordListMuted, Version: types.WordListVersion}},
+		{name: "other kind", list: types.WordList{Kind: types.WordListBanned, Version: types.WordListVersion}, wantErr: ErrWordListKind},
+		{name: "missing kind", list: types.WordList{Version: types.WordListVersion}, wantErr: ErrWordListKind},
+		{name: "newer version", list: types.WordList{Kind: types.WordListMuted, Version: types.WordListVersion + 1}, wantErr: ErrWordListVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateWordList(tt.list, types.WordListMuted); err != tt.wantErr {
+				t.Errorf("ValidateWordList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeHashtag(t *testing.T) {
+	tests := []struct {
+		tag    string
+		want   string
+		wantOK bool
+	}{
+		{tag: "golang", want: "golang", wantOK: true},
+		{tag: " #GoLang ", want: "golang", wantOK: true},
+		{tag: "café_2", want: "café_2", wantOK: true},
+		{tag: "go-lang", wantOK: false},
+		{tag: "#", wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := NormalizeHashtag(tt.tag)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("NormalizeHashtag(%q) = %q, %v, want %q, %v", tt.tag, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestStruct(t *testing.T) {
+	type item struct {
+		URL string `json:"url" validate:"required,url"`
+	}
+	type request struct {
+		Name    string  `json:"name" validate:"required,max=5"`
+		Email   *string `json:"email" validate:"notblank,email"`
+		Status  string  `json:"status" validate:"oneof=open closed"`
+		Enabled *bool   `json:"enabled" validate:"required"`
+		Items   []item  `json:"items" validate:"max=2"`
+	}
+	str := func(s string) *string { return &s }
+	enabled := false
+
+	tests := []struct {
+		name    string
+		request request
+		want    string
+	}{
+		{name: "valid", request: request{Name: "abc", Enabled: &enabled}},
+		{name: "optional fields set", request: request{Name: "héllo", Email: str("a@b.co"), Status: "open", Enabled: &enabled, Items: []item{{URL: "https://example.com"}}}},
+		{name: "missing required", request: request{Name: "  ", Enabled: &enabled}, want: "name is required"},
+		{name: "too long", request: request{Name: "abcdef", Enabled: &enabled}, want: "name can be at most 5 characters"},
+		{name: "blank pointer", request: request{Name: "abc", Email: str(""), Enabled: &enabled}, want: "email cannot be blank"},
+		{name: "bad email", request: request{Name: "abc", Email: str("nope"), Enabled: &enabled}, want: "email must be a valid email address"},
+		{name: "not one of", request: request{Name: "abc", Status: "pending", Enabled: &enabled}, want: "status must be one of open or closed"},
+		{name: "nil required pointer", request: request{Name: "abc"}, want: "enabled is required"},
+		{name: "too many items", request: request{Name: "abc", Enabled: &enabled, Items: make([]item, 3)}, want: "items can have at most 2 items"},
+		{name: "nested", request: request{Name: "abc", Enabled: &enabled, Items: []item{{URL: "ftp://example.com"}}}, want: "items[0].url must be an absolute http or https URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Struct(&tt.request)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Struct() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Struct() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestStructRequests(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name    string
+		request any
+		want    string
+	}{
+		{name: "report", request: &types.ReportRequest{Reason: types.ReportSpam}},
+		{name: "report without reason", request: &types.ReportRequest{}, want: "reason is required"},
+		{name: "report unknown reason", request: &types.ReportRequest{Reason: "boring"}, want: "reason must be one of spam, harassment, hate, violence, sexual, misinformation or other"},
+		{name: "report details too long", request: &types.ReportRequest{Reason: types.ReportOther, Details: strings.Repeat("a", MaxReportDetails+1)}, want: "details can be at most 1000 characters"},
+		{name: "resolution", request: &types.ReportResolveRequest{Resolution: types.ReportRemoved}},
+		{name: "resolution unknown", request: &types.ReportResolveRequest{Resolution: "ignored"}, want: "resolution must be one of dismissed, locked or removed"},
+		{name: "preferences", request: &types.UserPreferences{SensitiveContent: types.SensitiveHide}},
+		{name: "preferences unknown", request: &types.UserPreferences{SensitiveContent: "peek"}, want: "sensitive_content must be one of hide, blur or show"},
+		{name: "user", request: &types.UserRequest{Email: "ada@example.com", Password: "secret"}},
+		{name: "user bad email", request: &types.UserRequest{Email: "ada", Password: "secret"}, want: "email must be a valid email address"},
+		{name: "profile", request: &types.UserUpdateRequest{Email: "ada@example.com", Password: "secret", Website: str("https://ada.example"), AvatarURL: str("")}},
+		{name: "display name too long", request: &types.UserUpdateRequest{Email: "ada@example.com", Password: "secret", DisplayName: str(strings.Repeat("a", MaxDisplayNameLength+1))}, want: "display_name can be at most 50 characters"},
+		{name: "website scheme", request: &types.UserUpdateRequest{Email: "ada@example.com", Password: "secret", Website: str("javascript:alert(1)")}, want: "website must be an absolute http or https URL"},
+		{name: "too many chirp ids", request: &types.ChirpBatchDeleteRequest{ChirpIDs: make([]string, 101)}, want: "chirp_ids can have at most 100 items"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Struct(tt.request)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Struct() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Struct() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
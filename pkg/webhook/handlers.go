@@ -1,11 +1,17 @@
 package webhook
 
 import (
-	"encoding/json"
+	"context"
+	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/kai-xlr/neo_chirpy/internal/auth"
 	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/internal/httpclient"
+	"github.com/kai-xlr/neo_chirpy/internal/jobs"
+	"github.com/kai-xlr/neo_chirpy/internal/journal"
 	"github.com/kai-xlr/neo_chirpy/pkg/handlers"
 	"github.com/kai-xlr/neo_chirpy/pkg/types"
 )
@@ -14,6 +20,17 @@ import (
 type Config struct {
 	DB       *database.Queries
 	PolkaKey string
+	Events   *events.Bus
+
+	// Journal records every upgrade outside the database so it can be
+	// replayed after a restore; nil records nothing
+	Journal *journal.Journal
+
+	// Client and Jobs send moderation webhook deliveries and retry them.
+	// Webhook URLs come from tenant admins, so Client should have
+	// PublicOnly set to keep deliveries away from internal hosts.
+	Client *httpclient.Client
+	Jobs   *jobs.Runner
 }
 
 // HandlerPolkaWebhooks handles POST /api/polka/webhooks requests
@@ -34,11 +51,14 @@ func (cfg *Config) HandlerPolkaWebhooks(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Parse JSON from request body
+	// Parse JSON from request body, keeping the payload for the journal
+	payload, readErr := io.ReadAll(r.Body)
+	if readErr != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgDecodeParams, readErr)
+		return
+	}
 	var request types.WebhookRequest
-	decodeErr := json.NewDecoder(r.Body).Decode(&request)
-	if decodeErr != nil {
-		handlers.RespondWithError(w, http.StatusInternalServerError, types.ErrMsgDecodeParams, decodeErr)
+	if !handlers.UnmarshalJSON(w, payload, &request) {
 		return
 	}
 
@@ -49,7 +69,7 @@ func (cfg *Config) HandlerPolkaWebhooks(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Upgrade user to Chirpy Red
-	_, err = cfg.DB.UpgradeUserToChirpyRed(r.Context(), request.Data.UserID)
+	upgraded, err := cfg.DB.UpgradeUserToChirpyRed(r.Context(), request.Data.UserID)
 	if err != nil {
 		if err.Error() == "no rows in result set" || err.Error() == "sql: no rows in result set" {
 			handlers.RespondWithError(w, http.StatusNotFound, "User not found", err)
@@ -59,6 +79,62 @@ func (cfg *Config) HandlerPolkaWebhooks(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// An upgrade missing from the journal fails the webhook, so Polka
+	// retries it; upgrading again is harmless
+	if cfg.Journal != nil {
+		err = cfg.Journal.Record(journal.Entry{
+			Time:     upgraded.UpdatedAt,
+			Source:   "polka",
+			Event:    request.Event,
+			Mutation: journal.MutationUserUpgraded,
+			UserID:   upgraded.ID,
+			Payload:  payload,
+		})
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Couldn't record upgrade in the journal", err)
+			return
+		}
+	}
+
+	cfg.Events.Publish(events.Event{
+		Type:   events.UserUpgraded,
+		UserID: request.Data.UserID,
+	})
+
 	// Return 204 No Content for successful upgrade
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// ReplayResult counts the journal entries a replay applied and skipped
+type ReplayResult struct {
+	Applied int
+	Skipped int
+}
+
+// Replay reapplies journal entries in the order they were recorded, such as
+// after restoring a backup taken before them. Each entry sets the state it
+// recorded rather than repeating the webhook, so replaying twice changes
+// nothing. Entries for users that no longer exist are skipped.
+func (cfg *Config) Replay(ctx context.Context, entries []journal.Entry) (ReplayResult, error) {
+	var result ReplayResult
+	for _, entry := range entries {
+		switch entry.Mutation {
+		case journal.MutationUserUpgraded:
+			updated, err := cfg.DB.ReplayUserUpgrade(ctx, database.ReplayUserUpgradeParams{
+				UpgradedAt: entry.Time,
+				ID:         entry.UserID,
+			})
+			if err != nil {
+				return result, fmt.Errorf("replaying upgrade of user %s: %w", entry.UserID, err)
+			}
+			if updated == 0 {
+				result.Skipped++
+				continue
+			}
+			result.Applied++
+		default:
+			return result, fmt.Errorf("unknown journal mutation %q", entry.Mutation)
+		}
+	}
+	return result, nil
+}
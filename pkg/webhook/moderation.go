@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/events"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+// SignatureHeader carries the signature of a moderation webhook delivery
+const SignatureHeader = "X-Chirpy-Signature"
+
+// deliveryAttempts is how many times a delivery is queued before it is
+// dropped; the HTTP client retries each attempt on its own as well
+const deliveryAttempts = 5
+
+// moderationEvents maps the bus events moderation webhooks receive to the
+// names they are delivered under
+var moderationEvents = map[events.Type]string{
+	events.ChirpReported: types.WebhookReportCreated,
+	events.ChirpRemoved:  types.WebhookChirpRemoved,
+	events.UserSuspended: types.WebhookUserSuspended,
+}
+
+// SubscribeModeration delivers report, removal and suspension events to the community's
+// moderation webhooks until the returned function is called. Events relayed
+// from other replicas are left to the replica that published them, so each
+// is delivered once.
+func (cfg *Config) SubscribeModeration() func() {
+	return cfg.Events.Subscribe(func(ctx context.Context, event events.Event) {
+		if event.Remote {
+			return
+		}
+		if err := cfg.deliverModeration(ctx, event); err != nil {
+			log.Printf("Couldn't deliver %s to moderation webhooks: %s", event.Type, err)
+		}
+	}, events.ChirpReported, events.ChirpRemoved, events.UserSuspended)
+}
+
+// deliverModeration queues a signed delivery of the event to every webhook
+// of its community subscribed to it
+func (cfg *Config) deliverModeration(ctx context.Context, event events.Event) error {
+	name := moderationEvents[event.Type]
+	hooks, err := cfg.DB.GetModerationWebhooksForEvent(ctx, database.GetModerationWebhooksForEventParams{
+		TenantID: event.TenantID,
+		Event:    name,
+	})
+	if err != nil || len(hooks) == 0 {
+		return err
+	}
+
+	delivery := types.ModerationWebhookEvent{
+		ID:         uuid.New(),
+		Event:      name,
+		OccurredAt: types.NewTimestamp(event.OccurredAt),
+		UserID:     event.UserID,
+	}
+	if event.ChirpID != uuid.Nil {
+		delivery.ChirpID = &event.ChirpID
+	}
+	payload, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+	for _, hook := range hooks {
+		cfg.Jobs.Enqueue("moderation-webhook", deliveryAttempts, func(ctx context.Context) error {
+			return cfg.deliver(ctx, hook, name, payload)
+		})
+	}
+	return nil
+}
+
+// deliver posts a signed payload to a webhook. Anything but a 2xx response
+// fails the attempt so it is retried.
+func (cfg *Config) deliver(ctx context.Context, hook database.ModerationWebhook, event string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.Url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Chirpy-Event", event)
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, time.Now(), payload))
+
+	resp, err := cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s responded with %s", hook.ID, resp.Status)
+	}
+	return nil
+}
+
+// Sign returns the signature header of a delivery sent at the given time:
+// t=<unix seconds>,v1=<hex HMAC-SHA256 of "<unix seconds>.<payload>">.
+// Receivers recompute it with the webhook's secret and should reject old
+// timestamps, so a captured delivery can't be replayed later.
+func Sign(secret string, at time.Time, payload []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kai-xlr/neo_chirpy/internal/database"
+	"github.com/kai-xlr/neo_chirpy/internal/httpclient"
+	"github.com/kai-xlr/neo_chirpy/pkg/types"
+)
+
+func TestSign(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	payload := []byte(`{"event":"report.created"}`)
+
+	signature := Sign("secret", at, payload)
+	if !strings.HasPrefix(signature, "t=1700000000,v1=") {
+		t.Fatalf("Sign() = %q, want t=1700000000,v1=...", signature)
+	}
+	if Sign("secret", at, payload) != signature {
+		t.Error("Sign() isn't deterministic")
+	}
+	if Sign("other", at, payload) == signature {
+		t.Error("signature doesn't depend on the secret")
+	}
+	if Sign("secret", at.Add(time.Second), payload) == signature {
+		t.Error("signature doesn't depend on the timestamp")
+	}
+	if Sign("secret", at, []byte(`{"event":"chirp.removed"}`)) == signature {
+		t.Error("signature doesn't depend on the payload")
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	payload := []byte(`{"event":"chirp.removed"}`)
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "accepted", status: http.StatusNoContent},
+		{name: "rejected", status: http.StatusGone, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotEvent, gotSignature string
+			var gotBody []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotEvent, gotSignature = r.Header.Get("X-Chirpy-Event"), r.Header.Get(SignatureHeader)
+				gotBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			cfg := &Config{Client: httpclient.New(httpclient.DefaultConfig())}
+			hook := database.ModerationWebhook{ID: uuid.New(), Url: server.URL, Secret: "secret"}
+			err := cfg.deliver(context.Background(), hook, types.WebhookChirpRemoved, payload)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("deliver() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			if gotEvent != types.WebhookChirpRemoved || string(gotBody) != string(payload) {
+				t.Errorf("received %s %s, want %s %s", gotEvent, gotBody, types.WebhookChirpRemoved, payload)
+			}
+			timestamp, _, _ := strings.Cut(strings.TrimPrefix(gotSignature, "t="), ",")
+			sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				t.Fatalf("signature %q has no timestamp", gotSignature)
+			}
+			if want := Sign(hook.Secret, time.Unix(sentAt, 0), payload); gotSignature != want {
+				t.Errorf("signature = %q, want %q", gotSignature, want)
+			}
+		})
+	}
+}
+
+func TestDeliverRefusesInternalHosts(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	clientCfg := httpclient.DefaultConfig()
+	clientCfg.PublicOnly = true
+	cfg := &Config{Client: httpclient.New(clientCfg)}
+	hook := database.ModerationWebhook{ID: uuid.New(), Url: server.URL, Secret: "secret"}
+	err := cfg.deliver(context.Background(), hook, types.WebhookChirpRemoved, []byte(`{}`))
+	if !errors.Is(err, httpclient.ErrNonPublicAddress) {
+		t.Fatalf("deliver() error = %v, want %v", err, httpclient.ErrNonPublicAddress)
+	}
+	if called {
+		t.Error("webhook on a loopback address was called")
+	}
+}
@@ -0,0 +1,28 @@
+-- name: CancelAccountDeletion :exec
+DELETE FROM account_deletions
+WHERE user_id = $1;
+
+-- name: DeleteScheduledAccounts :execrows
+-- Accounts under legal hold are kept until the hold is released
+DELETE FROM users
+WHERE id IN (SELECT user_id FROM account_deletions WHERE delete_after < NOW())
+  AND NOT legal_hold;
+
+-- name: GetAccountDeletion :one
+SELECT * FROM account_deletions
+WHERE user_id = $1;
+
+-- name: ScheduleAccountDeletion :one
+-- Deactivates the account and schedules its deletion. Asking again keeps the
+-- earlier deadline.
+WITH deactivated AS (
+    UPDATE users
+    SET deactivated_at = COALESCE(deactivated_at, NOW()), updated_at = NOW()
+    WHERE id = sqlc.arg(user_id)
+    RETURNING id
+)
+INSERT INTO account_deletions (user_id, requested_at, delete_after)
+SELECT id, NOW(), sqlc.arg(delete_after)::timestamp FROM deactivated
+ON CONFLICT (user_id) DO UPDATE
+SET delete_after = LEAST(account_deletions.delete_after, EXCLUDED.delete_after)
+RETURNING *;
@@ -0,0 +1,41 @@
+-- name: RecordAccountFingerprint :exec
+INSERT INTO account_fingerprints (user_id, kind, hash, first_seen_at, last_seen_at)
+VALUES ($1, $2, $3, NOW(), NOW())
+ON CONFLICT (user_id, kind, hash) DO UPDATE SET last_seen_at = NOW();
+
+-- name: PurgeStaleAccountFingerprints :execrows
+DELETE FROM account_fingerprints
+WHERE last_seen_at < sqlc.arg(cutoff)::timestamp;
+
+-- name: GetBanEvasionMatches :many
+-- Accounts sharing a fingerprint with an account suspended over SCIM, one
+-- row per pair with the kinds they share. Pages through suspended accounts
+-- rather than rows, so each one's matches stay together.
+WITH suspended AS (
+    SELECT users.id
+    FROM users
+    JOIN scim_users ON scim_users.user_id = users.id AND scim_users.suspended
+    WHERE users.tenant_id = sqlc.arg(tenant_id)
+      AND EXISTS (
+        SELECT 1
+        FROM account_fingerprints AS own
+        JOIN account_fingerprints AS other
+          ON other.kind = own.kind AND other.hash = own.hash AND other.user_id <> own.user_id
+        JOIN users AS other_users ON other_users.id = other.user_id
+        WHERE own.user_id = users.id AND other_users.tenant_id = sqlc.arg(tenant_id)
+      )
+    ORDER BY users.id
+    LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset)
+)
+SELECT suspended.id AS suspended_user_id,
+       other.user_id,
+       users.username,
+       (array_agg(DISTINCT other.kind ORDER BY other.kind))::text[] AS shared,
+       MAX(other.last_seen_at)::timestamp AS last_seen_at
+FROM suspended
+JOIN account_fingerprints AS own ON own.user_id = suspended.id
+JOIN account_fingerprints AS other
+  ON other.kind = own.kind AND other.hash = own.hash AND other.user_id <> own.user_id
+JOIN users ON users.id = other.user_id AND users.tenant_id = sqlc.arg(tenant_id)
+GROUP BY suspended.id, other.user_id, users.username
+ORDER BY suspended.id, last_seen_at DESC, other.user_id;
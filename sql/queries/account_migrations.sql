@@ -0,0 +1,29 @@
+-- name: ClaimAccountMigration :execrows
+-- Records an import of a bundle; affects no rows if it was already imported
+INSERT INTO account_migrations (origin, source_account, user_id, imported_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteAccountMigration :exec
+DELETE FROM account_migrations
+WHERE origin = $1 AND source_account = $2;
+
+-- name: ImportChirps :many
+-- Inserts the chirps of an imported bundle in one statement, so they are
+-- imported all together or not at all
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, language, content_warning)
+SELECT gen_random_uuid(), imported.created_at, imported.created_at, imported.body, sqlc.arg(user_id), imported.created_at,
+       sqlc.arg(tenant_id), imported.sensitive, 'migration', imported.language, imported.content_warning
+FROM (
+    SELECT unnest(sqlc.arg(bodies)::text[]) AS body,
+           unnest(sqlc.arg(created_ats)::timestamp[]) AS created_at,
+           unnest(sqlc.arg(sensitive)::boolean[]) AS sensitive,
+           unnest(sqlc.arg(languages)::text[]) AS language,
+           unnest(sqlc.arg(content_warnings)::text[]) AS content_warning
+) AS imported
+RETURNING *;
+
+-- name: SetUsernameIfUnset :execrows
+UPDATE users
+SET username = $2, updated_at = NOW()
+WHERE id = $1 AND username IS NULL;
@@ -0,0 +1,38 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (id, created_at, key_hash, name, tier, tenant_id)
+VALUES (gen_random_uuid(), NOW(), $1, $2, $3, $4)
+RETURNING *;
+
+-- name: ListAPIKeys :many
+SELECT * FROM api_keys
+WHERE tenant_id = $1
+ORDER BY created_at ASC;
+
+-- name: RevokeAPIKey :execrows
+UPDATE api_keys SET revoked_at = NOW()
+WHERE id = $1 AND tenant_id = $2 AND revoked_at IS NULL;
+
+-- name: UseAPIKey :one
+-- Looks up an active key by hash and counts the request against it
+UPDATE api_keys SET requests = requests + 1, last_used_at = NOW()
+WHERE key_hash = $1 AND revoked_at IS NULL
+RETURNING *;
+
+-- name: AddAPIKeyChirpsDelivered :exec
+UPDATE api_keys SET chirps_delivered = chirps_delivered + sqlc.arg(chirps)::bigint
+WHERE id = sqlc.arg(id);
+
+-- name: GetChirpsPublishedSince :many
+-- Pages by (published_at, id) so chirps published in the same instant
+-- aren't skipped. Pass the last chirp seen, or uuid.Max with a bare time.
+SELECT * FROM chirps
+WHERE chirps.tenant_id = sqlc.arg(tenant_id)
+  AND (published_at, id) > (sqlc.arg(published_at)::timestamp, sqlc.arg(after_id)::uuid)
+  AND published_at <= NOW()
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY published_at ASC, id ASC
+LIMIT sqlc.arg(max_chirps);
@@ -0,0 +1,106 @@
+-- name: CreateAppeal :one
+-- Returns no row unless the user's chirp was removed by a moderator or
+-- hidden by a remove verdict that wasn't overturned, and has no appeal
+-- awaiting a decision or denied
+INSERT INTO appeals (id, created_at, tenant_id, user_id, chirp_id, reason)
+SELECT gen_random_uuid(), NOW(), chirps.tenant_id, chirps.user_id, chirps.id, sqlc.arg(reason)
+FROM chirps
+WHERE chirps.id = sqlc.arg(chirp_id) AND chirps.user_id = sqlc.arg(user_id) AND chirps.tenant_id = sqlc.arg(tenant_id)
+  AND chirps.deleted_at IS NOT NULL
+  AND (
+    EXISTS (
+      SELECT 1 FROM reports
+      WHERE reports.chirp_id = chirps.id AND reports.resolution = 'removed'
+    )
+    OR EXISTS (
+      SELECT 1 FROM moderation_verdicts
+      WHERE moderation_verdicts.chirp_id = chirps.id AND moderation_verdicts.verdict = 'remove'
+        AND moderation_verdicts.outcome IS DISTINCT FROM 'overturned'
+    )
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM appeals
+    WHERE appeals.chirp_id = chirps.id AND (appeals.decided_at IS NULL OR appeals.decision = 'denied')
+  )
+ON CONFLICT (chirp_id) WHERE decided_at IS NULL DO NOTHING
+RETURNING *;
+
+-- name: GetAppeal :one
+SELECT * FROM appeals
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: GetUserAppeals :many
+-- The user's appeals, newest first
+SELECT * FROM appeals
+WHERE user_id = $1 AND tenant_id = $2
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetAppeals :many
+-- Undecided appeals oldest first, which is the review queue, or decided
+-- ones most recently decided first
+SELECT sqlc.embed(appeals), chirps.body AS chirp_body
+FROM appeals
+JOIN chirps ON chirps.id = appeals.chirp_id
+WHERE appeals.tenant_id = sqlc.arg(tenant_id)
+  AND (appeals.decided_at IS NULL) = sqlc.arg(open)::bool
+ORDER BY CASE WHEN sqlc.arg(open)::bool THEN appeals.created_at END ASC,
+         appeals.decided_at DESC, appeals.id ASC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: DecideAppeal :one
+-- Records the moderator's decision and adds it to the audit log against the
+-- appealing user, with the chirp ID as details. Approving reverses the
+-- removal in the same statement: the chirp's removing reports are marked
+-- reinstated, its remove verdicts overturned and the chirp shown again,
+-- counted once more on the chirp it answers or shares. Returns no row when
+-- the appeal was decided already.
+WITH decided AS (
+    UPDATE appeals
+    SET decided_at = NOW(), decided_by = sqlc.arg(actor_id)::uuid, decision = sqlc.arg(decision)::text
+    WHERE appeals.id = sqlc.arg(id) AND appeals.tenant_id = sqlc.arg(tenant_id)
+      AND appeals.decided_at IS NULL
+    RETURNING *
+), audit AS (
+    INSERT INTO admin_audit_log (id, created_at, actor_id, action, target_user_id, details)
+    SELECT gen_random_uuid(), NOW(), sqlc.arg(actor_id)::uuid, sqlc.arg(action), decided.user_id, decided.chirp_id::text
+    FROM decided
+), reinstated AS (
+    UPDATE reports
+    SET resolution = 'reinstated'
+    FROM decided
+    WHERE decided.decision = 'approved' AND reports.chirp_id = decided.chirp_id AND reports.resolution = 'removed'
+), overturned AS (
+    UPDATE moderation_verdicts
+    SET reviewed_at = COALESCE(moderation_verdicts.reviewed_at, NOW()),
+        reviewed_by = COALESCE(moderation_verdicts.reviewed_by, sqlc.arg(actor_id)::uuid),
+        outcome = 'overturned'
+    FROM decided
+    WHERE decided.decision = 'approved' AND moderation_verdicts.chirp_id = decided.chirp_id
+      AND moderation_verdicts.verdict = 'remove' AND moderation_verdicts.outcome IS DISTINCT FROM 'overturned'
+), unhidden AS (
+    UPDATE chirps
+    SET deleted_at = NULL
+    FROM decided
+    WHERE decided.decision = 'approved' AND chirps.id = decided.chirp_id AND chirps.deleted_at IS NOT NULL
+    RETURNING chirps.parent_chirp_id, chirps.repost_of_chirp_id
+), counted AS (
+    UPDATE chirps
+    SET reply_count = chirps.reply_count + ((chirps.id = unhidden.parent_chirp_id) IS TRUE)::int,
+        repost_count = chirps.repost_count + ((chirps.id = unhidden.repost_of_chirp_id) IS TRUE)::int
+    FROM unhidden
+    WHERE chirps.id IN (unhidden.parent_chirp_id, unhidden.repost_of_chirp_id)
+), counted_archive AS (
+    UPDATE chirps_archive
+    SET reply_count = chirps_archive.reply_count + ((chirps_archive.id = unhidden.parent_chirp_id) IS TRUE)::int,
+        repost_count = chirps_archive.repost_count + ((chirps_archive.id = unhidden.repost_of_chirp_id) IS TRUE)::int
+    FROM unhidden
+    WHERE chirps_archive.id IN (unhidden.parent_chirp_id, unhidden.repost_of_chirp_id)
+)
+SELECT * FROM decided;
+
+-- name: GetAppealedChirpBody :one
+-- The body of an appeal's chirp, which may still be removed
+SELECT chirps.body
+FROM appeals
+JOIN chirps ON chirps.id = appeals.chirp_id
+WHERE appeals.id = $1 AND appeals.tenant_id = $2;
@@ -0,0 +1,14 @@
+-- name: GetBannedWords :many
+SELECT word FROM banned_words
+ORDER BY word ASC;
+
+-- name: ReplaceBannedWords :exec
+-- Words already banned keep their row, so the statement never deletes and
+-- inserts the same key
+WITH deleted AS (
+    DELETE FROM banned_words
+    WHERE word <> ALL(@words::text[])
+)
+INSERT INTO banned_words (word, created_at)
+SELECT unnest(@words::text[]), NOW()
+ON CONFLICT (word) DO NOTHING;
@@ -0,0 +1,43 @@
+-- name: CreateChirpCoauthor :exec
+INSERT INTO chirp_coauthors (chirp_id, user_id, status, created_at, updated_at)
+VALUES ($1, $2, 'pending', NOW(), NOW());
+
+-- name: GetChirpCoauthor :one
+SELECT * FROM chirp_coauthors
+WHERE chirp_id = $1 AND user_id = $2;
+
+-- name: UpdateChirpCoauthorStatus :one
+UPDATE chirp_coauthors
+SET status = sqlc.arg(status), updated_at = NOW()
+WHERE chirp_id = sqlc.arg(chirp_id) AND user_id = sqlc.arg(user_id)
+RETURNING *;
+
+-- name: GetAcceptedCoauthors :many
+SELECT chirp_coauthors.chirp_id, users.id, users.username, users.verified, users.display_name, users.bio, users.location, users.website, users.avatar_url
+FROM chirp_coauthors
+JOIN users ON users.id = chirp_coauthors.user_id
+WHERE chirp_coauthors.chirp_id = ANY(@chirp_ids::uuid[])
+  AND chirp_coauthors.status = 'accepted'
+  AND users.deactivated_at IS NULL;
+
+-- name: GetArchivedAcceptedCoauthors :many
+SELECT chirp_coauthors_archive.chirp_id, users.id, users.username, users.verified, users.display_name, users.bio, users.location, users.website, users.avatar_url
+FROM chirp_coauthors_archive
+JOIN users ON users.id = chirp_coauthors_archive.user_id
+WHERE chirp_coauthors_archive.chirp_id = ANY(@chirp_ids::uuid[])
+  AND chirp_coauthors_archive.status = 'accepted'
+  AND users.deactivated_at IS NULL;
+
+-- name: GetPendingCoauthorInvites :many
+SELECT chirps.id AS chirp_id, chirps.user_id AS author_id, chirps.body, chirp_coauthors.created_at
+FROM chirp_coauthors
+JOIN chirps ON chirps.id = chirp_coauthors.chirp_id
+WHERE chirp_coauthors.user_id = $1 AND chirp_coauthors.status = 'pending'
+  AND chirps.deleted_at IS NULL
+ORDER BY chirp_coauthors.created_at DESC;
+
+-- name: CountPendingCoauthorInvites :one
+SELECT COUNT(*) FROM chirp_coauthors
+JOIN chirps ON chirps.id = chirp_coauthors.chirp_id
+WHERE chirp_coauthors.user_id = $1 AND chirp_coauthors.status = 'pending'
+  AND chirps.deleted_at IS NULL;
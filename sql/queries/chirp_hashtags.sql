@@ -0,0 +1,55 @@
+-- name: SetChirpHashtags :exec
+-- Replaces the chirp's tags with the given ones, keeping the original
+-- created_at of tags that stay
+WITH removed AS (
+    DELETE FROM chirp_hashtags
+    WHERE chirp_hashtags.chirp_id = sqlc.arg(chirp_id) AND chirp_hashtags.tag <> ALL(@tags::text[])
+)
+INSERT INTO chirp_hashtags (chirp_id, tag, created_at)
+SELECT sqlc.arg(chirp_id), tag, NOW()
+FROM unnest(@tags::text[]) AS tag
+ON CONFLICT DO NOTHING;
+
+-- name: GetChirpsByHashtagAsc :many
+SELECT chirps.* FROM chirps
+JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+WHERE chirps.tenant_id = sqlc.arg(tenant_id) AND chirp_hashtags.tag = sqlc.arg(tag)
+  AND (sqlc.narg(user_id)::uuid IS NULL OR chirps.user_id = sqlc.narg(user_id)::uuid)
+  AND (sqlc.narg(language)::text IS NULL OR chirps.language = sqlc.narg(language)::text)
+  AND chirps.published_at <= NOW()
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY chirps.created_at ASC, chirps.id ASC;
+
+-- name: GetChirpsByHashtagDesc :many
+SELECT chirps.* FROM chirps
+JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+WHERE chirps.tenant_id = sqlc.arg(tenant_id) AND chirp_hashtags.tag = sqlc.arg(tag)
+  AND (sqlc.narg(user_id)::uuid IS NULL OR chirps.user_id = sqlc.narg(user_id)::uuid)
+  AND (sqlc.narg(language)::text IS NULL OR chirps.language = sqlc.narg(language)::text)
+  AND chirps.published_at <= NOW()
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY chirps.created_at DESC, chirps.id DESC;
+
+-- name: GetTrendingHashtags :many
+-- Tags by the number of chirps using them published since the given time,
+-- leaving out accounts registered after established_before
+SELECT chirp_hashtags.tag, COUNT(*) AS chirps
+FROM chirp_hashtags
+JOIN chirps ON chirps.id = chirp_hashtags.chirp_id
+JOIN users ON users.id = chirps.user_id
+WHERE chirps.tenant_id = sqlc.arg(tenant_id)
+  AND chirps.published_at > @since::timestamp AND chirps.published_at <= NOW()
+  AND chirps.deleted_at IS NULL
+  AND users.deactivated_at IS NULL
+  AND users.created_at <= @established_before::timestamp
+GROUP BY chirp_hashtags.tag
+ORDER BY chirps DESC, chirp_hashtags.tag
+LIMIT sqlc.arg(max_tags)::int;
@@ -0,0 +1,37 @@
+-- name: LikeChirp :exec
+-- Counts the like on the chirp in the same statement; liking it again
+-- changes nothing
+WITH liked AS (
+    INSERT INTO chirp_likes (chirp_id, user_id, created_at)
+    VALUES ($1, $2, NOW())
+    ON CONFLICT DO NOTHING
+    RETURNING chirp_id
+)
+UPDATE chirps
+SET like_count = chirps.like_count + 1
+WHERE chirps.id IN (SELECT liked.chirp_id FROM liked);
+
+-- name: UnlikeChirp :exec
+-- Stops counting the like in the same statement; unliking again changes
+-- nothing
+WITH unliked AS (
+    DELETE FROM chirp_likes
+    WHERE chirp_id = $1 AND user_id = $2
+    RETURNING chirp_id
+)
+UPDATE chirps
+SET like_count = GREATEST(chirps.like_count - 1, 0)
+WHERE chirps.id IN (SELECT unliked.chirp_id FROM unliked);
+
+-- name: GetLikedChirps :many
+-- Which of the given chirps the viewer liked
+SELECT chirp_likes.chirp_id
+FROM chirp_likes
+WHERE chirp_likes.user_id = sqlc.arg(viewer_id)
+  AND chirp_likes.chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[]);
+
+-- name: IsArchivedChirpLiked :one
+SELECT EXISTS (
+    SELECT 1 FROM chirp_likes_archive
+    WHERE chirp_likes_archive.chirp_id = sqlc.arg(chirp_id) AND chirp_likes_archive.user_id = sqlc.arg(viewer_id)
+)::boolean AS liked;
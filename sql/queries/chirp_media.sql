@@ -0,0 +1,10 @@
+-- name: CreateChirpMedia :many
+INSERT INTO chirp_media (id, created_at, chirp_id, position, url, alt_text)
+SELECT gen_random_uuid(), NOW(), @chirp_id, idx, (@urls::text[])[idx], (@alt_texts::text[])[idx]
+FROM generate_series(1, cardinality(@urls::text[])) AS idx
+RETURNING *;
+
+-- name: GetMediaForChirps :many
+SELECT * FROM chirp_media
+WHERE chirp_id = ANY(@chirp_ids::uuid[])
+ORDER BY chirp_id, position ASC;
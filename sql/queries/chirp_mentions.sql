@@ -0,0 +1,28 @@
+-- name: SetChirpMentions :exec
+-- Replaces the chirp's mentions with the active users of the tenant that
+-- have the given handles
+WITH mentioned AS (
+    SELECT users.id FROM users
+    WHERE users.tenant_id = sqlc.arg(tenant_id) AND users.username = ANY(@handles::text[])
+      AND users.deactivated_at IS NULL
+), removed AS (
+    DELETE FROM chirp_mentions
+    WHERE chirp_mentions.chirp_id = sqlc.arg(chirp_id)
+      AND chirp_mentions.user_id NOT IN (SELECT id FROM mentioned)
+)
+INSERT INTO chirp_mentions (chirp_id, user_id, created_at)
+SELECT sqlc.arg(chirp_id), mentioned.id, NOW()
+FROM mentioned
+ON CONFLICT DO NOTHING;
+
+-- name: GetChirpsMentioningUser :many
+SELECT chirps.* FROM chirps
+JOIN chirp_mentions ON chirp_mentions.chirp_id = chirps.id
+WHERE chirps.tenant_id = sqlc.arg(tenant_id) AND chirp_mentions.user_id = sqlc.arg(user_id)
+  AND chirps.published_at <= NOW()
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY chirps.created_at DESC, chirps.id DESC;
@@ -0,0 +1,42 @@
+-- name: AddChirpReaction :exec
+INSERT INTO chirp_reactions (chirp_id, user_id, emoji, created_at)
+VALUES ($1, $2, $3, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveChirpReaction :exec
+DELETE FROM chirp_reactions
+WHERE chirp_id = $1 AND user_id = $2 AND emoji = $3;
+
+-- name: GetReactionCounts :many
+SELECT chirp_reactions.chirp_id, chirp_reactions.emoji, COUNT(*) AS count
+FROM chirp_reactions
+JOIN users ON users.id = chirp_reactions.user_id
+WHERE chirp_reactions.chirp_id = ANY(@chirp_ids::uuid[])
+  AND users.deactivated_at IS NULL
+GROUP BY chirp_reactions.chirp_id, chirp_reactions.emoji
+ORDER BY chirp_reactions.chirp_id, count DESC, chirp_reactions.emoji;
+
+-- name: GetArchivedReactionCounts :many
+SELECT chirp_reactions_archive.chirp_id, chirp_reactions_archive.emoji, COUNT(*) AS count
+FROM chirp_reactions_archive
+JOIN users ON users.id = chirp_reactions_archive.user_id
+WHERE chirp_reactions_archive.chirp_id = ANY(@chirp_ids::uuid[])
+  AND users.deactivated_at IS NULL
+GROUP BY chirp_reactions_archive.chirp_id, chirp_reactions_archive.emoji
+ORDER BY chirp_reactions_archive.chirp_id, count DESC, chirp_reactions_archive.emoji;
+
+-- name: ListChirpReactions :many
+SELECT chirp_reactions.emoji, chirp_reactions.created_at, users.id AS user_id, users.username, users.verified
+FROM chirp_reactions
+JOIN users ON users.id = chirp_reactions.user_id
+WHERE chirp_reactions.chirp_id = $1
+  AND users.deactivated_at IS NULL
+ORDER BY chirp_reactions.created_at ASC;
+
+-- name: ListArchivedChirpReactions :many
+SELECT chirp_reactions_archive.emoji, chirp_reactions_archive.created_at, users.id AS user_id, users.username, users.verified
+FROM chirp_reactions_archive
+JOIN users ON users.id = chirp_reactions_archive.user_id
+WHERE chirp_reactions_archive.chirp_id = $1
+  AND users.deactivated_at IS NULL
+ORDER BY chirp_reactions_archive.created_at ASC;
@@ -0,0 +1,21 @@
+-- name: UpdateChirpBody :one
+WITH revision AS (
+    INSERT INTO chirp_revisions (id, created_at, chirp_id, revision, body)
+    SELECT
+        gen_random_uuid(),
+        chirps.updated_at,
+        chirps.id,
+        (SELECT COUNT(*) + 1 FROM chirp_revisions WHERE chirp_revisions.chirp_id = chirps.id),
+        chirps.body
+    FROM chirps
+    WHERE chirps.id = $1
+)
+UPDATE chirps
+SET body = $2, language = $3, updated_at = NOW()
+WHERE chirps.id = $1
+RETURNING *;
+
+-- name: GetChirpRevisions :many
+SELECT * FROM chirp_revisions
+WHERE chirp_id = $1
+ORDER BY revision ASC;
@@ -0,0 +1,22 @@
+-- name: AddChirpViews :exec
+-- Adds buffered view counts in one statement. Chirps deleted or archived
+-- since they were viewed are skipped.
+INSERT INTO chirp_views (chirp_id, view_count)
+SELECT views.chirp_id, views.view_count
+FROM (
+    SELECT unnest(sqlc.arg(chirp_ids)::uuid[]) AS chirp_id,
+           unnest(sqlc.arg(view_counts)::bigint[]) AS view_count
+) AS views
+WHERE EXISTS (SELECT 1 FROM chirps WHERE chirps.id = views.chirp_id)
+ON CONFLICT (chirp_id) DO UPDATE
+SET view_count = chirp_views.view_count + EXCLUDED.view_count;
+
+-- name: GetViewCounts :many
+SELECT chirp_id, view_count
+FROM chirp_views
+WHERE chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[]);
+
+-- name: GetArchivedViewCount :one
+SELECT COALESCE(SUM(view_count), 0)::bigint AS view_count
+FROM chirp_views_archive
+WHERE chirp_id = $1;
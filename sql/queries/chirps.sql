@@ -1,36 +1,401 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
-VALUES (
-    gen_random_uuid(),
-    NOW(),
-    NOW(),
-    $1,
-    $2
+-- Counts a reply on its parent, live or archived, in the same statement
+WITH created AS (
+    INSERT INTO chirps (id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, language, content_warning)
+    VALUES (
+        sqlc.arg(id),
+        NOW(),
+        NOW(),
+        sqlc.arg(body),
+        sqlc.arg(user_id),
+        NOW() + (sqlc.arg(delay_seconds)::int * INTERVAL '1 second'),
+        sqlc.arg(tenant_id),
+        sqlc.arg(sensitive),
+        sqlc.arg(source),
+        sqlc.narg(oauth_client_id),
+        sqlc.narg(parent_chirp_id),
+        sqlc.arg(language),
+        sqlc.arg(content_warning)
+    )
+    RETURNING *
+), counted AS (
+    UPDATE chirps SET reply_count = chirps.reply_count + 1
+    WHERE chirps.id = (SELECT created.parent_chirp_id FROM created)
+), counted_archive AS (
+    UPDATE chirps_archive SET reply_count = chirps_archive.reply_count + 1
+    WHERE chirps_archive.id = (SELECT created.parent_chirp_id FROM created)
 )
-RETURNING *;
+SELECT * FROM created;
 
 -- name: GetChirpsAsc :many
 SELECT * FROM chirps
-ORDER BY created_at ASC;
+WHERE chirps.tenant_id = sqlc.arg(tenant_id) AND published_at <= NOW()
+  AND (sqlc.narg(language)::text IS NULL OR chirps.language = sqlc.narg(language)::text)
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY created_at ASC, id ASC;
 
 -- name: GetChirpsDesc :many
 SELECT * FROM chirps
-ORDER BY created_at DESC;
+WHERE chirps.tenant_id = sqlc.arg(tenant_id) AND published_at <= NOW()
+  AND (sqlc.narg(language)::text IS NULL OR chirps.language = sqlc.narg(language)::text)
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetLatestChirps :many
+-- The viewer's chronological feed: the newest chirps of the users they
+-- follow and their own, at most page_size of them
+SELECT * FROM chirps
+WHERE chirps.tenant_id = sqlc.arg(tenant_id) AND published_at <= NOW()
+  AND (chirps.user_id = sqlc.arg(viewer_id)::uuid OR EXISTS (
+    SELECT 1 FROM follows
+    WHERE follows.follower_id = sqlc.arg(viewer_id)::uuid AND follows.followee_id = chirps.user_id
+  ))
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetChirpReplies :many
+SELECT * FROM chirps
+WHERE chirps.tenant_id = sqlc.arg(tenant_id) AND chirps.parent_chirp_id = sqlc.arg(parent_chirp_id)::uuid
+  AND published_at <= NOW()
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY created_at ASC, id ASC;
+
+-- name: GetChirpAncestors :many
+-- The chirps a reply answers, walking parent_chirp_id up from its parent,
+-- root first. Hidden chirps are walked through but left out.
+WITH RECURSIVE ancestors AS (
+    SELECT chirps.id, chirps.parent_chirp_id, 1 AS depth
+    FROM chirps
+    WHERE chirps.id = sqlc.arg(parent_chirp_id)::uuid AND chirps.tenant_id = sqlc.arg(tenant_id)
+    UNION ALL
+    SELECT parents.id, parents.parent_chirp_id, ancestors.depth + 1
+    FROM chirps AS parents
+    JOIN ancestors ON parents.id = ancestors.parent_chirp_id
+    WHERE parents.tenant_id = sqlc.arg(tenant_id)
+)
+SELECT chirps.* FROM ancestors
+JOIN chirps ON chirps.id = ancestors.id
+WHERE chirps.published_at <= NOW()
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY ancestors.depth DESC;
+
+-- name: GetConversationReplies :many
+-- One page of the replies below a chirp at any depth, depth first with
+-- siblings oldest first; depth is 1 for direct replies. Pass a NULL after_id
+-- for the first page. Hidden replies are walked through but left out.
+WITH RECURSIVE replies AS (
+    SELECT chirps.id, 1 AS depth,
+           ARRAY[to_char(chirps.created_at, 'YYYYMMDDHH24MISSUS') || chirps.id::text] AS path
+    FROM chirps
+    WHERE chirps.parent_chirp_id = sqlc.arg(chirp_id)::uuid AND chirps.tenant_id = sqlc.arg(tenant_id)
+    UNION ALL
+    SELECT children.id, replies.depth + 1,
+           replies.path || (to_char(children.created_at, 'YYYYMMDDHH24MISSUS') || children.id::text)
+    FROM chirps AS children
+    JOIN replies ON children.parent_chirp_id = replies.id
+    WHERE children.tenant_id = sqlc.arg(tenant_id)
+)
+SELECT sqlc.embed(chirps), replies.depth::int AS depth
+FROM replies
+JOIN chirps ON chirps.id = replies.id
+WHERE chirps.published_at <= NOW()
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+  AND (sqlc.narg(after_id)::uuid IS NULL
+    OR replies.path > (SELECT cursor.path FROM replies AS cursor WHERE cursor.id = sqlc.narg(after_id)::uuid))
+ORDER BY replies.path
+LIMIT sqlc.arg(page_size);
+
+-- name: IsChirpReplyTo :one
+-- Reports whether a chirp is a reply to the ancestor chirp at any depth
+WITH RECURSIVE ancestors AS (
+    SELECT chirps.parent_chirp_id
+    FROM chirps
+    WHERE chirps.id = sqlc.arg(chirp_id)::uuid
+    UNION ALL
+    SELECT parents.parent_chirp_id
+    FROM chirps AS parents
+    JOIN ancestors ON parents.id = ancestors.parent_chirp_id
+)
+SELECT EXISTS (
+    SELECT 1 FROM ancestors WHERE ancestors.parent_chirp_id = sqlc.arg(ancestor_id)::uuid
+)::boolean AS reply;
+
+-- name: CreateRepost :one
+-- Returns no row if the user already reposted the chirp. Counts the repost
+-- on the original in the same statement.
+WITH created AS (
+    INSERT INTO chirps (id, created_at, updated_at, body, user_id, published_at, tenant_id, source, oauth_client_id, repost_of_chirp_id)
+    VALUES (
+        sqlc.arg(id),
+        NOW(),
+        NOW(),
+        '',
+        sqlc.arg(user_id),
+        NOW(),
+        sqlc.arg(tenant_id),
+        sqlc.arg(source),
+        sqlc.narg(oauth_client_id),
+        sqlc.arg(repost_of_chirp_id)::uuid
+    )
+    ON CONFLICT (repost_of_chirp_id, user_id) WHERE repost_of_chirp_id IS NOT NULL AND deleted_at IS NULL DO NOTHING
+    RETURNING *
+), counted AS (
+    UPDATE chirps SET repost_count = chirps.repost_count + 1
+    WHERE chirps.id = (SELECT created.repost_of_chirp_id FROM created)
+)
+SELECT * FROM created;
+
+-- name: GetChirpsByIDs :many
+SELECT * FROM chirps
+WHERE chirps.tenant_id = sqlc.arg(tenant_id) AND chirps.id = ANY(@ids::uuid[])
+  AND published_at <= NOW()
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  );
+
+-- name: SearchChirps :many
+-- Best matches first. The to_tsvector expression matches idx_chirps_search.
+SELECT chirps.* FROM chirps
+WHERE chirps.tenant_id = sqlc.arg(tenant_id) AND published_at <= NOW()
+  AND to_tsvector('english', body) @@ websearch_to_tsquery('english', sqlc.arg(query)::text)
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY ts_rank(to_tsvector('english', body), websearch_to_tsquery('english', sqlc.arg(query)::text)) DESC, created_at DESC, id DESC
+LIMIT sqlc.arg(page_size)::int OFFSET sqlc.arg(page_offset)::int;
 
 -- name: GetChirpsByAuthorAsc :many
 SELECT * FROM chirps
-WHERE user_id = $1
-ORDER BY created_at ASC;
+WHERE chirps.tenant_id = sqlc.arg(tenant_id) AND chirps.user_id = sqlc.arg(user_id) AND published_at <= NOW()
+  AND (sqlc.narg(language)::text IS NULL OR chirps.language = sqlc.narg(language)::text)
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY created_at ASC, id ASC;
 
 -- name: GetChirpsByAuthorDesc :many
 SELECT * FROM chirps
-WHERE user_id = $1
-ORDER BY created_at DESC;
+WHERE chirps.tenant_id = sqlc.arg(tenant_id) AND chirps.user_id = sqlc.arg(user_id) AND published_at <= NOW()
+  AND (sqlc.narg(language)::text IS NULL OR chirps.language = sqlc.narg(language)::text)
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetUserTimeline :many
+-- One page of an author's chirps, newest first, created before the given
+-- chirp. Pass uuid.Max with a time in the future for the first page.
+SELECT * FROM chirps
+WHERE chirps.tenant_id = sqlc.arg(tenant_id) AND chirps.user_id = sqlc.arg(user_id)
+  AND (created_at, id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+  AND published_at <= NOW()
+  AND chirps.deleted_at IS NULL
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_size);
 
 -- name: GetChirpByID :one
 SELECT * FROM chirps
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NULL;
 
 -- name: DeleteChirp :exec
+-- Removes a chirp outright; used to roll back a failed create. A reply or
+-- repost stops being counted on the chirp it answers or shares.
+WITH removed AS (
+    DELETE FROM chirps
+    WHERE id = $1
+    RETURNING parent_chirp_id, repost_of_chirp_id
+), counted_archive AS (
+    UPDATE chirps_archive
+    SET reply_count = GREATEST(chirps_archive.reply_count - ((chirps_archive.id = removed.parent_chirp_id) IS TRUE)::int, 0),
+        repost_count = GREATEST(chirps_archive.repost_count - ((chirps_archive.id = removed.repost_of_chirp_id) IS TRUE)::int, 0)
+    FROM removed
+    WHERE chirps_archive.id IN (removed.parent_chirp_id, removed.repost_of_chirp_id)
+)
+UPDATE chirps
+SET reply_count = GREATEST(chirps.reply_count - ((chirps.id = removed.parent_chirp_id) IS TRUE)::int, 0),
+    repost_count = GREATEST(chirps.repost_count - ((chirps.id = removed.repost_of_chirp_id) IS TRUE)::int, 0)
+FROM removed
+WHERE chirps.id IN (removed.parent_chirp_id, removed.repost_of_chirp_id);
+
+-- name: SoftDeleteChirp :exec
+-- A reply or repost stops being counted on the chirp it answers or shares
+WITH deleted AS (
+    UPDATE chirps
+    SET deleted_at = NOW()
+    WHERE id = $1 AND deleted_at IS NULL
+    RETURNING parent_chirp_id, repost_of_chirp_id
+), counted_archive AS (
+    UPDATE chirps_archive
+    SET reply_count = GREATEST(chirps_archive.reply_count - ((chirps_archive.id = deleted.parent_chirp_id) IS TRUE)::int, 0),
+        repost_count = GREATEST(chirps_archive.repost_count - ((chirps_archive.id = deleted.repost_of_chirp_id) IS TRUE)::int, 0)
+    FROM deleted
+    WHERE chirps_archive.id IN (deleted.parent_chirp_id, deleted.repost_of_chirp_id)
+)
+UPDATE chirps
+SET reply_count = GREATEST(chirps.reply_count - ((chirps.id = deleted.parent_chirp_id) IS TRUE)::int, 0),
+    repost_count = GREATEST(chirps.repost_count - ((chirps.id = deleted.repost_of_chirp_id) IS TRUE)::int, 0)
+FROM deleted
+WHERE chirps.id IN (deleted.parent_chirp_id, deleted.repost_of_chirp_id);
+
+-- name: RestoreChirp :one
+-- Returns no row unless the author deleted the chirp after the cutoff.
+-- Chirps removed by a moderator, or hidden by the classifier unless a
+-- moderator overturned its verdict, can't be restored. A restored reply or
+-- repost is counted again on the chirp it answers or shares.
+WITH restored AS (
+    UPDATE chirps
+    SET deleted_at = NULL
+    WHERE chirps.id = sqlc.arg(id) AND chirps.user_id = sqlc.arg(user_id) AND chirps.tenant_id = sqlc.arg(tenant_id)
+      AND chirps.deleted_at > sqlc.arg(cutoff)::timestamp
+      AND NOT EXISTS (
+        SELECT 1 FROM reports
+        WHERE reports.chirp_id = chirps.id AND reports.resolution = 'removed'
+      )
+      AND NOT EXISTS (
+        SELECT 1 FROM moderation_verdicts
+        WHERE moderation_verdicts.chirp_id = chirps.id AND moderation_verdicts.verdict = 'remove'
+          AND moderation_verdicts.outcome IS DISTINCT FROM 'overturned'
+      )
+    RETURNING *
+), counted AS (
+    UPDATE chirps
+    SET reply_count = chirps.reply_count + ((chirps.id = restored.parent_chirp_id) IS TRUE)::int,
+        repost_count = chirps.repost_count + ((chirps.id = restored.repost_of_chirp_id) IS TRUE)::int
+    FROM restored
+    WHERE chirps.id IN (restored.parent_chirp_id, restored.repost_of_chirp_id)
+), counted_archive AS (
+    UPDATE chirps_archive
+    SET reply_count = chirps_archive.reply_count + ((chirps_archive.id = restored.parent_chirp_id) IS TRUE)::int,
+        repost_count = chirps_archive.repost_count + ((chirps_archive.id = restored.repost_of_chirp_id) IS TRUE)::int
+    FROM restored
+    WHERE chirps_archive.id IN (restored.parent_chirp_id, restored.repost_of_chirp_id)
+)
+SELECT * FROM restored;
+
+-- name: ReconcileChirpCounters :execrows
+-- Recounts the likes, replies and reposts of every chirp, fixing the
+-- counters that drifted from their rows. Only active accounts count, and
+-- replies and reposts are counted live or archived unless deleted.
+WITH counts AS (
+    SELECT chirps.id,
+           (SELECT COUNT(*) FROM chirp_likes AS likes JOIN users ON users.id = likes.user_id
+            WHERE likes.chirp_id = chirps.id AND users.deactivated_at IS NULL) AS like_count,
+           (SELECT COUNT(*) FROM chirps AS replies JOIN users ON users.id = replies.user_id
+            WHERE replies.parent_chirp_id = chirps.id AND replies.deleted_at IS NULL AND users.deactivated_at IS NULL)
+         + (SELECT COUNT(*) FROM chirps_archive AS replies JOIN users ON users.id = replies.user_id
+            WHERE replies.parent_chirp_id = chirps.id AND users.deactivated_at IS NULL) AS reply_count,
+           (SELECT COUNT(*) FROM chirps AS reposts JOIN users ON users.id = reposts.user_id
+            WHERE reposts.repost_of_chirp_id = chirps.id AND reposts.deleted_at IS NULL AND users.deactivated_at IS NULL)
+         + (SELECT COUNT(*) FROM chirps_archive AS reposts JOIN users ON users.id = reposts.user_id
+            WHERE reposts.repost_of_chirp_id = chirps.id AND users.deactivated_at IS NULL) AS repost_count
+    FROM chirps
+)
+UPDATE chirps
+SET like_count = counts.like_count, reply_count = counts.reply_count, repost_count = counts.repost_count
+FROM counts
+WHERE chirps.id = counts.id
+  AND (chirps.like_count, chirps.reply_count, chirps.repost_count)
+      <> (counts.like_count, counts.reply_count, counts.repost_count);
+
+-- name: PurgeDeletedChirps :execrows
+-- Chirps of users under legal hold are kept until the hold is released,
+-- chirps with a verdict until a moderator has reviewed it, and chirps with
+-- an appeal until it is decided
 DELETE FROM chirps
-WHERE id = $1;
+WHERE deleted_at < sqlc.arg(cutoff)::timestamp
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.legal_hold
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM moderation_verdicts
+    WHERE moderation_verdicts.chirp_id = chirps.id AND moderation_verdicts.reviewed_at IS NULL
+  )
+  AND NOT EXISTS (
+    SELECT 1 FROM appeals
+    WHERE appeals.chirp_id = chirps.id AND appeals.decided_at IS NULL
+  );
+
+-- name: SetChirpSensitive :one
+UPDATE chirps
+SET sensitive = $2, content_warning = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: SetChirpLocked :one
+-- Records the change in the audit log against the chirp's author, with the
+-- chirp ID as details
+WITH updated AS (
+    UPDATE chirps
+    SET locked = sqlc.arg(locked)
+    WHERE chirps.id = sqlc.arg(id) AND chirps.tenant_id = sqlc.arg(tenant_id) AND chirps.deleted_at IS NULL
+    RETURNING *
+), audit AS (
+    INSERT INTO admin_audit_log (id, created_at, actor_id, action, target_user_id, details)
+    SELECT gen_random_uuid(), NOW(), sqlc.arg(actor_id), sqlc.arg(action), updated.user_id, updated.id::text
+    FROM updated
+)
+SELECT * FROM updated;
+
+-- name: GetClientUsage :many
+-- Live chirps of the tenant grouped by the app that posted them, busiest first
+SELECT chirps.source,
+       oauth_clients.client_id AS oauth_client_id,
+       COUNT(*)::bigint AS chirps,
+       COUNT(DISTINCT chirps.user_id)::bigint AS authors,
+       MAX(chirps.created_at)::timestamp AS last_chirp_at
+FROM chirps
+LEFT JOIN oauth_clients ON oauth_clients.id = chirps.oauth_client_id
+WHERE chirps.tenant_id = $1 AND chirps.deleted_at IS NULL
+GROUP BY chirps.source, oauth_clients.client_id
+ORDER BY chirps DESC, chirps.source;
+
+-- name: GetChirpsForExport :many
+-- One page of a user's live and archived chirps, oldest first, created
+-- after the given chirp. Pass uuid.Nil with the zero time for the first page.
+SELECT id, created_at, updated_at, published_at, body, sensitive, content_warning, language, source, parent_chirp_id, repost_of_chirp_id,
+       FALSE AS archived
+FROM chirps
+WHERE chirps.user_id = sqlc.arg(user_id) AND chirps.deleted_at IS NULL
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::uuid)
+UNION ALL
+SELECT id, created_at, updated_at, published_at, body, sensitive, content_warning, language, source, parent_chirp_id, repost_of_chirp_id,
+       TRUE AS archived
+FROM chirps_archive
+WHERE chirps_archive.user_id = sqlc.arg(user_id)
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::uuid)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(page_size);
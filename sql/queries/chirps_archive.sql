@@ -0,0 +1,124 @@
+-- name: ArchiveChirps :execrows
+-- Moves the oldest chirps created before the cutoff, with their media,
+-- revisions, co-authors, reactions, likes and view counts, into the archive
+-- tables in a single statement.
+-- Every part of the statement reads the same snapshot, so the related rows
+-- are copied before the delete cascades to them. Deleted chirps are left for
+-- the purge job, scheduled ones until they are published and reported ones
+-- until a moderator resolves the reports.
+WITH moved AS (
+    DELETE FROM chirps
+    WHERE chirps.id IN (
+        SELECT old.id FROM chirps AS old
+        WHERE old.created_at < sqlc.arg(cutoff)::timestamp AND old.deleted_at IS NULL
+          AND old.published_at <= NOW()
+          AND NOT EXISTS (
+            SELECT 1 FROM reports
+            WHERE reports.chirp_id = old.id AND reports.resolved_at IS NULL
+          )
+        ORDER BY old.created_at
+        LIMIT sqlc.arg(batch_size)::int
+    )
+    RETURNING chirps.*
+), media AS (
+    INSERT INTO chirp_media_archive (id, created_at, chirp_id, position, url, alt_text)
+    SELECT chirp_media.id, chirp_media.created_at, chirp_media.chirp_id,
+           chirp_media.position, chirp_media.url, chirp_media.alt_text
+    FROM chirp_media
+    JOIN moved ON moved.id = chirp_media.chirp_id
+), revisions AS (
+    INSERT INTO chirp_revisions_archive (id, created_at, chirp_id, revision, body)
+    SELECT chirp_revisions.id, chirp_revisions.created_at, chirp_revisions.chirp_id,
+           chirp_revisions.revision, chirp_revisions.body
+    FROM chirp_revisions
+    JOIN moved ON moved.id = chirp_revisions.chirp_id
+), coauthors AS (
+    INSERT INTO chirp_coauthors_archive (chirp_id, user_id, status, created_at, updated_at)
+    SELECT chirp_coauthors.chirp_id, chirp_coauthors.user_id, chirp_coauthors.status,
+           chirp_coauthors.created_at, chirp_coauthors.updated_at
+    FROM chirp_coauthors
+    JOIN moved ON moved.id = chirp_coauthors.chirp_id
+), reactions AS (
+    INSERT INTO chirp_reactions_archive (chirp_id, user_id, emoji, created_at)
+    SELECT chirp_reactions.chirp_id, chirp_reactions.user_id, chirp_reactions.emoji, chirp_reactions.created_at
+    FROM chirp_reactions
+    JOIN moved ON moved.id = chirp_reactions.chirp_id
+), likes AS (
+    INSERT INTO chirp_likes_archive (chirp_id, user_id, created_at)
+    SELECT chirp_likes.chirp_id, chirp_likes.user_id, chirp_likes.created_at
+    FROM chirp_likes
+    JOIN moved ON moved.id = chirp_likes.chirp_id
+), views AS (
+    INSERT INTO chirp_views_archive (chirp_id, view_count)
+    SELECT chirp_views.chirp_id, chirp_views.view_count
+    FROM chirp_views
+    JOIN moved ON moved.id = chirp_views.chirp_id
+)
+INSERT INTO chirps_archive (id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id, language, content_warning,
+                            like_count, reply_count, repost_count, archived_at)
+SELECT moved.id, moved.created_at, moved.updated_at, moved.body, moved.user_id, moved.published_at, moved.tenant_id, moved.sensitive,
+       moved.source, moved.oauth_client_id, moved.parent_chirp_id, moved.locked, moved.repost_of_chirp_id, moved.language, moved.content_warning,
+       moved.like_count, moved.reply_count, moved.repost_count, NOW()
+FROM moved;
+
+-- name: GetArchivedChirpByID :one
+-- Deleted chirps are never archived
+SELECT id, created_at, updated_at, body, user_id, published_at, tenant_id, sensitive, source, oauth_client_id, parent_chirp_id, locked, repost_of_chirp_id,
+       NULL::timestamp AS deleted_at, language, content_warning, like_count, reply_count, repost_count
+FROM chirps_archive
+WHERE id = $1;
+
+-- name: GetArchivedMediaForChirp :many
+SELECT id, created_at, chirp_id, position, url, alt_text
+FROM chirp_media_archive
+WHERE chirp_id = $1
+ORDER BY position ASC;
+
+-- name: GetArchivedChirpRevisions :many
+SELECT id, created_at, chirp_id, revision, body
+FROM chirp_revisions_archive
+WHERE chirp_id = $1
+ORDER BY revision ASC;
+
+-- name: DeleteArchivedChirp :exec
+-- A reply or repost stops being counted on the chirp it answers or shares
+WITH removed AS (
+    DELETE FROM chirps_archive
+    WHERE id = $1
+    RETURNING parent_chirp_id, repost_of_chirp_id
+), counted AS (
+    UPDATE chirps
+    SET reply_count = GREATEST(chirps.reply_count - ((chirps.id = removed.parent_chirp_id) IS TRUE)::int, 0),
+        repost_count = GREATEST(chirps.repost_count - ((chirps.id = removed.repost_of_chirp_id) IS TRUE)::int, 0)
+    FROM removed
+    WHERE chirps.id IN (removed.parent_chirp_id, removed.repost_of_chirp_id)
+)
+UPDATE chirps_archive
+SET reply_count = GREATEST(chirps_archive.reply_count - ((chirps_archive.id = removed.parent_chirp_id) IS TRUE)::int, 0),
+    repost_count = GREATEST(chirps_archive.repost_count - ((chirps_archive.id = removed.repost_of_chirp_id) IS TRUE)::int, 0)
+FROM removed
+WHERE chirps_archive.id IN (removed.parent_chirp_id, removed.repost_of_chirp_id);
+
+-- name: ReconcileArchivedChirpCounters :execrows
+-- Recounts the likes, replies and reposts of archived chirps like
+-- ReconcileChirpCounters, fixing the counters that drifted
+WITH counts AS (
+    SELECT chirps_archive.id,
+           (SELECT COUNT(*) FROM chirp_likes_archive AS likes JOIN users ON users.id = likes.user_id
+            WHERE likes.chirp_id = chirps_archive.id AND users.deactivated_at IS NULL) AS like_count,
+           (SELECT COUNT(*) FROM chirps AS replies JOIN users ON users.id = replies.user_id
+            WHERE replies.parent_chirp_id = chirps_archive.id AND replies.deleted_at IS NULL AND users.deactivated_at IS NULL)
+         + (SELECT COUNT(*) FROM chirps_archive AS replies JOIN users ON users.id = replies.user_id
+            WHERE replies.parent_chirp_id = chirps_archive.id AND users.deactivated_at IS NULL) AS reply_count,
+           (SELECT COUNT(*) FROM chirps AS reposts JOIN users ON users.id = reposts.user_id
+            WHERE reposts.repost_of_chirp_id = chirps_archive.id AND reposts.deleted_at IS NULL AND users.deactivated_at IS NULL)
+         + (SELECT COUNT(*) FROM chirps_archive AS reposts JOIN users ON users.id = reposts.user_id
+            WHERE reposts.repost_of_chirp_id = chirps_archive.id AND users.deactivated_at IS NULL) AS repost_count
+    FROM chirps_archive
+)
+UPDATE chirps_archive
+SET like_count = counts.like_count, reply_count = counts.reply_count, repost_count = counts.repost_count
+FROM counts
+WHERE chirps_archive.id = counts.id
+  AND (chirps_archive.like_count, chirps_archive.reply_count, chirps_archive.repost_count)
+      <> (counts.like_count, counts.reply_count, counts.repost_count);
@@ -0,0 +1,41 @@
+-- name: CreateCustomDomain :one
+INSERT INTO custom_domains (domain, created_at, tenant_id, user_id, verification_token)
+VALUES ($1, NOW(), $2, $3, $4)
+RETURNING *;
+
+-- name: DeleteCustomDomain :execrows
+DELETE FROM custom_domains
+WHERE domain = $1;
+
+-- name: DeleteUserCustomDomain :execrows
+DELETE FROM custom_domains
+WHERE user_id = $1;
+
+-- name: GetUserCustomDomain :one
+SELECT * FROM custom_domains
+WHERE user_id = $1;
+
+-- name: GetVerifiedCustomDomain :one
+-- Resolves the Host header of a request to the community, and profile if
+-- any, it is addressed to
+SELECT custom_domains.user_id, tenants.id AS tenant_id, tenants.slug, tenants.name, tenants.description
+FROM custom_domains
+JOIN tenants ON tenants.id = custom_domains.tenant_id
+WHERE custom_domains.domain = $1 AND custom_domains.verified_at IS NOT NULL;
+
+-- name: ListCustomDomains :many
+SELECT * FROM custom_domains
+ORDER BY domain ASC;
+
+-- name: ListUnverifiedCustomDomains :many
+-- Least recently checked first, so every domain gets its turn
+SELECT * FROM custom_domains
+WHERE verified_at IS NULL
+ORDER BY checked_at ASC NULLS FIRST
+LIMIT $1;
+
+-- name: MarkCustomDomainChecked :exec
+UPDATE custom_domains
+SET checked_at = NOW(),
+    verified_at = CASE WHEN sqlc.arg(verified)::boolean THEN NOW() END
+WHERE domain = sqlc.arg(domain);
@@ -0,0 +1,38 @@
+-- name: GetFeedCandidates :many
+-- The chirps the ranked feed chooses from: the newest the viewer or the
+-- users they follow published since the given time, at most max_candidates
+-- of them, with their engagement
+SELECT chirps.id, chirps.user_id, chirps.published_at, chirps.like_count, chirps.reply_count, chirps.repost_count
+FROM chirps
+WHERE chirps.tenant_id = sqlc.arg(tenant_id)
+  AND (chirps.user_id = sqlc.arg(viewer_id)::uuid OR EXISTS (
+    SELECT 1 FROM follows
+    WHERE follows.follower_id = sqlc.arg(viewer_id)::uuid AND follows.followee_id = chirps.user_id
+  ))
+  AND chirps.published_at > sqlc.arg(since)::timestamp AND chirps.published_at <= NOW()
+  AND chirps.deleted_at IS NULL
+  AND NOT EXISTS (
+    SELECT 1 FROM users
+    WHERE users.id = chirps.user_id AND users.deactivated_at IS NOT NULL
+  )
+ORDER BY chirps.published_at DESC, chirps.id DESC
+LIMIT sqlc.arg(max_candidates)::int;
+
+-- name: GetAuthorAffinity :many
+-- How many of each author's chirps the viewer has liked or replied to since
+-- the given time
+SELECT interactions.author_id, COUNT(*) AS interactions
+FROM (
+    SELECT liked.user_id AS author_id
+    FROM chirp_likes
+    JOIN chirps AS liked ON liked.id = chirp_likes.chirp_id
+    WHERE chirp_likes.user_id = sqlc.arg(viewer_id) AND chirp_likes.created_at > sqlc.arg(since)::timestamp
+    UNION ALL
+    SELECT parents.user_id AS author_id
+    FROM chirps AS replies
+    JOIN chirps AS parents ON parents.id = replies.parent_chirp_id
+    WHERE replies.user_id = sqlc.arg(viewer_id) AND replies.created_at > sqlc.arg(since)::timestamp
+      AND replies.deleted_at IS NULL
+) AS interactions
+WHERE interactions.author_id <> sqlc.arg(viewer_id)
+GROUP BY interactions.author_id;
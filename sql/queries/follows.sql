@@ -0,0 +1,47 @@
+-- name: FollowUser :exec
+INSERT INTO follows (follower_id, followee_id, created_at)
+VALUES ($1, $2, NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: UnfollowUser :exec
+DELETE FROM follows
+WHERE follower_id = $1 AND followee_id = $2;
+
+-- name: GetFollow :one
+SELECT follower_id, followee_id, created_at FROM follows
+WHERE follower_id = $1 AND followee_id = $2;
+
+-- name: GetFollowers :many
+-- One page of the active users following a user, most recent follow first,
+-- starting after the given follow. Pass uuid.Max with a time in the future
+-- for the first page.
+SELECT users.id, users.created_at, users.username, users.verified, users.display_name, users.bio, users.location, users.website, users.avatar_url,
+       follows.created_at AS followed_at
+FROM follows
+JOIN users ON users.id = follows.follower_id
+WHERE follows.followee_id = sqlc.arg(user_id)
+  AND (follows.created_at, follows.follower_id) < (sqlc.arg(before_followed_at)::timestamp, sqlc.arg(before_id)::uuid)
+  AND users.deactivated_at IS NULL
+ORDER BY follows.created_at DESC, follows.follower_id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetFollowing :many
+-- One page of the active users a user follows, most recent follow first,
+-- starting after the given follow. Pass uuid.Max with a time in the future
+-- for the first page.
+SELECT users.id, users.created_at, users.username, users.verified, users.display_name, users.bio, users.location, users.website, users.avatar_url,
+       follows.created_at AS followed_at
+FROM follows
+JOIN users ON users.id = follows.followee_id
+WHERE follows.follower_id = sqlc.arg(user_id)
+  AND (follows.created_at, follows.followee_id) < (sqlc.arg(before_followed_at)::timestamp, sqlc.arg(before_id)::uuid)
+  AND users.deactivated_at IS NULL
+ORDER BY follows.created_at DESC, follows.followee_id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: GetFollowedUserIDs :many
+-- Every active user a user follows, oldest follow first
+SELECT follows.followee_id FROM follows
+JOIN users ON users.id = follows.followee_id
+WHERE follows.follower_id = $1 AND users.deactivated_at IS NULL
+ORDER BY follows.created_at, follows.followee_id;
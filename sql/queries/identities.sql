@@ -0,0 +1,13 @@
+-- name: GetUserByIdentity :one
+-- Finds the account a provider subject is linked to
+SELECT users.id, users.created_at, users.updated_at, users.email, users.hashed_password, users.is_chirpy_red, users.role, users.deactivated_at, users.username, users.verified, users.tenant_id, users.legal_hold, users.display_name, users.bio, users.location, users.website, users.avatar_url
+FROM identities
+JOIN users ON users.id = identities.user_id
+WHERE identities.tenant_id = sqlc.arg(tenant_id)
+  AND identities.issuer = sqlc.arg(issuer)
+  AND identities.subject = sqlc.arg(subject);
+
+-- name: CreateIdentity :exec
+INSERT INTO identities (id, created_at, user_id, tenant_id, issuer, subject)
+VALUES (gen_random_uuid(), NOW(), sqlc.arg(user_id), sqlc.arg(tenant_id), sqlc.arg(issuer), sqlc.arg(subject))
+ON CONFLICT (tenant_id, issuer, subject) DO NOTHING;
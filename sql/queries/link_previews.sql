@@ -0,0 +1,28 @@
+-- name: SetChirpLink :exec
+INSERT INTO chirp_links (chirp_id, url)
+VALUES (sqlc.arg(chirp_id), sqlc.arg(url))
+ON CONFLICT (chirp_id) DO UPDATE SET url = EXCLUDED.url;
+
+-- name: DeleteChirpLink :exec
+DELETE FROM chirp_links WHERE chirp_id = $1;
+
+-- name: IsLinkPreviewFresh :one
+SELECT EXISTS (
+    SELECT 1 FROM link_previews
+    WHERE url = sqlc.arg(url) AND fetched_at > sqlc.arg(since)
+);
+
+-- name: UpsertLinkPreview :exec
+INSERT INTO link_previews (url, fetched_at, title, description, image_url)
+VALUES (sqlc.arg(url), NOW(), sqlc.arg(title), sqlc.arg(description), sqlc.arg(image_url))
+ON CONFLICT (url) DO UPDATE SET
+    fetched_at = EXCLUDED.fetched_at,
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    image_url = EXCLUDED.image_url;
+
+-- name: GetLinkPreviews :many
+SELECT chirp_links.chirp_id, link_previews.url, link_previews.title, link_previews.description, link_previews.image_url
+FROM chirp_links
+JOIN link_previews ON link_previews.url = chirp_links.url
+WHERE chirp_links.chirp_id = ANY(@chirp_ids::uuid[]);
@@ -0,0 +1,26 @@
+-- name: LinkAccounts :execrows
+-- Links two accounts in both directions; affects no rows if they already are
+INSERT INTO linked_accounts (user_id, linked_user_id, created_at)
+VALUES (sqlc.arg(user_id), sqlc.arg(linked_user_id), NOW()), (sqlc.arg(linked_user_id), sqlc.arg(user_id), NOW())
+ON CONFLICT DO NOTHING;
+
+-- name: UnlinkAccounts :execrows
+DELETE FROM linked_accounts
+WHERE (user_id = sqlc.arg(user_id) AND linked_user_id = sqlc.arg(linked_user_id))
+   OR (user_id = sqlc.arg(linked_user_id) AND linked_user_id = sqlc.arg(user_id));
+
+-- name: CountLinkedAccounts :one
+SELECT COUNT(*) FROM linked_accounts WHERE user_id = $1;
+
+-- name: GetLinkedAccounts :many
+SELECT users.id, users.email, users.username, users.verified, linked_accounts.created_at AS linked_at
+FROM linked_accounts
+JOIN users ON users.id = linked_accounts.linked_user_id
+WHERE linked_accounts.user_id = $1 AND users.deactivated_at IS NULL
+ORDER BY linked_accounts.created_at ASC, users.id ASC;
+
+-- name: GetLinkedUser :one
+SELECT users.* FROM users
+JOIN linked_accounts ON linked_accounts.linked_user_id = users.id
+WHERE linked_accounts.user_id = sqlc.arg(user_id) AND users.id = sqlc.arg(linked_user_id)
+  AND users.deactivated_at IS NULL;
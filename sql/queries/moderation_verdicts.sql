@@ -0,0 +1,88 @@
+-- name: CreateModerationVerdict :one
+-- Returns no row if the chirp already has a verdict awaiting review. A
+-- remove verdict hides the chirp in the same statement, so it is never
+-- hidden without the verdict that keeps its author from restoring it.
+WITH recorded AS (
+    INSERT INTO moderation_verdicts (id, created_at, tenant_id, chirp_id, verdict, label, score)
+    VALUES (gen_random_uuid(), NOW(), $1, $2, $3, $4, $5)
+    ON CONFLICT (chirp_id) WHERE reviewed_at IS NULL DO NOTHING
+    RETURNING *
+), hidden AS (
+    UPDATE chirps
+    SET deleted_at = NOW()
+    FROM recorded
+    WHERE chirps.id = recorded.chirp_id AND recorded.verdict = 'remove' AND chirps.deleted_at IS NULL
+    RETURNING chirps.parent_chirp_id, chirps.repost_of_chirp_id
+), counted AS (
+    UPDATE chirps
+    SET reply_count = GREATEST(chirps.reply_count - ((chirps.id = hidden.parent_chirp_id) IS TRUE)::int, 0),
+        repost_count = GREATEST(chirps.repost_count - ((chirps.id = hidden.repost_of_chirp_id) IS TRUE)::int, 0)
+    FROM hidden
+    WHERE chirps.id IN (hidden.parent_chirp_id, hidden.repost_of_chirp_id)
+), counted_archive AS (
+    UPDATE chirps_archive
+    SET reply_count = GREATEST(chirps_archive.reply_count - ((chirps_archive.id = hidden.parent_chirp_id) IS TRUE)::int, 0),
+        repost_count = GREATEST(chirps_archive.repost_count - ((chirps_archive.id = hidden.repost_of_chirp_id) IS TRUE)::int, 0)
+    FROM hidden
+    WHERE chirps_archive.id IN (hidden.parent_chirp_id, hidden.repost_of_chirp_id)
+)
+SELECT * FROM recorded;
+
+-- name: GetModerationVerdict :one
+SELECT * FROM moderation_verdicts
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: GetModerationVerdicts :many
+-- Unreviewed verdicts oldest first, which is the review queue, or reviewed
+-- ones most recently reviewed first
+SELECT moderation_verdicts.*, chirps.body AS chirp_body, chirps.user_id AS chirp_author_id
+FROM moderation_verdicts
+JOIN chirps ON chirps.id = moderation_verdicts.chirp_id
+WHERE moderation_verdicts.tenant_id = sqlc.arg(tenant_id)
+  AND (moderation_verdicts.reviewed_at IS NULL) = sqlc.arg(open)::bool
+ORDER BY CASE WHEN sqlc.arg(open)::bool THEN moderation_verdicts.created_at END ASC,
+         moderation_verdicts.reviewed_at DESC, moderation_verdicts.id ASC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: ReviewModerationVerdict :one
+-- Records the moderator's outcome and adds it to the audit log against the
+-- chirp's author, with the chirp ID as details. Returns no row when the
+-- verdict was reviewed already.
+WITH reviewed AS (
+    UPDATE moderation_verdicts
+    SET reviewed_at = NOW(), reviewed_by = sqlc.arg(actor_id)::uuid, outcome = sqlc.arg(outcome)::text
+    WHERE moderation_verdicts.id = sqlc.arg(id) AND moderation_verdicts.tenant_id = sqlc.arg(tenant_id)
+      AND moderation_verdicts.reviewed_at IS NULL
+    RETURNING *
+), audit AS (
+    INSERT INTO admin_audit_log (id, created_at, actor_id, action, target_user_id, details)
+    SELECT gen_random_uuid(), NOW(), sqlc.arg(actor_id)::uuid, sqlc.arg(action), chirps.user_id, chirps.id::text
+    FROM reviewed
+    JOIN chirps ON chirps.id = reviewed.chirp_id
+)
+SELECT * FROM reviewed;
+
+-- name: UnhideChirp :one
+-- Brings back a chirp hidden by a remove verdict that was overturned,
+-- returning its author. Returns no row when the chirp isn't hidden. An
+-- unhidden reply or repost is counted again on the chirp it answers or
+-- shares.
+WITH unhidden AS (
+    UPDATE chirps
+    SET deleted_at = NULL
+    WHERE id = $1 AND deleted_at IS NOT NULL
+    RETURNING user_id, parent_chirp_id, repost_of_chirp_id
+), counted AS (
+    UPDATE chirps
+    SET reply_count = chirps.reply_count + ((chirps.id = unhidden.parent_chirp_id) IS TRUE)::int,
+        repost_count = chirps.repost_count + ((chirps.id = unhidden.repost_of_chirp_id) IS TRUE)::int
+    FROM unhidden
+    WHERE chirps.id IN (unhidden.parent_chirp_id, unhidden.repost_of_chirp_id)
+), counted_archive AS (
+    UPDATE chirps_archive
+    SET reply_count = chirps_archive.reply_count + ((chirps_archive.id = unhidden.parent_chirp_id) IS TRUE)::int,
+        repost_count = chirps_archive.repost_count + ((chirps_archive.id = unhidden.repost_of_chirp_id) IS TRUE)::int
+    FROM unhidden
+    WHERE chirps_archive.id IN (unhidden.parent_chirp_id, unhidden.repost_of_chirp_id)
+)
+SELECT user_id FROM unhidden;
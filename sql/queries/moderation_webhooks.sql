@@ -0,0 +1,18 @@
+-- name: CreateModerationWebhook :one
+INSERT INTO moderation_webhooks (id, created_at, tenant_id, url, secret, events)
+VALUES (gen_random_uuid(), NOW(), $1, $2, $3, sqlc.arg(events)::text[])
+RETURNING *;
+
+-- name: ListModerationWebhooks :many
+SELECT * FROM moderation_webhooks
+WHERE tenant_id = $1
+ORDER BY created_at ASC;
+
+-- name: GetModerationWebhooksForEvent :many
+-- The community's webhooks subscribed to the event
+SELECT * FROM moderation_webhooks
+WHERE tenant_id = sqlc.arg(tenant_id) AND sqlc.arg(event)::text = ANY(events);
+
+-- name: DeleteModerationWebhook :execrows
+DELETE FROM moderation_webhooks
+WHERE id = $1 AND tenant_id = $2;
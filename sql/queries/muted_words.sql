@@ -0,0 +1,12 @@
+-- name: GetMutedWords :many
+SELECT phrase FROM user_muted_words
+WHERE user_id = $1
+ORDER BY phrase ASC;
+
+-- name: ReplaceMutedWords :exec
+WITH deleted AS (
+    DELETE FROM user_muted_words
+    WHERE user_id = $1
+)
+INSERT INTO user_muted_words (id, created_at, user_id, phrase)
+SELECT gen_random_uuid(), NOW(), $1, unnest(@phrases::text[]);
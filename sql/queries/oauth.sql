@@ -0,0 +1,96 @@
+-- name: CreateOAuthClient :one
+INSERT INTO oauth_clients (id, created_at, updated_at, client_id, secret_hash, name, redirect_uris, user_id, tenant_id)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    sqlc.arg(client_id),
+    sqlc.arg(secret_hash),
+    sqlc.arg(name),
+    sqlc.arg(redirect_uris)::text[],
+    sqlc.arg(user_id),
+    sqlc.arg(tenant_id)
+)
+RETURNING *;
+
+-- name: GetOAuthClient :one
+SELECT * FROM oauth_clients
+WHERE client_id = $1 AND tenant_id = $2;
+
+-- name: ListOAuthClientsByOwner :many
+SELECT * FROM oauth_clients
+WHERE user_id = $1
+ORDER BY created_at ASC;
+
+-- name: DeleteOAuthClient :execrows
+DELETE FROM oauth_clients
+WHERE client_id = $1 AND user_id = $2;
+
+-- name: CreateOAuthCode :exec
+INSERT INTO oauth_codes (code, created_at, client_id, user_id, redirect_uri, scopes, code_challenge, expires_at)
+VALUES (
+    sqlc.arg(code),
+    NOW(),
+    sqlc.arg(client_id),
+    sqlc.arg(user_id),
+    sqlc.arg(redirect_uri),
+    sqlc.arg(scopes)::text[],
+    sqlc.arg(code_challenge),
+    sqlc.arg(expires_at)
+);
+
+-- name: ConsumeOAuthCode :one
+-- Marks an unused, unexpired code issued to the client as used so it can be
+-- exchanged only once
+UPDATE oauth_codes
+SET used_at = NOW()
+WHERE code = sqlc.arg(code) AND client_id = sqlc.arg(client_id) AND used_at IS NULL AND expires_at > NOW()
+RETURNING *;
+
+-- name: CreateOAuthToken :one
+INSERT INTO oauth_tokens (token, created_at, updated_at, client_id, user_id, scopes, expires_at)
+VALUES (
+    sqlc.arg(token),
+    NOW(),
+    NOW(),
+    sqlc.arg(client_id),
+    sqlc.arg(user_id),
+    sqlc.arg(scopes)::text[],
+    sqlc.arg(expires_at)
+)
+RETURNING *;
+
+-- name: RotateOAuthToken :one
+-- Revokes a valid refresh token issued to the client so the caller can issue
+-- its replacement
+UPDATE oauth_tokens
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE token = sqlc.arg(token) AND client_id = sqlc.arg(client_id) AND revoked_at IS NULL AND expires_at > NOW()
+RETURNING *;
+
+-- name: ListOAuthGrants :many
+SELECT oauth_clients.client_id, oauth_clients.name, MIN(oauth_tokens.created_at)::timestamp AS authorized_at
+FROM oauth_tokens
+JOIN oauth_clients ON oauth_clients.id = oauth_tokens.client_id
+WHERE oauth_tokens.user_id = $1
+  AND oauth_tokens.revoked_at IS NULL
+  AND oauth_tokens.expires_at > NOW()
+GROUP BY oauth_clients.client_id, oauth_clients.name
+ORDER BY authorized_at ASC;
+
+-- name: RevokeOAuthGrant :execrows
+UPDATE oauth_tokens
+SET revoked_at = NOW(), updated_at = NOW()
+FROM oauth_clients
+WHERE oauth_clients.id = oauth_tokens.client_id
+  AND oauth_clients.client_id = sqlc.arg(client_id)
+  AND oauth_tokens.user_id = sqlc.arg(user_id)
+  AND oauth_tokens.revoked_at IS NULL;
+
+-- name: DeleteExpiredOAuthTokens :execrows
+WITH codes AS (
+    DELETE FROM oauth_codes
+    WHERE oauth_codes.expires_at < @cutoff::timestamp
+)
+DELETE FROM oauth_tokens
+WHERE oauth_tokens.expires_at < @cutoff::timestamp;
@@ -0,0 +1,34 @@
+-- name: CreateInvitedUser :one
+-- Creates an account without a password, returning no row if the email or
+-- handle is already taken
+INSERT INTO users (id, created_at, updated_at, email, username, tenant_id)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    sqlc.arg(email),
+    sqlc.narg(username),
+    sqlc.arg(tenant_id)
+)
+ON CONFLICT DO NOTHING
+RETURNING *;
+
+-- name: CreatePasswordResetToken :exec
+INSERT INTO password_reset_tokens (token_hash, user_id, created_at, expires_at)
+VALUES (sqlc.arg(token_hash), sqlc.arg(user_id), NOW(), sqlc.arg(expires_at));
+
+-- name: ResetPasswordWithToken :one
+-- Uses up the token and sets the password of its user. Returns no row for
+-- unknown, used or expired tokens, and for users of another community.
+WITH used AS (
+    UPDATE password_reset_tokens
+    SET used_at = NOW()
+    WHERE token_hash = sqlc.arg(token_hash) AND used_at IS NULL AND expires_at > NOW()
+      AND user_id IN (SELECT members.id FROM users AS members WHERE members.tenant_id = sqlc.arg(tenant_id))
+    RETURNING user_id
+)
+UPDATE users
+SET hashed_password = sqlc.arg(hashed_password), updated_at = NOW()
+FROM used
+WHERE users.id = used.user_id
+RETURNING users.id;
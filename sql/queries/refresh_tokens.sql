@@ -14,6 +14,7 @@ SELECT users.id, users.created_at, users.updated_at, users.email, users.hashed_p
 FROM refresh_tokens
 JOIN users ON refresh_tokens.user_id = users.id
 WHERE refresh_tokens.token = $1 
+  AND users.tenant_id = $2
   AND refresh_tokens.expires_at > NOW() 
   AND refresh_tokens.revoked_at IS NULL;
 
@@ -21,4 +22,19 @@ WHERE refresh_tokens.token = $1
 UPDATE refresh_tokens 
 SET revoked_at = NOW(), updated_at = NOW()
 WHERE token = $1
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: RevokeUserRefreshTokens :exec
+-- Revokes the user's own refresh tokens and those issued to OAuth clients
+WITH oauth AS (
+    UPDATE oauth_tokens
+    SET revoked_at = NOW(), updated_at = NOW()
+    WHERE oauth_tokens.user_id = $1 AND oauth_tokens.revoked_at IS NULL
+)
+UPDATE refresh_tokens
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE refresh_tokens.user_id = $1 AND refresh_tokens.revoked_at IS NULL;
+
+-- name: DeleteExpiredRefreshTokens :execrows
+DELETE FROM refresh_tokens
+WHERE expires_at < @cutoff::timestamp;
@@ -0,0 +1,41 @@
+-- name: CreateReport :one
+-- Returns no row when the reporter already has an open report on the chirp
+INSERT INTO reports (id, created_at, tenant_id, chirp_id, reporter_id, reason, details)
+VALUES (gen_random_uuid(), NOW(), $1, $2, $3, $4, $5)
+ON CONFLICT (chirp_id, reporter_id) WHERE resolved_at IS NULL DO NOTHING
+RETURNING *;
+
+-- name: GetReport :one
+SELECT * FROM reports
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: GetReports :many
+-- Open reports oldest first, which is the moderation queue, or resolved ones
+-- most recently resolved first. Each row counts the chirp's reports in the
+-- same state.
+SELECT reports.*, chirps.body AS chirp_body, chirps.user_id AS chirp_author_id,
+       (COUNT(*) OVER (PARTITION BY reports.chirp_id))::bigint AS chirp_report_count
+FROM reports
+JOIN chirps ON chirps.id = reports.chirp_id
+WHERE reports.tenant_id = sqlc.arg(tenant_id)
+  AND (reports.resolved_at IS NULL) = sqlc.arg(open)::bool
+ORDER BY CASE WHEN sqlc.arg(open)::bool THEN reports.created_at END ASC,
+         reports.resolved_at DESC, reports.id ASC
+LIMIT sqlc.arg(page_size) OFFSET sqlc.arg(page_offset);
+
+-- name: ResolveReports :many
+-- Resolves every open report on the chirp and records the resolution in the
+-- audit log against the chirp's author, with the chirp ID as details
+WITH resolved AS (
+    UPDATE reports
+    SET resolved_at = NOW(), resolved_by = sqlc.arg(actor_id)::uuid, resolution = sqlc.arg(resolution)::text
+    WHERE reports.chirp_id = sqlc.arg(chirp_id) AND reports.tenant_id = sqlc.arg(tenant_id)
+      AND reports.resolved_at IS NULL
+    RETURNING *
+), audit AS (
+    INSERT INTO admin_audit_log (id, created_at, actor_id, action, target_user_id, details)
+    SELECT gen_random_uuid(), NOW(), sqlc.arg(actor_id)::uuid, sqlc.arg(action), chirps.user_id, chirps.id::text
+    FROM chirps
+    WHERE chirps.id = sqlc.arg(chirp_id) AND EXISTS (SELECT 1 FROM resolved)
+)
+SELECT * FROM resolved;
@@ -1,2 +1,7 @@
 -- name: Reset :exec
+WITH audit AS (
+    DELETE FROM admin_audit_log
+), extra_tenants AS (
+    DELETE FROM tenants WHERE slug <> 'default'
+)
 DELETE FROM users;
@@ -0,0 +1,40 @@
+-- name: CreateAuditLogEntry :exec
+INSERT INTO admin_audit_log (id, created_at, actor_id, action, target_user_id, details)
+VALUES (gen_random_uuid(), NOW(), sqlc.arg(actor_id), sqlc.arg(action), sqlc.narg(target_user_id), sqlc.arg(details));
+
+-- name: CountRevokedTokens :one
+SELECT (
+    (SELECT COUNT(*) FROM refresh_tokens
+     WHERE refresh_tokens.revoked_at < @cutoff::timestamp
+       AND refresh_tokens.user_id NOT IN (SELECT id FROM users WHERE legal_hold))
+    + (SELECT COUNT(*) FROM oauth_tokens
+       WHERE oauth_tokens.revoked_at < @cutoff::timestamp
+         AND oauth_tokens.user_id NOT IN (SELECT id FROM users WHERE legal_hold))
+)::bigint AS count;
+
+-- name: DeleteRevokedTokens :one
+-- Deletes refresh tokens of both users and OAuth clients revoked before the
+-- cutoff, except those of users under legal hold
+WITH refresh AS (
+    DELETE FROM refresh_tokens
+    WHERE refresh_tokens.revoked_at < @cutoff::timestamp
+      AND refresh_tokens.user_id NOT IN (SELECT id FROM users WHERE legal_hold)
+    RETURNING 1
+), oauth AS (
+    DELETE FROM oauth_tokens
+    WHERE oauth_tokens.revoked_at < @cutoff::timestamp
+      AND oauth_tokens.user_id NOT IN (SELECT id FROM users WHERE legal_hold)
+    RETURNING 1
+)
+SELECT ((SELECT COUNT(*) FROM refresh) + (SELECT COUNT(*) FROM oauth))::bigint AS deleted;
+
+-- name: CountOldAuditLogEntries :one
+SELECT COUNT(*) FROM admin_audit_log
+WHERE created_at < @cutoff::timestamp
+  AND (target_user_id IS NULL OR target_user_id NOT IN (SELECT id FROM users WHERE legal_hold));
+
+-- name: DeleteOldAuditLogEntries :execrows
+-- Entries about users under legal hold are kept
+DELETE FROM admin_audit_log
+WHERE created_at < @cutoff::timestamp
+  AND (target_user_id IS NULL OR target_user_id NOT IN (SELECT id FROM users WHERE legal_hold));
@@ -0,0 +1,58 @@
+-- name: ScheduleChirp :one
+-- Queues a new chirp and sets the time it is published at
+WITH queued AS (
+    INSERT INTO scheduled_chirps (chirp_id, created_at)
+    VALUES (sqlc.arg(chirp_id), NOW())
+)
+UPDATE chirps
+SET published_at = sqlc.arg(publish_at)
+WHERE id = sqlc.arg(chirp_id)
+RETURNING *;
+
+-- name: GetScheduledChirps :many
+-- A user's chirps still waiting to be published, soonest first
+SELECT chirps.* FROM chirps
+JOIN scheduled_chirps ON scheduled_chirps.chirp_id = chirps.id
+WHERE chirps.user_id = $1
+  AND chirps.published_at > NOW()
+  AND chirps.deleted_at IS NULL
+ORDER BY chirps.published_at ASC, chirps.id ASC;
+
+-- name: GetScheduledChirp :one
+SELECT chirps.* FROM chirps
+JOIN scheduled_chirps ON scheduled_chirps.chirp_id = chirps.id
+WHERE chirps.id = sqlc.arg(id)
+  AND chirps.user_id = sqlc.arg(user_id)
+  AND chirps.published_at > NOW()
+  AND chirps.deleted_at IS NULL;
+
+-- name: UpdateScheduledChirp :one
+-- Edits or reschedules a chirp that hasn't been published yet. Omitted
+-- fields keep their value.
+UPDATE chirps
+SET body = COALESCE(sqlc.narg(body)::text, body),
+    language = COALESCE(sqlc.narg(language)::text, language),
+    sensitive = COALESCE(sqlc.narg(sensitive)::bool, sensitive),
+    published_at = COALESCE(sqlc.narg(publish_at)::timestamp, published_at),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+  AND user_id = sqlc.arg(user_id)
+  AND published_at > NOW()
+  AND deleted_at IS NULL
+  AND EXISTS (SELECT 1 FROM scheduled_chirps WHERE scheduled_chirps.chirp_id = chirps.id)
+RETURNING *;
+
+-- name: TakeDueScheduledChirps :many
+-- Dequeues scheduled chirps whose time has come so they can be announced.
+-- Deleted chirps stay queued in case they are restored.
+WITH due AS (
+    DELETE FROM scheduled_chirps
+    WHERE scheduled_chirps.chirp_id IN (
+        SELECT chirps.id FROM chirps
+        WHERE chirps.published_at <= NOW() AND chirps.deleted_at IS NULL
+    )
+    RETURNING scheduled_chirps.chirp_id
+)
+SELECT chirps.id, chirps.user_id, chirps.published_at
+FROM chirps
+JOIN due ON due.chirp_id = chirps.id;
@@ -0,0 +1,67 @@
+-- name: CreateSCIMUser :one
+-- Returns no row when the email or handle is already taken
+WITH created AS (
+    INSERT INTO users (id, created_at, updated_at, email, hashed_password, username, tenant_id, deactivated_at)
+    VALUES (
+        gen_random_uuid(),
+        NOW(),
+        NOW(),
+        sqlc.arg(email),
+        COALESCE(sqlc.narg(hashed_password)::text, 'unset'),
+        sqlc.narg(username),
+        sqlc.arg(tenant_id),
+        CASE WHEN sqlc.arg(active)::boolean THEN NULL ELSE NOW() END
+    )
+    ON CONFLICT DO NOTHING
+    RETURNING id
+), linked AS (
+    INSERT INTO scim_users (user_id, external_id, suspended)
+    SELECT id, sqlc.narg(external_id), NOT sqlc.arg(active)::boolean FROM created
+)
+SELECT id FROM created;
+
+-- name: ReplaceSCIMUser :one
+-- The handle and password are kept unless new ones are given. Returns no row
+-- when the user isn't in the tenant.
+WITH updated AS (
+    UPDATE users
+    SET email = sqlc.arg(email),
+        hashed_password = COALESCE(sqlc.narg(hashed_password), hashed_password),
+        username = COALESCE(sqlc.narg(username), username),
+        deactivated_at = CASE WHEN sqlc.arg(active)::boolean THEN NULL ELSE COALESCE(deactivated_at, NOW()) END,
+        updated_at = NOW()
+    WHERE id = sqlc.arg(id) AND tenant_id = sqlc.arg(tenant_id)
+    RETURNING id
+), linked AS (
+    INSERT INTO scim_users (user_id, external_id, suspended)
+    SELECT id, sqlc.narg(external_id), NOT sqlc.arg(active)::boolean FROM updated
+    ON CONFLICT (user_id) DO UPDATE
+    SET external_id = EXCLUDED.external_id, suspended = EXCLUDED.suspended
+)
+SELECT id FROM updated;
+
+-- name: GetSCIMUser :one
+SELECT users.id, users.created_at, users.updated_at, users.email, users.username, users.deactivated_at, scim_users.external_id
+FROM users
+LEFT JOIN scim_users ON scim_users.user_id = users.id
+WHERE users.id = sqlc.arg(id) AND users.tenant_id = sqlc.arg(tenant_id);
+
+-- name: ListSCIMUsers :many
+-- user_name filters by email, ignoring case as SCIM does for userName
+SELECT users.id, users.created_at, users.updated_at, users.email, users.username, users.deactivated_at, scim_users.external_id
+FROM users
+LEFT JOIN scim_users ON scim_users.user_id = users.id
+WHERE users.tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(user_name)::text IS NULL OR lower(users.email) = lower(sqlc.narg(user_name)))
+ORDER BY users.created_at ASC, users.id ASC
+LIMIT sqlc.arg(max_users) OFFSET sqlc.arg(skip);
+
+-- name: CountSCIMUsers :one
+SELECT COUNT(*) FROM users
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(user_name)::text IS NULL OR lower(email) = lower(sqlc.narg(user_name)));
+
+-- name: IsUserSuspended :one
+SELECT EXISTS (
+    SELECT 1 FROM scim_users WHERE user_id = $1 AND suspended
+)::boolean AS suspended;
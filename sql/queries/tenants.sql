@@ -0,0 +1,18 @@
+-- name: CreateTenant :one
+INSERT INTO tenants (id, created_at, slug, name, description)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    sqlc.arg(slug),
+    sqlc.arg(name),
+    sqlc.arg(description)
+)
+RETURNING *;
+
+-- name: GetTenantBySlug :one
+SELECT * FROM tenants
+WHERE slug = $1;
+
+-- name: ListTenants :many
+SELECT * FROM tenants
+ORDER BY created_at ASC;
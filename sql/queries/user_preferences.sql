@@ -0,0 +1,10 @@
+-- name: GetUserPreferences :one
+SELECT * FROM user_preferences
+WHERE user_id = $1;
+
+-- name: UpsertUserPreferences :one
+INSERT INTO user_preferences (user_id, updated_at, sensitive_content)
+VALUES ($1, NOW(), $2)
+ON CONFLICT (user_id) DO UPDATE
+SET updated_at = NOW(), sensitive_content = EXCLUDED.sensitive_content
+RETURNING *;
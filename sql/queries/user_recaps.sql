@@ -0,0 +1,52 @@
+-- name: GenerateUserRecaps :execrows
+-- Summarises every user who published a chirp in [period_start, period_end).
+-- The most liked chirp is the one with the most reactions, the earliest on a
+-- tie; hashtags are counted case-insensitively and the top five kept.
+WITH published AS (
+    SELECT id, user_id, body, published_at FROM chirps
+    WHERE published_at >= @period_start::timestamp AND published_at < @period_end::timestamp
+      AND deleted_at IS NULL
+), totals AS (
+    SELECT user_id, COUNT(*) AS total_chirps FROM published
+    GROUP BY user_id
+), liked AS (
+    SELECT DISTINCT ON (p.user_id) p.user_id, p.id AS chirp_id, COUNT(*) AS reactions
+    FROM published p
+    JOIN chirp_reactions r ON r.chirp_id = p.id
+    GROUP BY p.user_id, p.id, p.published_at
+    ORDER BY p.user_id, COUNT(*) DESC, p.published_at
+), ranked AS (
+    SELECT p.user_id, LOWER(m.tag[1]) AS hashtag,
+        ROW_NUMBER() OVER (PARTITION BY p.user_id ORDER BY COUNT(*) DESC, LOWER(m.tag[1])) AS rank
+    FROM published p, regexp_matches(p.body, '#([[:alnum:]_]+)', 'g') AS m(tag)
+    GROUP BY p.user_id, LOWER(m.tag[1])
+), hashtags AS (
+    SELECT user_id, array_agg(hashtag ORDER BY rank)::text[] AS top_hashtags FROM ranked
+    WHERE rank <= 5
+    GROUP BY user_id
+)
+INSERT INTO user_recaps (user_id, period, period_start, period_end, created_at, total_chirps, most_liked_chirp_id, most_liked_reactions, top_hashtags)
+SELECT t.user_id, @period, @period_start::timestamp, @period_end::timestamp, NOW(), t.total_chirps,
+    l.chirp_id, COALESCE(l.reactions, 0), COALESCE(h.top_hashtags, '{}')
+FROM totals t
+LEFT JOIN liked l ON l.user_id = t.user_id
+LEFT JOIN hashtags h ON h.user_id = t.user_id
+ON CONFLICT (user_id, period, period_start) DO UPDATE
+SET period_end = EXCLUDED.period_end,
+    created_at = EXCLUDED.created_at,
+    total_chirps = EXCLUDED.total_chirps,
+    most_liked_chirp_id = EXCLUDED.most_liked_chirp_id,
+    most_liked_reactions = EXCLUDED.most_liked_reactions,
+    top_hashtags = EXCLUDED.top_hashtags;
+
+-- name: HasUserRecaps :one
+SELECT EXISTS (
+    SELECT 1 FROM user_recaps
+    WHERE period = $1 AND period_start = $2
+);
+
+-- name: GetLatestUserRecap :one
+SELECT * FROM user_recaps
+WHERE user_id = $1 AND period = $2
+ORDER BY period_start DESC
+LIMIT 1;
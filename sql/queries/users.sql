@@ -6,30 +6,121 @@ VALUES (
     NOW(),
     $1
 )
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red;
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url;
 
 -- name: CreateUserWithPassword :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, username, tenant_id)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
-    $1,
-    $2
+    sqlc.arg(email),
+    sqlc.arg(hashed_password),
+    sqlc.narg(username),
+    sqlc.arg(tenant_id)
 )
 RETURNING *;
 
 -- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users WHERE email = $1;
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url FROM users WHERE tenant_id = $1 AND email = $2;
 
 -- name: UpdateUser :one
+-- NULL arguments keep the stored value
 UPDATE users 
-SET email = $2, hashed_password = $3, updated_at = NOW()
-WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red;
+SET email = COALESCE(sqlc.narg(email), email),
+    hashed_password = COALESCE(sqlc.narg(hashed_password), hashed_password),
+    username = COALESCE(sqlc.narg(username), username),
+    display_name = COALESCE(sqlc.narg(display_name), display_name),
+    bio = COALESCE(sqlc.narg(bio), bio),
+    location = COALESCE(sqlc.narg(location), location),
+    website = COALESCE(sqlc.narg(website), website),
+    avatar_url = COALESCE(sqlc.narg(avatar_url), avatar_url),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url;
 
 -- name: UpgradeUserToChirpyRed :one
 UPDATE users 
 SET is_chirpy_red = TRUE, updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red;
\ No newline at end of file
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url;
+
+-- name: ReplayUserUpgrade :execrows
+-- Reapplies a journaled upgrade; updated_at never moves backwards
+UPDATE users
+SET is_chirpy_red = TRUE, updated_at = GREATEST(updated_at, sqlc.arg(upgraded_at)::timestamp)
+WHERE id = sqlc.arg(id);
+
+-- name: GetUserRole :one
+SELECT role FROM users WHERE id = $1;
+
+-- name: DeactivateUser :exec
+UPDATE users
+SET deactivated_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND deactivated_at IS NULL;
+
+-- name: ReactivateUser :one
+UPDATE users
+SET deactivated_at = NULL, updated_at = NOW()
+WHERE id = $1
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url;
+
+-- name: IsUserActive :one
+SELECT (deactivated_at IS NULL)::boolean AS active FROM users WHERE id = $1;
+
+-- name: DeleteDeactivatedUsers :execrows
+-- Accounts under legal hold are kept until the hold is released, and
+-- accounts their owners asked to delete wait for their own deadline
+DELETE FROM users
+WHERE deactivated_at IS NOT NULL AND deactivated_at < @cutoff::timestamp
+  AND NOT legal_hold
+  AND id NOT IN (SELECT user_id FROM account_deletions);
+
+-- name: SetUserVerified :one
+WITH updated AS (
+    UPDATE users
+    SET verified = sqlc.arg(verified), updated_at = NOW()
+    WHERE users.id = sqlc.arg(id) AND users.tenant_id = sqlc.arg(tenant_id)
+    RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url
+), audit AS (
+    INSERT INTO admin_audit_log (id, created_at, actor_id, action, target_user_id)
+    SELECT gen_random_uuid(), NOW(), sqlc.arg(actor_id), sqlc.arg(action), updated.id
+    FROM updated
+)
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url
+FROM updated;
+
+-- name: SetUserLegalHold :one
+WITH updated AS (
+    UPDATE users
+    SET legal_hold = sqlc.arg(legal_hold), updated_at = NOW()
+    WHERE users.id = sqlc.arg(id) AND users.tenant_id = sqlc.arg(tenant_id)
+    RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url
+), audit AS (
+    INSERT INTO admin_audit_log (id, created_at, actor_id, action, target_user_id)
+    SELECT gen_random_uuid(), NOW(), sqlc.arg(actor_id), sqlc.arg(action), updated.id
+    FROM updated
+)
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url
+FROM updated;
+
+-- name: IsUserOnLegalHold :one
+SELECT legal_hold FROM users WHERE id = $1;
+
+-- name: GetChirpAuthors :many
+SELECT id, username, verified, display_name, bio, location, website, avatar_url FROM users
+WHERE id = ANY(@user_ids::uuid[]);
+
+-- name: IsActiveUserInTenant :one
+SELECT EXISTS (
+    SELECT 1 FROM users
+    WHERE id = sqlc.arg(id) AND tenant_id = sqlc.arg(tenant_id) AND deactivated_at IS NULL
+)::boolean AS found;
+
+-- name: GetUserByID :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, role, deactivated_at, username, verified, tenant_id, legal_hold, display_name, bio, location, website, avatar_url FROM users WHERE tenant_id = $1 AND id = $2;
+
+-- name: GetUserByUsername :one
+-- Public profile of an active user, looked up by handle
+SELECT id, created_at, username, verified, display_name, bio, location, website, avatar_url FROM users
+WHERE tenant_id = $1 AND username = $2 AND deactivated_at IS NULL;
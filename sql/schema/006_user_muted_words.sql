@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE user_muted_words (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    phrase TEXT NOT NULL
+);
+
+CREATE INDEX idx_user_muted_words_user_id ON user_muted_words(user_id);
+
+-- +goose Down
+DROP TABLE user_muted_words;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE chirp_media (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    url TEXT NOT NULL,
+    alt_text TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_chirp_media_chirp_id ON chirp_media(chirp_id);
+
+-- +goose Down
+DROP TABLE chirp_media;
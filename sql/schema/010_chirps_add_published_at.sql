@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE chirps ADD COLUMN published_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+-- +goose Down
+ALTER TABLE chirps DROP COLUMN published_at;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE admin_audit_log (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    actor_id UUID NOT NULL,
+    action TEXT NOT NULL,
+    target_user_id UUID
+);
+
+CREATE INDEX idx_admin_audit_log_target_user_id ON admin_audit_log(target_user_id);
+
+-- +goose Down
+DROP TABLE admin_audit_log;
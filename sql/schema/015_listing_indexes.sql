@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE INDEX idx_chirps_created_at ON chirps(created_at);
+CREATE INDEX idx_chirps_user_id_created_at ON chirps(user_id, created_at);
+CREATE INDEX idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
+
+-- +goose Down
+DROP INDEX idx_refresh_tokens_expires_at;
+DROP INDEX idx_chirps_user_id_created_at;
+DROP INDEX idx_chirps_created_at;
@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE TABLE chirps_archive (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    body TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    published_at TIMESTAMP NOT NULL,
+    archived_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_chirps_archive_user_id ON chirps_archive(user_id);
+
+CREATE TABLE chirp_media_archive (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    chirp_id UUID NOT NULL REFERENCES chirps_archive(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    url TEXT NOT NULL,
+    alt_text TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_chirp_media_archive_chirp_id ON chirp_media_archive(chirp_id);
+
+CREATE TABLE chirp_revisions_archive (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    chirp_id UUID NOT NULL REFERENCES chirps_archive(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    body TEXT NOT NULL,
+    UNIQUE (chirp_id, revision)
+);
+
+-- +goose Down
+DROP TABLE chirp_revisions_archive;
+DROP TABLE chirp_media_archive;
+DROP TABLE chirps_archive;
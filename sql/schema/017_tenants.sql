@@ -0,0 +1,42 @@
+-- +goose Up
+CREATE TABLE tenants (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    slug TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+-- Existing data belongs to the default community
+INSERT INTO tenants (id, created_at, slug, name)
+VALUES ('00000000-0000-0000-0000-000000000001', NOW(), 'default', 'Chirpy');
+
+ALTER TABLE users
+    ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+ALTER TABLE users DROP CONSTRAINT users_email_key;
+ALTER TABLE users ADD CONSTRAINT users_tenant_email_key UNIQUE (tenant_id, email);
+ALTER TABLE users DROP CONSTRAINT users_username_key;
+ALTER TABLE users ADD CONSTRAINT users_tenant_username_key UNIQUE (tenant_id, username);
+
+ALTER TABLE chirps
+    ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+DROP INDEX idx_chirps_created_at;
+CREATE INDEX idx_chirps_tenant_id_created_at ON chirps(tenant_id, created_at);
+
+ALTER TABLE chirps_archive
+    ADD COLUMN tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000001' REFERENCES tenants(id);
+
+-- +goose Down
+ALTER TABLE chirps_archive DROP COLUMN tenant_id;
+
+DROP INDEX idx_chirps_tenant_id_created_at;
+CREATE INDEX idx_chirps_created_at ON chirps(created_at);
+ALTER TABLE chirps DROP COLUMN tenant_id;
+
+ALTER TABLE users DROP CONSTRAINT users_tenant_username_key;
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
+ALTER TABLE users DROP CONSTRAINT users_tenant_email_key;
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+ALTER TABLE users DROP COLUMN tenant_id;
+
+DROP TABLE tenants;
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE chirp_coauthors (
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chirp_id, user_id)
+);
+
+CREATE INDEX idx_chirp_coauthors_user_id_status ON chirp_coauthors(user_id, status);
+
+CREATE TABLE chirp_coauthors_archive (
+    chirp_id UUID NOT NULL REFERENCES chirps_archive(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chirp_id, user_id)
+);
+
+-- +goose Down
+DROP TABLE chirp_coauthors_archive;
+DROP TABLE chirp_coauthors;
@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE chirp_reactions (
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chirp_id, emoji, user_id)
+);
+
+CREATE TABLE chirp_reactions_archive (
+    chirp_id UUID NOT NULL REFERENCES chirps_archive(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chirp_id, emoji, user_id)
+);
+
+-- +goose Down
+DROP TABLE chirp_reactions_archive;
+DROP TABLE chirp_reactions;
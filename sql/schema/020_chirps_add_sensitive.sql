@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE chirps ADD COLUMN sensitive BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE chirps_archive ADD COLUMN sensitive BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE user_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    updated_at TIMESTAMP NOT NULL,
+    sensitive_content TEXT NOT NULL DEFAULT 'blur'
+);
+
+-- +goose Down
+DROP TABLE user_preferences;
+ALTER TABLE chirps_archive DROP COLUMN sensitive;
+ALTER TABLE chirps DROP COLUMN sensitive;
@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE chirps ADD COLUMN source TEXT NOT NULL DEFAULT '';
+ALTER TABLE chirps ADD COLUMN oauth_client_id UUID REFERENCES oauth_clients(id) ON DELETE SET NULL;
+ALTER TABLE chirps_archive ADD COLUMN source TEXT NOT NULL DEFAULT '';
+ALTER TABLE chirps_archive ADD COLUMN oauth_client_id UUID REFERENCES oauth_clients(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_chirps_oauth_client_id ON chirps(oauth_client_id);
+
+-- +goose Down
+DROP INDEX idx_chirps_oauth_client_id;
+ALTER TABLE chirps_archive DROP COLUMN oauth_client_id;
+ALTER TABLE chirps_archive DROP COLUMN source;
+ALTER TABLE chirps DROP COLUMN oauth_client_id;
+ALTER TABLE chirps DROP COLUMN source;
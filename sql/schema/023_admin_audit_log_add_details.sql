@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE admin_audit_log ADD COLUMN details TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE admin_audit_log DROP COLUMN details;
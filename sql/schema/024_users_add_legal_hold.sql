@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN legal_hold BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN legal_hold;
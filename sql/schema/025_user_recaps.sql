@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE user_recaps (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period TEXT NOT NULL,
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    total_chirps INTEGER NOT NULL,
+    most_liked_chirp_id UUID REFERENCES chirps(id) ON DELETE SET NULL,
+    most_liked_reactions INTEGER NOT NULL DEFAULT 0,
+    top_hashtags TEXT[] NOT NULL DEFAULT '{}',
+    PRIMARY KEY (user_id, period, period_start)
+);
+
+CREATE INDEX idx_user_recaps_period_start ON user_recaps(period, period_start);
+
+-- +goose Down
+DROP TABLE user_recaps;
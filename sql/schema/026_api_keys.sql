@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    name TEXT NOT NULL,
+    tier TEXT NOT NULL,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    requests BIGINT NOT NULL DEFAULT 0,
+    chirps_delivered BIGINT NOT NULL DEFAULT 0,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_api_keys_tenant_id ON api_keys(tenant_id);
+
+-- +goose Down
+DROP TABLE api_keys;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- No foreign key: a reply keeps pointing at its parent after the parent is
+-- archived, which moves it out of chirps
+ALTER TABLE chirps ADD COLUMN parent_chirp_id UUID;
+ALTER TABLE chirps_archive ADD COLUMN parent_chirp_id UUID;
+
+CREATE INDEX idx_chirps_parent_chirp_id ON chirps(parent_chirp_id);
+
+-- +goose Down
+DROP INDEX idx_chirps_parent_chirp_id;
+ALTER TABLE chirps_archive DROP COLUMN parent_chirp_id;
+ALTER TABLE chirps DROP COLUMN parent_chirp_id;
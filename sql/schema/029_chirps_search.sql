@@ -0,0 +1,6 @@
+-- +goose Up
+-- Search queries must use this exact expression to hit the index
+CREATE INDEX idx_chirps_search ON chirps USING GIN (to_tsvector('english', body));
+
+-- +goose Down
+DROP INDEX idx_chirps_search;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE chirps ADD COLUMN locked BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE chirps_archive ADD COLUMN locked BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE chirps_archive DROP COLUMN locked;
+ALTER TABLE chirps DROP COLUMN locked;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Tags are stored lowercase without the #. Archived chirps lose their tags,
+-- as they no longer appear in listings.
+CREATE TABLE chirp_hashtags (
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chirp_id, tag)
+);
+
+CREATE INDEX idx_chirp_hashtags_tag ON chirp_hashtags(tag, created_at);
+
+INSERT INTO chirp_hashtags (chirp_id, tag, created_at)
+SELECT DISTINCT chirps.id, lower(match[1]), chirps.created_at
+FROM chirps, regexp_matches(chirps.body, '(?:^|[^[:alnum:]_#])#([[:alnum:]_]+)', 'g') AS match;
+
+-- +goose Down
+DROP TABLE chirp_hashtags;
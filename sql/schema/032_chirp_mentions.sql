@@ -0,0 +1,13 @@
+-- +goose Up
+-- Archived chirps lose their mentions, as they no longer appear in listings
+CREATE TABLE chirp_mentions (
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chirp_id, user_id)
+);
+
+CREATE INDEX idx_chirp_mentions_user_id ON chirp_mentions(user_id, created_at);
+
+-- +goose Down
+DROP TABLE chirp_mentions;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- A repost is a chirp with an empty body pointing at the chirp it shares.
+-- Like parent_chirp_id there is no foreign key, so reposts outlive the
+-- original being archived.
+ALTER TABLE chirps ADD COLUMN repost_of_chirp_id UUID;
+ALTER TABLE chirps_archive ADD COLUMN repost_of_chirp_id UUID;
+
+-- Each user reposts a chirp at most once
+CREATE UNIQUE INDEX idx_chirps_repost_of_chirp_id ON chirps(repost_of_chirp_id, user_id)
+    WHERE repost_of_chirp_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX idx_chirps_repost_of_chirp_id;
+ALTER TABLE chirps_archive DROP COLUMN repost_of_chirp_id;
+ALTER TABLE chirps DROP COLUMN repost_of_chirp_id;
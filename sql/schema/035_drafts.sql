@@ -0,0 +1,16 @@
+-- +goose Up
+-- Drafts live apart from chirps so no listing can show them
+CREATE TABLE drafts (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    sensitive BOOLEAN NOT NULL DEFAULT false,
+    parent_chirp_id UUID
+);
+
+CREATE INDEX idx_drafts_user_id ON drafts(user_id, updated_at);
+
+-- +goose Down
+DROP TABLE drafts;
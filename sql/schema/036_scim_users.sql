@@ -0,0 +1,11 @@
+-- +goose Up
+-- Accounts an identity provider manages over SCIM. A suspended account was
+-- deactivated by the provider and can't be restored by logging in.
+CREATE TABLE scim_users (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    external_id TEXT,
+    suspended BOOLEAN NOT NULL DEFAULT false
+);
+
+-- +goose Down
+DROP TABLE scim_users;
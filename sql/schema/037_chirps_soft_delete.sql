@@ -0,0 +1,19 @@
+-- +goose Up
+-- Deleted chirps stay restorable by their author for a while before the
+-- purge job removes them
+ALTER TABLE chirps ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX idx_chirps_deleted_at ON chirps(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- A deleted repost doesn't stop the user reposting the chirp again
+DROP INDEX idx_chirps_repost_of_chirp_id;
+CREATE UNIQUE INDEX idx_chirps_repost_of_chirp_id ON chirps(repost_of_chirp_id, user_id)
+    WHERE repost_of_chirp_id IS NOT NULL AND deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX idx_chirps_repost_of_chirp_id;
+DELETE FROM chirps WHERE deleted_at IS NOT NULL;
+CREATE UNIQUE INDEX idx_chirps_repost_of_chirp_id ON chirps(repost_of_chirp_id, user_id)
+    WHERE repost_of_chirp_id IS NOT NULL;
+DROP INDEX idx_chirps_deleted_at;
+ALTER TABLE chirps DROP COLUMN deleted_at;
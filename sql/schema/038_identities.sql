@@ -0,0 +1,17 @@
+-- +goose Up
+-- Links accounts to the subjects an OpenID Connect provider signs them in
+-- as. A subject is only unique within its issuer.
+CREATE TABLE identities (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    issuer TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    UNIQUE (tenant_id, issuer, subject)
+);
+
+CREATE INDEX idx_identities_user_id ON identities (user_id);
+
+-- +goose Down
+DROP TABLE identities;
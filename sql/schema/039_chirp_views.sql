@@ -0,0 +1,16 @@
+-- +goose Up
+-- How many times each chirp has been served. Counts are buffered in memory
+-- and added in batches, so they lag behind by up to a flush interval.
+CREATE TABLE chirp_views (
+    chirp_id UUID PRIMARY KEY REFERENCES chirps(id) ON DELETE CASCADE,
+    view_count BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE TABLE chirp_views_archive (
+    chirp_id UUID PRIMARY KEY REFERENCES chirps_archive(id) ON DELETE CASCADE,
+    view_count BIGINT NOT NULL DEFAULT 0
+);
+
+-- +goose Down
+DROP TABLE chirp_views_archive;
+DROP TABLE chirp_views;
@@ -0,0 +1,10 @@
+-- +goose Up
+-- Chirps scheduled for later. A row stays until the scheduler announces the
+-- chirp once its published_at passes.
+CREATE TABLE scheduled_chirps (
+    chirp_id UUID PRIMARY KEY REFERENCES chirps(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE scheduled_chirps;
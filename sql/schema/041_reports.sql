@@ -0,0 +1,22 @@
+-- +goose Up
+-- Chirps users flagged for moderators. Reports are resolved together for
+-- their chirp, so the queue holds each flagged chirp once per reporter.
+CREATE TABLE reports (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    details TEXT NOT NULL DEFAULT '',
+    resolved_at TIMESTAMP,
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolution TEXT
+);
+
+-- A user can have one open report per chirp
+CREATE UNIQUE INDEX idx_reports_open_chirp_id_reporter_id ON reports (chirp_id, reporter_id) WHERE resolved_at IS NULL;
+CREATE INDEX idx_reports_tenant_id_created_at ON reports (tenant_id, created_at);
+
+-- +goose Down
+DROP TABLE reports;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- The language detected in the body when the chirp was posted or last
+-- edited, as an ISO 639-1 code. Empty when it couldn't be told.
+ALTER TABLE chirps ADD COLUMN language TEXT NOT NULL DEFAULT '';
+ALTER TABLE chirps_archive ADD COLUMN language TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_chirps_language ON chirps(tenant_id, language, created_at) WHERE language <> '';
+
+-- +goose Down
+DROP INDEX idx_chirps_language;
+ALTER TABLE chirps_archive DROP COLUMN language;
+ALTER TABLE chirps DROP COLUMN language;
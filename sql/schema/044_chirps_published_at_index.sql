@@ -0,0 +1,6 @@
+-- +goose Up
+-- The ranked feed picks its candidates by publish time
+CREATE INDEX idx_chirps_tenant_id_published_at ON chirps(tenant_id, published_at);
+
+-- +goose Down
+DROP INDEX idx_chirps_tenant_id_published_at;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Previews are fetched in the background and shared by every chirp linking
+-- to the same URL; fetched_at decides when one is fetched again
+CREATE TABLE link_previews (
+    url TEXT PRIMARY KEY,
+    fetched_at TIMESTAMP NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL,
+    image_url TEXT NOT NULL
+);
+
+-- The link a chirp shows a preview of: the first link in its body to an
+-- allowed host
+CREATE TABLE chirp_links (
+    chirp_id UUID PRIMARY KEY REFERENCES chirps(id) ON DELETE CASCADE,
+    url TEXT NOT NULL
+);
+
+-- +goose Down
+DROP TABLE chirp_links;
+DROP TABLE link_previews;
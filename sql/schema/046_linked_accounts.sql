@@ -0,0 +1,13 @@
+-- +goose Up
+-- Accounts a user has proven they also own, so a client can switch between
+-- them without signing in again. Each link is stored in both directions.
+CREATE TABLE linked_accounts (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    linked_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, linked_user_id),
+    CHECK (user_id <> linked_user_id)
+);
+
+-- +goose Down
+DROP TABLE linked_accounts;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- A short note shown in place of a sensitive chirp, such as "spoilers".
+-- Empty for chirps without one; a warning always comes with sensitive set.
+ALTER TABLE chirps ADD COLUMN content_warning TEXT NOT NULL DEFAULT '';
+ALTER TABLE chirps_archive ADD COLUMN content_warning TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE chirps_archive DROP COLUMN content_warning;
+ALTER TABLE chirps DROP COLUMN content_warning;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Account bundles imported from other instances. Each bundle can be imported
+-- once, so a retried import doesn't duplicate its chirps.
+CREATE TABLE account_migrations (
+    origin TEXT NOT NULL,
+    source_account TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    imported_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (origin, source_account)
+);
+
+-- +goose Down
+DROP TABLE account_migrations;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Domains people point at a community or at a single profile. A domain is
+-- only routed once its verification token is found in a TXT record.
+CREATE TABLE custom_domains (
+    domain TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    verification_token TEXT NOT NULL,
+    verified_at TIMESTAMP,
+    checked_at TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE custom_domains;
@@ -0,0 +1,15 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN display_name TEXT NOT NULL DEFAULT '',
+    ADD COLUMN bio TEXT NOT NULL DEFAULT '',
+    ADD COLUMN location TEXT NOT NULL DEFAULT '',
+    ADD COLUMN website TEXT NOT NULL DEFAULT '',
+    ADD COLUMN avatar_url TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE users
+    DROP COLUMN avatar_url,
+    DROP COLUMN website,
+    DROP COLUMN location,
+    DROP COLUMN bio,
+    DROP COLUMN display_name;
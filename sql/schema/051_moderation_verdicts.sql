@@ -0,0 +1,22 @@
+-- +goose Up
+-- Verdicts of the external classifier that scores chirps after they are
+-- published. Allowed chirps aren't recorded; flag and remove verdicts wait
+-- here for a moderator to uphold or overturn them.
+CREATE TABLE moderation_verdicts (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    verdict TEXT NOT NULL,
+    label TEXT NOT NULL DEFAULT '',
+    score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    reviewed_at TIMESTAMP,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    outcome TEXT
+);
+
+CREATE INDEX idx_moderation_verdicts_chirp_id ON moderation_verdicts (chirp_id);
+CREATE INDEX idx_moderation_verdicts_tenant_id_created_at ON moderation_verdicts (tenant_id, created_at);
+
+-- +goose Down
+DROP TABLE moderation_verdicts;
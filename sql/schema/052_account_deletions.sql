@@ -0,0 +1,13 @@
+-- +goose Up
+-- Accounts their owners asked to delete. The account is deactivated straight
+-- away and deleted, with its chirps, once delete_after has passed.
+CREATE TABLE account_deletions (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    requested_at TIMESTAMP NOT NULL,
+    delete_after TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_account_deletions_delete_after ON account_deletions (delete_after);
+
+-- +goose Down
+DROP TABLE account_deletions;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Who follows whom. Both users belong to the same community.
+CREATE TABLE follows (
+    follower_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    followee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX idx_follows_followee_id_created_at ON follows (followee_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE follows;
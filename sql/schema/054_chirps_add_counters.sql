@@ -0,0 +1,46 @@
+-- +goose Up
+-- Likes, replies and reposts counted on the chirp itself, so listings don't
+-- count them on every read. The statements that add or remove a like, reply
+-- or repost update the counter in the same statement, and a nightly job
+-- recounts them to fix drift, such as from deactivated accounts.
+ALTER TABLE chirps ADD COLUMN like_count BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE chirps ADD COLUMN reply_count BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE chirps ADD COLUMN repost_count BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE chirps_archive ADD COLUMN like_count BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE chirps_archive ADD COLUMN reply_count BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE chirps_archive ADD COLUMN repost_count BIGINT NOT NULL DEFAULT 0;
+
+-- The same counts the nightly job keeps: likes, replies and reposts from
+-- active accounts. Replies and reposts may have been archived before the
+-- chirp they answer.
+UPDATE chirps SET
+    like_count = (SELECT COUNT(*) FROM chirp_likes AS likes JOIN users ON users.id = likes.user_id
+                   WHERE likes.chirp_id = chirps.id AND users.deactivated_at IS NULL),
+    reply_count = (SELECT COUNT(*) FROM chirps AS replies JOIN users ON users.id = replies.user_id
+                   WHERE replies.parent_chirp_id = chirps.id AND replies.deleted_at IS NULL AND users.deactivated_at IS NULL)
+                + (SELECT COUNT(*) FROM chirps_archive AS replies JOIN users ON users.id = replies.user_id
+                   WHERE replies.parent_chirp_id = chirps.id AND users.deactivated_at IS NULL),
+    repost_count = (SELECT COUNT(*) FROM chirps AS reposts JOIN users ON users.id = reposts.user_id
+                   WHERE reposts.repost_of_chirp_id = chirps.id AND reposts.deleted_at IS NULL AND users.deactivated_at IS NULL)
+                 + (SELECT COUNT(*) FROM chirps_archive AS reposts JOIN users ON users.id = reposts.user_id
+                   WHERE reposts.repost_of_chirp_id = chirps.id AND users.deactivated_at IS NULL);
+
+UPDATE chirps_archive SET
+    like_count = (SELECT COUNT(*) FROM chirp_likes_archive AS likes JOIN users ON users.id = likes.user_id
+                   WHERE likes.chirp_id = chirps_archive.id AND users.deactivated_at IS NULL),
+    reply_count = (SELECT COUNT(*) FROM chirps AS replies JOIN users ON users.id = replies.user_id
+                   WHERE replies.parent_chirp_id = chirps_archive.id AND replies.deleted_at IS NULL AND users.deactivated_at IS NULL)
+                + (SELECT COUNT(*) FROM chirps_archive AS replies JOIN users ON users.id = replies.user_id
+                   WHERE replies.parent_chirp_id = chirps_archive.id AND users.deactivated_at IS NULL),
+    repost_count = (SELECT COUNT(*) FROM chirps AS reposts JOIN users ON users.id = reposts.user_id
+                   WHERE reposts.repost_of_chirp_id = chirps_archive.id AND reposts.deleted_at IS NULL AND users.deactivated_at IS NULL)
+                 + (SELECT COUNT(*) FROM chirps_archive AS reposts JOIN users ON users.id = reposts.user_id
+                   WHERE reposts.repost_of_chirp_id = chirps_archive.id AND users.deactivated_at IS NULL);
+
+-- +goose Down
+ALTER TABLE chirps_archive DROP COLUMN repost_count;
+ALTER TABLE chirps_archive DROP COLUMN reply_count;
+ALTER TABLE chirps_archive DROP COLUMN like_count;
+ALTER TABLE chirps DROP COLUMN repost_count;
+ALTER TABLE chirps DROP COLUMN reply_count;
+ALTER TABLE chirps DROP COLUMN like_count;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Endpoints that receive a community's moderation events, such as a chat bot
+-- announcing new reports. Deliveries are signed with the secret, so unlike
+-- API keys it is kept as is.
+CREATE TABLE moderation_webhooks (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL
+);
+
+CREATE INDEX idx_moderation_webhooks_tenant_id ON moderation_webhooks(tenant_id);
+
+-- +goose Down
+DROP TABLE moderation_webhooks;
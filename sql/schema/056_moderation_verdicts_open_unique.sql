@@ -0,0 +1,14 @@
+-- +goose Up
+-- A chirp has at most one verdict awaiting review, so a retried
+-- classification can't queue it twice. Edits are classified again once the
+-- earlier verdict was reviewed. Duplicates already queued keep the oldest.
+DELETE FROM moderation_verdicts AS duplicate
+USING moderation_verdicts AS kept
+WHERE duplicate.chirp_id = kept.chirp_id
+  AND duplicate.reviewed_at IS NULL AND kept.reviewed_at IS NULL
+  AND (duplicate.created_at, duplicate.id) > (kept.created_at, kept.id);
+
+CREATE UNIQUE INDEX idx_moderation_verdicts_open_chirp_id ON moderation_verdicts (chirp_id) WHERE reviewed_at IS NULL;
+
+-- +goose Down
+DROP INDEX idx_moderation_verdicts_open_chirp_id;
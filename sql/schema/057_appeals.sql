@@ -0,0 +1,22 @@
+-- +goose Up
+-- Appeals of authors against the moderation removal of their chirps. A
+-- chirp has at most one appeal awaiting a decision, and a denied appeal
+-- can't be filed again.
+CREATE TABLE appeals (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    decided_at TIMESTAMP,
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decision TEXT
+);
+
+CREATE UNIQUE INDEX idx_appeals_open_chirp_id ON appeals (chirp_id) WHERE decided_at IS NULL;
+CREATE INDEX idx_appeals_tenant_id_created_at ON appeals (tenant_id, created_at);
+CREATE INDEX idx_appeals_user_id_created_at ON appeals (user_id, created_at);
+
+-- +goose Down
+DROP TABLE appeals;
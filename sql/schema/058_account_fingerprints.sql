@@ -0,0 +1,18 @@
+-- +goose Up
+-- Keyed hashes of the IP addresses and device IDs accounts sign in from, so
+-- moderators can find accounts that share them with suspended ones. The
+-- raw values are never stored.
+CREATE TABLE account_fingerprints (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    hash TEXT NOT NULL,
+    first_seen_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, kind, hash)
+);
+
+CREATE INDEX idx_account_fingerprints_kind_hash ON account_fingerprints (kind, hash);
+CREATE INDEX idx_account_fingerprints_last_seen_at ON account_fingerprints (last_seen_at);
+
+-- +goose Down
+DROP TABLE account_fingerprints;